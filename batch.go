@@ -14,6 +14,8 @@ type QueuedQuery struct {
 	Arguments []any
 	Fn        batchItemFunc
 	sd        *pgconn.StatementDescription
+
+	argumentsFn func() ([]any, error)
 }
 
 type batchItemFunc func(br BatchResults) error
@@ -61,9 +63,10 @@ type Batch struct {
 }
 
 // Queue queues a query to batch b. query can be an SQL query or the name of a prepared statement. The only pgx option
-// argument that is supported is QueryRewriter. Queries are executed using the connection's DefaultQueryExecMode.
+// argument that is supported is QueryRewriter. Queries are executed using the connection's DefaultQueryExecMode unless
+// the context passed to SendBatch was created with WithQueryExecMode.
 //
-// While query can contain multiple statements if the connection's DefaultQueryExecMode is QueryModeSimple, this should
+// While query can contain multiple statements if the QueryExecMode is QueryExecModeSimpleProtocol, this should
 // be avoided. QueuedQuery.Fn must not be set as it will only be called for the first query. That is, QueuedQuery.Query,
 // QueuedQuery.QueryRow, and QueuedQuery.Exec must not be called. In addition, any error messages or tracing that
 // include the current query may reference the wrong query.
@@ -76,6 +79,29 @@ func (b *Batch) Queue(query string, arguments ...any) *QueuedQuery {
 	return qq
 }
 
+// QueueDependent queues a query to batch b whose arguments are computed by fn from the results of queries queued before
+// it. For example, a parent row can be inserted with a RETURNING clause whose result is scanned by a QueuedQuery.QueryRow
+// callback, and fn can then return the scanned id as an argument for inserting the child rows.
+//
+// When SendBatch reaches a dependent query, it flushes the queries queued before it to the server, reads their results,
+// and runs their callbacks before calling fn and sending the dependent query. The whole batch is still sent as one
+// pipeline in a single implicit transaction. Because of this, the results of all queries queued before a dependent query
+// are read by SendBatch and must be handled with QueuedQuery.Query, QueuedQuery.QueryRow, or QueuedQuery.Exec rather
+// than by reading the BatchResults. If a callback or fn returns an error, no further queries are sent and the error is
+// returned by the BatchResults.
+//
+// Dependent queries are not supported with QueryExecModeSimpleProtocol or QueryExecModeExec. A QueryRewriter is not
+// supported because the SQL is prepared before the arguments are known. SendBatch fails if the arguments returned by fn
+// or the Arguments of the QueuedQuery start with a QueryRewriter.
+func (b *Batch) QueueDependent(query string, fn func() ([]any, error)) *QueuedQuery {
+	qq := &QueuedQuery{
+		SQL:         query,
+		argumentsFn: fn,
+	}
+	b.QueuedQueries = append(b.QueuedQueries, qq)
+	return qq
+}
+
 // Len returns number of queries that have been queued so far.
 func (b *Batch) Len() int {
 	return len(b.QueuedQueries)
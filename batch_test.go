@@ -691,6 +691,116 @@ func TestConnSendBatchQueryPartialReadInsert(t *testing.T) {
 	})
 }
 
+func TestConnSendBatchDependentQuery(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, pgxtest.KnownOIDQueryExecModes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server serial type is incompatible with test")
+
+		mustExec(t, conn, `create temporary table parent(id serial primary key, name text not null);
+create temporary table child(id serial primary key, parent_id int not null references parent, name text not null);`)
+
+		var parentID int32
+		var childNames []string
+
+		batch := &pgx.Batch{}
+		batch.Queue("insert into parent(name) values($1) returning id", "p").QueryRow(func(row pgx.Row) error {
+			return row.Scan(&parentID)
+		})
+		batch.QueueDependent("insert into child(parent_id, name) values($1, $2), ($1, $3)", func() ([]any, error) {
+			return []any{parentID, "c1", "c2"}, nil
+		}).Exec(func(ct pgconn.CommandTag) error {
+			assert.EqualValues(t, 2, ct.RowsAffected())
+			return nil
+		})
+		batch.QueueDependent("select name from child where parent_id = $1 order by name", func() ([]any, error) {
+			return []any{parentID}, nil
+		}).Query(func(rows pgx.Rows) error {
+			var err error
+			childNames, err = pgx.CollectRows(rows, pgx.RowTo[string])
+			return err
+		})
+
+		err := conn.SendBatch(ctx, batch).Close()
+		require.NoError(t, err)
+		require.NotZero(t, parentID)
+		require.Equal(t, []string{"c1", "c2"}, childNames)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchDependentQueryErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, pgxtest.KnownOIDQueryExecModes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1/0").Exec(func(ct pgconn.CommandTag) error { return nil })
+		batch.QueueDependent("select $1::int4", func() ([]any, error) {
+			t.Error("dependent query arguments should not be computed after an error")
+			return []any{1}, nil
+		})
+
+		err := conn.SendBatch(ctx, batch).Close()
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "22012", pgErr.Code)
+
+		ensureConnValid(t, conn)
+
+		batch = &pgx.Batch{}
+		batch.Queue("select 1").Exec(func(ct pgconn.CommandTag) error { return nil })
+		batch.QueueDependent("select $1::int4", func() ([]any, error) {
+			return nil, errors.New("foo")
+		})
+
+		err = conn.SendBatch(ctx, batch).Close()
+		require.EqualError(t, err, "dependent query select $1::int4: foo")
+
+		ensureConnValid(t, conn)
+
+		// A QueryRewriter cannot be applied to a dependent query.
+		batch = &pgx.Batch{}
+		batch.QueueDependent("select $1::int4", func() ([]any, error) {
+			return []any{1}, nil
+		}).Arguments = []any{&testQueryRewriter{sql: "select 1"}}
+
+		err = conn.SendBatch(ctx, batch).Close()
+		require.EqualError(t, err, "dependent query select $1::int4: QueryRewriter is not supported")
+
+		ensureConnValid(t, conn)
+
+		batch = &pgx.Batch{}
+		batch.Queue("select 1").Exec(func(ct pgconn.CommandTag) error { return nil })
+		batch.QueueDependent("select $1::int4", func() ([]any, error) {
+			return []any{&testQueryRewriter{sql: "select 1"}, 1}, nil
+		})
+
+		err = conn.SendBatch(ctx, batch).Close()
+		require.EqualError(t, err, "dependent query select $1::int4: QueryRewriter is not supported")
+
+		ensureConnValid(t, conn)
+	})
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, []pgx.QueryExecMode{pgx.QueryExecModeExec, pgx.QueryExecModeSimpleProtocol}, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.QueueDependent("select $1::int4", func() ([]any, error) {
+			return []any{1}, nil
+		})
+
+		err := conn.SendBatch(ctx, batch).Close()
+		require.ErrorContains(t, err, "dependent batch queries are not supported")
+
+		ensureConnValid(t, conn)
+	})
+}
+
 func TestTxSendBatch(t *testing.T) {
 	t.Parallel()
 
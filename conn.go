@@ -41,6 +41,27 @@ type ConnConfig struct {
 	// functionality can be controlled on a per query basis by passing a QueryExecMode as the first query argument.
 	DefaultQueryExecMode QueryExecMode
 
+	// SQLMiddleware, if set, is called with the SQL of every query before it is sent. See SQLMiddleware for details.
+	SQLMiddleware SQLMiddleware
+
+	// StrictPlaceholderArgs enables checking that the number of arguments of a query matches the highest $n placeholder
+	// in its SQL before the query is sent. A mismatch is returned as an error without contacting the server. This is
+	// especially useful with QueryExecModeSimpleProtocol where the server cannot detect a mismatch. Placeholders in
+	// dollar-quoted strings are counted. The check is not applied to queries that execute a named prepared statement.
+	StrictPlaceholderArgs bool
+
+	// ScanErrorContext adds the name and OID of the column, the row number, and a summary of the SQL and arguments of the
+	// query to errors returned when scanning a row fails. See ScanArgError.
+	ScanErrorContext bool
+
+	// RedactScanErrorArgs replaces the argument values in the context added by ScanErrorContext with their types. Use
+	// this when arguments may contain sensitive data that must not appear in logs.
+	RedactScanErrorArgs bool
+
+	// ColumnScanPlan, if set, is called when a scan plan is chosen for a column of a query result by Rows.Scan. See
+	// ColumnScanPlanFunc.
+	ColumnScanPlan ColumnScanPlanFunc
+
 	createdByParseConfig bool // Used to enforce created by ParseConfig rule.
 }
 
@@ -75,6 +96,7 @@ type Conn struct {
 	batchTracer    BatchTracer
 	copyFromTracer CopyFromTracer
 	prepareTracer  PrepareTracer
+	txRetryTracer  TxRetryTracer
 
 	notifications []*pgconn.Notification
 
@@ -106,6 +128,11 @@ var (
 	ErrNoRows = newProxyErr(sql.ErrNoRows, "no rows in result set")
 	// ErrTooManyRows occurs when more rows than expected are returned.
 	ErrTooManyRows = errors.New("too many rows in result set")
+	// ErrPlanInvalidated occurs when a cached statement is executed inside a transaction after a schema change altered
+	// its result type. The server reports "cached plan must not change result type" and aborts the transaction. The
+	// cached statement has been invalidated so the transaction can be rolled back and restarted. The returned error also
+	// wraps the *pgconn.PgError. IsRetryableTxError returns true for it.
+	ErrPlanInvalidated = errors.New("cached plan invalidated")
 )
 
 func newProxyErr(background error, msg string) error {
@@ -266,6 +293,9 @@ func connect(ctx context.Context, config *ConnConfig) (c *Conn, err error) {
 	if t, ok := c.queryTracer.(PrepareTracer); ok {
 		c.prepareTracer = t
 	}
+	if t, ok := c.queryTracer.(TxRetryTracer); ok {
+		c.txRetryTracer = t
+	}
 
 	// Only install pgx notification system if no other callback handler is present.
 	if config.Config.OnNotification == nil {
@@ -353,9 +383,51 @@ func (c *Conn) Prepare(ctx context.Context, name, sql string) (sd *pgconn.Statem
 		c.preparedStatements[psKey] = sd
 	}
 
+	if len(c.typeMap.PendingLazyTypes()) > 0 && c.hasUnregisteredType(sd) {
+		// An error is ignored because the statement has been prepared successfully. The types remain pending and the
+		// statement can be used with the currently registered types.
+		_ = c.LoadPendingTypes(ctx)
+	}
+
 	return sd, nil
 }
 
+func (c *Conn) hasUnregisteredType(sd *pgconn.StatementDescription) bool {
+	for _, oid := range sd.ParamOIDs {
+		if _, ok := c.typeMap.TypeForOID(oid); !ok {
+			return true
+		}
+	}
+	for _, fd := range sd.Fields {
+		if _, ok := c.typeMap.TypeForOID(fd.DataTypeOID); !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadPendingTypes loads and registers the types recorded with pgtype.Map.LoadTypeLazy that have not been loaded. All
+// pending types and the types they depend on are loaded with a single query. Names that do not match a type are
+// remembered and are not queried again.
+//
+// Pending types are loaded automatically when a statement is prepared that has a parameter or result type that is not
+// registered. Call LoadPendingTypes before using a lazily loaded type with QueryExecModeExec or
+// QueryExecModeSimpleProtocol as those modes do not prepare statements.
+func (c *Conn) LoadPendingTypes(ctx context.Context) error {
+	names := c.typeMap.PendingLazyTypes()
+	if len(names) == 0 {
+		return nil
+	}
+
+	_, err := c.LoadTypes(ctx, names)
+	if err != nil {
+		return err
+	}
+
+	c.typeMap.LazyTypesLoaded(names)
+	return nil
+}
+
 // Deallocate releases a prepared statement. Calling Deallocate on a non-existent prepared statement will succeed.
 func (c *Conn) Deallocate(ctx context.Context, name string) error {
 	var psName string
@@ -387,8 +459,51 @@ func (c *Conn) DeallocateAll(ctx context.Context) error {
 	if c.config.DescriptionCacheCapacity > 0 {
 		c.descriptionCache = stmtcache.NewLRUCache(c.config.DescriptionCacheCapacity)
 	}
-	_, err := c.pgConn.Exec(ctx, "deallocate all").ReadAll()
-	return err
+	return c.pgConn.DeallocateAll(ctx)
+}
+
+// InvalidateStatementCaches invalidates all statements in the statement and description caches. The invalidated
+// prepared statements are deallocated before the next query is executed and the statements are prepared again when they
+// are next used. This avoids "cached plan must not change result type" errors after a schema change.
+func (c *Conn) InvalidateStatementCaches() {
+	if c.statementCache != nil {
+		c.statementCache.InvalidateAll()
+	}
+	if c.descriptionCache != nil {
+		c.descriptionCache.InvalidateAll()
+	}
+}
+
+// Reset restores the connection to a pristine session state. Any transaction in progress is rolled back and then
+// DISCARD ALL is executed. This deallocates prepared statements, closes cursors, unlistens from all channels, releases
+// advisory locks, drops temporary tables, and resets all session settings to their defaults. Client side prepared
+// statement and description caches and buffered notifications are cleared to match. Types registered in TypeMap are
+// not affected.
+//
+// Reset is intended for pools and long-lived connections that are shared between unrelated units of work.
+func (c *Conn) Reset(ctx context.Context) error {
+	if c.pgConn.TxStatus() != 'I' {
+		_, err := c.pgConn.Exec(ctx, "rollback").ReadAll()
+		if err != nil {
+			return fmt.Errorf("reset: rollback failed: %w", err)
+		}
+	}
+
+	c.preparedStatements = map[string]*pgconn.StatementDescription{}
+	if c.config.StatementCacheCapacity > 0 {
+		c.statementCache = stmtcache.NewLRUCache(c.config.StatementCacheCapacity)
+	}
+	if c.config.DescriptionCacheCapacity > 0 {
+		c.descriptionCache = stmtcache.NewLRUCache(c.config.DescriptionCacheCapacity)
+	}
+	c.notifications = nil
+
+	_, err := c.pgConn.Exec(ctx, "discard all").ReadAll()
+	if err != nil {
+		return fmt.Errorf("reset: discard all failed: %w", err)
+	}
+
+	return nil
 }
 
 func (c *Conn) bufferNotifications(_ *pgconn.PgConn, n *pgconn.Notification) {
@@ -439,6 +554,11 @@ func (c *Conn) Ping(ctx context.Context) error {
 	return c.pgConn.Ping(ctx)
 }
 
+// PingLight delegates to the underlying *pgconn.PgConn.PingLight.
+func (c *Conn) PingLight(ctx context.Context) error {
+	return c.pgConn.PingLight(ctx)
+}
+
 // PgConn returns the underlying *pgconn.PgConn. This is an escape hatch method that allows lower level access to the
 // PostgreSQL connection than pgx exposes.
 //
@@ -455,6 +575,11 @@ func (c *Conn) Config() *ConnConfig { return c.config.Copy() }
 // Exec executes sql. sql can be either a prepared statement name or an SQL string. arguments should be referenced
 // positionally from the sql string as $1, $2, etc.
 func (c *Conn) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	ctx, sql, err := c.config.ApplySQLMiddleware(ctx, sql)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+
 	if c.queryTracer != nil {
 		ctx = c.queryTracer.TraceQueryStart(ctx, c, TraceQueryStartData{SQL: sql, Args: arguments})
 	}
@@ -472,8 +597,59 @@ func (c *Conn) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.C
 	return commandTag, err
 }
 
+// ExecMany executes sql once for each set of arguments in argsList. All executions are sent to the server in a single
+// round trip. With the QueryExecModeCacheStatement, QueryExecModeCacheDescribe, and QueryExecModeDescribeExec modes sql
+// is prepared once and then bound and executed for each set of arguments. All executions are followed by a single Sync
+// and therefore run in an implicit transaction unless an explicit transaction is already in progress.
+//
+// ExecMany returns the command tags of the executions that succeeded in the order they were executed. If an execution
+// fails err will be an *ExecManyError identifying the failed set of arguments. Because of the implicit transaction the
+// successful executions before it will also have been rolled back.
+func (c *Conn) ExecMany(ctx context.Context, sql string, argsList [][]any) ([]pgconn.CommandTag, error) {
+	if len(argsList) == 0 {
+		return nil, nil
+	}
+
+	batch := &Batch{QueuedQueries: make([]*QueuedQuery, 0, len(argsList))}
+	for _, args := range argsList {
+		batch.Queue(sql, args...)
+	}
+
+	br := c.SendBatch(ctx, batch)
+	commandTags := make([]pgconn.CommandTag, 0, len(argsList))
+	for i := range argsList {
+		ct, err := br.Exec()
+		if err != nil {
+			br.Close()
+			return commandTags, &ExecManyError{Index: i, Err: err}
+		}
+		commandTags = append(commandTags, ct)
+	}
+
+	err := br.Close()
+	if err != nil {
+		return commandTags, err
+	}
+
+	return commandTags, nil
+}
+
+// ExecManyError is returned by ExecMany when an execution fails.
+type ExecManyError struct {
+	Index int // Index into the argsList passed to ExecMany of the failed execution.
+	Err   error
+}
+
+func (e *ExecManyError) Error() string {
+	return fmt.Sprintf("exec %d: %v", e.Index, e.Err)
+}
+
+func (e *ExecManyError) Unwrap() error {
+	return e.Err
+}
+
 func (c *Conn) exec(ctx context.Context, sql string, arguments ...any) (commandTag pgconn.CommandTag, err error) {
-	mode := c.config.DefaultQueryExecMode
+	mode := c.defaultQueryExecMode(ctx)
 	var queryRewriter QueryRewriter
 
 optionLoop:
@@ -497,6 +673,10 @@ optionLoop:
 		}
 	}
 
+	if err := c.checkPlaceholderArgs(sql, arguments); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+
 	// Always use simple protocol when there are no arguments.
 	if len(arguments) == 0 {
 		mode = QueryExecModeSimpleProtocol
@@ -520,7 +700,8 @@ optionLoop:
 			c.statementCache.Put(sd)
 		}
 
-		return c.execPrepared(ctx, sd, arguments)
+		commandTag, err = c.execPrepared(ctx, sd, arguments)
+		return commandTag, c.handleInvalidCachedPlan(sql, err)
 	case QueryExecModeCacheDescribe:
 		if c.descriptionCache == nil {
 			return pgconn.CommandTag{}, errDisabledDescriptionCache
@@ -534,7 +715,8 @@ optionLoop:
 			c.descriptionCache.Put(sd)
 		}
 
-		return c.execParams(ctx, sd, arguments)
+		commandTag, err = c.execParams(ctx, sd, arguments)
+		return commandTag, c.handleInvalidCachedPlan(sql, err)
 	case QueryExecModeDescribeExec:
 		sd, err := c.Prepare(ctx, "", sql)
 		if err != nil {
@@ -688,12 +870,37 @@ func (m QueryExecMode) String() string {
 	}
 }
 
+type queryExecModeCtxKey struct{}
+
+// WithQueryExecMode returns a copy of ctx that overrides ConnConfig.DefaultQueryExecMode for Exec, Query, QueryRow,
+// SendBatch, and CopyFrom called with the returned context. This allows specific queries to use a different mode, e.g.
+// QueryExecModeSimpleProtocol for multi-statement SQL, without changing every call site or using a second connection.
+// A QueryExecMode passed as a query argument takes precedence.
+func WithQueryExecMode(ctx context.Context, mode QueryExecMode) context.Context {
+	return context.WithValue(ctx, queryExecModeCtxKey{}, mode)
+}
+
+// defaultQueryExecMode returns the QueryExecMode set on ctx with WithQueryExecMode or c.config.DefaultQueryExecMode.
+func (c *Conn) defaultQueryExecMode(ctx context.Context) QueryExecMode {
+	if mode, ok := ctx.Value(queryExecModeCtxKey{}).(QueryExecMode); ok {
+		return mode
+	}
+	return c.config.DefaultQueryExecMode
+}
+
 // QueryResultFormats controls the result format (text=0, binary=1) of a query by result column position.
 type QueryResultFormats []int16
 
 // QueryResultFormatsByOID controls the result format (text=0, binary=1) of a query by the result column OID.
 type QueryResultFormatsByOID map[uint32]int16
 
+// QueryNextRowTimeout limits how long each call of Rows.Next waits for the next row when used as the first arguments
+// to Query. If the timeout expires the query is canceled as if its context had been canceled and Rows.Err returns a
+// *pgconn.RowTimeoutError. The time the application spends processing a row is not included. This allows consuming
+// results that never end, such as a FETCH from a cursor over a function that waits for new data, while still
+// detecting a stalled server. See pgconn.ResultReader.SetRowTimeout.
+type QueryNextRowTimeout time.Duration
+
 // QueryRewriter rewrites a query when used as the first arguments to a query method.
 type QueryRewriter interface {
 	RewriteQuery(ctx context.Context, conn *Conn, sql string, args []any) (newSQL string, newArgs []any, err error)
@@ -717,10 +924,15 @@ type QueryRewriter interface {
 // An implementor of QueryRewriter may be passed as the first element of args. It can rewrite the sql and change or
 // replace args. For example, NamedArgs is QueryRewriter that implements named arguments.
 //
-// For extra control over how the query is executed, the types QueryExecMode, QueryResultFormats, and
-// QueryResultFormatsByOID may be used as the first args to control exactly how the query is executed. This is rarely
-// needed. See the documentation for those types for details.
+// For extra control over how the query is executed, the types QueryExecMode, QueryResultFormats,
+// QueryResultFormatsByOID, and QueryNextRowTimeout may be used as the first args to control exactly how the query is
+// executed. This is rarely needed. See the documentation for those types for details.
 func (c *Conn) Query(ctx context.Context, sql string, args ...any) (Rows, error) {
+	ctx, sql, err := c.config.ApplySQLMiddleware(ctx, sql)
+	if err != nil {
+		return &baseRows{err: err, closed: true}, err
+	}
+
 	if c.queryTracer != nil {
 		ctx = c.queryTracer.TraceQueryStart(ctx, c, TraceQueryStartData{SQL: sql, Args: args})
 	}
@@ -734,8 +946,9 @@ func (c *Conn) Query(ctx context.Context, sql string, args ...any) (Rows, error)
 
 	var resultFormats QueryResultFormats
 	var resultFormatsByOID QueryResultFormatsByOID
-	mode := c.config.DefaultQueryExecMode
+	mode := c.defaultQueryExecMode(ctx)
 	var queryRewriter QueryRewriter
+	var nextRowTimeout QueryNextRowTimeout
 
 optionLoop:
 	for len(args) > 0 {
@@ -749,6 +962,9 @@ optionLoop:
 		case QueryExecMode:
 			mode = arg
 			args = args[1:]
+		case QueryNextRowTimeout:
+			nextRowTimeout = arg
+			args = args[1:]
 		case QueryRewriter:
 			queryRewriter = arg
 			args = args[1:]
@@ -778,7 +994,11 @@ optionLoop:
 	c.eqb.reset()
 	rows := c.getRows(ctx, sql, args)
 
-	var err error
+	if err := c.checkPlaceholderArgs(sql, args); err != nil {
+		rows.fatal(err)
+		return rows, err
+	}
+
 	sd, explicitPreparedStatement := c.preparedStatements[sql]
 	if sd != nil || mode == QueryExecModeCacheStatement || mode == QueryExecModeCacheDescribe || mode == QueryExecModeDescribeExec {
 		if sd == nil {
@@ -836,6 +1056,7 @@ optionLoop:
 		mrr := c.pgConn.Exec(ctx, sql)
 		if mrr.NextResult() {
 			rows.resultReader = mrr.ResultReader()
+			rows.resultReader.SetRowTimeout(time.Duration(nextRowTimeout))
 			rows.multiResultReader = mrr
 		} else {
 			err = mrr.Close()
@@ -850,11 +1071,32 @@ optionLoop:
 		return rows, rows.err
 	}
 
+	rows.resultReader.SetRowTimeout(time.Duration(nextRowTimeout))
+
 	c.eqb.reset() // Allow c.eqb internal memory to be GC'ed as soon as possible.
 
 	return rows, rows.err
 }
 
+// checkPlaceholderArgs returns an error if StrictPlaceholderArgs is enabled and the number of args does not match the
+// highest placeholder in sql.
+func (c *Conn) checkPlaceholderArgs(sql string, args []any) error {
+	if !c.config.StrictPlaceholderArgs {
+		return nil
+	}
+
+	if _, ok := c.preparedStatements[sql]; ok {
+		return nil
+	}
+
+	n := sanitize.MaxPlaceholder(sql)
+	if n != len(args) {
+		return fmt.Errorf("expected %d arguments for placeholders in sql, got %d", n, len(args))
+	}
+
+	return nil
+}
+
 // getStatementDescription returns the statement description of the sql query
 // according to the given mode.
 //
@@ -911,6 +1153,11 @@ func (c *Conn) QueryRow(ctx context.Context, sql string, args ...any) Row {
 // Depending on the QueryExecMode, all queries may be prepared before any are executed. This means that creating a table
 // and using it in a subsequent query in the same batch can fail.
 func (c *Conn) SendBatch(ctx context.Context, b *Batch) (br BatchResults) {
+	ctx, b, err := c.config.ApplySQLMiddlewareToBatch(ctx, b)
+	if err != nil {
+		return &batchResults{ctx: ctx, conn: c, err: err}
+	}
+
 	if c.batchTracer != nil {
 		ctx = c.batchTracer.TraceBatchStart(ctx, c, TraceBatchStartData{Batch: b})
 		defer func() {
@@ -926,6 +1173,15 @@ func (c *Conn) SendBatch(ctx context.Context, b *Batch) (br BatchResults) {
 	}
 
 	for _, bi := range b.QueuedQueries {
+		if bi.argumentsFn != nil {
+			// The arguments of dependent queries are not known until the results of earlier queries have been read. A
+			// QueryRewriter cannot be applied because the SQL must be known before then.
+			if err := checkDependentQueryArgs(bi.SQL, bi.Arguments); err != nil {
+				return &batchResults{ctx: ctx, conn: c, err: err}
+			}
+			continue
+		}
+
 		var queryRewriter QueryRewriter
 		sql := bi.SQL
 		arguments := bi.Arguments
@@ -950,12 +1206,23 @@ func (c *Conn) SendBatch(ctx context.Context, b *Batch) (br BatchResults) {
 			}
 		}
 
+		if err := c.checkPlaceholderArgs(sql, arguments); err != nil {
+			return &batchResults{ctx: ctx, conn: c, err: err}
+		}
+
 		bi.SQL = sql
 		bi.Arguments = arguments
 	}
 
-	// TODO: changing mode per batch? Update Batch.Queue function comment when implemented
-	mode := c.config.DefaultQueryExecMode
+	mode := c.defaultQueryExecMode(ctx)
+	if mode == QueryExecModeSimpleProtocol || mode == QueryExecModeExec {
+		for _, bi := range b.QueuedQueries {
+			if bi.argumentsFn != nil {
+				return &batchResults{ctx: ctx, conn: c, err: fmt.Errorf("dependent batch queries are not supported with query exec mode %v", mode)}
+			}
+		}
+	}
+
 	if mode == QueryExecModeSimpleProtocol {
 		return c.sendBatchQueryExecModeSimpleProtocol(ctx, b)
 	}
@@ -1192,8 +1459,22 @@ func (c *Conn) sendBatchExtendedWithDescription(ctx context.Context, b *Batch, d
 		}
 	}
 
+	pbr = &pipelineBatchResults{
+		ctx:      ctx,
+		conn:     c,
+		pipeline: pipeline,
+		b:        b,
+	}
+
 	// Queue the queries.
-	for _, bi := range b.QueuedQueries {
+	for i, bi := range b.QueuedQueries {
+		if bi.argumentsFn != nil {
+			err := c.readBatchResultsBeforeDependentQuery(pbr, i)
+			if err != nil {
+				return pbr
+			}
+		}
+
 		err := c.eqb.Build(c.typeMap, bi.sd, bi.Arguments)
 		if err != nil {
 			// we wrap the error so we the user can understand which query failed inside the batch
@@ -1213,12 +1494,72 @@ func (c *Conn) sendBatchExtendedWithDescription(ctx context.Context, b *Batch, d
 		return &pipelineBatchResults{ctx: ctx, conn: c, err: err, closed: true}
 	}
 
-	return &pipelineBatchResults{
-		ctx:      ctx,
-		conn:     c,
-		pipeline: pipeline,
-		b:        b,
+	return pbr
+}
+
+// readBatchResultsBeforeDependentQuery flushes the queries queued before the dependent query at index idx, reads their
+// results, and computes the arguments of the dependent query. If an error occurs, the pipeline is synchronized so it
+// can be closed cleanly and pbr is closed with the error.
+func (c *Conn) readBatchResultsBeforeDependentQuery(pbr *pipelineBatchResults, idx int) error {
+	bi := pbr.b.QueuedQueries[idx]
+
+	err := func() error {
+		pbr.pipeline.SendFlushRequest()
+		err := pbr.pipeline.Flush()
+		if err != nil {
+			return err
+		}
+
+		for pbr.err == nil && pbr.qqIdx < idx {
+			if fn := pbr.b.QueuedQueries[pbr.qqIdx].Fn; fn != nil {
+				err := fn(pbr)
+				if err != nil {
+					pbr.err = err
+				}
+			} else {
+				pbr.Exec()
+			}
+		}
+		if pbr.err != nil {
+			return pbr.err
+		}
+		if pbr.lastRows != nil && pbr.lastRows.err != nil {
+			return pbr.lastRows.err
+		}
+
+		arguments, err := bi.argumentsFn()
+		if err != nil {
+			return fmt.Errorf("dependent query %s: %w", bi.SQL, err)
+		}
+		if err := checkDependentQueryArgs(bi.SQL, arguments); err != nil {
+			return err
+		}
+		if err := c.checkPlaceholderArgs(bi.SQL, arguments); err != nil {
+			return err
+		}
+		bi.Arguments = arguments
+
+		return nil
+	}()
+	if err != nil {
+		pbr.err = err
+		pbr.closed = true
+		pbr.pipeline.Sync()
+		return err
+	}
+
+	return nil
+}
+
+// checkDependentQueryArgs returns an error if args of the dependent query sql start with a QueryRewriter. The
+// QueryRewriter would otherwise be ignored.
+func checkDependentQueryArgs(sql string, args []any) error {
+	if len(args) > 0 {
+		if _, ok := args[0].(QueryRewriter); ok {
+			return fmt.Errorf("dependent query %s: QueryRewriter is not supported", sql)
+		}
 	}
+	return nil
 }
 
 func (c *Conn) sanitizeForSimpleQuery(sql string, args ...any) (string, error) {
@@ -1247,7 +1588,8 @@ func (c *Conn) sanitizeForSimpleQuery(sql string, args ...any) (string, error) {
 // typeName must be one of the following:
 //   - An array type name of a type that is already registered. e.g. "_foo" when "foo" is registered.
 //   - A composite type name where all field types are already registered.
-//   - A domain type name where the base type is already registered.
+//   - A domain type name. The base type is loaded as well if it is not already registered. The domain uses the codec of
+//     the base type. Domain constraints are enforced by the server and do not affect encoding or decoding.
 //   - An enum type name.
 //   - A range type name where the element type is already registered.
 //   - A multirange type name where the element type is already registered.
@@ -1259,10 +1601,15 @@ func (c *Conn) LoadType(ctx context.Context, typeName string) (*pgtype.Type, err
 		return nil, err
 	}
 
+	return c.loadType(ctx, typeName, oid)
+}
+
+func (c *Conn) loadType(ctx context.Context, typeName string, oid uint32) (*pgtype.Type, error) {
 	var typtype string
 	var typbasetype uint32
+	var baseTypeName string
 
-	err = c.QueryRow(ctx, "select typtype::text, typbasetype from pg_type where oid=$1", oid).Scan(&typtype, &typbasetype)
+	err := c.QueryRow(ctx, "select typtype::text, typbasetype, typbasetype::regtype::text from pg_type where oid=$1", oid).Scan(&typtype, &typbasetype, &baseTypeName)
 	if err != nil {
 		return nil, err
 	}
@@ -1290,7 +1637,11 @@ func (c *Conn) LoadType(ctx context.Context, typeName string) (*pgtype.Type, err
 	case "d": // domain
 		dt, ok := c.TypeMap().TypeForOID(typbasetype)
 		if !ok {
-			return nil, errors.New("domain base type OID not registered")
+			// The base type is not registered. It may be another domain or a derived type that can be loaded.
+			dt, err = c.loadType(ctx, baseTypeName, typbasetype)
+			if err != nil {
+				return nil, fmt.Errorf("domain base type %s: %w", baseTypeName, err)
+			}
 		}
 
 		return &pgtype.Type{Name: typeName, OID: oid, Codec: dt.Codec}, nil
@@ -1392,6 +1743,41 @@ order by attnum`,
 	return fields, nil
 }
 
+// isInvalidCachedPlanError returns true if err was caused by executing a prepared statement whose result type was changed
+// by a schema change. The message is translated according to lc_messages so the routine that reports the error is
+// checked instead.
+func isInvalidCachedPlanError(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "0A000" && pgErr.Routine == "RevalidateCachedQuery"
+}
+
+// handleInvalidCachedPlan invalidates the cached statement and description of sql if err is an invalid cached plan
+// error. See planInvalidatedError.
+func (c *Conn) handleInvalidCachedPlan(sql string, err error) error {
+	if !isInvalidCachedPlanError(err) {
+		return err
+	}
+
+	if c.statementCache != nil {
+		c.statementCache.Invalidate(sql)
+	}
+	if c.descriptionCache != nil {
+		c.descriptionCache.Invalidate(sql)
+	}
+
+	return c.planInvalidatedError(err)
+}
+
+// planInvalidatedError wraps err with ErrPlanInvalidated if err is an invalid cached plan error that aborted an explicit
+// transaction. Outside of a transaction the statement will simply be prepared again on the next execution.
+func (c *Conn) planInvalidatedError(err error) error {
+	if !isInvalidCachedPlanError(err) || errors.Is(err, ErrPlanInvalidated) || c.pgConn.TxStatus() != 'E' {
+		return err
+	}
+
+	return fmt.Errorf("%w: %w", ErrPlanInvalidated, err)
+}
+
 func (c *Conn) deallocateInvalidatedCachedStatements(ctx context.Context) error {
 	if txStatus := c.pgConn.TxStatus(); txStatus != 'I' && txStatus != 'T' {
 		return nil
@@ -1410,19 +1796,12 @@ func (c *Conn) deallocateInvalidatedCachedStatements(ctx context.Context) error
 		return nil
 	}
 
-	pipeline := c.pgConn.StartPipeline(ctx)
-	defer pipeline.Close()
-
-	for _, sd := range invalidatedStatements {
-		pipeline.SendDeallocate(sd.Name)
-	}
-
-	err := pipeline.Sync()
-	if err != nil {
-		return fmt.Errorf("failed to deallocate cached statement(s): %w", err)
+	names := make([]string, len(invalidatedStatements))
+	for i, sd := range invalidatedStatements {
+		names[i] = sd.Name
 	}
 
-	err = pipeline.Close()
+	err := c.pgConn.DeallocateMany(ctx, names)
 	if err != nil {
 		return fmt.Errorf("failed to deallocate cached statement(s): %w", err)
 	}
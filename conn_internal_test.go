@@ -6,6 +6,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -53,3 +54,49 @@ func TestStmtCacheSizeLimit(t *testing.T) {
 	assert.Len(t, conn.preparedStatements, cacheLimit+1)
 	assert.Equal(t, cacheLimit, conn.statementCache.Len())
 }
+
+func TestQuoteLiteralWithoutDatabase(t *testing.T) {
+	for i, tt := range []struct {
+		s                         string
+		standardConformingStrings bool
+		expected                  string
+	}{
+		{s: "", standardConformingStrings: true, expected: `''`},
+		{s: "it's", standardConformingStrings: true, expected: `'it''s'`},
+		{s: `a\b'`, standardConformingStrings: true, expected: `'a\b'''`},
+		{s: "it's", standardConformingStrings: false, expected: `'it''s'`},
+		{s: `a\b'`, standardConformingStrings: false, expected: `E'a\\b'''`},
+	} {
+		assert.Equalf(t, tt.expected, quoteLiteral(tt.s, tt.standardConformingStrings), "%d", i)
+	}
+}
+
+type quoteTestStatus string
+
+func TestQuoteValueWithoutDatabase(t *testing.T) {
+	m := pgtype.NewMap()
+	m.RegisterType(&pgtype.Type{Name: "app.Status", OID: 100000, Codec: &pgtype.EnumCodec{}})
+	m.RegisterDefaultPgType(quoteTestStatus(""), "app.Status")
+
+	for i, tt := range []struct {
+		value                     any
+		standardConformingStrings bool
+		expected                  string
+	}{
+		{value: nil, standardConformingStrings: true, expected: `NULL`},
+		{value: (*int32)(nil), standardConformingStrings: true, expected: `NULL`},
+		{value: int32(42), standardConformingStrings: true, expected: `'42'::"int4"`},
+		{value: []byte{1, 255}, standardConformingStrings: true, expected: `'\x01ff'::"bytea"`},
+		{value: []byte{1, 255}, standardConformingStrings: false, expected: `E'\\x01ff'::"bytea"`},
+		{value: []int32{1, 2}, standardConformingStrings: true, expected: `'{1,2}'::"_int4"`},
+		{value: []string{"a", "b,c", "d'e"}, standardConformingStrings: true, expected: `'{a,"b,c",d''e}'::"_text"`},
+		{value: quoteTestStatus("active"), standardConformingStrings: true, expected: `'active'::"app"."Status"`},
+	} {
+		quoted, err := quoteValue(m, tt.value, tt.standardConformingStrings)
+		require.NoErrorf(t, err, "%d", i)
+		assert.Equalf(t, tt.expected, quoted, "%d", i)
+	}
+
+	_, err := quoteValue(m, struct{}{}, true)
+	require.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"errors"
 	"os"
 	"strings"
 	"sync"
@@ -309,6 +310,281 @@ func TestExecFailureWithArguments(t *testing.T) {
 	})
 }
 
+func TestConnSQLMiddleware(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+
+	var mux sync.Mutex
+	var routingKeys []any
+	config.SQLMiddleware = func(ctx context.Context, sql string) (context.Context, string, error) {
+		if strings.Contains(sql, "tenant=42") {
+			ctx = pgx.WithRoutingMetadata(ctx, 42)
+		}
+		return ctx, strings.ReplaceAll(sql, "$shard", "'shard_a'"), nil
+	}
+	config.Tracer = &testTracer{
+		traceQueryStart: func(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+			md, _ := pgx.RoutingMetadataFromContext(ctx)
+			mux.Lock()
+			routingKeys = append(routingKeys, md)
+			mux.Unlock()
+			return ctx
+		},
+	}
+
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	var s string
+	err := conn.QueryRow(ctx, "select $shard::text -- tenant=42").Scan(&s)
+	require.NoError(t, err)
+	assert.Equal(t, "shard_a", s)
+
+	_, err = conn.Exec(ctx, "select $shard::text")
+	require.NoError(t, err)
+
+	batch := &pgx.Batch{}
+	batch.Queue("select $shard::text")
+	err = conn.SendBatch(ctx, batch).QueryRow().Scan(&s)
+	require.NoError(t, err)
+	assert.Equal(t, "shard_a", s)
+
+	mux.Lock()
+	assert.Equal(t, []any{42, nil}, routingKeys)
+	mux.Unlock()
+
+	ensureConnValid(t, conn)
+}
+
+func TestConnStrictPlaceholderArgs(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.StrictPlaceholderArgs = true
+
+	for _, mode := range []pgx.QueryExecMode{pgx.QueryExecModeCacheStatement, pgx.QueryExecModeExec, pgx.QueryExecModeSimpleProtocol} {
+		t.Run(mode.String(), func(t *testing.T) {
+			config := config.Copy()
+			config.DefaultQueryExecMode = mode
+			conn := mustConnect(t, config)
+			defer closeConn(t, conn)
+
+			var n int64
+			err := conn.QueryRow(ctx, "select $1::int8 + $2::int8 -- $3", 1, 2).Scan(&n)
+			require.NoError(t, err)
+			assert.EqualValues(t, 3, n)
+
+			err = conn.QueryRow(ctx, "select $1::int8 + $2::int8", 1).Scan(&n)
+			require.EqualError(t, err, "expected 2 arguments for placeholders in sql, got 1")
+
+			_, err = conn.Exec(ctx, "select $1::int8", 1, 2)
+			require.EqualError(t, err, "expected 1 arguments for placeholders in sql, got 2")
+
+			_, err = conn.Exec(ctx, "select '$1'")
+			require.NoError(t, err)
+
+			batch := &pgx.Batch{}
+			batch.Queue("select $1::int8", 1)
+			batch.Queue("select $2::int8", 1)
+			err = conn.SendBatch(ctx, batch).Close()
+			require.EqualError(t, err, "expected 2 arguments for placeholders in sql, got 1")
+
+			ensureConnValid(t, conn)
+		})
+	}
+}
+
+func TestConnConfigApplySQLMiddleware(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgx.ParseConfig("")
+	require.NoError(t, err)
+
+	ctx, sql, err := config.ApplySQLMiddleware(context.Background(), "select 1")
+	require.NoError(t, err)
+	assert.Equal(t, "select 1", sql)
+	_, ok := pgx.RoutingMetadataFromContext(ctx)
+	assert.False(t, ok)
+
+	calls := 0
+	config.SQLMiddleware = func(ctx context.Context, sql string) (context.Context, string, error) {
+		calls++
+		return pgx.WithRoutingMetadata(ctx, "shard_b"), "/* shard_b */ " + sql, nil
+	}
+
+	ctx, sql, err = config.ApplySQLMiddleware(context.Background(), "select 1")
+	require.NoError(t, err)
+	assert.Equal(t, "/* shard_b */ select 1", sql)
+	md, ok := pgx.RoutingMetadataFromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "shard_b", md)
+
+	// The middleware is not applied again to an already processed context.
+	_, sql, err = config.ApplySQLMiddleware(ctx, sql)
+	require.NoError(t, err)
+	assert.Equal(t, "/* shard_b */ select 1", sql)
+	assert.Equal(t, 1, calls)
+
+	batch := &pgx.Batch{}
+	batch.Queue("select 1")
+	batch.Queue("select 2")
+	_, rewritten, err := config.ApplySQLMiddlewareToBatch(context.Background(), batch)
+	require.NoError(t, err)
+	assert.Equal(t, "/* shard_b */ select 1", rewritten.QueuedQueries[0].SQL)
+	assert.Equal(t, "/* shard_b */ select 2", rewritten.QueuedQueries[1].SQL)
+
+	// The batch of the caller is not modified so sending it again does not rewrite the SQL twice.
+	assert.Equal(t, "select 1", batch.QueuedQueries[0].SQL)
+	assert.Equal(t, "select 2", batch.QueuedQueries[1].SQL)
+
+	config.SQLMiddleware = func(ctx context.Context, sql string) (context.Context, string, error) {
+		return ctx, "", errors.New("no shard key")
+	}
+	_, _, err = config.ApplySQLMiddleware(context.Background(), "select 1")
+	require.ErrorContains(t, err, "no shard key")
+}
+
+func TestWithQueryExecMode(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	simpleCtx := pgx.WithQueryExecMode(ctx, pgx.QueryExecModeSimpleProtocol)
+
+	preparedStatementCount := func() int {
+		var count int
+		err := conn.QueryRow(simpleCtx, "select count(*) from pg_prepared_statements").Scan(&count)
+		require.NoError(t, err)
+		return count
+	}
+
+	// Multiple statements are only allowed with the simple protocol.
+	_, err := conn.Exec(simpleCtx, "create temporary table with_query_exec_mode(id int); insert into with_query_exec_mode values (1)")
+	require.NoError(t, err)
+
+	var n int
+	err = conn.QueryRow(simpleCtx, "select count(*) from with_query_exec_mode where id = $1", 1).Scan(&n)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 0, preparedStatementCount())
+
+	batch := &pgx.Batch{}
+	batch.Queue("select $1::int", 2)
+	err = conn.SendBatch(simpleCtx, batch).QueryRow().Scan(&n)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 0, preparedStatementCount())
+
+	// A QueryExecMode argument takes precedence over the context.
+	err = conn.QueryRow(simpleCtx, "select $1::int", pgx.QueryExecModeCacheStatement, 3).Scan(&n)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, 1, preparedStatementCount())
+
+	ensureConnValid(t, conn)
+}
+
+func TestConnReset(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	conn := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn)
+	pgxtest.SkipCockroachDB(t, conn, "Server does not support DISCARD ALL with LISTEN")
+
+	mustExec(t, conn, "set application_name = 'pgx_reset_test'")
+	mustExec(t, conn, "create temporary table reset_test(id int)")
+	mustExec(t, conn, "listen reset_test")
+	_, err := conn.Prepare(ctx, "ps1", "select 1")
+	require.NoError(t, err)
+	mustExec(t, conn, "begin")
+
+	err = conn.Reset(ctx)
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 'I', conn.PgConn().TxStatus())
+
+	var applicationName string
+	err = conn.QueryRow(ctx, "select current_setting('application_name')").Scan(&applicationName)
+	require.NoError(t, err)
+	assert.NotEqual(t, "pgx_reset_test", applicationName)
+
+	var n int
+	err = conn.QueryRow(ctx, "select count(*) from pg_prepared_statements where name = 'ps1'").Scan(&n)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	err = conn.QueryRow(ctx, "select count(*) from pg_listening_channels()").Scan(&n)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	_, err = conn.Exec(ctx, "select * from reset_test")
+	require.Error(t, err)
+
+	// The prepared statement name can be reused as the client side state was cleared.
+	_, err = conn.Prepare(ctx, "ps1", "select 2")
+	require.NoError(t, err)
+
+	ensureConnValid(t, conn)
+}
+
+func TestExecMany(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, "create temporary table exec_many(id int primary key, name text not null)")
+
+		commandTags, err := conn.ExecMany(ctx, "insert into exec_many(id, name) values($1, $2)", [][]any{
+			{1, "a"},
+			{2, "b"},
+			{3, "c"},
+		})
+		require.NoError(t, err)
+		require.Len(t, commandTags, 3)
+		for _, ct := range commandTags {
+			assert.Equal(t, "INSERT 0 1", ct.String())
+		}
+
+		var n int
+		err = conn.QueryRow(ctx, "select count(*) from exec_many").Scan(&n)
+		require.NoError(t, err)
+		assert.Equal(t, 3, n)
+
+		commandTags, err = conn.ExecMany(ctx, "insert into exec_many(id, name) values($1, $2)", [][]any{
+			{4, "d"},
+			{1, "duplicate"},
+			{5, "e"},
+		})
+		var execManyErr *pgx.ExecManyError
+		require.ErrorAs(t, err, &execManyErr)
+		assert.Equal(t, 1, execManyErr.Index)
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		assert.Equal(t, "23505", pgErr.Code)
+		assert.Len(t, commandTags, 1)
+
+		ensureConnValid(t, conn)
+	})
+}
+
 func TestExecContextWithoutCancelation(t *testing.T) {
 	t.Parallel()
 
@@ -1002,6 +1278,41 @@ func TestDomainType(t *testing.T) {
 	})
 }
 
+func TestLoadTypeDomainWithUnregisteredBaseType(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does support domain types (https://github.com/cockroachdb/cockroach/issues/27796)")
+
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		_, err = tx.Exec(ctx, `create type pgx_domain_point as (x int4, y int4);
+create domain pgx_domain_positive_point as pgx_domain_point check ((value).x > 0 and (value).y > 0);
+create domain pgx_domain_first_quadrant as pgx_domain_positive_point;
+`)
+		require.NoError(t, err)
+
+		// Neither the composite type nor the intermediate domain is registered.
+		dt, err := conn.LoadType(ctx, "pgx_domain_first_quadrant")
+		require.NoError(t, err)
+		require.IsType(t, &pgtype.CompositeCodec{}, dt.Codec)
+		conn.TypeMap().RegisterType(dt)
+
+		type point struct {
+			X int32
+			Y int32
+		}
+
+		var p point
+		err = tx.QueryRow(ctx, `select '(1,2)'::pgx_domain_first_quadrant`).Scan(&p)
+		require.NoError(t, err)
+		require.Equal(t, point{1, 2}, p)
+	})
+}
+
 func TestLoadTypeSameNameInDifferentSchemas(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
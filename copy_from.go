@@ -3,11 +3,14 @@ package pgx
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"slices"
 
 	"github.com/jackc/pgx/v5/internal/pgio"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // CopyFromRows returns a CopyFromSource interface over the provided rows slice
@@ -91,6 +94,132 @@ func (g *copyFromFunc) Err() error {
 	return g.err
 }
 
+// CopyFromTransform returns a CopyFromSource that wraps rowSrc and replaces the value of each column with an entry in
+// transforms with the result of calling that function with the original value. transforms is keyed by column index. If
+// a transform function returns an error, the copy is aborted. The rows returned by rowSrc are not modified.
+//
+// This is useful for normalizing or converting values, e.g. trimming strings or converting units, without building an
+// intermediate copy of the data. Use CopyFromTransformReject to skip rows that cannot be transformed instead.
+func CopyFromTransform(rowSrc CopyFromSource, transforms map[int]func(value any) (any, error)) CopyFromSource {
+	return CopyFromTransformReject(rowSrc, transforms, nil)
+}
+
+// CopyFromRejectedRow describes a row that was rejected by a transform function of CopyFromTransformReject.
+type CopyFromRejectedRow struct {
+	// Index is the zero-based index of the row in the source.
+	Index int
+
+	// Values are the original values of the row.
+	Values []any
+
+	// Column is the index of the column whose transform function failed.
+	Column int
+
+	// Err is the error returned by the transform function.
+	Err error
+}
+
+// CopyFromTransformReject is like CopyFromTransform, but a row for which a transform function returns an error is
+// passed to onReject. If onReject returns nil, the row is skipped and the copy continues. Otherwise, the copy is aborted
+// with the error returned by onReject. If onReject is nil, the copy is aborted on the first error like
+// CopyFromTransform.
+//
+// onReject can record the rejected rows to report them after the copy:
+//
+//	var rejected []pgx.CopyFromRejectedRow
+//	rowSrc := pgx.CopyFromTransformReject(src, transforms, func(row pgx.CopyFromRejectedRow) error {
+//		rejected = append(rejected, row)
+//		return nil
+//	})
+func CopyFromTransformReject(rowSrc CopyFromSource, transforms map[int]func(value any) (any, error), onReject func(row CopyFromRejectedRow) error) CopyFromSource {
+	return &copyFromTransform{rowSrc: rowSrc, transforms: transforms, onReject: onReject, index: -1}
+}
+
+type copyFromTransform struct {
+	rowSrc     CopyFromSource
+	transforms map[int]func(value any) (any, error)
+	onReject   func(row CopyFromRejectedRow) error
+	index      int
+	valueRow   []any
+	err        error
+}
+
+func (t *copyFromTransform) Next() bool {
+	for t.err == nil && t.rowSrc.Next() {
+		t.index++
+
+		values, err := t.rowSrc.Values()
+		if err != nil {
+			t.err = err
+			return false
+		}
+
+		column, err := t.transform(values)
+		if err == nil {
+			return true
+		}
+
+		var transformErr *copyFromTransformError
+		if t.onReject == nil || !errors.As(err, &transformErr) {
+			t.err = err
+			return false
+		}
+
+		err = t.onReject(CopyFromRejectedRow{Index: t.index, Values: slices.Clone(values), Column: column, Err: transformErr.err})
+		if err != nil {
+			t.err = err
+			return false
+		}
+	}
+
+	return false
+}
+
+// transform sets t.valueRow to the transformed values. It returns the index of the column that failed.
+func (t *copyFromTransform) transform(values []any) (int, error) {
+	t.valueRow = append(t.valueRow[:0], values...)
+	for i, transform := range t.transforms {
+		if i < 0 || i >= len(t.valueRow) {
+			return i, fmt.Errorf("transform for column %d but row has %d values", i, len(t.valueRow))
+		}
+
+		v, err := transform(t.valueRow[i])
+		if err != nil {
+			return i, &copyFromTransformError{column: i, err: err}
+		}
+		t.valueRow[i] = v
+	}
+
+	return 0, nil
+}
+
+func (t *copyFromTransform) Values() ([]any, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	return t.valueRow, nil
+}
+
+func (t *copyFromTransform) Err() error {
+	if t.err != nil {
+		return t.err
+	}
+	return t.rowSrc.Err()
+}
+
+type copyFromTransformError struct {
+	column int
+	err    error
+}
+
+func (e *copyFromTransformError) Error() string {
+	return fmt.Sprintf("transform column %d: %v", e.column, e.err)
+}
+
+func (e *copyFromTransformError) Unwrap() error {
+	return e.err
+}
+
 // CopyFromSource is the interface used by *Conn.CopyFrom as the source for copy data.
 type CopyFromSource interface {
 	// Next returns true if there is another row and makes the next row data
@@ -113,6 +242,13 @@ type copyFrom struct {
 	rowSrc        CopyFromSource
 	readerErrChan chan error
 	mode          QueryExecMode
+
+	// copyBinaryErrs are the errors returned by Map.CheckCopyBinary for the type of each column. A column whose type does
+	// not support the COPY binary format can still be copied if all its values are NULL.
+	copyBinaryErrs []error
+	// copyBinaryErr is the error for the first non-NULL value of such a column. It is set by the goroutine that encodes
+	// the rows.
+	copyBinaryErr error
 }
 
 func (ct *copyFrom) run(ctx context.Context) (int64, error) {
@@ -158,6 +294,13 @@ func (ct *copyFrom) run(ctx context.Context) (int64, error) {
 		return 0, fmt.Errorf("unknown QueryExecMode: %v", ct.mode)
 	}
 
+	ct.copyBinaryErrs = make([]error, len(sd.Fields))
+	for i, fd := range sd.Fields {
+		if err := ct.conn.typeMap.CheckCopyBinary(fd.DataTypeOID); err != nil {
+			ct.copyBinaryErrs[i] = fmt.Errorf("column %q: %w", ct.columnNames[i], err)
+		}
+	}
+
 	r, w := io.Pipe()
 	doneChan := make(chan struct{})
 
@@ -176,6 +319,10 @@ func (ct *copyFrom) run(ctx context.Context) (int64, error) {
 			var err error
 			moreRows, buf, err = ct.buildCopyBuf(buf, sd)
 			if err != nil {
+				var unsupportedErr *pgtype.CopyBinaryUnsupportedError
+				if errors.As(err, &unsupportedErr) {
+					ct.copyBinaryErr = err
+				}
 				w.CloseWithError(err)
 				return
 			}
@@ -204,6 +351,12 @@ func (ct *copyFrom) run(ctx context.Context) (int64, error) {
 	r.Close()
 	<-doneChan
 
+	// The server only reports that the copy failed. Return the more useful error for a type that does not support the
+	// COPY binary format.
+	if ct.copyBinaryErr != nil {
+		err = ct.copyBinaryErr
+	}
+
 	if ct.conn.copyFromTracer != nil {
 		ct.conn.copyFromTracer.TraceCopyFromEnd(ctx, ct.conn, TraceCopyFromEndData{
 			CommandTag: commandTag,
@@ -232,9 +385,19 @@ func (ct *copyFrom) buildCopyBuf(buf []byte, sd *pgconn.StatementDescription) (b
 
 		buf = pgio.AppendInt16(buf, int16(len(ct.columnNames)))
 		for i, val := range values {
+			if copyBinaryErr := ct.copyBinaryErrs[i]; copyBinaryErr != nil {
+				// NULL is encoded the same way for every type.
+				textBuf, err := ct.conn.typeMap.Encode(sd.Fields[i].DataTypeOID, TextFormatCode, val, nil)
+				if err != nil || textBuf != nil {
+					return false, nil, copyBinaryErr
+				}
+				buf = pgio.AppendInt32(buf, -1)
+				continue
+			}
+
 			buf, err = encodeCopyValue(ct.conn.typeMap, buf, sd.Fields[i].DataTypeOID, val)
 			if err != nil {
-				return false, nil, err
+				return false, nil, ct.encodeError(i, sd.Fields[i].DataTypeOID, err)
 			}
 		}
 
@@ -254,11 +417,19 @@ func (ct *copyFrom) buildCopyBuf(buf []byte, sd *pgconn.StatementDescription) (b
 	return false, buf, nil
 }
 
+func (ct *copyFrom) encodeError(columnIdx int, oid uint32, err error) error {
+	typeName := fmt.Sprintf("OID %d", oid)
+	if dt, ok := ct.conn.typeMap.TypeForOID(oid); ok {
+		typeName = dt.Name
+	}
+	return fmt.Errorf("column %q (%s): %w", ct.columnNames[columnIdx], typeName, err)
+}
+
 // CopyFrom uses the PostgreSQL copy protocol to perform bulk data insertion. It returns the number of rows copied and
 // an error.
 //
 // CopyFrom requires all values use the binary format. A pgtype.Type that supports the binary format must be registered
-// for the type of each column. Almost all types implemented by pgx support the binary format.
+// for the type of each column that has a non-NULL value. Almost all types implemented by pgx support the binary format.
 //
 // Even though enum types appear to be strings they still must be registered to use with CopyFrom. This can be done with
 // Conn.LoadType and pgtype.Map.RegisterType.
@@ -269,7 +440,7 @@ func (c *Conn) CopyFrom(ctx context.Context, tableName Identifier, columnNames [
 		columnNames:   columnNames,
 		rowSrc:        rowSrc,
 		readerErrChan: make(chan error),
-		mode:          c.config.DefaultQueryExecMode,
+		mode:          c.defaultQueryExecMode(ctx),
 	}
 
 	return ct.run(ctx)
@@ -2,14 +2,17 @@ package pgx_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxtest"
 	"github.com/stretchr/testify/require"
 )
@@ -198,6 +201,147 @@ func TestConnCopyFromSmall(t *testing.T) {
 	ensureConnValid(t, conn)
 }
 
+func TestConnCopyFromBinaryUnsupportedType(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	conn := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn)
+	pgxtest.SkipCockroachDB(t, conn, "Server does not support jsonpath")
+
+	mustExec(t, conn, `create temporary table foo(
+		a int4,
+		b jsonpath
+	)`)
+
+	_, err := conn.CopyFrom(ctx, pgx.Identifier{"foo"}, []string{"a", "b"}, pgx.CopyFromRows([][]any{{int32(1), "$.a"}}))
+	var unsupportedErr *pgtype.CopyBinaryUnsupportedError
+	require.ErrorAs(t, err, &unsupportedErr)
+	require.Contains(t, err.Error(), `column "b"`)
+	require.Equal(t, "jsonpath", unsupportedErr.TypeName)
+
+	// A column of a type that does not support the binary format can be copied if all its values are NULL.
+	copyCount, err := conn.CopyFrom(ctx, pgx.Identifier{"foo"}, []string{"a", "b"}, pgx.CopyFromRows([][]any{{int32(1), nil}, {int32(2), pgtype.Text{}}}))
+	require.NoError(t, err)
+	require.EqualValues(t, 2, copyCount)
+
+	ensureConnValid(t, conn)
+}
+
+func TestConnCopyFromTransform(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	conn := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn)
+
+	mustExec(t, conn, `create temporary table foo(
+		a int4,
+		b text
+	)`)
+
+	inputRows := [][]any{
+		{int32(1), "  abc  "},
+		{int32(2), nil},
+	}
+
+	rowSrc := pgx.CopyFromTransform(pgx.CopyFromRows(inputRows), map[int]func(any) (any, error){
+		0: func(v any) (any, error) { return v.(int32) * 10, nil },
+		1: func(v any) (any, error) {
+			if s, ok := v.(string); ok {
+				return strings.TrimSpace(s), nil
+			}
+			return v, nil
+		},
+	})
+
+	copyCount, err := conn.CopyFrom(ctx, pgx.Identifier{"foo"}, []string{"a", "b"}, rowSrc)
+	require.NoError(t, err)
+	require.EqualValues(t, len(inputRows), copyCount)
+
+	// The source rows must not be modified.
+	require.Equal(t, "  abc  ", inputRows[0][1])
+
+	rows, _ := conn.Query(ctx, "select a, b from foo order by a")
+	var outputRows [][]any
+	for rows.Next() {
+		row, err := rows.Values()
+		require.NoError(t, err)
+		outputRows = append(outputRows, row)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, [][]any{{int32(10), "abc"}, {int32(20), nil}}, outputRows)
+
+	transformErr := errors.New("bad value")
+	rowSrc = pgx.CopyFromTransform(pgx.CopyFromRows(inputRows), map[int]func(any) (any, error){
+		1: func(v any) (any, error) { return nil, transformErr },
+	})
+	_, err = conn.CopyFrom(ctx, pgx.Identifier{"foo"}, []string{"a", "b"}, rowSrc)
+	require.ErrorIs(t, err, transformErr)
+
+	ensureConnValid(t, conn)
+}
+
+func TestConnCopyFromTransformReject(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	conn := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn)
+
+	mustExec(t, conn, `create temporary table foo(
+		a int4,
+		b text
+	)`)
+
+	inputRows := [][]any{
+		{int32(1), "a"},
+		{int32(2), ""},
+		{int32(3), "c"},
+	}
+	transformErr := errors.New("empty value")
+	transforms := map[int]func(any) (any, error){
+		1: func(v any) (any, error) {
+			if v == "" {
+				return nil, transformErr
+			}
+			return v, nil
+		},
+	}
+
+	var rejected []pgx.CopyFromRejectedRow
+	rowSrc := pgx.CopyFromTransformReject(pgx.CopyFromRows(inputRows), transforms, func(row pgx.CopyFromRejectedRow) error {
+		rejected = append(rejected, row)
+		return nil
+	})
+	copyCount, err := conn.CopyFrom(ctx, pgx.Identifier{"foo"}, []string{"a", "b"}, rowSrc)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, copyCount)
+	require.Equal(t, []pgx.CopyFromRejectedRow{{Index: 1, Values: []any{int32(2), ""}, Column: 1, Err: transformErr}}, rejected)
+
+	var values []int32
+	rows, _ := conn.Query(ctx, "select a from foo order by a")
+	values, err = pgx.CollectRows(rows, pgx.RowTo[int32])
+	require.NoError(t, err)
+	require.Equal(t, []int32{1, 3}, values)
+
+	// onReject can abort the copy.
+	abortErr := errors.New("too many rejected rows")
+	rowSrc = pgx.CopyFromTransformReject(pgx.CopyFromRows(inputRows), transforms, func(row pgx.CopyFromRejectedRow) error {
+		return abortErr
+	})
+	_, err = conn.CopyFrom(ctx, pgx.Identifier{"foo"}, []string{"a", "b"}, rowSrc)
+	require.ErrorIs(t, err, abortErr)
+
+	ensureConnValid(t, conn)
+}
+
 func TestConnCopyFromSliceSmall(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,63 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+)
+
+// ArgEncoding describes how an argument to a query is encoded. See Conn.DebugEncode.
+type ArgEncoding struct {
+	Index      int    // index of the argument
+	GoType     string // Go type of the argument, e.g. "int64" or "<nil>"
+	OID        uint32 // OID of the parameter as described by the server
+	TypeName   string // name of the PostgreSQL type of the parameter if it is registered with the type map
+	FormatCode int16  // format code the argument is encoded with
+	Len        int    // length of the encoded argument in bytes, or -1 for NULL
+	Err        error  // error encoding the argument, e.g. when no encode plan can be found
+}
+
+// DebugEncode describes sql on the server and encodes args as a query with the default query execution mode would,
+// but does not execute sql. It returns how each argument is encoded or why it could not be encoded. This helps to debug
+// errors such as "unable to encode" without trial and error against the server.
+//
+// An error is returned only if sql cannot be described or if the number of args does not match the number of
+// parameters. Errors encoding an argument are reported in the Err field of its ArgEncoding.
+func (c *Conn) DebugEncode(ctx context.Context, sql string, args ...any) ([]ArgEncoding, error) {
+	sd := c.preparedStatements[sql]
+	if sd == nil {
+		var err error
+		sd, err = c.Prepare(ctx, "", sql)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(sd.ParamOIDs) != len(args) {
+		return nil, fmt.Errorf("expected %d arguments, got %d", len(sd.ParamOIDs), len(args))
+	}
+
+	var eqb ExtendedQueryBuilder
+	encodings := make([]ArgEncoding, len(args))
+	for i, arg := range args {
+		oid := sd.ParamOIDs[i]
+		encodings[i] = ArgEncoding{Index: i, GoType: fmt.Sprintf("%T", arg), OID: oid}
+		if t, ok := c.typeMap.TypeForOID(oid); ok {
+			encodings[i].TypeName = t.Name
+		}
+
+		err := eqb.appendParam(c.typeMap, oid, -1, arg)
+		if err != nil {
+			encodings[i].Err = err
+			continue
+		}
+
+		encodings[i].FormatCode = eqb.ParamFormats[len(eqb.ParamFormats)-1]
+		if v := eqb.ParamValues[len(eqb.ParamValues)-1]; v != nil {
+			encodings[i].Len = len(v)
+		} else {
+			encodings[i].Len = -1
+		}
+	}
+
+	return encodings, nil
+}
@@ -0,0 +1,42 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnDebugEncode(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	defaultConnTestRunner.RunTest(ctx, t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		type unsupported struct{}
+
+		encodings, err := conn.DebugEncode(ctx, "select $1::int4, $2::text, $3::int8, $4::point", int32(42), "foo", nil, unsupported{})
+		require.NoError(t, err)
+		require.Len(t, encodings, 4)
+
+		assert.Equal(t, pgx.ArgEncoding{Index: 0, GoType: "int32", OID: pgtype.Int4OID, TypeName: "int4", FormatCode: pgx.BinaryFormatCode, Len: 4}, encodings[0])
+		assert.Equal(t, pgx.ArgEncoding{Index: 1, GoType: "string", OID: pgtype.TextOID, TypeName: "text", FormatCode: pgx.TextFormatCode, Len: 3}, encodings[1])
+		assert.Equal(t, pgx.ArgEncoding{Index: 2, GoType: "<nil>", OID: pgtype.Int8OID, TypeName: "int8", FormatCode: pgx.BinaryFormatCode, Len: -1}, encodings[2])
+
+		assert.Equal(t, 3, encodings[3].Index)
+		assert.Equal(t, "pgx_test.unsupported", encodings[3].GoType)
+		assert.EqualValues(t, pgtype.PointOID, encodings[3].OID)
+		assert.Error(t, encodings[3].Err)
+
+		_, err = conn.DebugEncode(ctx, "select $1::int4", 1, 2)
+		require.EqualError(t, err, "expected 1 arguments, got 2")
+
+		// The statement was not executed.
+		ensureConnValid(t, conn)
+	})
+}
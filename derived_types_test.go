@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 )
 
@@ -38,3 +39,37 @@ create type dtype_test as (
 		require.Equal(t, types[5].Name, "dtype_test")
 	})
 }
+
+func TestLoadTypeLazy(t *testing.T) {
+	skipCockroachDB(t, "Server does not support composite types (see https://github.com/cockroachdb/cockroach/issues/27792)")
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		_, err := conn.Exec(ctx, `
+drop type if exists lazy_type_test;
+create type lazy_type_test as (
+  a text,
+  b int4
+);`)
+		require.NoError(t, err)
+		defer conn.Exec(ctx, "drop type lazy_type_test")
+
+		conn.TypeMap().LoadTypeLazy("lazy_type_test")
+		conn.TypeMap().LoadTypeLazy("lazy_type_test_missing")
+
+		_, ok := conn.TypeMap().TypeForName("lazy_type_test")
+		require.False(t, ok)
+
+		// Preparing a statement with an unregistered type loads the pending types.
+		var a string
+		var b int32
+		err = conn.QueryRow(ctx, "select row('foo', 42)::lazy_type_test", pgx.QueryExecModeDescribeExec).Scan(pgtype.CompositeFields{&a, &b})
+		require.NoError(t, err)
+		require.Equal(t, "foo", a)
+		require.EqualValues(t, 42, b)
+
+		_, ok = conn.TypeMap().TypeForName("lazy_type_test")
+		require.True(t, ok)
+		require.Empty(t, conn.TypeMap().PendingLazyTypes())
+		require.True(t, conn.TypeMap().LazyTypeMissing("lazy_type_test_missing"))
+	})
+}
@@ -189,6 +189,7 @@ implemented on top of pgconn. The Conn.PgConn() method can be used to access thi
 PgBouncer
 
 By default pgx automatically uses prepared statements. Prepared statements are incompatible with PgBouncer. This can be
-disabled by setting a different QueryExecMode in ConnConfig.DefaultQueryExecMode.
+disabled by setting a different QueryExecMode in ConnConfig.DefaultQueryExecMode. The mode can also be changed for
+individual operations with WithQueryExecMode.
 */
 package pgx
@@ -0,0 +1,115 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// EnumRegistry checks that the Go constants of enum types match the labels of the corresponding PostgreSQL enum
+// types. Register the Go values of each enum with RegisterEnum and call Load after connecting (e.g. in
+// pgxpool.Config.AfterConnect). Load fails if a Go value is not a label of the PostgreSQL enum. This catches
+// differences between the Go code and the database schema at startup instead of when a query fails.
+//
+// Load also registers the enum types with the connection's type map. Values that are not labels of the enum are then
+// rejected by the client with an error that lists the valid labels.
+//
+// An EnumRegistry must not be modified concurrently with Load. It is safe to call Load concurrently.
+type EnumRegistry struct {
+	// Strict makes Load also fail if a PostgreSQL enum has a label that has no Go value. This detects labels that were
+	// added to the database but not to the Go code.
+	Strict bool
+
+	typeNames []string
+	values    map[string][]string
+}
+
+// RegisterEnum registers values as the Go values of the PostgreSQL enum type typeName. typeName may be schema
+// qualified. Registering the same type again adds to its values.
+func RegisterEnum[T ~string](r *EnumRegistry, typeName string, values ...T) {
+	if r.values == nil {
+		r.values = make(map[string][]string)
+	}
+
+	if _, ok := r.values[typeName]; !ok {
+		r.typeNames = append(r.typeNames, typeName)
+	}
+
+	for _, v := range values {
+		r.values[typeName] = append(r.values[typeName], string(v))
+	}
+}
+
+// Load loads the labels of the registered enum types, checks the registered Go values against them, and registers the
+// enum types and their array types with conn's type map.
+func (r *EnumRegistry) Load(ctx context.Context, conn *Conn) error {
+	for _, typeName := range r.typeNames {
+		err := r.loadEnum(ctx, conn, typeName)
+		if err != nil {
+			return fmt.Errorf("enum %s: %w", typeName, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *EnumRegistry) loadEnum(ctx context.Context, conn *Conn, typeName string) error {
+	var oid, arrayOID uint32
+	var name, nspName, arrayName string
+	var labels []string
+	err := conn.QueryRow(ctx,
+		`select t.oid, t.typname, n.nspname, t.typarray, coalesce(a.typname, ''),
+	array(select e.enumlabel from pg_catalog.pg_enum e where e.enumtypid = t.oid order by e.enumsortorder)
+from pg_catalog.pg_type t
+	join pg_catalog.pg_namespace n on n.oid = t.typnamespace
+	left join pg_catalog.pg_type a on a.oid = t.typarray
+where t.oid = $1::text::regtype and t.typtype = 'e'`,
+		typeName,
+	).Scan(&oid, &name, &nspName, &arrayOID, &arrayName, &labels)
+	if err != nil {
+		if errors.Is(err, ErrNoRows) {
+			return errors.New("not an enum type")
+		}
+		return err
+	}
+
+	var missing []string
+	for _, v := range r.values[typeName] {
+		if !slices.Contains(labels, v) {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("Go values are not labels of the PostgreSQL enum: %s", strings.Join(missing, ", "))
+	}
+
+	if r.Strict {
+		var unregistered []string
+		for _, label := range labels {
+			if !slices.Contains(r.values[typeName], label) {
+				unregistered = append(unregistered, label)
+			}
+		}
+		if len(unregistered) > 0 {
+			return fmt.Errorf("PostgreSQL enum labels have no Go value: %s", strings.Join(unregistered, ", "))
+		}
+	}
+
+	// Register the types by name and by schema qualified name like LoadTypes.
+	m := conn.TypeMap()
+	codec := &pgtype.EnumCodec{Labels: labels}
+	enumType := &pgtype.Type{Name: name, OID: oid, Codec: codec}
+	m.RegisterType(enumType)
+	m.RegisterType(&pgtype.Type{Name: nspName + "." + name, OID: oid, Codec: codec})
+	if arrayOID != 0 {
+		arrayCodec := &pgtype.ArrayCodec{ElementType: enumType}
+		m.RegisterType(&pgtype.Type{Name: arrayName, OID: arrayOID, Codec: arrayCodec})
+		m.RegisterType(&pgtype.Type{Name: nspName + "." + arrayName, OID: arrayOID, Codec: arrayCodec})
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type enumRegistryColor string
+
+const (
+	enumRegistryRed   enumRegistryColor = "red"
+	enumRegistryGreen enumRegistryColor = "green"
+	enumRegistryBlue  enumRegistryColor = "blue"
+)
+
+func TestEnumRegistry(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	defaultConnTestRunner.RunTest(ctx, t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		_, err = tx.Exec(ctx, `create type enum_registry_color as enum ('red', 'green', 'blue')`)
+		require.NoError(t, err)
+
+		registry := &pgx.EnumRegistry{}
+		pgx.RegisterEnum(registry, "enum_registry_color", enumRegistryRed, enumRegistryGreen)
+		err = registry.Load(ctx, conn)
+		require.NoError(t, err)
+
+		var c enumRegistryColor
+		err = tx.QueryRow(ctx, "select $1::enum_registry_color", enumRegistryGreen).Scan(&c)
+		require.NoError(t, err)
+		require.Equal(t, enumRegistryGreen, c)
+
+		var colors []enumRegistryColor
+		err = tx.QueryRow(ctx, "select $1::enum_registry_color[]", []enumRegistryColor{enumRegistryRed, enumRegistryBlue}).Scan(&colors)
+		require.NoError(t, err)
+		require.Equal(t, []enumRegistryColor{enumRegistryRed, enumRegistryBlue}, colors)
+
+		_, err = tx.Exec(ctx, "select $1::enum_registry_color", enumRegistryColor("purple"))
+		require.ErrorContains(t, err, `invalid input value for enum enum_registry_color: "purple" (valid values: red, green, blue)`)
+
+		strict := &pgx.EnumRegistry{Strict: true}
+		pgx.RegisterEnum(strict, "enum_registry_color", enumRegistryRed, enumRegistryGreen)
+		err = strict.Load(ctx, conn)
+		require.EqualError(t, err, "enum enum_registry_color: PostgreSQL enum labels have no Go value: blue")
+
+		pgx.RegisterEnum(strict, "enum_registry_color", enumRegistryBlue)
+		err = strict.Load(ctx, conn)
+		require.NoError(t, err)
+
+		invalid := &pgx.EnumRegistry{}
+		pgx.RegisterEnum(invalid, "enum_registry_color", enumRegistryRed, "purple", "orange")
+		err = invalid.Load(ctx, conn)
+		require.EqualError(t, err, "enum enum_registry_color: Go values are not labels of the PostgreSQL enum: purple, orange")
+
+		notEnum := &pgx.EnumRegistry{}
+		pgx.RegisterEnum(notEnum, "text", "foo")
+		err = notEnum.Load(ctx, conn)
+		require.EqualError(t, err, "enum text: not an enum type")
+	})
+}
@@ -0,0 +1,186 @@
+package pgx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ExplainOptions configures Explain. See the PostgreSQL EXPLAIN documentation for the meaning of each option.
+type ExplainOptions struct {
+	// Analyze executes the statement and includes actual timing and row counts. The statement is executed for real, so
+	// wrap statements with side effects in a transaction that is rolled back.
+	Analyze bool
+
+	// Verbose includes additional information such as the output columns of each node.
+	Verbose bool
+
+	// Buffers includes buffer usage. Buffer usage is only reported for executed statements prior to PostgreSQL 13.
+	Buffers bool
+
+	// Settings includes configuration parameters that affect planning and differ from the built-in default. Requires
+	// PostgreSQL 12 or later.
+	Settings bool
+
+	// WAL includes WAL record generation. Requires Analyze and PostgreSQL 13 or later.
+	WAL bool
+}
+
+// ExplainResult is the parsed output of EXPLAIN.
+type ExplainResult struct {
+	// Plan is the root node of the plan tree.
+	Plan *ExplainNode
+
+	// PlanningTime and ExecutionTime are in milliseconds. ExecutionTime is only set when ExplainOptions.Analyze is true.
+	PlanningTime  float64
+	ExecutionTime float64
+
+	// Settings contains the configuration parameters reported when ExplainOptions.Settings is true.
+	Settings map[string]string
+
+	// Triggers contains the triggers that were fired when ExplainOptions.Analyze is true.
+	Triggers []ExplainTrigger
+}
+
+// ExplainTrigger describes a trigger fired while executing an explained statement.
+type ExplainTrigger struct {
+	TriggerName    string  `json:"Trigger Name"`
+	ConstraintName string  `json:"Constraint Name"`
+	Relation       string  `json:"Relation"`
+	Time           float64 `json:"Time"`
+	Calls          int64   `json:"Calls"`
+}
+
+// ExplainNode is a node in a plan tree. Commonly used properties have fields. All properties of the node, including those
+// without a field, are in Properties.
+type ExplainNode struct {
+	NodeType           string `json:"Node Type"`
+	ParentRelationship string `json:"Parent Relationship"`
+	RelationName       string `json:"Relation Name"`
+	Schema             string `json:"Schema"`
+	Alias              string `json:"Alias"`
+	IndexName          string `json:"Index Name"`
+	JoinType           string `json:"Join Type"`
+	Strategy           string `json:"Strategy"`
+
+	// Costs are in the planner's arbitrary units. PlanRows is the estimated number of rows and PlanWidth is the
+	// estimated average row width in bytes.
+	StartupCost float64 `json:"Startup Cost"`
+	TotalCost   float64 `json:"Total Cost"`
+	PlanRows    float64 `json:"Plan Rows"`
+	PlanWidth   int64   `json:"Plan Width"`
+
+	// Actual values are only set when ExplainOptions.Analyze is true. Times are in milliseconds and are per loop.
+	ActualStartupTime float64 `json:"Actual Startup Time"`
+	ActualTotalTime   float64 `json:"Actual Total Time"`
+	ActualRows        float64 `json:"Actual Rows"`
+	ActualLoops       float64 `json:"Actual Loops"`
+
+	Output              []string `json:"Output"`
+	Filter              string   `json:"Filter"`
+	IndexCond           string   `json:"Index Cond"`
+	RowsRemovedByFilter float64  `json:"Rows Removed by Filter"`
+
+	// Buffer counts are only set when ExplainOptions.Buffers is true. They are in blocks.
+	SharedHitBlocks     int64 `json:"Shared Hit Blocks"`
+	SharedReadBlocks    int64 `json:"Shared Read Blocks"`
+	SharedDirtiedBlocks int64 `json:"Shared Dirtied Blocks"`
+	SharedWrittenBlocks int64 `json:"Shared Written Blocks"`
+	LocalHitBlocks      int64 `json:"Local Hit Blocks"`
+	LocalReadBlocks     int64 `json:"Local Read Blocks"`
+	LocalDirtiedBlocks  int64 `json:"Local Dirtied Blocks"`
+	LocalWrittenBlocks  int64 `json:"Local Written Blocks"`
+	TempReadBlocks      int64 `json:"Temp Read Blocks"`
+	TempWrittenBlocks   int64 `json:"Temp Written Blocks"`
+
+	// Plans are the child nodes.
+	Plans []*ExplainNode `json:"Plans"`
+
+	// Properties contains every property of the node as decoded by encoding/json except for Plans.
+	Properties map[string]any `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *ExplainNode) UnmarshalJSON(b []byte) error {
+	type explainNode ExplainNode
+	var node explainNode
+	err := json.Unmarshal(b, &node)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(b, &node.Properties)
+	if err != nil {
+		return err
+	}
+	delete(node.Properties, "Plans")
+
+	*n = ExplainNode(node)
+	return nil
+}
+
+// explainResultJSON is the JSON representation of ExplainResult.
+type explainResultJSON struct {
+	Plan          *ExplainNode      `json:"Plan"`
+	PlanningTime  float64           `json:"Planning Time"`
+	ExecutionTime float64           `json:"Execution Time"`
+	Settings      map[string]string `json:"Settings"`
+	Triggers      []ExplainTrigger  `json:"Triggers"`
+}
+
+// ParseExplainJSON parses the output of EXPLAIN (FORMAT JSON).
+func ParseExplainJSON(b []byte) (*ExplainResult, error) {
+	var results []explainResultJSON
+	err := json.Unmarshal(b, &results)
+	if err != nil {
+		return nil, fmt.Errorf("parse explain: %w", err)
+	}
+	if len(results) != 1 || results[0].Plan == nil {
+		return nil, fmt.Errorf("parse explain: expected 1 plan, got %d", len(results))
+	}
+
+	r := results[0]
+	return &ExplainResult{
+		Plan:          r.Plan,
+		PlanningTime:  r.PlanningTime,
+		ExecutionTime: r.ExecutionTime,
+		Settings:      r.Settings,
+		Triggers:      r.Triggers,
+	}, nil
+}
+
+// Explain runs EXPLAIN (FORMAT JSON) for sql with args and returns the parsed plan. options may be nil. See
+// ExplainOptions.Analyze for the consequences of analyzing a statement.
+func (c *Conn) Explain(ctx context.Context, sql string, args []any, options *ExplainOptions) (*ExplainResult, error) {
+	if options == nil {
+		options = &ExplainOptions{}
+	}
+
+	explainOptions := []string{"format json"}
+	if options.Analyze {
+		explainOptions = append(explainOptions, "analyze")
+	}
+	if options.Verbose {
+		explainOptions = append(explainOptions, "verbose")
+	}
+	if options.Buffers {
+		explainOptions = append(explainOptions, "buffers")
+	}
+	if options.Settings {
+		explainOptions = append(explainOptions, "settings")
+	}
+	if options.WAL {
+		explainOptions = append(explainOptions, "wal")
+	}
+
+	explainSQL := "explain (" + strings.Join(explainOptions, ", ") + ") " + sql
+
+	var buf []byte
+	err := c.QueryRow(ctx, explainSQL, args...).Scan(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseExplainJSON(buf)
+}
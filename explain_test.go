@@ -0,0 +1,131 @@
+package pgx_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExplainJSON(t *testing.T) {
+	t.Parallel()
+
+	src := `[
+  {
+    "Plan": {
+      "Node Type": "Nested Loop",
+      "Parallel Aware": false,
+      "Join Type": "Inner",
+      "Startup Cost": 0.00,
+      "Total Cost": 12.50,
+      "Plan Rows": 10,
+      "Plan Width": 8,
+      "Actual Startup Time": 0.010,
+      "Actual Total Time": 0.120,
+      "Actual Rows": 10,
+      "Actual Loops": 1,
+      "Shared Hit Blocks": 3,
+      "Plans": [
+        {
+          "Node Type": "Seq Scan",
+          "Parent Relationship": "Outer",
+          "Relation Name": "widgets",
+          "Alias": "w",
+          "Startup Cost": 0.00,
+          "Total Cost": 1.10,
+          "Plan Rows": 10,
+          "Plan Width": 4,
+          "Filter": "(id > 0)",
+          "Rows Removed by Filter": 2
+        },
+        {
+          "Node Type": "Index Scan",
+          "Parent Relationship": "Inner",
+          "Index Name": "parts_pkey",
+          "Relation Name": "parts",
+          "Index Cond": "(id = w.part_id)",
+          "Startup Cost": 0.15,
+          "Total Cost": 1.10,
+          "Plan Rows": 1,
+          "Plan Width": 4
+        }
+      ]
+    },
+    "Planning Time": 0.250,
+    "Triggers": [],
+    "Execution Time": 0.180
+  }
+]`
+
+	result, err := pgx.ParseExplainJSON([]byte(src))
+	require.NoError(t, err)
+
+	assert.Equal(t, 0.25, result.PlanningTime)
+	assert.Equal(t, 0.18, result.ExecutionTime)
+
+	root := result.Plan
+	require.NotNil(t, root)
+	assert.Equal(t, "Nested Loop", root.NodeType)
+	assert.Equal(t, "Inner", root.JoinType)
+	assert.Equal(t, 12.5, root.TotalCost)
+	assert.EqualValues(t, 10, root.PlanRows)
+	assert.EqualValues(t, 8, root.PlanWidth)
+	assert.Equal(t, 0.12, root.ActualTotalTime)
+	assert.EqualValues(t, 3, root.SharedHitBlocks)
+	assert.Equal(t, false, root.Properties["Parallel Aware"])
+	assert.NotContains(t, root.Properties, "Plans")
+
+	require.Len(t, root.Plans, 2)
+	assert.Equal(t, "Seq Scan", root.Plans[0].NodeType)
+	assert.Equal(t, "widgets", root.Plans[0].RelationName)
+	assert.Equal(t, "(id > 0)", root.Plans[0].Filter)
+	assert.EqualValues(t, 2, root.Plans[0].RowsRemovedByFilter)
+	assert.Equal(t, "Index Scan", root.Plans[1].NodeType)
+	assert.Equal(t, "parts_pkey", root.Plans[1].IndexName)
+	assert.Equal(t, "(id = w.part_id)", root.Plans[1].IndexCond)
+
+	_, err = pgx.ParseExplainJSON([]byte(`[]`))
+	require.Error(t, err)
+}
+
+func TestConnExplain(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		result, err := conn.Explain(ctx, "select n from generate_series(1, $1::int) n where n > 2", []any{10}, nil)
+		require.NoError(t, err)
+		require.NotNil(t, result.Plan)
+		assert.Equal(t, "Function Scan", result.Plan.NodeType)
+		assert.Greater(t, result.Plan.TotalCost, 0.0)
+		assert.Zero(t, result.Plan.ActualLoops)
+		assert.Zero(t, result.ExecutionTime)
+
+		result, err = conn.Explain(ctx, "select n from generate_series(1, $1::int) n where n > 2", []any{10}, &pgx.ExplainOptions{Analyze: true, Verbose: true, Buffers: true})
+		require.NoError(t, err)
+		require.NotNil(t, result.Plan)
+		assert.EqualValues(t, 8, result.Plan.ActualRows)
+		assert.EqualValues(t, 1, result.Plan.ActualLoops)
+		assert.EqualValues(t, 2, result.Plan.RowsRemovedByFilter)
+		assert.NotEmpty(t, result.Plan.Output)
+		assert.Greater(t, result.ExecutionTime, 0.0)
+	})
+}
+
+func TestConnExplainError(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn)
+
+	_, err := conn.Explain(context.Background(), "select * from table_that_does_not_exist", nil, nil)
+	require.Error(t, err)
+	ensureConnValid(t, conn)
+}
@@ -427,6 +427,22 @@ var queryPool = &pool[*Query]{
 	},
 }
 
+// MaxPlaceholder returns the highest placeholder number in sql. e.g. 2 for "select $1, $2". It returns 0 if sql does
+// not have any placeholders. Placeholders in string literals, quoted identifiers, and comments are ignored.
+func MaxPlaceholder(sql string) int {
+	query := queryPool.get()
+	query.init(sql)
+	defer queryPool.put(query)
+
+	max := 0
+	for _, part := range query.Parts {
+		if n, ok := part.(int); ok && n > max {
+			max = n
+		}
+	}
+	return max
+}
+
 // SanitizeSQL replaces placeholder values with args. It quotes and escapes args
 // as necessary. This function is only safe when standard_conforming_strings is
 // on.
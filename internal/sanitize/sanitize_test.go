@@ -120,6 +120,27 @@ func TestNewQuery(t *testing.T) {
 	}
 }
 
+func TestMaxPlaceholder(t *testing.T) {
+	for i, tt := range []struct {
+		sql      string
+		expected int
+	}{
+		{sql: "select 42", expected: 0},
+		{sql: "select $1", expected: 1},
+		{sql: "select $2, $1, $2", expected: 2},
+		{sql: "select $12", expected: 12},
+		{sql: "select '$3', $1", expected: 1},
+		{sql: `select "$3", $1`, expected: 1},
+		{sql: "select $1 -- $3", expected: 1},
+		{sql: "select $1 /* $3 */", expected: 1},
+		{sql: "select $$", expected: 0},
+	} {
+		if actual := sanitize.MaxPlaceholder(tt.sql); actual != tt.expected {
+			t.Errorf("%d. expected %d but got %d", i, tt.expected, actual)
+		}
+	}
+}
+
 func TestQuerySanitize(t *testing.T) {
 	successfulTests := []struct {
 		query    sanitize.Query
@@ -0,0 +1,148 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// MigrationLockHolder describes a session that holds a migration lock.
+type MigrationLockHolder struct {
+	PID             uint32
+	User            string
+	ApplicationName string
+	ClientAddr      string
+	State           string
+	BackendStart    time.Time
+}
+
+// MigrationLockOptions configures AcquireMigrationLock.
+type MigrationLockOptions struct {
+	// PollInterval is the delay between attempts to acquire the lock. If 0, a default of 1 second is used.
+	PollInterval time.Duration
+
+	// OnWait is called with the sessions holding the lock each time an attempt to acquire the lock fails. holders may be
+	// empty if the holder released the lock after the attempt. It may be nil.
+	OnWait func(holders []MigrationLockHolder)
+}
+
+// MigrationLock is a session level advisory lock held on a dedicated connection. It is used to ensure that only one
+// process runs migrations at a time. As the lock is held on its own connection it is unaffected by the transactions,
+// errors, and DDL of the connection running the migrations.
+type MigrationLock struct {
+	conn     *Conn
+	key      int64
+	released bool
+}
+
+// AcquireMigrationLock connects to the database with config and acquires a session level advisory lock identified by
+// key on that connection. It waits until the lock is available or ctx is canceled. While waiting, options.OnWait is
+// called with the sessions holding the lock. options may be nil.
+//
+// Release must be called to release the lock. The lock is also released by the server if the connection is lost or the
+// process exits.
+func AcquireMigrationLock(ctx context.Context, config *ConnConfig, key int64, options *MigrationLockOptions) (*MigrationLock, error) {
+	if options == nil {
+		options = &MigrationLockOptions{}
+	}
+
+	pollInterval := options.PollInterval
+	if pollInterval == 0 {
+		pollInterval = time.Second
+	}
+
+	clock := config.Clock
+	if clock == nil {
+		clock = pgconn.SystemClock()
+	}
+
+	conn, err := ConnectConfig(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		var acquired bool
+		err = conn.QueryRow(ctx, "select pg_try_advisory_lock($1)", key).Scan(&acquired)
+		if err != nil {
+			conn.Close(context.Background())
+			return nil, fmt.Errorf("acquire migration lock: %w", err)
+		}
+		if acquired {
+			return &MigrationLock{conn: conn, key: key}, nil
+		}
+
+		if options.OnWait != nil {
+			holders, err := migrationLockHolders(ctx, conn, key)
+			if err != nil {
+				conn.Close(context.Background())
+				return nil, fmt.Errorf("find migration lock holders: %w", err)
+			}
+			options.OnWait(holders)
+		}
+
+		timer := clock.NewTimer(pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			conn.Close(context.Background())
+			return nil, ctx.Err()
+		case <-timer.C():
+		}
+	}
+}
+
+// migrationLockHolders returns the sessions holding the advisory lock key. A bigint advisory lock is stored in pg_locks
+// with the high 32 bits in classid, the low 32 bits in objid, and an objsubid of 1.
+func migrationLockHolders(ctx context.Context, conn *Conn, key int64) ([]MigrationLockHolder, error) {
+	rows, err := conn.Query(ctx, `select a.pid, coalesce(a.usename, ''), coalesce(a.application_name, ''),
+	coalesce(host(a.client_addr), ''), coalesce(a.state, ''), a.backend_start
+from pg_locks l
+	join pg_stat_activity a on a.pid = l.pid
+where l.locktype = 'advisory'
+	and l.granted
+	and l.database = (select oid from pg_database where datname = current_database())
+	and l.classid = $1
+	and l.objid = $2
+	and l.objsubid = 1`,
+		uint32(uint64(key)>>32), uint32(key),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var holders []MigrationLockHolder
+	var h MigrationLockHolder
+	_, err = ForEachRow(rows, []any{&h.PID, &h.User, &h.ApplicationName, &h.ClientAddr, &h.State, &h.BackendStart}, func() error {
+		holders = append(holders, h)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return holders, nil
+}
+
+// Release releases the lock and closes its connection. The connection is closed even if unlocking fails, which causes
+// the server to release the lock. It is safe to call Release more than once.
+func (l *MigrationLock) Release(ctx context.Context) error {
+	if l.released {
+		return nil
+	}
+	l.released = true
+	defer l.conn.Close(ctx)
+
+	var unlocked bool
+	err := l.conn.QueryRow(ctx, "select pg_advisory_unlock($1)", l.key).Scan(&unlocked)
+	if err != nil {
+		return fmt.Errorf("release migration lock: %w", err)
+	}
+	if !unlocked {
+		return fmt.Errorf("release migration lock: lock %d was not held", l.key)
+	}
+
+	return nil
+}
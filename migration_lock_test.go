@@ -0,0 +1,60 @@
+package pgx_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireMigrationLock(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+	pgxtest.SkipCockroachDB(t, conn, "Server does not support advisory locks")
+
+	const key = -7216354987346232871
+
+	holderConn := mustConnect(t, config)
+	defer closeConn(t, holderConn)
+	mustExec(t, holderConn, "select pg_advisory_lock($1)", key)
+
+	var waitHolders []pgx.MigrationLockHolder
+	options := &pgx.MigrationLockOptions{
+		PollInterval: 10 * time.Millisecond,
+		OnWait: func(holders []pgx.MigrationLockHolder) {
+			if waitHolders == nil {
+				waitHolders = holders
+				mustExec(t, holderConn, "select pg_advisory_unlock($1)", key)
+			}
+		},
+	}
+
+	lock, err := pgx.AcquireMigrationLock(ctx, config, key, options)
+	require.NoError(t, err)
+	require.Len(t, waitHolders, 1)
+	require.Equal(t, holderConn.PgConn().PID(), waitHolders[0].PID)
+
+	// The lock is held so another attempt must wait.
+	shortCtx, shortCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	_, err = pgx.AcquireMigrationLock(shortCtx, config, key, &pgx.MigrationLockOptions{PollInterval: 10 * time.Millisecond})
+	shortCancel()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, lock.Release(ctx))
+	require.NoError(t, lock.Release(ctx))
+
+	lock, err = pgx.AcquireMigrationLock(ctx, config, key, nil)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release(ctx))
+}
@@ -16,6 +16,7 @@ type Tracer struct {
 	CopyFromTracers    []pgx.CopyFromTracer
 	PrepareTracers     []pgx.PrepareTracer
 	ConnectTracers     []pgx.ConnectTracer
+	TxRetryTracers     []pgx.TxRetryTracer
 	PoolAcquireTracers []pgxpool.AcquireTracer
 	PoolReleaseTracers []pgxpool.ReleaseTracer
 }
@@ -43,6 +44,10 @@ func New(tracers ...pgx.QueryTracer) *Tracer {
 			t.ConnectTracers = append(t.ConnectTracers, connectTracer)
 		}
 
+		if txRetryTracer, ok := tracer.(pgx.TxRetryTracer); ok {
+			t.TxRetryTracers = append(t.TxRetryTracers, txRetryTracer)
+		}
+
 		if poolAcquireTracer, ok := tracer.(pgxpool.AcquireTracer); ok {
 			t.PoolAcquireTracers = append(t.PoolAcquireTracers, poolAcquireTracer)
 		}
@@ -131,6 +136,12 @@ func (t *Tracer) TraceConnectEnd(ctx context.Context, data pgx.TraceConnectEndDa
 	}
 }
 
+func (t *Tracer) TraceTxRetry(ctx context.Context, conn *pgx.Conn, data pgx.TraceTxRetryData) {
+	for _, tracer := range t.TxRetryTracers {
+		tracer.TraceTxRetry(ctx, conn, data)
+	}
+}
+
 func (t *Tracer) TraceAcquireStart(ctx context.Context, pool *pgxpool.Pool, data pgxpool.TraceAcquireStartData) context.Context {
 	for _, tracer := range t.PoolAcquireTracers {
 		ctx = tracer.TraceAcquireStart(ctx, pool, data)
@@ -50,6 +50,9 @@ func (tt *testFullTracer) TraceConnectStart(ctx context.Context, data pgx.TraceC
 func (tt *testFullTracer) TraceConnectEnd(ctx context.Context, data pgx.TraceConnectEndData) {
 }
 
+func (tt *testFullTracer) TraceTxRetry(ctx context.Context, conn *pgx.Conn, data pgx.TraceTxRetryData) {
+}
+
 func (tt *testFullTracer) TraceAcquireStart(ctx context.Context, pool *pgxpool.Pool, data pgxpool.TraceAcquireStartData) context.Context {
 	return ctx
 }
@@ -103,6 +106,9 @@ func TestNew(t *testing.T) {
 			ConnectTracers: []pgx.ConnectTracer{
 				fullTracer,
 			},
+			TxRetryTracers: []pgx.TxRetryTracer{
+				fullTracer,
+			},
 			PoolAcquireTracers: []pgxpool.AcquireTracer{
 				fullTracer,
 			},
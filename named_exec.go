@@ -0,0 +1,239 @@
+package pgx
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// NamedExec executes sql with the '@' named placeholders bound to the fields of arg. See NamedQuery.
+func NamedExec(
+	ctx context.Context,
+	db interface {
+		Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	},
+	sql string,
+	arg any,
+) (pgconn.CommandTag, error) {
+	newSQL, newArgs, err := bindNamedArg(sql, arg)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+
+	return db.Exec(ctx, newSQL, newArgs...)
+}
+
+// NamedQuery executes sql with the '@' named placeholders bound to the fields of arg. arg must be a struct, a pointer to
+// a struct, or a map with string keys such as NamedArgs. Struct fields are matched to placeholders in the same way as
+// RowToStructByName matches them to columns. i.e. By the db struct tag if present or by the field name ignoring case and
+// underscores otherwise. Every placeholder must have a matching field.
+//
+// A placeholder that is the only element of an IN list and is bound to a slice other than []byte is expanded into one ordinal
+// placeholder per element. For example, with ids bound to []int32{1, 2, 3}:
+//
+//	select * from widgets where id in (@ids)
+//
+// is executed as
+//
+//	select * from widgets where id in ($1, $2, $3)
+//
+// An empty slice is an error as it cannot be expressed as an IN list. Slices used anywhere else are passed as a single
+// array argument. e.g. id = any(@ids).
+func NamedQuery(
+	ctx context.Context,
+	db interface {
+		Query(ctx context.Context, sql string, args ...any) (Rows, error)
+	},
+	sql string,
+	arg any,
+) (Rows, error) {
+	newSQL, newArgs, err := bindNamedArg(sql, arg)
+	if err != nil {
+		return &baseRows{err: err, closed: true}, err
+	}
+
+	return db.Query(ctx, newSQL, newArgs...)
+}
+
+type namedArgLookupFunc func(name string) (any, bool)
+
+func bindNamedArg(sql string, arg any) (string, []any, error) {
+	lookup, err := namedArgLookup(arg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return rewriteNamedQueryExpandingIn(sql, lookup)
+}
+
+func namedArgLookup(arg any) (namedArgLookupFunc, error) {
+	switch arg := arg.(type) {
+	case NamedArgs:
+		return mapNamedArgLookup(arg), nil
+	case StrictNamedArgs:
+		return mapNamedArgLookup(arg), nil
+	case map[string]any:
+		return mapNamedArgLookup(arg), nil
+	}
+
+	v := reflect.ValueOf(arg)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, fmt.Errorf("cannot bind named arguments to nil %T", arg)
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		fields := pgtype.StructFields(v.Type())
+		return func(name string) (any, bool) {
+			for i := range fields {
+				if fields[i].MatchName(name, nil) {
+					return v.FieldByIndex(fields[i].Index).Interface(), true
+				}
+			}
+			return nil, false
+		}, nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("cannot bind named arguments to %T: map keys must be strings", arg)
+		}
+		return func(name string) (any, bool) {
+			mv := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			if !mv.IsValid() {
+				return nil, false
+			}
+			return mv.Interface(), true
+		}, nil
+	}
+
+	return nil, fmt.Errorf("cannot bind named arguments to %T: must be a struct or map", arg)
+}
+
+func mapNamedArgLookup(m map[string]any) namedArgLookupFunc {
+	return func(name string) (any, bool) {
+		v, ok := m[name]
+		return v, ok
+	}
+}
+
+var (
+	inListStartRegexp = regexp.MustCompile(`(?i)(?:^|[^\w$])in\s*\(\s*$`)
+	inListEndRegexp   = regexp.MustCompile(`^\s*\)`)
+)
+
+// rewriteNamedQueryExpandingIn replaces the named placeholders in sql with ordinal placeholders. Slices that are the
+// only element of an IN list are expanded into one placeholder per element.
+func rewriteNamedQueryExpandingIn(sql string, lookup namedArgLookupFunc) (string, []any, error) {
+	l := &sqlLexer{
+		src:           sql,
+		stateFn:       rawState,
+		nameToOrdinal: make(map[namedArg]int),
+	}
+
+	for l.stateFn != nil {
+		l.stateFn = l.stateFn(l)
+	}
+
+	var newArgs []any
+	scalarOrdinals := make(map[namedArg]int)
+	expandedOrdinals := make(map[namedArg][]int)
+
+	sb := strings.Builder{}
+	for i, p := range l.parts {
+		switch p := p.(type) {
+		case string:
+			sb.WriteString(p)
+		case namedArg:
+			value, ok := lookup(string(p))
+			if !ok {
+				return "", nil, fmt.Errorf("argument %s found in sql query but not present in named argument", p)
+			}
+
+			var elements []any
+			inList := isOnlyElementOfInList(l.parts, i)
+			if inList {
+				elements, inList = expandNamedArgSlice(value)
+				if inList && len(elements) == 0 {
+					return "", nil, fmt.Errorf("argument %s is an empty slice and cannot be expanded into an IN list", p)
+				}
+			}
+
+			if !inList {
+				ordinal, ok := scalarOrdinals[p]
+				if !ok {
+					newArgs = append(newArgs, value)
+					ordinal = len(newArgs)
+					scalarOrdinals[p] = ordinal
+				}
+				sb.WriteByte('$')
+				sb.WriteString(strconv.Itoa(ordinal))
+				continue
+			}
+
+			ordinals, ok := expandedOrdinals[p]
+			if !ok {
+				ordinals = make([]int, len(elements))
+				for j, e := range elements {
+					newArgs = append(newArgs, e)
+					ordinals[j] = len(newArgs)
+				}
+				expandedOrdinals[p] = ordinals
+			}
+			for j, ordinal := range ordinals {
+				if j > 0 {
+					sb.WriteString(", ")
+				}
+				sb.WriteByte('$')
+				sb.WriteString(strconv.Itoa(ordinal))
+			}
+		}
+	}
+
+	return sb.String(), newArgs, nil
+}
+
+// isOnlyElementOfInList returns true if the named argument at parts[i] is the only element of an IN list.
+func isOnlyElementOfInList(parts []any, i int) bool {
+	if i == 0 || i == len(parts)-1 {
+		return false
+	}
+
+	before, ok := parts[i-1].(string)
+	if !ok || !inListStartRegexp.MatchString(before) {
+		return false
+	}
+
+	after, ok := parts[i+1].(string)
+	return ok && inListEndRegexp.MatchString(after)
+}
+
+// expandNamedArgSlice returns the elements of value if it is a slice that should be expanded. Arrays such as [16]byte
+// and values that are encoded as a single value such as []byte and types that implement driver.Valuer are not expanded.
+func expandNamedArgSlice(value any) ([]any, bool) {
+	if value == nil {
+		return nil, false
+	}
+	if _, ok := value.(driver.Valuer); ok {
+		return nil, false
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+
+	elements := make([]any, v.Len())
+	for i := range elements {
+		elements[i] = v.Index(i).Interface()
+	}
+	return elements, true
+}
@@ -0,0 +1,200 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingExecer records the SQL and arguments passed to Exec.
+type recordingExecer struct {
+	sql  string
+	args []any
+}
+
+func (e *recordingExecer) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	e.sql = sql
+	e.args = arguments
+	return pgconn.NewCommandTag("SELECT 1"), nil
+}
+
+func TestNamedExecBinding(t *testing.T) {
+	t.Parallel()
+
+	type Base struct {
+		TenantID int32
+	}
+
+	type widget struct {
+		Base
+		ID        int32   `db:"id"`
+		Name      string  `db:"widget_name"`
+		Ignored   string  `db:"-"`
+		SortOrder int32   // matched ignoring case and underscores
+		IDs       []int32 `db:"ids"`
+		Tags      []string
+		Data      []byte
+		unused    string
+	}
+
+	w := widget{
+		Base:      Base{TenantID: 7},
+		ID:        1,
+		Name:      "foo",
+		SortOrder: 3,
+		IDs:       []int32{10, 20, 30},
+		Tags:      []string{"a", "b"},
+		Data:      []byte("bytes"),
+	}
+	_ = w.unused
+
+	for i, tt := range []struct {
+		sql          string
+		arg          any
+		expectedSQL  string
+		expectedArgs []any
+	}{
+		{
+			sql:          "update widgets set name = @widget_name, sort_order = @sort_order where id = @id and tenant_id = @tenant_id",
+			arg:          w,
+			expectedSQL:  "update widgets set name = $1, sort_order = $2 where id = $3 and tenant_id = $4",
+			expectedArgs: []any{"foo", int32(3), int32(1), int32(7)},
+		},
+		{
+			sql:          "select * from widgets where id in (@ids) and tenant_id = @tenantID",
+			arg:          &w,
+			expectedSQL:  "select * from widgets where id in ($1, $2, $3) and tenant_id = $4",
+			expectedArgs: []any{int32(10), int32(20), int32(30), int32(7)},
+		},
+		{
+			sql:          "select * from widgets where id IN( @ids ) or parent_id in (@ids) or id = any(@ids)",
+			arg:          w,
+			expectedSQL:  "select * from widgets where id IN( $1, $2, $3 ) or parent_id in ($1, $2, $3) or id = any($4)",
+			expectedArgs: []any{int32(10), int32(20), int32(30), []int32{10, 20, 30}},
+		},
+		{
+			sql:          "select * from widgets where tag in (@tags) and data in (@data) and id in (@id, 2) and login(@ids)",
+			arg:          w,
+			expectedSQL:  "select * from widgets where tag in ($1, $2) and data in ($3) and id in ($4, 2) and login($5)",
+			expectedArgs: []any{"a", "b", []byte("bytes"), int32(1), []int32{10, 20, 30}},
+		},
+		{
+			sql:          "select @a, @b, @a, '@c' -- @d",
+			arg:          pgx.NamedArgs{"a": 1, "b": 2},
+			expectedSQL:  "select $1, $2, $1, '@c' -- @d",
+			expectedArgs: []any{1, 2},
+		},
+		{
+			sql:          "select * from t where x in (@xs)",
+			arg:          map[string]any{"xs": [2]string{"x", "y"}},
+			expectedSQL:  "select * from t where x in ($1)",
+			expectedArgs: []any{[2]string{"x", "y"}},
+		},
+		{
+			sql:          "select * from t where id in (@id)",
+			arg:          map[string]any{"id": [16]byte{1, 2, 3}},
+			expectedSQL:  "select * from t where id in ($1)",
+			expectedArgs: []any{[16]byte{1, 2, 3}},
+		},
+		{
+			sql:          "select * from t where x in (@xs)",
+			arg:          map[string][]int{"xs": {5}},
+			expectedSQL:  "select * from t where x in ($1)",
+			expectedArgs: []any{5},
+		},
+		{
+			sql:          "select * from t where x in (@xs)",
+			arg:          map[string]any{"xs": pgtype.FlatArray[int32]{1, 2}},
+			expectedSQL:  "select * from t where x in ($1, $2)",
+			expectedArgs: []any{int32(1), int32(2)},
+		},
+	} {
+		e := &recordingExecer{}
+		_, err := pgx.NamedExec(context.Background(), e, tt.sql, tt.arg)
+		require.NoErrorf(t, err, "%d", i)
+		assert.Equalf(t, tt.expectedSQL, e.sql, "%d", i)
+		assert.Equalf(t, tt.expectedArgs, e.args, "%d", i)
+	}
+}
+
+func TestNamedExecBindingErrors(t *testing.T) {
+	t.Parallel()
+
+	type widget struct {
+		ID  int32   `db:"id"`
+		IDs []int32 `db:"ids"`
+	}
+
+	for i, tt := range []struct {
+		sql         string
+		arg         any
+		expectedErr string
+	}{
+		{
+			sql:         "select @missing",
+			arg:         widget{},
+			expectedErr: "argument missing found in sql query but not present in named argument",
+		},
+		{
+			sql:         "select * from widgets where id in (@ids)",
+			arg:         widget{},
+			expectedErr: "argument ids is an empty slice and cannot be expanded into an IN list",
+		},
+		{
+			sql:         "select @id",
+			arg:         (*widget)(nil),
+			expectedErr: "cannot bind named arguments to nil *pgx_test.widget",
+		},
+		{
+			sql:         "select @id",
+			arg:         42,
+			expectedErr: "cannot bind named arguments to int: must be a struct or map",
+		},
+		{
+			sql:         "select @id",
+			arg:         map[int]any{1: 1},
+			expectedErr: "cannot bind named arguments to map[int]interface {}: map keys must be strings",
+		},
+	} {
+		e := &recordingExecer{}
+		_, err := pgx.NamedExec(context.Background(), e, tt.sql, tt.arg)
+		assert.EqualErrorf(t, err, tt.expectedErr, "%d", i)
+	}
+}
+
+func TestNamedQuery(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	defaultConnTestRunner.RunTest(ctx, t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		type filter struct {
+			IDs []int32 `db:"ids"`
+			Min int32
+		}
+
+		rows, err := pgx.NamedQuery(ctx, conn, "select n from generate_series(1, 10) n where n in (@ids) and n >= @min order by n", filter{IDs: []int32{2, 4, 6, 8}, Min: 4})
+		require.NoError(t, err)
+		ns, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Equal(t, []int32{4, 6, 8}, ns)
+
+		_, err = conn.Exec(ctx, "create temporary table named_exec_test (id int4 primary key, name text)")
+		require.NoError(t, err)
+
+		type row struct {
+			ID   int32 `db:"id"`
+			Name string
+		}
+		ct, err := pgx.NamedExec(ctx, conn, "insert into named_exec_test (id, name) values (@id, @name)", row{ID: 1, Name: "foo"})
+		require.NoError(t, err)
+		require.EqualValues(t, 1, ct.RowsAffected())
+	})
+}
@@ -0,0 +1,244 @@
+package pgx
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PaginateOptions configures Paginate.
+type PaginateOptions struct {
+	// Keys are the names of the columns that rows are ordered by. Together they must uniquely identify a row of the
+	// query and they must not be NULL. e.g. []string{"created_at", "id"}. Required.
+	Keys []string
+
+	// Descending orders the rows by Keys in descending instead of ascending order.
+	Descending bool
+
+	// Limit is the maximum number of rows in a page. Required.
+	Limit int
+
+	// Cursor is the Page.NextCursor of the previous page. It is empty for the first page.
+	Cursor string
+}
+
+// Page is a page of rows returned by Paginate.
+type Page[T any] struct {
+	// Items are the rows of the page converted by the RowToFunc passed to Paginate.
+	Items []T
+
+	// NextCursor is an opaque token that is passed as PaginateOptions.Cursor to get the next page. It is empty if this
+	// is the last page.
+	NextCursor string
+}
+
+// Paginate executes sql with args and returns a page of its rows using keyset pagination. Unlike LIMIT and OFFSET, the
+// cost of getting a page does not grow with the number of preceding rows and rows are neither skipped nor repeated
+// when rows are inserted or deleted between requests.
+//
+// sql is wrapped in a query that filters and orders its rows by opts.Keys. e.g. with opts.Keys of "created_at" and "id"
+// the executed query is similar to:
+//
+//	select * from (sql) where (created_at, id) > ($3, $4) order by created_at, id limit $5
+//
+// sql must not have its own ORDER BY or LIMIT and must only use positional placeholders that are bound to args. The
+// rows are converted with fn as with CollectRows. fn does not see the additional columns that Paginate uses to build
+// the cursor.
+//
+// The cursor contains the key values of the last row of the page. It is not encrypted or signed. The values are
+// compared as parameters of the types of the key columns, so an altered cursor can only select a different page.
+func Paginate[T any](
+	ctx context.Context,
+	db interface {
+		Query(ctx context.Context, sql string, args ...any) (Rows, error)
+	},
+	sql string,
+	args []any,
+	opts PaginateOptions,
+	fn RowToFunc[T],
+) (Page[T], error) {
+	if len(opts.Keys) == 0 {
+		return Page[T]{}, errors.New("paginate: Keys are required")
+	}
+	if opts.Limit < 1 {
+		return Page[T]{}, errors.New("paginate: Limit must be greater than 0")
+	}
+
+	var cursorValues []string
+	if opts.Cursor != "" {
+		var err error
+		cursorValues, err = decodePaginateCursor(opts.Cursor)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		if len(cursorValues) != len(opts.Keys) {
+			return Page[T]{}, fmt.Errorf("paginate: cursor has %d values but there are %d keys", len(cursorValues), len(opts.Keys))
+		}
+	}
+
+	keys := make([]string, len(opts.Keys))
+	for i, k := range opts.Keys {
+		keys[i] = "pgx_paginate." + Identifier{k}.Sanitize()
+	}
+	keyList := strings.Join(keys, ", ")
+
+	queryArgs := make([]any, 0, len(args)+len(cursorValues)+1)
+	queryArgs = append(queryArgs, args...)
+
+	var sb strings.Builder
+	sb.WriteString("select pgx_paginate.*")
+	for _, k := range keys {
+		sb.WriteString(", ")
+		sb.WriteString(k)
+		sb.WriteString("::text")
+	}
+	sb.WriteString(" from (")
+	sb.WriteString(sql)
+	sb.WriteString(") as pgx_paginate")
+
+	if cursorValues != nil {
+		sb.WriteString(" where (")
+		sb.WriteString(keyList)
+		if opts.Descending {
+			sb.WriteString(") < (")
+		} else {
+			sb.WriteString(") > (")
+		}
+		for i, v := range cursorValues {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			queryArgs = append(queryArgs, v)
+			fmt.Fprintf(&sb, "$%d", len(queryArgs))
+		}
+		sb.WriteString(")")
+	}
+
+	sb.WriteString(" order by ")
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(k)
+		if opts.Descending {
+			sb.WriteString(" desc")
+		}
+	}
+
+	// Get one more row than the limit to know whether there is a next page.
+	queryArgs = append(queryArgs, opts.Limit+1)
+	fmt.Fprintf(&sb, " limit $%d", len(queryArgs))
+
+	rows, err := db.Query(ctx, sb.String(), queryArgs...)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	defer rows.Close()
+
+	page := Page[T]{Items: make([]T, 0, opts.Limit)}
+	row := &paginateRow{Rows: rows, keyCount: len(keys)}
+	var lastKeyValues []string
+	for rows.Next() {
+		if len(page.Items) == opts.Limit {
+			page.NextCursor, err = encodePaginateCursor(lastKeyValues)
+			if err != nil {
+				return Page[T]{}, err
+			}
+			break
+		}
+
+		value, err := fn(row)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		page.Items = append(page.Items, value)
+
+		lastKeyValues, err = row.keyValues()
+		if err != nil {
+			return Page[T]{}, err
+		}
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return Page[T]{}, err
+	}
+
+	return page, nil
+}
+
+// paginateRow hides the key columns that Paginate appends to each row.
+type paginateRow struct {
+	Rows
+	keyCount int
+	scanDest []any
+}
+
+func (r *paginateRow) FieldDescriptions() []pgconn.FieldDescription {
+	fds := r.Rows.FieldDescriptions()
+	return fds[:len(fds)-r.keyCount]
+}
+
+func (r *paginateRow) Scan(dest ...any) error {
+	// The key columns are skipped by scanning them into nil.
+	r.scanDest = append(r.scanDest[:0], dest...)
+	for i := 0; i < r.keyCount; i++ {
+		r.scanDest = append(r.scanDest, nil)
+	}
+	return r.Rows.Scan(r.scanDest...)
+}
+
+func (r *paginateRow) Values() ([]any, error) {
+	values, err := r.Rows.Values()
+	if err != nil {
+		return nil, err
+	}
+	return values[:len(values)-r.keyCount], nil
+}
+
+func (r *paginateRow) RawValues() [][]byte {
+	rawValues := r.Rows.RawValues()
+	return rawValues[:len(rawValues)-r.keyCount]
+}
+
+// keyValues returns the text of the key columns of the current row. The text and binary formats of text are the same
+// so the raw values can be used regardless of the result format.
+func (r *paginateRow) keyValues() ([]string, error) {
+	rawValues := r.Rows.RawValues()
+	keyValues := make([]string, r.keyCount)
+	for i, v := range rawValues[len(rawValues)-r.keyCount:] {
+		if v == nil {
+			return nil, errors.New("paginate: key value is NULL")
+		}
+		keyValues[i] = string(v)
+	}
+	return keyValues, nil
+}
+
+func encodePaginateCursor(keyValues []string) (string, error) {
+	buf, err := json.Marshal(keyValues)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func decodePaginateCursor(cursor string) ([]string, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("paginate: invalid cursor: %w", err)
+	}
+
+	var keyValues []string
+	err = json.Unmarshal(buf, &keyValues)
+	if err != nil {
+		return nil, fmt.Errorf("paginate: invalid cursor: %w", err)
+	}
+
+	return keyValues, nil
+}
@@ -0,0 +1,91 @@
+package pgx_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginate(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		sql := `select n / 3 as a, n % 3 as b, 'n' || n as name from generate_series(0, $1::int - 1) n`
+
+		type item struct {
+			A    int32
+			B    int32
+			Name string
+		}
+
+		var items []item
+		opts := pgx.PaginateOptions{Keys: []string{"a", "b"}, Limit: 4}
+		for pages := 0; ; pages++ {
+			require.Less(t, pages, 5)
+
+			page, err := pgx.Paginate(ctx, conn, sql, []any{10}, opts, pgx.RowToStructByName[item])
+			require.NoError(t, err)
+			items = append(items, page.Items...)
+
+			if page.NextCursor == "" {
+				assert.Len(t, page.Items, 2)
+				break
+			}
+			assert.Len(t, page.Items, 4)
+			opts.Cursor = page.NextCursor
+		}
+
+		require.Len(t, items, 10)
+		for i, it := range items {
+			assert.Equal(t, item{A: int32(i / 3), B: int32(i % 3), Name: fmt.Sprintf("n%d", i)}, it)
+		}
+	})
+}
+
+func TestPaginateDescending(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		sql := `select n from generate_series(1, 5) n`
+		opts := pgx.PaginateOptions{Keys: []string{"n"}, Descending: true, Limit: 3}
+
+		page, err := pgx.Paginate(ctx, conn, sql, nil, opts, pgx.RowTo[int32])
+		require.NoError(t, err)
+		assert.Equal(t, []int32{5, 4, 3}, page.Items)
+		require.NotEmpty(t, page.NextCursor)
+
+		opts.Cursor = page.NextCursor
+		page, err = pgx.Paginate(ctx, conn, sql, nil, opts, pgx.RowTo[int32])
+		require.NoError(t, err)
+		assert.Equal(t, []int32{2, 1}, page.Items)
+		assert.Empty(t, page.NextCursor)
+	})
+}
+
+func TestPaginateEmpty(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		sql := `select n from generate_series(1, 0) n`
+		opts := pgx.PaginateOptions{Keys: []string{"n"}, Limit: 3}
+
+		page, err := pgx.Paginate(ctx, conn, sql, nil, opts, pgx.RowTo[int32])
+		require.NoError(t, err)
+		assert.NotNil(t, page.Items)
+		assert.Empty(t, page.Items)
+		assert.Empty(t, page.NextCursor)
+	})
+}
+
+func TestPaginateInvalidOptions(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		sql := `select n from generate_series(1, 5) n`
+
+		_, err := pgx.Paginate(ctx, conn, sql, nil, pgx.PaginateOptions{Limit: 3}, pgx.RowTo[int32])
+		require.ErrorContains(t, err, "Keys are required")
+
+		_, err = pgx.Paginate(ctx, conn, sql, nil, pgx.PaginateOptions{Keys: []string{"n"}}, pgx.RowTo[int32])
+		require.ErrorContains(t, err, "Limit must be greater than 0")
+
+		_, err = pgx.Paginate(ctx, conn, sql, nil, pgx.PaginateOptions{Keys: []string{"n"}, Limit: 3, Cursor: "!"}, pgx.RowTo[int32])
+		require.ErrorContains(t, err, "invalid cursor")
+	})
+}
@@ -0,0 +1,66 @@
+package pgconn
+
+import "time"
+
+// Clock is a source of the current time and of timers. It is used for write deadlock detection, context cancellation
+// deadlines, and cancel request delays. Replacing it allows tests to simulate timeouts deterministically.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTimer creates a Timer that sends the current time on its channel after at least duration d.
+	NewTimer(d time.Duration) Timer
+
+	// AfterFunc waits for the duration to elapse and then calls f in its own goroutine. The returned Timer has a nil
+	// channel.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer created by a Clock. Its methods have the same semantics as those of *time.Timer.
+type Timer interface {
+	// C returns the channel on which the time is delivered.
+	C() <-chan time.Time
+
+	// Stop prevents the Timer from firing. It returns true if the call stops the timer, false if the timer has already
+	// expired or been stopped.
+	Stop() bool
+
+	// Reset changes the timer to expire after duration d. It returns true if the timer had been active, false if the
+	// timer had expired or been stopped.
+	Reset(d time.Duration) bool
+}
+
+// SystemClock returns a Clock that uses the time package.
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return systemTimer{time.AfterFunc(d, f)}
+}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// clockOrSystem returns c or the system clock if c is nil.
+func clockOrSystem(c Clock) Clock {
+	if c == nil {
+		return systemClock{}
+	}
+	return c
+}
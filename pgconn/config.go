@@ -41,6 +41,29 @@ type Config struct {
 	LookupFunc     LookupFunc // e.g. net.Resolver.LookupHost
 	BuildFrontend  BuildFrontendFunc
 
+	// WriteBufferSize, if greater than 0, is the maximum capacity of the write buffer of the pgproto3.Frontend that is
+	// kept between writes. See pgproto3.Frontend.SetWriteBufferSize.
+	WriteBufferSize int
+
+	// VectoredWriteThreshold, if greater than 0, is the minimum length of a query parameter value or CopyFrom data
+	// chunk that is written directly from the caller's buffer with a vectored write (writev) instead of being copied into
+	// the write buffer. This reduces memory copies when executing queries with large parameters. See
+	// pgproto3.Frontend.SetVectoredWriteThreshold.
+	VectoredWriteThreshold int
+
+	// KeepAlive configures TCP keepalive probes for connections to the host and to all fallback hosts. It is applied to
+	// connections returned by DialFunc that are a *net.TCPConn. It is set from the libpq compatible keepalives,
+	// keepalives_idle, keepalives_interval, and keepalives_count connection string parameters.
+	KeepAlive KeepAliveConfig
+
+	// MinProtocolVersion and MaxProtocolVersion are the lowest and highest protocol versions to use. Valid values are
+	// "3.0", "3.2", and "latest". The connection is started with MaxProtocolVersion. If the server only supports an
+	// older version it responds with NegotiateProtocolVersion and the connection continues with that version unless it
+	// is lower than MinProtocolVersion. They are set from the libpq compatible min_protocol_version and
+	// max_protocol_version connection string parameters. An empty string is treated as "3.0".
+	MinProtocolVersion string
+	MaxProtocolVersion string
+
 	// BuildContextWatcherHandler is called to create a ContextWatcherHandler for a connection. The handler is called
 	// when a context passed to a PgConn method is canceled.
 	BuildContextWatcherHandler func(*PgConn) ctxwatch.Handler
@@ -63,14 +86,74 @@ type Config struct {
 	// OnNotice is a callback function called when a notice response is received.
 	OnNotice NoticeHandler
 
+	// OnCopySkippedRow is a callback function called when the server reports that it skipped a row during COPY FROM with
+	// the ON_ERROR ignore and LOG_VERBOSITY verbose options. The notice reporting the row is also passed to OnNotice. See
+	// ParseCopySkippedRow.
+	OnCopySkippedRow CopySkippedRowHandler
+
+	// GuardedParameterStatuses are the names of parameters that must not change after the connection is established.
+	// Connection poolers in transaction pooling mode such as PgBouncer can hand over a server session with different
+	// settings than the one the connection was established with. This can silently break encoding and quoting
+	// assumptions. If the server reports a new value for one of these parameters, OnGuardedParameterStatusChange is
+	// called. See DefaultGuardedParameterStatuses.
+	GuardedParameterStatuses []string
+
+	// OnGuardedParameterStatusChange is called when the server reports a new value for a parameter in
+	// GuardedParameterStatuses. If it returns an error or is nil the connection is closed and a
+	// *ParameterStatusChangeError is returned by the operation in progress. Return nil to accept an expected change. e.g.
+	// a SET search_path executed by the application.
+	OnGuardedParameterStatusChange ParameterStatusChangeHandler
+
 	// OnNotification is a callback function called when a notification from the LISTEN/NOTIFY system is received.
 	OnNotification NotificationHandler
 
+	// OnUnknownMessage, if set, is called when a backend message with an unrecognized type is received. Such messages
+	// are then ignored by the operation in progress. This allows experimenting with protocol extensions and proxies that
+	// inject custom messages. If not set, an unrecognized message is a protocol error that closes the connection.
+	OnUnknownMessage UnknownMessageHandler
+
 	// OnPgError is a callback function called when a Postgres error is received by the server. The default handler will close
 	// the connection on any FATAL errors. If you override this handler you should call the previously set handler or ensure
 	// that you close on FATAL errors by returning false.
 	OnPgError PgErrorHandler
 
+	// OnClose is called exactly once when an established connection is closed. err is the error that caused the
+	// connection to be closed, or nil if it was closed with Close. This includes connections that are closed
+	// asynchronously because of a fatal error or a canceled context. Operations attempted after the connection is closed
+	// return a *ConnectionClosedError that wraps err.
+	OnClose CloseHandler
+
+	// Clock is the source of time used by the connection for timers and deadlines. If nil, the system clock is used.
+	Clock Clock
+
+	// TraceWriter, if set, receives a trace of every protocol message sent and received by the connection, starting
+	// with the startup message. The format is similar to that produced by the libpq function PQtrace. Password, SASL,
+	// and GSS authentication payloads are always redacted.
+	TraceWriter io.Writer
+
+	// TraceOptions controls the output written to TraceWriter. Set RedactParameterValues to keep query arguments out of
+	// the trace.
+	TraceOptions pgproto3.TracerOptions
+
+	// ProtocolHistorySize is the number of the most recently sent and received protocol messages to retain. When greater
+	// than zero, errors caused by failing to read or decode a message from the server are returned as a *ProtocolError
+	// that includes the retained messages. This is intended to aid in reporting bugs. Only the direction, type, and
+	// length of each message are retained. SQL text, parameter values, and row values are not.
+	ProtocolHistorySize int
+
+	// ReadAheadBufferSize, when greater than zero, enables reading ahead while the rows of a result are read. After the
+	// first row is received the connection continues reading from the server in the background while the application
+	// processes earlier rows. Reading pauses when ReadAheadBufferSize bytes are buffered. This can smooth throughput on
+	// high latency links. When zero, rows are read from the server only when requested.
+	ReadAheadBufferSize int
+
+	// MaxResultBytes, when greater than zero, limits the total size of the row values of a single query result. When a
+	// result exceeds the limit, reading rows stops and the remaining rows are discarded. The ResultReader then returns a
+	// *ResultTooLargeError and the connection remains usable. This protects against queries that unexpectedly return
+	// far more data than the application can hold in memory, e.g. with ResultReader.Read or pgx.CollectRows. The size
+	// is measured as the length of the row values as received from the server, not the size of the decoded values.
+	MaxResultBytes int64
+
 	createdByParseConfig bool // Used to enforce created by ParseConfig rule.
 }
 
@@ -96,6 +179,10 @@ func (c *Config) Copy() *Config {
 			newConf.RuntimeParams[k] = v
 		}
 	}
+	if newConf.GuardedParameterStatuses != nil {
+		newConf.GuardedParameterStatuses = make([]string, len(c.GuardedParameterStatuses))
+		copy(newConf.GuardedParameterStatuses, c.GuardedParameterStatuses)
+	}
 	if newConf.Fallbacks != nil {
 		newConf.Fallbacks = make([]*FallbackConfig, len(c.Fallbacks))
 		for i, fallback := range c.Fallbacks {
@@ -145,10 +232,38 @@ func isAbsolutePath(path string) bool {
 	return strings.HasPrefix(path, "/") || isWindowsPath(path)
 }
 
+// isAbstractSocket checks if host is the name of a Linux abstract namespace Unix domain socket directory. As in libpq,
+// the name is prefixed with "@".
+func isAbstractSocket(host string) bool {
+	return strings.HasPrefix(host, "@")
+}
+
+// isNamedPipe checks if host is the path of a Windows named pipe such as \\.\pipe\name or //./pipe/name.
+func isNamedPipe(host string) bool {
+	if len(host) < 2 || (host[0] != '\\' && host[0] != '/') || host[1] != host[0] {
+		return false
+	}
+	parts := strings.FieldsFunc(host, func(r rune) bool { return r == '\\' || r == '/' })
+	return len(parts) >= 3 && strings.EqualFold(parts[1], "pipe")
+}
+
+// isLocalHost checks if host is a Unix domain socket or a Windows named pipe rather than a host name to be resolved.
+func isLocalHost(host string) bool {
+	return isAbsolutePath(host) || isAbstractSocket(host) || isNamedPipe(host)
+}
+
 // NetworkAddress converts a PostgreSQL host and port into network and address suitable for use with
 // net.Dial.
+//
+// A host that is an absolute path or that starts with "@" is the directory of a Unix domain socket. The "@" prefix
+// denotes a Linux abstract namespace socket. A host that is a Windows named pipe path such as \\.\pipe\name or
+// //./pipe/name uses the "pipe" network with the pipe path as address. The port is not used for named pipes. The default
+// DialFunc supports the "pipe" network on Windows.
 func NetworkAddress(host string, port uint16) (network, address string) {
-	if isAbsolutePath(host) {
+	if isNamedPipe(host) {
+		network = "pipe"
+		address = host
+	} else if isAbsolutePath(host) || isAbstractSocket(host) {
 		network = "unix"
 		address = filepath.Join(host, ".s.PGSQL.") + strconv.FormatInt(int64(port), 10)
 	} else {
@@ -201,6 +316,8 @@ func NetworkAddress(host string, port uint16) (network, address string) {
 //	PGAPPNAME
 //	PGCONNECT_TIMEOUT
 //	PGTARGETSESSIONATTRS
+//	PGMINPROTOCOLVERSION
+//	PGMAXPROTOCOLVERSION
 //
 // See http://www.postgresql.org/docs/11/static/libpq-envars.html for details on the meaning of environment variables.
 //
@@ -215,6 +332,9 @@ func NetworkAddress(host string, port uint16) (network, address string) {
 // See http://www.postgresql.org/docs/11/static/libpq-ssl.html#LIBPQ-SSL-PROTECTION for details on what level of
 // security each sslmode provides.
 //
+// As in libpq 16, sslrootcert "system" uses the operating system's trusted CA certificates. It defaults sslmode to
+// "verify-full" and any other sslmode is an error.
+//
 // The sslmode "prefer" (the default), sslmode "allow", and multiple hosts are implemented via the Fallbacks field of
 // the Config struct. If TLSConfig is manually changed it will not affect the fallbacks. For example, in the case of
 // sslmode "prefer" this means it will first try the main Config settings which use TLS, then it will try the fallback
@@ -232,6 +352,9 @@ func NetworkAddress(host string, port uint16) (network, address string) {
 //   - servicefile.
 //     libpq only reads servicefile from the PGSERVICEFILE environment variable. ParseConfig accepts servicefile as a
 //     part of the connection string.
+//   - context_watcher_handler.
+//     Selects what happens when the context of an operation is canceled. Valid values are "deadline" (the default),
+//     "cancel+deadline", and "cancel-only". See ContextWatcherHandlerPreset.
 func ParseConfig(connString string) (*Config, error) {
 	var parseConfigOptions ParseConfigOptions
 	return ParseConfigWithOptions(connString, parseConfigOptions)
@@ -277,12 +400,11 @@ func ParseConfigWithOptions(connString string, options ParseConfigOptions) (*Con
 		User:                 settings["user"],
 		Password:             settings["password"],
 		RuntimeParams:        make(map[string]string),
+		Clock:                SystemClock(),
 		BuildFrontend: func(r io.Reader, w io.Writer) *pgproto3.Frontend {
 			return pgproto3.NewFrontend(r, w)
 		},
-		BuildContextWatcherHandler: func(pgConn *PgConn) ctxwatch.Handler {
-			return &DeadlineContextWatcherHandler{Conn: pgConn.conn}
-		},
+		BuildContextWatcherHandler: BuildPresetContextWatcherHandler(ContextWatcherHandlerDeadline),
 		OnPgError: func(_ *PgConn, pgErr *PgError) bool {
 			// we want to automatically close any fatal errors
 			if strings.EqualFold(pgErr.Severity, "FATAL") {
@@ -300,31 +422,65 @@ func ParseConfigWithOptions(connString string, options ParseConfigOptions) (*Con
 		config.ConnectTimeout = connectTimeout
 		config.DialFunc = makeConnectTimeoutDialFunc(connectTimeout)
 	} else {
-		defaultDialer := makeDefaultDialer()
-		config.DialFunc = defaultDialer.DialContext
+		config.DialFunc = makeDefaultDialFunc(makeDefaultDialer())
 	}
 
 	config.LookupFunc = makeDefaultResolver().LookupHost
 
+	keepAlive, keepAliveSetting, err := parseKeepAliveSettings(settings)
+	if err != nil {
+		return nil, &ParseConfigError{ConnString: connString, msg: "invalid " + keepAliveSetting, err: err}
+	}
+	config.KeepAlive = keepAlive
+
+	config.MinProtocolVersion = settings["min_protocol_version"]
+	config.MaxProtocolVersion = settings["max_protocol_version"]
+	minProtocolVersion, err := parseProtocolVersion(config.MinProtocolVersion)
+	if err != nil {
+		return nil, &ParseConfigError{ConnString: connString, msg: "invalid min_protocol_version", err: err}
+	}
+	maxProtocolVersion, err := parseProtocolVersion(config.MaxProtocolVersion)
+	if err != nil {
+		return nil, &ParseConfigError{ConnString: connString, msg: "invalid max_protocol_version", err: err}
+	}
+	if minProtocolVersion > maxProtocolVersion {
+		return nil, &ParseConfigError{ConnString: connString, msg: "min_protocol_version cannot be greater than max_protocol_version"}
+	}
+
 	notRuntimeParams := map[string]struct{}{
-		"host":                 {},
-		"port":                 {},
-		"database":             {},
-		"user":                 {},
-		"password":             {},
-		"passfile":             {},
-		"connect_timeout":      {},
-		"sslmode":              {},
-		"sslkey":               {},
-		"sslcert":              {},
-		"sslrootcert":          {},
-		"sslpassword":          {},
-		"sslsni":               {},
-		"krbspn":               {},
-		"krbsrvname":           {},
-		"target_session_attrs": {},
-		"service":              {},
-		"servicefile":          {},
+		"host":                    {},
+		"port":                    {},
+		"database":                {},
+		"user":                    {},
+		"password":                {},
+		"passfile":                {},
+		"connect_timeout":         {},
+		"sslmode":                 {},
+		"sslkey":                  {},
+		"sslcert":                 {},
+		"sslrootcert":             {},
+		"sslpassword":             {},
+		"sslsni":                  {},
+		"krbspn":                  {},
+		"krbsrvname":              {},
+		"target_session_attrs":    {},
+		"service":                 {},
+		"servicefile":             {},
+		"context_watcher_handler": {},
+		"keepalives":              {},
+		"keepalives_idle":         {},
+		"keepalives_interval":     {},
+		"keepalives_count":        {},
+		"min_protocol_version":    {},
+		"max_protocol_version":    {},
+	}
+
+	if s, present := settings["context_watcher_handler"]; present {
+		preset, err := ParseContextWatcherHandlerPreset(s)
+		if err != nil {
+			return nil, &ParseConfigError{ConnString: connString, msg: "invalid context_watcher_handler", err: err}
+		}
+		config.BuildContextWatcherHandler = BuildPresetContextWatcherHandler(preset)
 	}
 
 	// Adding kerberos configuration
@@ -362,8 +518,8 @@ func ParseConfigWithOptions(connString string, options ParseConfigOptions) (*Con
 
 		var tlsConfigs []*tls.Config
 
-		// Ignore TLS settings if Unix domain socket like libpq
-		if network, _ := NetworkAddress(host, port); network == "unix" {
+		// Ignore TLS settings if Unix domain socket or named pipe like libpq
+		if isLocalHost(host) {
 			tlsConfigs = append(tlsConfigs, nil)
 		} else {
 			var err error
@@ -391,7 +547,7 @@ func ParseConfigWithOptions(connString string, options ParseConfigOptions) (*Con
 	if err == nil {
 		if config.Password == "" {
 			host := config.Host
-			if network, _ := NetworkAddress(config.Host, config.Port); network == "unix" {
+			if isLocalHost(config.Host) {
 				host = "localhost"
 			}
 
@@ -419,6 +575,18 @@ func ParseConfigWithOptions(connString string, options ParseConfigOptions) (*Con
 	return config, nil
 }
 
+// parseProtocolVersion parses a min_protocol_version or max_protocol_version value into a protocol version number.
+func parseProtocolVersion(s string) (uint32, error) {
+	switch s {
+	case "", "3.0":
+		return pgproto3.ProtocolVersion30, nil
+	case "3.2", "latest":
+		return pgproto3.ProtocolVersion32, nil
+	default:
+		return 0, fmt.Errorf("unsupported protocol version: %s", s)
+	}
+}
+
 func mergeSettings(settingSets ...map[string]string) map[string]string {
 	settings := make(map[string]string)
 
@@ -452,6 +620,8 @@ func parseEnvSettings() map[string]string {
 		"PGTARGETSESSIONATTRS": "target_session_attrs",
 		"PGSERVICE":            "service",
 		"PGSERVICEFILE":        "servicefile",
+		"PGMINPROTOCOLVERSION": "min_protocol_version",
+		"PGMAXPROTOCOLVERSION": "max_protocol_version",
 	}
 
 	for envname, realname := range nameMap {
@@ -648,6 +818,17 @@ func configTLS(settings map[string]string, thisHost string, parseConfigOptions P
 	sslsni := settings["sslsni"]
 
 	// Match libpq default behavior
+	if sslrootcert == "system" {
+		// libpq 16 only allows the system certificate pool with full verification as public CAs will sign
+		// certificates for any host.
+		switch sslmode {
+		case "":
+			sslmode = "verify-full"
+		case "verify-full":
+		default:
+			return nil, fmt.Errorf(`sslmode %q may not be used with sslrootcert=system (use verify-full)`, sslmode)
+		}
+	}
 	if sslmode == "" {
 		sslmode = "prefer"
 	}
@@ -667,8 +848,6 @@ func configTLS(settings map[string]string, thisHost string, parseConfigOptions P
 			if err != nil {
 				return nil, fmt.Errorf("unable to load system certificate pool: %w", err)
 			}
-
-			sslmode = "verify-full"
 		} else {
 			caCertPool = x509.NewCertPool()
 
@@ -855,18 +1034,34 @@ func parseConnectTimeoutSetting(s string) (time.Duration, error) {
 func makeConnectTimeoutDialFunc(timeout time.Duration) DialFunc {
 	d := makeDefaultDialer()
 	d.Timeout = timeout
-	return d.DialContext
+	return makeDefaultDialFunc(d)
+}
+
+// makeDefaultDialFunc returns a DialFunc that dials with d and that also supports the "pipe" network returned by
+// NetworkAddress for Windows named pipes.
+func makeDefaultDialFunc(d *net.Dialer) DialFunc {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if network == "pipe" {
+			if d.Timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, d.Timeout)
+				defer cancel()
+			}
+			return dialPipe(ctx, address)
+		}
+		return d.DialContext(ctx, network, address)
+	}
 }
 
 // ValidateConnectTargetSessionAttrsReadWrite is a ValidateConnectFunc that implements libpq compatible
 // target_session_attrs=read-write.
 func ValidateConnectTargetSessionAttrsReadWrite(ctx context.Context, pgConn *PgConn) error {
-	result, err := pgConn.Exec(ctx, "show transaction_read_only").ReadAll()
+	readOnly, err := serverIsReadOnly(ctx, pgConn)
 	if err != nil {
 		return err
 	}
 
-	if string(result[0].Rows[0][0]) == "on" {
+	if readOnly {
 		return errors.New("read only connection")
 	}
 
@@ -876,12 +1071,12 @@ func ValidateConnectTargetSessionAttrsReadWrite(ctx context.Context, pgConn *PgC
 // ValidateConnectTargetSessionAttrsReadOnly is a ValidateConnectFunc that implements libpq compatible
 // target_session_attrs=read-only.
 func ValidateConnectTargetSessionAttrsReadOnly(ctx context.Context, pgConn *PgConn) error {
-	result, err := pgConn.Exec(ctx, "show transaction_read_only").ReadAll()
+	readOnly, err := serverIsReadOnly(ctx, pgConn)
 	if err != nil {
 		return err
 	}
 
-	if string(result[0].Rows[0][0]) != "on" {
+	if !readOnly {
 		return errors.New("connection is not read only")
 	}
 
@@ -891,12 +1086,12 @@ func ValidateConnectTargetSessionAttrsReadOnly(ctx context.Context, pgConn *PgCo
 // ValidateConnectTargetSessionAttrsStandby is a ValidateConnectFunc that implements libpq compatible
 // target_session_attrs=standby.
 func ValidateConnectTargetSessionAttrsStandby(ctx context.Context, pgConn *PgConn) error {
-	result, err := pgConn.Exec(ctx, "select pg_is_in_recovery()").ReadAll()
+	inHotStandby, err := serverIsInHotStandby(ctx, pgConn)
 	if err != nil {
 		return err
 	}
 
-	if string(result[0].Rows[0][0]) != "t" {
+	if !inHotStandby {
 		return errors.New("server is not in hot standby mode")
 	}
 
@@ -906,12 +1101,12 @@ func ValidateConnectTargetSessionAttrsStandby(ctx context.Context, pgConn *PgCon
 // ValidateConnectTargetSessionAttrsPrimary is a ValidateConnectFunc that implements libpq compatible
 // target_session_attrs=primary.
 func ValidateConnectTargetSessionAttrsPrimary(ctx context.Context, pgConn *PgConn) error {
-	result, err := pgConn.Exec(ctx, "select pg_is_in_recovery()").ReadAll()
+	inHotStandby, err := serverIsInHotStandby(ctx, pgConn)
 	if err != nil {
 		return err
 	}
 
-	if string(result[0].Rows[0][0]) == "t" {
+	if inHotStandby {
 		return errors.New("server is in standby mode")
 	}
 
@@ -921,14 +1116,48 @@ func ValidateConnectTargetSessionAttrsPrimary(ctx context.Context, pgConn *PgCon
 // ValidateConnectTargetSessionAttrsPreferStandby is a ValidateConnectFunc that implements libpq compatible
 // target_session_attrs=prefer-standby.
 func ValidateConnectTargetSessionAttrsPreferStandby(ctx context.Context, pgConn *PgConn) error {
-	result, err := pgConn.Exec(ctx, "select pg_is_in_recovery()").ReadAll()
+	inHotStandby, err := serverIsInHotStandby(ctx, pgConn)
 	if err != nil {
 		return err
 	}
 
-	if string(result[0].Rows[0][0]) != "t" {
+	if !inHotStandby {
 		return &NotPreferredError{err: errors.New("server is not in hot standby mode")}
 	}
 
 	return nil
 }
+
+// serverIsReadOnly reports whether the server of pgConn only allows read only transactions by default. Like libpq, it
+// uses the in_hot_standby and default_transaction_read_only parameter statuses reported by PostgreSQL 14 and later to
+// avoid a round trip. Otherwise it queries the server.
+func serverIsReadOnly(ctx context.Context, pgConn *PgConn) (bool, error) {
+	inHotStandby, inHotStandbyPresent := pgConn.parameterStatuses["in_hot_standby"]
+	defaultReadOnly, defaultReadOnlyPresent := pgConn.parameterStatuses["default_transaction_read_only"]
+	if inHotStandbyPresent && defaultReadOnlyPresent {
+		return inHotStandby == "on" || defaultReadOnly == "on", nil
+	}
+
+	result, err := pgConn.Exec(ctx, "show transaction_read_only").ReadAll()
+	if err != nil {
+		return false, err
+	}
+
+	return string(result[0].Rows[0][0]) == "on", nil
+}
+
+// serverIsInHotStandby reports whether the server of pgConn is in hot standby mode. Like libpq, it uses the
+// in_hot_standby parameter status reported by PostgreSQL 14 and later to avoid a round trip. Otherwise it queries the
+// server.
+func serverIsInHotStandby(ctx context.Context, pgConn *PgConn) (bool, error) {
+	if inHotStandby, present := pgConn.parameterStatuses["in_hot_standby"]; present {
+		return inHotStandby == "on", nil
+	}
+
+	result, err := pgConn.Exec(ctx, "select pg_is_in_recovery()").ReadAll()
+	if err != nil {
+		return false, err
+	}
+
+	return string(result[0].Rows[0][0]) == "t", nil
+}
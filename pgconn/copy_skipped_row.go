@@ -0,0 +1,62 @@
+package pgconn
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// CopySkippedRow describes a row that the server skipped during COPY FROM with the ON_ERROR ignore and LOG_VERBOSITY
+// verbose options. These options require PostgreSQL 17 or later.
+type CopySkippedRow struct {
+	// Line is the line number of the row in the COPY input.
+	Line int64
+
+	// Column is the name of the column whose input value could not be converted.
+	Column string
+
+	// Value is the input value that could not be converted. It is empty if Null is true.
+	Value string
+
+	// Null is true if the input value that could not be converted was NULL. e.g. NULL input for a NOT NULL domain.
+	Null bool
+
+	// Notice is the notice the row was reported in.
+	Notice *Notice
+}
+
+// CopySkippedRowHandler is a function that handles rows skipped during COPY FROM. The *PgConn is provided so the handler
+// is aware of the origin of the skipped row, but it must not invoke any query method.
+type CopySkippedRowHandler func(*PgConn, *CopySkippedRow)
+
+var copySkippedRowRegexp = regexp.MustCompile(`^skipping row due to data type incompatibility at line (\d+) for column "(.*?)": (?:null input|"(.*)")$`)
+
+// ParseCopySkippedRow parses a notice sent by the server when it skips a row during COPY FROM with the ON_ERROR ignore
+// and LOG_VERBOSITY verbose options. ok is false if notice does not report a skipped row.
+//
+// The server does not report skipped rows in structured fields so the notice message must be parsed. This requires
+// the lc_messages setting to be English.
+func ParseCopySkippedRow(notice *Notice) (row *CopySkippedRow, ok bool) {
+	match := copySkippedRowRegexp.FindStringSubmatchIndex(notice.Message)
+	if match == nil {
+		return nil, false
+	}
+
+	line, err := strconv.ParseInt(notice.Message[match[2]:match[3]], 10, 64)
+	if err != nil {
+		return nil, false
+	}
+
+	row = &CopySkippedRow{
+		Line:   line,
+		Column: notice.Message[match[4]:match[5]],
+		Notice: notice,
+	}
+
+	if match[6] == -1 {
+		row.Null = true
+	} else {
+		row.Value = notice.Message[match[6]:match[7]]
+	}
+
+	return row, true
+}
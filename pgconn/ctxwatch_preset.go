@@ -0,0 +1,90 @@
+package pgconn
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
+)
+
+// ContextWatcherHandlerPreset is a named ctxwatch.Handler configuration that determines what happens when the context
+// of an operation is canceled. It can be set in a connection string with the context_watcher_handler parameter.
+type ContextWatcherHandlerPreset string
+
+const (
+	// ContextWatcherHandlerDeadline immediately sets a deadline on the net.Conn. This interrupts the operation right
+	// away but the connection is closed. This is the default.
+	ContextWatcherHandlerDeadline ContextWatcherHandlerPreset = "deadline"
+
+	// ContextWatcherHandlerCancelDeadline sends a cancel request to the server and sets a deadline on the net.Conn
+	// CancelDeadlineDelay later. If the server cancels the query before the deadline the connection remains usable.
+	ContextWatcherHandlerCancelDeadline ContextWatcherHandlerPreset = "cancel+deadline"
+
+	// ContextWatcherHandlerCancelOnly sends a cancel request to the server and waits for it to cancel the query. The
+	// connection remains usable, but the operation can block indefinitely if the server does not respond.
+	ContextWatcherHandlerCancelOnly ContextWatcherHandlerPreset = "cancel-only"
+)
+
+// CancelDeadlineDelay is the delay between sending the cancel request and setting the deadline for the
+// ContextWatcherHandlerCancelDeadline preset.
+const CancelDeadlineDelay = 5 * time.Second
+
+// ParseContextWatcherHandlerPreset parses s as a ContextWatcherHandlerPreset.
+func ParseContextWatcherHandlerPreset(s string) (ContextWatcherHandlerPreset, error) {
+	switch preset := ContextWatcherHandlerPreset(s); preset {
+	case ContextWatcherHandlerDeadline, ContextWatcherHandlerCancelDeadline, ContextWatcherHandlerCancelOnly:
+		return preset, nil
+	default:
+		return "", fmt.Errorf("unknown context watcher handler preset: %q", s)
+	}
+}
+
+type contextWatcherHandlerPresetCtxKey struct{}
+
+// WithContextWatcherHandlerPreset returns a copy of ctx that causes operations using it to be handled by preset when
+// ctx is canceled instead of the preset of the connection. It only has an effect on connections whose
+// Config.BuildContextWatcherHandler was created by ParseConfig or BuildPresetContextWatcherHandler.
+func WithContextWatcherHandlerPreset(ctx context.Context, preset ContextWatcherHandlerPreset) context.Context {
+	return context.WithValue(ctx, contextWatcherHandlerPresetCtxKey{}, preset)
+}
+
+// BuildPresetContextWatcherHandler returns a function suitable for Config.BuildContextWatcherHandler that uses preset
+// unless it is overridden for an operation with WithContextWatcherHandlerPreset.
+func BuildPresetContextWatcherHandler(preset ContextWatcherHandlerPreset) func(*PgConn) ctxwatch.Handler {
+	return func(pgConn *PgConn) ctxwatch.Handler {
+		return &presetContextWatcherHandler{pgConn: pgConn, preset: preset}
+	}
+}
+
+// presetContextWatcherHandler delegates to the handler of the preset of the canceled context.
+type presetContextWatcherHandler struct {
+	pgConn *PgConn
+	preset ContextWatcherHandlerPreset
+
+	// active is the handler that HandleCancel delegated to.
+	active ctxwatch.Handler
+}
+
+func (h *presetContextWatcherHandler) HandleCancel(ctx context.Context) {
+	preset := h.preset
+	if p, ok := ctx.Value(contextWatcherHandlerPresetCtxKey{}).(ContextWatcherHandlerPreset); ok {
+		preset = p
+	}
+
+	switch preset {
+	case ContextWatcherHandlerCancelDeadline:
+		h.active = &CancelRequestContextWatcherHandler{Conn: h.pgConn, DeadlineDelay: CancelDeadlineDelay}
+	case ContextWatcherHandlerCancelOnly:
+		h.active = &CancelRequestContextWatcherHandler{Conn: h.pgConn, NoDeadline: true}
+	default:
+		h.active = &DeadlineContextWatcherHandler{Conn: h.pgConn.conn, Clock: h.pgConn.config.Clock}
+	}
+
+	h.active.HandleCancel(ctx)
+}
+
+func (h *presetContextWatcherHandler) HandleUnwatchAfterCancel() {
+	h.active.HandleUnwatchAfterCancel()
+	h.active = nil
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package pgconn
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+// dialPipe is not supported on this platform.
+func dialPipe(ctx context.Context, address string) (net.Conn, error) {
+	return nil, errors.New("named pipes are only supported on Windows")
+}
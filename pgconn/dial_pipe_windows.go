@@ -0,0 +1,177 @@
+package pgconn
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// errorPipeBusy is the Windows ERROR_PIPE_BUSY error. It is returned when all instances of a named pipe are in use.
+const errorPipeBusy = syscall.Errno(231)
+
+// errorNotFound is the Windows ERROR_NOT_FOUND error. It is returned by CancelIoEx when there is no I/O to cancel.
+const errorNotFound = syscall.Errno(1168)
+
+// dialPipe opens the Windows named pipe at address. It waits for an instance of the pipe to become available until ctx
+// is done.
+func dialPipe(ctx context.Context, address string) (net.Conn, error) {
+	for {
+		f, err := os.OpenFile(address, os.O_RDWR, 0)
+		if err == nil {
+			return &pipeConn{File: f, addr: pipeAddr(address)}, nil
+		}
+		if !errors.Is(err, errorPipeBusy) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// pipeAddr is the net.Addr of a Windows named pipe.
+type pipeAddr string
+
+func (a pipeAddr) Network() string { return "pipe" }
+func (a pipeAddr) String() string  { return string(a) }
+
+// pipeConn adapts a Windows named pipe opened as a file to net.Conn. The pipe is not opened for overlapped I/O so the
+// file does not support deadlines. Instead, pipeConn cancels the pending I/O on the pipe with CancelIoEx when a deadline
+// is reached. A read or write that is interrupted or started after its deadline returns os.ErrDeadlineExceeded. This
+// allows a canceled context to interrupt a blocked read or write.
+type pipeConn struct {
+	*os.File
+	addr pipeAddr
+
+	mux           sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+	timer         *time.Timer
+
+	pendingReads  atomic.Int32
+	pendingWrites atomic.Int32
+}
+
+func (c *pipeConn) LocalAddr() net.Addr  { return c.addr }
+func (c *pipeConn) RemoteAddr() net.Addr { return c.addr }
+
+func (c *pipeConn) Read(b []byte) (int, error) {
+	// The pending count must be incremented before the deadline is checked. See cancelOnDeadline.
+	c.pendingReads.Add(1)
+	defer c.pendingReads.Add(-1)
+
+	if c.deadlinePassed(true) {
+		return 0, os.ErrDeadlineExceeded
+	}
+
+	n, err := c.File.Read(b)
+	if err != nil && c.deadlinePassed(true) {
+		err = os.ErrDeadlineExceeded
+	}
+	return n, err
+}
+
+func (c *pipeConn) Write(b []byte) (int, error) {
+	c.pendingWrites.Add(1)
+	defer c.pendingWrites.Add(-1)
+
+	if c.deadlinePassed(false) {
+		return 0, os.ErrDeadlineExceeded
+	}
+
+	n, err := c.File.Write(b)
+	if err != nil && c.deadlinePassed(false) {
+		err = os.ErrDeadlineExceeded
+	}
+	return n, err
+}
+
+func (c *pipeConn) Close() error {
+	c.mux.Lock()
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	c.mux.Unlock()
+
+	return c.File.Close()
+}
+
+func (c *pipeConn) SetDeadline(t time.Time) error {
+	return c.setDeadline(t, true, true)
+}
+
+func (c *pipeConn) SetReadDeadline(t time.Time) error {
+	return c.setDeadline(t, true, false)
+}
+
+func (c *pipeConn) SetWriteDeadline(t time.Time) error {
+	return c.setDeadline(t, false, true)
+}
+
+func (c *pipeConn) setDeadline(t time.Time, read, write bool) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if read {
+		c.readDeadline = t
+	}
+	if write {
+		c.writeDeadline = t
+	}
+
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+
+	next := c.readDeadline
+	if next.IsZero() || (!c.writeDeadline.IsZero() && c.writeDeadline.Before(next)) {
+		next = c.writeDeadline
+	}
+	if !next.IsZero() {
+		c.timer = time.AfterFunc(time.Until(next), c.cancelOnDeadline)
+	}
+
+	return nil
+}
+
+func (c *pipeConn) deadlinePassed(read bool) bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	deadline := c.writeDeadline
+	if read {
+		deadline = c.readDeadline
+	}
+	return !deadline.IsZero() && !time.Now().Before(deadline)
+}
+
+// cancelOnDeadline cancels the pending I/O whose deadline has passed. A read or write may be about to start when the
+// deadline passes, so the I/O is canceled until none is pending. A read or write that starts after the pending count is
+// checked sees the passed deadline itself.
+func (c *pipeConn) cancelOnDeadline() {
+	for {
+		readPending := c.deadlinePassed(true) && c.pendingReads.Load() > 0
+		writePending := c.deadlinePassed(false) && c.pendingWrites.Load() > 0
+		if !readPending && !writePending {
+			return
+		}
+
+		// CancelIoEx cancels all I/O on the handle, including synchronous I/O issued by other threads.
+		err := syscall.CancelIoEx(syscall.Handle(c.File.Fd()), nil)
+		if err != nil && !errors.Is(err, errorNotFound) {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -32,6 +32,11 @@ This can be especially useful when queries that are frequently canceled and the
 a problem. DeadlineContextWatcherHandler and CancelRequestContextWatcherHandler can be used to introduce a delay before
 interrupting the query in such a way as to close the connection.
 
+The context_watcher_handler connection string parameter selects one of several preset behaviors without writing a
+BuildContextWatcherHandler. "deadline" (the default) closes the connection immediately, "cancel+deadline" sends a
+cancel request to the server before falling back to closing the connection, and "cancel-only" only sends a cancel
+request. The preset can be overridden for a single operation with WithContextWatcherHandlerPreset.
+
 The CancelRequest method may be used to request the PostgreSQL server cancel an in-progress query without forcing the
 client to abort.
 */
@@ -2,12 +2,16 @@ package pgconn
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/url"
 	"regexp"
 	"strings"
+	"syscall"
+	"time"
 )
 
 // SafeToRetry checks if the err is guaranteed to have occurred before sending any data to the server.
@@ -26,6 +30,79 @@ func Timeout(err error) bool {
 	return errors.As(err, &timeoutErr)
 }
 
+// ErrorKind is the category of an error returned by pgconn. See ClassifyError.
+type ErrorKind int
+
+const (
+	// ErrorKindUnknown is an error that could not be categorized.
+	ErrorKindUnknown ErrorKind = iota
+
+	// ErrorKindCanceled is an operation interrupted by the cancellation of its context.
+	ErrorKindCanceled
+
+	// ErrorKindDeadlineExceeded is an operation interrupted by the deadline of its context or a network timeout.
+	ErrorKindDeadlineExceeded
+
+	// ErrorKindNetworkUnreachable is a server that could not be reached or a connection that was lost. e.g. a dial error,
+	// a connection reset, or an unexpected EOF.
+	ErrorKindNetworkUnreachable
+
+	// ErrorKindServer is an error reported by the PostgreSQL server. The error wraps a *PgError.
+	ErrorKindServer
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorKindCanceled:
+		return "canceled"
+	case ErrorKindDeadlineExceeded:
+		return "deadline exceeded"
+	case ErrorKindNetworkUnreachable:
+		return "network unreachable"
+	case ErrorKindServer:
+		return "server error"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrorClass is the classification of an error returned by ClassifyError.
+type ErrorClass struct {
+	Kind ErrorKind
+
+	// SafeToRetry is true if the error is guaranteed to have occurred before sending any data to the server. See
+	// SafeToRetry.
+	SafeToRetry bool
+}
+
+// ClassifyError classifies err. It is intended for uniform handling of errors in retry middleware and for labeling
+// metrics. Context cancellation takes precedence over a server error, which takes precedence over a network error. A
+// nil err is classified as ErrorKindUnknown.
+func ClassifyError(err error) ErrorClass {
+	return ErrorClass{Kind: errorKind(err), SafeToRetry: SafeToRetry(err)}
+}
+
+func errorKind(err error) ErrorKind {
+	var pgErr *PgError
+	var netErr net.Error
+
+	switch {
+	case err == nil:
+		return ErrorKindUnknown
+	case errors.Is(err, context.Canceled):
+		return ErrorKindCanceled
+	case errors.Is(err, context.DeadlineExceeded), Timeout(err), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorKindDeadlineExceeded
+	case errors.As(err, &pgErr):
+		return ErrorKindServer
+	case errors.As(err, &netErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNABORTED), errors.Is(err, syscall.EPIPE):
+		return ErrorKindNetworkUnreachable
+	default:
+		return ErrorKindUnknown
+	}
+}
+
 // PgError represents an error reported by the PostgreSQL server. See
 // http://www.postgresql.org/docs/11/static/protocol-error-fields.html for
 // detailed field description.
@@ -105,6 +182,27 @@ func (e *connLockError) Error() string {
 	return e.status
 }
 
+// ConnectionClosedError is returned when an operation is attempted on a closed connection. Err is the error that caused
+// the connection to be closed, or nil if it was closed with Close.
+type ConnectionClosedError struct {
+	Err error
+}
+
+func (e *ConnectionClosedError) SafeToRetry() bool {
+	return true // the operation was not attempted on the closed connection.
+}
+
+func (e *ConnectionClosedError) Error() string {
+	if e.Err == nil {
+		return "conn closed"
+	}
+	return fmt.Sprintf("conn closed: %v", e.Err)
+}
+
+func (e *ConnectionClosedError) Unwrap() error {
+	return e.Err
+}
+
 // ParseConfigError is the error returned when a connection string cannot be parsed.
 type ParseConfigError struct {
 	ConnString string // The connection string that could not be parsed.
@@ -127,6 +225,14 @@ func (e *ParseConfigError) Unwrap() error {
 	return e.err
 }
 
+// NormalizeTimeoutError converts a network timeout error caused by the cancellation or deadline of ctx into the error
+// pgconn would return. If ctx was canceled it returns context.Canceled. Otherwise, a timeout error is returned for which
+// Timeout returns true. Other errors are returned unchanged. It is useful for code that sets deadlines on the
+// underlying net.Conn of a *PgConn, e.g. through Hijack or a custom CtxWatcherHandler.
+func NormalizeTimeoutError(ctx context.Context, err error) error {
+	return normalizeTimeoutError(ctx, err)
+}
+
 func normalizeTimeoutError(ctx context.Context, err error) error {
 	var netErr net.Error
 	if errors.As(err, &netErr) && netErr.Timeout() {
@@ -142,6 +248,22 @@ func normalizeTimeoutError(ctx context.Context, err error) error {
 	return err
 }
 
+// ProtocolError occurs when a message could not be read from the server. It is only returned when
+// Config.ProtocolHistorySize is greater than zero. RecentMessages contains the most recent protocol messages sent and
+// received before the error, oldest first, and is suitable for inclusion in a bug report.
+type ProtocolError struct {
+	Err            error
+	RecentMessages []string
+}
+
+func (e *ProtocolError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ProtocolError) Unwrap() error {
+	return e.Err
+}
+
 type pgconnError struct {
 	msg         string
 	err         error
@@ -246,3 +368,150 @@ func (e *NotPreferredError) SafeToRetry() bool {
 func (e *NotPreferredError) Unwrap() error {
 	return e.err
 }
+
+// ConnCheckCause is the category of failure found by CheckConn or Ping.
+type ConnCheckCause int
+
+const (
+	// ConnCheckCauseUnknown is a failure that could not be categorized.
+	ConnCheckCauseUnknown ConnCheckCause = iota
+
+	// ConnCheckCauseServerTerminated is a server that ended the session with an error. e.g. idle_session_timeout,
+	// idle_in_transaction_session_timeout, or an administrator terminating the backend. The server is reachable and an
+	// immediate reconnect is usually appropriate. ServerError has the details.
+	ConnCheckCauseServerTerminated
+
+	// ConnCheckCauseClosed is a connection that was closed by the peer without an error message (FIN received). This is
+	// typically a server restart or a proxy or load balancer closing an idle connection.
+	ConnCheckCauseClosed
+
+	// ConnCheckCauseReset is a connection that was reset (RST received or broken pipe). This typically indicates a
+	// network device dropping the connection or a server crash.
+	ConnCheckCauseReset
+
+	// ConnCheckCauseTimeout is a connection that did not respond in time. This typically indicates a network partition
+	// or an overloaded server. Backing off before reconnecting is usually appropriate.
+	ConnCheckCauseTimeout
+
+	// ConnCheckCauseTLSAlert is a TLS alert received from the peer.
+	ConnCheckCauseTLSAlert
+)
+
+func (c ConnCheckCause) String() string {
+	switch c {
+	case ConnCheckCauseServerTerminated:
+		return "server terminated"
+	case ConnCheckCauseClosed:
+		return "closed"
+	case ConnCheckCauseReset:
+		return "reset"
+	case ConnCheckCauseTimeout:
+		return "timeout"
+	case ConnCheckCauseTLSAlert:
+		return "TLS alert"
+	default:
+		return "unknown"
+	}
+}
+
+// ConnCheckError is returned by CheckConn and by Ping when the connection is broken. Cause categorizes the failure so
+// callers can decide whether to reconnect immediately or back off.
+type ConnCheckError struct {
+	Cause ConnCheckCause
+	Err   error
+}
+
+func (e *ConnCheckError) Error() string {
+	return fmt.Sprintf("connection check failed (%v): %s", e.Cause, e.Err.Error())
+}
+
+func (e *ConnCheckError) SafeToRetry() bool {
+	return SafeToRetry(e.Err)
+}
+
+func (e *ConnCheckError) Unwrap() error {
+	return e.Err
+}
+
+// ServerError returns the error sent by the server when Cause is ConnCheckCauseServerTerminated. Otherwise it returns
+// nil.
+func (e *ConnCheckError) ServerError() *PgError {
+	var pgErr *PgError
+	if errors.As(e.Err, &pgErr) {
+		return pgErr
+	}
+	return nil
+}
+
+func newConnCheckError(err error) *ConnCheckError {
+	return &ConnCheckError{Cause: connCheckCause(err), Err: err}
+}
+
+func connCheckCause(err error) ConnCheckCause {
+	var pgErr *PgError
+	var alertErr tls.AlertError
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &pgErr):
+		return ConnCheckCauseServerTerminated
+	case errors.As(err, &alertErr):
+		return ConnCheckCauseTLSAlert
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ConnCheckCauseClosed
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNABORTED), errors.Is(err, syscall.EPIPE):
+		return ConnCheckCauseReset
+	case Timeout(err), errors.As(err, &netErr) && netErr.Timeout():
+		return ConnCheckCauseTimeout
+	default:
+		return ConnCheckCauseUnknown
+	}
+}
+
+// ParameterStatusChangeError is returned when the server reports a new value for a parameter listed in
+// Config.GuardedParameterStatuses and Config.OnGuardedParameterStatusChange does not accept it. The connection is
+// closed.
+type ParameterStatusChangeError struct {
+	Name     string
+	OldValue string
+	NewValue string
+	Err      error // the error returned by Config.OnGuardedParameterStatusChange, if any
+}
+
+func (e *ParameterStatusChangeError) Error() string {
+	msg := fmt.Sprintf("unexpected change of parameter %s from %q to %q", e.Name, e.OldValue, e.NewValue)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ParameterStatusChangeError) Unwrap() error {
+	return e.Err
+}
+
+// ResultTooLargeError is returned when the row values of a query result exceed Config.MaxResultBytes.
+type ResultTooLargeError struct {
+	MaxResultBytes int64 // the configured limit
+	Rows           int64 // the number of rows read, including the row that exceeded the limit
+}
+
+func (e *ResultTooLargeError) Error() string {
+	return fmt.Sprintf("result exceeded max result size of %d bytes after %d rows", e.MaxResultBytes, e.Rows)
+}
+
+// RowTimeoutError is returned when the next row of a result was not received within the timeout set with
+// ResultReader.SetRowTimeout. It matches context.DeadlineExceeded with errors.Is. Err is the error that resulted from
+// canceling the query.
+type RowTimeoutError struct {
+	Timeout time.Duration
+	Err     error
+}
+
+func (e *RowTimeoutError) Error() string {
+	return fmt.Sprintf("no row received within %v: %v", e.Timeout, e.Err)
+}
+
+func (e *RowTimeoutError) Unwrap() []error {
+	return []error{context.DeadlineExceeded, e.Err}
+}
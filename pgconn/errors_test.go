@@ -1,6 +1,13 @@
 package pgconn_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
 	"testing"
 
 	"github.com/jackc/pgx/v5/pgconn"
@@ -52,3 +59,42 @@ func TestConfigError(t *testing.T) {
 		})
 	}
 }
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		kind        pgconn.ErrorKind
+		safeToRetry bool
+	}{
+		{name: "nil", err: nil, kind: pgconn.ErrorKindUnknown},
+		{name: "canceled", err: fmt.Errorf("query: %w", context.Canceled), kind: pgconn.ErrorKindCanceled},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, kind: pgconn.ErrorKindDeadlineExceeded},
+		{
+			name: "normalized network timeout",
+			err:  pgconn.NormalizeTimeoutError(context.Background(), &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}),
+			kind: pgconn.ErrorKindDeadlineExceeded,
+		},
+		{name: "server error", err: &pgconn.PgError{Code: "23505"}, kind: pgconn.ErrorKindServer},
+		{name: "unexpected EOF", err: fmt.Errorf("read: %w", io.ErrUnexpectedEOF), kind: pgconn.ErrorKindNetworkUnreachable},
+		{name: "connection reset", err: &net.OpError{Op: "read", Err: syscall.ECONNRESET}, kind: pgconn.ErrorKindNetworkUnreachable},
+		{name: "unknown", err: errors.New("something else"), kind: pgconn.ErrorKindUnknown},
+		{name: "safe to retry", err: &safeToRetryError{err: io.EOF}, kind: pgconn.ErrorKindNetworkUnreachable, safeToRetry: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			class := pgconn.ClassifyError(tt.err)
+			assert.Equal(t, tt.kind, class.Kind)
+			assert.Equal(t, tt.safeToRetry, class.SafeToRetry)
+		})
+	}
+}
+
+type safeToRetryError struct {
+	err error
+}
+
+func (e *safeToRetryError) Error() string     { return e.err.Error() }
+func (e *safeToRetryError) SafeToRetry() bool { return true }
+func (e *safeToRetryError) Unwrap() error     { return e.err }
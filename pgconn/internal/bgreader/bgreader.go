@@ -21,6 +21,11 @@ type BGReader struct {
 	cond        *sync.Cond
 	status      int32
 	readResults []readResult
+
+	// maxBufferedBytes is the number of buffered bytes at which the background reader waits for them to be read before
+	// reading more. If 0, there is no limit.
+	maxBufferedBytes int
+	bufferedBytes    int
 }
 
 type readResult struct {
@@ -28,19 +33,42 @@ type readResult struct {
 	err error
 }
 
-// Start starts the backgrounder reader. If the background reader is already running this is a no-op. The background
-// reader will stop automatically when the underlying reader returns an error.
+// Start starts the backgrounder reader. If the background reader is already running this is a no-op except that any
+// limit set by StartLimited is removed. The background reader will stop automatically when the underlying reader
+// returns an error.
 func (r *BGReader) Start() {
 	r.cond.L.Lock()
 	defer r.cond.L.Unlock()
 
+	r.maxBufferedBytes = 0
+
 	switch r.status {
 	case StatusStopped:
 		r.status = StatusRunning
 		go r.bgRead()
+	case StatusRunning:
+		// Wake the background reader if it is waiting for buffered bytes to be read.
+		r.cond.Broadcast()
+	case StatusStopping:
+		r.status = StatusRunning
+	}
+}
+
+// StartLimited starts the background reader like Start, but the background reader waits when maxBufferedBytes or more
+// bytes are buffered until enough of them are read. If the background reader is already running this is a no-op.
+func (r *BGReader) StartLimited(maxBufferedBytes int) {
+	r.cond.L.Lock()
+	defer r.cond.L.Unlock()
+
+	switch r.status {
+	case StatusStopped:
+		r.maxBufferedBytes = maxBufferedBytes
+		r.status = StatusRunning
+		go r.bgRead()
 	case StatusRunning:
 		// no-op
 	case StatusStopping:
+		r.maxBufferedBytes = maxBufferedBytes
 		r.status = StatusRunning
 	}
 }
@@ -56,6 +84,8 @@ func (r *BGReader) Stop() {
 		// no-op
 	case StatusRunning:
 		r.status = StatusStopping
+		// Wake the background reader if it is waiting for buffered bytes to be read.
+		r.cond.Broadcast()
 	case StatusStopping:
 		// no-op
 	}
@@ -71,12 +101,17 @@ func (r *BGReader) Status() int32 {
 func (r *BGReader) bgRead() {
 	keepReading := true
 	for keepReading {
+		if !r.waitForBufferSpace() {
+			return
+		}
+
 		buf := iobufpool.Get(8192)
 		n, err := r.r.Read(*buf)
 		*buf = (*buf)[:n]
 
 		r.cond.L.Lock()
 		r.readResults = append(r.readResults, readResult{buf: buf, err: err})
+		r.bufferedBytes += n
 		if r.status == StatusStopping || err != nil {
 			r.status = StatusStopped
 			keepReading = false
@@ -86,6 +121,29 @@ func (r *BGReader) bgRead() {
 	}
 }
 
+// waitForBufferSpace waits until fewer than maxBufferedBytes bytes are buffered. It returns false if the background
+// reader was stopped while waiting.
+func (r *BGReader) waitForBufferSpace() bool {
+	r.cond.L.Lock()
+	defer r.cond.L.Unlock()
+
+	if r.maxBufferedBytes == 0 || r.bufferedBytes < r.maxBufferedBytes {
+		return true
+	}
+
+	for r.status == StatusRunning && r.maxBufferedBytes > 0 && r.bufferedBytes >= r.maxBufferedBytes {
+		r.cond.Wait()
+	}
+
+	if r.status == StatusStopping {
+		r.status = StatusStopped
+		r.cond.Broadcast()
+		return false
+	}
+
+	return true
+}
+
 // Read implements the io.Reader interface.
 func (r *BGReader) Read(p []byte) (int, error) {
 	r.cond.L.Lock()
@@ -100,11 +158,15 @@ func (r *BGReader) Read(p []byte) (int, error) {
 		return r.r.Read(p)
 	}
 
-	// Wait for results from the background reader
-	for len(r.readResults) == 0 {
+	// Wait for results from the background reader. The background reader may stop without a result if it was stopped
+	// while waiting for buffered bytes to be read.
+	for len(r.readResults) == 0 && r.status != StatusStopped {
 		r.cond.Wait()
 	}
-	return r.readFromReadResults(p)
+	if len(r.readResults) > 0 {
+		return r.readFromReadResults(p)
+	}
+	return r.r.Read(p)
 }
 
 // readBackgroundResults reads a result previously read by the background reader. r.cond.L must be held.
@@ -113,6 +175,10 @@ func (r *BGReader) readFromReadResults(p []byte) (int, error) {
 	var err error
 
 	n := copy(p, *buf)
+	r.bufferedBytes -= n
+	if r.maxBufferedBytes > 0 {
+		r.cond.Broadcast()
+	}
 	if n == len(*buf) {
 		err = r.readResults[0].err
 		iobufpool.Put(buf)
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"math/rand"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -94,6 +95,53 @@ func TestBGReaderErrorWhenStopped(t *testing.T) {
 	require.EqualError(t, err, "oops")
 }
 
+type countingReader struct {
+	reads atomic.Int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	cr.reads.Add(1)
+	for i := range p {
+		p[i] = 'x'
+	}
+	return len(p), nil
+}
+
+func TestBGReaderStartLimited(t *testing.T) {
+	cr := &countingReader{}
+	bgr := bgreader.New(cr)
+
+	// The background reader reads 8192 bytes at a time so it stops reading after 2 reads.
+	bgr.StartLimited(16384)
+	require.Eventually(t, func() bool { return cr.reads.Load() == 2 }, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	require.EqualValues(t, 2, cr.reads.Load())
+
+	// Reading buffered bytes allows the background reader to continue.
+	buf := make([]byte, 8192)
+	_, err := io.ReadFull(bgr, buf)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return cr.reads.Load() == 3 }, time.Second, time.Millisecond)
+
+	// Stopping a background reader that is waiting for buffered bytes to be read stops it immediately.
+	bgr.Stop()
+	require.Eventually(t, func() bool { return bgr.Status() == bgreader.StatusStopped }, time.Second, time.Millisecond)
+	require.EqualValues(t, 3, cr.reads.Load())
+
+	// Buffered bytes are read before reading directly from the underlying reader.
+	buf = make([]byte, 3*8192)
+	_, err = io.ReadFull(bgr, buf)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, cr.reads.Load())
+
+	// Start removes the limit.
+	bgr.StartLimited(8192)
+	require.Eventually(t, func() bool { return cr.reads.Load() == 5 }, time.Second, time.Millisecond)
+	bgr.Start()
+	require.Eventually(t, func() bool { return cr.reads.Load() > 10 }, time.Second, time.Millisecond)
+	bgr.Stop()
+}
+
 type numberReader struct {
 	v   uint8
 	rng *rand.Rand
@@ -123,8 +171,10 @@ func TestBGReaderStress(t *testing.T) {
 	for bytesRead < 1_000_000 {
 		randomNumber := rng.Intn(100)
 		switch {
-		case randomNumber < 10:
+		case randomNumber < 5:
 			go bgr.Start()
+		case randomNumber < 10:
+			go bgr.StartLimited(20_000)
 		case randomNumber < 20:
 			go bgr.Stop()
 		default:
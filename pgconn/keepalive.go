@@ -0,0 +1,117 @@
+package pgconn
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"time"
+)
+
+// KeepAliveConfig configures TCP keepalive probes. Probes detect connections that were silently dropped, e.g. by a NAT
+// gateway or firewall with an idle timeout shorter than the interval between queries. The zero value leaves the
+// settings of the connection returned by DialFunc unchanged.
+type KeepAliveConfig struct {
+	// Disable turns off keepalive probes. It corresponds to the libpq keepalives=0 parameter.
+	Disable bool
+
+	// Idle is the time a connection must be idle before the first probe is sent. It corresponds to the libpq
+	// keepalives_idle parameter.
+	Idle time.Duration
+
+	// Interval is the time between probes. It corresponds to the libpq keepalives_interval parameter. It is only
+	// supported on Linux.
+	Interval time.Duration
+
+	// Count is the number of unanswered probes after which the connection is considered dead. It corresponds to the libpq
+	// keepalives_count parameter. It is only supported on Linux.
+	Count int
+}
+
+func (c KeepAliveConfig) isZero() bool {
+	return c == KeepAliveConfig{}
+}
+
+// applyKeepAlive applies config to conn if it is a TCP connection.
+func applyKeepAlive(conn net.Conn, config KeepAliveConfig) error {
+	if config.isZero() {
+		return nil
+	}
+
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+
+	if config.Disable {
+		return tcpConn.SetKeepAlive(false)
+	}
+
+	err := tcpConn.SetKeepAlive(true)
+	if err != nil {
+		return err
+	}
+
+	if config.Idle > 0 {
+		// On some platforms this also sets the interval between probes. Interval is applied afterwards to override it.
+		err = tcpConn.SetKeepAlivePeriod(config.Idle)
+		if err != nil {
+			return err
+		}
+	}
+
+	if config.Interval > 0 || config.Count > 0 {
+		err = setKeepAliveIntervalAndCount(tcpConn, config.Interval, config.Count)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseKeepAliveSettings parses the libpq compatible keepalive settings.
+func parseKeepAliveSettings(settings map[string]string) (KeepAliveConfig, string, error) {
+	var config KeepAliveConfig
+
+	if s, ok := settings["keepalives"]; ok {
+		switch s {
+		case "0":
+			config.Disable = true
+		case "1":
+		default:
+			return config, "keepalives", errors.New("must be 0 or 1")
+		}
+	}
+
+	for _, d := range []struct {
+		name string
+		dst  *time.Duration
+	}{
+		{"keepalives_idle", &config.Idle},
+		{"keepalives_interval", &config.Interval},
+	} {
+		if s, ok := settings[d.name]; ok {
+			n, err := strconv.ParseInt(s, 10, 32)
+			if err != nil {
+				return config, d.name, err
+			}
+			if n < 0 {
+				return config, d.name, errors.New("negative duration")
+			}
+			*d.dst = time.Duration(n) * time.Second
+		}
+	}
+
+	if s, ok := settings["keepalives_count"]; ok {
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return config, "keepalives_count", err
+		}
+		if n < 0 {
+			return config, "keepalives_count", errors.New("negative count")
+		}
+		config.Count = int(n)
+	}
+
+	return config, "", nil
+}
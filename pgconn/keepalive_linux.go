@@ -0,0 +1,32 @@
+package pgconn
+
+import (
+	"net"
+	"syscall"
+	"time"
+)
+
+func setKeepAliveIntervalAndCount(conn *net.TCPConn, interval time.Duration, count int) error {
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var sockErr error
+	err = rawConn.Control(func(fd uintptr) {
+		if interval > 0 {
+			secs := int((interval + time.Second - 1) / time.Second)
+			sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_KEEPINTVL, secs)
+			if sockErr != nil {
+				return
+			}
+		}
+		if count > 0 {
+			sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_KEEPCNT, count)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return sockErr
+}
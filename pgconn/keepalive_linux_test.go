@@ -0,0 +1,69 @@
+package pgconn_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectAppliesKeepAlive(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	config, err := pgconn.ParseConfig("host=" + host + " port=" + port + " sslmode=disable keepalives_idle=30 keepalives_interval=7 keepalives_count=4")
+	require.NoError(t, err)
+
+	var keepAlive, idle, interval, count int
+	var sockoptErr error
+	buildFrontend := config.BuildFrontend
+	config.BuildFrontend = func(r io.Reader, w io.Writer) *pgproto3.Frontend {
+		// BuildFrontend is called after the keepalive settings were applied and before the startup message is sent.
+		rawConn, err := w.(*net.TCPConn).SyscallConn()
+		if err != nil {
+			sockoptErr = err
+			return buildFrontend(r, w)
+		}
+		rawConn.Control(func(fd uintptr) {
+			keepAlive, sockoptErr = syscall.GetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_KEEPALIVE)
+			idle, _ = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_KEEPIDLE)
+			interval, _ = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_KEEPINTVL)
+			count, _ = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_KEEPCNT)
+		})
+		return buildFrontend(r, w)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The server closes the connection immediately so connecting fails after the frontend is built.
+	_, err = pgconn.ConnectConfig(ctx, config)
+	require.Error(t, err)
+	require.NoError(t, sockoptErr)
+
+	assert.Equal(t, 1, keepAlive)
+	assert.Equal(t, 30, idle)
+	assert.Equal(t, 7, interval)
+	assert.Equal(t, 4, count)
+}
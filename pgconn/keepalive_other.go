@@ -0,0 +1,13 @@
+//go:build !linux
+
+package pgconn
+
+import (
+	"net"
+	"time"
+)
+
+// setKeepAliveIntervalAndCount is not supported on this platform. The settings are ignored.
+func setKeepAliveIntervalAndCount(conn *net.TCPConn, interval time.Duration, count int) error {
+	return nil
+}
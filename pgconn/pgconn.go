@@ -12,6 +12,8 @@ import (
 	"io"
 	"math"
 	"net"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -30,6 +32,7 @@ const (
 	connStatusClosed
 	connStatusIdle
 	connStatusBusy
+	connStatusLazy
 )
 
 // Notice represents a notice response message reported by the PostgreSQL server. Be aware that this is distinct from
@@ -59,29 +62,53 @@ type BuildFrontendFunc func(r io.Reader, w io.Writer) *pgproto3.Frontend
 // aware of the origin of the error, but it must not invoke any query method.
 type PgErrorHandler func(*PgConn, *PgError) bool
 
+// CloseHandler is a function that is called when a connection is closed. err is the error that caused the connection to
+// be closed, or nil if it was closed with Close. The *PgConn is provided so the handler is aware of which connection was
+// closed, but it must not invoke any query method.
+type CloseHandler func(pgConn *PgConn, err error)
+
 // NoticeHandler is a function that can handle notices received from the PostgreSQL server. Notices can be received at
 // any time, usually during handling of a query response. The *PgConn is provided so the handler is aware of the origin
 // of the notice, but it must not invoke any query method. Be aware that this is distinct from LISTEN/NOTIFY
 // notification.
 type NoticeHandler func(*PgConn, *Notice)
 
+// ParameterStatusChangeHandler is a function that handles a change of a parameter listed in
+// Config.GuardedParameterStatuses. Returning an error closes the connection. The *PgConn is provided so the handler is
+// aware of the origin of the change, but it must not invoke any query method.
+type ParameterStatusChangeHandler func(pgConn *PgConn, name, oldValue, newValue string) error
+
+// DefaultGuardedParameterStatuses are parameters that pgx relies on and that should not change during a session.
+// client_encoding and standard_conforming_strings affect how text is encoded and how values are quoted by the simple
+// protocol. search_path is only reported by PostgreSQL 18 and later.
+var DefaultGuardedParameterStatuses = []string{"client_encoding", "standard_conforming_strings", "search_path"}
+
 // NotificationHandler is a function that can handle notifications received from the PostgreSQL server. Notifications
 // can be received at any time, usually during handling of a query response. The *PgConn is provided so the handler is
 // aware of the origin of the notice, but it must not invoke any query method. Be aware that this is distinct from a
 // notice event.
 type NotificationHandler func(*PgConn, *Notification)
 
+// UnknownMessageHandler is a function that handles backend messages with a type that is not part of the PostgreSQL
+// protocol as implemented by pgproto3. msg is only valid until the handler returns. The *PgConn is provided so the
+// handler is aware of the origin of the message, but it must not invoke any query method.
+type UnknownMessageHandler func(pgConn *PgConn, msg *pgproto3.UnknownMessage)
+
 // PgConn is a low-level PostgreSQL connection handle. It is not safe for concurrent usage.
 type PgConn struct {
 	conn              net.Conn
 	pid               uint32            // backend pid
 	secretKey         uint32            // key to use to send a cancel query message to the server
+	extendedSecretKey []byte            // secret key used instead of secretKey when the server sends one that is not 4 bytes
+	protocolVersion   uint32            // protocol version in use
 	parameterStatuses map[string]string // parameters that have been reported by the server
 	txStatus          byte
 	frontend          *pgproto3.Frontend
 	bgReader          *bgreader.BGReader
-	slowWriteTimer    *time.Timer
+	slowWriteTimer    Timer
 	bgReaderStarted   chan struct{}
+	protocolHistory   *protocolHistory
+	closeErr          error // error that caused the connection to be closed; nil if closed with Close
 
 	customData map[string]any
 
@@ -143,6 +170,76 @@ func ConnectConfig(ctx context.Context, config *Config) (*PgConn, error) {
 		panic("config must be created by ParseConfig")
 	}
 
+	pgConn := new(PgConn)
+	err := connectConfig(ctx, config, pgConn)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgConn, nil
+}
+
+// NewLazyConn returns a *PgConn that is not connected until Connect is called or until the first operation that
+// communicates with the server. This avoids the cost of connections that may never be used. Any error connecting is
+// returned by that operation and the connection may be retried by the next operation. config must have been
+// constructed with [ParseConfig].
+//
+// Until it is connected, the *PgConn is not closed, its PID and SecretKey are 0, its TxStatus is 'I', and Conn returns
+// nil.
+func NewLazyConn(config *Config) *PgConn {
+	// Default values are set in ParseConfig. Enforce initial creation by ParseConfig rather than setting defaults from
+	// zero values.
+	if !config.createdByParseConfig {
+		panic("config must be created by ParseConfig")
+	}
+
+	pgConn := new(PgConn)
+	pgConn.reset(config)
+	pgConn.status = connStatusLazy
+	pgConn.txStatus = 'I'
+
+	return pgConn
+}
+
+// Connect connects a *PgConn created by NewLazyConn. It does nothing if the *PgConn is already connected. ctx can be used
+// to cancel a connect attempt.
+func (pgConn *PgConn) Connect(ctx context.Context) error {
+	switch pgConn.status {
+	case connStatusLazy:
+	case connStatusClosed:
+		return &ConnectionClosedError{Err: pgConn.closeErr}
+	default:
+		return nil
+	}
+
+	config := pgConn.config
+	err := connectConfig(ctx, config, pgConn)
+	if err != nil {
+		pgConn.reset(config)
+		pgConn.status = connStatusLazy
+		pgConn.txStatus = 'I'
+		return err
+	}
+
+	return nil
+}
+
+// reset prepares pgConn for a connection attempt with config. Custom data is preserved.
+func (pgConn *PgConn) reset(config *Config) {
+	customData := pgConn.customData
+	if customData == nil {
+		customData = make(map[string]any)
+	}
+
+	*pgConn = PgConn{
+		config:      config,
+		cleanupDone: make(chan struct{}),
+		customData:  customData,
+	}
+}
+
+// connectConfig establishes a connection to a PostgreSQL server using config. The connection is established in pgConn.
+func connectConfig(ctx context.Context, config *Config, pgConn *PgConn) error {
 	var allErrors []error
 
 	connectConfigs, errs := buildConnectOneConfigs(ctx, config)
@@ -151,24 +248,24 @@ func ConnectConfig(ctx context.Context, config *Config) (*PgConn, error) {
 	}
 
 	if len(connectConfigs) == 0 {
-		return nil, &ConnectError{Config: config, err: fmt.Errorf("hostname resolving error: %w", errors.Join(allErrors...))}
+		return &ConnectError{Config: config, err: fmt.Errorf("hostname resolving error: %w", errors.Join(allErrors...))}
 	}
 
-	pgConn, errs := connectPreferred(ctx, config, connectConfigs)
+	errs = connectPreferred(ctx, config, connectConfigs, pgConn)
 	if len(errs) > 0 {
 		allErrors = append(allErrors, errs...)
-		return nil, &ConnectError{Config: config, err: errors.Join(allErrors...)}
+		return &ConnectError{Config: config, err: errors.Join(allErrors...)}
 	}
 
 	if config.AfterConnect != nil {
 		err := config.AfterConnect(ctx, pgConn)
 		if err != nil {
 			pgConn.conn.Close()
-			return nil, &ConnectError{Config: config, err: fmt.Errorf("AfterConnect error: %w", err)}
+			return &ConnectError{Config: config, err: fmt.Errorf("AfterConnect error: %w", err)}
 		}
 	}
 
-	return pgConn, nil
+	return nil
 }
 
 // buildConnectOneConfigs resolves hostnames and builds a list of connectOneConfigs to try connecting to. It returns a
@@ -190,8 +287,8 @@ func buildConnectOneConfigs(ctx context.Context, config *Config) ([]*connectOneC
 	var allErrors []error
 
 	for _, fb := range fallbackConfigs {
-		// skip resolve for unix sockets
-		if isAbsolutePath(fb.Host) {
+		// skip resolve for unix sockets and named pipes
+		if isLocalHost(fb.Host) {
 			network, address := NetworkAddress(fb.Host, fb.Port)
 			configs = append(configs, &connectOneConfig{
 				network:          network,
@@ -239,9 +336,10 @@ func buildConnectOneConfigs(ctx context.Context, config *Config) ([]*connectOneC
 }
 
 // connectPreferred attempts to connect to the preferred host from connectOneConfigs. The connections are attempted in
-// order. If a connection is successful it is returned. If no connection is successful then all errors are returned. If
-// a connection attempt returns a [NotPreferredError], then that host will be used if no other hosts are successful.
-func connectPreferred(ctx context.Context, config *Config, connectOneConfigs []*connectOneConfig) (*PgConn, []error) {
+// order. If a connection is successful it is established in pgConn. If no connection is successful then all errors are
+// returned. If a connection attempt returns a [NotPreferredError], then that host will be used if no other hosts are
+// successful.
+func connectPreferred(ctx context.Context, config *Config, connectOneConfigs []*connectOneConfig, pgConn *PgConn) []error {
 	octx := ctx
 	var allErrors []error
 
@@ -259,9 +357,9 @@ func connectPreferred(ctx context.Context, config *Config, connectOneConfigs []*
 			ctx = octx
 		}
 
-		pgConn, err := connectOne(ctx, config, c, false)
-		if pgConn != nil {
-			return pgConn, nil
+		err := connectOne(ctx, config, c, false, pgConn)
+		if err == nil {
+			return nil
 		}
 
 		allErrors = append(allErrors, err)
@@ -276,7 +374,7 @@ func connectPreferred(ctx context.Context, config *Config, connectOneConfigs []*
 				pgErr.Code == ERRCODE_INVALID_AUTHORIZATION_SPECIFICATION && c.tlsConfig != nil ||
 				pgErr.Code == ERRCODE_INVALID_CATALOG_NAME ||
 				pgErr.Code == ERRCODE_INSUFFICIENT_PRIVILEGE {
-				return nil, allErrors
+				return allErrors
 			}
 		}
 
@@ -287,24 +385,21 @@ func connectPreferred(ctx context.Context, config *Config, connectOneConfigs []*
 	}
 
 	if fallbackConnectOneConfig != nil {
-		pgConn, err := connectOne(ctx, config, fallbackConnectOneConfig, true)
+		err := connectOne(ctx, config, fallbackConnectOneConfig, true, pgConn)
 		if err == nil {
-			return pgConn, nil
+			return nil
 		}
 		allErrors = append(allErrors, err)
 	}
 
-	return nil, allErrors
+	return allErrors
 }
 
-// connectOne makes one connection attempt to a single host.
+// connectOne makes one connection attempt to a single host. The connection is established in pgConn.
 func connectOne(ctx context.Context, config *Config, connectConfig *connectOneConfig,
-	ignoreNotPreferredErr bool,
-) (*PgConn, error) {
-	pgConn := new(PgConn)
-	pgConn.config = config
-	pgConn.cleanupDone = make(chan struct{})
-	pgConn.customData = make(map[string]any)
+	ignoreNotPreferredErr bool, pgConn *PgConn,
+) error {
+	pgConn.reset(config)
 
 	var err error
 
@@ -316,7 +411,13 @@ func connectOne(ctx context.Context, config *Config, connectConfig *connectOneCo
 
 	pgConn.conn, err = config.DialFunc(ctx, connectConfig.network, connectConfig.address)
 	if err != nil {
-		return nil, newPerDialConnectError("dial error", err)
+		return newPerDialConnectError("dial error", err)
+	}
+
+	err = applyKeepAlive(pgConn.conn, config.KeepAlive)
+	if err != nil {
+		pgConn.conn.Close()
+		return newPerDialConnectError("failed to configure keepalive", err)
 	}
 
 	if connectConfig.tlsConfig != nil {
@@ -326,7 +427,7 @@ func connectOne(ctx context.Context, config *Config, connectConfig *connectOneCo
 		pgConn.contextWatcher.Unwatch() // Always unwatch `netConn` after TLS.
 		if err != nil {
 			pgConn.conn.Close()
-			return nil, newPerDialConnectError("tls error", err)
+			return newPerDialConnectError("tls error", err)
 		}
 
 		pgConn.conn = tlsConn
@@ -339,7 +440,7 @@ func connectOne(ctx context.Context, config *Config, connectConfig *connectOneCo
 	pgConn.parameterStatuses = make(map[string]string)
 	pgConn.status = connStatusConnecting
 	pgConn.bgReader = bgreader.New(pgConn.conn)
-	pgConn.slowWriteTimer = time.AfterFunc(time.Duration(math.MaxInt64),
+	pgConn.slowWriteTimer = clockOrSystem(pgConn.config.Clock).AfterFunc(time.Duration(math.MaxInt64),
 		func() {
 			pgConn.bgReader.Start()
 			pgConn.bgReaderStarted <- struct{}{}
@@ -348,9 +449,23 @@ func connectOne(ctx context.Context, config *Config, connectConfig *connectOneCo
 	pgConn.slowWriteTimer.Stop()
 	pgConn.bgReaderStarted = make(chan struct{})
 	pgConn.frontend = config.BuildFrontend(pgConn.bgReader, pgConn.conn)
+	pgConn.configureFrontend()
+	pgConn.startTrace()
+
+	minProtocolVersion, err := parseProtocolVersion(config.MinProtocolVersion)
+	if err != nil {
+		pgConn.conn.Close()
+		return newPerDialConnectError("invalid min protocol version", err)
+	}
+	maxProtocolVersion, err := parseProtocolVersion(config.MaxProtocolVersion)
+	if err != nil {
+		pgConn.conn.Close()
+		return newPerDialConnectError("invalid max protocol version", err)
+	}
+	pgConn.protocolVersion = maxProtocolVersion
 
 	startupMsg := pgproto3.StartupMessage{
-		ProtocolVersion: pgproto3.ProtocolVersionNumber,
+		ProtocolVersion: maxProtocolVersion,
 		Parameters:      make(map[string]string),
 	}
 
@@ -367,7 +482,7 @@ func connectOne(ctx context.Context, config *Config, connectConfig *connectOneCo
 	pgConn.frontend.Send(&startupMsg)
 	if err := pgConn.flushWithPotentialWriteReadDeadlock(); err != nil {
 		pgConn.conn.Close()
-		return nil, newPerDialConnectError("failed to write startup message", err)
+		return newPerDialConnectError("failed to write startup message", err)
 	}
 
 	for {
@@ -375,41 +490,60 @@ func connectOne(ctx context.Context, config *Config, connectConfig *connectOneCo
 		if err != nil {
 			pgConn.conn.Close()
 			if err, ok := err.(*PgError); ok {
-				return nil, newPerDialConnectError("server error", err)
+				return newPerDialConnectError("server error", err)
 			}
-			return nil, newPerDialConnectError("failed to receive message", err)
+			return newPerDialConnectError("failed to receive message", err)
 		}
 
 		switch msg := msg.(type) {
 		case *pgproto3.BackendKeyData:
 			pgConn.pid = msg.ProcessID
 			pgConn.secretKey = msg.SecretKey
+			pgConn.extendedSecretKey = msg.ExtendedSecretKey
+
+		case *pgproto3.NegotiateProtocolVersion:
+			// The server does not support the requested minor version or some of the requested protocol options. No
+			// protocol options are requested so any unrecognized options mean the server is misbehaving.
+			if len(msg.UnrecognizedOptions) > 0 {
+				pgConn.conn.Close()
+				return newPerDialConnectError("server reported unsupported protocol options", fmt.Errorf("%v", msg.UnrecognizedOptions))
+			}
+			serverVersion := pgproto3.ProtocolVersion30&0xFFFF0000 | msg.NewestMinorProtocol
+			if serverVersion > maxProtocolVersion {
+				pgConn.conn.Close()
+				return newPerDialConnectError("server negotiated invalid protocol version", fmt.Errorf("%d.%d", serverVersion>>16, serverVersion&0xFFFF))
+			}
+			if serverVersion < minProtocolVersion {
+				pgConn.conn.Close()
+				return newPerDialConnectError("server does not support min protocol version", fmt.Errorf("server supports %d.%d", serverVersion>>16, serverVersion&0xFFFF))
+			}
+			pgConn.protocolVersion = serverVersion
 
 		case *pgproto3.AuthenticationOk:
 		case *pgproto3.AuthenticationCleartextPassword:
 			err = pgConn.txPasswordMessage(pgConn.config.Password)
 			if err != nil {
 				pgConn.conn.Close()
-				return nil, newPerDialConnectError("failed to write password message", err)
+				return newPerDialConnectError("failed to write password message", err)
 			}
 		case *pgproto3.AuthenticationMD5Password:
 			digestedPassword := "md5" + hexMD5(hexMD5(pgConn.config.Password+pgConn.config.User)+string(msg.Salt[:]))
 			err = pgConn.txPasswordMessage(digestedPassword)
 			if err != nil {
 				pgConn.conn.Close()
-				return nil, newPerDialConnectError("failed to write password message", err)
+				return newPerDialConnectError("failed to write password message", err)
 			}
 		case *pgproto3.AuthenticationSASL:
 			err = pgConn.scramAuth(msg.AuthMechanisms)
 			if err != nil {
 				pgConn.conn.Close()
-				return nil, newPerDialConnectError("failed SASL auth", err)
+				return newPerDialConnectError("failed SASL auth", err)
 			}
 		case *pgproto3.AuthenticationGSS:
 			err = pgConn.gssAuth()
 			if err != nil {
 				pgConn.conn.Close()
-				return nil, newPerDialConnectError("failed GSS auth", err)
+				return newPerDialConnectError("failed GSS auth", err)
 			}
 		case *pgproto3.ReadyForQuery:
 			pgConn.status = connStatusIdle
@@ -424,21 +558,21 @@ func connectOne(ctx context.Context, config *Config, connectConfig *connectOneCo
 				err := config.ValidateConnect(ctx, pgConn)
 				if err != nil {
 					if _, ok := err.(*NotPreferredError); ignoreNotPreferredErr && ok {
-						return pgConn, nil
+						return nil
 					}
 					pgConn.conn.Close()
-					return nil, newPerDialConnectError("ValidateConnect failed", err)
+					return newPerDialConnectError("ValidateConnect failed", err)
 				}
 			}
-			return pgConn, nil
+			return nil
 		case *pgproto3.ParameterStatus, *pgproto3.NoticeResponse:
 			// handled by ReceiveMessage
 		case *pgproto3.ErrorResponse:
 			pgConn.conn.Close()
-			return nil, newPerDialConnectError("server error", ErrorResponseToPgError(msg))
+			return newPerDialConnectError("server error", ErrorResponseToPgError(msg))
 		default:
 			pgConn.conn.Close()
-			return nil, newPerDialConnectError("received unexpected message", err)
+			return newPerDialConnectError("received unexpected message", err)
 		}
 	}
 }
@@ -490,6 +624,40 @@ func (pgConn *PgConn) signalMessage() chan struct{} {
 	return ch
 }
 
+// configureFrontend applies the write buffer settings of the config to the frontend.
+func (pgConn *PgConn) configureFrontend() {
+	if pgConn.config.WriteBufferSize > 0 {
+		pgConn.frontend.SetWriteBufferSize(pgConn.config.WriteBufferSize)
+	}
+	if pgConn.config.VectoredWriteThreshold > 0 {
+		pgConn.frontend.SetVectoredWriteThreshold(pgConn.config.VectoredWriteThreshold)
+	}
+	if pgConn.config.OnUnknownMessage != nil {
+		pgConn.frontend.SetPassthroughUnknownMessages(true)
+	}
+}
+
+// startTrace starts tracing the frontend to the configured TraceWriter and recording the protocol history.
+func (pgConn *PgConn) startTrace() {
+	config := pgConn.config
+	if config.ProtocolHistorySize > 0 {
+		pgConn.protocolHistory = newProtocolHistory(config.ProtocolHistorySize)
+		pgConn.frontend.SetMessageObserver(pgConn.protocolHistory.record)
+	}
+	if config.TraceWriter != nil {
+		pgConn.frontend.Trace(config.TraceWriter, config.TraceOptions)
+	}
+}
+
+// RecentProtocolMessages returns the most recently sent and received protocol messages. Each message is formatted as the
+// sender, the message type, and the encoded length separated by tabs. It returns nil unless Config.ProtocolHistorySize is greater than zero.
+func (pgConn *PgConn) RecentProtocolMessages() []string {
+	if pgConn.protocolHistory == nil {
+		return nil
+	}
+	return pgConn.protocolHistory.messages()
+}
+
 // ReceiveMessage receives one wire protocol message from the PostgreSQL server. It must only be used when the
 // connection is not busy. e.g. It is an error to call ReceiveMessage while reading the result of a query. The messages
 // are still handled by the core pgconn message handling system so receiving a NotificationResponse will still trigger
@@ -498,7 +666,7 @@ func (pgConn *PgConn) signalMessage() chan struct{} {
 // This is a very low level method that requires deep understanding of the PostgreSQL wire protocol to use correctly.
 // See https://www.postgresql.org/docs/current/protocol.html.
 func (pgConn *PgConn) ReceiveMessage(ctx context.Context) (pgproto3.BackendMessage, error) {
-	if err := pgConn.lock(); err != nil {
+	if err := pgConn.lockContext(ctx); err != nil {
 		return nil, err
 	}
 	defer pgConn.unlock()
@@ -530,37 +698,49 @@ func (pgConn *PgConn) peekMessage() (pgproto3.BackendMessage, error) {
 		return pgConn.peekedMsg, nil
 	}
 
-	var msg pgproto3.BackendMessage
-	var err error
-	if pgConn.bufferingReceive {
-		pgConn.bufferingReceiveMux.Lock()
-		msg = pgConn.bufferingReceiveMsg
-		err = pgConn.bufferingReceiveErr
-		pgConn.bufferingReceiveMux.Unlock()
-		pgConn.bufferingReceive = false
-
-		// If a timeout error happened in the background try the read again.
-		var netErr net.Error
-		if errors.As(err, &netErr) && netErr.Timeout() {
+	for {
+		var msg pgproto3.BackendMessage
+		var err error
+		if pgConn.bufferingReceive {
+			pgConn.bufferingReceiveMux.Lock()
+			msg = pgConn.bufferingReceiveMsg
+			err = pgConn.bufferingReceiveErr
+			pgConn.bufferingReceiveMux.Unlock()
+			pgConn.bufferingReceive = false
+
+			// If a timeout error happened in the background try the read again.
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				msg, err = pgConn.frontend.Receive()
+			}
+		} else {
 			msg, err = pgConn.frontend.Receive()
 		}
-	} else {
-		msg, err = pgConn.frontend.Receive()
-	}
 
-	if err != nil {
-		// Close on anything other than timeout error - everything else is fatal
-		var netErr net.Error
-		isNetErr := errors.As(err, &netErr)
-		if !(isNetErr && netErr.Timeout()) {
-			pgConn.asyncClose()
+		if err != nil {
+			// Close on anything other than timeout error - everything else is fatal
+			var netErr net.Error
+			isNetErr := errors.As(err, &netErr)
+			if !(isNetErr && netErr.Timeout()) {
+				pgConn.asyncClose(err)
+				if pgConn.protocolHistory != nil {
+					err = &ProtocolError{Err: err, RecentMessages: pgConn.protocolHistory.messages()}
+				}
+			}
+
+			return nil, err
 		}
 
-		return nil, err
-	}
+		// Unknown messages are only received when OnUnknownMessage is set. They are passed to it rather than to the
+		// operation in progress.
+		if msg, ok := msg.(*pgproto3.UnknownMessage); ok {
+			pgConn.config.OnUnknownMessage(pgConn, msg)
+			continue
+		}
 
-	pgConn.peekedMsg = msg
-	return msg, nil
+		pgConn.peekedMsg = msg
+		return msg, nil
+	}
 }
 
 // receiveMessage receives a message without setting up context cancellation
@@ -575,18 +755,41 @@ func (pgConn *PgConn) receiveMessage() (pgproto3.BackendMessage, error) {
 	case *pgproto3.ReadyForQuery:
 		pgConn.txStatus = msg.TxStatus
 	case *pgproto3.ParameterStatus:
+		oldValue, hadValue := pgConn.parameterStatuses[msg.Name]
 		pgConn.parameterStatuses[msg.Name] = msg.Value
+		if hadValue && oldValue != msg.Value && (pgConn.status == connStatusIdle || pgConn.status == connStatusBusy) &&
+			slices.Contains(pgConn.config.GuardedParameterStatuses, msg.Name) {
+			var handlerErr error
+			if pgConn.config.OnGuardedParameterStatusChange != nil {
+				handlerErr = pgConn.config.OnGuardedParameterStatusChange(pgConn, msg.Name, oldValue, msg.Value)
+			}
+			if pgConn.config.OnGuardedParameterStatusChange == nil || handlerErr != nil {
+				err := &ParameterStatusChangeError{Name: msg.Name, OldValue: oldValue, NewValue: msg.Value, Err: handlerErr}
+				pgConn.markClosed(err)
+				pgConn.conn.Close() // Ignore error as the connection must not be used and there is already an error to return.
+				close(pgConn.cleanupDone)
+				return nil, err
+			}
+		}
 	case *pgproto3.ErrorResponse:
 		err := ErrorResponseToPgError(msg)
 		if pgConn.config.OnPgError != nil && !pgConn.config.OnPgError(pgConn, err) {
-			pgConn.status = connStatusClosed
+			pgConn.markClosed(err)
 			pgConn.conn.Close() // Ignore error as the connection is already broken and there is already an error to return.
 			close(pgConn.cleanupDone)
 			return nil, err
 		}
 	case *pgproto3.NoticeResponse:
-		if pgConn.config.OnNotice != nil {
-			pgConn.config.OnNotice(pgConn, noticeResponseToNotice(msg))
+		if pgConn.config.OnNotice != nil || pgConn.config.OnCopySkippedRow != nil {
+			notice := noticeResponseToNotice(msg)
+			if pgConn.config.OnNotice != nil {
+				pgConn.config.OnNotice(pgConn, notice)
+			}
+			if pgConn.config.OnCopySkippedRow != nil {
+				if row, ok := ParseCopySkippedRow(notice); ok {
+					pgConn.config.OnCopySkippedRow(pgConn, row)
+				}
+			}
 		}
 	case *pgproto3.NotificationResponse:
 		if pgConn.config.OnNotification != nil {
@@ -598,7 +801,8 @@ func (pgConn *PgConn) receiveMessage() (pgproto3.BackendMessage, error) {
 }
 
 // Conn returns the underlying net.Conn. This rarely necessary. If the connection will be directly used for reading or
-// writing then SyncConn should usually be called before Conn.
+// writing then SyncConn should usually be called before Conn. It returns nil if the connection was created by
+// NewLazyConn and has not yet been connected.
 func (pgConn *PgConn) Conn() net.Conn {
 	return pgConn.conn
 }
@@ -621,11 +825,24 @@ func (pgConn *PgConn) TxStatus() byte {
 	return pgConn.txStatus
 }
 
-// SecretKey returns the backend secret key used to send a cancel query message to the server.
+// SecretKey returns the backend secret key used to send a cancel query message to the server. It returns 0 if the
+// server sent a secret key that is not 4 bytes long. See ExtendedSecretKey.
 func (pgConn *PgConn) SecretKey() uint32 {
 	return pgConn.secretKey
 }
 
+// ExtendedSecretKey returns the backend secret key used to send a cancel query message to the server when the server
+// sent a secret key that is not 4 bytes long. Otherwise it returns nil. Servers using protocol version 3.2 may send
+// longer secret keys.
+func (pgConn *PgConn) ExtendedSecretKey() []byte {
+	return pgConn.extendedSecretKey
+}
+
+// ProtocolVersion returns the protocol version negotiated with the server (e.g. pgproto3.ProtocolVersion30).
+func (pgConn *PgConn) ProtocolVersion() uint32 {
+	return pgConn.protocolVersion
+}
+
 // Frontend returns the underlying *pgproto3.Frontend. This rarely necessary.
 func (pgConn *PgConn) Frontend() *pgproto3.Frontend {
 	return pgConn.frontend
@@ -638,7 +855,12 @@ func (pgConn *PgConn) Close(ctx context.Context) error {
 	if pgConn.status == connStatusClosed {
 		return nil
 	}
-	pgConn.status = connStatusClosed
+	if pgConn.status == connStatusLazy {
+		pgConn.markClosed(nil)
+		close(pgConn.cleanupDone)
+		return nil
+	}
+	pgConn.markClosed(nil)
 
 	defer close(pgConn.cleanupDone)
 	defer pgConn.conn.Close()
@@ -666,19 +888,24 @@ func (pgConn *PgConn) Close(ctx context.Context) error {
 	return pgConn.conn.Close()
 }
 
-// asyncClose marks the connection as closed and asynchronously sends a cancel query message and closes the underlying
-// connection.
-func (pgConn *PgConn) asyncClose() {
+// asyncClose marks the connection as closed because of err and asynchronously sends a cancel query message and closes
+// the underlying connection.
+func (pgConn *PgConn) asyncClose(err error) {
 	if pgConn.status == connStatusClosed {
 		return
 	}
-	pgConn.status = connStatusClosed
+	if pgConn.status == connStatusLazy {
+		pgConn.markClosed(err)
+		close(pgConn.cleanupDone)
+		return
+	}
+	pgConn.markClosed(err)
 
 	go func() {
 		defer close(pgConn.cleanupDone)
 		defer pgConn.conn.Close()
 
-		deadline := time.Now().Add(time.Second * 15)
+		deadline := clockOrSystem(pgConn.config.Clock).Now().Add(time.Second * 15)
 
 		ctx, cancel := context.WithDeadline(context.Background(), deadline)
 		defer cancel()
@@ -692,6 +919,18 @@ func (pgConn *PgConn) asyncClose() {
 	}()
 }
 
+// markClosed marks the connection as closed because of err. err is nil if the connection was closed with Close. If the
+// connection was established, Config.OnClose is called.
+func (pgConn *PgConn) markClosed(err error) {
+	established := pgConn.status == connStatusIdle || pgConn.status == connStatusBusy
+	pgConn.status = connStatusClosed
+	pgConn.closeErr = err
+
+	if established && pgConn.config.OnClose != nil {
+		pgConn.config.OnClose(pgConn, err)
+	}
+}
+
 // CleanupDone returns a channel that will be closed after all underlying resources have been cleaned up. A closed
 // connection is no longer usable, but underlying resources, in particular the net.Conn, may not have finished closing
 // yet. This is because certain errors such as a context cancellation require that the interrupted function call return
@@ -722,14 +961,28 @@ func (pgConn *PgConn) lock() error {
 	case connStatusBusy:
 		return &connLockError{status: "conn busy"} // This only should be possible in case of an application bug.
 	case connStatusClosed:
-		return &connLockError{status: "conn closed"}
+		return &ConnectionClosedError{Err: pgConn.closeErr}
 	case connStatusUninitialized:
 		return &connLockError{status: "conn uninitialized"}
+	case connStatusLazy:
+		return &connLockError{status: "conn not connected"}
 	}
 	pgConn.status = connStatusBusy
 	return nil
 }
 
+// lockContext connects the connection if it was created by NewLazyConn and then locks it.
+func (pgConn *PgConn) lockContext(ctx context.Context) error {
+	if pgConn.status == connStatusLazy {
+		err := pgConn.Connect(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return pgConn.lock()
+}
+
 func (pgConn *PgConn) unlock() {
 	switch pgConn.status {
 	case connStatusBusy:
@@ -848,7 +1101,7 @@ type StatementDescription struct {
 // Prepare does not send a PREPARE statement to the server. It uses the PostgreSQL Parse and Describe protocol messages
 // directly.
 func (pgConn *PgConn) Prepare(ctx context.Context, name, sql string, paramOIDs []uint32) (*StatementDescription, error) {
-	if err := pgConn.lock(); err != nil {
+	if err := pgConn.lockContext(ctx); err != nil {
 		return nil, err
 	}
 	defer pgConn.unlock()
@@ -866,9 +1119,9 @@ func (pgConn *PgConn) Prepare(ctx context.Context, name, sql string, paramOIDs [
 	pgConn.frontend.SendParse(&pgproto3.Parse{Name: name, Query: sql, ParameterOIDs: paramOIDs})
 	pgConn.frontend.SendDescribe(&pgproto3.Describe{ObjectType: 'S', Name: name})
 	pgConn.frontend.SendSync(&pgproto3.Sync{})
-	err := pgConn.flushWithPotentialWriteReadDeadlock()
+	err := pgConn.flushResumable(ctx)
 	if err != nil {
-		pgConn.asyncClose()
+		pgConn.asyncClose(err)
 		return nil, err
 	}
 
@@ -880,7 +1133,7 @@ readloop:
 	for {
 		msg, err := pgConn.receiveMessage()
 		if err != nil {
-			pgConn.asyncClose()
+			pgConn.asyncClose(err)
 			return nil, normalizeTimeoutError(ctx, err)
 		}
 
@@ -910,7 +1163,17 @@ readloop:
 //   - Deallocate can succeed in an aborted transaction.
 //   - Deallocating a non-existent prepared statement is not an error.
 func (pgConn *PgConn) Deallocate(ctx context.Context, name string) error {
-	if err := pgConn.lock(); err != nil {
+	return pgConn.DeallocateMany(ctx, []string{name})
+}
+
+// DeallocateMany deallocates prepared statements in a single round trip. It behaves like Deallocate for each name. If an
+// error occurs, the statements after the one that failed are not deallocated.
+func (pgConn *PgConn) DeallocateMany(ctx context.Context, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	if err := pgConn.lockContext(ctx); err != nil {
 		return err
 	}
 	defer pgConn.unlock()
@@ -925,30 +1188,42 @@ func (pgConn *PgConn) Deallocate(ctx context.Context, name string) error {
 		defer pgConn.contextWatcher.Unwatch()
 	}
 
-	pgConn.frontend.SendClose(&pgproto3.Close{ObjectType: 'S', Name: name})
+	for _, name := range names {
+		pgConn.frontend.SendClose(&pgproto3.Close{ObjectType: 'S', Name: name})
+	}
 	pgConn.frontend.SendSync(&pgproto3.Sync{})
-	err := pgConn.flushWithPotentialWriteReadDeadlock()
+	err := pgConn.flushResumable(ctx)
 	if err != nil {
-		pgConn.asyncClose()
+		pgConn.asyncClose(err)
 		return err
 	}
 
+	var pgErr *PgError
 	for {
 		msg, err := pgConn.receiveMessage()
 		if err != nil {
-			pgConn.asyncClose()
+			pgConn.asyncClose(err)
 			return normalizeTimeoutError(ctx, err)
 		}
 
 		switch msg := msg.(type) {
 		case *pgproto3.ErrorResponse:
-			return ErrorResponseToPgError(msg)
+			pgErr = ErrorResponseToPgError(msg)
 		case *pgproto3.ReadyForQuery:
+			if pgErr != nil {
+				return pgErr
+			}
 			return nil
 		}
 	}
 }
 
+// DeallocateAll deallocates all prepared statements of the connection with a DEALLOCATE ALL statement.
+func (pgConn *PgConn) DeallocateAll(ctx context.Context) error {
+	_, err := pgConn.Exec(ctx, "deallocate all").ReadAll()
+	return err
+}
+
 // ErrorResponseToPgError converts a wire protocol error message to a *PgError.
 func ErrorResponseToPgError(msg *pgproto3.ErrorResponse) *PgError {
 	return &PgError{
@@ -982,6 +1257,11 @@ func noticeResponseToNotice(msg *pgproto3.NoticeResponse) *Notice {
 // request, but lack of an error does not ensure that the query was canceled. As specified in the documentation, there
 // is no way to be sure a query was canceled. See https://www.postgresql.org/docs/11/protocol-flow.html#id-1.10.5.7.9
 func (pgConn *PgConn) CancelRequest(ctx context.Context) error {
+	// A connection that has not been connected has nothing to cancel.
+	if pgConn.status == connStatusLazy {
+		return nil
+	}
+
 	// Open a cancellation request to the same server. The address is taken from the net.Conn directly instead of reusing
 	// the connection config. This is important in high availability configurations where fallback connections may be
 	// specified or DNS may be used to load balance.
@@ -1017,11 +1297,15 @@ func (pgConn *PgConn) CancelRequest(ctx context.Context) error {
 		defer contextWatcher.Unwatch()
 	}
 
-	buf := make([]byte, 16)
-	binary.BigEndian.PutUint32(buf[0:4], 16)
-	binary.BigEndian.PutUint32(buf[4:8], 80877102)
-	binary.BigEndian.PutUint32(buf[8:12], pgConn.pid)
-	binary.BigEndian.PutUint32(buf[12:16], pgConn.secretKey)
+	cancelRequest := pgproto3.CancelRequest{
+		ProcessID:         pgConn.pid,
+		SecretKey:         pgConn.secretKey,
+		ExtendedSecretKey: pgConn.extendedSecretKey,
+	}
+	buf, err := cancelRequest.Encode(nil)
+	if err != nil {
+		return err
+	}
 
 	if _, err := cancelConn.Write(buf); err != nil {
 		return fmt.Errorf("write to connection for cancellation: %w", err)
@@ -1037,7 +1321,7 @@ func (pgConn *PgConn) CancelRequest(ctx context.Context) error {
 // WaitForNotification waits for a LISTEN/NOTIFY message to be received. It returns an error if a notification was not
 // received.
 func (pgConn *PgConn) WaitForNotification(ctx context.Context) error {
-	if err := pgConn.lock(); err != nil {
+	if err := pgConn.lockContext(ctx); err != nil {
 		return err
 	}
 	defer pgConn.unlock()
@@ -1072,7 +1356,7 @@ func (pgConn *PgConn) WaitForNotification(ctx context.Context) error {
 //
 // Prefer ExecParams unless executing arbitrary SQL that may contain multiple queries.
 func (pgConn *PgConn) Exec(ctx context.Context, sql string) *MultiResultReader {
-	if err := pgConn.lock(); err != nil {
+	if err := pgConn.lockContext(ctx); err != nil {
 		return &MultiResultReader{
 			closed: true,
 			err:    err,
@@ -1097,9 +1381,9 @@ func (pgConn *PgConn) Exec(ctx context.Context, sql string) *MultiResultReader {
 	}
 
 	pgConn.frontend.SendQuery(&pgproto3.Query{String: sql})
-	err := pgConn.flushWithPotentialWriteReadDeadlock()
+	err := pgConn.flushResumable(ctx)
 	if err != nil {
-		pgConn.asyncClose()
+		pgConn.asyncClose(err)
 		pgConn.contextWatcher.Unwatch()
 		multiResult.closed = true
 		multiResult.err = err
@@ -1136,7 +1420,7 @@ func (pgConn *PgConn) ExecParams(ctx context.Context, sql string, paramValues []
 	}
 
 	pgConn.frontend.SendParse(&pgproto3.Parse{Query: sql, ParameterOIDs: paramOIDs})
-	pgConn.frontend.SendBind(&pgproto3.Bind{ParameterFormatCodes: paramFormats, Parameters: paramValues, ResultFormatCodes: resultFormats})
+	pgConn.frontend.SendBindVectored(&pgproto3.Bind{ParameterFormatCodes: paramFormats, Parameters: paramValues, ResultFormatCodes: resultFormats})
 
 	pgConn.execExtendedSuffix(result)
 
@@ -1161,7 +1445,7 @@ func (pgConn *PgConn) ExecPrepared(ctx context.Context, stmtName string, paramVa
 		return result
 	}
 
-	pgConn.frontend.SendBind(&pgproto3.Bind{PreparedStatement: stmtName, ParameterFormatCodes: paramFormats, Parameters: paramValues, ResultFormatCodes: resultFormats})
+	pgConn.frontend.SendBindVectored(&pgproto3.Bind{PreparedStatement: stmtName, ParameterFormatCodes: paramFormats, Parameters: paramValues, ResultFormatCodes: resultFormats})
 
 	pgConn.execExtendedSuffix(result)
 
@@ -1175,7 +1459,7 @@ func (pgConn *PgConn) execExtendedPrefix(ctx context.Context, paramValues [][]by
 	}
 	result := &pgConn.resultReader
 
-	if err := pgConn.lock(); err != nil {
+	if err := pgConn.lockContext(ctx); err != nil {
 		result.concludeCommand(CommandTag{}, err)
 		result.closed = true
 		return result
@@ -1208,9 +1492,9 @@ func (pgConn *PgConn) execExtendedSuffix(result *ResultReader) {
 	pgConn.frontend.SendExecute(&pgproto3.Execute{})
 	pgConn.frontend.SendSync(&pgproto3.Sync{})
 
-	err := pgConn.flushWithPotentialWriteReadDeadlock()
+	err := pgConn.flushResumable(result.ctx)
 	if err != nil {
-		pgConn.asyncClose()
+		pgConn.asyncClose(err)
 		result.concludeCommand(CommandTag{}, err)
 		pgConn.contextWatcher.Unwatch()
 		result.closed = true
@@ -1223,7 +1507,7 @@ func (pgConn *PgConn) execExtendedSuffix(result *ResultReader) {
 
 // CopyTo executes the copy command sql and copies the results to w.
 func (pgConn *PgConn) CopyTo(ctx context.Context, w io.Writer, sql string) (CommandTag, error) {
-	if err := pgConn.lock(); err != nil {
+	if err := pgConn.lockContext(ctx); err != nil {
 		return CommandTag{}, err
 	}
 
@@ -1241,9 +1525,9 @@ func (pgConn *PgConn) CopyTo(ctx context.Context, w io.Writer, sql string) (Comm
 	// Send copy to command
 	pgConn.frontend.SendQuery(&pgproto3.Query{String: sql})
 
-	err := pgConn.flushWithPotentialWriteReadDeadlock()
+	err := pgConn.flushResumable(ctx)
 	if err != nil {
-		pgConn.asyncClose()
+		pgConn.asyncClose(err)
 		pgConn.unlock()
 		return CommandTag{}, err
 	}
@@ -1254,7 +1538,7 @@ func (pgConn *PgConn) CopyTo(ctx context.Context, w io.Writer, sql string) (Comm
 	for {
 		msg, err := pgConn.receiveMessage()
 		if err != nil {
-			pgConn.asyncClose()
+			pgConn.asyncClose(err)
 			return CommandTag{}, normalizeTimeoutError(ctx, err)
 		}
 
@@ -1263,7 +1547,7 @@ func (pgConn *PgConn) CopyTo(ctx context.Context, w io.Writer, sql string) (Comm
 		case *pgproto3.CopyData:
 			_, err := w.Write(msg.Data)
 			if err != nil {
-				pgConn.asyncClose()
+				pgConn.asyncClose(err)
 				return CommandTag{}, err
 			}
 		case *pgproto3.ReadyForQuery:
@@ -1279,10 +1563,14 @@ func (pgConn *PgConn) CopyTo(ctx context.Context, w io.Writer, sql string) (Comm
 
 // CopyFrom executes the copy command sql and copies all of r to the PostgreSQL server.
 //
+// Messages from the server are read while r is being copied. If the server reports an error, such as a constraint
+// violation on an early row, copying stops after the chunk currently being read or sent and the error is returned
+// without reading the rest of r.
+//
 // Note: context cancellation will only interrupt operations on the underlying PostgreSQL network connection. Reads on r
 // could still block.
 func (pgConn *PgConn) CopyFrom(ctx context.Context, r io.Reader, sql string) (CommandTag, error) {
-	if err := pgConn.lock(); err != nil {
+	if err := pgConn.lockContext(ctx); err != nil {
 		return CommandTag{}, err
 	}
 	defer pgConn.unlock()
@@ -1299,9 +1587,9 @@ func (pgConn *PgConn) CopyFrom(ctx context.Context, r io.Reader, sql string) (Co
 
 	// Send copy from query
 	pgConn.frontend.SendQuery(&pgproto3.Query{String: sql})
-	err := pgConn.flushWithPotentialWriteReadDeadlock()
+	err := pgConn.flushResumable(ctx)
 	if err != nil {
-		pgConn.asyncClose()
+		pgConn.asyncClose(err)
 		return CommandTag{}, err
 	}
 
@@ -1324,7 +1612,7 @@ func (pgConn *PgConn) CopyFrom(ctx context.Context, r io.Reader, sql string) (Co
 				*buf = (*buf)[0 : n+5]
 				pgio.SetInt32((*buf)[1:], int32(n+4))
 
-				writeErr := pgConn.frontend.SendUnbufferedEncodedCopyData(*buf)
+				writeErr := pgConn.sendEncodedCopyData(*buf)
 				if writeErr != nil {
 					// Write errors are always fatal, but we can't use asyncClose because we are in a different goroutine. Not
 					// setting pgConn.status or closing pgConn.cleanupDone for the same reason.
@@ -1357,7 +1645,7 @@ func (pgConn *PgConn) CopyFrom(ctx context.Context, r io.Reader, sql string) (Co
 			// the goroutine. So instead check pgConn.bufferingReceiveErr which will have been set by the signalMessage. If an
 			// error is found then forcibly close the connection without sending the Terminate message.
 			if err := pgConn.bufferingReceiveErr; err != nil {
-				pgConn.status = connStatusClosed
+				pgConn.markClosed(err)
 				pgConn.conn.Close()
 				close(pgConn.cleanupDone)
 				return CommandTag{}, normalizeTimeoutError(ctx, err)
@@ -1381,9 +1669,9 @@ func (pgConn *PgConn) CopyFrom(ctx context.Context, r io.Reader, sql string) (Co
 	} else {
 		pgConn.frontend.Send(&pgproto3.CopyFail{Message: copyErr.Error()})
 	}
-	err = pgConn.flushWithPotentialWriteReadDeadlock()
+	err = pgConn.flushResumable(ctx)
 	if err != nil {
-		pgConn.asyncClose()
+		pgConn.asyncClose(err)
 		return CommandTag{}, err
 	}
 
@@ -1392,7 +1680,7 @@ func (pgConn *PgConn) CopyFrom(ctx context.Context, r io.Reader, sql string) (Co
 	for {
 		msg, err := pgConn.receiveMessage()
 		if err != nil {
-			pgConn.asyncClose()
+			pgConn.asyncClose(err)
 			return CommandTag{}, normalizeTimeoutError(ctx, err)
 		}
 
@@ -1407,6 +1695,18 @@ func (pgConn *PgConn) CopyFrom(ctx context.Context, r io.Reader, sql string) (Co
 	}
 }
 
+// sendEncodedCopyData writes the encoded CopyData message buf. If vectored writes are enabled the data is sent with
+// SendCopyDataVectored so a chunk at least as long as the threshold is written with its header in a single vectored
+// write.
+func (pgConn *PgConn) sendEncodedCopyData(buf []byte) error {
+	if pgConn.config.VectoredWriteThreshold > 0 {
+		pgConn.frontend.SendCopyDataVectored(&pgproto3.CopyData{Data: buf[5:]})
+		return pgConn.frontend.Flush()
+	}
+
+	return pgConn.frontend.SendUnbufferedEncodedCopyData(buf)
+}
+
 // MultiResultReader is a reader for a command that could return multiple results such as Exec or ExecBatch.
 type MultiResultReader struct {
 	pgConn *PgConn
@@ -1436,7 +1736,7 @@ func (mrr *MultiResultReader) receiveMessage() (pgproto3.BackendMessage, error)
 		mrr.pgConn.contextWatcher.Unwatch()
 		mrr.err = normalizeTimeoutError(mrr.ctx, err)
 		mrr.closed = true
-		mrr.pgConn.asyncClose()
+		mrr.pgConn.asyncClose(err)
 		return nil, mrr.err
 	}
 
@@ -1517,6 +1817,15 @@ type ResultReader struct {
 	commandConcluded  bool
 	closed            bool
 	err               error
+
+	readingAhead bool
+
+	// rowTimeout limits how long NextRow waits for a row. rowCtx is the context watched while waiting.
+	rowTimeout time.Duration
+	rowCtx     context.Context
+
+	resultBytes int64 // total size of the row values read so far; only tracked when Config.MaxResultBytes > 0
+	resultRows  int64
 }
 
 // Result is the saved query response that is returned by calling Read on a ResultReader.
@@ -1553,9 +1862,58 @@ func (rr *ResultReader) Read() *Result {
 	return br
 }
 
+// SetRowTimeout sets the maximum time NextRow waits for the next row. If the timeout expires the query is canceled as
+// if its context had been canceled and the error of the ResultReader is a *RowTimeoutError. The time the application
+// spends processing a row is not included. This is useful for results that are streamed indefinitely, such as a FETCH
+// from a cursor of a query that waits for new data, where a deadline on the context of the entire query is not
+// suitable. A timeout of 0 disables the limit.
+//
+// Each call of NextRow with a row timeout watches a new context, so it has a small overhead.
+func (rr *ResultReader) SetRowTimeout(timeout time.Duration) {
+	rr.rowTimeout = timeout
+}
+
 // NextRow advances the ResultReader to the next row and returns true if a row is available.
 func (rr *ResultReader) NextRow() bool {
-	for !rr.commandConcluded {
+	if rr.rowTimeout > 0 && !rr.commandConcluded && !rr.closed && rr.err == nil {
+		return rr.nextRowWithTimeout()
+	}
+
+	return rr.nextRow()
+}
+
+// nextRowWithTimeout calls nextRow while watching a context with the row timeout instead of the context of the query.
+func (rr *ResultReader) nextRowWithTimeout() bool {
+	ctx, cancel := context.WithTimeout(rr.ctx, rr.rowTimeout)
+	defer cancel()
+
+	rr.pgConn.contextWatcher.Unwatch()
+	rr.pgConn.contextWatcher.Watch(ctx)
+	rr.rowCtx = ctx
+
+	ok := rr.nextRow()
+
+	rr.rowCtx = nil
+	// receiveMessage unwatches the context if reading fails.
+	if !rr.closed {
+		rr.pgConn.contextWatcher.Unwatch()
+		rr.pgConn.contextWatcher.Watch(rr.ctx)
+	}
+
+	if rr.err != nil && ctx.Err() == context.DeadlineExceeded && rr.ctx.Err() == nil {
+		var rowTimeoutErr *RowTimeoutError
+		if !errors.As(rr.err, &rowTimeoutErr) {
+			rr.err = &RowTimeoutError{Timeout: rr.rowTimeout, Err: rr.err}
+		}
+	}
+
+	return ok
+}
+
+func (rr *ResultReader) nextRow() bool {
+	// rr.err is only set before the command is concluded when the result exceeded Config.MaxResultBytes. The remaining
+	// rows are discarded by Close.
+	for !rr.commandConcluded && rr.err == nil {
 		msg, err := rr.receiveMessage()
 		if err != nil {
 			return false
@@ -1563,6 +1921,23 @@ func (rr *ResultReader) NextRow() bool {
 
 		switch msg := msg.(type) {
 		case *pgproto3.DataRow:
+			if !rr.readingAhead && rr.pgConn.config.ReadAheadBufferSize > 0 {
+				rr.pgConn.bgReader.StartLimited(rr.pgConn.config.ReadAheadBufferSize)
+				rr.readingAhead = true
+			}
+			if maxResultBytes := rr.pgConn.config.MaxResultBytes; maxResultBytes > 0 {
+				rr.resultRows++
+				for _, v := range msg.Values {
+					rr.resultBytes += int64(len(v))
+				}
+				if rr.resultBytes > maxResultBytes {
+					rr.err = &ResultTooLargeError{MaxResultBytes: maxResultBytes, Rows: rr.resultRows}
+					if rr.multiResultReader != nil && rr.multiResultReader.err == nil {
+						rr.multiResultReader.err = rr.err
+					}
+					return false
+				}
+			}
 			rr.rowValues = msg.Values
 			return true
 		}
@@ -1649,12 +2024,16 @@ func (rr *ResultReader) receiveMessage() (msg pgproto3.BackendMessage, err error
 	}
 
 	if err != nil {
-		err = normalizeTimeoutError(rr.ctx, err)
+		ctx := rr.ctx
+		if rr.rowCtx != nil {
+			ctx = rr.rowCtx
+		}
+		err = normalizeTimeoutError(ctx, err)
 		rr.concludeCommand(CommandTag{}, err)
 		rr.pgConn.contextWatcher.Unwatch()
 		rr.closed = true
 		if rr.multiResultReader == nil {
-			rr.pgConn.asyncClose()
+			rr.pgConn.asyncClose(err)
 		}
 
 		return nil, rr.err
@@ -1689,6 +2068,11 @@ func (rr *ResultReader) concludeCommand(commandTag CommandTag, err error) {
 		return
 	}
 
+	if rr.readingAhead {
+		rr.pgConn.bgReader.Stop()
+		rr.readingAhead = false
+	}
+
 	rr.commandTag = commandTag
 	rr.rowValues = nil
 	rr.commandConcluded = true
@@ -1746,7 +2130,7 @@ func (pgConn *PgConn) ExecBatch(ctx context.Context, batch *Batch) *MultiResultR
 		}
 	}
 
-	if err := pgConn.lock(); err != nil {
+	if err := pgConn.lockContext(ctx); err != nil {
 		return &MultiResultReader{
 			closed: true,
 			err:    err,
@@ -1815,16 +2199,22 @@ func (pgConn *PgConn) EscapeString(s string) (string, error) {
 // condition. If this is done immediately before sending a query it reduces the chances a query will be sent that fails
 // without the client knowing whether the server received it or not.
 //
+// If the connection is broken the returned error is a *ConnCheckError that categorizes the failure.
+//
 // Deprecated: CheckConn is deprecated in favor of Ping. CheckConn cannot detect all types of broken connections where
 // the write would still appear to succeed. Prefer Ping unless on a high latency connection.
 func (pgConn *PgConn) CheckConn() error {
+	if pgConn.status == connStatusLazy {
+		return nil
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Millisecond)
 	defer cancel()
 
 	_, err := pgConn.ReceiveMessage(ctx)
 	if err != nil {
 		if !Timeout(err) {
-			return err
+			return newConnCheckError(err)
 		}
 	}
 
@@ -1834,8 +2224,68 @@ func (pgConn *PgConn) CheckConn() error {
 // Ping pings the server. This can be useful because a TCP connection can be broken such that a write will appear to
 // succeed even though it will never actually reach the server. Pinging immediately before sending a query reduces the
 // chances a query will be sent that fails without the client knowing whether the server received it or not.
+//
+// If the connection is broken the returned error is a *ConnCheckError that categorizes the failure.
 func (pgConn *PgConn) Ping(ctx context.Context) error {
-	return pgConn.Exec(ctx, "-- ping").Close()
+	err := pgConn.Exec(ctx, "-- ping").Close()
+	if err != nil && pgConn.IsClosed() {
+		return newConnCheckError(err)
+	}
+	return err
+}
+
+// PingLight pings the server with a protocol level Sync message instead of a query. The server responds to the Sync
+// with ReadyForQuery without parsing or executing anything. This makes it cheaper than Ping and it does not cause log
+// entries when the server is configured with log_statement=all. However, it only verifies that the server process is
+// responsive. Use Ping to verify that the server can execute statements.
+//
+// If the connection is broken the returned error is a *ConnCheckError that categorizes the failure.
+func (pgConn *PgConn) PingLight(ctx context.Context) error {
+	err := pgConn.sync(ctx)
+	if err != nil && pgConn.IsClosed() {
+		return newConnCheckError(err)
+	}
+	return err
+}
+
+// sync sends a Sync message and waits for the ReadyForQuery response.
+func (pgConn *PgConn) sync(ctx context.Context) error {
+	if err := pgConn.lockContext(ctx); err != nil {
+		return err
+	}
+	defer pgConn.unlock()
+
+	if ctx != context.Background() {
+		select {
+		case <-ctx.Done():
+			return newContextAlreadyDoneError(ctx)
+		default:
+		}
+		pgConn.contextWatcher.Watch(ctx)
+		defer pgConn.contextWatcher.Unwatch()
+	}
+
+	pgConn.frontend.SendSync(&pgproto3.Sync{})
+	err := pgConn.flushResumable(ctx)
+	if err != nil {
+		pgConn.asyncClose(err)
+		return err
+	}
+
+	for {
+		msg, err := pgConn.receiveMessage()
+		if err != nil {
+			pgConn.asyncClose(err)
+			return normalizeTimeoutError(ctx, err)
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.ErrorResponse:
+			return ErrorResponseToPgError(msg)
+		case *pgproto3.ReadyForQuery:
+			return nil
+		}
+	}
 }
 
 // makeCommandTag makes a CommandTag. It does not retain a reference to buf or buf's underlying memory.
@@ -1878,6 +2328,51 @@ func (pgConn *PgConn) flushWithPotentialWriteReadDeadlock() error {
 	return err
 }
 
+// maxWriteResumes is the number of times flushResumable resumes a write that was interrupted by a deadline.
+const maxWriteResumes = 3
+
+// flushResumable flushes like flushWithPotentialWriteReadDeadlock. But if the write is interrupted by a deadline on the
+// net.Conn while ctx is still alive, the unwritten remainder of the messages is written again instead of failing. This
+// avoids closing the connection when a deadline is set only briefly, e.g. by a ContextWatcher handler for a context
+// that is concurrently being unwatched.
+//
+// Writes to a TLS connection are never resumed. A TLS connection whose write timed out is corrupt and all further
+// writes fail.
+func (pgConn *PgConn) flushResumable(ctx context.Context) error {
+	pgConn.enterPotentialWriteReadDeadlock()
+	defer pgConn.exitPotentialWriteReadDeadlock()
+
+	if isTLSConn(pgConn.conn) {
+		return pgConn.frontend.Flush()
+	}
+
+	err := pgConn.frontend.FlushResumable()
+	for i := 0; err != nil && i < maxWriteResumes; i++ {
+		if !errors.Is(err, os.ErrDeadlineExceeded) || ctx.Err() != nil {
+			break
+		}
+
+		// Give whatever set the deadline a moment to clear it.
+		timer := clockOrSystem(pgConn.config.Clock).NewTimer(time.Duration(i+1) * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C():
+		}
+
+		err = pgConn.frontend.FlushResumable()
+	}
+
+	return err
+}
+
+// isTLSConn returns true if conn is a *tls.Conn or a net.Conn that wraps one and exposes its state.
+func isTLSConn(conn net.Conn) bool {
+	_, ok := conn.(interface{ ConnectionState() tls.ConnectionState })
+	return ok
+}
+
 // SyncConn prepares the underlying net.Conn for direct use. PgConn may internally buffer reads or use goroutines for
 // background IO. This means that any direct use of the underlying net.Conn may be corrupted if a read is already
 // buffered or a read is in progress. SyncConn drains read buffers and stops background IO. In some cases this may
@@ -1886,6 +2381,13 @@ func (pgConn *PgConn) flushWithPotentialWriteReadDeadlock() error {
 //
 // This should not be confused with the PostgreSQL protocol Sync message.
 func (pgConn *PgConn) SyncConn(ctx context.Context) error {
+	if pgConn.status == connStatusLazy {
+		err := pgConn.Connect(ctx)
+		if err != nil {
+			return fmt.Errorf("SyncConn: %w", err)
+		}
+	}
+
 	for i := 0; i < 10; i++ {
 		if pgConn.bgReader.Status() == bgreader.StatusStopped && pgConn.frontend.ReadBufferLen() == 0 {
 			return nil
@@ -1907,7 +2409,11 @@ func (pgConn *PgConn) CustomData() map[string]any {
 	return pgConn.customData
 }
 
-// HijackedConn is the result of hijacking a connection.
+// HijackedConn is the result of hijacking a connection. It is a snapshot of the state of the connection that can be
+// passed to Construct to resume using the connection, e.g. in another component of the process.
+//
+// Prepared statements remain on the server across Hijack and Construct. pgconn does not track them so they are not
+// part of the snapshot. A higher level layer that does, such as a statement cache, must hand off its own state.
 //
 // Due to the necessary exposure of internal implementation details, it is not covered by the semantic versioning
 // compatibility.
@@ -1915,16 +2421,26 @@ type HijackedConn struct {
 	Conn              net.Conn
 	PID               uint32            // backend pid
 	SecretKey         uint32            // key to use to send a cancel query message to the server
+	ExtendedSecretKey []byte            // secret key used instead of SecretKey if not nil
+	ProtocolVersion   uint32            // protocol version in use; 0 means pgproto3.ProtocolVersion30
 	ParameterStatuses map[string]string // parameters that have been reported by the server
 	TxStatus          byte
 	Frontend          *pgproto3.Frontend
 	Config            *Config
 	CustomData        map[string]any
+
+	// ReadBuffer is data that has already been read from Conn but has not yet been processed. e.g. a notification that
+	// arrived after the last query completed. Construct processes it before reading from Conn.
+	ReadBuffer []byte
 }
 
-// Hijack extracts the internal connection data. pgConn must be in an idle state. SyncConn should be called immediately
-// before Hijack. pgConn is unusable after hijacking. Hijacking is typically only useful when using pgconn to establish
-// a connection, but taking complete control of the raw connection after that (e.g. a load balancer or proxy).
+// Hijack extracts the internal connection data. pgConn must be in an idle state. pgConn is unusable after hijacking.
+// Hijacking is typically only useful when using pgconn to establish a connection, but taking complete control of the
+// raw connection after that (e.g. a load balancer or proxy) or handing the connection off to another component.
+//
+// Data that has been received from the server but not yet processed is returned in ReadBuffer so it is not necessary to
+// call SyncConn before Hijack. However, Hijack fails if a background read is in progress. In that case SyncConn can be
+// called to synchronize the connection before trying again.
 //
 // Due to the necessary exposure of internal implementation details, it is not covered by the semantic versioning
 // compatibility.
@@ -1932,37 +2448,84 @@ func (pgConn *PgConn) Hijack() (*HijackedConn, error) {
 	if err := pgConn.lock(); err != nil {
 		return nil, err
 	}
+
+	if pgConn.bgReader.Status() != bgreader.StatusStopped || pgConn.peekedMsg != nil {
+		pgConn.unlock()
+		return nil, errors.New("hijack: conn is not synchronized")
+	}
+
+	readBuffer, err := pgConn.frontend.ReadBuffer()
+	if err != nil {
+		pgConn.unlock()
+		return nil, fmt.Errorf("hijack: %w", err)
+	}
+
+	parameterStatuses := make(map[string]string, len(pgConn.parameterStatuses))
+	for k, v := range pgConn.parameterStatuses {
+		parameterStatuses[k] = v
+	}
+
 	pgConn.status = connStatusClosed
 
 	return &HijackedConn{
 		Conn:              pgConn.conn,
 		PID:               pgConn.pid,
 		SecretKey:         pgConn.secretKey,
-		ParameterStatuses: pgConn.parameterStatuses,
+		ExtendedSecretKey: pgConn.extendedSecretKey,
+		ProtocolVersion:   pgConn.protocolVersion,
+		ParameterStatuses: parameterStatuses,
 		TxStatus:          pgConn.txStatus,
 		Frontend:          pgConn.frontend,
 		Config:            pgConn.config,
 		CustomData:        pgConn.customData,
+		ReadBuffer:        readBuffer,
 	}, nil
 }
 
 // Construct created a PgConn from an already established connection to a PostgreSQL server. This is the inverse of
-// PgConn.Hijack. The connection must be in an idle state.
+// PgConn.Hijack. The connection must be in an idle state. hc is validated before it is used.
 //
-// hc.Frontend is replaced by a new pgproto3.Frontend built by hc.Config.BuildFrontend.
+// hc.Frontend is replaced by a new pgproto3.Frontend built by hc.Config.BuildFrontend. hc.ParameterStatuses is copied
+// so later changes to either do not affect the other.
 //
 // Due to the necessary exposure of internal implementation details, it is not covered by the semantic versioning
 // compatibility.
 func Construct(hc *HijackedConn) (*PgConn, error) {
+	if err := validateHijackedConn(hc); err != nil {
+		return nil, err
+	}
+
+	parameterStatuses := make(map[string]string, len(hc.ParameterStatuses))
+	for k, v := range hc.ParameterStatuses {
+		parameterStatuses[k] = v
+	}
+
+	customData := hc.CustomData
+	if customData == nil {
+		customData = make(map[string]any)
+	}
+
+	protocolVersion := hc.ProtocolVersion
+	if protocolVersion == 0 {
+		protocolVersion = pgproto3.ProtocolVersion30
+	}
+
+	conn := hc.Conn
+	if len(hc.ReadBuffer) > 0 {
+		conn = &readBufferConn{Conn: hc.Conn, buf: hc.ReadBuffer}
+	}
+
 	pgConn := &PgConn{
-		conn:              hc.Conn,
+		conn:              conn,
 		pid:               hc.PID,
 		secretKey:         hc.SecretKey,
-		parameterStatuses: hc.ParameterStatuses,
+		extendedSecretKey: hc.ExtendedSecretKey,
+		protocolVersion:   protocolVersion,
+		parameterStatuses: parameterStatuses,
 		txStatus:          hc.TxStatus,
 		frontend:          hc.Frontend,
 		config:            hc.Config,
-		customData:        hc.CustomData,
+		customData:        customData,
 
 		status: connStatusIdle,
 
@@ -1971,7 +2534,7 @@ func Construct(hc *HijackedConn) (*PgConn, error) {
 
 	pgConn.contextWatcher = ctxwatch.NewContextWatcher(hc.Config.BuildContextWatcherHandler(pgConn))
 	pgConn.bgReader = bgreader.New(pgConn.conn)
-	pgConn.slowWriteTimer = time.AfterFunc(time.Duration(math.MaxInt64),
+	pgConn.slowWriteTimer = clockOrSystem(pgConn.config.Clock).AfterFunc(time.Duration(math.MaxInt64),
 		func() {
 			pgConn.bgReader.Start()
 			pgConn.bgReaderStarted <- struct{}{}
@@ -1980,10 +2543,44 @@ func Construct(hc *HijackedConn) (*PgConn, error) {
 	pgConn.slowWriteTimer.Stop()
 	pgConn.bgReaderStarted = make(chan struct{})
 	pgConn.frontend = hc.Config.BuildFrontend(pgConn.bgReader, pgConn.conn)
+	pgConn.configureFrontend()
 
 	return pgConn, nil
 }
 
+func validateHijackedConn(hc *HijackedConn) error {
+	if hc.Conn == nil {
+		return errors.New("construct: Conn is required")
+	}
+	if hc.Config == nil {
+		return errors.New("construct: Config is required")
+	}
+
+	switch hc.TxStatus {
+	case 'I', 'T', 'E':
+	default:
+		return fmt.Errorf("construct: invalid TxStatus: %q", hc.TxStatus)
+	}
+
+	return nil
+}
+
+// readBufferConn is a net.Conn that returns buf before reading from Conn. It is used by Construct to process data that
+// was read but not processed before the connection was hijacked.
+type readBufferConn struct {
+	net.Conn
+	buf []byte
+}
+
+func (c *readBufferConn) Read(p []byte) (int, error) {
+	if len(c.buf) > 0 {
+		n := copy(p, c.buf)
+		c.buf = c.buf[n:]
+		return n, nil
+	}
+	return c.Conn.Read(p)
+}
+
 // Pipeline represents a connection in pipeline mode.
 //
 // SendPrepare, SendQueryParams, and SendQueryPrepared queue requests to the server. These requests are not written until
@@ -2133,7 +2730,7 @@ func (s *pipelineState) ExpectedReadyForQuery() int {
 //
 // Prefer ExecBatch when only sending one group of queries at once.
 func (pgConn *PgConn) StartPipeline(ctx context.Context) *Pipeline {
-	if err := pgConn.lock(); err != nil {
+	if err := pgConn.lockContext(ctx); err != nil {
 		pipeline := &Pipeline{
 			closed: true,
 			err:    err,
@@ -2252,11 +2849,11 @@ func (p *Pipeline) Flush() error {
 		return errors.New("pipeline closed")
 	}
 
-	err := p.conn.flushWithPotentialWriteReadDeadlock()
+	err := p.conn.flushResumable(p.ctx)
 	if err != nil {
 		err = normalizeTimeoutError(p.ctx, err)
 
-		p.conn.asyncClose()
+		p.conn.asyncClose(err)
 
 		p.conn.contextWatcher.Unwatch()
 		p.conn.unlock()
@@ -2299,7 +2896,7 @@ func (p *Pipeline) getResults() (results any, err error) {
 		if err != nil {
 			p.closed = true
 			p.err = err
-			p.conn.asyncClose()
+			p.conn.asyncClose(err)
 			return nil, normalizeTimeoutError(p.ctx, err)
 		}
 
@@ -2322,7 +2919,7 @@ func (p *Pipeline) getResults() (results any, err error) {
 		case *pgproto3.ParseComplete:
 			peekedMsg, err := p.conn.peekMessage()
 			if err != nil {
-				p.conn.asyncClose()
+				p.conn.asyncClose(err)
 				return nil, normalizeTimeoutError(p.ctx, err)
 			}
 			if _, ok := peekedMsg.(*pgproto3.ParameterDescription); ok {
@@ -2347,7 +2944,7 @@ func (p *Pipeline) getResultsPrepare() (*StatementDescription, error) {
 	for {
 		msg, err := p.conn.receiveMessage()
 		if err != nil {
-			p.conn.asyncClose()
+			p.conn.asyncClose(err)
 			return nil, normalizeTimeoutError(p.ctx, err)
 		}
 
@@ -2370,11 +2967,13 @@ func (p *Pipeline) getResultsPrepare() (*StatementDescription, error) {
 			p.state.HandleError(pgErr)
 			return nil, pgErr
 		case *pgproto3.CommandComplete:
-			p.conn.asyncClose()
-			return nil, errors.New("BUG: received CommandComplete while handling Describe")
+			err := errors.New("BUG: received CommandComplete while handling Describe")
+			p.conn.asyncClose(err)
+			return nil, err
 		case *pgproto3.ReadyForQuery:
-			p.conn.asyncClose()
-			return nil, errors.New("BUG: received ReadyForQuery while handling Describe")
+			err := errors.New("BUG: received ReadyForQuery while handling Describe")
+			p.conn.asyncClose(err)
+			return nil, err
 		}
 	}
 }
@@ -2388,8 +2987,8 @@ func (p *Pipeline) Close() error {
 	p.closed = true
 
 	if p.state.PendingSync() {
-		p.conn.asyncClose()
 		p.err = errors.New("pipeline has unsynced requests")
+		p.conn.asyncClose(p.err)
 		p.conn.contextWatcher.Unwatch()
 		p.conn.unlock()
 
@@ -2402,7 +3001,7 @@ func (p *Pipeline) Close() error {
 			p.err = err
 			var pgErr *PgError
 			if !errors.As(err, &pgErr) {
-				p.conn.asyncClose()
+				p.conn.asyncClose(err)
 				break
 			}
 		}
@@ -2420,10 +3019,13 @@ type DeadlineContextWatcherHandler struct {
 
 	// DeadlineDelay is the delay to set on the deadline set on net.Conn when the context is canceled.
 	DeadlineDelay time.Duration
+
+	// Clock is used to compute the deadline. If nil, the system clock is used.
+	Clock Clock
 }
 
 func (h *DeadlineContextWatcherHandler) HandleCancel(ctx context.Context) {
-	h.Conn.SetDeadline(time.Now().Add(h.DeadlineDelay))
+	h.Conn.SetDeadline(clockOrSystem(h.Clock).Now().Add(h.DeadlineDelay))
 }
 
 func (h *DeadlineContextWatcherHandler) HandleUnwatchAfterCancel() {
@@ -2441,6 +3043,13 @@ type CancelRequestContextWatcherHandler struct {
 	// DeadlineDelay is the delay to set on the deadline set on net.Conn when the context is canceled.
 	DeadlineDelay time.Duration
 
+	// Clock is used for the cancel request delay and the deadline. If nil, the clock of Conn's Config is used.
+	Clock Clock
+
+	// NoDeadline prevents a deadline from being set on net.Conn. The operation is only interrupted by the server
+	// canceling the query. If the server never responds to the cancel request the operation can block indefinitely.
+	NoDeadline bool
+
 	cancelFinishedChan             chan struct{}
 	handleUnwatchAfterCancelCalled func()
 }
@@ -2450,19 +3059,35 @@ func (h *CancelRequestContextWatcherHandler) HandleCancel(context.Context) {
 	var handleUnwatchedAfterCancelCalledCtx context.Context
 	handleUnwatchedAfterCancelCalledCtx, h.handleUnwatchAfterCancelCalled = context.WithCancel(context.Background())
 
-	deadline := time.Now().Add(h.DeadlineDelay)
-	h.Conn.conn.SetDeadline(deadline)
+	clock := h.Clock
+	if clock == nil {
+		clock = clockOrSystem(h.Conn.config.Clock)
+	}
+
+	var deadline time.Time
+	if !h.NoDeadline {
+		deadline = clock.Now().Add(h.DeadlineDelay)
+		h.Conn.conn.SetDeadline(deadline)
+	}
 
 	go func() {
 		defer close(h.cancelFinishedChan)
 
+		cancelRequestDelayTimer := clock.NewTimer(h.CancelRequestDelay)
 		select {
 		case <-handleUnwatchedAfterCancelCalledCtx.Done():
+			cancelRequestDelayTimer.Stop()
 			return
-		case <-time.After(h.CancelRequestDelay):
+		case <-cancelRequestDelayTimer.C():
 		}
 
-		cancelRequestCtx, cancel := context.WithDeadline(handleUnwatchedAfterCancelCalledCtx, deadline)
+		var cancelRequestCtx context.Context
+		var cancel context.CancelFunc
+		if deadline.IsZero() {
+			cancelRequestCtx, cancel = context.WithCancel(handleUnwatchedAfterCancelCalledCtx)
+		} else {
+			cancelRequestCtx, cancel = context.WithDeadline(handleUnwatchedAfterCancelCalledCtx, deadline)
+		}
 		defer cancel()
 		h.Conn.CancelRequest(cancelRequestCtx)
 
@@ -2471,7 +3096,7 @@ func (h *CancelRequestContextWatcherHandler) HandleCancel(context.Context) {
 		// immediately used then it is possible the CancelRequest will actually cancel our next query. The
 		// TestCancelRequestContextWatcherHandler Stress test can produce this error without the sleep below. The sleep time
 		// is arbitrary, but should be sufficient to prevent this error case.
-		time.Sleep(100 * time.Millisecond)
+		<-clock.NewTimer(100 * time.Millisecond).C()
 	}()
 }
 
@@ -14,6 +14,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -22,9 +23,9 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/internal/pgio"
-	"github.com/jackc/pgx/v5/internal/pgmock"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
+	"github.com/jackc/pgx/v5/pgmock"
 	"github.com/jackc/pgx/v5/pgproto3"
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -603,6 +604,470 @@ func TestConnectWithValidateConnectTargetSessionAttrsReadWrite(t *testing.T) {
 	}
 }
 
+func TestConnectTargetSessionAttrsUsesParameterStatus(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		targetSessionAttrs string
+		inHotStandby       string
+		defaultReadOnly    string
+		expectedErr        string
+	}{
+		{targetSessionAttrs: "primary", inHotStandby: "off", defaultReadOnly: "off"},
+		{targetSessionAttrs: "primary", inHotStandby: "on", defaultReadOnly: "off", expectedErr: "server is in standby mode"},
+		{targetSessionAttrs: "standby", inHotStandby: "on", defaultReadOnly: "off"},
+		{targetSessionAttrs: "standby", inHotStandby: "off", defaultReadOnly: "off", expectedErr: "server is not in hot standby mode"},
+		{targetSessionAttrs: "read-write", inHotStandby: "off", defaultReadOnly: "off"},
+		{targetSessionAttrs: "read-write", inHotStandby: "off", defaultReadOnly: "on", expectedErr: "read only connection"},
+		{targetSessionAttrs: "read-write", inHotStandby: "on", defaultReadOnly: "off", expectedErr: "read only connection"},
+		{targetSessionAttrs: "read-only", inHotStandby: "on", defaultReadOnly: "off"},
+		{targetSessionAttrs: "read-only", inHotStandby: "off", defaultReadOnly: "off", expectedErr: "connection is not read only"},
+	} {
+		tt := tt
+		name := fmt.Sprintf("%s in_hot_standby=%s default_transaction_read_only=%s", tt.targetSessionAttrs, tt.inHotStandby, tt.defaultReadOnly)
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			// The server never answers a query, so validation must be based on the reported parameter statuses alone.
+			steps := []pgmock.Step{
+				pgmock.ExpectAnyMessage(&pgproto3.StartupMessage{ProtocolVersion: pgproto3.ProtocolVersionNumber, Parameters: map[string]string{}}),
+				pgmock.SendMessage(&pgproto3.AuthenticationOk{}),
+				pgmock.SendMessage(&pgproto3.ParameterStatus{Name: "in_hot_standby", Value: tt.inHotStandby}),
+				pgmock.SendMessage(&pgproto3.ParameterStatus{Name: "default_transaction_read_only", Value: tt.defaultReadOnly}),
+				pgmock.SendMessage(&pgproto3.BackendKeyData{ProcessID: 0, SecretKey: 0}),
+				pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}),
+			}
+			if tt.expectedErr == "" {
+				steps = append(steps, pgmock.ExpectMessage(&pgproto3.Terminate{}))
+			}
+
+			server, err := pgmock.NewServer(&pgmock.Script{Steps: steps})
+			require.NoError(t, err)
+			defer server.Close()
+
+			conn, err := pgconn.Connect(ctx, server.ConnString()+" target_session_attrs="+tt.targetSessionAttrs)
+			if tt.expectedErr == "" {
+				require.NoError(t, err)
+				require.NoError(t, conn.Close(ctx))
+			} else {
+				require.ErrorContains(t, err, tt.expectedErr)
+			}
+
+			require.NoError(t, server.Close())
+		})
+	}
+}
+
+func TestConnGuardedParameterStatuses(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name        string
+		param       string
+		handler     pgconn.ParameterStatusChangeHandler
+		expectedErr string
+	}{
+		{
+			name:        "no handler",
+			param:       "client_encoding",
+			expectedErr: `unexpected change of parameter client_encoding from "UTF8" to "LATIN1"`,
+		},
+		{
+			name:  "handler accepts",
+			param: "client_encoding",
+			handler: func(pgConn *pgconn.PgConn, name, oldValue, newValue string) error {
+				return nil
+			},
+		},
+		{
+			name:  "handler rejects",
+			param: "client_encoding",
+			handler: func(pgConn *pgconn.PgConn, name, oldValue, newValue string) error {
+				return errors.New("rejected")
+			},
+			expectedErr: `unexpected change of parameter client_encoding from "UTF8" to "LATIN1": rejected`,
+		},
+		{
+			name:  "unguarded parameter",
+			param: "application_name",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			steps := []pgmock.Step{
+				pgmock.ExpectAnyMessage(&pgproto3.StartupMessage{ProtocolVersion: pgproto3.ProtocolVersionNumber, Parameters: map[string]string{}}),
+				pgmock.SendMessage(&pgproto3.AuthenticationOk{}),
+				pgmock.SendMessage(&pgproto3.ParameterStatus{Name: "client_encoding", Value: "UTF8"}),
+				pgmock.SendMessage(&pgproto3.ParameterStatus{Name: "application_name", Value: "foo"}),
+				pgmock.SendMessage(&pgproto3.BackendKeyData{ProcessID: 0, SecretKey: 0}),
+				pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}),
+				pgmock.ExpectMessage(&pgproto3.Query{String: "set something"}),
+				pgmock.SendMessage(&pgproto3.ParameterStatus{Name: tt.param, Value: "LATIN1"}),
+				pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte("SET")}),
+				pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}),
+			}
+			if tt.expectedErr == "" {
+				steps = append(steps, pgmock.ExpectMessage(&pgproto3.Terminate{}))
+			}
+
+			server, err := pgmock.NewServer(&pgmock.Script{Steps: steps})
+			require.NoError(t, err)
+			defer server.Close()
+
+			config, err := pgconn.ParseConfig(server.ConnString())
+			require.NoError(t, err)
+			config.GuardedParameterStatuses = pgconn.DefaultGuardedParameterStatuses
+			config.OnGuardedParameterStatusChange = tt.handler
+
+			conn, err := pgconn.ConnectConfig(ctx, config)
+			require.NoError(t, err)
+
+			_, err = conn.Exec(ctx, "set something").ReadAll()
+			if tt.expectedErr == "" {
+				require.NoError(t, err)
+				require.Equal(t, "LATIN1", conn.ParameterStatus(tt.param))
+				require.NoError(t, conn.Close(ctx))
+			} else {
+				require.EqualError(t, err, tt.expectedErr)
+				var changeErr *pgconn.ParameterStatusChangeError
+				require.ErrorAs(t, err, &changeErr)
+				require.Equal(t, "client_encoding", changeErr.Name)
+				require.True(t, conn.IsClosed())
+			}
+
+			require.NoError(t, server.Close())
+		})
+	}
+}
+
+func TestConnectNegotiateProtocolVersion(t *testing.T) {
+	t.Parallel()
+
+	extendedSecretKey := []byte("0123456789abcdef0123456789abcdef")
+
+	for _, tt := range []struct {
+		name                      string
+		connParams                string
+		serverSteps               []pgmock.Step
+		expectedProtocolVersion   uint32
+		expectedExtendedSecretKey []byte
+		expectedErr               string
+	}{
+		{
+			name:       "server supports 3.2",
+			connParams: "max_protocol_version=3.2",
+			serverSteps: []pgmock.Step{
+				pgmock.SendMessage(&pgproto3.AuthenticationOk{}),
+				pgmock.SendMessage(&pgproto3.BackendKeyData{ProcessID: 1, ExtendedSecretKey: extendedSecretKey}),
+				pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}),
+			},
+			expectedProtocolVersion:   pgproto3.ProtocolVersion32,
+			expectedExtendedSecretKey: extendedSecretKey,
+		},
+		{
+			name:       "server downgrades to 3.0",
+			connParams: "max_protocol_version=latest",
+			serverSteps: []pgmock.Step{
+				pgmock.SendMessage(&pgproto3.NegotiateProtocolVersion{NewestMinorProtocol: 0}),
+				pgmock.SendMessage(&pgproto3.AuthenticationOk{}),
+				pgmock.SendMessage(&pgproto3.BackendKeyData{ProcessID: 1, SecretKey: 2}),
+				pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}),
+			},
+			expectedProtocolVersion: pgproto3.ProtocolVersion30,
+		},
+		{
+			name:       "server does not support min protocol version",
+			connParams: "min_protocol_version=3.2 max_protocol_version=3.2",
+			serverSteps: []pgmock.Step{
+				pgmock.SendMessage(&pgproto3.NegotiateProtocolVersion{NewestMinorProtocol: 0}),
+			},
+			expectedErr: "server does not support min protocol version",
+		},
+		{
+			name:       "server reports unrecognized options",
+			connParams: "max_protocol_version=3.2",
+			serverSteps: []pgmock.Step{
+				pgmock.SendMessage(&pgproto3.NegotiateProtocolVersion{NewestMinorProtocol: 2, UnrecognizedOptions: []string{"_pq_.foo"}}),
+			},
+			expectedErr: "server reported unsupported protocol options",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			steps := []pgmock.Step{
+				pgmock.ExpectAnyMessage(&pgproto3.StartupMessage{ProtocolVersion: pgproto3.ProtocolVersion32, Parameters: map[string]string{}}),
+			}
+			steps = append(steps, tt.serverSteps...)
+			if tt.expectedErr == "" {
+				steps = append(steps, pgmock.ExpectMessage(&pgproto3.Terminate{}))
+			}
+
+			server, err := pgmock.NewServer(&pgmock.Script{Steps: steps})
+			require.NoError(t, err)
+			defer server.Close()
+
+			config, err := pgconn.ParseConfig(server.ConnString() + " " + tt.connParams)
+			require.NoError(t, err)
+
+			conn, err := pgconn.ConnectConfig(ctx, config)
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedProtocolVersion, conn.ProtocolVersion())
+			require.Equal(t, tt.expectedExtendedSecretKey, conn.ExtendedSecretKey())
+			if tt.expectedExtendedSecretKey == nil {
+				require.EqualValues(t, 2, conn.SecretKey())
+			}
+			require.NoError(t, conn.Close(ctx))
+
+			require.NoError(t, server.Close())
+		})
+	}
+}
+
+func TestConnCancelRequestExtendedSecretKey(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	extendedSecretKey := []byte("0123456789abcdef0123456789abcdef")
+
+	startupScript := &pgmock.Script{Steps: []pgmock.Step{
+		pgmock.ExpectAnyMessage(&pgproto3.StartupMessage{ProtocolVersion: pgproto3.ProtocolVersion32, Parameters: map[string]string{}}),
+		pgmock.SendMessage(&pgproto3.AuthenticationOk{}),
+		pgmock.SendMessage(&pgproto3.BackendKeyData{ProcessID: 1, ExtendedSecretKey: extendedSecretKey}),
+		pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}),
+	}}
+	terminateScript := &pgmock.Script{Steps: []pgmock.Step{
+		pgmock.ExpectMessage(&pgproto3.Terminate{}),
+	}}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	serverErrChan := make(chan error, 1)
+	cancelRequestChan := make(chan *pgproto3.CancelRequest, 1)
+	go func() {
+		defer close(serverErrChan)
+
+		conn, err := ln.Accept()
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+		defer conn.Close()
+
+		err = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+
+		backend := pgproto3.NewBackend(conn, conn)
+		err = startupScript.Run(backend)
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+
+		cancelConn, err := ln.Accept()
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+		defer cancelConn.Close()
+
+		msg, err := pgproto3.NewBackend(cancelConn, cancelConn).ReceiveStartupMessage()
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+		cancelRequest, ok := msg.(*pgproto3.CancelRequest)
+		if !ok {
+			serverErrChan <- fmt.Errorf("expected CancelRequest, got %T", msg)
+			return
+		}
+		cancelRequestChan <- cancelRequest
+		cancelConn.Close()
+
+		err = terminateScript.Run(backend)
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+	}()
+
+	host, port, _ := strings.Cut(ln.Addr().String(), ":")
+	connStr := fmt.Sprintf("sslmode=disable host=%s port=%s max_protocol_version=3.2", host, port)
+
+	conn, err := pgconn.Connect(ctx, connStr)
+	require.NoError(t, err)
+	require.Equal(t, extendedSecretKey, conn.ExtendedSecretKey())
+
+	err = conn.CancelRequest(ctx)
+	require.NoError(t, err)
+
+	cancelRequest := <-cancelRequestChan
+	require.EqualValues(t, 1, cancelRequest.ProcessID)
+	require.Equal(t, extendedSecretKey, cancelRequest.ExtendedSecretKey)
+
+	require.NoError(t, conn.Close(ctx))
+	require.NoError(t, <-serverErrChan)
+}
+
+func TestConnOnUnknownMessage(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	newScript := func() *pgmock.Script {
+		script := &pgmock.Script{Steps: pgmock.AcceptUnauthenticatedConnRequestSteps()}
+		script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Query{String: "select 1"}))
+		script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.UnknownMessage{Type: '~', Data: []byte("hello")}))
+		script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 0")}))
+		script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+		return script
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		server, err := pgmock.NewServer(newScript())
+		require.NoError(t, err)
+		defer server.Close()
+
+		config, err := pgconn.ParseConfig(server.ConnString())
+		require.NoError(t, err)
+
+		var msgType byte
+		var msgData []byte
+		config.OnUnknownMessage = func(pgConn *pgconn.PgConn, msg *pgproto3.UnknownMessage) {
+			msgType = msg.Type
+			msgData = append([]byte(nil), msg.Data...)
+		}
+
+		conn, err := pgconn.ConnectConfig(ctx, config)
+		require.NoError(t, err)
+		defer closeConn(t, conn)
+
+		results, err := conn.Exec(ctx, "select 1").ReadAll()
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.Equal(t, "SELECT 0", results[0].CommandTag.String())
+
+		require.EqualValues(t, '~', msgType)
+		require.Equal(t, []byte("hello"), msgData)
+	})
+
+	t.Run("no handler", func(t *testing.T) {
+		server, err := pgmock.NewServer(newScript())
+		require.NoError(t, err)
+		defer server.Close()
+
+		conn, err := pgconn.Connect(ctx, server.ConnString())
+		require.NoError(t, err)
+
+		_, err = conn.Exec(ctx, "select 1").ReadAll()
+		require.ErrorContains(t, err, "unknown message type: ~")
+		require.True(t, conn.IsClosed())
+	})
+}
+
+func TestConnOnClose(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	t.Run("fatal error", func(t *testing.T) {
+		script := &pgmock.Script{Steps: pgmock.AcceptUnauthenticatedConnRequestSteps()}
+		script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Query{String: "select 1"}))
+		script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.ErrorResponse{Severity: "FATAL", Code: "57P01", Message: "terminating connection"}))
+
+		server, err := pgmock.NewServer(script)
+		require.NoError(t, err)
+		defer server.Close()
+
+		config, err := pgconn.ParseConfig(server.ConnString())
+		require.NoError(t, err)
+
+		var onCloseCount int
+		var onCloseErr error
+		config.OnClose = func(pgConn *pgconn.PgConn, err error) {
+			onCloseCount++
+			onCloseErr = err
+		}
+
+		conn, err := pgconn.ConnectConfig(ctx, config)
+		require.NoError(t, err)
+
+		_, err = conn.Exec(ctx, "select 1").ReadAll()
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "57P01", pgErr.Code)
+
+		require.True(t, conn.IsClosed())
+		require.Equal(t, 1, onCloseCount)
+		require.Equal(t, pgErr, onCloseErr)
+
+		_, err = conn.Exec(ctx, "select 1").ReadAll()
+		var closedErr *pgconn.ConnectionClosedError
+		require.ErrorAs(t, err, &closedErr)
+		require.ErrorIs(t, err, pgErr)
+		require.EqualError(t, err, "conn closed: FATAL: terminating connection (SQLSTATE 57P01)")
+		require.True(t, pgconn.SafeToRetry(err))
+
+		require.NoError(t, conn.Close(ctx))
+		require.Equal(t, 1, onCloseCount)
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		script := &pgmock.Script{Steps: pgmock.AcceptUnauthenticatedConnRequestSteps()}
+		script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Terminate{}))
+
+		server, err := pgmock.NewServer(script)
+		require.NoError(t, err)
+		defer server.Close()
+
+		config, err := pgconn.ParseConfig(server.ConnString())
+		require.NoError(t, err)
+
+		var onCloseCount int
+		var onCloseErr error
+		config.OnClose = func(pgConn *pgconn.PgConn, err error) {
+			onCloseCount++
+			onCloseErr = err
+		}
+
+		conn, err := pgconn.ConnectConfig(ctx, config)
+		require.NoError(t, err)
+		require.NoError(t, conn.Close(ctx))
+		require.NoError(t, conn.Close(ctx))
+
+		require.Equal(t, 1, onCloseCount)
+		require.NoError(t, onCloseErr)
+
+		_, err = conn.Exec(ctx, "select 1").ReadAll()
+		require.EqualError(t, err, "conn closed")
+	})
+}
+
 func TestConnectWithAfterConnect(t *testing.T) {
 	t.Parallel()
 
@@ -759,7 +1224,7 @@ func TestConnDeallocateNonExistantStatementSucceeds(t *testing.T) {
 	ensureConnValid(t, pgConn)
 }
 
-func TestConnExec(t *testing.T) {
+func TestConnDeallocateMany(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -769,19 +1234,50 @@ func TestConnExec(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, pgConn)
 
-	results, err := pgConn.Exec(ctx, "select 'Hello, world'").ReadAll()
-	assert.NoError(t, err)
+	for _, name := range []string{"ps1", "ps2", "ps3"} {
+		_, err = pgConn.Prepare(ctx, name, "select 1", nil)
+		require.NoError(t, err)
+	}
 
-	assert.Len(t, results, 1)
-	assert.Nil(t, results[0].Err)
-	assert.Equal(t, "SELECT 1", results[0].CommandTag.String())
-	assert.Len(t, results[0].Rows, 1)
-	assert.Equal(t, "Hello, world", string(results[0].Rows[0][0]))
+	err = pgConn.DeallocateMany(ctx, []string{"ps1", "ps2", "nonexistent"})
+	require.NoError(t, err)
+
+	results, err := pgConn.Exec(ctx, "select name from pg_prepared_statements order by name").ReadAll()
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, [][][]byte{{[]byte("ps3")}}, results[0].Rows)
 
 	ensureConnValid(t, pgConn)
 }
 
-func TestConnExecEmpty(t *testing.T) {
+func TestConnDeallocateManySingleRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	script := &pgmock.Script{Steps: pgmock.AcceptUnauthenticatedConnRequestSteps()}
+	script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Close{ObjectType: 'S', Name: "ps1"}))
+	script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Close{ObjectType: 'S', Name: "ps2"}))
+	script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Sync{}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.CloseComplete{}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.CloseComplete{}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+
+	server, err := pgmock.NewServer(script)
+	require.NoError(t, err)
+	defer server.Close()
+
+	pgConn, err := pgconn.Connect(ctx, server.ConnString())
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	err = pgConn.DeallocateMany(ctx, []string{"ps1", "ps2"})
+	require.NoError(t, err)
+	require.False(t, pgConn.IsBusy())
+}
+
+func TestConnDeallocateAll(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -791,21 +1287,22 @@ func TestConnExecEmpty(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, pgConn)
 
-	multiResult := pgConn.Exec(ctx, ";")
-
-	resultCount := 0
-	for multiResult.NextResult() {
-		resultCount++
-		multiResult.ResultReader().Close()
+	for _, name := range []string{"ps1", "ps2"} {
+		_, err = pgConn.Prepare(ctx, name, "select 1", nil)
+		require.NoError(t, err)
 	}
-	assert.Equal(t, 0, resultCount)
-	err = multiResult.Close()
-	assert.NoError(t, err)
+
+	err = pgConn.DeallocateAll(ctx)
+	require.NoError(t, err)
+
+	results, err := pgConn.Exec(ctx, "select count(*) from pg_prepared_statements").ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][][]byte{{[]byte("0")}}, results[0].Rows)
 
 	ensureConnValid(t, pgConn)
 }
 
-func TestConnExecMultipleQueries(t *testing.T) {
+func TestQueuedPipeline(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -815,8 +1312,142 @@ func TestConnExecMultipleQueries(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, pgConn)
 
-	results, err := pgConn.Exec(ctx, "select 'Hello, world'; select 1").ReadAll()
-	assert.NoError(t, err)
+	_, err = pgConn.Prepare(ctx, "ps", "select 10 / $1::int", nil)
+	require.NoError(t, err)
+
+	qp := pgconn.NewQueuedPipeline(pgConn)
+	params := [][]byte{[]byte("1")}
+	pr1 := qp.ExecPrepared("ps", params, nil, nil)
+	params[0] = []byte("0") // ExecPrepared copies the parameters.
+	pr2 := qp.ExecPrepared("ps", params, nil, nil)
+	pr3 := qp.ExecPrepared("ps", [][]byte{[]byte("5")}, nil, nil)
+	require.Equal(t, 3, qp.Pending())
+	require.False(t, pr3.Done())
+
+	// Reading a later result sends all queued requests.
+	result, err := pr3.Read(ctx)
+	require.NoError(t, err)
+	require.Equal(t, [][][]byte{{[]byte("2")}}, result.Rows)
+	require.Equal(t, 0, qp.Pending())
+	require.True(t, pr1.Done())
+	require.True(t, pr2.Done())
+
+	result, err = pr1.Read(ctx)
+	require.NoError(t, err)
+	require.Equal(t, [][][]byte{{[]byte("10")}}, result.Rows)
+
+	// Each request is executed separately, so the failure of pr2 did not affect pr3.
+	_, err = pr2.Read(ctx)
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "22012", pgErr.Code)
+
+	ensureConnValid(t, pgConn)
+}
+
+func TestQueuedPipelineSingleRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// The server does not respond until it has received both requests.
+	script := &pgmock.Script{Steps: pgmock.AcceptUnauthenticatedConnRequestSteps()}
+	for i := 0; i < 2; i++ {
+		script.Steps = append(script.Steps, pgmock.ExpectAnyMessage(&pgproto3.Bind{}))
+		script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Describe{ObjectType: 'P'}))
+		script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Execute{}))
+		script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Sync{}))
+	}
+	for _, n := range []string{"1", "2"} {
+		script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.BindComplete{}))
+		script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+			{Name: []byte("n"), DataTypeOID: 23, DataTypeSize: 4, TypeModifier: -1},
+		}}))
+		script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.DataRow{Values: [][]byte{[]byte(n)}}))
+		script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 1")}))
+		script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+	}
+
+	server, err := pgmock.NewServer(script)
+	require.NoError(t, err)
+	defer server.Close()
+
+	pgConn, err := pgconn.Connect(ctx, server.ConnString())
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	qp := pgconn.NewQueuedPipeline(pgConn)
+	pr1 := qp.ExecPrepared("ps", [][]byte{[]byte("1")}, nil, nil)
+	pr2 := qp.ExecPrepared("ps", [][]byte{[]byte("2")}, nil, nil)
+
+	result, err := pr1.Read(ctx)
+	require.NoError(t, err)
+	require.Equal(t, [][][]byte{{[]byte("1")}}, result.Rows)
+
+	result, err = pr2.Read(ctx)
+	require.NoError(t, err)
+	require.Equal(t, [][][]byte{{[]byte("2")}}, result.Rows)
+}
+
+func TestConnExec(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgConn, err := pgconn.Connect(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	results, err := pgConn.Exec(ctx, "select 'Hello, world'").ReadAll()
+	assert.NoError(t, err)
+
+	assert.Len(t, results, 1)
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, "SELECT 1", results[0].CommandTag.String())
+	assert.Len(t, results[0].Rows, 1)
+	assert.Equal(t, "Hello, world", string(results[0].Rows[0][0]))
+
+	ensureConnValid(t, pgConn)
+}
+
+func TestConnExecEmpty(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgConn, err := pgconn.Connect(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	multiResult := pgConn.Exec(ctx, ";")
+
+	resultCount := 0
+	for multiResult.NextResult() {
+		resultCount++
+		multiResult.ResultReader().Close()
+	}
+	assert.Equal(t, 0, resultCount)
+	err = multiResult.Close()
+	assert.NoError(t, err)
+
+	ensureConnValid(t, pgConn)
+}
+
+func TestConnExecMultipleQueries(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgConn, err := pgconn.Connect(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	results, err := pgConn.Exec(ctx, "select 'Hello, world'; select 1").ReadAll()
+	assert.NoError(t, err)
 
 	assert.Len(t, results, 2)
 
@@ -1007,6 +1638,31 @@ func TestConnExecParams(t *testing.T) {
 	ensureConnValid(t, pgConn)
 }
 
+func TestConnExecParamsVectoredWrite(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgconn.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.WriteBufferSize = 4096
+	config.VectoredWriteThreshold = 1024
+
+	pgConn, err := pgconn.ConnectConfig(ctx, config)
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	large := strings.Repeat("x", 100_000)
+	result := pgConn.ExecParams(ctx, "select length($1::text), $2::text", [][]byte{[]byte(large), []byte("small")}, nil, nil, nil).Read()
+	require.NoError(t, result.Err)
+	require.Len(t, result.Rows, 1)
+	assert.Equal(t, "100000", string(result.Rows[0][0]))
+	assert.Equal(t, "small", string(result.Rows[0][1]))
+
+	ensureConnValid(t, pgConn)
+}
+
 func TestConnExecParamsDeferredError(t *testing.T) {
 	t.Parallel()
 
@@ -1615,6 +2271,102 @@ end$$;`)
 	ensureConnValid(t, pgConn)
 }
 
+func TestConnOnCopySkippedRow(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgconn.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+
+	var skippedRows []*pgconn.CopySkippedRow
+	config.OnCopySkippedRow = func(c *pgconn.PgConn, row *pgconn.CopySkippedRow) {
+		skippedRows = append(skippedRows, row)
+	}
+	config.RuntimeParams["client_min_messages"] = "notice"
+	config.RuntimeParams["lc_messages"] = "C"
+
+	pgConn, err := pgconn.ConnectConfig(ctx, config)
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	if pgConn.ParameterStatus("crdb_version") != "" {
+		t.Skip("Server does not support COPY ON_ERROR")
+	}
+	majorVersion, _, _ := strings.Cut(pgConn.ParameterStatus("server_version"), ".")
+	if v, err := strconv.Atoi(majorVersion); err != nil || v < 17 {
+		t.Skip("COPY ON_ERROR and LOG_VERBOSITY require PostgreSQL 17")
+	}
+
+	_, err = pgConn.Exec(ctx, `create temporary table foo(
+		a int4,
+		b text
+	)`).ReadAll()
+	require.NoError(t, err)
+
+	input := "1\tgood\nbad\tfoo\n3\tgood\n4x\tbar\n"
+	ct, err := pgConn.CopyFrom(ctx, strings.NewReader(input), "copy foo from stdin with (on_error ignore, log_verbosity verbose)")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, ct.RowsAffected())
+
+	require.Len(t, skippedRows, 2)
+	assert.EqualValues(t, 2, skippedRows[0].Line)
+	assert.Equal(t, "a", skippedRows[0].Column)
+	assert.Equal(t, "bad", skippedRows[0].Value)
+	assert.False(t, skippedRows[0].Null)
+	assert.EqualValues(t, 4, skippedRows[1].Line)
+	assert.Equal(t, "4x", skippedRows[1].Value)
+
+	ensureConnValid(t, pgConn)
+}
+
+func TestParseCopySkippedRow(t *testing.T) {
+	t.Parallel()
+
+	for i, tt := range []struct {
+		message string
+		ok      bool
+		row     pgconn.CopySkippedRow
+	}{
+		{
+			message: `skipping row due to data type incompatibility at line 2 for column "a": "bad"`,
+			ok:      true,
+			row:     pgconn.CopySkippedRow{Line: 2, Column: "a", Value: "bad"},
+		},
+		{
+			message: `skipping row due to data type incompatibility at line 12 for column "my col": "say "hi""`,
+			ok:      true,
+			row:     pgconn.CopySkippedRow{Line: 12, Column: "my col", Value: `say "hi"`},
+		},
+		{
+			message: `skipping row due to data type incompatibility at line 3 for column "d": null input`,
+			ok:      true,
+			row:     pgconn.CopySkippedRow{Line: 3, Column: "d", Null: true},
+		},
+		{
+			message: `2 rows were skipped due to data type incompatibility`,
+			ok:      false,
+		},
+		{
+			message: `hello, world`,
+			ok:      false,
+		},
+	} {
+		notice := &pgconn.Notice{Severity: "NOTICE", Message: tt.message}
+		row, ok := pgconn.ParseCopySkippedRow(notice)
+		require.Equalf(t, tt.ok, ok, "%d", i)
+		if !tt.ok {
+			continue
+		}
+		assert.Equalf(t, tt.row.Line, row.Line, "%d", i)
+		assert.Equalf(t, tt.row.Column, row.Column, "%d", i)
+		assert.Equalf(t, tt.row.Value, row.Value, "%d", i)
+		assert.Equalf(t, tt.row.Null, row.Null, "%d", i)
+		assert.Samef(t, notice, row.Notice, "%d", i)
+	}
+}
+
 func TestConnOnNotification(t *testing.T) {
 	t.Parallel()
 
@@ -1940,6 +2692,37 @@ func TestConnCopyFrom(t *testing.T) {
 	ensureConnValid(t, pgConn)
 }
 
+func TestConnCopyFromVectoredWrite(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgconn.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.VectoredWriteThreshold = 1024
+
+	pgConn, err := pgconn.ConnectConfig(ctx, config)
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	_, err = pgConn.Exec(ctx, `create temporary table foo(a text)`).ReadAll()
+	require.NoError(t, err)
+
+	// The large rows are sent in chunks longer than the threshold and the small ones in a chunk shorter than it.
+	large := strings.Repeat("x", 100_000)
+	src := strings.Repeat(large+"\n", 3) + "a\nb\n"
+	ct, err := pgConn.CopyFrom(ctx, strings.NewReader(src), "COPY foo FROM STDIN")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), ct.RowsAffected())
+
+	result := pgConn.ExecParams(ctx, "select sum(length(a)) from foo", nil, nil, nil, nil).Read()
+	require.NoError(t, result.Err)
+	assert.Equal(t, [][][]byte{{[]byte("300002")}}, result.Rows)
+
+	ensureConnValid(t, pgConn)
+}
+
 func TestConnCopyFromBinary(t *testing.T) {
 	t.Parallel()
 
@@ -2203,6 +2986,48 @@ func TestConnCopyFromQueryNoTableError(t *testing.T) {
 	ensureConnValid(t, pgConn)
 }
 
+// endlessCopyReader returns an unlimited number of copy rows and counts how many bytes were read.
+type endlessCopyReader struct {
+	row       []byte
+	bytesRead int64
+}
+
+func (r *endlessCopyReader) Read(p []byte) (int, error) {
+	n := 0
+	for n+len(r.row) <= len(p) {
+		n += copy(p[n:], r.row)
+	}
+	r.bytesRead += int64(n)
+	return n, nil
+}
+
+func TestConnCopyFromStopsOnServerError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgConn, err := pgconn.Connect(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	_, err = pgConn.Exec(ctx, `create temporary table foo(
+		a int4 primary key
+	)`).ReadAll()
+	require.NoError(t, err)
+
+	// Every row violates the primary key after the first. CopyFrom could never finish reading r so it must stop when the
+	// server reports the error.
+	r := &endlessCopyReader{row: []byte("1\n")}
+	_, err = pgConn.CopyFrom(ctx, r, "COPY foo FROM STDIN")
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	assert.Equal(t, "23505", pgErr.Code)
+	assert.Less(t, r.bytesRead, int64(100*1024*1024))
+
+	ensureConnValid(t, pgConn)
+}
+
 // https://github.com/jackc/pgconn/issues/21
 func TestConnCopyFromNoticeResponseReceivedMidStream(t *testing.T) {
 	t.Parallel()
@@ -2482,6 +3307,97 @@ func TestHijackAndConstruct(t *testing.T) {
 	ensureConnValid(t, newConn)
 }
 
+type sendMessagesStep []pgproto3.BackendMessage
+
+func (msgs sendMessagesStep) Step(backend *pgproto3.Backend) error {
+	for _, msg := range msgs {
+		backend.Send(msg)
+	}
+	return backend.Flush()
+}
+
+func TestHijackWithUnprocessedData(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	steps := pgmock.AcceptUnauthenticatedConnRequestSteps()
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Query{}))
+	// The notification is sent in the same write as the ReadyForQuery so it is buffered when Exec completes.
+	steps = append(steps, sendMessagesStep{
+		&pgproto3.CommandComplete{CommandTag: []byte("LISTEN")},
+		&pgproto3.ReadyForQuery{TxStatus: 'I'},
+		&pgproto3.NotificationResponse{PID: 42, Channel: "foo", Payload: "bar"},
+	})
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Query{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte("BEGIN")}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'T'}))
+	steps = append(steps, pgmock.WaitForClose())
+
+	server, err := pgmock.NewServer(&pgmock.Script{Steps: steps})
+	require.NoError(t, err)
+	defer server.Close()
+
+	origConn, err := pgconn.Connect(ctx, server.ConnString())
+	require.NoError(t, err)
+
+	_, err = origConn.Exec(ctx, "listen foo").ReadAll()
+	require.NoError(t, err)
+
+	hc, err := origConn.Hijack()
+	require.NoError(t, err)
+	require.NotEmpty(t, hc.ReadBuffer)
+
+	var notification *pgconn.Notification
+	hc.Config.OnNotification = func(_ *pgconn.PgConn, n *pgconn.Notification) { notification = n }
+
+	newConn, err := pgconn.Construct(hc)
+	require.NoError(t, err)
+	defer closeConn(t, newConn)
+
+	err = newConn.WaitForNotification(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, notification)
+	require.Equal(t, "bar", notification.Payload)
+
+	_, err = newConn.Exec(ctx, "begin").ReadAll()
+	require.NoError(t, err)
+	require.EqualValues(t, 'T', newConn.TxStatus())
+}
+
+func TestConstructValidatesHijackedConn(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgconn.ParseConfig("")
+	require.NoError(t, err)
+
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	for i, tt := range []struct {
+		hc  *pgconn.HijackedConn
+		err string
+	}{
+		{
+			hc:  &pgconn.HijackedConn{Config: config, TxStatus: 'I'},
+			err: "construct: Conn is required",
+		},
+		{
+			hc:  &pgconn.HijackedConn{Conn: client, TxStatus: 'I'},
+			err: "construct: Config is required",
+		},
+		{
+			hc:  &pgconn.HijackedConn{Conn: client, Config: config},
+			err: `construct: invalid TxStatus: '\x00'`,
+		},
+	} {
+		_, err := pgconn.Construct(tt.hc)
+		require.EqualErrorf(t, err, tt.err, "%d", i)
+	}
+}
+
 func TestConnCloseWhileCancellableQueryInProgress(t *testing.T) {
 	t.Parallel()
 
@@ -2502,6 +3418,238 @@ func TestConnCloseWhileCancellableQueryInProgress(t *testing.T) {
 	}
 }
 
+// unknownBackendMessage is a message with a type byte that is not part of the protocol.
+type unknownBackendMessage struct{}
+
+// interruptedWriteConn writes only half of the data of the next Write and returns os.ErrDeadlineExceeded as if a
+// deadline was briefly set on the connection.
+type interruptedWriteConn struct {
+	net.Conn
+	interruptNextWrite atomic.Bool
+}
+
+func (c *interruptedWriteConn) Write(b []byte) (int, error) {
+	if c.interruptNextWrite.CompareAndSwap(true, false) {
+		n, err := c.Conn.Write(b[:len(b)/2])
+		if err != nil {
+			return n, err
+		}
+		return n, os.ErrDeadlineExceeded
+	}
+	return c.Conn.Write(b)
+}
+
+func TestWriteResumedAfterDeadlineInterruption(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	script := &pgmock.Script{Steps: pgmock.AcceptUnauthenticatedConnRequestSteps()}
+	script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Query{String: "select 'resumed'"}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 0")}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+	script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Terminate{}))
+
+	server, err := pgmock.NewServer(script)
+	require.NoError(t, err)
+	defer server.Close()
+
+	config, err := pgconn.ParseConfig(server.ConnString())
+	require.NoError(t, err)
+
+	var conn *interruptedWriteConn
+	config.DialFunc = func(ctx context.Context, network, address string) (net.Conn, error) {
+		netConn, err := net.Dial(network, address)
+		if err != nil {
+			return nil, err
+		}
+		conn = &interruptedWriteConn{Conn: netConn}
+		return conn, nil
+	}
+
+	pgConn, err := pgconn.ConnectConfig(ctx, config)
+	require.NoError(t, err)
+
+	conn.interruptNextWrite.Store(true)
+	_, err = pgConn.Exec(ctx, "select 'resumed'").ReadAll()
+	require.NoError(t, err)
+	require.False(t, pgConn.IsClosed())
+
+	require.NoError(t, pgConn.Close(ctx))
+	require.NoError(t, server.Close())
+}
+
+// interruptedWriteTLSConn is an interruptedWriteConn that appears to be a TLS connection.
+type interruptedWriteTLSConn struct {
+	*interruptedWriteConn
+}
+
+func (c *interruptedWriteTLSConn) ConnectionState() tls.ConnectionState {
+	return tls.ConnectionState{}
+}
+
+func TestWriteNotResumedForTLSConn(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	script := &pgmock.Script{Steps: pgmock.AcceptUnauthenticatedConnRequestSteps()}
+	script.Steps = append(script.Steps, pgmock.WaitForClose())
+
+	server, err := pgmock.NewServer(script)
+	require.NoError(t, err)
+	defer server.Close()
+
+	config, err := pgconn.ParseConfig(server.ConnString())
+	require.NoError(t, err)
+
+	var conn *interruptedWriteConn
+	config.DialFunc = func(ctx context.Context, network, address string) (net.Conn, error) {
+		netConn, err := net.Dial(network, address)
+		if err != nil {
+			return nil, err
+		}
+		conn = &interruptedWriteConn{Conn: netConn}
+		return &interruptedWriteTLSConn{interruptedWriteConn: conn}, nil
+	}
+
+	pgConn, err := pgconn.ConnectConfig(ctx, config)
+	require.NoError(t, err)
+
+	conn.interruptNextWrite.Store(true)
+	_, err = pgConn.Exec(ctx, "select 'not resumed'").ReadAll()
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	require.True(t, pgConn.IsClosed())
+}
+
+func TestMaxResultBytesMock(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	const rowCount = 10
+	value := bytes.Repeat([]byte("x"), 100)
+
+	script := &pgmock.Script{Steps: pgmock.AcceptUnauthenticatedConnRequestSteps()}
+	script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Query{String: "select n"}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+		{Name: []byte("n"), DataTypeOID: 25, DataTypeSize: -1, TypeModifier: -1},
+	}}))
+	for i := 0; i < rowCount; i++ {
+		script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.DataRow{Values: [][]byte{value}}))
+	}
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte(fmt.Sprintf("SELECT %d", rowCount))}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 0")}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+	script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Query{String: "select 1"}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 0")}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+	script.Steps = append(script.Steps, pgmock.WaitForClose())
+
+	server, err := pgmock.NewServer(script)
+	require.NoError(t, err)
+	defer server.Close()
+
+	config, err := pgconn.ParseConfig(server.ConnString())
+	require.NoError(t, err)
+	config.MaxResultBytes = 350
+
+	pgConn, err := pgconn.ConnectConfig(ctx, config)
+	require.NoError(t, err)
+
+	results, err := pgConn.Exec(ctx, "select n").ReadAll()
+	var tooLargeErr *pgconn.ResultTooLargeError
+	require.ErrorAs(t, err, &tooLargeErr)
+	require.EqualValues(t, 350, tooLargeErr.MaxResultBytes)
+	require.EqualValues(t, 4, tooLargeErr.Rows)
+	require.EqualError(t, err, "result exceeded max result size of 350 bytes after 4 rows")
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Rows, 3)
+
+	_, err = pgConn.Exec(ctx, "select 1").ReadAll()
+	require.NoError(t, err)
+
+	require.NoError(t, pgConn.Close(ctx))
+	require.NoError(t, server.Close())
+}
+
+func (*unknownBackendMessage) Backend()                 {}
+func (*unknownBackendMessage) Decode(data []byte) error { return nil }
+func (*unknownBackendMessage) Encode(dst []byte) ([]byte, error) {
+	return append(dst, '~', 0, 0, 0, 4), nil
+}
+
+func TestProtocolErrorIncludesRecentMessages(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	steps := pgmock.AcceptUnauthenticatedConnRequestSteps()
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Query{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+		{Name: []byte("mock")},
+	}}))
+	steps = append(steps, pgmock.SendMessage(&unknownBackendMessage{}))
+
+	script := &pgmock.Script{Steps: steps}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	serverErrChan := make(chan error, 1)
+	go func() {
+		defer close(serverErrChan)
+
+		conn, err := ln.Accept()
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+		defer conn.Close()
+
+		err = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+
+		err = script.Run(pgproto3.NewBackend(conn, conn))
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+	}()
+
+	host, port, _ := strings.Cut(ln.Addr().String(), ":")
+	config, err := pgconn.ParseConfig(fmt.Sprintf("sslmode=disable host=%s port=%s", host, port))
+	require.NoError(t, err)
+	config.ProtocolHistorySize = 3
+
+	ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	conn, err := pgconn.ConnectConfig(ctx, config)
+	require.NoError(t, err)
+
+	_, err = conn.Exec(ctx, "select 'mocked'").ReadAll()
+	var protocolErr *pgconn.ProtocolError
+	require.ErrorAs(t, err, &protocolErr)
+	require.Len(t, protocolErr.RecentMessages, 3)
+	assert.Contains(t, protocolErr.RecentMessages[0], "ReadyForQuery")
+	assert.Contains(t, protocolErr.RecentMessages[1], "Query")
+	assert.Contains(t, protocolErr.RecentMessages[2], "RowDescription")
+	assert.Equal(t, protocolErr.RecentMessages, conn.RecentProtocolMessages())
+
+	// Message details such as SQL text and column names are not retained.
+	for _, msg := range protocolErr.RecentMessages {
+		assert.NotContains(t, msg, "mock")
+	}
+}
+
 // https://github.com/jackc/pgx/issues/800
 func TestFatalErrorReceivedAfterCommandComplete(t *testing.T) {
 	t.Parallel()
@@ -2601,7 +3749,172 @@ func TestConnLargeResponseWhileWritingDoesNotDeadlock(t *testing.T) {
 	ensureConnValid(t, pgConn)
 }
 
-func TestConnCheckConn(t *testing.T) {
+func TestConnCheckConn(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	// Intentionally using TCP connection for more predictable close behavior. (Not sure if Unix domain sockets would behave subtly different.)
+
+	connString := os.Getenv("PGX_TEST_TCP_CONN_STRING")
+	if connString == "" {
+		t.Skipf("Skipping due to missing environment variable %v", "PGX_TEST_TCP_CONN_STRING")
+	}
+
+	c1, err := pgconn.Connect(ctx, connString)
+	require.NoError(t, err)
+	defer c1.Close(ctx)
+
+	if c1.ParameterStatus("crdb_version") != "" {
+		t.Skip("Server does not support pg_terminate_backend() (https://github.com/cockroachdb/cockroach/issues/35897)")
+	}
+
+	err = c1.CheckConn()
+	require.NoError(t, err)
+
+	c2, err := pgconn.Connect(ctx, connString)
+	require.NoError(t, err)
+	defer c2.Close(ctx)
+
+	_, err = c2.Exec(ctx, fmt.Sprintf("select pg_terminate_backend(%d)", c1.PID())).ReadAll()
+	require.NoError(t, err)
+
+	// It may take a while for the server to kill the backend. Retry until the error is detected or the test context is
+	// canceled.
+	for err == nil && ctx.Err() == nil {
+		time.Sleep(50 * time.Millisecond)
+		err = c1.CheckConn()
+	}
+	require.Error(t, err)
+}
+
+func TestConnCheckConnErrorCause(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name  string
+		steps []pgmock.Step
+		cause pgconn.ConnCheckCause
+	}{
+		{
+			name: "idle session timeout",
+			steps: []pgmock.Step{pgmock.SendMessage(&pgproto3.ErrorResponse{
+				Severity: "FATAL",
+				Code:     "57P05",
+				Message:  "terminating connection due to idle-session timeout",
+			})},
+			cause: pgconn.ConnCheckCauseServerTerminated,
+		},
+		{
+			name:  "closed",
+			steps: nil,
+			cause: pgconn.ConnCheckCauseClosed,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			script := &pgmock.Script{Steps: append(pgmock.AcceptUnauthenticatedConnRequestSteps(), tt.steps...)}
+
+			ln, err := net.Listen("tcp", "127.0.0.1:")
+			require.NoError(t, err)
+			defer ln.Close()
+
+			serverErrChan := make(chan error, 1)
+			go func() {
+				defer close(serverErrChan)
+
+				conn, err := ln.Accept()
+				if err != nil {
+					serverErrChan <- err
+					return
+				}
+				defer conn.Close()
+
+				err = conn.SetDeadline(time.Now().Add(5 * time.Second))
+				if err != nil {
+					serverErrChan <- err
+					return
+				}
+
+				err = script.Run(pgproto3.NewBackend(conn, conn))
+				if err != nil {
+					serverErrChan <- err
+					return
+				}
+			}()
+
+			host, port, _ := strings.Cut(ln.Addr().String(), ":")
+			conn, err := pgconn.Connect(ctx, fmt.Sprintf("sslmode=disable host=%s port=%s", host, port))
+			require.NoError(t, err)
+			defer conn.Close(ctx)
+
+			require.NoError(t, <-serverErrChan)
+
+			for err == nil && ctx.Err() == nil {
+				err = conn.CheckConn()
+				if err == nil {
+					time.Sleep(10 * time.Millisecond)
+				}
+			}
+
+			var checkErr *pgconn.ConnCheckError
+			require.ErrorAs(t, err, &checkErr)
+			assert.Equal(t, tt.cause, checkErr.Cause)
+			if tt.cause == pgconn.ConnCheckCauseServerTerminated {
+				require.NotNil(t, checkErr.ServerError())
+				assert.Equal(t, "57P05", checkErr.ServerError().Code)
+			} else {
+				assert.Nil(t, checkErr.ServerError())
+			}
+		})
+	}
+}
+
+func TestConnPing(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	// Intentionally using TCP connection for more predictable close behavior. (Not sure if Unix domain sockets would behave subtly different.)
+
+	connString := os.Getenv("PGX_TEST_TCP_CONN_STRING")
+	if connString == "" {
+		t.Skipf("Skipping due to missing environment variable %v", "PGX_TEST_TCP_CONN_STRING")
+	}
+
+	c1, err := pgconn.Connect(ctx, connString)
+	require.NoError(t, err)
+	defer c1.Close(ctx)
+
+	if c1.ParameterStatus("crdb_version") != "" {
+		t.Skip("Server does not support pg_terminate_backend() (https://github.com/cockroachdb/cockroach/issues/35897)")
+	}
+
+	err = c1.Exec(ctx, "set log_statement = 'all'").Close()
+	require.NoError(t, err)
+
+	err = c1.Ping(ctx)
+	require.NoError(t, err)
+
+	c2, err := pgconn.Connect(ctx, connString)
+	require.NoError(t, err)
+	defer c2.Close(ctx)
+
+	_, err = c2.Exec(ctx, fmt.Sprintf("select pg_terminate_backend(%d)", c1.PID())).ReadAll()
+	require.NoError(t, err)
+
+	// Give a little time for the signal to actually kill the backend.
+	time.Sleep(500 * time.Millisecond)
+
+	err = c1.Ping(ctx)
+	require.Error(t, err)
+}
+
+func TestConnPingLight(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -2622,7 +3935,16 @@ func TestConnCheckConn(t *testing.T) {
 		t.Skip("Server does not support pg_terminate_backend() (https://github.com/cockroachdb/cockroach/issues/35897)")
 	}
 
-	err = c1.CheckConn()
+	err = c1.PingLight(ctx)
+	require.NoError(t, err)
+
+	// PingLight does not end a transaction.
+	err = c1.Exec(ctx, "begin").Close()
+	require.NoError(t, err)
+	err = c1.PingLight(ctx)
+	require.NoError(t, err)
+	require.Equal(t, byte('T'), c1.TxStatus())
+	err = c1.Exec(ctx, "rollback").Close()
 	require.NoError(t, err)
 
 	c2, err := pgconn.Connect(ctx, connString)
@@ -2632,54 +3954,88 @@ func TestConnCheckConn(t *testing.T) {
 	_, err = c2.Exec(ctx, fmt.Sprintf("select pg_terminate_backend(%d)", c1.PID())).ReadAll()
 	require.NoError(t, err)
 
-	// It may take a while for the server to kill the backend. Retry until the error is detected or the test context is
-	// canceled.
-	for err == nil && ctx.Err() == nil {
-		time.Sleep(50 * time.Millisecond)
-		err = c1.CheckConn()
-	}
+	// Give a little time for the signal to actually kill the backend.
+	time.Sleep(500 * time.Millisecond)
+
+	err = c1.PingLight(ctx)
 	require.Error(t, err)
+	var connCheckErr *pgconn.ConnCheckError
+	require.ErrorAs(t, err, &connCheckErr)
 }
 
-func TestConnPing(t *testing.T) {
+func TestConnPingLightSendsOnlySync(t *testing.T) {
 	t.Parallel()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// Intentionally using TCP connection for more predictable close behavior. (Not sure if Unix domain sockets would behave subtly different.)
+	script := &pgmock.Script{Steps: pgmock.AcceptUnauthenticatedConnRequestSteps()}
+	script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Sync{}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+	script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Sync{}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
 
-	connString := os.Getenv("PGX_TEST_TCP_CONN_STRING")
-	if connString == "" {
-		t.Skipf("Skipping due to missing environment variable %v", "PGX_TEST_TCP_CONN_STRING")
-	}
+	server, err := pgmock.NewServer(script)
+	require.NoError(t, err)
+	defer server.Close()
 
-	c1, err := pgconn.Connect(ctx, connString)
+	pgConn, err := pgconn.Connect(ctx, server.ConnString())
 	require.NoError(t, err)
-	defer c1.Close(ctx)
+	defer closeConn(t, pgConn)
 
-	if c1.ParameterStatus("crdb_version") != "" {
-		t.Skip("Server does not support pg_terminate_backend() (https://github.com/cockroachdb/cockroach/issues/35897)")
+	for i := 0; i < 2; i++ {
+		err = pgConn.PingLight(ctx)
+		require.NoError(t, err)
+		require.False(t, pgConn.IsBusy())
 	}
+}
 
-	err = c1.Exec(ctx, "set log_statement = 'all'").Close()
-	require.NoError(t, err)
+func TestResultReaderSetRowTimeout(t *testing.T) {
+	t.Parallel()
 
-	err = c1.Ping(ctx)
-	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-	c2, err := pgconn.Connect(ctx, connString)
+	script := &pgmock.Script{Steps: pgmock.AcceptUnauthenticatedConnRequestSteps()}
+	script.Steps = append(script.Steps, pgmock.ExpectAnyMessage(&pgproto3.Query{}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+		{Name: []byte("n"), DataTypeOID: 25, DataTypeSize: -1, TypeModifier: -1},
+	}}))
+	// The total time exceeds the row timeout, but the time between rows does not.
+	for _, v := range []string{"1", "2", "3"} {
+		script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.DataRow{Values: [][]byte{[]byte(v)}}))
+		script.Steps = append(script.Steps, pgmock.Sleep(300*time.Millisecond))
+	}
+	script.Steps = append(script.Steps, pgmock.Sleep(2*time.Second))
+
+	server, err := pgmock.NewServer(script)
 	require.NoError(t, err)
-	defer c2.Close(ctx)
+	defer server.Close()
 
-	_, err = c2.Exec(ctx, fmt.Sprintf("select pg_terminate_backend(%d)", c1.PID())).ReadAll()
+	pgConn, err := pgconn.Connect(ctx, server.ConnString())
 	require.NoError(t, err)
+	defer closeConn(t, pgConn)
 
-	// Give a little time for the signal to actually kill the backend.
-	time.Sleep(500 * time.Millisecond)
+	mrr := pgConn.Exec(ctx, "select n from stream")
+	require.True(t, mrr.NextResult())
+	rr := mrr.ResultReader()
+	rr.SetRowTimeout(500 * time.Millisecond)
 
-	err = c1.Ping(ctx)
-	require.Error(t, err)
+	var values []string
+	for rr.NextRow() {
+		values = append(values, string(rr.Values()[0]))
+	}
+	require.Equal(t, []string{"1", "2", "3"}, values)
+
+	_, err = rr.Close()
+	var rowTimeoutErr *pgconn.RowTimeoutError
+	require.ErrorAs(t, err, &rowTimeoutErr)
+	require.Equal(t, 500*time.Millisecond, rowTimeoutErr.Timeout)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NoError(t, ctx.Err())
+
+	mrr.Close()
+	require.True(t, pgConn.IsClosed())
 }
 
 func TestPipelinePrepare(t *testing.T) {
@@ -3105,6 +4461,145 @@ func TestPipelineQueryErrorBetweenSyncs(t *testing.T) {
 	ensureConnValid(t, pgConn)
 }
 
+func TestPipelineCorrelator(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	steps := pgmock.AcceptUnauthenticatedConnRequestSteps()
+	for i := 0; i < 3; i++ {
+		steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Parse{}))
+		steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Bind{}))
+		steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Describe{}))
+		steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Execute{}))
+	}
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Sync{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.ParseComplete{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.BindComplete{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+		{Name: []byte("n"), DataTypeOID: 23, DataTypeSize: 4, TypeModifier: -1},
+	}}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.DataRow{Values: [][]byte{[]byte("1")}}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 1")}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.ErrorResponse{Severity: "ERROR", Code: "22012", Message: "division by zero"}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Parse{}))
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Bind{}))
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Describe{}))
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Execute{}))
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Flush{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.ParseComplete{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.BindComplete{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.NoData{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte("INSERT 0 1")}))
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Sync{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+	steps = append(steps, pgmock.WaitForClose())
+
+	server, err := pgmock.NewServer(&pgmock.Script{Steps: steps})
+	require.NoError(t, err)
+	defer server.Close()
+
+	pgConn, err := pgconn.Connect(ctx, server.ConnString())
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	pipeline := pgConn.StartPipeline(ctx)
+	pc := pgconn.NewPipelineCorrelator(pipeline)
+
+	id1 := pc.SendQueryParams(`select 1`, nil, nil, nil, nil)
+	id2 := pc.SendQueryParams(`select 1/0`, nil, nil, nil, nil)
+	id3 := pc.SendQueryParams(`select 3`, nil, nil, nil, nil)
+	require.NoError(t, pc.Sync())
+	id4 := pc.SendQueryParams(`insert into t values (1)`, nil, nil, nil, nil)
+
+	// id3 was skipped by the server because id2 failed.
+	result, err := pc.GetResultFor(id3)
+	require.NoError(t, err)
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, result.Err, &pgErr)
+	require.Equal(t, "22012", pgErr.Code)
+
+	// id4 has not been sent yet. GetResultFor sends it with a flush request.
+	result, err = pc.GetResultFor(id4)
+	require.NoError(t, err)
+	require.NoError(t, result.Err)
+	require.Equal(t, "INSERT 0 1", result.Result.CommandTag.String())
+	require.NoError(t, pc.Sync())
+
+	result, err = pc.GetResultFor(id1)
+	require.NoError(t, err)
+	require.NoError(t, result.Err)
+	require.Equal(t, [][][]byte{{[]byte("1")}}, result.Result.Rows)
+
+	result, err = pc.GetResultFor(id2)
+	require.NoError(t, err)
+	require.ErrorAs(t, result.Err, &pgErr)
+
+	_, err = pc.GetResultFor(id2)
+	require.Error(t, err)
+
+	require.NoError(t, pc.Close())
+}
+
+func TestPipelineCorrelatorQueueWhileReading(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	steps := pgmock.AcceptUnauthenticatedConnRequestSteps()
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Close{}))
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Sync{}))
+	steps = append(steps, pgmock.Sleep(500*time.Millisecond))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.CloseComplete{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Close{}))
+	steps = append(steps, pgmock.ExpectAnyMessage(&pgproto3.Sync{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.CloseComplete{}))
+	steps = append(steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+	steps = append(steps, pgmock.WaitForClose())
+
+	server, err := pgmock.NewServer(&pgmock.Script{Steps: steps})
+	require.NoError(t, err)
+	defer server.Close()
+
+	pgConn, err := pgconn.Connect(ctx, server.ConnString())
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	pc := pgconn.NewPipelineCorrelator(pgConn.StartPipeline(ctx))
+
+	id1 := pc.SendDeallocate("ps1")
+	require.NoError(t, pc.Sync())
+
+	readDone := make(chan error)
+	go func() {
+		_, err := pc.GetResultFor(id1)
+		readDone <- err
+	}()
+
+	// Give the reader time to block on the server.
+	time.Sleep(100 * time.Millisecond)
+
+	// Queueing a request does not wait for the reader.
+	id2 := pc.SendDeallocate("ps2")
+	select {
+	case err := <-readDone:
+		t.Fatalf("expected reader to still be waiting, got %v", err)
+	default:
+	}
+
+	require.NoError(t, <-readDone)
+	require.NoError(t, pc.Sync())
+	result, err := pc.GetResultFor(id2)
+	require.NoError(t, err)
+	require.NoError(t, result.Err)
+
+	require.NoError(t, pc.Close())
+}
+
 func TestPipelineFlushForSingleRequests(t *testing.T) {
 	t.Parallel()
 
@@ -3909,6 +5404,100 @@ func TestDeadlineContextWatcherHandler(t *testing.T) {
 	})
 }
 
+type fixedClock struct {
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time { return c.now }
+
+func (c fixedClock) NewTimer(d time.Duration) pgconn.Timer {
+	return pgconn.SystemClock().NewTimer(d)
+}
+
+func (c fixedClock) AfterFunc(d time.Duration, f func()) pgconn.Timer {
+	return pgconn.SystemClock().AfterFunc(d, f)
+}
+
+type deadlineRecordingConn struct {
+	net.Conn
+	deadline time.Time
+}
+
+func (c *deadlineRecordingConn) SetDeadline(t time.Time) error {
+	c.deadline = t
+	return nil
+}
+
+func TestDeadlineContextWatcherHandlerUsesClock(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	conn := &deadlineRecordingConn{}
+	handler := &pgconn.DeadlineContextWatcherHandler{Conn: conn, DeadlineDelay: time.Second, Clock: fixedClock{now: now}}
+
+	handler.HandleCancel(context.Background())
+	require.Equal(t, now.Add(time.Second), conn.deadline)
+
+	handler.HandleUnwatchAfterCancel()
+	require.True(t, conn.deadline.IsZero())
+}
+
+func TestContextWatcherHandlerPresets(t *testing.T) {
+	t.Parallel()
+
+	for _, preset := range []pgconn.ContextWatcherHandlerPreset{
+		pgconn.ContextWatcherHandlerCancelDeadline,
+		pgconn.ContextWatcherHandlerCancelOnly,
+	} {
+		preset := preset
+		t.Run(string(preset), func(t *testing.T) {
+			t.Parallel()
+
+			config, err := pgconn.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+			require.NoError(t, err)
+			config.BuildContextWatcherHandler = pgconn.BuildPresetContextWatcherHandler(preset)
+			config.ConnectTimeout = 5 * time.Second
+
+			pgConn, err := pgconn.ConnectConfig(context.Background(), config)
+			require.NoError(t, err)
+			defer closeConn(t, pgConn)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			_, err = pgConn.Exec(ctx, "select 1, pg_sleep(3)").ReadAll()
+			require.Error(t, err)
+			var pgErr *pgconn.PgError
+			require.ErrorAs(t, err, &pgErr)
+
+			ensureConnValid(t, pgConn)
+		})
+	}
+
+	t.Run("per query override", func(t *testing.T) {
+		t.Parallel()
+
+		config, err := pgconn.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+		require.NoError(t, err)
+		config.ConnectTimeout = 5 * time.Second
+
+		pgConn, err := pgconn.ConnectConfig(context.Background(), config)
+		require.NoError(t, err)
+		defer closeConn(t, pgConn)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		ctx = pgconn.WithContextWatcherHandlerPreset(ctx, pgconn.ContextWatcherHandlerCancelDeadline)
+
+		_, err = pgConn.Exec(ctx, "select 1, pg_sleep(3)").ReadAll()
+		require.Error(t, err)
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+
+		ensureConnValid(t, pgConn)
+	})
+}
+
 func TestCancelRequestContextWatcherHandler(t *testing.T) {
 	t.Parallel()
 
@@ -4020,3 +5609,158 @@ func TestCancelRequestContextWatcherHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestNewLazyConn(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	script := &pgmock.Script{Steps: pgmock.AcceptUnauthenticatedConnRequestSteps()}
+	script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Query{String: "select 1"}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 0")}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+	script.Steps = append(script.Steps, pgmock.WaitForClose())
+
+	server, err := pgmock.NewServer(script)
+	require.NoError(t, err)
+	defer server.Close()
+
+	config, err := pgconn.ParseConfig(server.ConnString())
+	require.NoError(t, err)
+
+	pgConn := pgconn.NewLazyConn(config)
+	require.False(t, pgConn.IsClosed())
+	require.Nil(t, pgConn.Conn())
+	require.EqualValues(t, 'I', pgConn.TxStatus())
+	require.NoError(t, pgConn.CheckConn())
+
+	_, err = pgConn.Exec(ctx, "select 1").ReadAll()
+	require.NoError(t, err)
+	require.NotNil(t, pgConn.Conn())
+	require.NoError(t, pgConn.Connect(ctx))
+
+	require.NoError(t, pgConn.Close(ctx))
+	require.NoError(t, server.Close())
+}
+
+func TestNewLazyConnConnectError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	ln.Close()
+
+	config, err := pgconn.ParseConfig(fmt.Sprintf("host=%s port=%s sslmode=disable", host, port))
+	require.NoError(t, err)
+
+	pgConn := pgconn.NewLazyConn(config)
+	pgConn.CustomData()["foo"] = "bar"
+
+	_, err = pgConn.Exec(ctx, "select 1").ReadAll()
+	var connectErr *pgconn.ConnectError
+	require.ErrorAs(t, err, &connectErr)
+
+	// The connection can be retried after a failed connect.
+	require.False(t, pgConn.IsClosed())
+	require.Equal(t, "bar", pgConn.CustomData()["foo"])
+	require.Error(t, pgConn.Connect(ctx))
+
+	require.NoError(t, pgConn.Close(ctx))
+	require.True(t, pgConn.IsClosed())
+	select {
+	case <-pgConn.CleanupDone():
+	default:
+		t.Fatal("CleanupDone not closed")
+	}
+	require.Error(t, pgConn.Connect(ctx))
+}
+
+func TestReadAhead(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	config, err := pgconn.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.ReadAheadBufferSize = 4096
+
+	pgConn, err := pgconn.ConnectConfig(ctx, config)
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	for i := 0; i < 3; i++ {
+		rr := pgConn.ExecParams(ctx, "select n, repeat('x', 100) from generate_series(1, 10000) n", nil, nil, nil, nil)
+		var rowCount int
+		for rr.NextRow() {
+			rowCount++
+			require.Equal(t, strconv.Itoa(rowCount), string(rr.Values()[0]))
+		}
+		_, err = rr.Close()
+		require.NoError(t, err)
+		require.Equal(t, 10000, rowCount)
+
+		ensureConnValid(t, pgConn)
+	}
+}
+
+func TestReadAheadMock(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	const rowCount = 1000
+	value := bytes.Repeat([]byte("x"), 100)
+
+	script := &pgmock.Script{Steps: pgmock.AcceptUnauthenticatedConnRequestSteps()}
+	script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Query{String: "select n"}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+		{Name: []byte("n"), DataTypeOID: 25, DataTypeSize: -1, TypeModifier: -1},
+	}}))
+	for i := 0; i < rowCount; i++ {
+		script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.DataRow{Values: [][]byte{value}}))
+	}
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte(fmt.Sprintf("SELECT %d", rowCount))}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+	script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Query{String: "select 1"}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 0")}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+	script.Steps = append(script.Steps, pgmock.WaitForClose())
+
+	server, err := pgmock.NewServer(script)
+	require.NoError(t, err)
+	defer server.Close()
+
+	config, err := pgconn.ParseConfig(server.ConnString())
+	require.NoError(t, err)
+	config.ReadAheadBufferSize = 1024
+
+	pgConn, err := pgconn.ConnectConfig(ctx, config)
+	require.NoError(t, err)
+
+	mrr := pgConn.Exec(ctx, "select n")
+	require.True(t, mrr.NextResult())
+	rr := mrr.ResultReader()
+	var n int
+	for rr.NextRow() {
+		n++
+		require.Equal(t, value, rr.Values()[0])
+	}
+	commandTag, err := rr.Close()
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("SELECT %d", rowCount), commandTag.String())
+	require.Equal(t, rowCount, n)
+	require.NoError(t, mrr.Close())
+
+	_, err = pgConn.Exec(ctx, "select 1").ReadAll()
+	require.NoError(t, err)
+
+	require.NoError(t, pgConn.Close(ctx))
+	require.NoError(t, server.Close())
+}
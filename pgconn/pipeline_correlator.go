@@ -0,0 +1,318 @@
+package pgconn
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// PipelineRequestID identifies a request queued with a PipelineCorrelator.
+type PipelineRequestID uint64
+
+// PipelineResult is the result of a request queued with a PipelineCorrelator.
+type PipelineResult struct {
+	// Result is set for requests queued with SendQueryParams or SendQueryPrepared.
+	Result *Result
+
+	// StatementDescription is set for requests queued with SendPrepare.
+	StatementDescription *StatementDescription
+
+	// Err is the error returned by the server for the request. Requests after a failed request up to the next sync are
+	// not executed by the server. Err is the error of the failed request for these requests.
+	Err error
+}
+
+// PipelineCorrelator tags each request queued in a Pipeline with an ID and allows the results to be retrieved by ID in
+// any order. Results still arrive from the server in order. GetResultFor reads and buffers the results of earlier
+// requests until the result for the requested ID is available.
+//
+// Results are read in full and buffered in memory. Use Pipeline directly to stream large results.
+//
+// A PipelineCorrelator is safe for concurrent use. It allows different components to queue requests into the same
+// pipeline without coordinating the order in which they consume results. Queueing a request and retrieving a buffered
+// result do not wait for another goroutine that is reading from the server.
+type PipelineCorrelator struct {
+	// ioMux serializes the use of pipeline. It is held while sending to and reading from the server. It must be acquired
+	// before mux.
+	ioMux sync.Mutex
+
+	pipeline *Pipeline
+
+	// pgErr is the error of the last failed request. It is protected by ioMux.
+	pgErr error
+
+	// mux protects the fields below. It is never held during I/O.
+	mux sync.Mutex
+
+	lastID PipelineRequestID
+
+	// queued contains the requests that have not yet been written to pipeline. Requests are only written to pipeline
+	// while holding ioMux so queueing a request does not wait for a reader.
+	queued []func(*Pipeline)
+
+	// pending contains the requests that have not yet been read from the pipeline in the order they were queued.
+	pending list.List
+
+	results map[PipelineRequestID]*PipelineResult
+	err     error
+}
+
+type correlatedRequest struct {
+	id   PipelineRequestID
+	sync bool
+}
+
+// NewPipelineCorrelator returns a PipelineCorrelator for p. All requests must be queued through the PipelineCorrelator
+// and results must only be read through GetResultFor.
+func NewPipelineCorrelator(p *Pipeline) *PipelineCorrelator {
+	return &PipelineCorrelator{
+		pipeline: p,
+		results:  make(map[PipelineRequestID]*PipelineResult),
+	}
+}
+
+func (pc *PipelineCorrelator) queueRequest(send func(*Pipeline)) PipelineRequestID {
+	pc.mux.Lock()
+	defer pc.mux.Unlock()
+
+	pc.queued = append(pc.queued, send)
+	pc.lastID++
+	pc.pending.PushBack(correlatedRequest{id: pc.lastID})
+	return pc.lastID
+}
+
+// writeQueued writes the queued requests to pc.pipeline. pc.ioMux must be held.
+func (pc *PipelineCorrelator) writeQueued() {
+	pc.mux.Lock()
+	queued := pc.queued
+	pc.queued = nil
+	pc.mux.Unlock()
+
+	for _, send := range queued {
+		send(pc.pipeline)
+	}
+}
+
+// SendPrepare is the PipelineCorrelator version of *Pipeline.SendPrepare.
+func (pc *PipelineCorrelator) SendPrepare(name, sql string, paramOIDs []uint32) PipelineRequestID {
+	return pc.queueRequest(func(p *Pipeline) { p.SendPrepare(name, sql, paramOIDs) })
+}
+
+// SendDeallocate is the PipelineCorrelator version of *Pipeline.SendDeallocate.
+func (pc *PipelineCorrelator) SendDeallocate(name string) PipelineRequestID {
+	return pc.queueRequest(func(p *Pipeline) { p.SendDeallocate(name) })
+}
+
+// SendQueryParams is the PipelineCorrelator version of *Pipeline.SendQueryParams.
+func (pc *PipelineCorrelator) SendQueryParams(sql string, paramValues [][]byte, paramOIDs []uint32, paramFormats []int16, resultFormats []int16) PipelineRequestID {
+	return pc.queueRequest(func(p *Pipeline) {
+		p.SendQueryParams(sql, paramValues, paramOIDs, paramFormats, resultFormats)
+	})
+}
+
+// SendQueryPrepared is the PipelineCorrelator version of *Pipeline.SendQueryPrepared.
+func (pc *PipelineCorrelator) SendQueryPrepared(stmtName string, paramValues [][]byte, paramFormats []int16, resultFormats []int16) PipelineRequestID {
+	return pc.queueRequest(func(p *Pipeline) { p.SendQueryPrepared(stmtName, paramValues, paramFormats, resultFormats) })
+}
+
+// SendPipelineSync is the PipelineCorrelator version of *Pipeline.SendPipelineSync.
+func (pc *PipelineCorrelator) SendPipelineSync() {
+	pc.mux.Lock()
+	defer pc.mux.Unlock()
+
+	pc.queued = append(pc.queued, (*Pipeline).SendPipelineSync)
+	pc.pending.PushBack(correlatedRequest{sync: true})
+}
+
+// Flush is the PipelineCorrelator version of *Pipeline.Flush.
+func (pc *PipelineCorrelator) Flush() error {
+	pc.ioMux.Lock()
+	defer pc.ioMux.Unlock()
+
+	pc.writeQueued()
+	return pc.pipeline.Flush()
+}
+
+// Sync is the PipelineCorrelator version of *Pipeline.Sync.
+func (pc *PipelineCorrelator) Sync() error {
+	pc.SendPipelineSync()
+	return pc.Flush()
+}
+
+// GetResultFor returns the result of the request identified by id. The results of requests queued before id are read
+// and buffered. If the request has not been sent to the server yet, GetResultFor sends it and all other queued requests
+// along with a flush request. Each result can only be retrieved once.
+//
+// If the pipeline fails, for example due to a network error, the error is returned and the results of all requests
+// that have not yet been read are lost.
+func (pc *PipelineCorrelator) GetResultFor(id PipelineRequestID) (*PipelineResult, error) {
+	for {
+		result, done, err := pc.takeResult(id)
+		if done {
+			return result, err
+		}
+
+		pc.ioMux.Lock()
+		// Another goroutine may have read the result while this one waited for ioMux.
+		result, done, err = pc.takeResult(id)
+		if done {
+			pc.ioMux.Unlock()
+			return result, err
+		}
+		err = pc.readNext()
+		pc.ioMux.Unlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// takeResult removes and returns the buffered result for id. done is false if the result has not been read yet.
+func (pc *PipelineCorrelator) takeResult(id PipelineRequestID) (result *PipelineResult, done bool, err error) {
+	pc.mux.Lock()
+	defer pc.mux.Unlock()
+
+	if id == 0 || id > pc.lastID {
+		return nil, true, fmt.Errorf("unknown pipeline request ID %d", id)
+	}
+
+	if result, ok := pc.results[id]; ok {
+		delete(pc.results, id)
+		return result, true, nil
+	}
+
+	if pc.err != nil {
+		return nil, true, pc.err
+	}
+
+	if !pc.isPending(id) {
+		return nil, true, fmt.Errorf("result for pipeline request ID %d already retrieved", id)
+	}
+
+	return nil, false, nil
+}
+
+func (pc *PipelineCorrelator) isPending(id PipelineRequestID) bool {
+	for elem := pc.pending.Front(); elem != nil; elem = elem.Next() {
+		if elem.Value.(correlatedRequest).id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// fail records err as the error of the pipeline and returns it.
+func (pc *PipelineCorrelator) fail(err error) error {
+	pc.mux.Lock()
+	defer pc.mux.Unlock()
+
+	pc.err = err
+	return err
+}
+
+// readNext reads the result of the request at the front of pc.pending. pc.ioMux must be held.
+func (pc *PipelineCorrelator) readNext() error {
+	// The pipeline skips the requests after a failed request up to the next sync. They never receive a result from the
+	// server.
+	if pc.pgErr != nil {
+		pc.mux.Lock()
+		for elem := pc.pending.Front(); elem != nil; elem = pc.pending.Front() {
+			req := elem.Value.(correlatedRequest)
+			if req.sync {
+				break
+			}
+			pc.pending.Remove(elem)
+			pc.results[req.id] = &PipelineResult{Err: pc.pgErr}
+		}
+		pc.mux.Unlock()
+		pc.pgErr = nil
+	}
+
+	results, err := pc.pipeline.GetResults()
+	if err == nil && results == nil {
+		// The request at the front has been queued but the server has not been asked to send its result. Send everything
+		// that is queued with a flush request and try again.
+		pc.writeQueued()
+		pc.pipeline.SendFlushRequest()
+		if err := pc.pipeline.Flush(); err != nil {
+			return pc.fail(err)
+		}
+
+		results, err = pc.pipeline.GetResults()
+		if err == nil && results == nil {
+			return pc.fail(errors.New("BUG: no pipeline result after flush"))
+		}
+	}
+	if err != nil {
+		var pgErr *PgError
+		if !errors.As(err, &pgErr) {
+			return pc.fail(err)
+		}
+	}
+
+	pc.mux.Lock()
+	elem := pc.pending.Front()
+	if elem != nil {
+		pc.pending.Remove(elem)
+	}
+	pc.mux.Unlock()
+	if elem == nil {
+		return pc.fail(errors.New("BUG: received pipeline result without pending request"))
+	}
+	req := elem.Value.(correlatedRequest)
+
+	if err != nil {
+		pc.pgErr = err
+		if !req.sync {
+			pc.storeResult(req.id, &PipelineResult{Err: err})
+		}
+		return nil
+	}
+
+	if req.sync {
+		if _, ok := results.(*PipelineSync); !ok {
+			return pc.fail(fmt.Errorf("BUG: expected PipelineSync, got %T", results))
+		}
+		return nil
+	}
+
+	result := &PipelineResult{}
+	switch results := results.(type) {
+	case *ResultReader:
+		result.Result = results.Read()
+		if result.Result.Err != nil {
+			var pgErr *PgError
+			if !errors.As(result.Result.Err, &pgErr) {
+				return pc.fail(result.Result.Err)
+			}
+			result.Err = result.Result.Err
+			pc.pgErr = result.Result.Err
+		}
+	case *StatementDescription:
+		result.StatementDescription = results
+	case *CloseComplete:
+	default:
+		return pc.fail(fmt.Errorf("BUG: unexpected pipeline result %T", results))
+	}
+	pc.storeResult(req.id, result)
+
+	return nil
+}
+
+func (pc *PipelineCorrelator) storeResult(id PipelineRequestID, result *PipelineResult) {
+	pc.mux.Lock()
+	defer pc.mux.Unlock()
+
+	pc.results[id] = result
+}
+
+// Close closes the underlying pipeline. Buffered results that have not been retrieved are discarded. Requests that
+// are still queued are written to the pipeline first.
+func (pc *PipelineCorrelator) Close() error {
+	pc.ioMux.Lock()
+	defer pc.ioMux.Unlock()
+
+	pc.writeQueued()
+	return pc.pipeline.Close()
+}
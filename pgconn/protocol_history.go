@@ -0,0 +1,149 @@
+package pgconn
+
+import (
+	"strconv"
+	"sync"
+)
+
+// protocolHistory retains the sender, type, and length of the most recently sent and received protocol messages. It is
+// recorded by a pgproto3.Frontend message observer. The message contents such as SQL text, parameter values, and row
+// values are never seen so they cannot leak into bug reports.
+type protocolHistory struct {
+	mux     sync.Mutex
+	entries []protocolHistoryEntry
+	next    int
+	full    bool
+}
+
+type protocolHistoryEntry struct {
+	sender     byte
+	msgType    byte
+	encodedLen int32
+}
+
+func newProtocolHistory(size int) *protocolHistory {
+	return &protocolHistory{entries: make([]protocolHistoryEntry, size)}
+}
+
+// record is a pgproto3.Frontend message observer.
+func (h *protocolHistory) record(sender, msgType byte, encodedLen int32) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	h.entries[h.next] = protocolHistoryEntry{sender: sender, msgType: msgType, encodedLen: encodedLen}
+	h.next++
+	if h.next == len(h.entries) {
+		h.next = 0
+		h.full = true
+	}
+}
+
+// messages returns the retained messages from oldest to newest. Each message is formatted as the sender, the message
+// type, and the encoded length separated by tabs like the start of a pgproto3 trace line.
+func (h *protocolHistory) messages() []string {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	var entries []protocolHistoryEntry
+	if h.full {
+		entries = append(entries, h.entries[h.next:]...)
+	}
+	entries = append(entries, h.entries[:h.next]...)
+
+	messages := make([]string, len(entries))
+	for i, e := range entries {
+		messages[i] = string(e.sender) + "\t" + protocolMessageName(e.sender, e.msgType) + "\t" + strconv.FormatInt(int64(e.encodedLen), 10)
+	}
+	return messages
+}
+
+// protocolMessageName returns the name of the message of msgType sent by sender ('F' or 'B').
+func protocolMessageName(sender, msgType byte) string {
+	if sender == 'F' {
+		switch msgType {
+		case 0:
+			return "StartupMessage"
+		case 'B':
+			return "Bind"
+		case 'C':
+			return "Close"
+		case 'c':
+			return "CopyDone"
+		case 'd':
+			return "CopyData"
+		case 'D':
+			return "Describe"
+		case 'E':
+			return "Execute"
+		case 'f':
+			return "CopyFail"
+		case 'F':
+			return "FunctionCall"
+		case 'H':
+			return "Flush"
+		case 'p':
+			return "PasswordMessage"
+		case 'P':
+			return "Parse"
+		case 'Q':
+			return "Query"
+		case 'S':
+			return "Sync"
+		case 'X':
+			return "Terminate"
+		}
+	} else {
+		switch msgType {
+		case '1':
+			return "ParseComplete"
+		case '2':
+			return "BindComplete"
+		case '3':
+			return "CloseComplete"
+		case 'A':
+			return "NotificationResponse"
+		case 'c':
+			return "CopyDone"
+		case 'C':
+			return "CommandComplete"
+		case 'd':
+			return "CopyData"
+		case 'D':
+			return "DataRow"
+		case 'E':
+			return "ErrorResponse"
+		case 'G':
+			return "CopyInResponse"
+		case 'H':
+			return "CopyOutResponse"
+		case 'I':
+			return "EmptyQueryResponse"
+		case 'K':
+			return "BackendKeyData"
+		case 'n':
+			return "NoData"
+		case 'N':
+			return "NoticeResponse"
+		case 'R':
+			return "Authentication"
+		case 's':
+			return "PortalSuspended"
+		case 'S':
+			return "ParameterStatus"
+		case 't':
+			return "ParameterDescription"
+		case 'T':
+			return "RowDescription"
+		case 'v':
+			return "NegotiateProtocolVersion"
+		case 'V':
+			return "FunctionCallResponse"
+		case 'W':
+			return "CopyBothResponse"
+		case 'Z':
+			return "ReadyForQuery"
+		}
+	}
+
+	return "Unknown(" + strconv.Quote(string(msgType)) + ")"
+}
@@ -0,0 +1,151 @@
+package pgconn
+
+import (
+	"context"
+	"errors"
+)
+
+// QueuedPipeline is a manual batching helper that coalesces the requests queued with its ExecPrepared method into a
+// pipeline. QueuedPipeline.ExecPrepared does not communicate with the server. It queues the request and returns a
+// *PendingResult immediately. When the result of any queued request is read, all the requests queued so far are sent
+// to the server at once and their results are read and buffered. A burst of independent queries, such as the N+1
+// queries of an ORM, then takes a single round trip.
+//
+// Requests are only coalesced if they are explicitly queued with a QueuedPipeline. PgConn.ExecPrepared is not
+// affected and always sends its request immediately.
+//
+// Each request is executed in its own implicit transaction. A request that fails does not prevent the others from being
+// executed.
+//
+// The connection is only in pipeline mode while results are being read. Other methods of the PgConn may be called
+// between calls of QueuedPipeline methods, but they do not send the queued requests. Results are read in full and
+// buffered in memory. A QueuedPipeline is not safe for concurrent use.
+type QueuedPipeline struct {
+	pgConn *PgConn
+	queue  []*PendingResult
+}
+
+// PendingResult is the result of a request queued with QueuedPipeline.ExecPrepared.
+type PendingResult struct {
+	qp *QueuedPipeline
+
+	stmtName      string
+	paramValues   [][]byte
+	paramFormats  []int16
+	resultFormats []int16
+
+	result *Result
+	err    error
+	done   bool
+}
+
+// NewQueuedPipeline returns a QueuedPipeline for pgConn.
+func NewQueuedPipeline(pgConn *PgConn) *QueuedPipeline {
+	return &QueuedPipeline{pgConn: pgConn}
+}
+
+// ExecPrepared queues the execution of a prepared statement. The arguments are the same as for PgConn.ExecPrepared.
+// paramValues is copied, so it may be modified after ExecPrepared returns.
+func (qp *QueuedPipeline) ExecPrepared(stmtName string, paramValues [][]byte, paramFormats []int16, resultFormats []int16) *PendingResult {
+	pr := &PendingResult{
+		qp:            qp,
+		stmtName:      stmtName,
+		paramValues:   copyParamValues(paramValues),
+		paramFormats:  paramFormats,
+		resultFormats: resultFormats,
+	}
+	qp.queue = append(qp.queue, pr)
+	return pr
+}
+
+// Pending returns the number of requests that are queued and have not been sent to the server. It does not block.
+func (qp *QueuedPipeline) Pending() int {
+	return len(qp.queue)
+}
+
+// Flush sends all queued requests to the server in a single pipeline and buffers their results. It is called
+// automatically by PendingResult.Read.
+func (qp *QueuedPipeline) Flush(ctx context.Context) error {
+	queue := qp.queue
+	qp.queue = nil
+	if len(queue) == 0 {
+		return nil
+	}
+
+	pc := NewPipelineCorrelator(qp.pgConn.StartPipeline(ctx))
+	ids := make([]PipelineRequestID, len(queue))
+	for i, pr := range queue {
+		ids[i] = pc.SendQueryPrepared(pr.stmtName, pr.paramValues, pr.paramFormats, pr.resultFormats)
+		pc.SendPipelineSync()
+	}
+
+	err := pc.Flush()
+	for i, pr := range queue {
+		pr.done = true
+		if err != nil {
+			pr.err = err
+			continue
+		}
+
+		var result *PipelineResult
+		result, err = pc.GetResultFor(ids[i])
+		if err != nil {
+			pr.err = err
+			continue
+		}
+
+		pr.result = result.Result
+		pr.err = result.Err
+	}
+
+	closeErr := pc.Close()
+	if err == nil {
+		var pgErr *PgError
+		if !errors.As(closeErr, &pgErr) {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// Done returns true if the result has been received. It does not block.
+func (pr *PendingResult) Done() bool {
+	return pr.done
+}
+
+// Read returns the result of the request. If the request has not been sent to the server yet, Read sends all the
+// requests queued in the QueuedPipeline with ctx. If the server returned an error for the request it is returned as err
+// and is also the Err field of the Result if there is one.
+func (pr *PendingResult) Read(ctx context.Context) (*Result, error) {
+	if !pr.done {
+		// Flush records any error that prevented the result from being received in pr.
+		_ = pr.qp.Flush(ctx)
+	}
+
+	return pr.result, pr.err
+}
+
+func copyParamValues(paramValues [][]byte) [][]byte {
+	if paramValues == nil {
+		return nil
+	}
+
+	size := 0
+	for _, v := range paramValues {
+		size += len(v)
+	}
+
+	buf := make([]byte, 0, size)
+	copied := make([][]byte, len(paramValues))
+	for i, v := range paramValues {
+		if v == nil {
+			continue
+		}
+		start := len(buf)
+		buf = append(buf, v...)
+		copied[i] = buf[start:len(buf):len(buf)]
+	}
+
+	return copied
+}
@@ -0,0 +1,257 @@
+// Package pgmock provides the ability to mock a PostgreSQL server.
+//
+// A Script is a sequence of Steps that are run against a client connection. Steps expect messages from the client,
+// send messages to the client, or inject failures such as latency or an abrupt close. Server accepts connections and
+// runs a Script for each of them. This allows driver-level integration tests to run without a live PostgreSQL server.
+//
+//	script := &pgmock.Script{Steps: pgmock.AcceptUnauthenticatedConnRequestSteps()}
+//	script.Steps = append(script.Steps,
+//		pgmock.ExpectQuery(regexp.MustCompile(`^select`)),
+//		pgmock.SendResult(&pgmock.Result{
+//			Columns: []pgmock.Column{{Name: "n", DataTypeOID: pgtype.Int4OID}},
+//			Rows:    [][]any{{1}, {2}},
+//		}),
+//		pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}),
+//		pgmock.WaitForClose(),
+//	)
+//
+//	server, err := pgmock.NewServer(script)
+//	...
+//	conn, err := pgconn.Connect(ctx, server.ConnString())
+package pgmock
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgproto3"
+)
+
+// Step is a single step in a Script.
+type Step interface {
+	Step(*pgproto3.Backend) error
+}
+
+// Script is a sequence of steps. A Script is also a Step so scripts can be nested.
+type Script struct {
+	Steps []Step
+}
+
+// Run runs each step in order. It stops and returns the error of the first step that fails.
+func (s *Script) Run(backend *pgproto3.Backend) error {
+	for _, step := range s.Steps {
+		err := step.Step(backend)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Step runs s as a step of another Script.
+func (s *Script) Step(backend *pgproto3.Backend) error {
+	return s.Run(backend)
+}
+
+type expectMessageStep struct {
+	want pgproto3.FrontendMessage
+	any  bool
+}
+
+func (e *expectMessageStep) Step(backend *pgproto3.Backend) error {
+	msg, err := backend.Receive()
+	if err != nil {
+		return err
+	}
+
+	if e.any && reflect.TypeOf(msg) == reflect.TypeOf(e.want) {
+		return nil
+	}
+
+	if !reflect.DeepEqual(msg, e.want) {
+		return fmt.Errorf("msg => %#v, e.want => %#v", msg, e.want)
+	}
+
+	return nil
+}
+
+type expectStartupMessageStep struct {
+	want *pgproto3.StartupMessage
+	any  bool
+}
+
+func (e *expectStartupMessageStep) Step(backend *pgproto3.Backend) error {
+	msg, err := backend.ReceiveStartupMessage()
+	if err != nil {
+		return err
+	}
+
+	if e.any {
+		return nil
+	}
+
+	if !reflect.DeepEqual(msg, e.want) {
+		return fmt.Errorf("msg => %#v, e.want => %#v", msg, e.want)
+	}
+
+	return nil
+}
+
+// ExpectMessage returns a Step that receives a message and fails unless it is equal to want.
+func ExpectMessage(want pgproto3.FrontendMessage) Step {
+	return expectMessage(want, false)
+}
+
+// ExpectAnyMessage returns a Step that receives a message and fails unless it is the same type as want.
+func ExpectAnyMessage(want pgproto3.FrontendMessage) Step {
+	return expectMessage(want, true)
+}
+
+func expectMessage(want pgproto3.FrontendMessage, any bool) Step {
+	if want, ok := want.(*pgproto3.StartupMessage); ok {
+		return &expectStartupMessageStep{want: want, any: any}
+	}
+
+	return &expectMessageStep{want: want, any: any}
+}
+
+type sendMessageStep struct {
+	msg pgproto3.BackendMessage
+}
+
+func (e *sendMessageStep) Step(backend *pgproto3.Backend) error {
+	backend.Send(e.msg)
+	return backend.Flush()
+}
+
+// SendMessage returns a Step that sends msg.
+func SendMessage(msg pgproto3.BackendMessage) Step {
+	return &sendMessageStep{msg: msg}
+}
+
+type waitForCloseMessageStep struct{}
+
+func (e *waitForCloseMessageStep) Step(backend *pgproto3.Backend) error {
+	for {
+		msg, err := backend.Receive()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if _, ok := msg.(*pgproto3.Terminate); ok {
+			return nil
+		}
+	}
+}
+
+// WaitForClose returns a Step that receives and ignores messages until the client sends Terminate or closes the
+// connection.
+func WaitForClose() Step {
+	return &waitForCloseMessageStep{}
+}
+
+// AcceptUnauthenticatedConnRequestSteps returns the steps to accept a connection without authentication.
+func AcceptUnauthenticatedConnRequestSteps() []Step {
+	return []Step{
+		ExpectAnyMessage(&pgproto3.StartupMessage{ProtocolVersion: pgproto3.ProtocolVersionNumber, Parameters: map[string]string{}}),
+		SendMessage(&pgproto3.AuthenticationOk{}),
+		SendMessage(&pgproto3.BackendKeyData{ProcessID: 0, SecretKey: 0}),
+		SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}),
+	}
+}
+
+type expectMessageFuncStep struct {
+	check func(msg pgproto3.FrontendMessage) error
+}
+
+func (e *expectMessageFuncStep) Step(backend *pgproto3.Backend) error {
+	msg, err := backend.Receive()
+	if err != nil {
+		return err
+	}
+
+	return e.check(msg)
+}
+
+// ExpectMessageFunc returns a Step that receives a message and passes it to check. The step fails if check returns an
+// error. msg is only valid until check returns.
+func ExpectMessageFunc(check func(msg pgproto3.FrontendMessage) error) Step {
+	return &expectMessageFuncStep{check: check}
+}
+
+// ExpectQuery returns a Step that receives a simple protocol Query message and fails unless its SQL matches re.
+func ExpectQuery(re *regexp.Regexp) Step {
+	return ExpectMessageFunc(func(msg pgproto3.FrontendMessage) error {
+		query, ok := msg.(*pgproto3.Query)
+		if !ok {
+			return fmt.Errorf("expected Query, got %#v", msg)
+		}
+		if !re.MatchString(query.String) {
+			return fmt.Errorf("query %q does not match %v", query.String, re)
+		}
+		return nil
+	})
+}
+
+// ExpectParse returns a Step that receives a Parse message and fails unless its SQL matches re.
+func ExpectParse(re *regexp.Regexp) Step {
+	return ExpectMessageFunc(func(msg pgproto3.FrontendMessage) error {
+		parse, ok := msg.(*pgproto3.Parse)
+		if !ok {
+			return fmt.Errorf("expected Parse, got %#v", msg)
+		}
+		if !re.MatchString(parse.Query) {
+			return fmt.Errorf("parse query %q does not match %v", parse.Query, re)
+		}
+		return nil
+	})
+}
+
+// ExpectBind returns a Step that receives a Bind message and passes it to check. The step fails if check returns an
+// error. It can be used to make assertions on the parameters sent by the client.
+func ExpectBind(check func(bind *pgproto3.Bind) error) Step {
+	return ExpectMessageFunc(func(msg pgproto3.FrontendMessage) error {
+		bind, ok := msg.(*pgproto3.Bind)
+		if !ok {
+			return fmt.Errorf("expected Bind, got %#v", msg)
+		}
+		return check(bind)
+	})
+}
+
+type sleepStep struct {
+	d time.Duration
+}
+
+func (e *sleepStep) Step(backend *pgproto3.Backend) error {
+	time.Sleep(e.d)
+	return nil
+}
+
+// Sleep returns a Step that waits for d. It can be used to inject latency.
+func Sleep(d time.Duration) Step {
+	return &sleepStep{d: d}
+}
+
+// ErrCloseConnection is returned by the Step created by CloseConnection. Server closes the connection without error
+// when a Script returns it. Callers running a Script directly should close the connection when Run returns it.
+var ErrCloseConnection = errors.New("pgmock: close connection")
+
+type closeConnectionStep struct{}
+
+func (e *closeConnectionStep) Step(backend *pgproto3.Backend) error {
+	return ErrCloseConnection
+}
+
+// CloseConnection returns a Step that ends the script and causes the connection to be closed abruptly, i.e. without
+// sending an error or waiting for the client to terminate.
+func CloseConnection() Step {
+	return &closeConnectionStep{}
+}
@@ -0,0 +1,235 @@
+package pgmock_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgmock"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScript(t *testing.T) {
+	script := &pgmock.Script{
+		Steps: pgmock.AcceptUnauthenticatedConnRequestSteps(),
+	}
+	script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Query{String: "select 42"}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.RowDescription{
+		Fields: []pgproto3.FieldDescription{
+			{
+				Name:                 []byte("?column?"),
+				TableOID:             0,
+				TableAttributeNumber: 0,
+				DataTypeOID:          23,
+				DataTypeSize:         4,
+				TypeModifier:         -1,
+				Format:               0,
+			},
+		},
+	}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.DataRow{
+		Values: [][]byte{[]byte("42")},
+	}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 1")}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+	script.Steps = append(script.Steps, pgmock.ExpectMessage(&pgproto3.Terminate{}))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	serverErrChan := make(chan error, 1)
+	go func() {
+		defer close(serverErrChan)
+
+		conn, err := ln.Accept()
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+		defer conn.Close()
+
+		err = conn.SetDeadline(time.Now().Add(time.Second))
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+
+		err = script.Run(pgproto3.NewBackend(conn, conn))
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+	}()
+
+	host, port, _ := strings.Cut(ln.Addr().String(), ":")
+	connStr := fmt.Sprintf("sslmode=disable host=%s port=%s", host, port)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	pgConn, err := pgconn.Connect(ctx, connStr)
+	require.NoError(t, err)
+	results, err := pgConn.Exec(ctx, "select 42").ReadAll()
+	assert.NoError(t, err)
+
+	assert.Len(t, results, 1)
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, "SELECT 1", results[0].CommandTag.String())
+	assert.Len(t, results[0].Rows, 1)
+	assert.Equal(t, "42", string(results[0].Rows[0][0]))
+
+	pgConn.Close(ctx)
+
+	assert.NoError(t, <-serverErrChan)
+}
+
+func TestServerResult(t *testing.T) {
+	script := &pgmock.Script{
+		Steps: pgmock.AcceptUnauthenticatedConnRequestSteps(),
+	}
+	script.Steps = append(script.Steps, pgmock.ExpectQuery(regexp.MustCompile(`^select n from`)))
+	script.Steps = append(script.Steps, pgmock.SendResult(&pgmock.Result{
+		Columns: []pgmock.Column{{Name: "n", DataTypeOID: pgtype.Int4OID}, {Name: "s", DataTypeOID: pgtype.TextOID}},
+		Rows:    [][]any{{1, "a"}, {2, nil}},
+	}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+	script.Steps = append(script.Steps, pgmock.ExpectAnyMessage(&pgproto3.Terminate{}))
+
+	server, err := pgmock.NewServer(script)
+	require.NoError(t, err)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pgConn, err := pgconn.Connect(ctx, server.ConnString())
+	require.NoError(t, err)
+	results, err := pgConn.Exec(ctx, "select n from t").ReadAll()
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].FieldDescriptions, 2)
+	assert.Equal(t, "n", results[0].FieldDescriptions[0].Name)
+	assert.Equal(t, [][][]byte{{[]byte("1"), []byte("a")}, {[]byte("2"), nil}}, results[0].Rows)
+	assert.Equal(t, "SELECT 2", results[0].CommandTag.String())
+
+	require.NoError(t, pgConn.Close(ctx))
+	require.NoError(t, server.Close())
+}
+
+func TestServerQueryMismatch(t *testing.T) {
+	script := &pgmock.Script{
+		Steps: pgmock.AcceptUnauthenticatedConnRequestSteps(),
+	}
+	script.Steps = append(script.Steps, pgmock.ExpectQuery(regexp.MustCompile(`^select 1$`)))
+
+	server, err := pgmock.NewServer(script)
+	require.NoError(t, err)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pgConn, err := pgconn.Connect(ctx, server.ConnString())
+	require.NoError(t, err)
+	_, err = pgConn.Exec(ctx, "select 2").ReadAll()
+	require.Error(t, err)
+	pgConn.Close(ctx)
+
+	err = server.Close()
+	require.ErrorContains(t, err, `query "select 2" does not match`)
+}
+
+func TestServerCloseConnection(t *testing.T) {
+	script := &pgmock.Script{
+		Steps: pgmock.AcceptUnauthenticatedConnRequestSteps(),
+	}
+	script.Steps = append(script.Steps, pgmock.ExpectAnyMessage(&pgproto3.Query{}))
+	script.Steps = append(script.Steps, pgmock.Sleep(10*time.Millisecond))
+	script.Steps = append(script.Steps, pgmock.CloseConnection())
+
+	server, err := pgmock.NewServer(script)
+	require.NoError(t, err)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pgConn, err := pgconn.Connect(ctx, server.ConnString())
+	require.NoError(t, err)
+	_, err = pgConn.Exec(ctx, "select 1").ReadAll()
+	require.Error(t, err)
+	assert.True(t, pgConn.IsClosed())
+
+	require.NoError(t, server.Close())
+}
+
+func TestServerConnTimeout(t *testing.T) {
+	script := &pgmock.Script{
+		Steps: pgmock.AcceptUnauthenticatedConnRequestSteps(),
+	}
+	script.Steps = append(script.Steps, pgmock.ExpectAnyMessage(&pgproto3.Query{}))
+
+	server, err := pgmock.NewServerWithConfig(script, pgmock.ServerConfig{ConnTimeout: 100 * time.Millisecond})
+	require.NoError(t, err)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pgConn, err := pgconn.Connect(ctx, server.ConnString())
+	require.NoError(t, err)
+	defer pgConn.Close(ctx)
+
+	// The server waits for a query that is never sent until the connection times out.
+	err = server.Close()
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	require.True(t, netErr.Timeout())
+}
+
+func TestExpectBind(t *testing.T) {
+	script := &pgmock.Script{
+		Steps: pgmock.AcceptUnauthenticatedConnRequestSteps(),
+	}
+	script.Steps = append(script.Steps, pgmock.ExpectParse(regexp.MustCompile(`\$1`)))
+	script.Steps = append(script.Steps, pgmock.ExpectBind(func(bind *pgproto3.Bind) error {
+		if len(bind.Parameters) != 1 || string(bind.Parameters[0]) != "42" {
+			return fmt.Errorf("unexpected parameters: %v", bind.Parameters)
+		}
+		return nil
+	}))
+	script.Steps = append(script.Steps, pgmock.ExpectAnyMessage(&pgproto3.Describe{}))
+	script.Steps = append(script.Steps, pgmock.ExpectAnyMessage(&pgproto3.Execute{}))
+	script.Steps = append(script.Steps, pgmock.ExpectAnyMessage(&pgproto3.Sync{}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.ParseComplete{}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.BindComplete{}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.NoData{}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte("UPDATE 1")}))
+	script.Steps = append(script.Steps, pgmock.SendMessage(&pgproto3.ReadyForQuery{TxStatus: 'I'}))
+	script.Steps = append(script.Steps, pgmock.WaitForClose())
+
+	server, err := pgmock.NewServer(script)
+	require.NoError(t, err)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pgConn, err := pgconn.Connect(ctx, server.ConnString())
+	require.NoError(t, err)
+	result := pgConn.ExecParams(ctx, "update t set n = $1", [][]byte{[]byte("42")}, nil, nil, nil).Read()
+	require.NoError(t, result.Err)
+	assert.Equal(t, "UPDATE 1", result.CommandTag.String())
+
+	require.NoError(t, pgConn.Close(ctx))
+	require.NoError(t, server.Close())
+}
@@ -0,0 +1,155 @@
+package pgmock
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Column describes a column of a Result.
+type Column struct {
+	Name        string
+	DataTypeOID uint32
+}
+
+// Result is a canned query result built from Go values.
+type Result struct {
+	// Columns are the columns of the result.
+	Columns []Column
+
+	// Rows are the values of each row. Values are encoded with Map according to the DataTypeOID of their column.
+	Rows [][]any
+
+	// Format is the format code used to encode the values. The zero value is the text format.
+	Format int16
+
+	// Map is used to encode values. If nil, pgtype.NewMap() is used.
+	Map *pgtype.Map
+
+	// CommandTag is the command tag of the CommandComplete message. If empty, "SELECT n" is used where n is the number
+	// of rows.
+	CommandTag string
+}
+
+// RowDescription returns the RowDescription message of r.
+func (r *Result) RowDescription() *pgproto3.RowDescription {
+	m := r.typeMap()
+
+	fields := make([]pgproto3.FieldDescription, len(r.Columns))
+	for i, c := range r.Columns {
+		var dataTypeSize int16 = -1
+		if dt, ok := m.TypeForOID(c.DataTypeOID); ok {
+			dataTypeSize = typeSize(dt.Name)
+		}
+
+		fields[i] = pgproto3.FieldDescription{
+			Name:         []byte(c.Name),
+			DataTypeOID:  c.DataTypeOID,
+			DataTypeSize: dataTypeSize,
+			TypeModifier: -1,
+			Format:       r.Format,
+		}
+	}
+
+	return &pgproto3.RowDescription{Fields: fields}
+}
+
+// DataRows returns the DataRow messages of r.
+func (r *Result) DataRows() ([]*pgproto3.DataRow, error) {
+	m := r.typeMap()
+
+	dataRows := make([]*pgproto3.DataRow, len(r.Rows))
+	for i, row := range r.Rows {
+		if len(row) != len(r.Columns) {
+			return nil, fmt.Errorf("row %d has %d values but there are %d columns", i, len(row), len(r.Columns))
+		}
+
+		values := make([][]byte, len(row))
+		for j, v := range row {
+			buf, err := m.Encode(r.Columns[j].DataTypeOID, r.Format, v, nil)
+			if err != nil {
+				return nil, fmt.Errorf("row %d column %q: %w", i, r.Columns[j].Name, err)
+			}
+			values[j] = buf
+		}
+
+		dataRows[i] = &pgproto3.DataRow{Values: values}
+	}
+
+	return dataRows, nil
+}
+
+// CommandComplete returns the CommandComplete message of r.
+func (r *Result) CommandComplete() *pgproto3.CommandComplete {
+	commandTag := r.CommandTag
+	if commandTag == "" {
+		commandTag = fmt.Sprintf("SELECT %d", len(r.Rows))
+	}
+	return &pgproto3.CommandComplete{CommandTag: []byte(commandTag)}
+}
+
+// Messages returns the RowDescription, DataRow, and CommandComplete messages of r.
+func (r *Result) Messages() ([]pgproto3.BackendMessage, error) {
+	dataRows, err := r.DataRows()
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := make([]pgproto3.BackendMessage, 0, len(dataRows)+2)
+	msgs = append(msgs, r.RowDescription())
+	for _, dr := range dataRows {
+		msgs = append(msgs, dr)
+	}
+	msgs = append(msgs, r.CommandComplete())
+
+	return msgs, nil
+}
+
+func (r *Result) typeMap() *pgtype.Map {
+	if r.Map != nil {
+		return r.Map
+	}
+	return pgtype.NewMap()
+}
+
+// typeSize returns the size of fixed length types by name or -1 for variable length types.
+func typeSize(name string) int16 {
+	switch name {
+	case "bool", "char":
+		return 1
+	case "int2":
+		return 2
+	case "int4", "float4", "oid", "date":
+		return 4
+	case "int8", "float8", "timestamp", "timestamptz", "time", "money":
+		return 8
+	case "uuid", "interval":
+		return 16
+	default:
+		return -1
+	}
+}
+
+type sendResultStep struct {
+	result *Result
+}
+
+func (e *sendResultStep) Step(backend *pgproto3.Backend) error {
+	msgs, err := e.result.Messages()
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range msgs {
+		backend.Send(msg)
+	}
+	return backend.Flush()
+}
+
+// SendResult returns a Step that sends the RowDescription, DataRow, and CommandComplete messages of result. It does
+// not send ReadyForQuery. For the extended protocol, where the RowDescription is sent in response to Describe, use the
+// messages from Result directly with SendMessage.
+func SendResult(result *Result) Step {
+	return &sendResultStep{result: result}
+}
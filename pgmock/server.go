@@ -0,0 +1,111 @@
+package pgmock
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgproto3"
+)
+
+// Server is a mock PostgreSQL server that runs a Script for each accepted connection.
+type Server struct {
+	ln          net.Listener
+	script      *Script
+	connTimeout time.Duration
+
+	mux  sync.Mutex
+	errs []error
+
+	wg sync.WaitGroup
+}
+
+// ServerConfig configures a Server.
+type ServerConfig struct {
+	// ConnTimeout is the maximum time a connection may be open. If zero, it defaults to one minute.
+	ConnTimeout time.Duration
+}
+
+// NewServer starts a Server listening on a random port on 127.0.0.1 that runs script for each connection.
+func NewServer(script *Script) (*Server, error) {
+	return NewServerWithConfig(script, ServerConfig{})
+}
+
+// NewServerWithConfig is like NewServer but configures the Server with config.
+func NewServerWithConfig(script *Script, config ServerConfig) (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	if err != nil {
+		return nil, err
+	}
+
+	connTimeout := config.ConnTimeout
+	if connTimeout == 0 {
+		connTimeout = time.Minute
+	}
+
+	s := &Server{ln: ln, script: script, connTimeout: connTimeout}
+
+	s.wg.Add(1)
+	go s.acceptLoop()
+
+	return s, nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *Server) Addr() net.Addr {
+	return s.ln.Addr()
+}
+
+// ConnString returns a connection string for connecting to the server.
+func (s *Server) ConnString() string {
+	host, port, _ := net.SplitHostPort(s.ln.Addr().String())
+	return fmt.Sprintf("sslmode=disable host=%s port=%s", host, port)
+}
+
+// Close stops the server and waits for all connections to finish. It returns the errors returned by running the script
+// on each connection.
+func (s *Server) Close() error {
+	s.ln.Close()
+	s.wg.Wait()
+	return s.Err()
+}
+
+// Err returns the errors returned by running the script on connections that have finished.
+func (s *Server) Err() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return errors.Join(s.errs...)
+}
+
+func (s *Server) acceptLoop() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handleConn(conn)
+		}()
+	}
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	err := conn.SetDeadline(time.Now().Add(s.connTimeout))
+	if err == nil {
+		err = s.script.Run(pgproto3.NewBackend(conn, conn))
+	}
+	if err != nil && !errors.Is(err, ErrCloseConnection) {
+		s.mux.Lock()
+		s.errs = append(s.errs, err)
+		s.mux.Unlock()
+	}
+}
@@ -43,6 +43,11 @@ type Backend struct {
 	msgType    byte
 	partialMsg bool
 	authType   uint32
+
+	// reuseSlices is set by SetReuseSlices. bindReleased and parseReleased are set by Release.
+	reuseSlices   bool
+	bindReleased  bool
+	parseReleased bool
 }
 
 const (
@@ -136,13 +141,17 @@ func (b *Backend) ReceiveStartupMessage() (FrontendMessage, error) {
 
 	code := binary.BigEndian.Uint32(buf)
 
-	switch code {
-	case ProtocolVersionNumber:
+	// Any minor version of protocol version 3 is a startup message. The server responds with NegotiateProtocolVersion if
+	// it does not support the requested minor version.
+	if code>>16 == ProtocolVersionNumber>>16 {
 		err = b.startupMessage.Decode(buf)
 		if err != nil {
 			return nil, err
 		}
 		return &b.startupMessage, nil
+	}
+
+	switch code {
 	case sslRequestNumber:
 		err = b.sslRequest.Decode(buf)
 		if err != nil {
@@ -243,7 +252,16 @@ func (b *Backend) Receive() (FrontendMessage, error) {
 
 	b.partialMsg = false
 
-	err = msg.Decode(msgBody)
+	switch b.msgType {
+	case 'B':
+		err = b.bind.decode(msgBody, b.reuseSlices && b.bindReleased)
+		b.bindReleased = false
+	case 'P':
+		err = b.parse.decode(msgBody, b.reuseSlices && b.parseReleased)
+		b.parseReleased = false
+	default:
+		err = msg.Decode(msgBody)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -288,6 +306,31 @@ func (b *Backend) SetAuthType(authType uint32) error {
 	return nil
 }
 
+// SetReuseSlices sets whether Receive reuses the slices of Bind and Parse messages. By default, each received Bind and
+// Parse has newly allocated slices such as ParameterFormatCodes and ParameterOIDs. Reusing them avoids allocating for
+// each message, which matters for proxies relaying large numbers of messages.
+//
+// When enabled, the slices of a Bind or Parse returned by Receive are reused only after the message has been passed to
+// Release. A released message and its slices must not be used anymore.
+func (b *Backend) SetReuseSlices(reuse bool) {
+	b.reuseSlices = reuse
+}
+
+// Release declares that msg, which was returned by Receive, and its slices are no longer used. This allows Receive to
+// reuse its slices if SetReuseSlices is enabled. Release has no effect for other messages.
+func (b *Backend) Release(msg FrontendMessage) {
+	switch msg := msg.(type) {
+	case *Bind:
+		if msg == &b.bind {
+			b.bindReleased = true
+		}
+	case *Parse:
+		if msg == &b.parse {
+			b.parseReleased = true
+		}
+	}
+}
+
 // SetMaxBodyLen sets the maximum length of a message body in octets.
 // If a message body exceeds this length, Receive will return an error.
 // This is useful for protecting against malicious clients that send
@@ -0,0 +1,275 @@
+package pgproto3
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// ErrAuthenticationFailed is returned by the Authenticate functions when the client fails to authenticate. A fatal
+// ErrorResponse will already have been sent to the client.
+var ErrAuthenticationFailed = errors.New("password authentication failed")
+
+const scramSHA256IterationCount = 4096
+
+// AuthenticateTrust completes authentication without requiring a password by sending AuthenticationOk.
+//
+// After successful authentication a server typically sends ParameterStatus messages, BackendKeyData, and
+// ReadyForQuery.
+func AuthenticateTrust(b *Backend) error {
+	b.Send(&AuthenticationOk{})
+	return b.Flush()
+}
+
+// AuthenticateCleartextPassword requests a cleartext password from the client and calls verify with it. If verify
+// returns true then AuthenticationOk is sent. Otherwise, a fatal error is sent and ErrAuthenticationFailed is returned.
+func AuthenticateCleartextPassword(b *Backend, verify func(password string) bool) error {
+	b.Send(&AuthenticationCleartextPassword{})
+	err := b.Flush()
+	if err != nil {
+		return err
+	}
+
+	err = b.SetAuthType(AuthTypeCleartextPassword)
+	if err != nil {
+		return err
+	}
+
+	msg, err := receivePasswordMessage[*PasswordMessage](b)
+	if err != nil {
+		return err
+	}
+
+	if !verify(msg.Password) {
+		return failAuthentication(b)
+	}
+
+	return AuthenticateTrust(b)
+}
+
+// AuthenticateMD5Password authenticates the client using MD5 password authentication. user and password are the
+// expected credentials.
+func AuthenticateMD5Password(b *Backend, user, password string) error {
+	var salt [4]byte
+	_, err := rand.Read(salt[:])
+	if err != nil {
+		return err
+	}
+
+	b.Send(&AuthenticationMD5Password{Salt: salt})
+	err = b.Flush()
+	if err != nil {
+		return err
+	}
+
+	err = b.SetAuthType(AuthTypeMD5Password)
+	if err != nil {
+		return err
+	}
+
+	msg, err := receivePasswordMessage[*PasswordMessage](b)
+	if err != nil {
+		return err
+	}
+
+	expected := "md5" + hexMD5(hexMD5(password+user)+string(salt[:]))
+	if subtle.ConstantTimeCompare([]byte(msg.Password), []byte(expected)) != 1 {
+		return failAuthentication(b)
+	}
+
+	return AuthenticateTrust(b)
+}
+
+func hexMD5(s string) string {
+	hash := md5.Sum([]byte(s))
+	return hex.EncodeToString(hash[:])
+}
+
+// AuthenticateSCRAMSHA256 authenticates the client using SCRAM-SHA-256 with password as the expected password. Channel
+// binding is not supported.
+func AuthenticateSCRAMSHA256(b *Backend, password string) error {
+	b.Send(&AuthenticationSASL{AuthMechanisms: []string{"SCRAM-SHA-256"}})
+	err := b.Flush()
+	if err != nil {
+		return err
+	}
+
+	err = b.SetAuthType(AuthTypeSASL)
+	if err != nil {
+		return err
+	}
+
+	initialResponse, err := receivePasswordMessage[*SASLInitialResponse](b)
+	if err != nil {
+		return err
+	}
+	if initialResponse.AuthMechanism != "SCRAM-SHA-256" {
+		sendFatalError(b, "28000", fmt.Sprintf("unsupported SASL mechanism %q", initialResponse.AuthMechanism))
+		return fmt.Errorf("unsupported SASL mechanism %q", initialResponse.AuthMechanism)
+	}
+
+	gs2Header, clientFirstBare, clientNonce, err := parseSCRAMClientFirstMessage(initialResponse.Data)
+	if err != nil {
+		sendFatalError(b, "08P01", err.Error())
+		return err
+	}
+
+	salt := make([]byte, 16)
+	serverNonce := make([]byte, 18)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	if _, err := rand.Read(serverNonce); err != nil {
+		return err
+	}
+	nonce := clientNonce + base64.RawStdEncoding.EncodeToString(serverNonce)
+
+	serverFirst := fmt.Sprintf("r=%s,s=%s,i=%d", nonce, base64.StdEncoding.EncodeToString(salt), scramSHA256IterationCount)
+	b.Send(&AuthenticationSASLContinue{Data: []byte(serverFirst)})
+	err = b.Flush()
+	if err != nil {
+		return err
+	}
+
+	err = b.SetAuthType(AuthTypeSASLContinue)
+	if err != nil {
+		return err
+	}
+
+	response, err := receivePasswordMessage[*SASLResponse](b)
+	if err != nil {
+		return err
+	}
+
+	clientFinalWithoutProof, proof, err := parseSCRAMClientFinalMessage(response.Data, gs2Header, nonce)
+	if err != nil {
+		sendFatalError(b, "08P01", err.Error())
+		return err
+	}
+
+	saltedPassword := pbkdf2.Key([]byte(password), salt, scramSHA256IterationCount, 32, sha256.New)
+	authMessage := []byte(clientFirstBare + "," + serverFirst + "," + clientFinalWithoutProof)
+
+	clientKey := scramHMAC(saltedPassword, []byte("Client Key"))
+	storedKey := sha256.Sum256(clientKey)
+	clientSignature := scramHMAC(storedKey[:], authMessage)
+
+	recoveredClientKey := make([]byte, len(proof))
+	for i := range proof {
+		recoveredClientKey[i] = proof[i] ^ clientSignature[i]
+	}
+	recoveredStoredKey := sha256.Sum256(recoveredClientKey)
+	if subtle.ConstantTimeCompare(recoveredStoredKey[:], storedKey[:]) != 1 {
+		return failAuthentication(b)
+	}
+
+	serverKey := scramHMAC(saltedPassword, []byte("Server Key"))
+	serverSignature := scramHMAC(serverKey, authMessage)
+	b.Send(&AuthenticationSASLFinal{Data: []byte("v=" + base64.StdEncoding.EncodeToString(serverSignature))})
+
+	return AuthenticateTrust(b)
+}
+
+// parseSCRAMClientFirstMessage parses a SCRAM client-first-message. It returns the GS2 header, the client-first-message-bare,
+// and the client nonce.
+func parseSCRAMClientFirstMessage(msg []byte) (gs2Header, clientFirstBare, clientNonce string, err error) {
+	// gs2-header is the channel binding flag and the optional authzid each followed by a comma.
+	parts := bytes.SplitN(msg, []byte(","), 3)
+	if len(parts) != 3 {
+		return "", "", "", errors.New("invalid SCRAM client-first-message")
+	}
+
+	switch {
+	case bytes.Equal(parts[0], []byte("n")), bytes.Equal(parts[0], []byte("y")):
+	case bytes.HasPrefix(parts[0], []byte("p=")):
+		return "", "", "", errors.New("SCRAM channel binding is not supported")
+	default:
+		return "", "", "", errors.New("invalid SCRAM client-first-message")
+	}
+
+	gs2Header = string(parts[0]) + "," + string(parts[1]) + ","
+	clientFirstBare = string(parts[2])
+
+	for _, attr := range bytes.Split(parts[2], []byte(",")) {
+		if bytes.HasPrefix(attr, []byte("r=")) {
+			clientNonce = string(attr[2:])
+		}
+	}
+	if clientNonce == "" {
+		return "", "", "", errors.New("SCRAM client-first-message is missing nonce")
+	}
+
+	return gs2Header, clientFirstBare, clientNonce, nil
+}
+
+// parseSCRAMClientFinalMessage parses a SCRAM client-final-message and validates its channel binding and nonce. It
+// returns the client-final-message-without-proof and the decoded proof.
+func parseSCRAMClientFinalMessage(msg []byte, gs2Header, nonce string) (string, []byte, error) {
+	idx := bytes.LastIndex(msg, []byte(",p="))
+	if idx < 0 {
+		return "", nil, errors.New("SCRAM client-final-message is missing proof")
+	}
+
+	withoutProof := msg[:idx]
+	proof, err := base64.StdEncoding.DecodeString(string(msg[idx+3:]))
+	if err != nil || len(proof) != sha256.Size {
+		return "", nil, errors.New("invalid SCRAM client proof")
+	}
+
+	var channelBinding, finalNonce []byte
+	for _, attr := range bytes.Split(withoutProof, []byte(",")) {
+		switch {
+		case bytes.HasPrefix(attr, []byte("c=")):
+			channelBinding = attr[2:]
+		case bytes.HasPrefix(attr, []byte("r=")):
+			finalNonce = attr[2:]
+		}
+	}
+
+	if string(channelBinding) != base64.StdEncoding.EncodeToString([]byte(gs2Header)) {
+		return "", nil, errors.New("invalid SCRAM channel binding")
+	}
+	if string(finalNonce) != nonce {
+		return "", nil, errors.New("invalid SCRAM nonce")
+	}
+
+	return string(withoutProof), proof, nil
+}
+
+func scramHMAC(key, msg []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(msg)
+	return mac.Sum(nil)
+}
+
+// receivePasswordMessage receives the client's response to an authentication request.
+func receivePasswordMessage[T FrontendMessage](b *Backend) (T, error) {
+	var zero T
+
+	msg, err := b.Receive()
+	if err != nil {
+		return zero, fmt.Errorf("receive authentication response: %w", err)
+	}
+
+	response, ok := msg.(T)
+	if !ok {
+		sendFatalError(b, "08P01", fmt.Sprintf("expected authentication response, got %T", msg))
+		return zero, fmt.Errorf("expected authentication response, got %T", msg)
+	}
+
+	return response, nil
+}
+
+func failAuthentication(b *Backend) error {
+	sendFatalError(b, "28P01", ErrAuthenticationFailed.Error())
+	return ErrAuthenticationFailed
+}
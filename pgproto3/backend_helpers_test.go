@@ -0,0 +1,168 @@
+package pgproto3_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveOne accepts a single connection on a new listener and runs handle with it. It returns a connection string for
+// the listener and a channel that receives the result of handle.
+func serveOne(t *testing.T, handle func(conn net.Conn) error) (string, <-chan error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	errChan := make(chan error, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			errChan <- err
+			return
+		}
+		defer conn.Close()
+
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		errChan <- handle(conn)
+	}()
+
+	host, port, _ := strings.Cut(ln.Addr().String(), ":")
+	return fmt.Sprintf("sslmode=disable host=%s port=%s user=alice password=secret", host, port), errChan
+}
+
+func TestBackendAuthentication(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name         string
+		authenticate func(b *pgproto3.Backend) error
+	}{
+		{"trust", pgproto3.AuthenticateTrust},
+		{"cleartext", func(b *pgproto3.Backend) error {
+			return pgproto3.AuthenticateCleartextPassword(b, func(password string) bool { return password == "secret" })
+		}},
+		{"md5", func(b *pgproto3.Backend) error { return pgproto3.AuthenticateMD5Password(b, "alice", "secret") }},
+		{"scram-sha-256", func(b *pgproto3.Backend) error { return pgproto3.AuthenticateSCRAMSHA256(b, "secret") }},
+	} {
+		for _, password := range []string{"secret", "wrong"} {
+			t.Run(tt.name+"/"+password, func(t *testing.T) {
+				connString, serverErrChan := serveOne(t, func(conn net.Conn) error {
+					startup, err := pgproto3.NegotiateStartup(conn, nil)
+					if err != nil {
+						return err
+					}
+					if startup.StartupMessage.Parameters["user"] != "alice" {
+						return fmt.Errorf("unexpected user %q", startup.StartupMessage.Parameters["user"])
+					}
+
+					err = tt.authenticate(startup.Backend)
+					if err != nil {
+						return err
+					}
+
+					startup.Backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+					return startup.Backend.Flush()
+				})
+
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				connString = strings.Replace(connString, "password=secret", "password="+password, 1)
+				conn, err := pgconn.Connect(ctx, connString)
+				serverErr := <-serverErrChan
+
+				if password == "secret" || tt.name == "trust" {
+					require.NoError(t, err)
+					require.NoError(t, serverErr)
+					conn.Close(ctx)
+				} else {
+					require.Error(t, err)
+					require.ErrorIs(t, serverErr, pgproto3.ErrAuthenticationFailed)
+					var pgErr *pgconn.PgError
+					require.ErrorAs(t, err, &pgErr)
+					assert.Equal(t, "28P01", pgErr.Code)
+				}
+			})
+		}
+	}
+}
+
+func TestRelay(t *testing.T) {
+	t.Parallel()
+
+	upstreamConn, proxyUpstreamConn := net.Pipe()
+	defer upstreamConn.Close()
+	defer proxyUpstreamConn.Close()
+
+	// The upstream server answers every query with an empty result.
+	upstreamErrChan := make(chan error, 1)
+	go func() {
+		b := pgproto3.NewBackend(upstreamConn, upstreamConn)
+		for {
+			msg, err := b.Receive()
+			if err != nil {
+				upstreamErrChan <- err
+				return
+			}
+
+			switch msg := msg.(type) {
+			case *pgproto3.Query:
+				b.Send(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 0")})
+				b.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+				err = b.Flush()
+				if err != nil {
+					upstreamErrChan <- err
+					return
+				}
+			case *pgproto3.Terminate:
+				upstreamErrChan <- nil
+				return
+			default:
+				upstreamErrChan <- fmt.Errorf("unexpected message %T", msg)
+				return
+			}
+		}
+	}()
+
+	connString, proxyErrChan := serveOne(t, func(conn net.Conn) error {
+		startup, err := pgproto3.NegotiateStartup(conn, nil)
+		if err != nil {
+			return err
+		}
+
+		err = pgproto3.AuthenticateTrust(startup.Backend)
+		if err != nil {
+			return err
+		}
+		startup.Backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+		err = startup.Backend.Flush()
+		if err != nil {
+			return err
+		}
+
+		return pgproto3.Relay(startup.Backend, pgproto3.NewFrontend(proxyUpstreamConn, proxyUpstreamConn))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := pgconn.Connect(ctx, connString)
+	require.NoError(t, err)
+
+	results, err := conn.Exec(ctx, "select 1 where false").ReadAll()
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "SELECT 0", results[0].CommandTag.String())
+
+	require.NoError(t, conn.Close(ctx))
+	require.NoError(t, <-proxyErrChan)
+	require.NoError(t, <-upstreamErrChan)
+}
@@ -10,6 +10,10 @@ import (
 type BackendKeyData struct {
 	ProcessID uint32
 	SecretKey uint32
+
+	// ExtendedSecretKey is the secret key if it is not 4 bytes long. Protocol version 3.2 allows secret keys of up to 256
+	// bytes. SecretKey is ignored when ExtendedSecretKey is not nil.
+	ExtendedSecretKey []byte
 }
 
 // Backend identifies this message as sendable by the PostgreSQL backend.
@@ -18,12 +22,19 @@ func (*BackendKeyData) Backend() {}
 // Decode decodes src into dst. src must contain the complete message with the exception of the initial 1 byte message
 // type identifier and 4 byte message length.
 func (dst *BackendKeyData) Decode(src []byte) error {
-	if len(src) != 8 {
+	if len(src) < 8 || len(src) > 4+maxSecretKeyLen {
 		return &invalidMessageLenErr{messageType: "BackendKeyData", expectedLen: 8, actualLen: len(src)}
 	}
 
 	dst.ProcessID = binary.BigEndian.Uint32(src[:4])
-	dst.SecretKey = binary.BigEndian.Uint32(src[4:])
+	if len(src) == 8 {
+		dst.SecretKey = binary.BigEndian.Uint32(src[4:])
+		dst.ExtendedSecretKey = nil
+	} else {
+		dst.SecretKey = 0
+		dst.ExtendedSecretKey = make([]byte, len(src)-4)
+		copy(dst.ExtendedSecretKey, src[4:])
+	}
 
 	return nil
 }
@@ -32,19 +43,25 @@ func (dst *BackendKeyData) Decode(src []byte) error {
 func (src *BackendKeyData) Encode(dst []byte) ([]byte, error) {
 	dst, sp := beginMessage(dst, 'K')
 	dst = pgio.AppendUint32(dst, src.ProcessID)
-	dst = pgio.AppendUint32(dst, src.SecretKey)
+	if src.ExtendedSecretKey != nil {
+		dst = append(dst, src.ExtendedSecretKey...)
+	} else {
+		dst = pgio.AppendUint32(dst, src.SecretKey)
+	}
 	return finishMessage(dst, sp)
 }
 
 // MarshalJSON implements encoding/json.Marshaler.
 func (src BackendKeyData) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
-		Type      string
-		ProcessID uint32
-		SecretKey uint32
+		Type              string
+		ProcessID         uint32
+		SecretKey         uint32
+		ExtendedSecretKey []byte `json:",omitempty"`
 	}{
-		Type:      "BackendKeyData",
-		ProcessID: src.ProcessID,
-		SecretKey: src.SecretKey,
+		Type:              "BackendKeyData",
+		ProcessID:         src.ProcessID,
+		SecretKey:         src.SecretKey,
+		ExtendedSecretKey: src.ExtendedSecretKey,
 	})
 }
@@ -0,0 +1,111 @@
+package pgproto3
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// StartupConfig configures NegotiateStartup.
+type StartupConfig struct {
+	// TLSConfig is used to accept an SSLRequest. If nil, SSLRequests are declined and the client may continue without
+	// encryption.
+	TLSConfig *tls.Config
+
+	// RequireTLS causes a StartupMessage received on an unencrypted connection to be rejected. It requires TLSConfig.
+	RequireTLS bool
+}
+
+// Startup is the result of NegotiateStartup.
+type Startup struct {
+	// Conn is the connection to the client. It is a *tls.Conn if TLS was negotiated.
+	Conn net.Conn
+
+	// Backend reads from and writes to Conn.
+	Backend *Backend
+
+	// StartupMessage is the client's startup message. It is nil if the client sent a CancelRequest.
+	StartupMessage *StartupMessage
+
+	// CancelRequest is the client's cancel request. It is nil if the client sent a StartupMessage.
+	CancelRequest *CancelRequest
+}
+
+// NegotiateStartup handles the startup phase of a connection from a client. It responds to SSLRequest and
+// GSSEncRequest messages, upgrading conn to TLS when config.TLSConfig is set, until the client sends a StartupMessage
+// or CancelRequest. GSS encryption is always declined.
+//
+// When a StartupMessage is received the next step is authentication. See AuthenticateTrust,
+// AuthenticateCleartextPassword, AuthenticateMD5Password, and AuthenticateSCRAMSHA256.
+func NegotiateStartup(conn net.Conn, config *StartupConfig) (*Startup, error) {
+	if config == nil {
+		config = &StartupConfig{}
+	}
+	if config.RequireTLS && config.TLSConfig == nil {
+		return nil, errors.New("RequireTLS requires TLSConfig")
+	}
+
+	startup := &Startup{Conn: conn, Backend: NewBackend(conn, conn)}
+	encrypted := false
+
+	for {
+		msg, err := startup.Backend.ReceiveStartupMessage()
+		if err != nil {
+			return nil, fmt.Errorf("receive startup message: %w", err)
+		}
+
+		switch msg := msg.(type) {
+		case *StartupMessage:
+			if config.RequireTLS && !encrypted {
+				sendFatalError(startup.Backend, "28000", "SSL connection is required")
+				return nil, errors.New("client did not request TLS")
+			}
+			startup.StartupMessage = msg
+			return startup, nil
+
+		case *CancelRequest:
+			startup.CancelRequest = msg
+			return startup, nil
+
+		case *SSLRequest:
+			if encrypted || config.TLSConfig == nil {
+				_, err = startup.Conn.Write([]byte{'N'})
+				if err != nil {
+					return nil, fmt.Errorf("decline SSL request: %w", err)
+				}
+				continue
+			}
+
+			_, err = startup.Conn.Write([]byte{'S'})
+			if err != nil {
+				return nil, fmt.Errorf("accept SSL request: %w", err)
+			}
+
+			tlsConn := tls.Server(startup.Conn, config.TLSConfig)
+			err = tlsConn.Handshake()
+			if err != nil {
+				return nil, fmt.Errorf("TLS handshake: %w", err)
+			}
+			startup.Conn = tlsConn
+			startup.Backend = NewBackend(tlsConn, tlsConn)
+			encrypted = true
+
+		case *GSSEncRequest:
+			_, err = startup.Conn.Write([]byte{'N'})
+			if err != nil {
+				return nil, fmt.Errorf("decline GSS encryption request: %w", err)
+			}
+
+		default:
+			return nil, fmt.Errorf("unexpected startup message: %T", msg)
+		}
+	}
+}
+
+// sendFatalError sends a fatal ErrorResponse to the client. Errors are ignored as the connection is about to be
+// closed.
+func sendFatalError(b *Backend, code, message string) {
+	b.Send(&ErrorResponse{Severity: "FATAL", SeverityUnlocalized: "FATAL", Code: code, Message: message})
+	b.Flush()
+}
@@ -1,6 +1,7 @@
 package pgproto3_test
 
 import (
+	"bytes"
 	"io"
 	"testing"
 
@@ -84,6 +85,26 @@ func TestStartupMessage(t *testing.T) {
 		require.Equal(t, want, msg)
 	})
 
+	t.Run("protocol version 3.2", func(t *testing.T) {
+		want := &pgproto3.StartupMessage{
+			ProtocolVersion: pgproto3.ProtocolVersion32,
+			Parameters: map[string]string{
+				"username": "tester",
+			},
+		}
+		dst, err := want.Encode([]byte{})
+		require.NoError(t, err)
+
+		server := &interruptReader{}
+		server.push(dst)
+
+		backend := pgproto3.NewBackend(server, nil)
+
+		msg, err := backend.ReceiveStartupMessage()
+		require.NoError(t, err)
+		require.Equal(t, want, msg)
+	})
+
 	t.Run("invalid packet length", func(t *testing.T) {
 		wantErr := "invalid length of startup packet"
 		tests := []struct {
@@ -138,3 +159,103 @@ func TestBackendReceiveExceededMaxBodyLen(t *testing.T) {
 	var invalidBodyLenErr *pgproto3.ExceededMaxBodyLenErr
 	assert.ErrorAs(t, err, &invalidBodyLenErr)
 }
+
+func TestBackendReceiveExtendedCancelRequest(t *testing.T) {
+	t.Parallel()
+
+	for _, want := range []*pgproto3.CancelRequest{
+		{ProcessID: 1, SecretKey: 2},
+		{ProcessID: 1, ExtendedSecretKey: []byte("0123456789abcdef0123456789abcdef")},
+	} {
+		dst, err := want.Encode(nil)
+		require.NoError(t, err)
+
+		server := &interruptReader{}
+		server.push(dst)
+
+		backend := pgproto3.NewBackend(server, nil)
+
+		msg, err := backend.ReceiveStartupMessage()
+		require.NoError(t, err)
+		require.Equal(t, want, msg)
+	}
+}
+
+func TestBackendReceiveReusesMessages(t *testing.T) {
+	const iterations = 200
+
+	var buf []byte
+	var err error
+	for i := 0; i < iterations+1; i++ {
+		buf, err = (&pgproto3.Bind{
+			ParameterFormatCodes: []int16{1, 0},
+			Parameters:           [][]byte{{0, 0, 0, byte(i)}, nil},
+			ResultFormatCodes:    []int16{1},
+		}).Encode(buf)
+		require.NoError(t, err)
+		buf, err = (&pgproto3.Execute{}).Encode(buf)
+		require.NoError(t, err)
+		buf, err = (&pgproto3.Sync{}).Encode(buf)
+		require.NoError(t, err)
+	}
+
+	backend := pgproto3.NewBackend(bytes.NewReader(buf), nil)
+	backend.SetReuseSlices(true)
+
+	receiveBindExecuteSync := func() {
+		msg, err := backend.Receive()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bind, ok := msg.(*pgproto3.Bind); !ok || len(bind.Parameters) != 2 || bind.Parameters[1] != nil {
+			t.Fatalf("unexpected message: %#v", msg)
+		}
+		backend.Release(msg)
+
+		msg, err = backend.Receive()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := msg.(*pgproto3.Execute); !ok {
+			t.Fatalf("unexpected message: %#v", msg)
+		}
+
+		msg, err = backend.Receive()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := msg.(*pgproto3.Sync); !ok {
+			t.Fatalf("unexpected message: %#v", msg)
+		}
+	}
+
+	// The first messages allocate the slices that are reused by later messages.
+	receiveBindExecuteSync()
+
+	allocs := testing.AllocsPerRun(iterations-1, receiveBindExecuteSync)
+	require.Zero(t, allocs)
+}
+
+func TestBackendReceiveDoesNotReuseUnreleasedSlices(t *testing.T) {
+	var buf []byte
+	var err error
+	for i := 0; i < 2; i++ {
+		buf, err = (&pgproto3.Parse{Name: "ps", Query: "select $1", ParameterOIDs: []uint32{uint32(i)}}).Encode(buf)
+		require.NoError(t, err)
+	}
+
+	for _, reuseSlices := range []bool{false, true} {
+		backend := pgproto3.NewBackend(bytes.NewReader(buf), nil)
+		backend.SetReuseSlices(reuseSlices)
+
+		msg, err := backend.Receive()
+		require.NoError(t, err)
+		parameterOIDs := msg.(*pgproto3.Parse).ParameterOIDs
+
+		// The first message is not released so its slices must not be reused.
+		msg, err = backend.Receive()
+		require.NoError(t, err)
+		require.Equal(t, []uint32{1}, msg.(*pgproto3.Parse).ParameterOIDs)
+		require.Equal(t, []uint32{0}, parameterOIDs)
+	}
+}
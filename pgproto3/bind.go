@@ -26,6 +26,16 @@ func (*Bind) Frontend() {}
 // Decode decodes src into dst. src must contain the complete message with the exception of the initial 1 byte message
 // type identifier and 4 byte message length.
 func (dst *Bind) Decode(src []byte) error {
+	return dst.decode(src, false)
+}
+
+// decode decodes src into dst like Decode. If reuseSlices is true, the slices of dst are reused if possible.
+func (dst *Bind) decode(src []byte, reuseSlices bool) error {
+	var parameterFormatCodes, resultFormatCodes []int16
+	var parameters [][]byte
+	if reuseSlices {
+		parameterFormatCodes, parameters, resultFormatCodes = dst.ParameterFormatCodes, dst.Parameters, dst.ResultFormatCodes
+	}
 	*dst = Bind{}
 
 	idx := bytes.IndexByte(src, 0)
@@ -49,7 +59,7 @@ func (dst *Bind) Decode(src []byte) error {
 	rp += 2
 
 	if parameterFormatCodeCount > 0 {
-		dst.ParameterFormatCodes = make([]int16, parameterFormatCodeCount)
+		dst.ParameterFormatCodes = reuseSlice(parameterFormatCodes, parameterFormatCodeCount)
 
 		if len(src[rp:]) < len(dst.ParameterFormatCodes)*2 {
 			return &invalidMessageFormatErr{messageType: "Bind"}
@@ -67,7 +77,7 @@ func (dst *Bind) Decode(src []byte) error {
 	rp += 2
 
 	if parameterCount > 0 {
-		dst.Parameters = make([][]byte, parameterCount)
+		dst.Parameters = reuseSlice(parameters, parameterCount)
 
 		for i := 0; i < parameterCount; i++ {
 			if len(src[rp:]) < 4 {
@@ -79,6 +89,7 @@ func (dst *Bind) Decode(src []byte) error {
 
 			// null
 			if msgSize == -1 {
+				dst.Parameters[i] = nil
 				continue
 			}
 
@@ -97,7 +108,7 @@ func (dst *Bind) Decode(src []byte) error {
 	resultFormatCodeCount := int(binary.BigEndian.Uint16(src[rp:]))
 	rp += 2
 
-	dst.ResultFormatCodes = make([]int16, resultFormatCodeCount)
+	dst.ResultFormatCodes = reuseSlice(resultFormatCodes, resultFormatCodeCount)
 	if len(src[rp:]) < len(dst.ResultFormatCodes)*2 {
 		return &invalidMessageFormatErr{messageType: "Bind"}
 	}
@@ -111,7 +122,15 @@ func (dst *Bind) Decode(src []byte) error {
 
 // Encode encodes src into dst. dst will include the 1 byte message type identifier and the 4 byte message length.
 func (src *Bind) Encode(dst []byte) ([]byte, error) {
+	dst, _, err := src.encode(dst, 0, nil)
+	return dst, err
+}
+
+// encode encodes src into dst like Encode. If threshold is greater than 0, parameter values of at least threshold bytes
+// are appended to segs instead of being copied into dst.
+func (src *Bind) encode(dst []byte, threshold int, segs []writeSegment) ([]byte, []writeSegment, error) {
 	dst, sp := beginMessage(dst, 'B')
+	externalLen := 0
 
 	dst = append(dst, src.DestinationPortal...)
 	dst = append(dst, 0)
@@ -119,7 +138,7 @@ func (src *Bind) Encode(dst []byte) ([]byte, error) {
 	dst = append(dst, 0)
 
 	if len(src.ParameterFormatCodes) > math.MaxUint16 {
-		return nil, errors.New("too many parameter format codes")
+		return nil, nil, errors.New("too many parameter format codes")
 	}
 	dst = pgio.AppendUint16(dst, uint16(len(src.ParameterFormatCodes)))
 	for _, fc := range src.ParameterFormatCodes {
@@ -127,7 +146,7 @@ func (src *Bind) Encode(dst []byte) ([]byte, error) {
 	}
 
 	if len(src.Parameters) > math.MaxUint16 {
-		return nil, errors.New("too many parameters")
+		return nil, nil, errors.New("too many parameters")
 	}
 	dst = pgio.AppendUint16(dst, uint16(len(src.Parameters)))
 	for _, p := range src.Parameters {
@@ -137,18 +156,24 @@ func (src *Bind) Encode(dst []byte) ([]byte, error) {
 		}
 
 		dst = pgio.AppendInt32(dst, int32(len(p)))
-		dst = append(dst, p...)
+		if threshold > 0 && len(p) >= threshold {
+			segs = append(segs, writeSegment{off: len(dst), data: p})
+			externalLen += len(p)
+		} else {
+			dst = append(dst, p...)
+		}
 	}
 
 	if len(src.ResultFormatCodes) > math.MaxUint16 {
-		return nil, errors.New("too many result format codes")
+		return nil, nil, errors.New("too many result format codes")
 	}
 	dst = pgio.AppendUint16(dst, uint16(len(src.ResultFormatCodes)))
 	for _, fc := range src.ResultFormatCodes {
 		dst = pgio.AppendInt16(dst, fc)
 	}
 
-	return finishMessage(dst, sp)
+	dst, err := finishVectoredMessage(dst, sp, externalLen)
+	return dst, segs, err
 }
 
 // MarshalJSON implements encoding/json.Marshaler.
@@ -10,16 +10,24 @@ import (
 
 const cancelRequestCode = 80877102
 
+// maxSecretKeyLen is the maximum length of a secret key for cancel requests. Protocol version 3.2 allows secret keys
+// of up to 256 bytes.
+const maxSecretKeyLen = 256
+
 type CancelRequest struct {
 	ProcessID uint32
 	SecretKey uint32
+
+	// ExtendedSecretKey is the secret key if it is not 4 bytes long. See BackendKeyData.ExtendedSecretKey. SecretKey is
+	// ignored when ExtendedSecretKey is not nil.
+	ExtendedSecretKey []byte
 }
 
 // Frontend identifies this message as sendable by a PostgreSQL frontend.
 func (*CancelRequest) Frontend() {}
 
 func (dst *CancelRequest) Decode(src []byte) error {
-	if len(src) != 12 {
+	if len(src) < 12 || len(src) > 8+maxSecretKeyLen {
 		return errors.New("bad cancel request size")
 	}
 
@@ -30,13 +38,28 @@ func (dst *CancelRequest) Decode(src []byte) error {
 	}
 
 	dst.ProcessID = binary.BigEndian.Uint32(src[4:])
-	dst.SecretKey = binary.BigEndian.Uint32(src[8:])
+	if len(src) == 12 {
+		dst.SecretKey = binary.BigEndian.Uint32(src[8:])
+		dst.ExtendedSecretKey = nil
+	} else {
+		dst.SecretKey = 0
+		dst.ExtendedSecretKey = make([]byte, len(src)-8)
+		copy(dst.ExtendedSecretKey, src[8:])
+	}
 
 	return nil
 }
 
 // Encode encodes src into dst. dst will include the 4 byte message length.
 func (src *CancelRequest) Encode(dst []byte) ([]byte, error) {
+	if src.ExtendedSecretKey != nil {
+		dst = pgio.AppendInt32(dst, int32(12+len(src.ExtendedSecretKey)))
+		dst = pgio.AppendInt32(dst, cancelRequestCode)
+		dst = pgio.AppendUint32(dst, src.ProcessID)
+		dst = append(dst, src.ExtendedSecretKey...)
+		return dst, nil
+	}
+
 	dst = pgio.AppendInt32(dst, 16)
 	dst = pgio.AppendInt32(dst, cancelRequestCode)
 	dst = pgio.AppendUint32(dst, src.ProcessID)
@@ -47,12 +70,14 @@ func (src *CancelRequest) Encode(dst []byte) ([]byte, error) {
 // MarshalJSON implements encoding/json.Marshaler.
 func (src CancelRequest) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
-		Type      string
-		ProcessID uint32
-		SecretKey uint32
+		Type              string
+		ProcessID         uint32
+		SecretKey         uint32
+		ExtendedSecretKey []byte `json:",omitempty"`
 	}{
-		Type:      "CancelRequest",
-		ProcessID: src.ProcessID,
-		SecretKey: src.SecretKey,
+		Type:              "CancelRequest",
+		ProcessID:         src.ProcessID,
+		SecretKey:         src.SecretKey,
+		ExtendedSecretKey: src.ExtendedSecretKey,
 	})
 }
@@ -7,5 +7,15 @@
 // The Trace method of Frontend and Backend can be used to examine the wire-level message traffic. It outputs in a
 // similar format to the PQtrace function in libpq.
 //
+// Servers and proxies can use NegotiateStartup to handle SSL negotiation and the startup message, AuthenticateTrust,
+// AuthenticateCleartextPassword, AuthenticateMD5Password, or AuthenticateSCRAMSHA256 to authenticate the client, and
+// Relay to relay messages between a client and a server.
+//
+// Frontend and Backend reuse the message structs returned by Receive, so a received message is only valid until the next
+// call to Receive. Some fields of a received message, e.g. the Parameters of Bind and the Values of DataRow, reference
+// the receive buffer rather than copying it. A message that must be retained longer must be copied, e.g. by encoding it
+// with Encode and decoding the result into a new message struct. Backend can also reuse the slices of received Bind and
+// Parse messages to avoid allocating. This must be enabled explicitly. See Backend.SetReuseSlices.
+//
 // See https://www.postgresql.org/docs/current/protocol-message-formats.html for meanings of the different messages.
 package pgproto3
@@ -19,18 +19,17 @@ func (*Execute) Frontend() {}
 // Decode decodes src into dst. src must contain the complete message with the exception of the initial 1 byte message
 // type identifier and 4 byte message length.
 func (dst *Execute) Decode(src []byte) error {
-	buf := bytes.NewBuffer(src)
-
-	b, err := buf.ReadBytes(0)
-	if err != nil {
-		return err
+	idx := bytes.IndexByte(src, 0)
+	if idx < 0 {
+		return &invalidMessageFormatErr{messageType: "Execute"}
 	}
-	dst.Portal = string(b[:len(b)-1])
+	dst.Portal = string(src[:idx])
+	rp := idx + 1
 
-	if buf.Len() < 4 {
+	if len(src[rp:]) < 4 {
 		return &invalidMessageFormatErr{messageType: "Execute"}
 	}
-	dst.MaxRows = binary.BigEndian.Uint32(buf.Next(4))
+	dst.MaxRows = binary.BigEndian.Uint32(src[rp:])
 
 	return nil
 }
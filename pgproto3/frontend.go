@@ -6,8 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 )
 
+// defaultWriteBufferSize is the default maximum capacity of the write buffer that is kept after a flush.
+const defaultWriteBufferSize = 1024
+
 // Frontend acts as a client for the PostgreSQL wire protocol version 3.
 type Frontend struct {
 	cr *chunkReader
@@ -18,9 +22,27 @@ type Frontend struct {
 	// idle. Setting and unsetting tracer provides equivalent functionality to PQtrace and PQuntrace in libpq.
 	tracer *tracer
 
+	// observer is called with the sender, type, and encoded length of each message sent or received. See
+	// SetMessageObserver.
+	observer func(sender, msgType byte, encodedLen int32)
+
 	wbuf        []byte
 	encodeError error
 
+	// wbufPartiallyWritten is true when the start of the messages in wbuf was written by a FlushResumable that failed.
+	wbufPartiallyWritten bool
+
+	// wbufSize is the maximum capacity of wbuf that is kept after a flush. See SetWriteBufferSize.
+	wbufSize int
+
+	// vectoredWriteThreshold is the minimum length of a value that SendBindVectored and SendCopyDataVectored write
+	// without copying it into wbuf. Vectored writes are disabled if it is 0. See SetVectoredWriteThreshold.
+	vectoredWriteThreshold int
+
+	// wsegs are the values that are written between the bytes of wbuf on the next flush.
+	wsegs []writeSegment
+	wvec  net.Buffers
+
 	// Backend message flyweights
 	authenticationOk                AuthenticationOk
 	authenticationCleartextPassword AuthenticationCleartextPassword
@@ -31,6 +53,7 @@ type Frontend struct {
 	authenticationSASLContinue      AuthenticationSASLContinue
 	authenticationSASLFinal         AuthenticationSASLFinal
 	backendKeyData                  BackendKeyData
+	negotiateProtocolVersion        NegotiateProtocolVersion
 	bindComplete                    BindComplete
 	closeComplete                   CloseComplete
 	commandComplete                 CommandComplete
@@ -52,18 +75,29 @@ type Frontend struct {
 	readyForQuery                   ReadyForQuery
 	rowDescription                  RowDescription
 	portalSuspended                 PortalSuspended
+	unknownMessage                  UnknownMessage
 
 	bodyLen    int
 	maxBodyLen int // maxBodyLen is the maximum length of a message body in octets. If a message body exceeds this length, Receive will return an error.
 	msgType    byte
 	partialMsg bool
 	authType   uint32
+
+	// passthroughUnknownMessages causes Receive to return messages with unrecognized types as *UnknownMessage. See
+	// SetPassthroughUnknownMessages.
+	passthroughUnknownMessages bool
 }
 
 // NewFrontend creates a new Frontend.
 func NewFrontend(r io.Reader, w io.Writer) *Frontend {
 	cr := newChunkReader(r, 0)
-	return &Frontend{cr: cr, w: w}
+	return &Frontend{cr: cr, w: w, wbufSize: defaultWriteBufferSize}
+}
+
+// writeSegment is a value that is written after wbuf[:off] and before the rest of wbuf without being copied into wbuf.
+type writeSegment struct {
+	off  int
+	data []byte
 }
 
 // Send sends a message to the backend (i.e. the server). The message is buffered until Flush is called. Any error
@@ -89,34 +123,164 @@ func (f *Frontend) Send(msg FrontendMessage) {
 	if f.tracer != nil {
 		f.tracer.traceMessage('F', int32(len(f.wbuf)-prevLen), msg)
 	}
+	if f.observer != nil {
+		var msgType byte
+		switch msg.(type) {
+		case *StartupMessage, *SSLRequest, *GSSEncRequest, *CancelRequest:
+			// These messages do not have a type byte.
+		default:
+			msgType = f.wbuf[prevLen]
+		}
+		f.observer('F', msgType, int32(len(f.wbuf)-prevLen))
+	}
 }
 
 // Flush writes any pending messages to the backend (i.e. the server).
 func (f *Frontend) Flush() error {
 	if err := f.encodeError; err != nil {
 		f.encodeError = nil
-		f.wbuf = f.wbuf[:0]
-		return &writeError{err: err, safeToRetry: true}
+		safeToRetry := !f.wbufPartiallyWritten
+		f.resetWriteBuffer()
+		return &writeError{err: err, safeToRetry: safeToRetry}
 	}
 
-	if len(f.wbuf) == 0 {
+	if len(f.wbuf) == 0 && len(f.wsegs) == 0 {
 		return nil
 	}
 
+	n, err := f.write()
+	safeToRetry := n == 0 && !f.wbufPartiallyWritten
+	f.resetWriteBuffer()
+
+	if err != nil {
+		return &writeError{err: err, safeToRetry: safeToRetry}
+	}
+
+	return nil
+}
+
+// FlushResumable writes the enqueued messages to the backend like Flush. But if the write fails, the part of the
+// messages that was not written is kept instead of discarded. A subsequent call to Flush or FlushResumable writes it
+// before any messages sent after the failure. This allows resuming a write that was interrupted by a transient error
+// such as a deadline on the underlying net.Conn.
+func (f *Frontend) FlushResumable() error {
+	if err := f.encodeError; err != nil {
+		f.encodeError = nil
+		safeToRetry := !f.wbufPartiallyWritten
+		f.resetWriteBuffer()
+		return &writeError{err: err, safeToRetry: safeToRetry}
+	}
+
+	if len(f.wbuf) == 0 && len(f.wsegs) == 0 {
+		return nil
+	}
+
+	if len(f.wsegs) > 0 {
+		return f.flushVectoredResumable()
+	}
+
 	n, err := f.w.Write(f.wbuf)
+	if err != nil {
+		safeToRetry := n == 0 && !f.wbufPartiallyWritten
+		f.wbuf = f.wbuf[:copy(f.wbuf, f.wbuf[n:])]
+		if n > 0 {
+			f.wbufPartiallyWritten = true
+		}
+		return &writeError{err: err, safeToRetry: safeToRetry}
+	}
+
+	f.resetWriteBuffer()
+
+	return nil
+}
+
+// flushVectoredResumable is FlushResumable when there are wsegs. If the write fails the bytes that were not written
+// are copied into wbuf so the caller may reuse the values that were referenced by wsegs.
+func (f *Frontend) flushVectoredResumable() error {
+	n, unwritten, err := f.writeVectored()
+	if err != nil {
+		safeToRetry := n == 0 && !f.wbufPartiallyWritten
+		var remaining []byte
+		for _, b := range unwritten {
+			remaining = append(remaining, b...)
+		}
+		f.resetWriteBuffer()
+		f.wbuf = append(f.wbuf, remaining...)
+		if n > 0 {
+			f.wbufPartiallyWritten = true
+		}
+		return &writeError{err: err, safeToRetry: safeToRetry}
+	}
+
+	f.resetWriteBuffer()
+
+	return nil
+}
+
+// write writes wbuf and wsegs to f.w.
+func (f *Frontend) write() (int64, error) {
+	if len(f.wsegs) == 0 {
+		n, err := f.w.Write(f.wbuf)
+		return int64(n), err
+	}
+
+	n, _, err := f.writeVectored()
+	return n, err
+}
+
+// writeVectored writes wbuf and wsegs to f.w with net.Buffers which uses a single vectored write if f.w supports it.
+// unwritten is the part of the buffers that was not written.
+func (f *Frontend) writeVectored() (n int64, unwritten net.Buffers, err error) {
+	f.wvec = f.wvec[:0]
+	start := 0
+	for _, seg := range f.wsegs {
+		if seg.off > start {
+			f.wvec = append(f.wvec, f.wbuf[start:seg.off])
+		}
+		f.wvec = append(f.wvec, seg.data)
+		start = seg.off
+	}
+	if start < len(f.wbuf) {
+		f.wvec = append(f.wvec, f.wbuf[start:])
+	}
+
+	// WriteTo consumes the buffers so use a copy of the slice header to keep f.wvec for reuse.
+	unwritten = f.wvec
+	n, err = unwritten.WriteTo(f.w)
+	return n, unwritten, err
+}
 
-	const maxLen = 1024
-	if len(f.wbuf) > maxLen {
-		f.wbuf = make([]byte, 0, maxLen)
+func (f *Frontend) resetWriteBuffer() {
+	if len(f.wbuf) > f.wbufSize {
+		f.wbuf = make([]byte, 0, f.wbufSize)
 	} else {
 		f.wbuf = f.wbuf[:0]
 	}
+	f.wbufPartiallyWritten = false
 
-	if err != nil {
-		return &writeError{err: err, safeToRetry: n == 0}
+	if len(f.wsegs) > 0 {
+		// Do not keep references to the caller's values.
+		clear(f.wsegs)
+		f.wsegs = f.wsegs[:0]
+		clear(f.wvec)
+		f.wvec = f.wvec[:0]
 	}
+}
 
-	return nil
+// SetWriteBufferSize sets the maximum capacity of the write buffer that is kept after a flush. A larger buffer avoids
+// reallocating the buffer for each flush of large messages at the cost of the memory held by an idle Frontend. The
+// default is 1024 bytes.
+func (f *Frontend) SetWriteBufferSize(size int) {
+	f.wbufSize = size
+}
+
+// SetVectoredWriteThreshold sets the minimum length of a Bind parameter value or CopyData payload that
+// SendBindVectored and SendCopyDataVectored write directly from the message instead of copying it into the write
+// buffer. The buffered messages and these values are written with a single vectored write (writev) when the writer
+// supports it. This avoids copying large values for bulk workloads. If threshold is 0, which is the default, vectored
+// writes are disabled.
+func (f *Frontend) SetVectoredWriteThreshold(threshold int) {
+	f.vectoredWriteThreshold = threshold
 }
 
 // Trace starts tracing the message traffic to w. It writes in a similar format to that produced by the libpq function
@@ -134,6 +298,21 @@ func (f *Frontend) Untrace() {
 	f.tracer = nil
 }
 
+// SetMessageObserver sets fn to be called with the sender ('F' or 'B'), type, and encoded length of each message that is
+// sent or received. Messages without a type byte such as StartupMessage have a type of 0. Unlike Trace, the message
+// contents are not formatted so it is cheap enough to keep enabled. Pass nil to remove the observer. It is safe to change
+// the observer when the Frontend is idle.
+func (f *Frontend) SetMessageObserver(fn func(sender, msgType byte, encodedLen int32)) {
+	f.observer = fn
+}
+
+// observeSent calls the observer for the message of encodedLen bytes that starts at f.wbuf[off].
+func (f *Frontend) observeSent(off, encodedLen int) {
+	if f.observer != nil {
+		f.observer('F', f.wbuf[off], int32(encodedLen))
+	}
+}
+
 // SendBind sends a Bind message to the backend (i.e. the server). The message is buffered until Flush is called. Any
 // error encountered will be returned from Flush.
 func (f *Frontend) SendBind(msg *Bind) {
@@ -152,6 +331,67 @@ func (f *Frontend) SendBind(msg *Bind) {
 	if f.tracer != nil {
 		f.tracer.traceBind('F', int32(len(f.wbuf)-prevLen), msg)
 	}
+	f.observeSent(prevLen, len(f.wbuf)-prevLen)
+}
+
+// SendBindVectored sends a Bind message like SendBind. But parameter values that are at least as long as the vectored
+// write threshold are not copied into the write buffer. They must not be modified until Flush or FlushResumable
+// returns. See SetVectoredWriteThreshold.
+func (f *Frontend) SendBindVectored(msg *Bind) {
+	if f.encodeError != nil {
+		return
+	}
+
+	prevLen := len(f.wbuf)
+	prevSegs := len(f.wsegs)
+	newBuf, newSegs, err := msg.encode(f.wbuf, f.vectoredWriteThreshold, f.wsegs)
+	if err != nil {
+		f.encodeError = err
+		return
+	}
+	f.wbuf = newBuf
+	f.wsegs = newSegs
+
+	if f.tracer != nil || f.observer != nil {
+		msgLen := len(f.wbuf) - prevLen
+		for _, seg := range f.wsegs[prevSegs:] {
+			msgLen += len(seg.data)
+		}
+		if f.tracer != nil {
+			f.tracer.traceBind('F', int32(msgLen), msg)
+		}
+		f.observeSent(prevLen, msgLen)
+	}
+}
+
+// SendCopyDataVectored sends a CopyData message like Send. But if msg.Data is at least as long as the vectored write
+// threshold it is not copied into the write buffer. It must not be modified until Flush or FlushResumable returns. See
+// SetVectoredWriteThreshold.
+func (f *Frontend) SendCopyDataVectored(msg *CopyData) {
+	if f.vectoredWriteThreshold == 0 || len(msg.Data) < f.vectoredWriteThreshold {
+		f.Send(msg)
+		return
+	}
+
+	if f.encodeError != nil {
+		return
+	}
+
+	dst, sp := beginMessage(f.wbuf, 'd')
+	dst, err := finishVectoredMessage(dst, sp, len(msg.Data))
+	if err != nil {
+		f.encodeError = err
+		return
+	}
+	f.wbuf = dst
+	f.wsegs = append(f.wsegs, writeSegment{off: len(f.wbuf), data: msg.Data})
+
+	if f.tracer != nil {
+		f.tracer.traceCopyData('F', int32(len(msg.Data)+5), msg)
+	}
+	if f.observer != nil {
+		f.observer('F', 'd', int32(len(msg.Data)+5))
+	}
 }
 
 // SendParse sends a Parse message to the backend (i.e. the server). The message is buffered until Flush is called. Any
@@ -172,6 +412,7 @@ func (f *Frontend) SendParse(msg *Parse) {
 	if f.tracer != nil {
 		f.tracer.traceParse('F', int32(len(f.wbuf)-prevLen), msg)
 	}
+	f.observeSent(prevLen, len(f.wbuf)-prevLen)
 }
 
 // SendClose sends a Close message to the backend (i.e. the server). The message is buffered until Flush is called. Any
@@ -192,6 +433,7 @@ func (f *Frontend) SendClose(msg *Close) {
 	if f.tracer != nil {
 		f.tracer.traceClose('F', int32(len(f.wbuf)-prevLen), msg)
 	}
+	f.observeSent(prevLen, len(f.wbuf)-prevLen)
 }
 
 // SendDescribe sends a Describe message to the backend (i.e. the server). The message is buffered until Flush is
@@ -212,6 +454,7 @@ func (f *Frontend) SendDescribe(msg *Describe) {
 	if f.tracer != nil {
 		f.tracer.traceDescribe('F', int32(len(f.wbuf)-prevLen), msg)
 	}
+	f.observeSent(prevLen, len(f.wbuf)-prevLen)
 }
 
 // SendExecute sends an Execute message to the backend (i.e. the server). The message is buffered until Flush is called.
@@ -232,6 +475,7 @@ func (f *Frontend) SendExecute(msg *Execute) {
 	if f.tracer != nil {
 		f.tracer.TraceQueryute('F', int32(len(f.wbuf)-prevLen), msg)
 	}
+	f.observeSent(prevLen, len(f.wbuf)-prevLen)
 }
 
 // SendSync sends a Sync message to the backend (i.e. the server). The message is buffered until Flush is called. Any
@@ -252,6 +496,7 @@ func (f *Frontend) SendSync(msg *Sync) {
 	if f.tracer != nil {
 		f.tracer.traceSync('F', int32(len(f.wbuf)-prevLen), msg)
 	}
+	f.observeSent(prevLen, len(f.wbuf)-prevLen)
 }
 
 // SendQuery sends a Query message to the backend (i.e. the server). The message is buffered until Flush is called. Any
@@ -272,6 +517,7 @@ func (f *Frontend) SendQuery(msg *Query) {
 	if f.tracer != nil {
 		f.tracer.traceQuery('F', int32(len(f.wbuf)-prevLen), msg)
 	}
+	f.observeSent(prevLen, len(f.wbuf)-prevLen)
 }
 
 // SendUnbufferedEncodedCopyData immediately sends an encoded CopyData message to the backend (i.e. the server). This method
@@ -291,6 +537,9 @@ func (f *Frontend) SendUnbufferedEncodedCopyData(msg []byte) error {
 	if f.tracer != nil {
 		f.tracer.traceCopyData('F', int32(len(msg)-1), &CopyData{})
 	}
+	if f.observer != nil {
+		f.observer('F', 'd', int32(len(msg)))
+	}
 
 	return nil
 }
@@ -377,6 +626,8 @@ func (f *Frontend) Receive() (BackendMessage, error) {
 		msg = &f.parameterDescription
 	case 'T':
 		msg = &f.rowDescription
+	case 'v':
+		msg = &f.negotiateProtocolVersion
 	case 'V':
 		msg = &f.functionCallResponse
 	case 'W':
@@ -384,7 +635,11 @@ func (f *Frontend) Receive() (BackendMessage, error) {
 	case 'Z':
 		msg = &f.readyForQuery
 	default:
-		return nil, fmt.Errorf("unknown message type: %c", f.msgType)
+		if !f.passthroughUnknownMessages {
+			return nil, fmt.Errorf("unknown message type: %c", f.msgType)
+		}
+		f.unknownMessage.Type = f.msgType
+		msg = &f.unknownMessage
 	}
 
 	err = msg.Decode(msgBody)
@@ -395,6 +650,9 @@ func (f *Frontend) Receive() (BackendMessage, error) {
 	if f.tracer != nil {
 		f.tracer.traceMessage('B', int32(5+len(msgBody)), msg)
 	}
+	if f.observer != nil {
+		f.observer('B', f.msgType, int32(5+len(msgBody)))
+	}
 
 	return msg, nil
 }
@@ -457,6 +715,18 @@ func (f *Frontend) ReadBufferLen() int {
 	return f.cr.wp - f.cr.rp
 }
 
+// ReadBuffer returns a copy of the data that has been read from the underlying reader but not yet received as a
+// message. It returns an error if Receive has partially read a message.
+func (f *Frontend) ReadBuffer() ([]byte, error) {
+	if f.partialMsg {
+		return nil, errors.New("cannot get read buffer while a message is partially read")
+	}
+
+	buf := make([]byte, f.cr.wp-f.cr.rp)
+	copy(buf, (*f.cr.buf)[f.cr.rp:f.cr.wp])
+	return buf, nil
+}
+
 // SetMaxBodyLen sets the maximum length of a message body in octets.
 // If a message body exceeds this length, Receive will return an error.
 // This is useful for protecting against a corrupted server that sends
@@ -466,3 +736,9 @@ func (f *Frontend) ReadBufferLen() int {
 func (f *Frontend) SetMaxBodyLen(maxBodyLen int) {
 	f.maxBodyLen = maxBodyLen
 }
+
+// SetPassthroughUnknownMessages sets whether Receive returns messages with unrecognized types as *UnknownMessage instead
+// of an error. The default is false.
+func (f *Frontend) SetPassthroughUnknownMessages(passthrough bool) {
+	f.passthroughUnknownMessages = passthrough
+}
@@ -1,7 +1,9 @@
 package pgproto3_test
 
 import (
+	"bytes"
 	"io"
+	"os"
 	"testing"
 
 	"github.com/jackc/pgx/v5/pgproto3"
@@ -133,3 +135,216 @@ func TestFrontendReceiveExceededMaxBodyLen(t *testing.T) {
 	var invalidBodyLenErr *pgproto3.ExceededMaxBodyLenErr
 	assert.ErrorAs(t, err, &invalidBodyLenErr)
 }
+
+func TestFrontendReceiveUnknownMessage(t *testing.T) {
+	t.Parallel()
+
+	unknown, err := (&pgproto3.UnknownMessage{Type: '~', Data: []byte("hello")}).Encode(nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte{'~', 0, 0, 0, 9, 'h', 'e', 'l', 'l', 'o'}, unknown)
+
+	server := &interruptReader{}
+	server.push(unknown)
+	frontend := pgproto3.NewFrontend(server, nil)
+	_, err = frontend.Receive()
+	require.EqualError(t, err, "unknown message type: ~")
+
+	server = &interruptReader{}
+	server.push(unknown)
+	server.push([]byte{'Z', 0, 0, 0, 5, 'I'})
+	frontend = pgproto3.NewFrontend(server, nil)
+	frontend.SetPassthroughUnknownMessages(true)
+
+	msg, err := frontend.Receive()
+	require.NoError(t, err)
+	require.Equal(t, &pgproto3.UnknownMessage{Type: '~', Data: []byte("hello")}, msg)
+
+	msg, err = frontend.Receive()
+	require.NoError(t, err)
+	require.Equal(t, &pgproto3.ReadyForQuery{TxStatus: 'I'}, msg)
+}
+
+func TestFrontendReceiveNegotiateProtocolVersion(t *testing.T) {
+	t.Parallel()
+
+	for _, want := range []*pgproto3.NegotiateProtocolVersion{
+		{NewestMinorProtocol: 0},
+		{NewestMinorProtocol: 2, UnrecognizedOptions: []string{"_pq_.foo", "_pq_.bar"}},
+	} {
+		dst, err := want.Encode(nil)
+		require.NoError(t, err)
+
+		server := &interruptReader{}
+		server.push(dst)
+
+		frontend := pgproto3.NewFrontend(server, nil)
+
+		msg, err := frontend.Receive()
+		require.NoError(t, err)
+		require.Equal(t, want, msg)
+	}
+}
+
+func TestFrontendReceiveExtendedBackendKeyData(t *testing.T) {
+	t.Parallel()
+
+	for _, want := range []*pgproto3.BackendKeyData{
+		{ProcessID: 1, SecretKey: 2},
+		{ProcessID: 1, ExtendedSecretKey: []byte("0123456789abcdef0123456789abcdef")},
+		{ProcessID: 1, SecretKey: 2},
+	} {
+		dst, err := want.Encode(nil)
+		require.NoError(t, err)
+
+		server := &interruptReader{}
+		server.push(dst)
+
+		frontend := pgproto3.NewFrontend(server, nil)
+
+		msg, err := frontend.Receive()
+		require.NoError(t, err)
+		require.Equal(t, want, msg)
+	}
+}
+
+type partialWriter struct {
+	bytes.Buffer
+	failNextWriteAfter int
+}
+
+func (w *partialWriter) Write(p []byte) (int, error) {
+	if w.failNextWriteAfter > 0 {
+		n, _ := w.Buffer.Write(p[:w.failNextWriteAfter])
+		w.failNextWriteAfter = 0
+		return n, os.ErrDeadlineExceeded
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestFrontendFlushResumable(t *testing.T) {
+	t.Parallel()
+
+	w := &partialWriter{failNextWriteAfter: 3}
+	frontend := pgproto3.NewFrontend(nil, w)
+
+	frontend.Send(&pgproto3.Query{String: "select 1"})
+	err := frontend.FlushResumable()
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	var retryErr interface{ SafeToRetry() bool }
+	require.ErrorAs(t, err, &retryErr)
+	require.False(t, retryErr.SafeToRetry())
+
+	frontend.Send(&pgproto3.Sync{})
+	err = frontend.FlushResumable()
+	require.NoError(t, err)
+
+	expected, err := (&pgproto3.Query{String: "select 1"}).Encode(nil)
+	require.NoError(t, err)
+	expected, err = (&pgproto3.Sync{}).Encode(expected)
+	require.NoError(t, err)
+	require.Equal(t, expected, w.Bytes())
+}
+
+func TestFrontendSendVectored(t *testing.T) {
+	t.Parallel()
+
+	w := &bytes.Buffer{}
+	frontend := pgproto3.NewFrontend(nil, w)
+	frontend.SetVectoredWriteThreshold(8)
+
+	bind := &pgproto3.Bind{
+		PreparedStatement:    "ps",
+		ParameterFormatCodes: []int16{1},
+		Parameters:           [][]byte{[]byte("short"), bytes.Repeat([]byte("x"), 16), nil},
+		ResultFormatCodes:    []int16{1},
+	}
+	largeCopyData := &pgproto3.CopyData{Data: bytes.Repeat([]byte("y"), 32)}
+	smallCopyData := &pgproto3.CopyData{Data: []byte("z")}
+
+	frontend.SendBindVectored(bind)
+	frontend.SendCopyDataVectored(largeCopyData)
+	frontend.SendCopyDataVectored(smallCopyData)
+	frontend.Send(&pgproto3.Sync{})
+	err := frontend.Flush()
+	require.NoError(t, err)
+
+	var expected []byte
+	for _, msg := range []pgproto3.FrontendMessage{bind, largeCopyData, smallCopyData, &pgproto3.Sync{}} {
+		expected, err = msg.Encode(expected)
+		require.NoError(t, err)
+	}
+	require.Equal(t, expected, w.Bytes())
+}
+
+func TestFrontendMessageObserver(t *testing.T) {
+	t.Parallel()
+
+	type observed struct {
+		sender     byte
+		msgType    byte
+		encodedLen int32
+	}
+	var messages []observed
+
+	server := &interruptReader{}
+	server.push([]byte{'Z', 0, 0, 0, 5, 'I'})
+
+	w := &bytes.Buffer{}
+	frontend := pgproto3.NewFrontend(server, w)
+	frontend.SetVectoredWriteThreshold(8)
+	frontend.SetMessageObserver(func(sender, msgType byte, encodedLen int32) {
+		messages = append(messages, observed{sender: sender, msgType: msgType, encodedLen: encodedLen})
+	})
+
+	frontend.Send(&pgproto3.StartupMessage{ProtocolVersion: pgproto3.ProtocolVersionNumber, Parameters: map[string]string{"user": "u"}})
+	frontend.SendQuery(&pgproto3.Query{String: "select 1"})
+	frontend.SendBindVectored(&pgproto3.Bind{Parameters: [][]byte{bytes.Repeat([]byte("x"), 16)}})
+	frontend.SendCopyDataVectored(&pgproto3.CopyData{Data: bytes.Repeat([]byte("y"), 32)})
+	frontend.Send(&pgproto3.Sync{})
+	require.NoError(t, frontend.Flush())
+
+	_, err := frontend.Receive()
+	require.NoError(t, err)
+
+	// The lengths of all sent messages add up to the bytes written.
+	var sentLen int32
+	for _, msg := range messages[:5] {
+		sentLen += msg.encodedLen
+	}
+	assert.EqualValues(t, w.Len(), sentLen)
+
+	assert.Equal(t, []observed{
+		{sender: 'F', msgType: 0, encodedLen: messages[0].encodedLen},
+		{sender: 'F', msgType: 'Q', encodedLen: 14},
+		{sender: 'F', msgType: 'B', encodedLen: 33},
+		{sender: 'F', msgType: 'd', encodedLen: 37},
+		{sender: 'F', msgType: 'S', encodedLen: 5},
+		{sender: 'B', msgType: 'Z', encodedLen: 6},
+	}, messages)
+}
+
+func TestFrontendFlushResumableVectored(t *testing.T) {
+	t.Parallel()
+
+	w := &partialWriter{failNextWriteAfter: 3}
+	frontend := pgproto3.NewFrontend(nil, w)
+	frontend.SetVectoredWriteThreshold(8)
+
+	data := bytes.Repeat([]byte("y"), 32)
+	frontend.SendCopyDataVectored(&pgproto3.CopyData{Data: data})
+	err := frontend.FlushResumable()
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+
+	// The unwritten data was copied so the caller may reuse it.
+	expected, err := (&pgproto3.CopyData{Data: bytes.Repeat([]byte("y"), 32)}).Encode(nil)
+	require.NoError(t, err)
+	copy(data, "modified")
+
+	frontend.Send(&pgproto3.Sync{})
+	err = frontend.FlushResumable()
+	require.NoError(t, err)
+
+	expected, err = (&pgproto3.Sync{}).Encode(expected)
+	require.NoError(t, err)
+	require.Equal(t, expected, w.Bytes())
+}
@@ -0,0 +1,78 @@
+package pgproto3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5/internal/pgio"
+)
+
+// NegotiateProtocolVersion is sent by the server when the client requested a newer minor protocol version than the
+// server supports or protocol options (parameters prefixed with _pq_.) that the server does not recognize.
+type NegotiateProtocolVersion struct {
+	// NewestMinorProtocol is the newest minor protocol version supported by the server for the major version requested
+	// by the client.
+	NewestMinorProtocol uint32
+
+	// UnrecognizedOptions are the protocol options requested by the client that the server does not recognize.
+	UnrecognizedOptions []string
+}
+
+// Backend identifies this message as sendable by the PostgreSQL backend.
+func (*NegotiateProtocolVersion) Backend() {}
+
+// Decode decodes src into dst. src must contain the complete message with the exception of the initial 1 byte message
+// type identifier and 4 byte message length.
+func (dst *NegotiateProtocolVersion) Decode(src []byte) error {
+	if len(src) < 8 {
+		return &invalidMessageLenErr{messageType: "NegotiateProtocolVersion", expectedLen: 8, actualLen: len(src)}
+	}
+
+	newestMinorProtocol := binary.BigEndian.Uint32(src[:4])
+	optionCount := int(binary.BigEndian.Uint32(src[4:8]))
+	rp := 8
+
+	// Use the remaining message size as an upper bound for the capacity to avoid a large allocation from a malicious
+	// message.
+	var options []string
+	if optionCount > 0 {
+		options = make([]string, 0, min(optionCount, len(src)-rp))
+	}
+	for i := 0; i < optionCount; i++ {
+		idx := bytes.IndexByte(src[rp:], 0)
+		if idx < 0 {
+			return &invalidMessageFormatErr{messageType: "NegotiateProtocolVersion"}
+		}
+		options = append(options, string(src[rp:rp+idx]))
+		rp += idx + 1
+	}
+
+	*dst = NegotiateProtocolVersion{NewestMinorProtocol: newestMinorProtocol, UnrecognizedOptions: options}
+	return nil
+}
+
+// Encode encodes src into dst. dst will include the 1 byte message type identifier and the 4 byte message length.
+func (src *NegotiateProtocolVersion) Encode(dst []byte) ([]byte, error) {
+	dst, sp := beginMessage(dst, 'v')
+	dst = pgio.AppendUint32(dst, src.NewestMinorProtocol)
+	dst = pgio.AppendUint32(dst, uint32(len(src.UnrecognizedOptions)))
+	for _, option := range src.UnrecognizedOptions {
+		dst = append(dst, option...)
+		dst = append(dst, 0)
+	}
+	return finishMessage(dst, sp)
+}
+
+// MarshalJSON implements encoding/json.Marshaler.
+func (src NegotiateProtocolVersion) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type                string
+		NewestMinorProtocol uint32
+		UnrecognizedOptions []string
+	}{
+		Type:                "NegotiateProtocolVersion",
+		NewestMinorProtocol: src.NewestMinorProtocol,
+		UnrecognizedOptions: src.UnrecognizedOptions,
+	})
+}
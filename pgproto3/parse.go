@@ -22,32 +22,46 @@ func (*Parse) Frontend() {}
 // Decode decodes src into dst. src must contain the complete message with the exception of the initial 1 byte message
 // type identifier and 4 byte message length.
 func (dst *Parse) Decode(src []byte) error {
-	*dst = Parse{}
+	return dst.decode(src, false)
+}
 
-	buf := bytes.NewBuffer(src)
+// decode decodes src into dst like Decode. If reuseSlices is true, the slices of dst are reused if possible.
+func (dst *Parse) decode(src []byte, reuseSlices bool) error {
+	var parameterOIDs []uint32
+	if reuseSlices {
+		parameterOIDs = dst.ParameterOIDs
+	}
+	*dst = Parse{}
 
-	b, err := buf.ReadBytes(0)
-	if err != nil {
-		return err
+	idx := bytes.IndexByte(src, 0)
+	if idx < 0 {
+		return &invalidMessageFormatErr{messageType: "Parse"}
 	}
-	dst.Name = string(b[:len(b)-1])
+	dst.Name = string(src[:idx])
+	rp := idx + 1
 
-	b, err = buf.ReadBytes(0)
-	if err != nil {
-		return err
+	idx = bytes.IndexByte(src[rp:], 0)
+	if idx < 0 {
+		return &invalidMessageFormatErr{messageType: "Parse"}
 	}
-	dst.Query = string(b[:len(b)-1])
+	dst.Query = string(src[rp : rp+idx])
+	rp += idx + 1
 
-	if buf.Len() < 2 {
+	if len(src[rp:]) < 2 {
 		return &invalidMessageFormatErr{messageType: "Parse"}
 	}
-	parameterOIDCount := int(binary.BigEndian.Uint16(buf.Next(2)))
+	parameterOIDCount := int(binary.BigEndian.Uint16(src[rp:]))
+	rp += 2
 
-	for i := 0; i < parameterOIDCount; i++ {
-		if buf.Len() < 4 {
+	if parameterOIDCount > 0 {
+		if len(src[rp:]) < parameterOIDCount*4 {
 			return &invalidMessageFormatErr{messageType: "Parse"}
 		}
-		dst.ParameterOIDs = append(dst.ParameterOIDs, binary.BigEndian.Uint32(buf.Next(4)))
+		dst.ParameterOIDs = reuseSlice(parameterOIDs, parameterOIDCount)
+		for i := 0; i < parameterOIDCount; i++ {
+			dst.ParameterOIDs[i] = binary.BigEndian.Uint32(src[rp:])
+			rp += 4
+		}
 	}
 
 	return nil
@@ -111,10 +111,26 @@ func beginMessage(dst []byte, t byte) ([]byte, int) {
 // finishMessage finishes a message that was started with beginMessage. It computes the message length and writes it to
 // dst[sp]. If the message length is too large it returns an error. Otherwise it returns the final message buffer.
 func finishMessage(dst []byte, sp int) ([]byte, error) {
-	messageBodyLen := len(dst[sp:])
+	return finishVectoredMessage(dst, sp, 0)
+}
+
+// finishVectoredMessage finishes a message like finishMessage for a message that has externalLen bytes in writeSegments
+// in addition to the bytes in dst.
+func finishVectoredMessage(dst []byte, sp int, externalLen int) ([]byte, error) {
+	messageBodyLen := len(dst[sp:]) + externalLen
 	if messageBodyLen > maxMessageBodyLen {
 		return nil, errors.New("message body too large")
 	}
 	pgio.SetInt32(dst[sp:], int32(messageBodyLen))
 	return dst, nil
 }
+
+// reuseSlice returns a slice of length n. It reuses the memory of s if its capacity is large enough but not
+// substantially too large. This allows a message decoded repeatedly into the same struct to avoid allocating while
+// preventing one message with many elements from permanently allocating memory.
+func reuseSlice[T any](s []T, n int) []T {
+	if s == nil || cap(s) < n || cap(s)-n > 32 {
+		return make([]T, n)
+	}
+	return s[:n]
+}
@@ -0,0 +1,57 @@
+package pgproto3
+
+import (
+	"fmt"
+)
+
+// Relay relays messages between a client connected to b and a server connected to f. It is the core of a message
+// level proxy. Startup and authentication must already have been completed on both connections, e.g. with
+// NegotiateStartup and one of the Authenticate functions on the client side and Frontend on the server side.
+//
+// Relay returns nil when the client sends Terminate, which is forwarded to the server. Otherwise, it returns the first
+// error from either direction. The other direction may still be blocked reading when Relay returns. The caller must
+// close both underlying connections after Relay returns.
+func Relay(b *Backend, f *Frontend) error {
+	errChan := make(chan error, 2)
+
+	go func() {
+		for {
+			msg, err := b.Receive()
+			if err != nil {
+				errChan <- fmt.Errorf("receive from client: %w", err)
+				return
+			}
+
+			f.Send(msg)
+			err = f.Flush()
+			if err != nil {
+				errChan <- fmt.Errorf("send to server: %w", err)
+				return
+			}
+
+			if _, ok := msg.(*Terminate); ok {
+				errChan <- nil
+				return
+			}
+		}
+	}()
+
+	go func() {
+		for {
+			msg, err := f.Receive()
+			if err != nil {
+				errChan <- fmt.Errorf("receive from server: %w", err)
+				return
+			}
+
+			b.Send(msg)
+			err = b.Flush()
+			if err != nil {
+				errChan <- fmt.Errorf("send to client: %w", err)
+				return
+			}
+		}
+	}()
+
+	return <-errChan
+}
@@ -12,6 +12,15 @@ import (
 
 const ProtocolVersionNumber = 196608 // 3.0
 
+const (
+	// ProtocolVersion30 is protocol version 3.0. It is supported by all PostgreSQL versions since 7.4.
+	ProtocolVersion30 = ProtocolVersionNumber
+
+	// ProtocolVersion32 is protocol version 3.2. It was introduced in PostgreSQL 18. It allows secret keys for cancel
+	// requests longer than 4 bytes.
+	ProtocolVersion32 = 196610
+)
+
 type StartupMessage struct {
 	ProtocolVersion uint32
 	Parameters      map[string]string
@@ -30,8 +39,8 @@ func (dst *StartupMessage) Decode(src []byte) error {
 	dst.ProtocolVersion = binary.BigEndian.Uint32(src)
 	rp := 4
 
-	if dst.ProtocolVersion != ProtocolVersionNumber {
-		return fmt.Errorf("Bad startup message version number. Expected %d, got %d", ProtocolVersionNumber, dst.ProtocolVersion)
+	if dst.ProtocolVersion>>16 != ProtocolVersionNumber>>16 {
+		return fmt.Errorf("Bad startup message version number. Expected major version %d, got %d", ProtocolVersionNumber>>16, dst.ProtocolVersion)
 	}
 
 	dst.Parameters = make(map[string]string)
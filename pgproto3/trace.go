@@ -27,6 +27,10 @@ type TracerOptions struct {
 
 	// RegressMode redacts fields that may be vary between executions.
 	RegressMode bool
+
+	// RedactParameterValues replaces the values of bound parameters with a placeholder. Password, SASL, and GSS
+	// authentication payloads are always redacted regardless of this setting.
+	RedactParameterValues bool
 }
 
 func (t *tracer) traceMessage(sender byte, encodedLen int32, msg Message) {
@@ -91,6 +95,10 @@ func (t *tracer) traceMessage(sender byte, encodedLen int32, msg Message) {
 		t.traceFunctionCallResponse(sender, encodedLen, msg)
 	case *GSSEncRequest:
 		t.traceGSSEncRequest(sender, encodedLen, msg)
+	case *GSSResponse:
+		t.traceGSSResponse(sender, encodedLen, msg)
+	case *NegotiateProtocolVersion:
+		t.traceNegotiateProtocolVersion(sender, encodedLen, msg)
 	case *NoData:
 		t.traceNoData(sender, encodedLen, msg)
 	case *NoticeResponse:
@@ -103,6 +111,8 @@ func (t *tracer) traceMessage(sender byte, encodedLen int32, msg Message) {
 		t.traceParameterStatus(sender, encodedLen, msg)
 	case *Parse:
 		t.traceParse(sender, encodedLen, msg)
+	case *PasswordMessage:
+		t.tracePasswordMessage(sender, encodedLen, msg)
 	case *ParseComplete:
 		t.traceParseComplete(sender, encodedLen, msg)
 	case *PortalSuspended:
@@ -113,6 +123,10 @@ func (t *tracer) traceMessage(sender byte, encodedLen int32, msg Message) {
 		t.traceReadyForQuery(sender, encodedLen, msg)
 	case *RowDescription:
 		t.traceRowDescription(sender, encodedLen, msg)
+	case *SASLInitialResponse:
+		t.traceSASLInitialResponse(sender, encodedLen, msg)
+	case *SASLResponse:
+		t.traceSASLResponse(sender, encodedLen, msg)
 	case *SSLRequest:
 		t.traceSSLRequest(sender, encodedLen, msg)
 	case *StartupMessage:
@@ -121,6 +135,8 @@ func (t *tracer) traceMessage(sender byte, encodedLen int32, msg Message) {
 		t.traceSync(sender, encodedLen, msg)
 	case *Terminate:
 		t.traceTerminate(sender, encodedLen, msg)
+	case *UnknownMessage:
+		t.traceUnknownMessage(sender, encodedLen, msg)
 	default:
 		t.writeTrace(sender, encodedLen, "Unknown", nil)
 	}
@@ -163,7 +179,11 @@ func (t *tracer) traceBackendKeyData(sender byte, encodedLen int32, msg *Backend
 		if t.RegressMode {
 			t.buf.WriteString("\t NNNN NNNN")
 		} else {
-			fmt.Fprintf(t.buf, "\t %d %d", msg.ProcessID, msg.SecretKey)
+			if msg.ExtendedSecretKey != nil {
+				fmt.Fprintf(t.buf, "\t %d %x", msg.ProcessID, msg.ExtendedSecretKey)
+			} else {
+				fmt.Fprintf(t.buf, "\t %d %d", msg.ProcessID, msg.SecretKey)
+			}
 		}
 	})
 }
@@ -176,7 +196,11 @@ func (t *tracer) traceBind(sender byte, encodedLen int32, msg *Bind) {
 		}
 		fmt.Fprintf(t.buf, " %d", len(msg.Parameters))
 		for _, p := range msg.Parameters {
-			fmt.Fprintf(t.buf, " %s", traceSingleQuotedString(p))
+			if t.RedactParameterValues && p != nil {
+				t.buf.WriteString(" " + traceRedacted)
+			} else {
+				fmt.Fprintf(t.buf, " %s", traceSingleQuotedString(p))
+			}
 		}
 		fmt.Fprintf(t.buf, " %d", len(msg.ResultFormatCodes))
 		for _, fc := range msg.ResultFormatCodes {
@@ -282,6 +306,21 @@ func (t *tracer) traceGSSEncRequest(sender byte, encodedLen int32, msg *GSSEncRe
 	t.writeTrace(sender, encodedLen, "GSSEncRequest", nil)
 }
 
+func (t *tracer) traceGSSResponse(sender byte, encodedLen int32, msg *GSSResponse) {
+	t.writeTrace(sender, encodedLen, "GSSResponse", func() {
+		t.buf.WriteString("\t " + traceRedacted)
+	})
+}
+
+func (t *tracer) traceNegotiateProtocolVersion(sender byte, encodedLen int32, msg *NegotiateProtocolVersion) {
+	t.writeTrace(sender, encodedLen, "NegotiateProtocolVersion", func() {
+		fmt.Fprintf(t.buf, "\t %d %d", msg.NewestMinorProtocol, len(msg.UnrecognizedOptions))
+		for _, option := range msg.UnrecognizedOptions {
+			fmt.Fprintf(t.buf, " %s", traceDoubleQuotedString([]byte(option)))
+		}
+	})
+}
+
 func (t *tracer) traceNoData(sender byte, encodedLen int32, msg *NoData) {
 	t.writeTrace(sender, encodedLen, "NoData", nil)
 }
@@ -315,6 +354,12 @@ func (t *tracer) traceParse(sender byte, encodedLen int32, msg *Parse) {
 	})
 }
 
+func (t *tracer) tracePasswordMessage(sender byte, encodedLen int32, msg *PasswordMessage) {
+	t.writeTrace(sender, encodedLen, "PasswordMessage", func() {
+		t.buf.WriteString("\t " + traceRedacted)
+	})
+}
+
 func (t *tracer) traceParseComplete(sender byte, encodedLen int32, msg *ParseComplete) {
 	t.writeTrace(sender, encodedLen, "ParseComplete", nil)
 }
@@ -344,6 +389,18 @@ func (t *tracer) traceRowDescription(sender byte, encodedLen int32, msg *RowDesc
 	})
 }
 
+func (t *tracer) traceSASLInitialResponse(sender byte, encodedLen int32, msg *SASLInitialResponse) {
+	t.writeTrace(sender, encodedLen, "SASLInitialResponse", func() {
+		fmt.Fprintf(t.buf, "\t %s %s", traceDoubleQuotedString([]byte(msg.AuthMechanism)), traceRedacted)
+	})
+}
+
+func (t *tracer) traceSASLResponse(sender byte, encodedLen int32, msg *SASLResponse) {
+	t.writeTrace(sender, encodedLen, "SASLResponse", func() {
+		t.buf.WriteString("\t " + traceRedacted)
+	})
+}
+
 func (t *tracer) traceSSLRequest(sender byte, encodedLen int32, msg *SSLRequest) {
 	t.writeTrace(sender, encodedLen, "SSLRequest", nil)
 }
@@ -360,6 +417,12 @@ func (t *tracer) traceTerminate(sender byte, encodedLen int32, msg *Terminate) {
 	t.writeTrace(sender, encodedLen, "Terminate", nil)
 }
 
+func (t *tracer) traceUnknownMessage(sender byte, encodedLen int32, msg *UnknownMessage) {
+	t.writeTrace(sender, encodedLen, "Unknown", func() {
+		fmt.Fprintf(t.buf, "\t %s", traceSingleQuotedString([]byte{msg.Type}))
+	})
+}
+
 func (t *tracer) writeTrace(sender byte, encodedLen int32, msgType string, writeDetails func()) {
 	t.mux.Lock()
 	defer t.mux.Unlock()
@@ -391,6 +454,9 @@ func (t *tracer) writeTrace(sender byte, encodedLen int32, msgType string, write
 	t.buf.WriteTo(t.w)
 }
 
+// traceRedacted is written in place of values that must not appear in trace output.
+const traceRedacted = "'[redacted]'"
+
 // traceDoubleQuotedString returns t.buf as a double-quoted string without any escaping. It is roughly equivalent to
 // pqTraceOutputString in libpq.
 func traceDoubleQuotedString(buf []byte) string {
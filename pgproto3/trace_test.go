@@ -54,3 +54,29 @@ B	ReadyForQuery	6	 I
 
 	require.Equal(t, expected, traceOutput.String())
 }
+
+func TestTraceRedaction(t *testing.T) {
+	t.Parallel()
+
+	traceOutput := &bytes.Buffer{}
+	frontend := pgproto3.NewFrontend(&bytes.Buffer{}, &bytes.Buffer{})
+	frontend.Trace(traceOutput, pgproto3.TracerOptions{
+		SuppressTimestamps:    true,
+		RedactParameterValues: true,
+	})
+
+	frontend.Send(&pgproto3.PasswordMessage{Password: "secret"})
+	frontend.Send(&pgproto3.SASLInitialResponse{AuthMechanism: "SCRAM-SHA-256", Data: []byte("n,,n=,r=secret")})
+	frontend.Send(&pgproto3.SASLResponse{Data: []byte("c=biws,r=secret")})
+	frontend.Send(&pgproto3.Bind{Parameters: [][]byte{[]byte("secret"), nil}})
+	require.NoError(t, frontend.Flush())
+
+	expected := `F	PasswordMessage	12	 '[redacted]'
+F	SASLInitialResponse	37	 "SCRAM-SHA-256" '[redacted]'
+F	SASLResponse	20	 '[redacted]'
+F	Bind	27	 "" "" 0 2 '[redacted]' '' 0
+`
+
+	require.Equal(t, expected, traceOutput.String())
+	require.NotContains(t, traceOutput.String(), "secret")
+}
@@ -0,0 +1,47 @@
+package pgproto3
+
+import (
+	"encoding/hex"
+	"encoding/json"
+)
+
+// UnknownMessage is a backend message with a type that pgproto3 does not recognize. Such messages are only returned by
+// Frontend.Receive when enabled with Frontend.SetPassthroughUnknownMessages. They can be used to experiment with
+// protocol extensions or with proxies that inject custom messages.
+type UnknownMessage struct {
+	// Type is the 1 byte message type identifier.
+	Type byte
+
+	// Data is the message body.
+	Data []byte
+}
+
+// Backend identifies this message as sendable by the PostgreSQL backend.
+func (*UnknownMessage) Backend() {}
+
+// Decode decodes src into dst. src must contain the complete message with the exception of the initial 1 byte message
+// type identifier and 4 byte message length. Type is not changed.
+func (dst *UnknownMessage) Decode(src []byte) error {
+	dst.Data = src
+	return nil
+}
+
+// Encode encodes src into dst. dst will include the 1 byte message type identifier and the 4 byte message length.
+func (src *UnknownMessage) Encode(dst []byte) ([]byte, error) {
+	dst, sp := beginMessage(dst, src.Type)
+	dst = append(dst, src.Data...)
+	return finishMessage(dst, sp)
+}
+
+// MarshalJSON implements encoding/json.Marshaler.
+func (src UnknownMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type        string
+		MessageType string
+		Data        string
+	}{
+		Type:        "UnknownMessage",
+		MessageType: string(src.Type),
+		Data:        hex.EncodeToString(src.Data),
+	})
+}
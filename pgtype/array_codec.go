@@ -59,6 +59,10 @@ func (c *ArrayCodec) PreferredFormat() int16 {
 }
 
 func (c *ArrayCodec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
+	if arrayIterator, ok := value.(ArrayIterator); ok {
+		return c.planEncodeArrayIterator(m, oid, format, arrayIterator)
+	}
+
 	arrayValuer, ok := value.(ArrayGetter)
 	if !ok {
 		return nil
@@ -222,6 +226,20 @@ func (p *encodePlanArrayCodecBinary) Encode(value any, buf []byte) (newBuf []byt
 }
 
 func (c *ArrayCodec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan {
+	// Avoid the generic path for arrays of bytea and uuid that are often in hot paths.
+	if format == BinaryFormatCode {
+		switch c.ElementType.Codec.(type) {
+		case ByteaCodec:
+			if _, ok := target.(*[][]byte); ok {
+				return scanPlanBinaryByteaArrayToBytesSlice{}
+			}
+		case UUIDCodec:
+			if elemType, ok := byte16SliceTargetElemType(target); ok {
+				return &scanPlanBinaryUUIDArrayToByte16Slice{elemType: elemType}
+			}
+		}
+	}
+
 	arrayScanner, ok := target.(ArraySetter)
 	if !ok {
 		return nil
@@ -363,3 +363,111 @@ func TestArrayCodecDecodeTextArrayPrefersBinaryFormat(t *testing.T) {
 		require.Equal(t, int16(1), conn.TypeMap().FormatCodeForOID(sd.Fields[0].DataTypeOID))
 	})
 }
+
+func TestArrayCodecScanMapSet(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var set map[string]struct{}
+		err := conn.QueryRow(ctx, `select array['a', 'b', 'a']::text[]`).Scan(&set)
+		require.NoError(t, err)
+		require.Equal(t, map[string]struct{}{"a": {}, "b": {}}, set)
+
+		var boolSet map[int32]bool
+		err = conn.QueryRow(ctx, `select '{{1,2},{3,1}}'::int4[]`).Scan(&boolSet)
+		require.NoError(t, err)
+		require.Equal(t, map[int32]bool{1: true, 2: true, 3: true}, boolSet)
+
+		err = conn.QueryRow(ctx, `select null::int4[]`).Scan(&boolSet)
+		require.NoError(t, err)
+		require.Nil(t, boolSet)
+	})
+}
+
+func TestArrayCodecScanMapSetWithoutDatabase(t *testing.T) {
+	m := pgtype.NewMap()
+
+	for _, format := range []int16{pgtype.TextFormatCode, pgtype.BinaryFormatCode} {
+		buf, err := m.Encode(pgtype.Int8ArrayOID, format, []int64{3, 1, 3, 2}, nil)
+		require.NoError(t, err)
+
+		var set map[int64]struct{}
+		err = m.Scan(pgtype.Int8ArrayOID, format, buf, &set)
+		require.NoError(t, err)
+		require.Equal(t, map[int64]struct{}{1: {}, 2: {}, 3: {}}, set)
+
+		type stringSet map[string]bool
+		buf, err = m.Encode(pgtype.TextArrayOID, format, []string{"x"}, nil)
+		require.NoError(t, err)
+
+		var named stringSet
+		err = m.Scan(pgtype.TextArrayOID, format, buf, &named)
+		require.NoError(t, err)
+		require.Equal(t, stringSet{"x": true}, named)
+
+		buf, err = m.Encode(pgtype.TextArrayOID, format, []string{}, nil)
+		require.NoError(t, err)
+
+		err = m.Scan(pgtype.TextArrayOID, format, buf, &named)
+		require.NoError(t, err)
+		require.NotNil(t, named)
+		require.Empty(t, named)
+
+		err = m.Scan(pgtype.TextArrayOID, format, nil, &named)
+		require.NoError(t, err)
+		require.Nil(t, named)
+	}
+}
+
+func TestArrayCodecEncodeArrayValuerFuncWithoutDatabase(t *testing.T) {
+	m := pgtype.NewMap()
+
+	count := func(n int) pgtype.ArrayValuerFunc[int32] {
+		return func(yield func(int32) bool) {
+			for i := 0; i < n; i++ {
+				if !yield(int32(i)) {
+					return
+				}
+			}
+		}
+	}
+
+	for _, format := range []int16{pgtype.TextFormatCode, pgtype.BinaryFormatCode} {
+		for _, n := range []int{0, 1, 5} {
+			buf, err := m.Encode(pgtype.Int4ArrayOID, format, count(n), nil)
+			require.NoError(t, err)
+
+			var got []int32
+			err = m.Scan(pgtype.Int4ArrayOID, format, buf, &got)
+			require.NoError(t, err)
+
+			want := make([]int32, n)
+			for i := range want {
+				want[i] = int32(i)
+			}
+			require.Equal(t, want, got, "format %d, n %d", format, n)
+		}
+
+		strs := pgtype.ArrayValuerFunc[*string](func(yield func(*string) bool) {
+			for _, s := range []string{"a", "b,c", `d"e`} {
+				if !yield(&s) {
+					return
+				}
+			}
+			yield(nil)
+		})
+		buf, err := m.Encode(pgtype.TextArrayOID, format, strs, nil)
+		require.NoError(t, err)
+
+		var got []*string
+		err = m.Scan(pgtype.TextArrayOID, format, buf, &got)
+		require.NoError(t, err)
+		require.Len(t, got, 4)
+		require.Equal(t, "a", *got[0])
+		require.Equal(t, "b,c", *got[1])
+		require.Equal(t, `d"e`, *got[2])
+		require.Nil(t, got[3])
+
+		buf, err = m.Encode(pgtype.Int4ArrayOID, format, pgtype.ArrayValuerFunc[int32](nil), nil)
+		require.NoError(t, err)
+		require.Nil(t, buf)
+	}
+}
@@ -0,0 +1,187 @@
+package pgtype
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/jackc/pgx/v5/internal/pgio"
+)
+
+// ArrayIterator is a one-dimensional array whose elements are produced in order instead of being accessed by index.
+// ArrayCodec encodes an ArrayIterator without first collecting its elements in a slice. See ArrayValuerFunc.
+type ArrayIterator interface {
+	// ArrayElements calls yield with each element of the array in order. It must stop if yield returns false.
+	ArrayElements(yield func(elem any) bool)
+
+	// ElementType returns a non-nil value of the type of the elements. This is used by ArrayCodec.PlanEncode.
+	ElementType() any
+}
+
+// ArrayValuerFunc is a function that produces the elements of a one-dimensional array by calling yield with each element
+// in order. It has the same signature as iter.Seq[T], so an iterator can be used as a query argument for an array
+// parameter by converting it. e.g. pgtype.ArrayValuerFunc[float32](seq). This allows encoding large arrays without
+// materializing them in a slice. A nil ArrayValuerFunc is encoded as NULL.
+//
+// ArrayValuerFunc can only be encoded. It is called once each time the query argument is encoded.
+type ArrayValuerFunc[T any] func(yield func(T) bool)
+
+// ArrayElements implements ArrayIterator.
+func (f ArrayValuerFunc[T]) ArrayElements(yield func(elem any) bool) {
+	if f == nil {
+		return
+	}
+	f(func(elem T) bool {
+		return yield(elem)
+	})
+}
+
+// ElementType implements ArrayIterator.
+func (f ArrayValuerFunc[T]) ElementType() any {
+	var v T
+	return v
+}
+
+func (c *ArrayCodec) planEncodeArrayIterator(m *Map, oid uint32, format int16, arrayIterator ArrayIterator) EncodePlan {
+	elementType := arrayIterator.ElementType()
+
+	elementEncodePlan := m.PlanEncode(c.ElementType.OID, format, elementType)
+	if elementEncodePlan == nil {
+		if reflect.TypeOf(elementType) != nil {
+			return nil
+		}
+	}
+
+	switch format {
+	case BinaryFormatCode:
+		return &encodePlanArrayIteratorBinary{ac: c, m: m, oid: oid}
+	case TextFormatCode:
+		return &encodePlanArrayIteratorText{ac: c, m: m, oid: oid}
+	}
+
+	return nil
+}
+
+type encodePlanArrayIteratorText struct {
+	ac  *ArrayCodec
+	m   *Map
+	oid uint32
+}
+
+func (p *encodePlanArrayIteratorText) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	array := value.(ArrayIterator)
+
+	buf = append(buf, '{')
+
+	var encodePlan EncodePlan
+	var lastElemType reflect.Type
+	inElemBuf := make([]byte, 0, 32)
+	elementCount := 0
+	array.ArrayElements(func(elem any) bool {
+		if elementCount > 0 {
+			buf = append(buf, ',')
+		}
+		elementCount++
+
+		var elemBuf []byte
+		if elem != nil {
+			elemType := reflect.TypeOf(elem)
+			if lastElemType != elemType {
+				lastElemType = elemType
+				encodePlan = p.m.PlanEncode(p.ac.ElementType.OID, TextFormatCode, elem)
+				if encodePlan == nil {
+					err = fmt.Errorf("unable to encode %v", elem)
+					return false
+				}
+			}
+			elemBuf, err = encodePlan.Encode(elem, inElemBuf)
+			if err != nil {
+				return false
+			}
+		}
+
+		if elemBuf == nil {
+			buf = append(buf, `NULL`...)
+		} else {
+			buf = append(buf, quoteArrayElementIfNeeded(string(elemBuf))...)
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	buf = append(buf, '}')
+
+	return buf, nil
+}
+
+type encodePlanArrayIteratorBinary struct {
+	ac  *ArrayCodec
+	m   *Map
+	oid uint32
+}
+
+func (p *encodePlanArrayIteratorBinary) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	array := value.(ArrayIterator)
+
+	// The number of elements is not known until they have all been encoded. The length of the dimension is set
+	// afterwards.
+	headerIndex := len(buf)
+	arrayHeader := arrayHeader{
+		Dimensions: []ArrayDimension{{Length: 0, LowerBound: 1}},
+		ElementOID: p.ac.ElementType.OID,
+	}
+	buf = arrayHeader.EncodeBinary(buf)
+	containsNullIndex := headerIndex + 4
+	lengthIndex := headerIndex + 12
+
+	var encodePlan EncodePlan
+	var lastElemType reflect.Type
+	var elementCount int32
+	array.ArrayElements(func(elem any) bool {
+		elementCount++
+
+		sp := len(buf)
+		buf = pgio.AppendInt32(buf, -1)
+
+		var elemBuf []byte
+		if elem != nil {
+			elemType := reflect.TypeOf(elem)
+			if lastElemType != elemType {
+				lastElemType = elemType
+				encodePlan = p.m.PlanEncode(p.ac.ElementType.OID, BinaryFormatCode, elem)
+				if encodePlan == nil {
+					err = fmt.Errorf("unable to encode %v", elem)
+					return false
+				}
+			}
+			elemBuf, err = encodePlan.Encode(elem, buf)
+			if err != nil {
+				return false
+			}
+		}
+
+		if elemBuf == nil {
+			pgio.SetInt32(buf[containsNullIndex:], 1)
+		} else {
+			buf = elemBuf
+			pgio.SetInt32(buf[sp:], int32(len(buf[sp:])-4))
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if elementCount == 0 {
+		// An empty array has no dimensions.
+		arrayHeader.Dimensions = nil
+		return arrayHeader.EncodeBinary(buf[:headerIndex]), nil
+	}
+
+	pgio.SetInt32(buf[lengthIndex:], elementCount)
+
+	return buf, nil
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"encoding/binary"
 	"fmt"
+	"math/big"
 
 	"github.com/jackc/pgx/v5/internal/pgio"
 )
@@ -60,6 +61,15 @@ func (src Bits) Value() (driver.Value, error) {
 	return string(buf), err
 }
 
+// BitsCodec is the codec for the PostgreSQL bit and varbit types. In addition to Bits, BitsScanner, and BitsValuer it
+// supports the following Go types:
+//
+//   - uint64 is the unsigned integer represented by the bits with the first bit as the most significant bit. Only bit
+//     strings of 64 bits or fewer can be scanned into a uint64. A uint64 is encoded as a 64 bit string. Use a cast such as
+//     $1::int8::bit(n) to encode an integer as a shorter bit string.
+//   - []bool has one element per bit. A NULL is scanned as a nil slice.
+//   - big.Int is the unsigned integer represented by the bits like uint64, but of any length. A *big.Int is encoded
+//     as the shortest bit string that represents it. It must not be negative.
 type BitsCodec struct{}
 
 func (BitsCodec) FormatSupported(format int16) bool {
@@ -71,20 +81,82 @@ func (BitsCodec) PreferredFormat() int16 {
 }
 
 func (BitsCodec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
-	if _, ok := value.(BitsValuer); !ok {
-		return nil
-	}
-
+	var bitsPlan EncodePlan
 	switch format {
 	case BinaryFormatCode:
-		return encodePlanBitsCodecBinary{}
+		bitsPlan = encodePlanBitsCodecBinary{}
 	case TextFormatCode:
-		return encodePlanBitsCodecText{}
+		bitsPlan = encodePlanBitsCodecText{}
+	default:
+		return nil
+	}
+
+	switch value.(type) {
+	case BitsValuer:
+		return bitsPlan
+	case uint64:
+		return &encodePlanBitsCodecUint64{bitsPlan: bitsPlan}
+	case []bool:
+		return &encodePlanBitsCodecBoolSlice{bitsPlan: bitsPlan}
+	case *big.Int:
+		return &encodePlanBitsCodecBigInt{bitsPlan: bitsPlan}
 	}
 
 	return nil
 }
 
+type encodePlanBitsCodecUint64 struct {
+	bitsPlan EncodePlan
+}
+
+func (plan *encodePlanBitsCodecUint64) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	bytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(bytes, value.(uint64))
+	return plan.bitsPlan.Encode(Bits{Bytes: bytes, Len: 64, Valid: true}, buf)
+}
+
+type encodePlanBitsCodecBoolSlice struct {
+	bitsPlan EncodePlan
+}
+
+func (plan *encodePlanBitsCodecBoolSlice) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	bools := value.([]bool)
+	if bools == nil {
+		return nil, nil
+	}
+
+	bytes := make([]byte, (len(bools)+7)/8)
+	for i, b := range bools {
+		if b {
+			bytes[i/8] |= 128 >> uint(i%8)
+		}
+	}
+
+	return plan.bitsPlan.Encode(Bits{Bytes: bytes, Len: int32(len(bools)), Valid: true}, buf)
+}
+
+type encodePlanBitsCodecBigInt struct {
+	bitsPlan EncodePlan
+}
+
+func (plan *encodePlanBitsCodecBigInt) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	n := value.(*big.Int)
+	if n == nil {
+		return nil, nil
+	}
+
+	if n.Sign() < 0 {
+		return nil, fmt.Errorf("cannot encode negative %v into bit string", n)
+	}
+
+	bitLen := max(n.BitLen(), 1)
+	byteLen := (bitLen + 7) / 8
+	// Shift left so the bits are aligned to the start of the first byte.
+	bytes := new(big.Int).Lsh(n, uint(byteLen*8-bitLen)).FillBytes(make([]byte, byteLen))
+
+	return plan.bitsPlan.Encode(Bits{Bytes: bytes, Len: int32(bitLen), Valid: true}, buf)
+}
+
 type encodePlanBitsCodecBinary struct{}
 
 func (encodePlanBitsCodecBinary) Encode(value any, buf []byte) (newBuf []byte, err error) {
@@ -127,19 +199,119 @@ func (encodePlanBitsCodecText) Encode(value any, buf []byte) (newBuf []byte, err
 }
 
 func (BitsCodec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan {
-
+	var bitsPlan ScanPlan
 	switch format {
 	case BinaryFormatCode:
-		switch target.(type) {
-		case BitsScanner:
-			return scanPlanBinaryBitsToBitsScanner{}
-		}
+		bitsPlan = scanPlanBinaryBitsToBitsScanner{}
 	case TextFormatCode:
-		switch target.(type) {
-		case BitsScanner:
-			return scanPlanTextAnyToBitsScanner{}
+		bitsPlan = scanPlanTextAnyToBitsScanner{}
+	default:
+		return nil
+	}
+
+	switch target.(type) {
+	case BitsScanner:
+		return bitsPlan
+	case *uint64:
+		return &scanPlanBitsCodecToUint64{bitsPlan: bitsPlan}
+	case *[]bool:
+		return &scanPlanBitsCodecToBoolSlice{bitsPlan: bitsPlan}
+	case *big.Int:
+		return &scanPlanBitsCodecToBigInt{bitsPlan: bitsPlan}
+	}
+
+	return nil
+}
+
+// scanBitsForConversion scans src with bitsPlan and checks that the result holds Len bits.
+func scanBitsForConversion(bitsPlan ScanPlan, src []byte) (Bits, error) {
+	var bits Bits
+	err := bitsPlan.Scan(src, &bits)
+	if err != nil {
+		return Bits{}, err
+	}
+
+	if bits.Len < 0 || len(bits.Bytes) != (int(bits.Len)+7)/8 {
+		return Bits{}, fmt.Errorf("invalid length for bit/varbit: %d bits in %d bytes", bits.Len, len(bits.Bytes))
+	}
+
+	return bits, nil
+}
+
+type scanPlanBitsCodecToUint64 struct {
+	bitsPlan ScanPlan
+}
+
+func (plan *scanPlanBitsCodecToUint64) Scan(src []byte, dst any) error {
+	if src == nil {
+		return fmt.Errorf("cannot scan NULL into %T", dst)
+	}
+
+	bits, err := scanBitsForConversion(plan.bitsPlan, src)
+	if err != nil {
+		return err
+	}
+
+	if bits.Len > 64 {
+		return fmt.Errorf("cannot scan bit string of length %d into %T", bits.Len, dst)
+	}
+
+	var n uint64
+	for i := int32(0); i < bits.Len; i++ {
+		n <<= 1
+		if bits.Bytes[i/8]&(128>>uint(i%8)) != 0 {
+			n |= 1
 		}
 	}
+	*dst.(*uint64) = n
+
+	return nil
+}
+
+type scanPlanBitsCodecToBoolSlice struct {
+	bitsPlan ScanPlan
+}
+
+func (plan *scanPlanBitsCodecToBoolSlice) Scan(src []byte, dst any) error {
+	p := dst.(*[]bool)
+
+	if src == nil {
+		*p = nil
+		return nil
+	}
+
+	bits, err := scanBitsForConversion(plan.bitsPlan, src)
+	if err != nil {
+		return err
+	}
+
+	bools := make([]bool, bits.Len)
+	for i := range bools {
+		bools[i] = bits.Bytes[i/8]&(128>>uint(i%8)) != 0
+	}
+	*p = bools
+
+	return nil
+}
+
+type scanPlanBitsCodecToBigInt struct {
+	bitsPlan ScanPlan
+}
+
+func (plan *scanPlanBitsCodecToBigInt) Scan(src []byte, dst any) error {
+	if src == nil {
+		return fmt.Errorf("cannot scan NULL into %T", dst)
+	}
+
+	bits, err := scanBitsForConversion(plan.bitsPlan, src)
+	if err != nil {
+		return err
+	}
+
+	// The bits are aligned to the start of the first byte. Shift right to drop the padding in the last byte.
+	n := dst.(*big.Int)
+	n.SetBytes(bits.Bytes)
+	n.Rsh(n, uint(len(bits.Bytes)*8-int(bits.Len)))
 
 	return nil
 }
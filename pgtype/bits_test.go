@@ -3,10 +3,12 @@ package pgtype_test
 import (
 	"bytes"
 	"context"
+	"math/big"
 	"testing"
 
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
 )
 
 func isExpectedEqBits(a any) func(any) bool {
@@ -55,3 +57,86 @@ func TestBitsCodecVarbit(t *testing.T) {
 		{nil, new(pgtype.Bits), isExpectedEqBits(pgtype.Bits{})},
 	})
 }
+
+func TestBitsCodecGoTypes(t *testing.T) {
+	pgxtest.RunValueRoundTripTests(context.Background(), t, defaultConnTestRunner, nil, "varbit", []pgxtest.ValueRoundTripTest{
+		{uint64(0), new(uint64), isExpectedEq(uint64(0))},
+		{uint64(0x8000000000000001), new(uint64), isExpectedEq(uint64(0x8000000000000001))},
+		{[]bool{true, false, true, true, false, false, false, false, true}, new([]bool), isExpectedEqBoolSlice([]bool{true, false, true, true, false, false, false, false, true})},
+		{[]bool{}, new([]bool), isExpectedEqBoolSlice([]bool{})},
+		{big.NewInt(0), new(big.Int), isExpectedEqBigInt(big.NewInt(0))},
+		{big.NewInt(5), new(big.Int), isExpectedEqBigInt(big.NewInt(5))},
+		{new(big.Int).Lsh(big.NewInt(3), 100), new(big.Int), isExpectedEqBigInt(new(big.Int).Lsh(big.NewInt(3), 100))},
+	})
+}
+
+func isExpectedEqBoolSlice(a []bool) func(any) bool {
+	return func(v any) bool {
+		vb := v.([]bool)
+		if len(a) != len(vb) {
+			return false
+		}
+		for i := range a {
+			if a[i] != vb[i] {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func isExpectedEqBigInt(a *big.Int) func(any) bool {
+	return func(v any) bool {
+		vb := v.(big.Int)
+		return a.Cmp(&vb) == 0
+	}
+}
+
+func TestBitsCodecGoTypesWithoutServer(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+
+	for _, format := range []int16{pgtype.TextFormatCode, pgtype.BinaryFormatCode} {
+		buf, err := m.Encode(pgtype.VarbitOID, format, []bool{true, false, true}, nil)
+		require.NoError(t, err)
+
+		var n uint64
+		err = m.Scan(pgtype.VarbitOID, format, buf, &n)
+		require.NoError(t, err)
+		require.EqualValues(t, 5, n)
+
+		var bi big.Int
+		err = m.Scan(pgtype.VarbitOID, format, buf, &bi)
+		require.NoError(t, err)
+		require.EqualValues(t, 5, bi.Int64())
+
+		buf, err = m.Encode(pgtype.VarbitOID, format, big.NewInt(6), nil)
+		require.NoError(t, err)
+
+		var bools []bool
+		err = m.Scan(pgtype.VarbitOID, format, buf, &bools)
+		require.NoError(t, err)
+		require.Equal(t, []bool{true, true, false}, bools)
+
+		buf, err = m.Encode(pgtype.VarbitOID, format, uint64(1), nil)
+		require.NoError(t, err)
+
+		err = m.Scan(pgtype.VarbitOID, format, buf, &bools)
+		require.NoError(t, err)
+		require.Len(t, bools, 64)
+		require.True(t, bools[63])
+
+		_, err = m.Encode(pgtype.VarbitOID, format, big.NewInt(-1), nil)
+		require.Error(t, err)
+	}
+
+	var n uint64
+	err := m.Scan(pgtype.VarbitOID, pgtype.TextFormatCode, bytes.Repeat([]byte("1"), 65), &n)
+	require.EqualError(t, err, "cannot scan bit string of length 65 into *uint64")
+
+	bools := []bool{true}
+	err = m.Scan(pgtype.VarbitOID, pgtype.TextFormatCode, nil, &bools)
+	require.NoError(t, err)
+	require.Nil(t, bools)
+}
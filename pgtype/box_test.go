@@ -34,6 +34,7 @@ func TestBoxCodec(t *testing.T) {
 				Valid: true,
 			}),
 		},
+		{[2][2]float64{{7.1, 5.2345678}, {-13.14, -5.234}}, new([2][2]float64), isExpectedEq([2][2]float64{{7.1, 5.2345678}, {-13.14, -5.234}})},
 		{pgtype.Box{}, new(pgtype.Box), isExpectedEq(pgtype.Box{})},
 		{nil, new(pgtype.Box), isExpectedEq(pgtype.Box{})},
 	})
@@ -707,6 +707,127 @@ func (w byteSliceWrapper) UUIDValue() (UUID, error) {
 	return uuid, nil
 }
 
+// float64Array2Wrapper maps a [2]float64 of x and y to a point.
+type float64Array2Wrapper [2]float64
+
+func (w *float64Array2Wrapper) ScanPoint(v Point) error {
+	if !v.Valid {
+		return fmt.Errorf("cannot scan NULL into *[2]float64")
+	}
+	*w = float64Array2Wrapper{v.P.X, v.P.Y}
+	return nil
+}
+
+func (w float64Array2Wrapper) PointValue() (Point, error) {
+	return Point{P: Vec2{w[0], w[1]}, Valid: true}, nil
+}
+
+// float64Array3Wrapper maps a [3]float64 to a line as A, B, and C or to a circle as x, y, and radius.
+type float64Array3Wrapper [3]float64
+
+func (w *float64Array3Wrapper) ScanLine(v Line) error {
+	if !v.Valid {
+		return fmt.Errorf("cannot scan NULL into *[3]float64")
+	}
+	*w = float64Array3Wrapper{v.A, v.B, v.C}
+	return nil
+}
+
+func (w float64Array3Wrapper) LineValue() (Line, error) {
+	return Line{A: w[0], B: w[1], C: w[2], Valid: true}, nil
+}
+
+func (w *float64Array3Wrapper) ScanCircle(v Circle) error {
+	if !v.Valid {
+		return fmt.Errorf("cannot scan NULL into *[3]float64")
+	}
+	*w = float64Array3Wrapper{v.P.X, v.P.Y, v.R}
+	return nil
+}
+
+func (w float64Array3Wrapper) CircleValue() (Circle, error) {
+	return Circle{P: Vec2{w[0], w[1]}, R: w[2], Valid: true}, nil
+}
+
+// float64Array2x2Wrapper maps a [2][2]float64 of two x and y pairs to a box or lseg.
+type float64Array2x2Wrapper [2][2]float64
+
+func (w *float64Array2x2Wrapper) ScanBox(v Box) error {
+	if !v.Valid {
+		return fmt.Errorf("cannot scan NULL into *[2][2]float64")
+	}
+	*w = float64Array2x2Wrapper{{v.P[0].X, v.P[0].Y}, {v.P[1].X, v.P[1].Y}}
+	return nil
+}
+
+func (w float64Array2x2Wrapper) BoxValue() (Box, error) {
+	return Box{P: [2]Vec2{{w[0][0], w[0][1]}, {w[1][0], w[1][1]}}, Valid: true}, nil
+}
+
+func (w *float64Array2x2Wrapper) ScanLseg(v Lseg) error {
+	if !v.Valid {
+		return fmt.Errorf("cannot scan NULL into *[2][2]float64")
+	}
+	*w = float64Array2x2Wrapper{{v.P[0].X, v.P[0].Y}, {v.P[1].X, v.P[1].Y}}
+	return nil
+}
+
+func (w float64Array2x2Wrapper) LsegValue() (Lseg, error) {
+	return Lseg{P: [2]Vec2{{w[0][0], w[0][1]}, {w[1][0], w[1][1]}}, Valid: true}, nil
+}
+
+// float64Array2SliceWrapper maps a [][2]float64 of x and y pairs to a path or polygon. A path encoded from a
+// [][2]float64 is open. Whether a scanned path is open or closed is discarded.
+type float64Array2SliceWrapper [][2]float64
+
+func (w *float64Array2SliceWrapper) ScanPath(v Path) error {
+	if !v.Valid {
+		*w = nil
+		return nil
+	}
+	*w = vec2sToFloat64Array2Slice(v.P)
+	return nil
+}
+
+func (w float64Array2SliceWrapper) PathValue() (Path, error) {
+	if w == nil {
+		return Path{}, nil
+	}
+	return Path{P: float64Array2SliceToVec2s(w), Valid: true}, nil
+}
+
+func (w *float64Array2SliceWrapper) ScanPolygon(v Polygon) error {
+	if !v.Valid {
+		*w = nil
+		return nil
+	}
+	*w = vec2sToFloat64Array2Slice(v.P)
+	return nil
+}
+
+func (w float64Array2SliceWrapper) PolygonValue() (Polygon, error) {
+	if w == nil {
+		return Polygon{}, nil
+	}
+	return Polygon{P: float64Array2SliceToVec2s(w), Valid: true}, nil
+}
+
+func vec2sToFloat64Array2Slice(src []Vec2) float64Array2SliceWrapper {
+	dst := make(float64Array2SliceWrapper, len(src))
+	for i, p := range src {
+		dst[i] = [2]float64{p.X, p.Y}
+	}
+	return dst
+}
+
+func float64Array2SliceToVec2s(src float64Array2SliceWrapper) []Vec2 {
+	dst := make([]Vec2, len(src))
+	for i, p := range src {
+		dst[i] = Vec2{p[0], p[1]}
+	}
+	return dst
+}
+
 // structWrapper implements CompositeIndexGetter for a struct.
 type structWrapper struct {
 	s              any
@@ -719,7 +840,7 @@ func (w structWrapper) IsNull() bool {
 
 func (w structWrapper) Index(i int) any {
 	if i >= len(w.exportedFields) {
-		return fmt.Errorf("%#v only has %d public fields - %d is out of bounds", w.s, len(w.exportedFields), i)
+		return &compositeIndexScanError{err: fmt.Errorf("%#v only has %d public fields - %d is out of bounds", w.s, len(w.exportedFields), i)}
 	}
 
 	return w.exportedFields[i].Interface()
@@ -737,7 +858,7 @@ func (w *ptrStructWrapper) ScanNull() error {
 
 func (w *ptrStructWrapper) ScanIndex(i int) any {
 	if i >= len(w.exportedFields) {
-		return fmt.Errorf("%#v only has %d public fields - %d is out of bounds", w.s, len(w.exportedFields), i)
+		return &compositeIndexScanError{err: fmt.Errorf("%#v only has %d public fields - %d is out of bounds", w.s, len(w.exportedFields), i)}
 	}
 
 	return w.exportedFields[i].Addr().Interface()
@@ -785,6 +906,89 @@ func (a *anySliceArrayReflect) ScanIndexType() any {
 	return reflect.New(a.slice.Type().Elem()).Interface()
 }
 
+// The following methods allow a plain slice such as []Range[T] to be used as a multirange.
+
+func (a anySliceArrayReflect) IsNull() bool {
+	return a.slice.IsNil()
+}
+
+func (a anySliceArrayReflect) Len() int {
+	return a.slice.Len()
+}
+
+func (a *anySliceArrayReflect) ScanNull() error {
+	a.slice.Set(reflect.Zero(a.slice.Type()))
+	return nil
+}
+
+func (a *anySliceArrayReflect) SetLen(n int) error {
+	a.slice.Set(reflect.MakeSlice(a.slice.Type(), n, n))
+	return nil
+}
+
+// isMapSetType returns true if t is a map type used as a set. i.e. map[T]struct{} or map[T]bool.
+func isMapSetType(t reflect.Type) bool {
+	elemType := t.Elem()
+	return elemType.Kind() == reflect.Bool || (elemType.Kind() == reflect.Struct && elemType.NumField() == 0)
+}
+
+// anyMapSetArrayReflect scans an array into a map used as a set. ArraySetter has no notification when an element has
+// been scanned so each element is scanned into the same value and added to the map when ScanIndex is called for the
+// next element. addPending must be called after the array has been scanned to add the last element.
+type anyMapSetArrayReflect struct {
+	m       reflect.Value
+	present reflect.Value
+	elem    reflect.Value
+	index   int
+}
+
+func newAnyMapSetArrayReflect(m reflect.Value) *anyMapSetArrayReflect {
+	a := &anyMapSetArrayReflect{m: m, index: -1}
+
+	if valueType := m.Type().Elem(); valueType.Kind() == reflect.Bool {
+		a.present = reflect.ValueOf(true).Convert(valueType)
+	} else {
+		a.present = reflect.Zero(valueType)
+	}
+
+	return a
+}
+
+func (a *anyMapSetArrayReflect) SetDimensions(dimensions []ArrayDimension) error {
+	mapType := a.m.Type()
+
+	a.index = -1
+
+	if dimensions == nil {
+		a.m.Set(reflect.Zero(mapType))
+		return nil
+	}
+
+	a.m.Set(reflect.MakeMapWithSize(mapType, cardinality(dimensions)))
+	a.elem = reflect.New(mapType.Key())
+	return nil
+}
+
+func (a *anyMapSetArrayReflect) ScanIndex(i int) any {
+	if i != a.index {
+		a.addPending()
+		a.index = i
+	}
+	return a.elem.Interface()
+}
+
+func (a *anyMapSetArrayReflect) ScanIndexType() any {
+	return reflect.New(a.m.Type().Key()).Interface()
+}
+
+// addPending adds the most recently scanned element to the map.
+func (a *anyMapSetArrayReflect) addPending() {
+	if a.index >= 0 {
+		a.m.SetMapIndex(a.elem.Elem(), a.present)
+		a.index = -1
+	}
+}
+
 type anyMultiDimSliceArray struct {
 	slice reflect.Value
 	dims  []ArrayDimension
@@ -2,6 +2,7 @@ package pgtype
 
 import (
 	"database/sql/driver"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 )
@@ -183,6 +184,50 @@ func (scanPlanBinaryBytesToBytes) Scan(src []byte, dst any) error {
 	return nil
 }
 
+// scanPlanBinaryByteaArrayToBytesSlice scans a bytea[] into a *[][]byte. Multi-dimensional arrays are flattened.
+type scanPlanBinaryByteaArrayToBytesSlice struct{}
+
+func (scanPlanBinaryByteaArrayToBytesSlice) Scan(src []byte, dst any) error {
+	dstSlice := dst.(*[][]byte)
+	if src == nil {
+		*dstSlice = nil
+		return nil
+	}
+
+	var arrayHeader arrayHeader
+	rp, err := arrayHeader.DecodeBinary(nil, src)
+	if err != nil {
+		return err
+	}
+
+	slice := make([][]byte, cardinality(arrayHeader.Dimensions))
+
+	// All elements share one allocation. The capacity of each element is limited to its length so appending to an
+	// element cannot overwrite the next one.
+	buf := make([]byte, 0, len(src)-rp)
+	for i := range slice {
+		if len(src[rp:]) < 4 {
+			return fmt.Errorf("array too short for element %d", i)
+		}
+		elemLen := int(int32(binary.BigEndian.Uint32(src[rp:])))
+		rp += 4
+		if elemLen < 0 {
+			continue
+		}
+		if len(src[rp:]) < elemLen {
+			return fmt.Errorf("array too short for element %d", i)
+		}
+
+		start := len(buf)
+		buf = append(buf, src[rp:rp+elemLen]...)
+		slice[i] = buf[start:len(buf):len(buf)]
+		rp += elemLen
+	}
+
+	*dstSlice = slice
+	return nil
+}
+
 type scanPlanBinaryBytesToBytesScanner struct{}
 
 func (scanPlanBinaryBytesToBytesScanner) Scan(src []byte, dst any) error {
@@ -38,6 +38,32 @@ func TestByteaCodec(t *testing.T) {
 	})
 }
 
+func TestByteaArrayCodecScanBytesSliceWithoutDatabase(t *testing.T) {
+	m := pgtype.NewMap()
+
+	buf, err := m.Encode(pgtype.ByteaArrayOID, pgtype.BinaryFormatCode, [][]byte{{1, 2, 3}, {}, nil, {4, 5}}, nil)
+	require.NoError(t, err)
+
+	var slice [][]byte
+	err = m.Scan(pgtype.ByteaArrayOID, pgtype.BinaryFormatCode, buf, &slice)
+	require.NoError(t, err)
+	require.Len(t, slice, 4)
+	require.True(t, isExpectedEqBytes([]byte{1, 2, 3})(slice[0]))
+	require.True(t, isExpectedEqBytes([]byte{})(slice[1]))
+	require.True(t, isExpectedEqBytes([]byte(nil))(slice[2]))
+	require.True(t, isExpectedEqBytes([]byte{4, 5})(slice[3]))
+
+	// Elements do not alias each other or the source.
+	slice[0] = append(slice[0], 9)
+	require.Equal(t, []byte{4, 5}, slice[3])
+	buf[len(buf)-1] = 0
+	require.Equal(t, []byte{4, 5}, slice[3])
+
+	err = m.Scan(pgtype.ByteaArrayOID, pgtype.BinaryFormatCode, nil, &slice)
+	require.NoError(t, err)
+	require.Nil(t, slice)
+}
+
 func TestDriverBytesQueryRow(t *testing.T) {
 	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
 		var buf []byte
@@ -22,6 +22,7 @@ func TestCircleTranscode(t *testing.T) {
 			new(pgtype.Circle),
 			isExpectedEq(pgtype.Circle{P: pgtype.Vec2{1.234, 5.67890123}, R: 3.5, Valid: true}),
 		},
+		{[3]float64{1.234, 5.67890123, 3.5}, new([3]float64), isExpectedEq([3]float64{1.234, 5.67890123, 3.5})},
 		{pgtype.Circle{}, new(pgtype.Circle), isExpectedEq(pgtype.Circle{})},
 		{nil, new(pgtype.Circle), isExpectedEq(pgtype.Circle{})},
 	})
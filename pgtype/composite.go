@@ -1,10 +1,12 @@
 package pgtype
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/jackc/pgx/v5/internal/pgio"
@@ -33,6 +35,12 @@ type CompositeCodecField struct {
 	Type *Type
 }
 
+// CompositeCodec is the codec for PostgreSQL composite types. Values that implement CompositeIndexGetter and
+// CompositeIndexScanner are supported. Structs are supported by mapping the fields of the struct to the attributes of
+// the composite type. If every attribute matches a struct field by name, the fields are mapped by name with the same
+// rules as pgx.RowToStructByName. See StructFields and Map.NamingStrategy. Otherwise, the exported fields of the struct
+// are mapped to the attributes by position. Unexported fields are skipped. Attributes that may be NULL can be scanned
+// into pointer fields, sql.Null* fields, or nullable pgtype fields such as pgtype.Int4.
 type CompositeCodec struct {
 	Fields []CompositeCodecField
 }
@@ -56,7 +64,20 @@ func (c *CompositeCodec) PreferredFormat() int16 {
 
 func (c *CompositeCodec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
 	if _, ok := value.(CompositeIndexGetter); !ok {
-		return nil
+		if _, ok := value.(driver.Valuer); ok {
+			return nil
+		}
+
+		fieldIndexes := c.namedStructFieldIndexes(m, reflect.TypeOf(value))
+		if fieldIndexes == nil {
+			return nil
+		}
+
+		next := c.PlanEncode(m, oid, format, namedStructCompositeGetter{})
+		if next == nil {
+			return nil
+		}
+		return &encodePlanNamedStructToComposite{fieldIndexes: fieldIndexes, next: next}
 	}
 
 	switch format {
@@ -110,6 +131,20 @@ func (plan *encodePlanCompositeCodecCompositeIndexGetterToText) Encode(value any
 }
 
 func (c *CompositeCodec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan {
+	switch target.(type) {
+	case CompositeIndexScanner, TextScanner, sql.Scanner:
+	default:
+		if targetType := reflect.TypeOf(target); targetType != nil && targetType.Kind() == reflect.Ptr {
+			if fieldIndexes := c.namedStructFieldIndexes(m, targetType.Elem()); fieldIndexes != nil {
+				next := c.PlanScan(m, oid, format, &namedStructCompositeScanner{})
+				if next == nil {
+					return nil
+				}
+				return &scanPlanCompositeToNamedStruct{fieldIndexes: fieldIndexes, next: next}
+			}
+		}
+	}
+
 	switch format {
 	case BinaryFormatCode:
 		switch target.(type) {
@@ -142,15 +177,18 @@ func (plan *scanPlanBinaryCompositeToCompositeIndexScanner) Scan(src []byte, tar
 	for i, field := range plan.cc.Fields {
 		if scanner.Next() {
 			fieldTarget := targetScanner.ScanIndex(i)
+			if scanErr, ok := fieldTarget.(*compositeIndexScanError); ok {
+				return scanErr.err
+			}
 			if fieldTarget != nil {
 				fieldPlan := plan.m.PlanScan(field.Type.OID, BinaryFormatCode, fieldTarget)
 				if fieldPlan == nil {
-					return fmt.Errorf("unable to encode %v into OID %d in binary format", field, field.Type.OID)
+					return fmt.Errorf("unable to scan composite field %s (OID %d) in binary format into %T", field.Name, field.Type.OID, fieldTarget)
 				}
 
 				err := fieldPlan.Scan(scanner.Bytes(), fieldTarget)
 				if err != nil {
-					return err
+					return fmt.Errorf("composite field %s: %w", field.Name, err)
 				}
 			}
 		} else {
@@ -165,6 +203,13 @@ func (plan *scanPlanBinaryCompositeToCompositeIndexScanner) Scan(src []byte, tar
 	return nil
 }
 
+// compositeIndexScanError is returned by ScanIndex of a CompositeIndexScanner implemented by this package instead of a
+// scan target when the target cannot hold the field, e.g. when a struct has fewer exported fields than the composite
+// type has attributes. A dedicated type is used as a scan target may itself implement error.
+type compositeIndexScanError struct {
+	err error
+}
+
 type scanPlanTextCompositeToCompositeIndexScanner struct {
 	cc *CompositeCodec
 	m  *Map
@@ -181,15 +226,18 @@ func (plan *scanPlanTextCompositeToCompositeIndexScanner) Scan(src []byte, targe
 	for i, field := range plan.cc.Fields {
 		if scanner.Next() {
 			fieldTarget := targetScanner.ScanIndex(i)
+			if scanErr, ok := fieldTarget.(*compositeIndexScanError); ok {
+				return scanErr.err
+			}
 			if fieldTarget != nil {
 				fieldPlan := plan.m.PlanScan(field.Type.OID, TextFormatCode, fieldTarget)
 				if fieldPlan == nil {
-					return fmt.Errorf("unable to encode %v into OID %d in text format", field, field.Type.OID)
+					return fmt.Errorf("unable to scan composite field %s (OID %d) in text format into %T", field.Name, field.Type.OID, fieldTarget)
 				}
 
 				err := fieldPlan.Scan(scanner.Bytes(), fieldTarget)
 				if err != nil {
-					return err
+					return fmt.Errorf("composite field %s: %w", field.Name, err)
 				}
 			}
 		} else {
@@ -204,6 +252,79 @@ func (plan *scanPlanTextCompositeToCompositeIndexScanner) Scan(src []byte, targe
 	return nil
 }
 
+// namedStructFieldIndexes returns the field index sequence of struct type t for each attribute of c. It returns nil if t
+// is not a struct or if any attribute does not match a field by name.
+func (c *CompositeCodec) namedStructFieldIndexes(m *Map, t reflect.Type) [][]int {
+	if t == nil || t.Kind() != reflect.Struct || len(c.Fields) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(c.Fields))
+	for i, f := range c.Fields {
+		names[i] = f.Name
+	}
+
+	fields := StructFields(t)
+	indexes, ok := matchStructFields(fields, names, m.NamingStrategy)
+	if !ok {
+		return nil
+	}
+
+	fieldIndexes := make([][]int, len(indexes))
+	for i, idx := range indexes {
+		fieldIndexes[i] = fields[idx].Index
+	}
+	return fieldIndexes
+}
+
+// namedStructCompositeGetter implements CompositeIndexGetter for a struct whose fields are mapped to composite
+// attributes by name.
+type namedStructCompositeGetter struct {
+	v            reflect.Value
+	fieldIndexes [][]int
+}
+
+func (g namedStructCompositeGetter) IsNull() bool {
+	return false
+}
+
+func (g namedStructCompositeGetter) Index(i int) any {
+	return g.v.FieldByIndex(g.fieldIndexes[i]).Interface()
+}
+
+type encodePlanNamedStructToComposite struct {
+	fieldIndexes [][]int
+	next         EncodePlan
+}
+
+func (plan *encodePlanNamedStructToComposite) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	return plan.next.Encode(namedStructCompositeGetter{v: reflect.ValueOf(value), fieldIndexes: plan.fieldIndexes}, buf)
+}
+
+// namedStructCompositeScanner implements CompositeIndexScanner for a pointer to a struct whose fields are mapped to
+// composite attributes by name.
+type namedStructCompositeScanner struct {
+	ptr          any
+	fieldIndexes [][]int
+}
+
+func (s *namedStructCompositeScanner) ScanNull() error {
+	return fmt.Errorf("cannot scan NULL into %#v", s.ptr)
+}
+
+func (s *namedStructCompositeScanner) ScanIndex(i int) any {
+	return reflect.ValueOf(s.ptr).Elem().FieldByIndex(s.fieldIndexes[i]).Addr().Interface()
+}
+
+type scanPlanCompositeToNamedStruct struct {
+	fieldIndexes [][]int
+	next         ScanPlan
+}
+
+func (plan *scanPlanCompositeToNamedStruct) Scan(src []byte, target any) error {
+	return plan.next.Scan(src, &namedStructCompositeScanner{ptr: target, fieldIndexes: plan.fieldIndexes})
+}
+
 func (c *CompositeCodec) DecodeDatabaseSQLValue(m *Map, oid uint32, format int16, src []byte) (driver.Value, error) {
 	if src == nil {
 		return nil, nil
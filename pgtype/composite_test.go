@@ -2,8 +2,10 @@ package pgtype_test
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"testing"
+	"time"
 
 	pgx "github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -253,3 +255,167 @@ create table point3d (
 		}
 	})
 }
+
+type nullableCompositeInner struct {
+	X int32
+}
+
+type nullableComposite struct {
+	A        *int32
+	B        sql.NullString
+	internal string
+	C        pgtype.Int8
+	D        *nullableCompositeInner
+	E        sql.NullTime
+}
+
+func TestCompositeCodecNullableStructFields(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+	int4Type, _ := m.TypeForName("int4")
+	int8Type, _ := m.TypeForName("int8")
+	textType, _ := m.TypeForName("text")
+	timestamptzType, _ := m.TypeForName("timestamptz")
+	innerType := &pgtype.Type{Name: "nullable_composite_inner", OID: 1000001, Codec: &pgtype.CompositeCodec{
+		Fields: []pgtype.CompositeCodecField{{Name: "x", Type: int4Type}},
+	}}
+	m.RegisterType(innerType)
+	m.RegisterType(&pgtype.Type{Name: "nullable_composite", OID: 1000000, Codec: &pgtype.CompositeCodec{
+		Fields: []pgtype.CompositeCodecField{
+			{Name: "a", Type: int4Type},
+			{Name: "b", Type: textType},
+			{Name: "c", Type: int8Type},
+			{Name: "d", Type: innerType},
+			{Name: "e", Type: timestamptzType},
+		},
+	}})
+
+	a := int32(1)
+	e := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, format := range []int16{pgtype.TextFormatCode, pgtype.BinaryFormatCode} {
+		for _, input := range []nullableComposite{
+			{},
+			{
+				A: &a,
+				B: sql.NullString{String: "foo", Valid: true},
+				C: pgtype.Int8{Int64: 3, Valid: true},
+				D: &nullableCompositeInner{X: 4},
+				E: sql.NullTime{Time: e, Valid: true},
+			},
+		} {
+			buf, err := m.Encode(1000000, format, input, nil)
+			require.NoError(t, err)
+
+			output := nullableComposite{internal: "unchanged"}
+			err = m.Scan(1000000, format, buf, &output)
+			require.NoError(t, err)
+			output.E.Time = output.E.Time.UTC()
+			input.internal = "unchanged"
+			require.Equal(t, input, output)
+		}
+	}
+}
+
+func TestCompositeCodecStructFieldErrors(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+	int4Type, _ := m.TypeForName("int4")
+	m.RegisterType(&pgtype.Type{Name: "two_ints", OID: 1000000, Codec: &pgtype.CompositeCodec{
+		Fields: []pgtype.CompositeCodecField{{Name: "a", Type: int4Type}, {Name: "b", Type: int4Type}},
+	}})
+
+	var notNullable struct {
+		A int32
+		B int32
+	}
+	err := m.Scan(1000000, pgtype.TextFormatCode, []byte("(1,)"), &notNullable)
+	require.ErrorContains(t, err, "composite field b: cannot scan NULL into *int32")
+
+	var tooFewFields struct {
+		A int32
+	}
+	err = m.Scan(1000000, pgtype.TextFormatCode, []byte("(1,2)"), &tooFewFields)
+	require.ErrorContains(t, err, "only has 1 public fields - 1 is out of bounds")
+
+	// A field whose pointer implements error is a scan target like any other field.
+	var errorField struct {
+		A int32
+		B compositeErrorField
+	}
+	err = m.Scan(1000000, pgtype.TextFormatCode, []byte("(1,2)"), &errorField)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, errorField.B)
+}
+
+type compositeErrorField int32
+
+func (f *compositeErrorField) Error() string {
+	return fmt.Sprintf("error %d", *f)
+}
+
+func TestCompositeCodecStructFieldsByName(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+	int4Type, _ := m.TypeForName("int4")
+	textType, _ := m.TypeForName("text")
+	m.RegisterType(&pgtype.Type{Name: "named_composite", OID: 1000000, Codec: &pgtype.CompositeCodec{
+		Fields: []pgtype.CompositeCodecField{
+			{Name: "user_id", Type: int4Type},
+			{Name: "full_name", Type: textType},
+		},
+	}})
+
+	type namedComposite struct {
+		Ignored  string `db:"-"`
+		Name     string `db:"full_name"`
+		UserID   int32
+		Internal string
+	}
+
+	for _, format := range []int16{pgtype.TextFormatCode, pgtype.BinaryFormatCode} {
+		input := namedComposite{Ignored: "x", Name: "foo", UserID: 42, Internal: "y"}
+		buf, err := m.Encode(1000000, format, input, nil)
+		require.NoError(t, err)
+
+		var check struct {
+			A int32
+			B string
+		}
+		err = m.Scan(1000000, format, buf, &check)
+		require.NoError(t, err)
+		require.Equal(t, int32(42), check.A)
+		require.Equal(t, "foo", check.B)
+
+		var output namedComposite
+		err = m.Scan(1000000, format, buf, &output)
+		require.NoError(t, err)
+		require.Equal(t, namedComposite{Name: "foo", UserID: 42}, output)
+	}
+}
+
+func TestCompositeCodecStructFieldsNamingStrategy(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+	m.NamingStrategy = pgtype.CamelCaseNamingStrategy
+	int4Type, _ := m.TypeForName("int4")
+	m.RegisterType(&pgtype.Type{Name: "camel_composite", OID: 1000000, Codec: &pgtype.CompositeCodec{
+		Fields: []pgtype.CompositeCodecField{
+			{Name: "userID", Type: int4Type},
+			{Name: "groupID", Type: int4Type},
+		},
+	}})
+
+	type camelComposite struct {
+		GroupID int32
+		UserID  int32
+	}
+
+	var output camelComposite
+	err := m.Scan(1000000, pgtype.TextFormatCode, []byte("(1,2)"), &output)
+	require.NoError(t, err)
+	require.Equal(t, camelComposite{GroupID: 2, UserID: 1}, output)
+}
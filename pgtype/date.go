@@ -40,6 +40,13 @@ const (
 	infinityDayOffset         = 2147483647
 )
 
+// minDateSecSinceDateEpoch and maxDateSecSinceDateEpoch are the range of PostgreSQL dates (4714-11-24 BC to
+// 5874897-12-31) in seconds since 2000-01-01.
+const (
+	minDateSecSinceDateEpoch = -2451545 * 86400
+	maxDateSecSinceDateEpoch = 2145031948 * 86400
+)
+
 // Scan implements the database/sql Scanner interface.
 func (dst *Date) Scan(src any) error {
 	if src == nil {
@@ -135,9 +142,9 @@ func (DateCodec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodeP
 
 	switch format {
 	case BinaryFormatCode:
-		return encodePlanDateCodecBinary{}
+		return wrapInfinityTimeEncodePlan(m, value, encodePlanDateCodecBinary{})
 	case TextFormatCode:
-		return encodePlanDateCodecText{}
+		return wrapInfinityTimeEncodePlan(m, value, encodePlanDateCodecText{})
 	}
 
 	return nil
@@ -162,6 +169,9 @@ func (encodePlanDateCodecBinary) Encode(value any, buf []byte) (newBuf []byte, e
 		dateEpoch := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
 
 		secSinceDateEpoch := tUnix - dateEpoch
+		if secSinceDateEpoch < minDateSecSinceDateEpoch || secSinceDateEpoch > maxDateSecSinceDateEpoch {
+			return nil, fmt.Errorf("date out of range: %v", date.Time)
+		}
 		daysSinceDateEpoch = int32(secSinceDateEpoch / 86400)
 	case Infinity:
 		daysSinceDateEpoch = infinityDayOffset
@@ -228,12 +238,12 @@ func (DateCodec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan
 	case BinaryFormatCode:
 		switch target.(type) {
 		case DateScanner:
-			return scanPlanBinaryDateToDateScanner{}
+			return wrapInfinityTimeScanPlan(m, target, scanPlanBinaryDateToDateScanner{})
 		}
 	case TextFormatCode:
 		switch target.(type) {
 		case DateScanner:
-			return scanPlanTextAnyToDateScanner{}
+			return wrapInfinityTimeScanPlan(m, target, scanPlanTextAnyToDateScanner{})
 		}
 	}
 
@@ -39,6 +39,9 @@ pgtype maps between all common base types directly between Go and PostgreSQL. In
     netip.Addr    inet
     netip.Prefix  cidr
 
+    net.HardwareAddr  macaddr
+                      macaddr8
+
     []byte        bytea
 
 Null Values
@@ -68,6 +71,15 @@ pgtype.Point and application can directly use its own point type with pgtype as
 
 See example_custom_type_test.go for an example of a custom type for the PostgreSQL point type.
 
+The geometric types can also be used with plain float64 arrays and slices without depending on pgtype types:
+
+    [2]float64     point (x, y)
+    [3]float64     line (A, B, C), circle (x, y, radius)
+    [2][2]float64  box, lseg
+    [][2]float64   path, polygon
+
+A path encoded from a [][2]float64 is open. Whether a path scanned into a [][2]float64 was open or closed is discarded.
+
 Sometimes pgx supports a PostgreSQL type such as numeric but the Go type is in an external package that does not have
 pgx support such as github.com/shopspring/decimal. These types can be registered with pgtype with custom conversion
 logic. See https://github.com/jackc/pgx-shopspring-decimal and https://github.com/jackc/pgx-gofrs-uuid for example
@@ -107,13 +119,31 @@ A type cannot be registered unless all types it depends on are already registere
 registered until its element type is registered.
 
 ArrayCodec implements support for arrays. If pgtype supports type T then it can easily support []T by registering an
-ArrayCodec for the appropriate PostgreSQL OID. In addition, Array[T] type can support multi-dimensional arrays.
+ArrayCodec for the appropriate PostgreSQL OID. In addition, Array[T] type can support multi-dimensional arrays. Arrays
+can also be scanned into maps used as sets, i.e. map[T]struct{} or map[T]bool. Each element becomes a key of the map.
+ArrayValuerFunc allows encoding a one-dimensional array from an iterator without collecting its elements in a slice.
+
+VectorCodec, HalfVectorCodec, and SparseVectorCodec implement support for the vector, halfvec, and sparsevec types of the
+pgvector extension. Use pgx.RegisterVectorTypes to register them.
 
-CompositeCodec implements support for PostgreSQL composite types. Go structs can be scanned into if the public fields of
-the struct are in the exact order and type of the PostgreSQL type or by implementing CompositeIndexScanner and
-CompositeIndexGetter.
+CompositeCodec implements support for PostgreSQL composite types. Go structs can be scanned into if every attribute of
+the PostgreSQL type matches a public field of the struct by name, if the public fields of the struct are in the exact
+order and type of the PostgreSQL type, or by implementing CompositeIndexScanner and CompositeIndexGetter.
 
-Domain types are treated as their underlying type if the underlying type and the domain type are registered.
+Struct Mapping
+
+Struct fields are matched with composite attributes by CompositeCodec and with result columns by
+pgx.RowToStructByName and similar functions using the same rules. StructFields returns the fields that can be matched:
+exported fields, including the fields of embedded structs, that are not tagged with db:"-". A field with a "db" struct
+tag only matches the tag name exactly. Other fields are matched by the NamingStrategy set in Map.NamingStrategy. The
+default is DefaultNamingStrategy, which is case-insensitive and ignores underscores. SnakeCaseNamingStrategy and
+CamelCaseNamingStrategy match exact snake_case and camelCase names. A custom strategy can be provided with
+NamingStrategyFunc.
+
+    conn.TypeMap().NamingStrategy = pgtype.SnakeCaseNamingStrategy
+
+Domain types are treated as their underlying type if the underlying type and the domain type are registered. The
+pgx.Conn LoadType method loads the underlying type of a domain automatically if it is not already registered.
 
 PostgreSQL enums can usually be treated as text. However, EnumCodec implements support for interning strings which can
 reduce memory usage.
@@ -142,6 +172,12 @@ Compatibility with database/sql
 pgtype also includes support for custom types implementing the database/sql.Scanner and database/sql/driver.Valuer
 interfaces.
 
+Compatibility with encoding.TextMarshaler
+
+Types implementing encoding.TextMarshaler and encoding.TextUnmarshaler can be encoded to and scanned from text-like
+columns such as text and varchar. These interfaces are only used when no Codec, database/sql.Scanner, or
+database/sql/driver.Valuer support is available.
+
 Encoding Typed Nils
 
 pgtype encodes untyped and typed nils (e.g. nil and []byte(nil)) to the SQL NULL value without going through the Codec
@@ -3,12 +3,19 @@ package pgtype
 import (
 	"database/sql/driver"
 	"fmt"
+	"strings"
 )
 
 // EnumCodec is a codec that caches the strings it decodes. If the same string is read multiple times only one copy is
 // allocated. These strings are only garbage collected when the EnumCodec is garbage collected. EnumCodec can be used
 // for any text type not only enums, but it should only be used when there are a small number of possible values.
+//
+// If Labels is not empty values are checked against it when they are encoded. A value that is not one of Labels is
+// rejected with an error before it is sent to the server.
 type EnumCodec struct {
+	// Labels are the valid values of the enum.
+	Labels []string
+
 	membersMap map[string]string // map to quickly lookup member and reuse string instead of allocating
 }
 
@@ -20,20 +27,57 @@ func (EnumCodec) PreferredFormat() int16 {
 	return TextFormatCode
 }
 
-func (EnumCodec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
+// checksTextEncode implements textEncodeChecker. Values must be planned by PlanEncode to be checked against Labels.
+func (c EnumCodec) checksTextEncode() bool {
+	return len(c.Labels) > 0
+}
+
+func (c EnumCodec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
+	var plan EncodePlan
 	switch format {
 	case TextFormatCode, BinaryFormatCode:
 		switch value.(type) {
 		case string:
-			return encodePlanTextCodecString{}
+			plan = encodePlanTextCodecString{}
 		case []byte:
-			return encodePlanTextCodecByteSlice{}
+			plan = encodePlanTextCodecByteSlice{}
 		case TextValuer:
-			return encodePlanTextCodecTextValuer{}
+			plan = encodePlanTextCodecTextValuer{}
 		}
 	}
 
-	return nil
+	if plan != nil && len(c.Labels) > 0 {
+		typeName := "enum"
+		if t, ok := m.TypeForOID(oid); ok {
+			typeName = t.Name
+		}
+		return &encodePlanEnumCodecCheckLabel{next: plan, typeName: typeName, labels: c.Labels}
+	}
+
+	return plan
+}
+
+type encodePlanEnumCodecCheckLabel struct {
+	next     EncodePlan
+	typeName string
+	labels   []string
+}
+
+func (plan *encodePlanEnumCodecCheckLabel) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	start := len(buf)
+	newBuf, err = plan.next.Encode(value, buf)
+	if err != nil || newBuf == nil {
+		return newBuf, err
+	}
+
+	label := string(newBuf[start:])
+	for _, l := range plan.labels {
+		if l == label {
+			return newBuf, nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid input value for enum %s: %q (valid values: %s)", plan.typeName, label, strings.Join(plan.labels, ", "))
 }
 
 func (c *EnumCodec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan {
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 )
 
@@ -67,3 +68,27 @@ create type enum_test as enum ('foo', 'bar', 'baz');`)
 		require.Equal(t, []any{"foo"}, values)
 	})
 }
+
+func TestEnumCodecLabels(t *testing.T) {
+	type color string
+
+	m := pgtype.NewMap()
+	m.RegisterType(&pgtype.Type{Name: "color", OID: 100000, Codec: &pgtype.EnumCodec{Labels: []string{"red", "green"}}})
+
+	for _, value := range []any{"red", []byte("green"), color("red"), pgtype.Text{String: "green", Valid: true}} {
+		buf, err := m.Encode(100000, pgtype.TextFormatCode, value, nil)
+		require.NoError(t, err)
+		require.NotEmpty(t, buf)
+	}
+
+	buf, err := m.Encode(100000, pgtype.TextFormatCode, pgtype.Text{}, nil)
+	require.NoError(t, err)
+	require.Nil(t, buf)
+
+	_, err = m.Encode(100000, pgtype.TextFormatCode, color("blue"), nil)
+	require.EqualError(t, err, `unable to encode "blue" into text format for color (OID 100000): invalid input value for enum color: "blue" (valid values: red, green)`)
+
+	// A string in the text format must not bypass the check.
+	_, err = m.Encode(100000, pgtype.TextFormatCode, "blue", nil)
+	require.Error(t, err)
+}
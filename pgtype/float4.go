@@ -89,7 +89,10 @@ func (f *Float4) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-type Float4Codec struct{}
+type Float4Codec struct {
+	// NonFinite controls how NaN, Infinity, and -Infinity are encoded and scanned. See NonFiniteFloatPolicy.
+	NonFinite NonFiniteFloatPolicy
+}
 
 func (Float4Codec) FormatSupported(format int16) bool {
 	return format == TextFormatCode || format == BinaryFormatCode
@@ -99,7 +102,11 @@ func (Float4Codec) PreferredFormat() int16 {
 	return BinaryFormatCode
 }
 
-func (Float4Codec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
+func (c Float4Codec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
+	return wrapNonFiniteFloatEncodePlan(c.NonFinite, true, c.planEncode(format, value))
+}
+
+func (Float4Codec) planEncode(format int16, value any) EncodePlan {
 	switch format {
 	case BinaryFormatCode:
 		switch value.(type) {
@@ -169,8 +176,17 @@ func (encodePlanFloat4CodecBinaryInt64Valuer) Encode(value any, buf []byte) (new
 	return pgio.AppendUint32(buf, math.Float32bits(f)), nil
 }
 
-func (Float4Codec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan {
+func (c Float4Codec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan {
+	if c.NonFinite == NonFiniteFloatNull {
+		if plan := planNonFiniteFloatToNullScan(m, oid, format, 4, target); plan != nil {
+			return plan
+		}
+	}
 
+	return wrapNonFiniteFloatScanPlan(c.NonFinite, format, 4, c.planScan(format, target))
+}
+
+func (Float4Codec) planScan(format int16, target any) ScanPlan {
 	switch format {
 	case BinaryFormatCode:
 		switch target.(type) {
@@ -293,7 +309,7 @@ func (scanPlanTextAnyToFloat32) Scan(src []byte, dst any) error {
 }
 
 func (c Float4Codec) DecodeDatabaseSQLValue(m *Map, oid uint32, format int16, src []byte) (driver.Value, error) {
-	if src == nil {
+	if src == nil || c.NonFinite == NonFiniteFloatNull && isNonFiniteFloatSrc(format, 4, src) {
 		return nil, nil
 	}
 
@@ -306,7 +322,7 @@ func (c Float4Codec) DecodeDatabaseSQLValue(m *Map, oid uint32, format int16, sr
 }
 
 func (c Float4Codec) DecodeValue(m *Map, oid uint32, format int16, src []byte) (any, error) {
-	if src == nil {
+	if src == nil || c.NonFinite == NonFiniteFloatNull && isNonFiniteFloatSrc(format, 4, src) {
 		return nil, nil
 	}
 
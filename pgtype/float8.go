@@ -97,7 +97,10 @@ func (f *Float8) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-type Float8Codec struct{}
+type Float8Codec struct {
+	// NonFinite controls how NaN, Infinity, and -Infinity are encoded and scanned. See NonFiniteFloatPolicy.
+	NonFinite NonFiniteFloatPolicy
+}
 
 func (Float8Codec) FormatSupported(format int16) bool {
 	return format == TextFormatCode || format == BinaryFormatCode
@@ -107,7 +110,11 @@ func (Float8Codec) PreferredFormat() int16 {
 	return BinaryFormatCode
 }
 
-func (Float8Codec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
+func (c Float8Codec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
+	return wrapNonFiniteFloatEncodePlan(c.NonFinite, false, c.planEncode(format, value))
+}
+
+func (Float8Codec) planEncode(format int16, value any) EncodePlan {
 	switch format {
 	case BinaryFormatCode:
 		switch value.(type) {
@@ -207,8 +214,17 @@ func (encodePlanTextInt64Valuer) Encode(value any, buf []byte) (newBuf []byte, e
 	return append(buf, strconv.FormatInt(n.Int64, 10)...), nil
 }
 
-func (Float8Codec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan {
+func (c Float8Codec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan {
+	if c.NonFinite == NonFiniteFloatNull {
+		if plan := planNonFiniteFloatToNullScan(m, oid, format, 8, target); plan != nil {
+			return plan
+		}
+	}
+
+	return wrapNonFiniteFloatScanPlan(c.NonFinite, format, 8, c.planScan(format, target))
+}
 
+func (Float8Codec) planScan(format int16, target any) ScanPlan {
 	switch format {
 	case BinaryFormatCode:
 		switch target.(type) {
@@ -352,7 +368,7 @@ func (c Float8Codec) DecodeDatabaseSQLValue(m *Map, oid uint32, format int16, sr
 }
 
 func (c Float8Codec) DecodeValue(m *Map, oid uint32, format int16, src []byte) (any, error) {
-	if src == nil {
+	if src == nil || c.NonFinite == NonFiniteFloatNull && isNonFiniteFloatSrc(format, 8, src) {
 		return nil, nil
 	}
 
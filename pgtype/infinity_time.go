@@ -0,0 +1,154 @@
+package pgtype
+
+import (
+	"fmt"
+	"time"
+)
+
+// MinTimestamp and MaxTimestamp are the earliest and latest times that PostgreSQL timestamp and timestamptz values can
+// represent.
+var (
+	MinTimestamp = time.Date(-4713, 11, 24, 0, 0, 0, 0, time.UTC)
+	MaxTimestamp = time.Date(294276, 12, 31, 23, 59, 59, 999999000, time.UTC)
+)
+
+// InfinityTimePolicy controls how infinite date, timestamp, and timestamptz values are scanned into and encoded from
+// time.Time. infinity is scanned into Infinity and -infinity is scanned into NegativeInfinity. A time.Time equal to
+// Infinity or NegativeInfinity is encoded as infinity or -infinity respectively.
+//
+// Infinity and NegativeInfinity can be any time.Time. They are typically MaxTimestamp and MinTimestamp (see
+// ClampInfinityTimePolicy) so that ordering is preserved, but a sentinel such as the zero time.Time can be used for
+// NegativeInfinity if the application treats it as "no lower bound". Note that all time.Time values equal to a sentinel
+// are encoded as infinite.
+type InfinityTimePolicy struct {
+	Infinity         time.Time
+	NegativeInfinity time.Time
+}
+
+// ClampInfinityTimePolicy returns an InfinityTimePolicy that maps infinity to MaxTimestamp and -infinity to
+// MinTimestamp.
+func ClampInfinityTimePolicy() *InfinityTimePolicy {
+	return &InfinityTimePolicy{Infinity: MaxTimestamp, NegativeInfinity: MinTimestamp}
+}
+
+func (p *InfinityTimePolicy) scan(w *timeWrapper, infinityModifier InfinityModifier) error {
+	switch infinityModifier {
+	case Infinity:
+		*w = timeWrapper(p.Infinity)
+	case NegativeInfinity:
+		*w = timeWrapper(p.NegativeInfinity)
+	default:
+		return fmt.Errorf("invalid InfinityModifier: %v", infinityModifier)
+	}
+	return nil
+}
+
+func (p *InfinityTimePolicy) infinityModifier(t time.Time) InfinityModifier {
+	switch {
+	case t.Equal(p.Infinity):
+		return Infinity
+	case t.Equal(p.NegativeInfinity):
+		return NegativeInfinity
+	default:
+		return Finite
+	}
+}
+
+// infinityTimeScanner applies an InfinityTimePolicy when scanning into a *time.Time.
+type infinityTimeScanner struct {
+	w      *timeWrapper
+	policy *InfinityTimePolicy
+}
+
+func (s *infinityTimeScanner) ScanDate(v Date) error {
+	if v.Valid && v.InfinityModifier != Finite {
+		return s.policy.scan(s.w, v.InfinityModifier)
+	}
+	return s.w.ScanDate(v)
+}
+
+func (s *infinityTimeScanner) ScanTimestamp(v Timestamp) error {
+	if v.Valid && v.InfinityModifier != Finite {
+		return s.policy.scan(s.w, v.InfinityModifier)
+	}
+	return s.w.ScanTimestamp(v)
+}
+
+func (s *infinityTimeScanner) ScanTimestamptz(v Timestamptz) error {
+	if v.Valid && v.InfinityModifier != Finite {
+		return s.policy.scan(s.w, v.InfinityModifier)
+	}
+	return s.w.ScanTimestamptz(v)
+}
+
+// infinityTimeValuer applies an InfinityTimePolicy when encoding a time.Time.
+type infinityTimeValuer struct {
+	t      time.Time
+	policy *InfinityTimePolicy
+}
+
+func (v infinityTimeValuer) DateValue() (Date, error) {
+	if im := v.policy.infinityModifier(v.t); im != Finite {
+		return Date{InfinityModifier: im, Valid: true}, nil
+	}
+	return Date{Time: v.t, Valid: true}, nil
+}
+
+func (v infinityTimeValuer) TimestampValue() (Timestamp, error) {
+	if im := v.policy.infinityModifier(v.t); im != Finite {
+		return Timestamp{InfinityModifier: im, Valid: true}, nil
+	}
+	return Timestamp{Time: v.t, Valid: true}, nil
+}
+
+func (v infinityTimeValuer) TimestamptzValue() (Timestamptz, error) {
+	if im := v.policy.infinityModifier(v.t); im != Finite {
+		return Timestamptz{InfinityModifier: im, Valid: true}, nil
+	}
+	return Timestamptz{Time: v.t, Valid: true}, nil
+}
+
+// wrapInfinityTimeScanPlan wraps plan so that m.InfinityTimePolicy is applied when target is a *time.Time. The policy
+// is read at scan time so it may be changed after plans have been memoized.
+func wrapInfinityTimeScanPlan(m *Map, target any, plan ScanPlan) ScanPlan {
+	if _, ok := target.(*timeWrapper); !ok || m == nil || plan == nil {
+		return plan
+	}
+	return &scanPlanInfinityTime{m: m, next: plan}
+}
+
+type scanPlanInfinityTime struct {
+	m    *Map
+	next ScanPlan
+}
+
+func (plan *scanPlanInfinityTime) Scan(src []byte, dst any) error {
+	if policy := plan.m.InfinityTimePolicy; policy != nil {
+		if w, ok := dst.(*timeWrapper); ok {
+			return plan.next.Scan(src, &infinityTimeScanner{w: w, policy: policy})
+		}
+	}
+	return plan.next.Scan(src, dst)
+}
+
+// wrapInfinityTimeEncodePlan wraps plan so that m.InfinityTimePolicy is applied when value is a time.Time.
+func wrapInfinityTimeEncodePlan(m *Map, value any, plan EncodePlan) EncodePlan {
+	if _, ok := value.(timeWrapper); !ok || m == nil || plan == nil {
+		return plan
+	}
+	return &encodePlanInfinityTime{m: m, next: plan}
+}
+
+type encodePlanInfinityTime struct {
+	m    *Map
+	next EncodePlan
+}
+
+func (plan *encodePlanInfinityTime) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	if policy := plan.m.InfinityTimePolicy; policy != nil {
+		if w, ok := value.(timeWrapper); ok {
+			return plan.next.Encode(infinityTimeValuer{t: time.Time(w), policy: policy}, buf)
+		}
+	}
+	return plan.next.Encode(value, buf)
+}
@@ -0,0 +1,109 @@
+package pgtype_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapScanInfinityTimeDefaultIsError(t *testing.T) {
+	m := pgtype.NewMap()
+
+	var tim time.Time
+	err := m.Scan(pgtype.TimestamptzOID, pgtype.TextFormatCode, []byte("infinity"), &tim)
+	require.Error(t, err)
+}
+
+func TestMapInfinityTimePolicy(t *testing.T) {
+	m := pgtype.NewMap()
+	m.InfinityTimePolicy = pgtype.ClampInfinityTimePolicy()
+
+	for _, oid := range []uint32{pgtype.DateOID, pgtype.TimestampOID, pgtype.TimestamptzOID} {
+		for _, format := range []int16{pgtype.TextFormatCode, pgtype.BinaryFormatCode} {
+			for _, tt := range []struct {
+				value    pgtype.InfinityModifier
+				expected time.Time
+			}{
+				{pgtype.Infinity, pgtype.MaxTimestamp},
+				{pgtype.NegativeInfinity, pgtype.MinTimestamp},
+			} {
+				var src []byte
+				var err error
+				switch oid {
+				case pgtype.DateOID:
+					src, err = m.Encode(oid, format, pgtype.Date{InfinityModifier: tt.value, Valid: true}, nil)
+				case pgtype.TimestampOID:
+					src, err = m.Encode(oid, format, pgtype.Timestamp{InfinityModifier: tt.value, Valid: true}, nil)
+				case pgtype.TimestamptzOID:
+					src, err = m.Encode(oid, format, pgtype.Timestamptz{InfinityModifier: tt.value, Valid: true}, nil)
+				}
+				require.NoError(t, err)
+
+				var tim time.Time
+				err = m.Scan(oid, format, src, &tim)
+				require.NoErrorf(t, err, "oid: %d, format: %d, value: %v", oid, format, tt.value)
+				assert.Truef(t, tt.expected.Equal(tim), "oid: %d, format: %d, value: %v, got: %v", oid, format, tt.value, tim)
+
+				buf, err := m.Encode(oid, format, tim, nil)
+				require.NoError(t, err)
+				assert.Equalf(t, src, buf, "oid: %d, format: %d, value: %v", oid, format, tt.value)
+			}
+		}
+	}
+}
+
+func TestMapInfinityTimePolicySentinel(t *testing.T) {
+	m := pgtype.NewMap()
+	m.InfinityTimePolicy = &pgtype.InfinityTimePolicy{
+		Infinity:         time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC),
+		NegativeInfinity: time.Time{},
+	}
+
+	var tim time.Time
+	err := m.Scan(pgtype.TimestamptzOID, pgtype.TextFormatCode, []byte("-infinity"), &tim)
+	require.NoError(t, err)
+	assert.True(t, tim.IsZero())
+
+	buf, err := m.Encode(pgtype.TimestamptzOID, pgtype.TextFormatCode, time.Time{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "-infinity", string(buf))
+
+	// The policy is read at scan time so plans memoized before it was changed observe the change.
+	m.InfinityTimePolicy = nil
+	err = m.Scan(pgtype.TimestamptzOID, pgtype.TextFormatCode, []byte("-infinity"), &tim)
+	require.Error(t, err)
+}
+
+func TestBCTimeRoundTrip(t *testing.T) {
+	m := pgtype.NewMap()
+
+	for _, oid := range []uint32{pgtype.DateOID, pgtype.TimestampOID, pgtype.TimestamptzOID} {
+		for _, format := range []int16{pgtype.TextFormatCode, pgtype.BinaryFormatCode} {
+			for _, value := range []time.Time{
+				time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(-43, 3, 15, 0, 0, 0, 0, time.UTC),
+				pgtype.MinTimestamp,
+			} {
+				buf, err := m.Encode(oid, format, value, nil)
+				require.NoError(t, err)
+
+				var tim time.Time
+				err = m.Scan(oid, format, buf, &tim)
+				require.NoErrorf(t, err, "oid: %d, format: %d, value: %v", oid, format, value)
+				assert.Truef(t, value.Equal(tim), "oid: %d, format: %d, value: %v, got: %v", oid, format, value, tim)
+			}
+		}
+	}
+}
+
+func TestEncodeTimeOutOfRange(t *testing.T) {
+	m := pgtype.NewMap()
+
+	for _, oid := range []uint32{pgtype.DateOID, pgtype.TimestampOID, pgtype.TimestamptzOID} {
+		_, err := m.Encode(oid, pgtype.BinaryFormatCode, time.Date(-300000, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+		require.Errorf(t, err, "oid: %d", oid)
+	}
+}
@@ -0,0 +1,196 @@
+package pgtype
+
+import (
+	"math"
+	"reflect"
+)
+
+// IntegerOutOfRangeScan describes an integer value that could not be scanned because it is outside the range of the
+// integer scan target. e.g. scanning an int8 value of 1<<40 into an *int32.
+type IntegerOutOfRangeScan struct {
+	// ColumnIndex is the index of the column being scanned. It is -1 when unknown such as when called from Map.Scan.
+	ColumnIndex int
+
+	// ColumnName is the name of the column being scanned. It is empty when unknown.
+	ColumnName string
+
+	// OID is the PostgreSQL type of the value.
+	OID uint32
+
+	// Value is the value read from PostgreSQL.
+	Value int64
+
+	// Target is the scan target. It is always a pointer to an integer.
+	Target any
+
+	// Err is the error that scanning returned.
+	Err error
+}
+
+// IntegerOutOfRangeScanPolicy is called when a scanned integer value is out of range of its integer target. If it
+// returns nil the scan is considered successful and the policy is responsible for having set s.Target. Otherwise the
+// returned error is the result of the scan.
+//
+// Only integer targets are covered. Date and timestamp values always fit in a time.Time except for infinite values,
+// which are handled by Map.InfinityTimePolicy.
+//
+// A policy that records bad values and continues can be built on ClampIntegerOutOfRangeScan:
+//
+//	m.IntegerOutOfRangeScanPolicy = func(s *pgtype.IntegerOutOfRangeScan) error {
+//		log.Printf("column %s: %d out of range", s.ColumnName, s.Value)
+//		return pgtype.ClampIntegerOutOfRangeScan(s)
+//	}
+type IntegerOutOfRangeScanPolicy func(s *IntegerOutOfRangeScan) error
+
+// ErrorIntegerOutOfRangeScan is an IntegerOutOfRangeScanPolicy that returns the original error. It is equivalent to
+// having no policy.
+func ErrorIntegerOutOfRangeScan(s *IntegerOutOfRangeScan) error {
+	return s.Err
+}
+
+// ClampIntegerOutOfRangeScan is an IntegerOutOfRangeScanPolicy that sets s.Target to the closest value it can
+// represent.
+func ClampIntegerOutOfRangeScan(s *IntegerOutOfRangeScan) error {
+	v := integerScanTarget(reflect.ValueOf(s.Target))
+	if !v.IsValid() {
+		return s.Err
+	}
+
+	minValue, maxValue := integerKindRange(v.Kind())
+	switch {
+	case isUnsignedKind(v.Kind()) && s.Value < 0:
+		v.SetUint(0)
+	case isUnsignedKind(v.Kind()) && uint64(s.Value) > maxValue:
+		v.SetUint(maxValue)
+	case isUnsignedKind(v.Kind()):
+		v.SetUint(uint64(s.Value))
+	case s.Value < minValue:
+		v.SetInt(minValue)
+	case s.Value > int64(maxValue):
+		v.SetInt(int64(maxValue))
+	default:
+		v.SetInt(s.Value)
+	}
+
+	return nil
+}
+
+// ResolveScanError applies m.IntegerOutOfRangeScanPolicy to err if err was caused by scanning an integer value into an
+// integer target that cannot represent it. Otherwise err is returned unchanged. columnIndex and columnName identify the
+// column being scanned; use -1 and "" if unknown.
+func (m *Map) ResolveScanError(oid uint32, formatCode int16, src []byte, dst any, columnIndex int, columnName string, err error) error {
+	if err == nil || m.IntegerOutOfRangeScanPolicy == nil || src == nil {
+		return err
+	}
+
+	kind := integerScanTargetKind(reflect.ValueOf(dst))
+	if kind == reflect.Invalid {
+		return err
+	}
+
+	var n Int8
+	if scanErr := m.Scan(oid, formatCode, src, &n); scanErr != nil || !n.Valid {
+		return err
+	}
+
+	minValue, maxValue := integerKindRange(kind)
+	if isUnsignedKind(kind) {
+		if n.Int64 >= 0 && uint64(n.Int64) <= maxValue {
+			return err
+		}
+	} else if n.Int64 >= minValue && n.Int64 <= int64(maxValue) {
+		return err
+	}
+
+	return m.IntegerOutOfRangeScanPolicy(&IntegerOutOfRangeScan{
+		ColumnIndex: columnIndex,
+		ColumnName:  columnName,
+		OID:         oid,
+		Value:       n.Int64,
+		Target:      dst,
+		Err:         err,
+	})
+}
+
+// integerScanTargetKind returns the kind of the integer that ptr points to directly or through a pointer. It returns
+// reflect.Invalid if ptr does not point to an integer. Unlike integerScanTarget, it does not modify ptr.
+func integerScanTargetKind(ptr reflect.Value) reflect.Kind {
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() {
+		return reflect.Invalid
+	}
+
+	t := ptr.Type().Elem()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if !isIntegerKind(t.Kind()) {
+		return reflect.Invalid
+	}
+
+	return t.Kind()
+}
+
+// integerScanTarget returns the settable integer value that ptr points to. If ptr is a pointer to a nil pointer to an
+// integer then a new integer is allocated so it must only be called to assign a value. It returns the zero Value if ptr
+// does not point to an integer.
+func integerScanTarget(ptr reflect.Value) reflect.Value {
+	if integerScanTargetKind(ptr) == reflect.Invalid {
+		return reflect.Value{}
+	}
+
+	v := ptr.Elem()
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	return v
+}
+
+func isIntegerKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func isUnsignedKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// integerKindRange returns the minimum and maximum values of k. max is returned as a uint64 so it can represent the
+// maximum value of 64-bit unsigned integers.
+func integerKindRange(k reflect.Kind) (minValue int64, maxValue uint64) {
+	switch k {
+	case reflect.Int8:
+		return math.MinInt8, math.MaxInt8
+	case reflect.Int16:
+		return math.MinInt16, math.MaxInt16
+	case reflect.Int32:
+		return math.MinInt32, math.MaxInt32
+	case reflect.Int:
+		return math.MinInt, math.MaxInt
+	case reflect.Int64:
+		return math.MinInt64, math.MaxInt64
+	case reflect.Uint8:
+		return 0, math.MaxUint8
+	case reflect.Uint16:
+		return 0, math.MaxUint16
+	case reflect.Uint32:
+		return 0, math.MaxUint32
+	case reflect.Uint:
+		return 0, math.MaxUint
+	case reflect.Uint64:
+		return 0, math.MaxUint64
+	}
+	return 0, 0
+}
@@ -0,0 +1,92 @@
+package pgtype_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapScanIntegerOutOfRangeDefaultIsError(t *testing.T) {
+	m := pgtype.NewMap()
+
+	var n int32
+	err := m.Scan(pgtype.Int8OID, pgtype.TextFormatCode, []byte("5000000000"), &n)
+	require.Error(t, err)
+}
+
+func TestMapScanIntegerOutOfRangeClamp(t *testing.T) {
+	m := pgtype.NewMap()
+	m.IntegerOutOfRangeScanPolicy = pgtype.ClampIntegerOutOfRangeScan
+
+	for i, tt := range []struct {
+		src    string
+		dst    any
+		expect any
+	}{
+		{src: "5000000000", dst: new(int32), expect: int32(math.MaxInt32)},
+		{src: "-5000000000", dst: new(int32), expect: int32(math.MinInt32)},
+		{src: "300", dst: new(int8), expect: int8(math.MaxInt8)},
+		{src: "-1", dst: new(uint16), expect: uint16(0)},
+		{src: "70000", dst: new(uint16), expect: uint16(math.MaxUint16)},
+		{src: "42", dst: new(int16), expect: int16(42)},
+	} {
+		err := m.Scan(pgtype.Int8OID, pgtype.TextFormatCode, []byte(tt.src), tt.dst)
+		require.NoErrorf(t, err, "%d", i)
+		assert.Equalf(t, tt.expect, reflectDeref(tt.dst), "%d", i)
+	}
+
+	var p *int32
+	err := m.Scan(pgtype.Int8OID, pgtype.TextFormatCode, []byte("5000000000"), &p)
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	assert.EqualValues(t, math.MaxInt32, *p)
+
+	// Resolving a scan error that the policy does not apply to leaves a nil pointer unchanged.
+	p = nil
+	scanErr := errors.New("cannot scan")
+	err = m.ResolveScanError(pgtype.Int8OID, pgtype.TextFormatCode, []byte("abc"), &p, -1, "", scanErr)
+	require.ErrorIs(t, err, scanErr)
+	assert.Nil(t, p)
+}
+
+func TestMapScanIntegerOutOfRangeCustomPolicy(t *testing.T) {
+	m := pgtype.NewMap()
+
+	var events []pgtype.IntegerOutOfRangeScan
+	m.IntegerOutOfRangeScanPolicy = func(s *pgtype.IntegerOutOfRangeScan) error {
+		events = append(events, *s)
+		return pgtype.ClampIntegerOutOfRangeScan(s)
+	}
+
+	var n int32
+	err := m.Scan(pgtype.Int8OID, pgtype.TextFormatCode, []byte("5000000000"), &n)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.EqualValues(t, 5000000000, events[0].Value)
+	assert.Equal(t, -1, events[0].ColumnIndex)
+	assert.EqualValues(t, pgtype.Int8OID, events[0].OID)
+	assert.Error(t, events[0].Err)
+
+	// Errors that are not caused by an out of range value are not passed to the policy.
+	err = m.Scan(pgtype.TextOID, pgtype.TextFormatCode, []byte("abc"), &n)
+	require.Error(t, err)
+	require.Len(t, events, 1)
+}
+
+func reflectDeref(v any) any {
+	switch v := v.(type) {
+	case *int8:
+		return *v
+	case *int16:
+		return *v
+	case *int32:
+		return *v
+	case *uint16:
+		return *v
+	}
+	return nil
+}
@@ -1,18 +1,87 @@
 package pgtype
 
 import (
+	"bytes"
 	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
 )
 
+// JSONCodec is the codec for the PostgreSQL json type.
+//
+// Marshal and Unmarshal may be replaced to use a different JSON implementation. For example, a faster library that is
+// compatible with encoding/json. Register a Type with the configured codec on a Map to use it for all json values:
+//
+//	m.RegisterType(&pgtype.Type{Name: "json", OID: pgtype.JSONOID, Codec: &pgtype.JSONCodec{
+//		Marshal:   jsoniter.Marshal,
+//		Unmarshal: jsoniter.Unmarshal,
+//	}})
 type JSONCodec struct {
-	Marshal   func(v any) ([]byte, error)
+	// Marshal encodes a Go value as JSON. If nil, json.Marshal is used.
+	Marshal func(v any) ([]byte, error)
+
+	// Unmarshal decodes JSON into a Go value. If nil, json.Unmarshal is used.
+	Unmarshal func(data []byte, v any) error
+
+	// TypeMarshalers overrides Marshal and Unmarshal for specific Go types. It is keyed by the type of the value to encode
+	// or the type of the destination to scan into. e.g. Use protojson for values and destinations of type *mypb.Message.
+	// TypeMarshalers takes precedence over all other handling of the value or destination including driver.Valuer,
+	// json.Marshaler, and sql.Scanner.
+	TypeMarshalers map[reflect.Type]JSONTypeMarshaler
+
+	// UseNumber causes numbers to be decoded as json.Number instead of float64 when decoding into an interface value such
+	// as any or map[string]any. This preserves the precision of integers that cannot be represented exactly by a
+	// float64. It is only used when Unmarshal is nil.
+	UseNumber bool
+}
+
+// JSONTypeMarshaler is a JSON implementation for a specific Go type. See JSONCodec.TypeMarshalers.
+type JSONTypeMarshaler struct {
+	// Marshal encodes a value of the type as JSON. If nil, values of the type are encoded as usual.
+	Marshal func(v any) ([]byte, error)
+
+	// Unmarshal decodes JSON into a destination of the type. If nil, destinations of the type are scanned as usual.
 	Unmarshal func(data []byte, v any) error
 }
 
+func (c *JSONCodec) marshal() func(v any) ([]byte, error) {
+	if c.Marshal == nil {
+		return json.Marshal
+	}
+	return c.Marshal
+}
+
+func (c *JSONCodec) unmarshal() func(data []byte, v any) error {
+	if c.Unmarshal == nil {
+		if c.UseNumber {
+			return unmarshalJSONUseNumber
+		}
+		return json.Unmarshal
+	}
+	return c.Unmarshal
+}
+
+// unmarshalJSONUseNumber is json.Unmarshal with numbers decoded as json.Number.
+func unmarshalJSONUseNumber(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	err := dec.Decode(v)
+	if err != nil {
+		return err
+	}
+
+	// Reject trailing data as json.Unmarshal does.
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("invalid character after top-level value")
+	}
+
+	return nil
+}
+
 func (*JSONCodec) FormatSupported(format int16) bool {
 	return format == TextFormatCode || format == BinaryFormatCode
 }
@@ -22,6 +91,12 @@ func (*JSONCodec) PreferredFormat() int16 {
 }
 
 func (c *JSONCodec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
+	if tm, ok := c.TypeMarshalers[reflect.TypeOf(value)]; ok && tm.Marshal != nil {
+		return &encodePlanJSONCodecEitherFormatMarshal{
+			marshal: tm.Marshal,
+		}
+	}
+
 	switch value.(type) {
 	case string:
 		return encodePlanJSONCodecEitherFormatString{}
@@ -48,7 +123,7 @@ func (c *JSONCodec) PlanEncode(m *Map, oid uint32, format int16, value any) Enco
 	// https://github.com/jackc/pgx/issues/1681
 	case json.Marshaler:
 		return &encodePlanJSONCodecEitherFormatMarshal{
-			marshal: c.Marshal,
+			marshal: c.marshal(),
 		}
 	}
 
@@ -67,7 +142,7 @@ func (c *JSONCodec) PlanEncode(m *Map, oid uint32, format int16, value any) Enco
 	}
 
 	return &encodePlanJSONCodecEitherFormatMarshal{
-		marshal: c.Marshal,
+		marshal: c.marshal(),
 	}
 }
 
@@ -118,6 +193,12 @@ func (e *encodePlanJSONCodecEitherFormatMarshal) Encode(value any, buf []byte) (
 }
 
 func (c *JSONCodec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan {
+	if tm, ok := c.TypeMarshalers[reflect.TypeOf(target)]; ok && tm.Unmarshal != nil {
+		return &scanPlanJSONToJSONUnmarshal{
+			unmarshal: tm.Unmarshal,
+		}
+	}
+
 	switch target.(type) {
 	case *string:
 		return scanPlanAnyToString{}
@@ -153,7 +234,7 @@ func (c *JSONCodec) PlanScan(m *Map, oid uint32, format int16, target any) ScanP
 	}
 
 	return &scanPlanJSONToJSONUnmarshal{
-		unmarshal: c.Unmarshal,
+		unmarshal: c.unmarshal(),
 	}
 }
 
@@ -243,6 +324,6 @@ func (c *JSONCodec) DecodeValue(m *Map, oid uint32, format int16, src []byte) (a
 	}
 
 	var dst any
-	err := c.Unmarshal(src, &dst)
+	err := c.unmarshal()(src, &dst)
 	return dst, err
 }
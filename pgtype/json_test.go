@@ -321,3 +321,91 @@ func TestJSONCodecScanToNonPointerValues(t *testing.T) {
 		require.Equal(t, 42, m)
 	})
 }
+
+// upperJSONMessage stands in for a type such as a protobuf message that needs a different JSON implementation.
+type upperJSONMessage struct {
+	Text string
+}
+
+func TestJSONCodecTypeMarshalers(t *testing.T) {
+	t.Parallel()
+
+	typeMarshalers := map[reflect.Type]pgtype.JSONTypeMarshaler{
+		reflect.TypeOf(&upperJSONMessage{}): {
+			Marshal: func(v any) ([]byte, error) {
+				return json.Marshal(map[string]string{"TEXT": v.(*upperJSONMessage).Text})
+			},
+			Unmarshal: func(data []byte, v any) error {
+				var m map[string]string
+				err := json.Unmarshal(data, &m)
+				if err != nil {
+					return err
+				}
+				v.(*upperJSONMessage).Text = m["TEXT"]
+				return nil
+			},
+		},
+	}
+
+	m := pgtype.NewMap()
+	m.RegisterType(&pgtype.Type{Name: "json", OID: pgtype.JSONOID, Codec: &pgtype.JSONCodec{TypeMarshalers: typeMarshalers}})
+	m.RegisterType(&pgtype.Type{Name: "jsonb", OID: pgtype.JSONBOID, Codec: &pgtype.JSONBCodec{TypeMarshalers: typeMarshalers}})
+
+	for _, oid := range []uint32{pgtype.JSONOID, pgtype.JSONBOID} {
+		for _, format := range []int16{pgtype.TextFormatCode, pgtype.BinaryFormatCode} {
+			if oid == pgtype.JSONOID && format == pgtype.BinaryFormatCode {
+				continue
+			}
+
+			buf, err := m.Encode(oid, format, &upperJSONMessage{Text: "foo"}, nil)
+			require.NoError(t, err)
+			var msg upperJSONMessage
+			err = m.Scan(oid, format, buf, &msg)
+			require.NoError(t, err)
+			require.Equal(t, "foo", msg.Text)
+
+			var s string
+			err = m.Scan(oid, format, buf, &s)
+			require.NoError(t, err)
+			require.Equal(t, `{"TEXT":"foo"}`, s)
+
+			// Other types use the codec's Marshal and Unmarshal. These default to encoding/json when nil.
+			buf, err = m.Encode(oid, format, upperJSONMessage{Text: "bar"}, nil)
+			require.NoError(t, err)
+			var other map[string]any
+			err = m.Scan(oid, format, buf, &other)
+			require.NoError(t, err)
+			require.Equal(t, map[string]any{"Text": "bar"}, other)
+		}
+	}
+}
+
+func TestJSONCodecUseNumber(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+	m.RegisterType(&pgtype.Type{Name: "json", OID: pgtype.JSONOID, Codec: &pgtype.JSONCodec{UseNumber: true}})
+	m.RegisterType(&pgtype.Type{Name: "jsonb", OID: pgtype.JSONBOID, Codec: &pgtype.JSONBCodec{UseNumber: true}})
+
+	src := []byte(`{"id": 9007199254740993, "n": 1.5}`)
+	for _, oid := range []uint32{pgtype.JSONOID, pgtype.JSONBOID} {
+		var dst map[string]any
+		err := m.Scan(oid, pgtype.TextFormatCode, src, &dst)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"id": json.Number("9007199254740993"), "n": json.Number("1.5")}, dst)
+
+		dt, ok := m.TypeForOID(oid)
+		require.True(t, ok)
+		v, err := dt.Codec.DecodeValue(m, oid, pgtype.TextFormatCode, src)
+		require.NoError(t, err)
+		require.Equal(t, json.Number("9007199254740993"), v.(map[string]any)["id"])
+
+		var n json.Number
+		err = m.Scan(oid, pgtype.TextFormatCode, []byte(`12345678901234567890`), &n)
+		require.NoError(t, err)
+		require.Equal(t, json.Number("12345678901234567890"), n)
+
+		err = m.Scan(oid, pgtype.TextFormatCode, []byte(`{} {}`), &dst)
+		require.Error(t, err)
+	}
+}
@@ -3,11 +3,20 @@ package pgtype
 import (
 	"database/sql/driver"
 	"fmt"
+	"reflect"
 )
 
+// JSONBCodec is the codec for the PostgreSQL jsonb type. Marshal, Unmarshal, TypeMarshalers, and UseNumber have the
+// same meaning as in JSONCodec.
 type JSONBCodec struct {
-	Marshal   func(v any) ([]byte, error)
-	Unmarshal func(data []byte, v any) error
+	Marshal        func(v any) ([]byte, error)
+	Unmarshal      func(data []byte, v any) error
+	TypeMarshalers map[reflect.Type]JSONTypeMarshaler
+	UseNumber      bool
+}
+
+func (c *JSONBCodec) jsonCodec() *JSONCodec {
+	return &JSONCodec{Marshal: c.Marshal, Unmarshal: c.Unmarshal, TypeMarshalers: c.TypeMarshalers, UseNumber: c.UseNumber}
 }
 
 func (*JSONBCodec) FormatSupported(format int16) bool {
@@ -21,12 +30,12 @@ func (*JSONBCodec) PreferredFormat() int16 {
 func (c *JSONBCodec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
 	switch format {
 	case BinaryFormatCode:
-		plan := (&JSONCodec{Marshal: c.Marshal, Unmarshal: c.Unmarshal}).PlanEncode(m, oid, TextFormatCode, value)
+		plan := c.jsonCodec().PlanEncode(m, oid, TextFormatCode, value)
 		if plan != nil {
 			return &encodePlanJSONBCodecBinaryWrapper{textPlan: plan}
 		}
 	case TextFormatCode:
-		return (&JSONCodec{Marshal: c.Marshal, Unmarshal: c.Unmarshal}).PlanEncode(m, oid, format, value)
+		return c.jsonCodec().PlanEncode(m, oid, format, value)
 	}
 
 	return nil
@@ -44,12 +53,12 @@ func (plan *encodePlanJSONBCodecBinaryWrapper) Encode(value any, buf []byte) (ne
 func (c *JSONBCodec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan {
 	switch format {
 	case BinaryFormatCode:
-		plan := (&JSONCodec{Marshal: c.Marshal, Unmarshal: c.Unmarshal}).PlanScan(m, oid, TextFormatCode, target)
+		plan := c.jsonCodec().PlanScan(m, oid, TextFormatCode, target)
 		if plan != nil {
 			return &scanPlanJSONBCodecBinaryUnwrapper{textPlan: plan}
 		}
 	case TextFormatCode:
-		return (&JSONCodec{Marshal: c.Marshal, Unmarshal: c.Unmarshal}).PlanScan(m, oid, format, target)
+		return c.jsonCodec().PlanScan(m, oid, format, target)
 	}
 
 	return nil
@@ -124,6 +133,6 @@ func (c *JSONBCodec) DecodeValue(m *Map, oid uint32, format int16, src []byte) (
 	}
 
 	var dst any
-	err := c.Unmarshal(src, &dst)
+	err := c.jsonCodec().unmarshal()(src, &dst)
 	return dst, err
 }
@@ -52,6 +52,7 @@ func TestLineTranscode(t *testing.T) {
 				Valid: true,
 			}),
 		},
+		{[3]float64{1.23, 4.56, 7.89}, new([3]float64), isExpectedEq([3]float64{1.23, 4.56, 7.89})},
 		{pgtype.Line{}, new(pgtype.Line), isExpectedEq(pgtype.Line{})},
 		{nil, new(pgtype.Line), isExpectedEq(pgtype.Line{})},
 	})
@@ -34,6 +34,7 @@ func TestLsegTranscode(t *testing.T) {
 				Valid: true,
 			}),
 		},
+		{[2][2]float64{{3.14, 1.678}, {7.1, 5.234}}, new([2][2]float64), isExpectedEq([2][2]float64{{3.14, 1.678}, {7.1, 5.234}})},
 		{pgtype.Lseg{}, new(pgtype.Lseg), isExpectedEq(pgtype.Lseg{})},
 		{nil, new(pgtype.Lseg), isExpectedEq(pgtype.Lseg{})},
 	})
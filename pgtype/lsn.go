@@ -0,0 +1,316 @@
+package pgtype
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/internal/pgio"
+)
+
+// LSN is a PostgreSQL Log Sequence Number. It is the Go representation of the pg_lsn type. It is a position in the WAL.
+//
+// LSN cannot represent NULL. Use *LSN or Uint64 to scan a value that may be NULL.
+type LSN uint64
+
+// ParseLSN parses s in the textual format of pg_lsn. e.g. 16/B374D848.
+func ParseLSN(s string) (LSN, error) {
+	upper, lower, found := strings.Cut(s, "/")
+	if !found {
+		return 0, fmt.Errorf("invalid format for pg_lsn: %q", s)
+	}
+
+	upperHalf, err := strconv.ParseUint(upper, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid format for pg_lsn: %q", s)
+	}
+
+	lowerHalf, err := strconv.ParseUint(lower, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid format for pg_lsn: %q", s)
+	}
+
+	return LSN(upperHalf<<32 | lowerHalf), nil
+}
+
+// String returns lsn in the textual format of pg_lsn.
+func (lsn LSN) String() string {
+	return fmt.Sprintf("%X/%X", uint32(lsn>>32), uint32(lsn))
+}
+
+func (lsn *LSN) ScanUint64(v Uint64) error {
+	if !v.Valid {
+		return fmt.Errorf("cannot scan NULL into *pgtype.LSN")
+	}
+
+	*lsn = LSN(v.Uint64)
+	return nil
+}
+
+func (lsn LSN) Uint64Value() (Uint64, error) {
+	return Uint64{Uint64: uint64(lsn), Valid: true}, nil
+}
+
+// Scan implements the database/sql Scanner interface.
+func (lsn *LSN) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		return fmt.Errorf("cannot scan NULL into *pgtype.LSN")
+	case string:
+		v, err := ParseLSN(src)
+		if err != nil {
+			return err
+		}
+		*lsn = v
+		return nil
+	case []byte:
+		v, err := ParseLSN(string(src))
+		if err != nil {
+			return err
+		}
+		*lsn = v
+		return nil
+	}
+
+	return fmt.Errorf("cannot scan %T", src)
+}
+
+// Value implements the database/sql/driver Valuer interface.
+func (lsn LSN) Value() (driver.Value, error) {
+	return lsn.String(), nil
+}
+
+type LSNCodec struct{}
+
+func (LSNCodec) FormatSupported(format int16) bool {
+	return format == TextFormatCode || format == BinaryFormatCode
+}
+
+func (LSNCodec) PreferredFormat() int16 {
+	return BinaryFormatCode
+}
+
+func (LSNCodec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
+	switch format {
+	case BinaryFormatCode:
+		switch value.(type) {
+		case Uint64Valuer:
+			return encodePlanLSNCodecBinaryUint64Valuer{}
+		case Int64Valuer:
+			return encodePlanLSNCodecBinaryInt64Valuer{}
+		}
+	case TextFormatCode:
+		switch value.(type) {
+		case Uint64Valuer:
+			return encodePlanLSNCodecTextUint64Valuer{}
+		case Int64Valuer:
+			return encodePlanLSNCodecTextInt64Valuer{}
+		}
+	}
+
+	return nil
+}
+
+type encodePlanLSNCodecBinaryUint64Valuer struct{}
+
+func (encodePlanLSNCodecBinaryUint64Valuer) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	v, err := value.(Uint64Valuer).Uint64Value()
+	if err != nil {
+		return nil, err
+	}
+
+	if !v.Valid {
+		return nil, nil
+	}
+
+	return pgio.AppendUint64(buf, v.Uint64), nil
+}
+
+type encodePlanLSNCodecBinaryInt64Valuer struct{}
+
+func (encodePlanLSNCodecBinaryInt64Valuer) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	v, err := value.(Int64Valuer).Int64Value()
+	if err != nil {
+		return nil, err
+	}
+
+	if !v.Valid {
+		return nil, nil
+	}
+
+	if v.Int64 < 0 {
+		return nil, fmt.Errorf("%d is less than minimum value for pg_lsn", v.Int64)
+	}
+
+	return pgio.AppendUint64(buf, uint64(v.Int64)), nil
+}
+
+type encodePlanLSNCodecTextUint64Valuer struct{}
+
+func (encodePlanLSNCodecTextUint64Valuer) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	v, err := value.(Uint64Valuer).Uint64Value()
+	if err != nil {
+		return nil, err
+	}
+
+	if !v.Valid {
+		return nil, nil
+	}
+
+	return append(buf, LSN(v.Uint64).String()...), nil
+}
+
+type encodePlanLSNCodecTextInt64Valuer struct{}
+
+func (encodePlanLSNCodecTextInt64Valuer) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	v, err := value.(Int64Valuer).Int64Value()
+	if err != nil {
+		return nil, err
+	}
+
+	if !v.Valid {
+		return nil, nil
+	}
+
+	if v.Int64 < 0 {
+		return nil, fmt.Errorf("%d is less than minimum value for pg_lsn", v.Int64)
+	}
+
+	return append(buf, LSN(v.Int64).String()...), nil
+}
+
+func (LSNCodec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan {
+	switch format {
+	case BinaryFormatCode:
+		switch target.(type) {
+		case Uint64Scanner:
+			return scanPlanBinaryLSNToUint64Scanner{}
+		case Int64Scanner:
+			return scanPlanBinaryLSNToInt64Scanner{}
+		case TextScanner:
+			return scanPlanBinaryLSNToTextScanner{}
+		}
+	case TextFormatCode:
+		switch target.(type) {
+		case Uint64Scanner:
+			return scanPlanTextAnyToLSNUint64Scanner{}
+		case Int64Scanner:
+			return scanPlanTextAnyToLSNInt64Scanner{}
+		case TextScanner:
+			return scanPlanTextAnyToTextScanner{}
+		}
+	}
+
+	return nil
+}
+
+type scanPlanBinaryLSNToUint64Scanner struct{}
+
+func (scanPlanBinaryLSNToUint64Scanner) Scan(src []byte, dst any) error {
+	s := (dst).(Uint64Scanner)
+
+	if src == nil {
+		return s.ScanUint64(Uint64{})
+	}
+
+	if len(src) != 8 {
+		return fmt.Errorf("invalid length for pg_lsn: %v", len(src))
+	}
+
+	return s.ScanUint64(Uint64{Uint64: binary.BigEndian.Uint64(src), Valid: true})
+}
+
+type scanPlanBinaryLSNToInt64Scanner struct{}
+
+func (scanPlanBinaryLSNToInt64Scanner) Scan(src []byte, dst any) error {
+	s := (dst).(Int64Scanner)
+
+	if src == nil {
+		return s.ScanInt64(Int8{})
+	}
+
+	if len(src) != 8 {
+		return fmt.Errorf("invalid length for pg_lsn: %v", len(src))
+	}
+
+	n := binary.BigEndian.Uint64(src)
+	if n > math.MaxInt64 {
+		return fmt.Errorf("pg_lsn value %d is greater than max value for int64", n)
+	}
+
+	return s.ScanInt64(Int8{Int64: int64(n), Valid: true})
+}
+
+type scanPlanBinaryLSNToTextScanner struct{}
+
+func (scanPlanBinaryLSNToTextScanner) Scan(src []byte, dst any) error {
+	s := (dst).(TextScanner)
+
+	if src == nil {
+		return s.ScanText(Text{})
+	}
+
+	if len(src) != 8 {
+		return fmt.Errorf("invalid length for pg_lsn: %v", len(src))
+	}
+
+	return s.ScanText(Text{String: LSN(binary.BigEndian.Uint64(src)).String(), Valid: true})
+}
+
+type scanPlanTextAnyToLSNUint64Scanner struct{}
+
+func (scanPlanTextAnyToLSNUint64Scanner) Scan(src []byte, dst any) error {
+	s := (dst).(Uint64Scanner)
+
+	if src == nil {
+		return s.ScanUint64(Uint64{})
+	}
+
+	lsn, err := ParseLSN(string(src))
+	if err != nil {
+		return err
+	}
+
+	return s.ScanUint64(Uint64{Uint64: uint64(lsn), Valid: true})
+}
+
+type scanPlanTextAnyToLSNInt64Scanner struct{}
+
+func (scanPlanTextAnyToLSNInt64Scanner) Scan(src []byte, dst any) error {
+	s := (dst).(Int64Scanner)
+
+	if src == nil {
+		return s.ScanInt64(Int8{})
+	}
+
+	lsn, err := ParseLSN(string(src))
+	if err != nil {
+		return err
+	}
+
+	if lsn > math.MaxInt64 {
+		return fmt.Errorf("pg_lsn value %d is greater than max value for int64", lsn)
+	}
+
+	return s.ScanInt64(Int8{Int64: int64(lsn), Valid: true})
+}
+
+func (c LSNCodec) DecodeDatabaseSQLValue(m *Map, oid uint32, format int16, src []byte) (driver.Value, error) {
+	return codecDecodeToTextFormat(c, m, oid, format, src)
+}
+
+func (c LSNCodec) DecodeValue(m *Map, oid uint32, format int16, src []byte) (any, error) {
+	if src == nil {
+		return nil, nil
+	}
+
+	var lsn LSN
+	err := codecScan(c, m, oid, format, src, &lsn)
+	if err != nil {
+		return nil, err
+	}
+	return lsn, nil
+}
@@ -0,0 +1,47 @@
+package pgtype_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLSNCodec(t *testing.T) {
+	skipCockroachDB(t, "Server does not support type pg_lsn")
+
+	pgxtest.RunValueRoundTripTests(context.Background(), t, defaultConnTestRunner, nil, "pg_lsn", []pgxtest.ValueRoundTripTest{
+		{pgtype.LSN(0x16B374D848), new(pgtype.LSN), isExpectedEq(pgtype.LSN(0x16B374D848))},
+		{pgtype.LSN(0xFFFFFFFFFFFFFFFF), new(pgtype.LSN), isExpectedEq(pgtype.LSN(0xFFFFFFFFFFFFFFFF))},
+		{pgtype.LSN(0x16B374D848), new(string), isExpectedEq("16/B374D848")},
+		{pgtype.LSN(0x16B374D848), new(uint64), isExpectedEq(uint64(0x16B374D848))},
+		{uint64(0x16B374D848), new(pgtype.LSN), isExpectedEq(pgtype.LSN(0x16B374D848))},
+		{pgtype.Uint64{Uint64: 0x16B374D848, Valid: true}, new(pgtype.Uint64), isExpectedEq(pgtype.Uint64{Uint64: 0x16B374D848, Valid: true})},
+		{pgtype.Uint64{}, new(pgtype.Uint64), isExpectedEq(pgtype.Uint64{})},
+		{nil, new(*pgtype.LSN), isExpectedEq((*pgtype.LSN)(nil))},
+	})
+}
+
+func TestParseLSN(t *testing.T) {
+	for _, tt := range []struct {
+		s   string
+		lsn pgtype.LSN
+	}{
+		{"0/0", 0},
+		{"16/B374D848", 0x16B374D848},
+		{"FFFFFFFF/FFFFFFFF", 0xFFFFFFFFFFFFFFFF},
+	} {
+		lsn, err := pgtype.ParseLSN(tt.s)
+		require.NoError(t, err)
+		assert.Equal(t, tt.lsn, lsn)
+		assert.Equal(t, tt.s, lsn.String())
+	}
+
+	for _, s := range []string{"", "16", "16/", "/B374D848", "G/0", "100000000/0"} {
+		_, err := pgtype.ParseLSN(s)
+		assert.Errorf(t, err, "%q", s)
+	}
+}
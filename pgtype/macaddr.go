@@ -5,6 +5,7 @@ import (
 	"net"
 )
 
+// MacaddrCodec is the codec for macaddr and macaddr8. Both are scanned into and encoded from net.HardwareAddr.
 type MacaddrCodec struct{}
 
 func (MacaddrCodec) FormatSupported(format int16) bool {
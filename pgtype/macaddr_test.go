@@ -29,8 +29,7 @@ func isExpectedEqHardwareAddr(a any) func(any) bool {
 func TestMacaddrCodec(t *testing.T) {
 	skipCockroachDB(t, "Server does not support type macaddr")
 
-	// Only testing known OID query exec modes as net.HardwareAddr could map to macaddr or macaddr8.
-	pgxtest.RunValueRoundTripTests(context.Background(), t, defaultConnTestRunner, pgxtest.KnownOIDQueryExecModes, "macaddr", []pgxtest.ValueRoundTripTest{
+	pgxtest.RunValueRoundTripTests(context.Background(), t, defaultConnTestRunner, nil, "macaddr", []pgxtest.ValueRoundTripTest{
 		{
 			mustParseMacaddr(t, "01:23:45:67:89:ab"),
 			new(net.HardwareAddr),
@@ -49,6 +48,7 @@ func TestMacaddrCodec(t *testing.T) {
 		{nil, new(*net.HardwareAddr), isExpectedEq((*net.HardwareAddr)(nil))},
 	})
 
+	// Only testing known OID query exec modes as net.HardwareAddr maps to macaddr by default.
 	pgxtest.RunValueRoundTripTests(context.Background(), t, defaultConnTestRunner, pgxtest.KnownOIDQueryExecModes, "macaddr8", []pgxtest.ValueRoundTripTest{
 		{
 			mustParseMacaddr(t, "01:23:45:67:89:ab:01:08"),
@@ -68,3 +68,47 @@ func TestMacaddrCodec(t *testing.T) {
 		{nil, new(*net.HardwareAddr), isExpectedEq((*net.HardwareAddr)(nil))},
 	})
 }
+
+func TestMacaddrArrayCodec(t *testing.T) {
+	skipCockroachDB(t, "Server does not support type macaddr")
+
+	pgxtest.RunValueRoundTripTests(context.Background(), t, defaultConnTestRunner, nil, "macaddr[]", []pgxtest.ValueRoundTripTest{
+		{
+			[]net.HardwareAddr{mustParseMacaddr(t, "01:23:45:67:89:ab"), nil},
+			new([]net.HardwareAddr),
+			isExpectedEqHardwareAddrSlice([]net.HardwareAddr{mustParseMacaddr(t, "01:23:45:67:89:ab"), nil}),
+		},
+		{[]net.HardwareAddr{}, new([]net.HardwareAddr), isExpectedEqHardwareAddrSlice([]net.HardwareAddr{})},
+	})
+
+	pgxtest.RunValueRoundTripTests(context.Background(), t, defaultConnTestRunner, pgxtest.KnownOIDQueryExecModes, "macaddr8[]", []pgxtest.ValueRoundTripTest{
+		{
+			[]net.HardwareAddr{mustParseMacaddr(t, "01:23:45:67:89:ab:01:08"), nil},
+			new([]net.HardwareAddr),
+			isExpectedEqHardwareAddrSlice([]net.HardwareAddr{mustParseMacaddr(t, "01:23:45:67:89:ab:01:08"), nil}),
+		},
+		{
+			[]string{"01:23:45:67:89:ab:01:08"},
+			new([]net.HardwareAddr),
+			isExpectedEqHardwareAddrSlice([]net.HardwareAddr{mustParseMacaddr(t, "01:23:45:67:89:ab:01:08")}),
+		},
+	})
+}
+
+func isExpectedEqHardwareAddrSlice(a []net.HardwareAddr) func(any) bool {
+	return func(v any) bool {
+		vv := v.([]net.HardwareAddr)
+
+		if len(a) != len(vv) {
+			return false
+		}
+
+		for i := range a {
+			if !isExpectedEqHardwareAddr(a[i])(vv[i]) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"reflect"
+	"slices"
 
 	"github.com/jackc/pgx/v5/internal/pgio"
 )
@@ -441,3 +442,190 @@ func (r Multirange[T]) ScanIndex(i int) any {
 func (r Multirange[T]) ScanIndexType() any {
 	return new(T)
 }
+
+// The following functions operate on multiranges of Range[T]. T must be a type that the comparison methods of Range
+// support. An error is returned for any other T.
+
+// MultirangeContains returns true if v is within any range of m.
+func MultirangeContains[T any](m Multirange[Range[T]], v T) (bool, error) {
+	for _, r := range m {
+		ok, err := r.Contains(v)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// MultirangeUnion returns the union of a and b. The result is in the canonical form that PostgreSQL uses for
+// multiranges: the ranges are normalized, sorted, and neither overlap nor are adjacent. NULL ranges are ignored. If a or
+// b is NULL, the result is NULL.
+func MultirangeUnion[T any](a, b Multirange[Range[T]]) (Multirange[Range[T]], error) {
+	if a.IsNull() || b.IsNull() {
+		return nil, nil
+	}
+
+	ranges := make([]Range[T], 0, len(a)+len(b))
+	ranges = append(ranges, a...)
+	ranges = append(ranges, b...)
+	return multirangeCanonical(ranges)
+}
+
+// MultirangeIntersect returns the intersection of a and b. The result is in the canonical form that PostgreSQL uses for
+// multiranges. NULL ranges are ignored. If a or b is NULL, the result is NULL.
+func MultirangeIntersect[T any](a, b Multirange[Range[T]]) (Multirange[Range[T]], error) {
+	if a.IsNull() || b.IsNull() {
+		return nil, nil
+	}
+
+	var ranges []Range[T]
+	for _, ar := range a {
+		ar, err := ar.Normalize()
+		if err != nil {
+			return nil, err
+		}
+		if !ar.Valid || ar.LowerType == Empty {
+			continue
+		}
+
+		for _, br := range b {
+			br, err := br.Normalize()
+			if err != nil {
+				return nil, err
+			}
+			if !br.Valid || br.LowerType == Empty {
+				continue
+			}
+
+			r := ar
+			c, err := rangeCompareLower(br, r)
+			if err != nil {
+				return nil, err
+			}
+			if c > 0 {
+				r.Lower, r.LowerType = br.Lower, br.LowerType
+			}
+			c, err = rangeCompareUpper(br, r)
+			if err != nil {
+				return nil, err
+			}
+			if c < 0 {
+				r.Upper, r.UpperType = br.Upper, br.UpperType
+			}
+			ranges = append(ranges, r)
+		}
+	}
+
+	return multirangeCanonical(ranges)
+}
+
+// multirangeCanonical normalizes, sorts, and merges ranges. It modifies ranges.
+func multirangeCanonical[T any](ranges []Range[T]) (Multirange[Range[T]], error) {
+	for i := range ranges {
+		r, err := ranges[i].Normalize()
+		if err != nil {
+			return nil, err
+		}
+		ranges[i] = r
+	}
+
+	// Empty and NULL ranges have no bounds to compare.
+	ranges = slices.DeleteFunc(ranges, func(r Range[T]) bool { return !r.Valid || r.LowerType == Empty })
+
+	var sortErr error
+	slices.SortFunc(ranges, func(a, b Range[T]) int {
+		c, err := rangeCompareLower(a, b)
+		if err != nil && sortErr == nil {
+			sortErr = err
+		}
+		return c
+	})
+	if sortErr != nil {
+		return nil, sortErr
+	}
+
+	result := Multirange[Range[T]]{}
+	for _, r := range ranges {
+		if len(result) > 0 {
+			last := &result[len(result)-1]
+			touches, err := rangeTouches(*last, r)
+			if err != nil {
+				return nil, err
+			}
+			if touches {
+				c, err := rangeCompareUpper(r, *last)
+				if err != nil {
+					return nil, err
+				}
+				if c > 0 {
+					last.Upper, last.UpperType = r.Upper, r.UpperType
+				}
+				continue
+			}
+		}
+
+		result = append(result, r)
+	}
+
+	return result, nil
+}
+
+// rangeCompareLower compares the lower bounds of a and b. An unbounded lower bound is lower than any value and an
+// inclusive lower bound is lower than an exclusive lower bound of the same value.
+func rangeCompareLower[T any](a, b Range[T]) (int, error) {
+	if a.LowerType == Unbounded || b.LowerType == Unbounded {
+		return rangeCompareBoundTypes(a.LowerType == Unbounded, b.LowerType == Unbounded), nil
+	}
+
+	c, err := rangeCompare(a.Lower, b.Lower)
+	if err != nil || c != 0 {
+		return c, err
+	}
+	return rangeCompareBoundTypes(a.LowerType == Inclusive, b.LowerType == Inclusive), nil
+}
+
+// rangeCompareUpper compares the upper bounds of a and b. An unbounded upper bound is higher than any value and an
+// inclusive upper bound is higher than an exclusive upper bound of the same value.
+func rangeCompareUpper[T any](a, b Range[T]) (int, error) {
+	if a.UpperType == Unbounded || b.UpperType == Unbounded {
+		return -rangeCompareBoundTypes(a.UpperType == Unbounded, b.UpperType == Unbounded), nil
+	}
+
+	c, err := rangeCompare(a.Upper, b.Upper)
+	if err != nil || c != 0 {
+		return c, err
+	}
+	return -rangeCompareBoundTypes(a.UpperType == Inclusive, b.UpperType == Inclusive), nil
+}
+
+// rangeCompareBoundTypes returns -1 if only a is set, 1 if only b is set, and 0 otherwise.
+func rangeCompareBoundTypes(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// rangeTouches returns true if the normalized ranges a and b overlap or are adjacent. The lower bound of a must not be
+// above the lower bound of b.
+func rangeTouches[T any](a, b Range[T]) (bool, error) {
+	if a.UpperType == Unbounded || b.LowerType == Unbounded {
+		return true, nil
+	}
+
+	c, err := rangeCompare(b.Lower, a.Upper)
+	if err != nil {
+		return false, err
+	}
+	if c == 0 {
+		return a.UpperType == Inclusive || b.LowerType == Inclusive, nil
+	}
+	return c < 0, nil
+}
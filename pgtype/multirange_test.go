@@ -112,3 +112,143 @@ func TestMultirangeCodecDecodeValue(t *testing.T) {
 		}
 	})
 }
+
+func TestMultirangeCodecPlainSlice(t *testing.T) {
+	skipPostgreSQLVersionLessThan(t, 14)
+	skipCockroachDB(t, "Server does not support range types (see https://github.com/cockroachdb/cockroach/issues/27791)")
+
+	pgxtest.RunValueRoundTripTests(context.Background(), t, defaultConnTestRunner, nil, "int4multirange", []pgxtest.ValueRoundTripTest{
+		{
+			[]pgtype.Range[int32](nil),
+			new([]pgtype.Range[int32]),
+			func(a any) bool { return reflect.DeepEqual([]pgtype.Range[int32](nil), a) },
+		},
+		{
+			[]pgtype.Range[int32]{},
+			new([]pgtype.Range[int32]),
+			func(a any) bool { return reflect.DeepEqual([]pgtype.Range[int32]{}, a) },
+		},
+		{
+			[]pgtype.Range[int32]{
+				{Lower: 1, Upper: 5, LowerType: pgtype.Inclusive, UpperType: pgtype.Exclusive, Valid: true},
+				{Lower: 7, Upper: 9, LowerType: pgtype.Inclusive, UpperType: pgtype.Exclusive, Valid: true},
+			},
+			new([]pgtype.Range[int32]),
+			func(a any) bool {
+				return reflect.DeepEqual([]pgtype.Range[int32]{
+					{Lower: 1, Upper: 5, LowerType: pgtype.Inclusive, UpperType: pgtype.Exclusive, Valid: true},
+					{Lower: 7, Upper: 9, LowerType: pgtype.Inclusive, UpperType: pgtype.Exclusive, Valid: true},
+				}, a)
+			},
+		},
+	})
+}
+
+func TestMultirangeSetOperations(t *testing.T) {
+	r := func(lower, upper int32) pgtype.Range[int32] {
+		return pgtype.Range[int32]{Lower: lower, Upper: upper, LowerType: pgtype.Inclusive, UpperType: pgtype.Exclusive, Valid: true}
+	}
+	unboundedAbove := func(lower int32) pgtype.Range[int32] {
+		return pgtype.Range[int32]{Lower: lower, LowerType: pgtype.Inclusive, UpperType: pgtype.Unbounded, Valid: true}
+	}
+
+	a := pgtype.Multirange[pgtype.Range[int32]]{r(1, 5), r(10, 15)}
+	b := pgtype.Multirange[pgtype.Range[int32]]{
+		{Lower: 4, Upper: 7, LowerType: pgtype.Exclusive, UpperType: pgtype.Inclusive, Valid: true}, // [5,8)
+		unboundedAbove(14),
+	}
+
+	require.Equal(t, pgtype.Multirange[pgtype.Range[int32]]{r(1, 8), unboundedAbove(10)}, multirangeUnion(t, a, b))
+	require.Equal(t, pgtype.Multirange[pgtype.Range[int32]]{r(14, 15)}, multirangeIntersect(t, a, b))
+	require.Equal(t, pgtype.Multirange[pgtype.Range[int32]]{}, multirangeIntersect(t, a, pgtype.Multirange[pgtype.Range[int32]]{r(5, 10)}))
+
+	// Overlapping and unsorted ranges in one multirange are merged.
+	require.Equal(t,
+		pgtype.Multirange[pgtype.Range[int32]]{r(1, 6)},
+		multirangeUnion(t, pgtype.Multirange[pgtype.Range[int32]]{r(3, 6), r(1, 4)}, pgtype.Multirange[pgtype.Range[int32]]{}),
+	)
+
+	require.Nil(t, multirangeUnion(t, a, nil))
+	require.Nil(t, multirangeIntersect(t, nil, b))
+
+	require.True(t, multirangeContains(t, a, 1))
+	require.True(t, multirangeContains(t, a, 14))
+	require.False(t, multirangeContains(t, a, 5))
+	require.False(t, multirangeContains(t, a, 20))
+
+	// Continuous ranges are merged only when they overlap or a bound is inclusive.
+	f := func(lower float64, lowerType pgtype.BoundType, upper float64, upperType pgtype.BoundType) pgtype.Range[float64] {
+		return pgtype.Range[float64]{Lower: lower, Upper: upper, LowerType: lowerType, UpperType: upperType, Valid: true}
+	}
+	require.Equal(t,
+		pgtype.Multirange[pgtype.Range[float64]]{f(1, pgtype.Inclusive, 2, pgtype.Exclusive), f(2, pgtype.Exclusive, 3, pgtype.Exclusive)},
+		multirangeUnion(t,
+			pgtype.Multirange[pgtype.Range[float64]]{f(2, pgtype.Exclusive, 3, pgtype.Exclusive)},
+			pgtype.Multirange[pgtype.Range[float64]]{f(1, pgtype.Inclusive, 2, pgtype.Exclusive)},
+		),
+	)
+	require.Equal(t,
+		pgtype.Multirange[pgtype.Range[float64]]{f(1, pgtype.Inclusive, 3, pgtype.Exclusive)},
+		multirangeUnion(t,
+			pgtype.Multirange[pgtype.Range[float64]]{f(2, pgtype.Inclusive, 3, pgtype.Exclusive)},
+			pgtype.Multirange[pgtype.Range[float64]]{f(1, pgtype.Inclusive, 2, pgtype.Exclusive)},
+		),
+	)
+}
+
+func multirangeUnion[T any](t *testing.T, a, b pgtype.Multirange[pgtype.Range[T]]) pgtype.Multirange[pgtype.Range[T]] {
+	t.Helper()
+	m, err := pgtype.MultirangeUnion(a, b)
+	require.NoError(t, err)
+	return m
+}
+
+func multirangeIntersect[T any](t *testing.T, a, b pgtype.Multirange[pgtype.Range[T]]) pgtype.Multirange[pgtype.Range[T]] {
+	t.Helper()
+	m, err := pgtype.MultirangeIntersect(a, b)
+	require.NoError(t, err)
+	return m
+}
+
+func multirangeContains[T any](t *testing.T, m pgtype.Multirange[pgtype.Range[T]], v T) bool {
+	t.Helper()
+	ok, err := pgtype.MultirangeContains(m, v)
+	require.NoError(t, err)
+	return ok
+}
+
+func TestMultirangeSetOperationsPgtypeBounds(t *testing.T) {
+	r := func(lower, upper int64) pgtype.Range[pgtype.Int8] {
+		return pgtype.Range[pgtype.Int8]{
+			Lower:     pgtype.Int8{Int64: lower, Valid: true},
+			Upper:     pgtype.Int8{Int64: upper, Valid: true},
+			LowerType: pgtype.Inclusive,
+			UpperType: pgtype.Inclusive,
+			Valid:     true,
+		}
+	}
+	exclusive := func(lower, upper int64) pgtype.Range[pgtype.Int8] {
+		rng := r(lower, upper)
+		rng.UpperType = pgtype.Exclusive
+		return rng
+	}
+
+	a := pgtype.Multirange[pgtype.Range[pgtype.Int8]]{r(1, 4), r(10, 14)}
+	b := pgtype.Multirange[pgtype.Range[pgtype.Int8]]{r(5, 7)}
+
+	require.Equal(t, pgtype.Multirange[pgtype.Range[pgtype.Int8]]{exclusive(1, 8), exclusive(10, 15)}, multirangeUnion(t, a, b))
+	require.Equal(t, pgtype.Multirange[pgtype.Range[pgtype.Int8]]{exclusive(3, 5)}, multirangeIntersect(t, a, pgtype.Multirange[pgtype.Range[pgtype.Int8]]{r(3, 4)}))
+	require.True(t, multirangeContains(t, a, pgtype.Int8{Int64: 14, Valid: true}))
+	require.False(t, multirangeContains(t, a, pgtype.Int8{Int64: 15, Valid: true}))
+}
+
+func TestMultirangeSetOperationsUnsupportedType(t *testing.T) {
+	type point struct{ X, Y int }
+	r := pgtype.Range[point]{Lower: point{1, 2}, Upper: point{3, 4}, LowerType: pgtype.Inclusive, UpperType: pgtype.Exclusive, Valid: true}
+	m := pgtype.Multirange[pgtype.Range[point]]{r, r}
+
+	_, err := pgtype.MultirangeUnion(m, m)
+	require.Error(t, err)
+	_, err = pgtype.MultirangeContains(m, point{2, 3})
+	require.Error(t, err)
+}
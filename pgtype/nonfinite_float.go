@@ -0,0 +1,197 @@
+package pgtype
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// NonFiniteFloatPolicy controls how Float4Codec and Float8Codec handle the non-finite values NaN, Infinity, and
+// -Infinity. PostgreSQL accepts these values, but they often cause problems elsewhere. e.g. encoding/json cannot
+// marshal them. A policy other than NonFiniteFloatAllow makes them fail or disappear at the database boundary instead.
+//
+// The policy is configured by registering the codec with it. The array types must be registered as well for the policy
+// to apply to array elements:
+//
+//	codec := pgtype.Float8Codec{NonFinite: pgtype.NonFiniteFloatError}
+//	float8Type := &pgtype.Type{Name: "float8", OID: pgtype.Float8OID, Codec: codec}
+//	m.RegisterType(float8Type)
+//	arrayCodec := &pgtype.ArrayCodec{ElementType: float8Type}
+//	m.RegisterType(&pgtype.Type{Name: "_float8", OID: pgtype.Float8ArrayOID, Codec: arrayCodec})
+type NonFiniteFloatPolicy int8
+
+const (
+	// NonFiniteFloatAllow encodes and scans non-finite values like any other value. This is the default.
+	NonFiniteFloatAllow NonFiniteFloatPolicy = iota
+
+	// NonFiniteFloatError returns an error when encoding or scanning a non-finite value.
+	NonFiniteFloatError
+
+	// NonFiniteFloatNull encodes a non-finite value as NULL and scans a non-finite value as if it were NULL. Scanning into
+	// a target that cannot represent NULL such as a *float64 fails as usual.
+	NonFiniteFloatNull
+)
+
+func (p NonFiniteFloatPolicy) String() string {
+	switch p {
+	case NonFiniteFloatAllow:
+		return "allow"
+	case NonFiniteFloatError:
+		return "error"
+	case NonFiniteFloatNull:
+		return "null"
+	default:
+		return fmt.Sprintf("NonFiniteFloatPolicy(%d)", int8(p))
+	}
+}
+
+func isNonFiniteFloat(f float64) bool {
+	return math.IsNaN(f) || math.IsInf(f, 0)
+}
+
+// isNonFiniteFloatSrc returns true if src is a non-finite float4 (size 4) or float8 (size 8) in format. It returns
+// false if src is not a valid float so the underlying plan can report the error.
+func isNonFiniteFloatSrc(format int16, size int, src []byte) bool {
+	if src == nil {
+		return false
+	}
+
+	switch format {
+	case BinaryFormatCode:
+		switch {
+		case size == 4 && len(src) == 4:
+			return isNonFiniteFloat(float64(math.Float32frombits(binary.BigEndian.Uint32(src))))
+		case size == 8 && len(src) == 8:
+			return isNonFiniteFloat(math.Float64frombits(binary.BigEndian.Uint64(src)))
+		}
+	case TextFormatCode:
+		f, err := strconv.ParseFloat(string(src), size*8)
+		return err == nil && isNonFiniteFloat(f)
+	}
+
+	return false
+}
+
+// wrapNonFiniteFloatEncodePlan wraps plan so that policy is applied to the values it encodes. float4 is true if the value
+// is encoded as a float4, in which case a float64 that overflows a float32 is also non-finite.
+func wrapNonFiniteFloatEncodePlan(policy NonFiniteFloatPolicy, float4 bool, plan EncodePlan) EncodePlan {
+	if policy == NonFiniteFloatAllow || plan == nil {
+		return plan
+	}
+	return &encodePlanNonFiniteFloat{policy: policy, float4: float4, next: plan}
+}
+
+type encodePlanNonFiniteFloat struct {
+	policy NonFiniteFloatPolicy
+	float4 bool
+	next   EncodePlan
+}
+
+func (plan *encodePlanNonFiniteFloat) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	var f float64
+	switch v := value.(type) {
+	case float32:
+		f = float64(v)
+	case float64:
+		f = v
+	case Float64Valuer:
+		n, err := v.Float64Value()
+		if err != nil {
+			return nil, err
+		}
+		if !n.Valid {
+			return nil, nil
+		}
+		// Do not call Float64Value again.
+		value = n
+		f = n.Float64
+	default:
+		return plan.next.Encode(value, buf)
+	}
+
+	if plan.float4 {
+		f = float64(float32(f))
+	}
+
+	if isNonFiniteFloat(f) {
+		if plan.policy == NonFiniteFloatNull {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cannot encode non-finite float %v", f)
+	}
+
+	return plan.next.Encode(value, buf)
+}
+
+// wrapNonFiniteFloatScanPlan wraps plan so that policy is applied to the values it scans. size is 4 for float4 and 8 for
+// float8.
+func wrapNonFiniteFloatScanPlan(policy NonFiniteFloatPolicy, format int16, size int, plan ScanPlan) ScanPlan {
+	if policy == NonFiniteFloatAllow || plan == nil {
+		return plan
+	}
+	return &scanPlanNonFiniteFloat{policy: policy, format: format, size: size, next: plan}
+}
+
+type scanPlanNonFiniteFloat struct {
+	policy NonFiniteFloatPolicy
+	format int16
+	size   int
+	next   ScanPlan
+}
+
+func (plan *scanPlanNonFiniteFloat) Scan(src []byte, dst any) error {
+	if isNonFiniteFloatSrc(plan.format, plan.size, src) {
+		if plan.policy == NonFiniteFloatNull {
+			return plan.next.Scan(nil, dst)
+		}
+		return fmt.Errorf("cannot scan non-finite float %s", nonFiniteFloatSrcString(plan.format, plan.size, src))
+	}
+
+	return plan.next.Scan(src, dst)
+}
+
+func nonFiniteFloatSrcString(format int16, size int, src []byte) string {
+	if format == TextFormatCode {
+		return string(src)
+	}
+	if size == 4 {
+		return strconv.FormatFloat(float64(math.Float32frombits(binary.BigEndian.Uint32(src))), 'f', -1, 32)
+	}
+	return strconv.FormatFloat(math.Float64frombits(binary.BigEndian.Uint64(src)), 'f', -1, 64)
+}
+
+// planNonFiniteFloatToNullScan returns a plan for scanning into a pointer to a pointer such as a **float64 with the
+// NonFiniteFloatNull policy. The plan sets the pointer to nil for a non-finite value. Otherwise, the generic pointer
+// handling of the Map would allocate the target before the value is known to be NULL. It returns nil for other targets.
+func planNonFiniteFloatToNullScan(m *Map, oid uint32, format int16, size int, target any) ScanPlan {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Pointer || targetValue.IsNil() || targetValue.Elem().Kind() != reflect.Pointer {
+		return nil
+	}
+
+	return &scanPlanNonFiniteFloatToNullPointer{m: m, oid: oid, format: format, size: size}
+}
+
+type scanPlanNonFiniteFloatToNullPointer struct {
+	m      *Map
+	oid    uint32
+	format int16
+	size   int
+}
+
+func (plan *scanPlanNonFiniteFloatToNullPointer) Scan(src []byte, dst any) error {
+	ptr := reflect.ValueOf(dst).Elem()
+	if src == nil || isNonFiniteFloatSrc(plan.format, plan.size, src) {
+		ptr.SetZero()
+		return nil
+	}
+
+	elem := reflect.New(ptr.Type().Elem())
+	if err := plan.m.PlanScan(plan.oid, plan.format, elem.Interface()).Scan(src, elem.Interface()); err != nil {
+		return err
+	}
+	ptr.Set(elem)
+	return nil
+}
@@ -0,0 +1,164 @@
+package pgtype_test
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMapWithNonFiniteFloatPolicy(policy pgtype.NonFiniteFloatPolicy) *pgtype.Map {
+	m := pgtype.NewMap()
+	float4Type := &pgtype.Type{Name: "float4", OID: pgtype.Float4OID, Codec: pgtype.Float4Codec{NonFinite: policy}}
+	float8Type := &pgtype.Type{Name: "float8", OID: pgtype.Float8OID, Codec: pgtype.Float8Codec{NonFinite: policy}}
+	m.RegisterType(float4Type)
+	m.RegisterType(float8Type)
+	m.RegisterType(&pgtype.Type{Name: "_float4", OID: pgtype.Float4ArrayOID, Codec: &pgtype.ArrayCodec{ElementType: float4Type}})
+	m.RegisterType(&pgtype.Type{Name: "_float8", OID: pgtype.Float8ArrayOID, Codec: &pgtype.ArrayCodec{ElementType: float8Type}})
+	return m
+}
+
+func nonFiniteFloatSrcs(oid uint32, format int16) [][]byte {
+	if format == pgtype.TextFormatCode {
+		return [][]byte{[]byte("NaN"), []byte("Infinity"), []byte("-Infinity")}
+	}
+
+	var srcs [][]byte
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if oid == pgtype.Float4OID {
+			srcs = append(srcs, binary.BigEndian.AppendUint32(nil, math.Float32bits(float32(f))))
+		} else {
+			srcs = append(srcs, binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+		}
+	}
+	return srcs
+}
+
+func TestNonFiniteFloatPolicyAllow(t *testing.T) {
+	m := newMapWithNonFiniteFloatPolicy(pgtype.NonFiniteFloatAllow)
+
+	for _, format := range []int16{pgtype.TextFormatCode, pgtype.BinaryFormatCode} {
+		buf, err := m.Encode(pgtype.Float8OID, format, math.Inf(1), nil)
+		require.NoError(t, err)
+		var f float64
+		err = m.Scan(pgtype.Float8OID, format, buf, &f)
+		require.NoError(t, err)
+		assert.True(t, math.IsInf(f, 1))
+	}
+}
+
+func TestNonFiniteFloatPolicyError(t *testing.T) {
+	m := newMapWithNonFiniteFloatPolicy(pgtype.NonFiniteFloatError)
+
+	for _, oid := range []uint32{pgtype.Float4OID, pgtype.Float8OID} {
+		for _, format := range []int16{pgtype.TextFormatCode, pgtype.BinaryFormatCode} {
+			for _, value := range []any{
+				math.NaN(),
+				math.Inf(1),
+				float32(math.Inf(-1)),
+				pgtype.Float8{Float64: math.Inf(1), Valid: true},
+				&pgtype.Float8{Float64: math.NaN(), Valid: true},
+			} {
+				_, err := m.Encode(oid, format, value, nil)
+				require.Errorf(t, err, "oid: %d, format: %d, value: %v", oid, format, value)
+			}
+
+			buf, err := m.Encode(oid, format, 1.5, nil)
+			require.NoError(t, err)
+			var f float64
+			err = m.Scan(oid, format, buf, &f)
+			require.NoError(t, err)
+			assert.Equal(t, 1.5, f)
+
+			buf, err = m.Encode(oid, format, pgtype.Float8{}, nil)
+			require.NoError(t, err)
+			require.Nil(t, buf)
+
+			for _, src := range nonFiniteFloatSrcs(oid, format) {
+				for _, dst := range []any{new(float32), new(float64), new(*float64), new(pgtype.Float8), new(sql.NullFloat64), new(any)} {
+					err := m.Scan(oid, format, src, dst)
+					require.Errorf(t, err, "oid: %d, format: %d, src: %v, dst: %T", oid, format, src, dst)
+				}
+			}
+		}
+	}
+
+	// A float64 that overflows a float4.
+	_, err := m.Encode(pgtype.Float4OID, pgtype.BinaryFormatCode, 1e300, nil)
+	require.Error(t, err)
+	_, err = m.Encode(pgtype.Float8OID, pgtype.BinaryFormatCode, 1e300, nil)
+	require.NoError(t, err)
+
+	// Array elements.
+	_, err = m.Encode(pgtype.Float8ArrayOID, pgtype.BinaryFormatCode, []float64{1, math.NaN()}, nil)
+	require.Error(t, err)
+}
+
+func TestNonFiniteFloatPolicyNull(t *testing.T) {
+	m := newMapWithNonFiniteFloatPolicy(pgtype.NonFiniteFloatNull)
+
+	for _, oid := range []uint32{pgtype.Float4OID, pgtype.Float8OID} {
+		for _, format := range []int16{pgtype.TextFormatCode, pgtype.BinaryFormatCode} {
+			for _, value := range []any{math.NaN(), math.Inf(1), float32(math.Inf(-1)), pgtype.Float8{Float64: math.Inf(1), Valid: true}} {
+				buf, err := m.Encode(oid, format, value, nil)
+				require.NoErrorf(t, err, "oid: %d, format: %d, value: %v", oid, format, value)
+				require.Nilf(t, buf, "oid: %d, format: %d, value: %v", oid, format, value)
+			}
+
+			for _, src := range nonFiniteFloatSrcs(oid, format) {
+				msg := []any{"oid: %d, format: %d, src: %v", oid, format, src}
+
+				p := new(float64)
+				err := m.Scan(oid, format, src, &p)
+				require.NoError(t, err, msg...)
+				assert.Nil(t, p, msg...)
+
+				f8 := pgtype.Float8{Float64: 1, Valid: true}
+				err = m.Scan(oid, format, src, &f8)
+				require.NoError(t, err, msg...)
+				assert.False(t, f8.Valid, msg...)
+
+				nf := sql.NullFloat64{Float64: 1, Valid: true}
+				err = m.Scan(oid, format, src, &nf)
+				require.NoError(t, err, msg...)
+				assert.False(t, nf.Valid, msg...)
+
+				var v any = 1
+				err = m.Scan(oid, format, src, &v)
+				require.NoError(t, err, msg...)
+				assert.Nil(t, v, msg...)
+
+				var f float64
+				err = m.Scan(oid, format, src, &f)
+				require.Error(t, err, msg...)
+			}
+
+			buf, err := m.Encode(oid, format, 2.5, nil)
+			require.NoError(t, err)
+			p := new(float64)
+			err = m.Scan(oid, format, buf, &p)
+			require.NoError(t, err)
+			require.NotNil(t, p)
+			assert.Equal(t, 2.5, *p)
+
+			err = m.Scan(oid, format, nil, &p)
+			require.NoError(t, err)
+			assert.Nil(t, p)
+		}
+	}
+
+	buf, err := m.Encode(pgtype.Float8ArrayOID, pgtype.TextFormatCode, []float64{1, math.NaN()}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "{1,NULL}", string(buf))
+
+	var ps []*float64
+	err = m.Scan(pgtype.Float8ArrayOID, pgtype.TextFormatCode, []byte("{1,Infinity}"), &ps)
+	require.NoError(t, err)
+	require.Len(t, ps, 2)
+	assert.Equal(t, 1.0, *ps[0])
+	assert.Nil(t, ps[1])
+}
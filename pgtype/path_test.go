@@ -70,6 +70,8 @@ func TestPathTranscode(t *testing.T) {
 				Valid:  true,
 			}),
 		},
+		{[][2]float64{{3.14, 1.678}, {7.1, 5.234}}, new([][2]float64), isExpectedEq([][2]float64{{3.14, 1.678}, {7.1, 5.234}})},
+		{[][2]float64(nil), new([][2]float64), isExpectedEq([][2]float64(nil))},
 		{pgtype.Path{}, new(pgtype.Path), isExpectedEqPath(pgtype.Path{})},
 		{nil, new(pgtype.Path), isExpectedEqPath(pgtype.Path{})},
 	})
@@ -3,11 +3,13 @@ package pgtype
 import (
 	"database/sql"
 	"database/sql/driver"
+	"encoding"
 	"errors"
 	"fmt"
 	"net"
 	"net/netip"
 	"reflect"
+	"sort"
 	"time"
 )
 
@@ -45,6 +47,7 @@ const (
 	CircleArrayOID         = 719
 	UnknownOID             = 705
 	Macaddr8OID            = 774
+	Macaddr8ArrayOID       = 775
 	MacaddrOID             = 829
 	InetOID                = 869
 	BoolArrayOID           = 1000
@@ -94,8 +97,12 @@ const (
 	NumericOID             = 1700
 	RecordOID              = 2249
 	RecordArrayOID         = 2287
+	TxidSnapshotArrayOID   = 2949
 	UUIDOID                = 2950
 	UUIDArrayOID           = 2951
+	TxidSnapshotOID        = 2970
+	PgLSNOID               = 3220
+	PgLSNArrayOID          = 3221
 	JSONBOID               = 3802
 	JSONBArrayOID          = 3807
 	DaterangeOID           = 3912
@@ -118,6 +125,8 @@ const (
 	TstzmultirangeOID      = 4534
 	DatemultirangeOID      = 4535
 	Int8multirangeOID      = 4536
+	PgSnapshotOID          = 5038
+	PgSnapshotArrayOID     = 5039
 	XID8OID                = 5069
 	Int4multirangeArrayOID = 6150
 	NummultirangeArrayOID  = 6151
@@ -177,6 +186,49 @@ type Codec interface {
 	DecodeValue(m *Map, oid uint32, format int16, src []byte) (any, error)
 }
 
+// CopyBinaryCodec may be implemented by a Codec to explicitly declare whether values of oid can be sent with the COPY
+// binary format. Codecs that do not implement CopyBinaryCodec are assumed to support COPY binary if they support the
+// binary format.
+type CopyBinaryCodec interface {
+	Codec
+
+	// CopyBinarySupported returns true if values of oid can be encoded in the binary format used by COPY.
+	CopyBinarySupported(oid uint32) bool
+}
+
+// CopyBinaryUnsupportedError is returned by Map.CheckCopyBinary when a type cannot be used with the COPY binary format.
+type CopyBinaryUnsupportedError struct {
+	OID      uint32
+	TypeName string
+}
+
+func (e *CopyBinaryUnsupportedError) Error() string {
+	return fmt.Sprintf("type %s (OID %d) does not support the COPY binary format", e.TypeName, e.OID)
+}
+
+// CheckCopyBinary returns a *CopyBinaryUnsupportedError if the type registered for oid cannot be encoded in the binary
+// format used by COPY. If no type is registered for oid then nil is returned as the value being encoded may determine
+// the type (e.g. a string for an unregistered enum).
+func (m *Map) CheckCopyBinary(oid uint32) error {
+	dt, ok := m.TypeForOID(oid)
+	if !ok {
+		return nil
+	}
+
+	var supported bool
+	if cbc, ok := dt.Codec.(CopyBinaryCodec); ok {
+		supported = cbc.CopyBinarySupported(oid)
+	} else {
+		supported = dt.Codec.FormatSupported(BinaryFormatCode)
+	}
+
+	if !supported {
+		return &CopyBinaryUnsupportedError{OID: oid, TypeName: dt.Name}
+	}
+
+	return nil
+}
+
 type nullAssignmentError struct {
 	dst any
 }
@@ -205,6 +257,17 @@ type Map struct {
 	memoizedScanPlans   map[uint32]map[reflect.Type][2]ScanPlan
 	memoizedEncodePlans map[uint32]map[reflect.Type][2]EncodePlan
 
+	planCache *PlanCache
+	// planCacheMap is a snapshot of m that builds the plans stored in planCache. Plans may retain a reference to the Map
+	// that built them and the plans in planCache are used by other Maps. planCacheMap is never modified so m can be
+	// modified while they are in use.
+	planCacheMap *Map
+
+	// pendingLazyTypeNames are the names of types recorded with LoadTypeLazy that have not been loaded.
+	pendingLazyTypeNames map[string]struct{}
+	// missingLazyTypeNames are the names of types recorded with LoadTypeLazy that were not found when loaded.
+	missingLazyTypeNames map[string]struct{}
+
 	// TryWrapEncodePlanFuncs is a slice of functions that will wrap a value that cannot be encoded by the Codec. Every
 	// time a wrapper is found the PlanEncode method will be recursively called with the new value. This allows several layers of wrappers
 	// to be built up. There are default functions placed in this slice by NewMap(). In most cases these functions
@@ -216,6 +279,19 @@ type Map struct {
 	// to be built up. There are default functions placed in this slice by NewMap(). In most cases these functions
 	// should run last. i.e. Additional functions should typically be prepended not appended.
 	TryWrapScanPlanFuncs []TryWrapScanPlanFunc
+
+	// IntegerOutOfRangeScanPolicy is called when an integer value is out of range of the Go integer type it is scanned
+	// into. It does not apply to other types. If nil, an error is returned. See ResolveScanError.
+	IntegerOutOfRangeScanPolicy IntegerOutOfRangeScanPolicy
+
+	// InfinityTimePolicy controls how infinite date, timestamp, and timestamptz values are scanned into and encoded from
+	// time.Time. If nil, scanning an infinite value into a time.Time is an error.
+	InfinityTimePolicy *InfinityTimePolicy
+
+	// NamingStrategy matches struct fields without a "db" tag with composite attribute names in CompositeCodec and with
+	// column names in pgx.RowToStructByName and similar functions. If nil, DefaultNamingStrategy is used. It must be set
+	// before the Map is used, as scan and encode plans are cached.
+	NamingStrategy NamingStrategy
 }
 
 // Copy returns a new Map containing the same registered types.
@@ -247,6 +323,7 @@ func NewMap() *Map {
 			TryWrapSliceEncodePlan,
 			TryWrapMultiDimSliceEncodePlan,
 			TryWrapArrayEncodePlan,
+			TryWrapTextMarshalerEncodePlan,
 		},
 
 		TryWrapScanPlanFuncs: []TryWrapScanPlanFunc{
@@ -257,6 +334,8 @@ func NewMap() *Map {
 			TryWrapPtrSliceScanPlan,
 			TryWrapPtrMultiDimSliceScanPlan,
 			TryWrapPtrArrayScanPlan,
+			TryWrapPtrMapSetScanPlan,
+			TryWrapTextUnmarshalerScanPlan,
 		},
 	}
 }
@@ -282,6 +361,63 @@ func (m *Map) RegisterType(t *Type) {
 	for k := range m.memoizedEncodePlans {
 		delete(m.memoizedEncodePlans, k)
 	}
+	m.invalidatePlanCache()
+}
+
+// LoadTypeLazy records that the type named name should be loaded from the database the first time it may be needed
+// rather than immediately. It has no effect if a type named name is already registered or if a previous load did not
+// find it.
+//
+// Map does not query the database itself. pgx.Conn loads all pending types and the types they depend on with a single
+// query when it prepares a statement that has a parameter or result type that is not registered. See
+// pgx.Conn.LoadPendingTypes.
+func (m *Map) LoadTypeLazy(name string) {
+	if _, ok := m.TypeForName(name); ok {
+		return
+	}
+	if _, ok := m.missingLazyTypeNames[name]; ok {
+		return
+	}
+
+	if m.pendingLazyTypeNames == nil {
+		m.pendingLazyTypeNames = make(map[string]struct{})
+	}
+	m.pendingLazyTypeNames[name] = struct{}{}
+}
+
+// PendingLazyTypes returns the names of the types recorded with LoadTypeLazy that have not been loaded in sorted order.
+func (m *Map) PendingLazyTypes() []string {
+	if len(m.pendingLazyTypeNames) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(m.pendingLazyTypeNames))
+	for name := range m.pendingLazyTypeNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LazyTypesLoaded records that an attempt to load the types named names has completed. The types that were found must
+// already be registered. Names that are not registered are remembered as missing and are not returned by
+// PendingLazyTypes again.
+func (m *Map) LazyTypesLoaded(names []string) {
+	for _, name := range names {
+		delete(m.pendingLazyTypeNames, name)
+		if _, ok := m.TypeForName(name); !ok {
+			if m.missingLazyTypeNames == nil {
+				m.missingLazyTypeNames = make(map[string]struct{})
+			}
+			m.missingLazyTypeNames[name] = struct{}{}
+		}
+	}
+}
+
+// LazyTypeMissing returns true if the type named name was recorded with LoadTypeLazy and was not found when loaded.
+func (m *Map) LazyTypeMissing(name string) bool {
+	_, ok := m.missingLazyTypeNames[name]
+	return ok
 }
 
 // RegisterDefaultPgType registers a mapping of a Go type to a PostgreSQL type name. Typically the data type to be
@@ -298,6 +434,7 @@ func (m *Map) RegisterDefaultPgType(value any, name string) {
 	for k := range m.memoizedEncodePlans {
 		delete(m.memoizedEncodePlans, k)
 	}
+	m.invalidatePlanCache()
 }
 
 // TypeForOID returns the Type registered for the given OID. The returned Type must not be mutated.
@@ -676,6 +813,14 @@ func TryWrapBuiltinTypeScanPlan(target any) (plan WrappedScanPlanNextSetter, nex
 		return &wrapMapStringToStringScanPlan{}, (*mapStringToStringWrapper)(target), true
 	case *[16]byte:
 		return &wrapByte16ScanPlan{}, (*byte16Wrapper)(target), true
+	case *[2]float64:
+		return &wrapFloat64Array2ScanPlan{}, (*float64Array2Wrapper)(target), true
+	case *[3]float64:
+		return &wrapFloat64Array3ScanPlan{}, (*float64Array3Wrapper)(target), true
+	case *[2][2]float64:
+		return &wrapFloat64Array2x2ScanPlan{}, (*float64Array2x2Wrapper)(target), true
+	case *[][2]float64:
+		return &wrapFloat64Array2SliceScanPlan{}, (*float64Array2SliceWrapper)(target), true
 	case *[]byte:
 		return &wrapByteSliceScanPlan{}, (*byteSliceWrapper)(target), true
 	}
@@ -903,6 +1048,46 @@ func (plan *wrapByte16ScanPlan) Scan(src []byte, dst any) error {
 	return plan.next.Scan(src, (*byte16Wrapper)(dst.(*[16]byte)))
 }
 
+type wrapFloat64Array2ScanPlan struct {
+	next ScanPlan
+}
+
+func (plan *wrapFloat64Array2ScanPlan) SetNext(next ScanPlan) { plan.next = next }
+
+func (plan *wrapFloat64Array2ScanPlan) Scan(src []byte, dst any) error {
+	return plan.next.Scan(src, (*float64Array2Wrapper)(dst.(*[2]float64)))
+}
+
+type wrapFloat64Array3ScanPlan struct {
+	next ScanPlan
+}
+
+func (plan *wrapFloat64Array3ScanPlan) SetNext(next ScanPlan) { plan.next = next }
+
+func (plan *wrapFloat64Array3ScanPlan) Scan(src []byte, dst any) error {
+	return plan.next.Scan(src, (*float64Array3Wrapper)(dst.(*[3]float64)))
+}
+
+type wrapFloat64Array2x2ScanPlan struct {
+	next ScanPlan
+}
+
+func (plan *wrapFloat64Array2x2ScanPlan) SetNext(next ScanPlan) { plan.next = next }
+
+func (plan *wrapFloat64Array2x2ScanPlan) Scan(src []byte, dst any) error {
+	return plan.next.Scan(src, (*float64Array2x2Wrapper)(dst.(*[2][2]float64)))
+}
+
+type wrapFloat64Array2SliceScanPlan struct {
+	next ScanPlan
+}
+
+func (plan *wrapFloat64Array2SliceScanPlan) SetNext(next ScanPlan) { plan.next = next }
+
+func (plan *wrapFloat64Array2SliceScanPlan) Scan(src []byte, dst any) error {
+	return plan.next.Scan(src, (*float64Array2SliceWrapper)(dst.(*[][2]float64)))
+}
+
 type wrapByteSliceScanPlan struct {
 	next ScanPlan
 }
@@ -996,6 +1181,10 @@ func TryWrapPtrSliceScanPlan(target any) (plan WrappedScanPlanNextSetter, nextVa
 		return &wrapPtrSliceScanPlan[string]{}, (*FlatArray[string])(target), true
 	case *[]time.Time:
 		return &wrapPtrSliceScanPlan[time.Time]{}, (*FlatArray[time.Time])(target), true
+	case *[][]byte:
+		return &wrapPtrSliceScanPlan[[]byte]{}, (*FlatArray[[]byte])(target), true
+	case *[][16]byte:
+		return &wrapPtrSliceScanPlan[[16]byte]{}, (*FlatArray[[16]byte])(target), true
 	}
 
 	targetType := reflect.TypeOf(target)
@@ -1088,6 +1277,68 @@ func (plan *wrapPtrArrayReflectScanPlan) Scan(src []byte, target any) error {
 	return plan.next.Scan(src, &anyArrayArrayReflect{array: reflect.ValueOf(target).Elem()})
 }
 
+// TryWrapPtrMapSetScanPlan tries to wrap a pointer to a map used as a set. i.e. map[T]struct{} or map[T]bool. Each
+// element of the array is added to the map. Multi-dimensional arrays are flattened. Duplicate elements are only added
+// once.
+func TryWrapPtrMapSetScanPlan(target any) (plan WrappedScanPlanNextSetter, nextValue any, ok bool) {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Ptr {
+		return nil, nil, false
+	}
+
+	targetElemValue := targetValue.Elem()
+
+	if targetElemValue.Kind() == reflect.Map && isMapSetType(targetElemValue.Type()) {
+		return &wrapPtrMapSetReflectScanPlan{}, newAnyMapSetArrayReflect(targetElemValue), true
+	}
+	return nil, nil, false
+}
+
+type wrapPtrMapSetReflectScanPlan struct {
+	next ScanPlan
+}
+
+func (plan *wrapPtrMapSetReflectScanPlan) SetNext(next ScanPlan) { plan.next = next }
+
+func (plan *wrapPtrMapSetReflectScanPlan) Scan(src []byte, target any) error {
+	a := newAnyMapSetArrayReflect(reflect.ValueOf(target).Elem())
+	err := plan.next.Scan(src, a)
+	if err != nil {
+		return err
+	}
+
+	a.addPending()
+	return nil
+}
+
+// TryWrapTextUnmarshalerScanPlan tries to scan into target with its encoding.TextUnmarshaler implementation. The value
+// is first scanned into a string which is then passed to UnmarshalText. This allows types such as custom IDs to be
+// scanned from text and varchar columns. It is the last scan plan tried so a Codec or sql.Scanner implementation will
+// be used if available.
+func TryWrapTextUnmarshalerScanPlan(target any) (plan WrappedScanPlanNextSetter, nextValue any, ok bool) {
+	if _, ok := target.(encoding.TextUnmarshaler); ok {
+		return &wrapTextUnmarshalerScanPlan{}, new(string), true
+	}
+
+	return nil, nil, false
+}
+
+type wrapTextUnmarshalerScanPlan struct {
+	next ScanPlan
+}
+
+func (plan *wrapTextUnmarshalerScanPlan) SetNext(next ScanPlan) { plan.next = next }
+
+func (plan *wrapTextUnmarshalerScanPlan) Scan(src []byte, target any) error {
+	var s string
+	err := plan.next.Scan(src, &s)
+	if err != nil {
+		return err
+	}
+
+	return target.(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+}
+
 // PlanScan prepares a plan to scan a value into target.
 func (m *Map) PlanScan(oid uint32, formatCode int16, target any) ScanPlan {
 	return m.planScanDepth(oid, formatCode, target, 0)
@@ -1098,6 +1349,16 @@ func (m *Map) planScanDepth(oid uint32, formatCode int16, target any, depth int)
 		return &scanPlanFail{m: m, oid: oid, formatCode: formatCode}
 	}
 
+	if m.planCache != nil {
+		key := planCacheKey{oid: oid, formatCode: formatCode, typ: reflect.TypeOf(target)}
+		plan := m.planCache.getScanPlan(key)
+		if plan == nil {
+			plan = m.planCacheMap.planScan(oid, formatCode, target, depth)
+			m.planCache.putScanPlan(key, plan)
+		}
+		return plan
+	}
+
 	oidMemo := m.memoizedScanPlans[oid]
 	if oidMemo == nil {
 		oidMemo = make(map[reflect.Type][2]ScanPlan)
@@ -1199,7 +1460,11 @@ func (m *Map) Scan(oid uint32, formatCode int16, src []byte, dst any) error {
 	}
 
 	plan := m.PlanScan(oid, formatCode, dst)
-	return plan.Scan(src, dst)
+	err := plan.Scan(src, dst)
+	if err != nil && m.IntegerOutOfRangeScanPolicy != nil {
+		return m.ResolveScanError(oid, formatCode, src, dst, -1, "", err)
+	}
+	return err
 }
 
 var ErrScanTargetTypeChanged = errors.New("scan target type changed")
@@ -1244,6 +1509,16 @@ func (m *Map) planEncodeDepth(oid uint32, format int16, value any, depth int) En
 		return nil
 	}
 
+	if m.planCache != nil {
+		key := planCacheKey{oid: oid, formatCode: format, typ: reflect.TypeOf(value)}
+		plan := m.planCache.getEncodePlan(key)
+		if plan == nil {
+			plan = m.planCacheMap.planEncode(oid, format, value, depth)
+			m.planCache.putEncodePlan(key, plan)
+		}
+		return plan
+	}
+
 	oidMemo := m.memoizedEncodePlans[oid]
 	if oidMemo == nil {
 		oidMemo = make(map[reflect.Type][2]EncodePlan)
@@ -1262,7 +1537,7 @@ func (m *Map) planEncodeDepth(oid uint32, format int16, value any, depth int) En
 }
 
 func (m *Map) planEncode(oid uint32, format int16, value any, depth int) EncodePlan {
-	if format == TextFormatCode {
+	if format == TextFormatCode && !m.codecChecksTextEncode(oid) {
 		switch value.(type) {
 		case string:
 			return encodePlanStringToAnyTextFormat{}
@@ -1306,6 +1581,22 @@ func (m *Map) planEncode(oid uint32, format int16, value any, depth int) EncodeP
 	return nil
 }
 
+// textEncodeChecker may be implemented by a Codec that checks the string and TextValuer values it encodes. The text
+// format fast path that copies these values as is is skipped when checksTextEncode returns true so the plan of the
+// codec is used instead.
+type textEncodeChecker interface {
+	checksTextEncode() bool
+}
+
+func (m *Map) codecChecksTextEncode(oid uint32) bool {
+	if dt, ok := m.TypeForOID(oid); ok {
+		if c, ok := dt.Codec.(textEncodeChecker); ok {
+			return c.checksTextEncode()
+		}
+	}
+	return false
+}
+
 type encodePlanStringToAnyTextFormat struct{}
 
 func (encodePlanStringToAnyTextFormat) Encode(value any, buf []byte) (newBuf []byte, err error) {
@@ -1543,6 +1834,14 @@ func TryWrapBuiltinTypeEncodePlan(value any) (plan WrappedEncodePlanNextSetter,
 		return &wrapMapStringToStringEncodePlan{}, mapStringToStringWrapper(value), true
 	case [16]byte:
 		return &wrapByte16EncodePlan{}, byte16Wrapper(value), true
+	case [2]float64:
+		return &wrapFloat64Array2EncodePlan{}, float64Array2Wrapper(value), true
+	case [3]float64:
+		return &wrapFloat64Array3EncodePlan{}, float64Array3Wrapper(value), true
+	case [2][2]float64:
+		return &wrapFloat64Array2x2EncodePlan{}, float64Array2x2Wrapper(value), true
+	case [][2]float64:
+		return &wrapFloat64Array2SliceEncodePlan{}, float64Array2SliceWrapper(value), true
 	case []byte:
 		return &wrapByteSliceEncodePlan{}, byteSliceWrapper(value), true
 	case fmt.Stringer:
@@ -1772,6 +2071,46 @@ func (plan *wrapByte16EncodePlan) Encode(value any, buf []byte) (newBuf []byte,
 	return plan.next.Encode(byte16Wrapper(value.([16]byte)), buf)
 }
 
+type wrapFloat64Array2EncodePlan struct {
+	next EncodePlan
+}
+
+func (plan *wrapFloat64Array2EncodePlan) SetNext(next EncodePlan) { plan.next = next }
+
+func (plan *wrapFloat64Array2EncodePlan) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	return plan.next.Encode(float64Array2Wrapper(value.([2]float64)), buf)
+}
+
+type wrapFloat64Array3EncodePlan struct {
+	next EncodePlan
+}
+
+func (plan *wrapFloat64Array3EncodePlan) SetNext(next EncodePlan) { plan.next = next }
+
+func (plan *wrapFloat64Array3EncodePlan) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	return plan.next.Encode(float64Array3Wrapper(value.([3]float64)), buf)
+}
+
+type wrapFloat64Array2x2EncodePlan struct {
+	next EncodePlan
+}
+
+func (plan *wrapFloat64Array2x2EncodePlan) SetNext(next EncodePlan) { plan.next = next }
+
+func (plan *wrapFloat64Array2x2EncodePlan) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	return plan.next.Encode(float64Array2x2Wrapper(value.([2][2]float64)), buf)
+}
+
+type wrapFloat64Array2SliceEncodePlan struct {
+	next EncodePlan
+}
+
+func (plan *wrapFloat64Array2SliceEncodePlan) SetNext(next EncodePlan) { plan.next = next }
+
+func (plan *wrapFloat64Array2SliceEncodePlan) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	return plan.next.Encode(float64Array2SliceWrapper(value.([][2]float64)), buf)
+}
+
 type wrapByteSliceEncodePlan struct {
 	next EncodePlan
 }
@@ -1863,6 +2202,10 @@ func TryWrapSliceEncodePlan(value any) (plan WrappedEncodePlanNextSetter, nextVa
 		return &wrapSliceEncodePlan[string]{}, (FlatArray[string])(value), true
 	case []time.Time:
 		return &wrapSliceEncodePlan[time.Time]{}, (FlatArray[time.Time])(value), true
+	case [][]byte:
+		return &wrapSliceEncodePlan[[]byte]{}, (FlatArray[[]byte])(value), true
+	case [][16]byte:
+		return &wrapSliceEncodePlan[[16]byte]{}, (FlatArray[[16]byte])(value), true
 	}
 
 	if valueType := reflect.TypeOf(value); valueType != nil && valueType.Kind() == reflect.Slice {
@@ -1964,6 +2307,41 @@ func (plan *wrapArrayEncodeReflectPlan) Encode(value any, buf []byte) (newBuf []
 	return plan.next.Encode(w, buf)
 }
 
+// TryWrapTextMarshalerEncodePlan tries to encode value with its encoding.TextMarshaler implementation. The result of
+// MarshalText is encoded as a string. This allows types such as custom IDs to be encoded to text and varchar columns.
+// It is the last encode plan tried so a Codec will be used if available. Values that implement driver.Valuer are not
+// wrapped so that Value continues to be used.
+func TryWrapTextMarshalerEncodePlan(value any) (plan WrappedEncodePlanNextSetter, nextValue any, ok bool) {
+	if _, ok := value.(driver.Valuer); ok {
+		return nil, nil, false
+	}
+
+	if _, ok := value.(encoding.TextMarshaler); ok {
+		return &wrapTextMarshalerEncodePlan{}, "", true
+	}
+
+	return nil, nil, false
+}
+
+type wrapTextMarshalerEncodePlan struct {
+	next EncodePlan
+}
+
+func (plan *wrapTextMarshalerEncodePlan) SetNext(next EncodePlan) { plan.next = next }
+
+func (plan *wrapTextMarshalerEncodePlan) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	if refValue := reflect.ValueOf(value); refValue.Kind() == reflect.Ptr && refValue.IsNil() {
+		return nil, nil
+	}
+
+	text, err := value.(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.next.Encode(string(text), buf)
+}
+
 func newEncodeError(value any, m *Map, oid uint32, formatCode int16, err error) error {
 	var format string
 	switch formatCode {
@@ -77,6 +77,8 @@ func initDefaultMap() {
 	defaultMap.RegisterType(&Type{Name: "numeric", OID: NumericOID, Codec: NumericCodec{}})
 	defaultMap.RegisterType(&Type{Name: "oid", OID: OIDOID, Codec: Uint32Codec{}})
 	defaultMap.RegisterType(&Type{Name: "path", OID: PathOID, Codec: PathCodec{}})
+	defaultMap.RegisterType(&Type{Name: "pg_lsn", OID: PgLSNOID, Codec: LSNCodec{}})
+	defaultMap.RegisterType(&Type{Name: "pg_snapshot", OID: PgSnapshotOID, Codec: SnapshotCodec{}})
 	defaultMap.RegisterType(&Type{Name: "point", OID: PointOID, Codec: PointCodec{}})
 	defaultMap.RegisterType(&Type{Name: "polygon", OID: PolygonOID, Codec: PolygonCodec{}})
 	defaultMap.RegisterType(&Type{Name: "record", OID: RecordOID, Codec: RecordCodec{}})
@@ -85,6 +87,7 @@ func initDefaultMap() {
 	defaultMap.RegisterType(&Type{Name: "time", OID: TimeOID, Codec: TimeCodec{}})
 	defaultMap.RegisterType(&Type{Name: "timestamp", OID: TimestampOID, Codec: &TimestampCodec{}})
 	defaultMap.RegisterType(&Type{Name: "timestamptz", OID: TimestamptzOID, Codec: &TimestamptzCodec{}})
+	defaultMap.RegisterType(&Type{Name: "txid_snapshot", OID: TxidSnapshotOID, Codec: SnapshotCodec{}})
 	defaultMap.RegisterType(&Type{Name: "unknown", OID: UnknownOID, Codec: TextCodec{}})
 	defaultMap.RegisterType(&Type{Name: "uuid", OID: UUIDOID, Codec: UUIDCodec{}})
 	defaultMap.RegisterType(&Type{Name: "varbit", OID: VarbitOID, Codec: BitsCodec{}})
@@ -137,11 +140,14 @@ func initDefaultMap() {
 	defaultMap.RegisterType(&Type{Name: "_line", OID: LineArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[LineOID]}})
 	defaultMap.RegisterType(&Type{Name: "_lseg", OID: LsegArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[LsegOID]}})
 	defaultMap.RegisterType(&Type{Name: "_macaddr", OID: MacaddrArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[MacaddrOID]}})
+	defaultMap.RegisterType(&Type{Name: "_macaddr8", OID: Macaddr8ArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[Macaddr8OID]}})
 	defaultMap.RegisterType(&Type{Name: "_name", OID: NameArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[NameOID]}})
 	defaultMap.RegisterType(&Type{Name: "_numeric", OID: NumericArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[NumericOID]}})
 	defaultMap.RegisterType(&Type{Name: "_numrange", OID: NumrangeArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[NumrangeOID]}})
 	defaultMap.RegisterType(&Type{Name: "_oid", OID: OIDArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[OIDOID]}})
 	defaultMap.RegisterType(&Type{Name: "_path", OID: PathArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[PathOID]}})
+	defaultMap.RegisterType(&Type{Name: "_pg_lsn", OID: PgLSNArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[PgLSNOID]}})
+	defaultMap.RegisterType(&Type{Name: "_pg_snapshot", OID: PgSnapshotArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[PgSnapshotOID]}})
 	defaultMap.RegisterType(&Type{Name: "_point", OID: PointArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[PointOID]}})
 	defaultMap.RegisterType(&Type{Name: "_polygon", OID: PolygonArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[PolygonOID]}})
 	defaultMap.RegisterType(&Type{Name: "_record", OID: RecordArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[RecordOID]}})
@@ -152,6 +158,7 @@ func initDefaultMap() {
 	defaultMap.RegisterType(&Type{Name: "_timestamptz", OID: TimestamptzArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[TimestamptzOID]}})
 	defaultMap.RegisterType(&Type{Name: "_tsrange", OID: TsrangeArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[TsrangeOID]}})
 	defaultMap.RegisterType(&Type{Name: "_tstzrange", OID: TstzrangeArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[TstzrangeOID]}})
+	defaultMap.RegisterType(&Type{Name: "_txid_snapshot", OID: TxidSnapshotArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[TxidSnapshotOID]}})
 	defaultMap.RegisterType(&Type{Name: "_uuid", OID: UUIDArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[UUIDOID]}})
 	defaultMap.RegisterType(&Type{Name: "_varbit", OID: VarbitArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[VarbitOID]}})
 	defaultMap.RegisterType(&Type{Name: "_varchar", OID: VarcharArrayOID, Codec: &ArrayCodec{ElementType: defaultMap.oidToType[VarcharOID]}})
@@ -178,6 +185,8 @@ func initDefaultMap() {
 
 	registerDefaultPgTypeVariants[bool](defaultMap, "bool")
 	registerDefaultPgTypeVariants[time.Time](defaultMap, "timestamptz")
+	registerDefaultPgTypeVariants[Range[time.Time]](defaultMap, "tstzrange")
+	registerDefaultPgTypeVariants[Multirange[Range[time.Time]]](defaultMap, "tstzmultirange")
 	registerDefaultPgTypeVariants[time.Duration](defaultMap, "interval")
 	registerDefaultPgTypeVariants[string](defaultMap, "text")
 	registerDefaultPgTypeVariants[json.RawMessage](defaultMap, "json")
@@ -187,6 +196,7 @@ func initDefaultMap() {
 	registerDefaultPgTypeVariants[net.IPNet](defaultMap, "cidr")
 	registerDefaultPgTypeVariants[netip.Addr](defaultMap, "inet")
 	registerDefaultPgTypeVariants[netip.Prefix](defaultMap, "cidr")
+	registerDefaultPgTypeVariants[net.HardwareAddr](defaultMap, "macaddr") // EUI-64 addresses must be explicitly cast to macaddr8.
 
 	// pgtype provided structs
 	registerDefaultPgTypeVariants[Bits](defaultMap, "varbit")
@@ -214,6 +224,8 @@ func initDefaultMap() {
 	registerDefaultPgTypeVariants[Range[Numeric]](defaultMap, "numrange")
 	registerDefaultPgTypeVariants[Multirange[Range[Numeric]]](defaultMap, "nummultirange")
 	registerDefaultPgTypeVariants[Path](defaultMap, "path")
+	registerDefaultPgTypeVariants[LSN](defaultMap, "pg_lsn")
+	registerDefaultPgTypeVariants[Snapshot](defaultMap, "pg_snapshot")
 	registerDefaultPgTypeVariants[Point](defaultMap, "point")
 	registerDefaultPgTypeVariants[Polygon](defaultMap, "polygon")
 	registerDefaultPgTypeVariants[TID](defaultMap, "tid")
@@ -12,6 +12,7 @@ import (
 	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/jackc/pgx/v5"
@@ -132,6 +133,26 @@ func (f driverValuerFunc) Value() (driver.Value, error) {
 	return f()
 }
 
+func TestMapLoadTypeLazy(t *testing.T) {
+	m := pgtype.NewMap()
+
+	m.LoadTypeLazy("int4") // already registered
+	m.LoadTypeLazy("foo")
+	m.LoadTypeLazy("bar")
+	m.LoadTypeLazy("foo")
+	require.Equal(t, []string{"bar", "foo"}, m.PendingLazyTypes())
+
+	m.RegisterType(&pgtype.Type{Name: "foo", OID: 999999, Codec: pgtype.TextCodec{}})
+	m.LazyTypesLoaded([]string{"bar", "foo"})
+	require.Empty(t, m.PendingLazyTypes())
+	require.False(t, m.LazyTypeMissing("foo"))
+	require.True(t, m.LazyTypeMissing("bar"))
+
+	// Missing types are not loaded again.
+	m.LoadTypeLazy("bar")
+	require.Empty(t, m.PendingLazyTypes())
+}
+
 func TestMapScanNilIsNoOp(t *testing.T) {
 	m := pgtype.NewMap()
 
@@ -555,6 +576,95 @@ func TestMapEncodeRawJSONIntoUnknownOID(t *testing.T) {
 	require.Equal(t, []byte(`{"foo": "bar"}`), buf)
 }
 
+func TestMapEncodeScanGeometricFloat64Arrays(t *testing.T) {
+	m := pgtype.NewMap()
+
+	for i, tt := range []struct {
+		oid   uint32
+		value any
+		text  string
+	}{
+		{oid: pgtype.PointOID, value: [2]float64{1.5, -2}, text: "(1.5,-2)"},
+		{oid: pgtype.LineOID, value: [3]float64{1, 2, 3}, text: "{1,2,3}"},
+		{oid: pgtype.CircleOID, value: [3]float64{1, 2, 3}, text: "<(1,2),3>"},
+		{oid: pgtype.BoxOID, value: [2][2]float64{{3, 4}, {1, 2}}, text: "(3,4),(1,2)"},
+		{oid: pgtype.LsegOID, value: [2][2]float64{{1, 2}, {3, 4}}, text: "[(1,2),(3,4)]"},
+		{oid: pgtype.PathOID, value: [][2]float64{{1, 2}, {3, 4}}, text: "[(1,2),(3,4)]"},
+		{oid: pgtype.PolygonOID, value: [][2]float64{{1, 2}, {3, 4}, {5, 6}}, text: "((1,2),(3,4),(5,6))"},
+	} {
+		buf, err := m.Encode(tt.oid, pgtype.TextFormatCode, tt.value, nil)
+		require.NoErrorf(t, err, "%d", i)
+		require.Equalf(t, tt.text, string(buf), "%d", i)
+
+		for _, format := range []int16{pgtype.TextFormatCode, pgtype.BinaryFormatCode} {
+			buf, err := m.Encode(tt.oid, format, tt.value, nil)
+			require.NoErrorf(t, err, "%d", i)
+
+			dst := reflect.New(reflect.TypeOf(tt.value))
+			err = m.Scan(tt.oid, format, buf, dst.Interface())
+			require.NoErrorf(t, err, "%d", i)
+			require.Equalf(t, tt.value, dst.Elem().Interface(), "%d", i)
+		}
+	}
+}
+
+// textMarshalerID is a custom ID type that is only usable through encoding.TextMarshaler and encoding.TextUnmarshaler.
+type textMarshalerID struct {
+	prefix string
+	n      int
+}
+
+func (id textMarshalerID) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%s-%d", id.prefix, id.n)), nil
+}
+
+func (id *textMarshalerID) UnmarshalText(text []byte) error {
+	prefix, n, ok := strings.Cut(string(text), "-")
+	if !ok {
+		return fmt.Errorf("invalid id: %s", text)
+	}
+
+	var err error
+	id.n, err = strconv.Atoi(n)
+	if err != nil {
+		return err
+	}
+	id.prefix = prefix
+	return nil
+}
+
+func TestMapEncodeScanTextMarshaler(t *testing.T) {
+	m := pgtype.NewMap()
+
+	for _, format := range []int16{pgtype.TextFormatCode, pgtype.BinaryFormatCode} {
+		for _, oid := range []uint32{pgtype.TextOID, pgtype.VarcharOID} {
+			buf, err := m.Encode(oid, format, textMarshalerID{prefix: "user", n: 42}, nil)
+			require.NoError(t, err)
+			require.Equal(t, []byte("user-42"), buf)
+
+			var id textMarshalerID
+			err = m.Scan(oid, format, buf, &id)
+			require.NoError(t, err)
+			require.Equal(t, textMarshalerID{prefix: "user", n: 42}, id)
+
+			var idPtr *textMarshalerID
+			err = m.Scan(oid, format, nil, &idPtr)
+			require.NoError(t, err)
+			require.Nil(t, idPtr)
+
+			err = m.Scan(oid, format, nil, &id)
+			require.Error(t, err)
+
+			err = m.Scan(oid, format, []byte("invalid"), &id)
+			require.Error(t, err)
+		}
+	}
+
+	buf, err := m.Encode(pgtype.TextOID, pgtype.TextFormatCode, (*textMarshalerID)(nil), nil)
+	require.NoError(t, err)
+	require.Nil(t, buf)
+}
+
 func BenchmarkMapScanInt4IntoBinaryDecoder(b *testing.B) {
 	m := pgtype.NewMap()
 	src := []byte{0, 0, 0, 42}
@@ -639,3 +749,29 @@ func isPtrExpectedEq(a any) func(any) bool {
 		return a == val.Elem().Interface()
 	}
 }
+
+type noCopyBinaryCodec struct {
+	pgtype.TextCodec
+}
+
+func (noCopyBinaryCodec) CopyBinarySupported(oid uint32) bool {
+	return false
+}
+
+func TestMapCheckCopyBinary(t *testing.T) {
+	m := pgtype.NewMap()
+	m.RegisterType(&pgtype.Type{Name: "nocopy", OID: 999999, Codec: noCopyBinaryCodec{}})
+
+	require.NoError(t, m.CheckCopyBinary(pgtype.Int4OID))
+	require.NoError(t, m.CheckCopyBinary(888888), "unregistered types are determined by the value")
+
+	var unsupportedErr *pgtype.CopyBinaryUnsupportedError
+	err := m.CheckCopyBinary(pgtype.JSONPathOID)
+	require.ErrorAs(t, err, &unsupportedErr)
+	require.Equal(t, "jsonpath", unsupportedErr.TypeName)
+
+	err = m.CheckCopyBinary(999999)
+	require.ErrorAs(t, err, &unsupportedErr)
+	require.EqualValues(t, 999999, unsupportedErr.OID)
+	require.Equal(t, "nocopy", unsupportedErr.TypeName)
+}
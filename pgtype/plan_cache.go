@@ -0,0 +1,157 @@
+package pgtype
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// PlanCache is a cache of scan and encode plans that can be shared by multiple Maps. It is safe for concurrent use. Its
+// primary use is sharing plans between the connections of a pool so a new connection does not have to plan the
+// application's common queries again.
+//
+// A plan built by one Map is used by all Maps that share the PlanCache. Therefore, all Maps that share a PlanCache must
+// have the same types registered and the same configuration (e.g. TryWrapScanPlanFuncs, IntegerOutOfRangeScanPolicy, and
+// InfinityTimePolicy). See Map.SetPlanCache.
+//
+// Reads do not take a lock. Writes copy the cache. This is efficient when the set of planned queries stabilizes, which
+// is the typical case.
+type PlanCache struct {
+	mu      sync.Mutex // serializes writers
+	entries atomic.Pointer[planCacheEntries]
+}
+
+type planCacheKey struct {
+	oid        uint32
+	formatCode int16
+	typ        reflect.Type
+}
+
+type planCacheEntries struct {
+	scanPlans   map[planCacheKey]ScanPlan
+	encodePlans map[planCacheKey]EncodePlan
+}
+
+// NewPlanCache returns a new, empty PlanCache.
+func NewPlanCache() *PlanCache {
+	c := &PlanCache{}
+	c.entries.Store(&planCacheEntries{
+		scanPlans:   map[planCacheKey]ScanPlan{},
+		encodePlans: map[planCacheKey]EncodePlan{},
+	})
+	return c
+}
+
+// Clear removes all plans from the cache.
+func (c *PlanCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries.Store(&planCacheEntries{
+		scanPlans:   map[planCacheKey]ScanPlan{},
+		encodePlans: map[planCacheKey]EncodePlan{},
+	})
+}
+
+// Len returns the number of plans in the cache.
+func (c *PlanCache) Len() int {
+	entries := c.entries.Load()
+	return len(entries.scanPlans) + len(entries.encodePlans)
+}
+
+func (c *PlanCache) getScanPlan(key planCacheKey) ScanPlan {
+	return c.entries.Load().scanPlans[key]
+}
+
+func (c *PlanCache) putScanPlan(key planCacheKey, plan ScanPlan) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	old := c.entries.Load()
+	if _, ok := old.scanPlans[key]; ok {
+		return
+	}
+
+	scanPlans := make(map[planCacheKey]ScanPlan, len(old.scanPlans)+1)
+	for k, v := range old.scanPlans {
+		scanPlans[k] = v
+	}
+	scanPlans[key] = plan
+
+	c.entries.Store(&planCacheEntries{scanPlans: scanPlans, encodePlans: old.encodePlans})
+}
+
+func (c *PlanCache) getEncodePlan(key planCacheKey) EncodePlan {
+	return c.entries.Load().encodePlans[key]
+}
+
+func (c *PlanCache) putEncodePlan(key planCacheKey, plan EncodePlan) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	old := c.entries.Load()
+	if _, ok := old.encodePlans[key]; ok {
+		return
+	}
+
+	encodePlans := make(map[planCacheKey]EncodePlan, len(old.encodePlans)+1)
+	for k, v := range old.encodePlans {
+		encodePlans[k] = v
+	}
+	encodePlans[key] = plan
+
+	c.entries.Store(&planCacheEntries{scanPlans: old.scanPlans, encodePlans: encodePlans})
+}
+
+// SetPlanCache sets a PlanCache that m uses instead of its own memoized plans. c may be shared with other Maps that have
+// the same types registered and the same configuration. The configuration of m must not be changed after SetPlanCache
+// is called.
+//
+// The plans that m stores in c are built by a snapshot of m taken by SetPlanCache so they are not affected by later
+// changes to m. Registering a type with RegisterType or RegisterDefaultPgType clears c and takes a new snapshot. As c
+// is shared, the type should be registered with all Maps that share c.
+//
+// If c is nil, m returns to using its own memoized plans.
+func (m *Map) SetPlanCache(c *PlanCache) {
+	m.planCache = c
+	m.planCacheMap = nil
+	if c != nil {
+		m.planCacheMap = m.planCacheSnapshot()
+	}
+}
+
+// PlanCache returns the PlanCache set by SetPlanCache or nil if there is none.
+func (m *Map) PlanCache() *PlanCache {
+	return m.planCache
+}
+
+// invalidatePlanCache clears the PlanCache of m and takes a new snapshot of m after m has been modified.
+func (m *Map) invalidatePlanCache() {
+	if m.planCache == nil {
+		return
+	}
+
+	m.planCache.Clear()
+	m.planCacheMap = m.planCacheSnapshot()
+}
+
+// planCacheSnapshot returns a copy of m that shares the PlanCache of m. The copy is used concurrently by the Maps that
+// share the PlanCache so all lazily initialized state is built now.
+func (m *Map) planCacheSnapshot() *Map {
+	snapshot := *m
+	snapshot.oidToType = maps.Clone(m.oidToType)
+	snapshot.nameToType = maps.Clone(m.nameToType)
+	snapshot.reflectTypeToName = maps.Clone(m.reflectTypeToName)
+	snapshot.oidToFormatCode = maps.Clone(m.oidToFormatCode)
+	snapshot.memoizedScanPlans = make(map[uint32]map[reflect.Type][2]ScanPlan)
+	snapshot.memoizedEncodePlans = make(map[uint32]map[reflect.Type][2]EncodePlan)
+	snapshot.pendingLazyTypeNames = maps.Clone(m.pendingLazyTypeNames)
+	snapshot.missingLazyTypeNames = maps.Clone(m.missingLazyTypeNames)
+	snapshot.TryWrapEncodePlanFuncs = slices.Clone(m.TryWrapEncodePlanFuncs)
+	snapshot.TryWrapScanPlanFuncs = slices.Clone(m.TryWrapScanPlanFuncs)
+	snapshot.buildReflectTypeToType()
+	snapshot.planCacheMap = &snapshot
+	return &snapshot
+}
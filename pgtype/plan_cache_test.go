@@ -0,0 +1,116 @@
+package pgtype_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanCacheSharedByMaps(t *testing.T) {
+	cache := pgtype.NewPlanCache()
+
+	m1 := pgtype.NewMap()
+	m1.SetPlanCache(cache)
+	m2 := pgtype.NewMap()
+	m2.SetPlanCache(cache)
+
+	var a []int32
+	scanPlan := m1.PlanScan(pgtype.Int4ArrayOID, pgtype.BinaryFormatCode, &a)
+	encodePlan := m1.PlanEncode(pgtype.Int4ArrayOID, pgtype.BinaryFormatCode, a)
+	planCount := cache.Len()
+	require.NotZero(t, planCount)
+
+	assert.Same(t, scanPlan, m2.PlanScan(pgtype.Int4ArrayOID, pgtype.BinaryFormatCode, &a))
+	assert.Same(t, encodePlan, m2.PlanEncode(pgtype.Int4ArrayOID, pgtype.BinaryFormatCode, a))
+	require.Equal(t, planCount, cache.Len())
+
+	buf, err := m2.Encode(pgtype.Int4ArrayOID, pgtype.BinaryFormatCode, []int32{1, 2, 3}, nil)
+	require.NoError(t, err)
+	err = m2.Scan(pgtype.Int4ArrayOID, pgtype.BinaryFormatCode, buf, &a)
+	require.NoError(t, err)
+	assert.Equal(t, []int32{1, 2, 3}, a)
+
+	cache.Clear()
+	require.Equal(t, 0, cache.Len())
+}
+
+func TestPlanCacheClearedByRegisterType(t *testing.T) {
+	cache := pgtype.NewPlanCache()
+
+	m := pgtype.NewMap()
+	m.SetPlanCache(cache)
+	require.Same(t, cache, m.PlanCache())
+
+	var n int32
+	m.PlanScan(pgtype.Int4OID, pgtype.BinaryFormatCode, &n)
+	require.NotZero(t, cache.Len())
+
+	m.RegisterType(&pgtype.Type{Name: "myint4", OID: 999999, Codec: pgtype.Int4Codec{}})
+	require.Same(t, cache, m.PlanCache())
+	require.Equal(t, 0, cache.Len())
+
+	m.PlanScan(999999, pgtype.BinaryFormatCode, &n)
+	err := m.Scan(999999, pgtype.TextFormatCode, []byte("42"), &n)
+	require.NoError(t, err)
+	require.EqualValues(t, 42, n)
+}
+
+func TestPlanCacheRegisterTypeWhileShared(t *testing.T) {
+	cache := pgtype.NewPlanCache()
+
+	m1 := pgtype.NewMap()
+	m1.SetPlanCache(cache)
+	m2 := pgtype.NewMap()
+	m2.SetPlanCache(cache)
+
+	// Plans built by m1 are used by m2 while types are registered with m1.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 100; j++ {
+			buf, err := m2.Encode(pgtype.Int4ArrayOID, pgtype.BinaryFormatCode, []int32{int32(j), 1, 2}, nil)
+			assert.NoError(t, err)
+
+			var a []int32
+			err = m2.Scan(pgtype.Int4ArrayOID, pgtype.BinaryFormatCode, buf, &a)
+			assert.NoError(t, err)
+			assert.Equal(t, []int32{int32(j), 1, 2}, a)
+		}
+	}()
+
+	for j := 0; j < 100; j++ {
+		var a []int32
+		m1.PlanScan(pgtype.Int4ArrayOID, pgtype.BinaryFormatCode, &a)
+		m1.RegisterType(&pgtype.Type{Name: "myint4", OID: uint32(999000 + j), Codec: pgtype.Int4Codec{}})
+	}
+	wg.Wait()
+}
+
+func TestPlanCacheConcurrentUse(t *testing.T) {
+	cache := pgtype.NewPlanCache()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		m := pgtype.NewMap()
+		m.SetPlanCache(cache)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				buf, err := m.Encode(pgtype.Int4ArrayOID, pgtype.BinaryFormatCode, []int32{int32(j), 1, 2}, nil)
+				assert.NoError(t, err)
+
+				var a []int32
+				err = m.Scan(pgtype.Int4ArrayOID, pgtype.BinaryFormatCode, buf, &a)
+				assert.NoError(t, err)
+				assert.Equal(t, []int32{int32(j), 1, 2}, a)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -24,6 +24,7 @@ func TestPointCodec(t *testing.T) {
 			new(pgtype.Point),
 			isExpectedEq(pgtype.Point{P: pgtype.Vec2{-1.234, -5.6789}, Valid: true}),
 		},
+		{[2]float64{1.234, -5.6789}, new([2]float64), isExpectedEq([2]float64{1.234, -5.6789})},
 		{pgtype.Point{}, new(pgtype.Point), isExpectedEq(pgtype.Point{})},
 		{nil, new(pgtype.Point), isExpectedEq(pgtype.Point{})},
 	})
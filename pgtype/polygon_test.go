@@ -53,6 +53,7 @@ func TestPolygonTranscode(t *testing.T) {
 				Valid: true,
 			}),
 		},
+		{[][2]float64{{3.14, -1.678}, {7.1, -5.234}, {23.1, 9.34}}, new([][2]float64), isExpectedEq([][2]float64{{3.14, -1.678}, {7.1, -5.234}, {23.1, 9.34}})},
 		{pgtype.Polygon{}, new(pgtype.Polygon), isExpectedEqPolygon(pgtype.Polygon{})},
 		{nil, new(pgtype.Polygon), isExpectedEqPolygon(pgtype.Polygon{})},
 	})
@@ -2,8 +2,14 @@ package pgtype
 
 import (
 	"bytes"
+	"cmp"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"time"
 )
 
 type BoundType byte
@@ -320,3 +326,322 @@ func (r *Range[T]) SetBoundTypes(lower, upper BoundType) error {
 	r.Valid = true
 	return nil
 }
+
+// The following methods compare bounds. T may be an integer, float, or string type including named types, a type with
+// a Compare(T) int method such as time.Time, Numeric, or a type that implements Float64Valuer, Int64Valuer,
+// DateValuer, TimestampValuer, or TimestamptzValuer such as Int4, Float8, Date, and Timestamptz. An error is returned
+// for any other T and for a bound that is NULL.
+
+// IsEmpty returns true if r is a valid range that contains no values. This is the case for a range with Empty bound
+// types and for a range whose bounds do not include any values. e.g. [5,5).
+func (r Range[T]) IsEmpty() (bool, error) {
+	if !r.Valid {
+		return false, nil
+	}
+
+	n, err := r.Normalize()
+	if err != nil {
+		return false, err
+	}
+	return n.LowerType == Empty, nil
+}
+
+// Normalize returns r in the canonical form that PostgreSQL uses. A range that contains no values is returned with Empty
+// bound types. For discrete types, i.e. integer types and Date, the bounds are converted to an inclusive lower bound and
+// an exclusive upper bound as PostgreSQL does for discrete ranges such as int4range. e.g. (1,5] is returned as [2,6).
+// An inclusive upper bound that is the maximum value of its type is kept as is. Unbounded bounds and NULL ranges are
+// returned unchanged.
+func (r Range[T]) Normalize() (Range[T], error) {
+	if !r.Valid {
+		return r, nil
+	}
+
+	if r.LowerType == Empty || r.UpperType == Empty {
+		return Range[T]{LowerType: Empty, UpperType: Empty, Valid: true}, nil
+	}
+
+	if r.LowerType == Exclusive {
+		next, ok, err := rangeNextDiscreteValue(r.Lower)
+		if errors.Is(err, errRangeBoundMaxValue) {
+			// No value is above the maximum value.
+			return Range[T]{LowerType: Empty, UpperType: Empty, Valid: true}, nil
+		}
+		if err != nil {
+			return Range[T]{}, err
+		}
+		if ok {
+			r.Lower = next
+			r.LowerType = Inclusive
+		}
+	}
+
+	if r.UpperType == Inclusive {
+		next, ok, err := rangeNextDiscreteValue(r.Upper)
+		if err != nil && !errors.Is(err, errRangeBoundMaxValue) {
+			return Range[T]{}, err
+		}
+		if ok {
+			r.Upper = next
+			r.UpperType = Exclusive
+		}
+	}
+
+	below, err := rangeLowerBelowUpper(r.Lower, r.LowerType, r.Upper, r.UpperType)
+	if err != nil {
+		return Range[T]{}, err
+	}
+	if !below {
+		return Range[T]{LowerType: Empty, UpperType: Empty, Valid: true}, nil
+	}
+
+	return r, nil
+}
+
+// Contains returns true if v is within r.
+func (r Range[T]) Contains(v T) (bool, error) {
+	r, err := r.Normalize()
+	if err != nil {
+		return false, err
+	}
+	if !r.Valid || r.LowerType == Empty {
+		return false, nil
+	}
+
+	aboveLower, err := rangeLowerBelowUpper(r.Lower, r.LowerType, v, Inclusive)
+	if err != nil || !aboveLower {
+		return false, err
+	}
+	return rangeLowerBelowUpper(v, Inclusive, r.Upper, r.UpperType)
+}
+
+// Overlaps returns true if r and other have any values in common.
+func (r Range[T]) Overlaps(other Range[T]) (bool, error) {
+	r, err := r.Normalize()
+	if err != nil {
+		return false, err
+	}
+	other, err = other.Normalize()
+	if err != nil {
+		return false, err
+	}
+	if !r.Valid || !other.Valid || r.LowerType == Empty || other.LowerType == Empty {
+		return false, nil
+	}
+
+	below, err := rangeLowerBelowUpper(r.Lower, r.LowerType, other.Upper, other.UpperType)
+	if err != nil || !below {
+		return false, err
+	}
+	return rangeLowerBelowUpper(other.Lower, other.LowerType, r.Upper, r.UpperType)
+}
+
+// rangeLowerBelowUpper returns true if there is a value that is at or above the lower bound and at or below the upper
+// bound.
+func rangeLowerBelowUpper[T any](lower T, lowerType BoundType, upper T, upperType BoundType) (bool, error) {
+	if lowerType == Unbounded || upperType == Unbounded {
+		return true, nil
+	}
+
+	c, err := rangeCompare(lower, upper)
+	if err != nil {
+		return false, err
+	}
+	if c == 0 {
+		return lowerType == Inclusive && upperType == Inclusive, nil
+	}
+	return c < 0, nil
+}
+
+var (
+	errRangeBoundNull = errors.New("cannot compare NULL range bound")
+
+	// errRangeBoundMaxValue is returned by rangeNextDiscreteValue when there is no next value.
+	errRangeBoundMaxValue = errors.New("range bound is the maximum value of its type")
+)
+
+func rangeCompare[T any](a, b T) (int, error) {
+	if a, ok := any(a).(interface{ Compare(T) int }); ok {
+		return a.Compare(b), nil
+	}
+
+	if a, ok := any(a).(Numeric); ok {
+		return rangeCompareNumeric(a, any(b).(Numeric))
+	}
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch va.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(va.Int(), vb.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(va.Uint(), vb.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(va.Float(), vb.Float()), nil
+	case reflect.String:
+		return cmp.Compare(va.String(), vb.String()), nil
+	}
+
+	switch a := any(a).(type) {
+	case Float64Valuer:
+		return rangeCompareValues(a, any(b).(Float64Valuer), func(v Float64Valuer) (float64, bool, error) {
+			n, err := v.Float64Value()
+			return n.Float64, n.Valid, err
+		}, cmp.Compare[float64])
+	case Int64Valuer:
+		return rangeCompareValues(a, any(b).(Int64Valuer), func(v Int64Valuer) (int64, bool, error) {
+			n, err := v.Int64Value()
+			return n.Int64, n.Valid, err
+		}, cmp.Compare[int64])
+	case DateValuer:
+		return rangeCompareValues(a, any(b).(DateValuer), func(v DateValuer) (Date, bool, error) {
+			d, err := v.DateValue()
+			return d, d.Valid, err
+		}, func(x, y Date) int { return rangeCompareTime(x.InfinityModifier, x.Time, y.InfinityModifier, y.Time) })
+	case TimestampValuer:
+		return rangeCompareValues(a, any(b).(TimestampValuer), func(v TimestampValuer) (Timestamp, bool, error) {
+			ts, err := v.TimestampValue()
+			return ts, ts.Valid, err
+		}, func(x, y Timestamp) int {
+			return rangeCompareTime(x.InfinityModifier, x.Time, y.InfinityModifier, y.Time)
+		})
+	case TimestamptzValuer:
+		return rangeCompareValues(a, any(b).(TimestamptzValuer), func(v TimestamptzValuer) (Timestamptz, bool, error) {
+			ts, err := v.TimestamptzValue()
+			return ts, ts.Valid, err
+		}, func(x, y Timestamptz) int {
+			return rangeCompareTime(x.InfinityModifier, x.Time, y.InfinityModifier, y.Time)
+		})
+	}
+
+	return 0, fmt.Errorf("cannot compare range bounds of type %T", a)
+}
+
+// rangeCompareValues gets the values of a and b with get and compares them with compare.
+func rangeCompareValues[V, N any](a, b V, get func(V) (N, bool, error), compare func(N, N) int) (int, error) {
+	na, valid, err := get(a)
+	if err != nil {
+		return 0, err
+	}
+	if !valid {
+		return 0, errRangeBoundNull
+	}
+
+	nb, valid, err := get(b)
+	if err != nil {
+		return 0, err
+	}
+	if !valid {
+		return 0, errRangeBoundNull
+	}
+
+	return compare(na, nb), nil
+}
+
+func rangeCompareTime(aInfinity InfinityModifier, a time.Time, bInfinity InfinityModifier, b time.Time) int {
+	if aInfinity != Finite || bInfinity != Finite {
+		return cmp.Compare(aInfinity, bInfinity)
+	}
+	return a.Compare(b)
+}
+
+// rangeCompareNumeric compares a and b like PostgreSQL. NaN is greater than any other value.
+func rangeCompareNumeric(a, b Numeric) (int, error) {
+	if !a.Valid || !b.Valid {
+		return 0, errRangeBoundNull
+	}
+
+	if a.NaN || b.NaN {
+		switch {
+		case a.NaN && b.NaN:
+			return 0, nil
+		case a.NaN:
+			return 1, nil
+		default:
+			return -1, nil
+		}
+	}
+
+	if a.InfinityModifier != Finite || b.InfinityModifier != Finite {
+		return cmp.Compare(a.InfinityModifier, b.InfinityModifier), nil
+	}
+
+	ai, bi := a.Int, b.Int
+	if ai == nil {
+		ai = big.NewInt(0)
+	}
+	if bi == nil {
+		bi = big.NewInt(0)
+	}
+
+	// Scale the value with the larger exponent so both have the same exponent.
+	if a.Exp > b.Exp {
+		ai = new(big.Int).Mul(ai, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(a.Exp)-int64(b.Exp)), nil))
+	} else if b.Exp > a.Exp {
+		bi = new(big.Int).Mul(bi, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(b.Exp)-int64(a.Exp)), nil))
+	}
+
+	return ai.Cmp(bi), nil
+}
+
+// rangeNextDiscreteValue returns the value after v if T is a discrete type. ok is false if T is not discrete. The error
+// is errRangeBoundMaxValue if v is the maximum value of T.
+func rangeNextDiscreteValue[T any](v T) (next T, ok bool, err error) {
+	var nextAny any
+	switch n := any(v).(type) {
+	case Int2:
+		if !n.Valid {
+			return v, false, errRangeBoundNull
+		}
+		if n.Int16 == math.MaxInt16 {
+			return v, false, errRangeBoundMaxValue
+		}
+		nextAny = Int2{Int16: n.Int16 + 1, Valid: true}
+	case Int4:
+		if !n.Valid {
+			return v, false, errRangeBoundNull
+		}
+		if n.Int32 == math.MaxInt32 {
+			return v, false, errRangeBoundMaxValue
+		}
+		nextAny = Int4{Int32: n.Int32 + 1, Valid: true}
+	case Int8:
+		if !n.Valid {
+			return v, false, errRangeBoundNull
+		}
+		if n.Int64 == math.MaxInt64 {
+			return v, false, errRangeBoundMaxValue
+		}
+		nextAny = Int8{Int64: n.Int64 + 1, Valid: true}
+	case Date:
+		if !n.Valid {
+			return v, false, errRangeBoundNull
+		}
+		if n.InfinityModifier != Finite {
+			return v, false, nil
+		}
+		nextAny = Date{Time: n.Time.AddDate(0, 0, 1), Valid: true}
+	}
+	if nextAny != nil {
+		return nextAny.(T), true, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	nextValue := reflect.New(rv.Type()).Elem()
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := rv.Int()
+		if i == math.MaxInt64 || rv.OverflowInt(i+1) {
+			return v, false, errRangeBoundMaxValue
+		}
+		nextValue.SetInt(i + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := rv.Uint()
+		if u == math.MaxUint64 || rv.OverflowUint(u+1) {
+			return v, false, errRangeBoundMaxValue
+		}
+		nextValue.SetUint(u + 1)
+	default:
+		return v, false, nil
+	}
+
+	return nextValue.Interface().(T), true, nil
+}
@@ -3,6 +3,7 @@ package pgtype_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	pgx "github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -161,3 +162,68 @@ func TestRangeCodecDecodeValue(t *testing.T) {
 		}
 	})
 }
+
+func TestRangeCodecTimeTime(t *testing.T) {
+	m := pgtype.NewMap()
+
+	r := pgtype.Range[time.Time]{
+		Lower:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Upper:     time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		LowerType: pgtype.Inclusive,
+		UpperType: pgtype.Exclusive,
+		Valid:     true,
+	}
+
+	dt, ok := m.TypeForValue(r)
+	require.True(t, ok)
+	require.Equal(t, "tstzrange", dt.Name)
+
+	for _, format := range []int16{pgtype.TextFormatCode, pgtype.BinaryFormatCode} {
+		buf, err := m.Encode(pgtype.TstzrangeOID, format, r, nil)
+		require.NoError(t, err)
+
+		var scanned pgtype.Range[time.Time]
+		err = m.Scan(pgtype.TstzrangeOID, format, buf, &scanned)
+		require.NoError(t, err)
+		require.True(t, r.Lower.Equal(scanned.Lower))
+		require.True(t, r.Upper.Equal(scanned.Upper))
+		require.Equal(t, r.LowerType, scanned.LowerType)
+		require.Equal(t, r.UpperType, scanned.UpperType)
+		require.True(t, scanned.Valid)
+	}
+}
+
+func TestRangeCodecTranscodeTstzrangeTimeTime(t *testing.T) {
+	skipCockroachDB(t, "Server does not support range types (see https://github.com/cockroachdb/cockroach/issues/27791)")
+
+	isExpectedEqTimeRange := func(a any) func(any) bool {
+		return func(v any) bool {
+			ar := a.(pgtype.Range[time.Time])
+			vr := v.(pgtype.Range[time.Time])
+			return ar.Lower.Equal(vr.Lower) && ar.Upper.Equal(vr.Upper) && ar.LowerType == vr.LowerType &&
+				ar.UpperType == vr.UpperType && ar.Valid == vr.Valid
+		}
+	}
+
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	pgxtest.RunValueRoundTripTests(context.Background(), t, defaultConnTestRunner, nil, "tstzrange", []pgxtest.ValueRoundTripTest{
+		{
+			pgtype.Range[time.Time]{Lower: jan, Upper: feb, LowerType: pgtype.Inclusive, UpperType: pgtype.Exclusive, Valid: true},
+			new(pgtype.Range[time.Time]),
+			isExpectedEqTimeRange(pgtype.Range[time.Time]{Lower: jan, Upper: feb, LowerType: pgtype.Inclusive, UpperType: pgtype.Exclusive, Valid: true}),
+		},
+		{
+			pgtype.Range[time.Time]{Lower: jan, LowerType: pgtype.Inclusive, UpperType: pgtype.Unbounded, Valid: true},
+			new(pgtype.Range[time.Time]),
+			isExpectedEqTimeRange(pgtype.Range[time.Time]{Lower: jan, LowerType: pgtype.Inclusive, UpperType: pgtype.Unbounded, Valid: true}),
+		},
+		{
+			pgtype.Range[time.Time]{LowerType: pgtype.Empty, UpperType: pgtype.Empty, Valid: true},
+			new(pgtype.Range[time.Time]),
+			isExpectedEqTimeRange(pgtype.Range[time.Time]{LowerType: pgtype.Empty, UpperType: pgtype.Empty, Valid: true}),
+		},
+		{nil, new(pgtype.Range[time.Time]), isExpectedEqTimeRange(pgtype.Range[time.Time]{})},
+	})
+}
@@ -2,7 +2,9 @@ package pgtype
 
 import (
 	"bytes"
+	"math"
 	"testing"
+	"time"
 )
 
 func TestParseUntypedTextRange(t *testing.T) {
@@ -175,3 +177,231 @@ func TestParseUntypedBinaryRange(t *testing.T) {
 		}
 	}
 }
+
+func TestRangeNormalize(t *testing.T) {
+	tests := []struct {
+		r        Range[int32]
+		expected Range[int32]
+	}{
+		{
+			r:        Range[int32]{Lower: 1, Upper: 5, LowerType: Exclusive, UpperType: Inclusive, Valid: true},
+			expected: Range[int32]{Lower: 2, Upper: 6, LowerType: Inclusive, UpperType: Exclusive, Valid: true},
+		},
+		{
+			r:        Range[int32]{Lower: 1, Upper: 5, LowerType: Inclusive, UpperType: Exclusive, Valid: true},
+			expected: Range[int32]{Lower: 1, Upper: 5, LowerType: Inclusive, UpperType: Exclusive, Valid: true},
+		},
+		{
+			r:        Range[int32]{Upper: 5, LowerType: Unbounded, UpperType: Inclusive, Valid: true},
+			expected: Range[int32]{Upper: 6, LowerType: Unbounded, UpperType: Exclusive, Valid: true},
+		},
+		{
+			r:        Range[int32]{Lower: 5, Upper: 5, LowerType: Inclusive, UpperType: Exclusive, Valid: true},
+			expected: Range[int32]{LowerType: Empty, UpperType: Empty, Valid: true},
+		},
+		{
+			r:        Range[int32]{Lower: 4, Upper: 5, LowerType: Exclusive, UpperType: Exclusive, Valid: true},
+			expected: Range[int32]{LowerType: Empty, UpperType: Empty, Valid: true},
+		},
+		{
+			r:        Range[int32]{Lower: 6, Upper: 5, LowerType: Inclusive, UpperType: Inclusive, Valid: true},
+			expected: Range[int32]{LowerType: Empty, UpperType: Empty, Valid: true},
+		},
+		{
+			r:        Range[int32]{},
+			expected: Range[int32]{},
+		},
+	}
+
+	for i, tt := range tests {
+		actual, err := tt.r.Normalize()
+		if err != nil {
+			t.Errorf("%d. %v", i, err)
+		} else if actual != tt.expected {
+			t.Errorf("%d. expected %v, got %v", i, tt.expected, actual)
+		}
+	}
+
+	// Continuous ranges keep their bound types.
+	f := Range[float64]{Lower: 1, Upper: 5, LowerType: Exclusive, UpperType: Inclusive, Valid: true}
+	if actual := mustRangeResult[Range[float64]](t)(f.Normalize()); actual != f {
+		t.Errorf("expected %v, got %v", f, actual)
+	}
+	f = Range[float64]{Lower: 5, Upper: 5, LowerType: Inclusive, UpperType: Inclusive, Valid: true}
+	if actual := mustRangeResult[Range[float64]](t)(f.Normalize()); actual != f {
+		t.Errorf("expected %v, got %v", f, actual)
+	}
+	f = Range[float64]{Lower: 5, Upper: 5, LowerType: Inclusive, UpperType: Exclusive, Valid: true}
+	if !mustRangeResult[bool](t)(f.IsEmpty()) {
+		t.Errorf("expected %v to be empty", f)
+	}
+}
+
+func TestRangeTimeMethods(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	janToFeb := Range[time.Time]{Lower: jan, Upper: feb, LowerType: Inclusive, UpperType: Exclusive, Valid: true}
+	febToMar := Range[time.Time]{Lower: feb, Upper: mar, LowerType: Inclusive, UpperType: Exclusive, Valid: true}
+	fromFeb := Range[time.Time]{Lower: feb, LowerType: Inclusive, UpperType: Unbounded, Valid: true}
+	empty := Range[time.Time]{LowerType: Empty, UpperType: Empty, Valid: true}
+	must := mustRangeResult[bool](t)
+
+	if !must(janToFeb.Contains(jan)) {
+		t.Error("expected janToFeb to contain jan")
+	}
+	if !must(janToFeb.Contains(jan.Add(time.Hour))) {
+		t.Error("expected janToFeb to contain jan + 1 hour")
+	}
+	if must(janToFeb.Contains(feb)) {
+		t.Error("expected janToFeb not to contain feb")
+	}
+	if !must(fromFeb.Contains(mar.AddDate(10, 0, 0))) {
+		t.Error("expected fromFeb to contain mar + 10 years")
+	}
+	if must(empty.Contains(jan)) {
+		t.Error("expected empty not to contain jan")
+	}
+	if must((Range[time.Time]{}).Contains(jan)) {
+		t.Error("expected NULL not to contain jan")
+	}
+
+	if must(janToFeb.Overlaps(febToMar)) {
+		t.Error("expected janToFeb not to overlap febToMar")
+	}
+	janToFebInclusive := janToFeb
+	janToFebInclusive.UpperType = Inclusive
+	if !must(janToFebInclusive.Overlaps(febToMar)) || !must(febToMar.Overlaps(janToFebInclusive)) {
+		t.Error("expected janToFebInclusive to overlap febToMar")
+	}
+	if !must(febToMar.Overlaps(fromFeb)) {
+		t.Error("expected febToMar to overlap fromFeb")
+	}
+	if must(empty.Overlaps(fromFeb)) {
+		t.Error("expected empty not to overlap fromFeb")
+	}
+
+	if must(janToFeb.IsEmpty()) {
+		t.Error("expected janToFeb not to be empty")
+	}
+	if !must(empty.IsEmpty()) {
+		t.Error("expected empty to be empty")
+	}
+	if must((Range[time.Time]{}).IsEmpty()) {
+		t.Error("expected NULL not to be empty")
+	}
+}
+
+type rangeTestInt int16
+
+func TestRangeMethodsPgtypeBounds(t *testing.T) {
+	must := mustRangeResult[bool](t)
+
+	int4 := Range[Int4]{Lower: Int4{Int32: 1, Valid: true}, Upper: Int4{Int32: 5, Valid: true}, LowerType: Exclusive, UpperType: Inclusive, Valid: true}
+	if actual, expected := mustRangeResult[Range[Int4]](t)(int4.Normalize()), (Range[Int4]{Lower: Int4{Int32: 2, Valid: true}, Upper: Int4{Int32: 6, Valid: true}, LowerType: Inclusive, UpperType: Exclusive, Valid: true}); actual != expected {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if !must(int4.Contains(Int4{Int32: 5, Valid: true})) || must(int4.Contains(Int4{Int32: 1, Valid: true})) {
+		t.Errorf("unexpected Contains result for %v", int4)
+	}
+
+	int8 := Range[Int8]{Lower: Int8{Int64: -10, Valid: true}, Upper: Int8{Int64: 10, Valid: true}, LowerType: Inclusive, UpperType: Exclusive, Valid: true}
+	if !must(int8.Contains(Int8{Int64: 0, Valid: true})) || must(int8.Contains(Int8{Int64: 10, Valid: true})) {
+		t.Errorf("unexpected Contains result for %v", int8)
+	}
+
+	numeric := Range[Numeric]{Lower: mustParseNumeric(t, "1.5"), Upper: mustParseNumeric(t, "2.25"), LowerType: Inclusive, UpperType: Exclusive, Valid: true}
+	if !must(numeric.Contains(mustParseNumeric(t, "2.2"))) || must(numeric.Contains(mustParseNumeric(t, "2.250"))) || must(numeric.Contains(mustParseNumeric(t, "1.49"))) {
+		t.Errorf("unexpected Contains result for %v", numeric)
+	}
+	if !must((Range[Numeric]{Lower: mustParseNumeric(t, "1"), LowerType: Inclusive, UpperType: Exclusive, Upper: Numeric{NaN: true, Valid: true}, Valid: true}).Contains(Numeric{InfinityModifier: Infinity, Valid: true})) {
+		t.Error("expected NaN to be above Infinity")
+	}
+
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	date := Range[Date]{Lower: Date{Time: jan, Valid: true}, Upper: Date{Time: feb, Valid: true}, LowerType: Inclusive, UpperType: Inclusive, Valid: true}
+	if actual := mustRangeResult[Range[Date]](t)(date.Normalize()); actual.Upper.Time != feb.AddDate(0, 0, 1) || actual.UpperType != Exclusive {
+		t.Errorf("unexpected normalized range %v", actual)
+	}
+	if !must(date.Contains(Date{Time: feb, Valid: true})) || must(date.Contains(Date{InfinityModifier: Infinity, Valid: true})) {
+		t.Errorf("unexpected Contains result for %v", date)
+	}
+	fromJan := Range[Date]{Lower: Date{Time: jan, Valid: true}, Upper: Date{InfinityModifier: Infinity, Valid: true}, LowerType: Inclusive, UpperType: Inclusive, Valid: true}
+	if !must(fromJan.Contains(Date{InfinityModifier: Infinity, Valid: true})) {
+		t.Errorf("expected %v to contain infinity", fromJan)
+	}
+
+	timestamptz := Range[Timestamptz]{Lower: Timestamptz{Time: jan, Valid: true}, Upper: Timestamptz{Time: feb, Valid: true}, LowerType: Inclusive, UpperType: Exclusive, Valid: true}
+	if !must(timestamptz.Contains(Timestamptz{Time: jan.Add(time.Hour), Valid: true})) || must(timestamptz.Contains(Timestamptz{Time: feb, Valid: true})) {
+		t.Errorf("unexpected Contains result for %v", timestamptz)
+	}
+	if must(timestamptz.Contains(Timestamptz{InfinityModifier: NegativeInfinity, Valid: true})) {
+		t.Errorf("expected %v not to contain -infinity", timestamptz)
+	}
+
+	named := Range[rangeTestInt]{Lower: 1, Upper: 3, LowerType: Exclusive, UpperType: Inclusive, Valid: true}
+	if actual, expected := mustRangeResult[Range[rangeTestInt]](t)(named.Normalize()), (Range[rangeTestInt]{Lower: 2, Upper: 4, LowerType: Inclusive, UpperType: Exclusive, Valid: true}); actual != expected {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestRangeNormalizeMaxValue(t *testing.T) {
+	maxInt4 := Int4{Int32: math.MaxInt32, Valid: true}
+	r := Range[Int4]{Lower: Int4{Int32: 1, Valid: true}, Upper: maxInt4, LowerType: Inclusive, UpperType: Inclusive, Valid: true}
+	if actual := mustRangeResult[Range[Int4]](t)(r.Normalize()); actual != r {
+		t.Errorf("expected %v, got %v", r, actual)
+	}
+	if !mustRangeResult[bool](t)(r.Contains(maxInt4)) {
+		t.Errorf("expected %v to contain the maximum value", r)
+	}
+
+	r = Range[Int4]{Lower: maxInt4, LowerType: Exclusive, UpperType: Unbounded, Valid: true}
+	if !mustRangeResult[bool](t)(r.IsEmpty()) {
+		t.Errorf("expected %v to be empty", r)
+	}
+
+	u := Range[uint8]{Lower: 1, Upper: math.MaxUint8, LowerType: Exclusive, UpperType: Inclusive, Valid: true}
+	expected := Range[uint8]{Lower: 2, Upper: math.MaxUint8, LowerType: Inclusive, UpperType: Inclusive, Valid: true}
+	if actual := mustRangeResult[Range[uint8]](t)(u.Normalize()); actual != expected {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	i := Range[int64]{Lower: math.MaxInt64, LowerType: Exclusive, UpperType: Unbounded, Valid: true}
+	if !mustRangeResult[bool](t)(i.IsEmpty()) {
+		t.Errorf("expected %v to be empty", i)
+	}
+}
+
+func TestRangeMethodsErrors(t *testing.T) {
+	type point struct{ X, Y int }
+	p := Range[point]{Lower: point{1, 2}, Upper: point{3, 4}, LowerType: Inclusive, UpperType: Exclusive, Valid: true}
+	if _, err := p.Contains(point{2, 3}); err == nil {
+		t.Error("expected error for unsupported bound type")
+	}
+
+	null := Range[Int4]{Lower: Int4{}, Upper: Int4{Int32: 5, Valid: true}, LowerType: Inclusive, UpperType: Exclusive, Valid: true}
+	if _, err := null.Normalize(); err == nil {
+		t.Error("expected error for NULL bound")
+	}
+}
+
+func mustParseNumeric(t *testing.T, s string) Numeric {
+	var n Numeric
+	if err := n.Scan(s); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// mustRangeResult returns a function that fails t if err is not nil and returns v otherwise.
+func mustRangeResult[V any](t *testing.T) func(v V, err error) V {
+	return func(v V, err error) V {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+}
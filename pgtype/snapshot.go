@@ -0,0 +1,283 @@
+package pgtype
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/internal/pgio"
+)
+
+type SnapshotScanner interface {
+	ScanSnapshot(v Snapshot) error
+}
+
+type SnapshotValuer interface {
+	SnapshotValue() (Snapshot, error)
+}
+
+// Snapshot is PostgreSQL's pg_snapshot type. It is also used for the txid_snapshot type which has the same
+// representation.
+//
+// A snapshot is the set of transactions that are visible. Transactions before Xmin are visible if committed.
+// Transactions at or after Xmax are not visible. Transactions in Xip are in progress and not visible.
+type Snapshot struct {
+	Xmin  uint64
+	Xmax  uint64
+	Xip   []uint64
+	Valid bool
+}
+
+func (s *Snapshot) ScanSnapshot(v Snapshot) error {
+	*s = v
+	return nil
+}
+
+func (s Snapshot) SnapshotValue() (Snapshot, error) {
+	return s, nil
+}
+
+// Scan implements the database/sql Scanner interface.
+func (dst *Snapshot) Scan(src any) error {
+	if src == nil {
+		*dst = Snapshot{}
+		return nil
+	}
+
+	switch src := src.(type) {
+	case string:
+		return scanPlanTextAnyToSnapshotScanner{}.Scan([]byte(src), dst)
+	}
+
+	return fmt.Errorf("cannot scan %T", src)
+}
+
+// Value implements the database/sql/driver Valuer interface.
+func (src Snapshot) Value() (driver.Value, error) {
+	if !src.Valid {
+		return nil, nil
+	}
+
+	buf, err := SnapshotCodec{}.PlanEncode(nil, 0, TextFormatCode, src).Encode(src, nil)
+	if err != nil {
+		return nil, err
+	}
+	return string(buf), err
+}
+
+type SnapshotCodec struct{}
+
+func (SnapshotCodec) FormatSupported(format int16) bool {
+	return format == TextFormatCode || format == BinaryFormatCode
+}
+
+func (SnapshotCodec) PreferredFormat() int16 {
+	return BinaryFormatCode
+}
+
+func (SnapshotCodec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
+	if _, ok := value.(SnapshotValuer); !ok {
+		return nil
+	}
+
+	switch format {
+	case BinaryFormatCode:
+		return encodePlanSnapshotCodecBinary{}
+	case TextFormatCode:
+		return encodePlanSnapshotCodecText{}
+	}
+
+	return nil
+}
+
+type encodePlanSnapshotCodecBinary struct{}
+
+func (encodePlanSnapshotCodecBinary) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	snapshot, err := value.(SnapshotValuer).SnapshotValue()
+	if err != nil {
+		return nil, err
+	}
+
+	if !snapshot.Valid {
+		return nil, nil
+	}
+
+	buf = pgio.AppendInt32(buf, int32(len(snapshot.Xip)))
+	buf = pgio.AppendUint64(buf, snapshot.Xmin)
+	buf = pgio.AppendUint64(buf, snapshot.Xmax)
+	for _, xip := range snapshot.Xip {
+		buf = pgio.AppendUint64(buf, xip)
+	}
+	return buf, nil
+}
+
+type encodePlanSnapshotCodecText struct{}
+
+func (encodePlanSnapshotCodecText) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	snapshot, err := value.(SnapshotValuer).SnapshotValue()
+	if err != nil {
+		return nil, err
+	}
+
+	if !snapshot.Valid {
+		return nil, nil
+	}
+
+	return appendSnapshotText(buf, snapshot), nil
+}
+
+func appendSnapshotText(buf []byte, snapshot Snapshot) []byte {
+	buf = strconv.AppendUint(buf, snapshot.Xmin, 10)
+	buf = append(buf, ':')
+	buf = strconv.AppendUint(buf, snapshot.Xmax, 10)
+	buf = append(buf, ':')
+	for i, xip := range snapshot.Xip {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = strconv.AppendUint(buf, xip, 10)
+	}
+	return buf
+}
+
+func (SnapshotCodec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan {
+	switch format {
+	case BinaryFormatCode:
+		switch target.(type) {
+		case SnapshotScanner:
+			return scanPlanBinarySnapshotToSnapshotScanner{}
+		case TextScanner:
+			return scanPlanBinarySnapshotToTextScanner{}
+		}
+	case TextFormatCode:
+		switch target.(type) {
+		case SnapshotScanner:
+			return scanPlanTextAnyToSnapshotScanner{}
+		case TextScanner:
+			return scanPlanTextAnyToTextScanner{}
+		}
+	}
+
+	return nil
+}
+
+func parseBinarySnapshot(src []byte) (Snapshot, error) {
+	if len(src) < 20 {
+		return Snapshot{}, fmt.Errorf("invalid length for pg_snapshot: %v", len(src))
+	}
+
+	nxip := int32(binary.BigEndian.Uint32(src))
+	if nxip < 0 || len(src) != 20+int(nxip)*8 {
+		return Snapshot{}, fmt.Errorf("invalid length for pg_snapshot: %v", len(src))
+	}
+
+	snapshot := Snapshot{
+		Xmin:  binary.BigEndian.Uint64(src[4:]),
+		Xmax:  binary.BigEndian.Uint64(src[12:]),
+		Valid: true,
+	}
+
+	if nxip > 0 {
+		snapshot.Xip = make([]uint64, nxip)
+		rp := 20
+		for i := range snapshot.Xip {
+			snapshot.Xip[i] = binary.BigEndian.Uint64(src[rp:])
+			rp += 8
+		}
+	}
+
+	return snapshot, nil
+}
+
+type scanPlanBinarySnapshotToSnapshotScanner struct{}
+
+func (scanPlanBinarySnapshotToSnapshotScanner) Scan(src []byte, dst any) error {
+	scanner := (dst).(SnapshotScanner)
+
+	if src == nil {
+		return scanner.ScanSnapshot(Snapshot{})
+	}
+
+	snapshot, err := parseBinarySnapshot(src)
+	if err != nil {
+		return err
+	}
+
+	return scanner.ScanSnapshot(snapshot)
+}
+
+type scanPlanBinarySnapshotToTextScanner struct{}
+
+func (scanPlanBinarySnapshotToTextScanner) Scan(src []byte, dst any) error {
+	scanner := (dst).(TextScanner)
+
+	if src == nil {
+		return scanner.ScanText(Text{})
+	}
+
+	snapshot, err := parseBinarySnapshot(src)
+	if err != nil {
+		return err
+	}
+
+	return scanner.ScanText(Text{String: string(appendSnapshotText(nil, snapshot)), Valid: true})
+}
+
+type scanPlanTextAnyToSnapshotScanner struct{}
+
+func (scanPlanTextAnyToSnapshotScanner) Scan(src []byte, dst any) error {
+	scanner := (dst).(SnapshotScanner)
+
+	if src == nil {
+		return scanner.ScanSnapshot(Snapshot{})
+	}
+
+	parts := strings.Split(string(src), ":")
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid format for pg_snapshot")
+	}
+
+	xmin, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return err
+	}
+
+	xmax, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return err
+	}
+
+	snapshot := Snapshot{Xmin: xmin, Xmax: xmax, Valid: true}
+
+	if parts[2] != "" {
+		xips := strings.Split(parts[2], ",")
+		snapshot.Xip = make([]uint64, len(xips))
+		for i, s := range xips {
+			snapshot.Xip[i], err = strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return scanner.ScanSnapshot(snapshot)
+}
+
+func (c SnapshotCodec) DecodeDatabaseSQLValue(m *Map, oid uint32, format int16, src []byte) (driver.Value, error) {
+	return codecDecodeToTextFormat(c, m, oid, format, src)
+}
+
+func (c SnapshotCodec) DecodeValue(m *Map, oid uint32, format int16, src []byte) (any, error) {
+	if src == nil {
+		return nil, nil
+	}
+
+	var snapshot Snapshot
+	err := codecScan(c, m, oid, format, src, &snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
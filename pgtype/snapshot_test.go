@@ -0,0 +1,67 @@
+package pgtype_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotCodec(t *testing.T) {
+	skipCockroachDB(t, "Server does not support type txid_snapshot")
+
+	for _, typeName := range []string{"txid_snapshot", "pg_snapshot"} {
+		if typeName == "pg_snapshot" {
+			skipPostgreSQLVersionLessThan(t, 13)
+		}
+
+		pgxtest.RunValueRoundTripTests(context.Background(), t, defaultConnTestRunner, nil, typeName, []pgxtest.ValueRoundTripTest{
+			{
+				pgtype.Snapshot{Xmin: 10, Xmax: 20, Xip: []uint64{10, 12, 15}, Valid: true},
+				new(pgtype.Snapshot),
+				isExpectedEq(pgtype.Snapshot{Xmin: 10, Xmax: 20, Xip: []uint64{10, 12, 15}, Valid: true}),
+			},
+			{
+				pgtype.Snapshot{Xmin: 10, Xmax: 10, Valid: true},
+				new(pgtype.Snapshot),
+				isExpectedEq(pgtype.Snapshot{Xmin: 10, Xmax: 10, Valid: true}),
+			},
+			{
+				pgtype.Snapshot{Xmin: 10, Xmax: 20, Xip: []uint64{10, 12, 15}, Valid: true},
+				new(string),
+				isExpectedEq("10:20:10,12,15"),
+			},
+			{pgtype.Snapshot{}, new(pgtype.Snapshot), isExpectedEq(pgtype.Snapshot{})},
+			{nil, new(pgtype.Snapshot), isExpectedEq(pgtype.Snapshot{})},
+		})
+	}
+}
+
+func TestSnapshotEncodeScan(t *testing.T) {
+	m := pgtype.NewMap()
+
+	for _, format := range []int16{pgtype.TextFormatCode, pgtype.BinaryFormatCode} {
+		for _, snapshot := range []pgtype.Snapshot{
+			{Xmin: 10, Xmax: 20, Xip: []uint64{10, 12, 15}, Valid: true},
+			{Xmin: 1 << 40, Xmax: 1<<40 + 5, Xip: []uint64{1<<40 + 1}, Valid: true},
+			{Xmin: 3, Xmax: 3, Valid: true},
+		} {
+			buf, err := m.Encode(pgtype.PgSnapshotOID, format, snapshot, nil)
+			require.NoError(t, err)
+
+			var result pgtype.Snapshot
+			err = m.Scan(pgtype.PgSnapshotOID, format, buf, &result)
+			require.NoError(t, err)
+			assert.Equal(t, snapshot, result)
+		}
+	}
+
+	var result pgtype.Snapshot
+	err := m.Scan(pgtype.PgSnapshotOID, pgtype.TextFormatCode, []byte("10:20"), &result)
+	require.Error(t, err)
+	err = m.Scan(pgtype.PgSnapshotOID, pgtype.BinaryFormatCode, []byte{0, 0, 0, 1}, &result)
+	require.Error(t, err)
+}
@@ -0,0 +1,200 @@
+package pgtype
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// StructTagKey is the key of the struct tag that overrides the database name of a struct field. The name is the part
+// of the tag before the first comma. A field tagged with db:"-" is ignored. A field with an empty name, e.g.
+// db:",omitempty", is treated as if it had no tag.
+const StructTagKey = "db"
+
+// NamingStrategy matches struct fields that do not have a "db" tag with database column and composite attribute names.
+// It is used by pgx.RowToStructByName and similar functions and by CompositeCodec when scanning into or encoding from a
+// struct. It is configured with Map.NamingStrategy.
+//
+// A NamingStrategy whose dynamic type is comparable allows struct mappings to be cached.
+type NamingStrategy interface {
+	// MatchName returns true if the struct field named fieldName corresponds to the database name dbName.
+	MatchName(fieldName, dbName string) bool
+}
+
+var (
+	// DefaultNamingStrategy matches names case-insensitively and ignores underscores. e.g. the field UserID matches
+	// user_id, userid, and UserID.
+	DefaultNamingStrategy NamingStrategy = defaultNamingStrategy{}
+
+	// SnakeCaseNamingStrategy matches the snake case form of the field name exactly. e.g. the field UserID matches
+	// user_id and the field HTTPServer matches http_server.
+	SnakeCaseNamingStrategy NamingStrategy = snakeCaseNamingStrategy{}
+
+	// CamelCaseNamingStrategy matches the lower camel case form of the field name exactly. e.g. the field UserID matches
+	// userID and the field HTTPServer matches httpServer.
+	CamelCaseNamingStrategy NamingStrategy = camelCaseNamingStrategy{}
+)
+
+// NamingStrategyFunc is a NamingStrategy that matches the database name returned by the function for a field name
+// exactly. Its type is not comparable, so struct mappings that use it are not cached.
+type NamingStrategyFunc func(fieldName string) string
+
+// MatchName implements NamingStrategy.
+func (f NamingStrategyFunc) MatchName(fieldName, dbName string) bool {
+	return f(fieldName) == dbName
+}
+
+type defaultNamingStrategy struct{}
+
+func (defaultNamingStrategy) MatchName(fieldName, dbName string) bool {
+	return strings.EqualFold(strings.ReplaceAll(fieldName, "_", ""), strings.ReplaceAll(dbName, "_", ""))
+}
+
+type snakeCaseNamingStrategy struct{}
+
+func (snakeCaseNamingStrategy) MatchName(fieldName, dbName string) bool {
+	return toSnakeCase(fieldName) == dbName
+}
+
+type camelCaseNamingStrategy struct{}
+
+func (camelCaseNamingStrategy) MatchName(fieldName, dbName string) bool {
+	return toCamelCase(fieldName) == dbName
+}
+
+// toSnakeCase converts a Go identifier to snake case. An acronym is treated as a single word. e.g. UserID becomes
+// user_id and HTTPServer becomes http_server.
+func toSnakeCase(s string) string {
+	runes := []rune(s)
+	var sb strings.Builder
+	sb.Grow(len(s) + 4)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prev != '_' && (unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower)) {
+				sb.WriteByte('_')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
+
+// toCamelCase converts a Go identifier to lower camel case. A leading acronym is lower cased as a single word. e.g.
+// UserID becomes userID and HTTPServer becomes httpServer.
+func toCamelCase(s string) string {
+	runes := []rune(s)
+	for i := range runes {
+		if !unicode.IsUpper(runes[i]) {
+			break
+		}
+		// The last upper case letter of a leading acronym starts the next word.
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// StructField is a field of a struct that can be mapped to a database column or composite attribute by name. See
+// StructFields.
+type StructField struct {
+	// Name is the name of the Go field.
+	Name string
+
+	// DBName is the name from the "db" struct tag. It is empty if the field does not have a name in a "db" tag.
+	DBName string
+
+	// Index is the index sequence of the field for reflect.Value.FieldByIndex.
+	Index []int
+}
+
+// MatchName returns true if f corresponds to the database name dbName. A field with a "db" tag only matches its tag
+// name exactly. Otherwise, strategy decides. If strategy is nil, DefaultNamingStrategy is used.
+func (f *StructField) MatchName(dbName string, strategy NamingStrategy) bool {
+	if f.DBName != "" {
+		return f.DBName == dbName
+	}
+	if strategy == nil {
+		strategy = DefaultNamingStrategy
+	}
+	return strategy.MatchName(f.Name, dbName)
+}
+
+// ColumnName returns the name of f for use in error messages. It is the "db" tag name if present and the Go field
+// name otherwise.
+func (f *StructField) ColumnName() string {
+	if f.DBName != "" {
+		return f.DBName
+	}
+	return f.Name
+}
+
+// Map from reflect.Type -> []StructField
+var structFieldsMap sync.Map
+
+// StructFields returns the fields of the struct type t that can be mapped by name in order. These are the exported
+// fields of t that are not tagged with db:"-". The fields of an embedded struct are included in place of the embedded
+// struct itself. An embedded pointer to a struct is treated as a regular field. The returned slice is shared and must
+// not be modified.
+func StructFields(t reflect.Type) []StructField {
+	if cached, ok := structFieldsMap.Load(t); ok {
+		return cached.([]StructField)
+	}
+
+	fields := computeStructFields(t, nil, nil)
+	fieldsIface, _ := structFieldsMap.LoadOrStore(t, fields)
+	return fieldsIface.([]StructField)
+}
+
+func computeStructFields(t reflect.Type, fields []StructField, index []int) []StructField {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fieldIndex := append(index[:len(index):len(index)], i)
+
+		// Handle anonymous struct embedding, but do not try to handle embedded pointers.
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			fields = computeStructFields(sf.Type, fields, fieldIndex)
+			continue
+		}
+
+		if !sf.IsExported() {
+			continue
+		}
+
+		dbTag, _ := sf.Tag.Lookup(StructTagKey)
+		dbName, _, _ := strings.Cut(dbTag, ",")
+		if dbName == "-" {
+			continue
+		}
+
+		fields = append(fields, StructField{Name: sf.Name, DBName: dbName, Index: fieldIndex})
+	}
+
+	return fields
+}
+
+// matchStructFields returns the index of the struct field in fields for each of dbNames. ok is false if any of dbNames
+// does not match a field or if a field matches more than one of dbNames.
+func matchStructFields(fields []StructField, dbNames []string, strategy NamingStrategy) (indexes []int, ok bool) {
+	indexes = make([]int, len(dbNames))
+	used := make([]bool, len(fields))
+	for i, dbName := range dbNames {
+		indexes[i] = -1
+		for j := range fields {
+			if fields[j].MatchName(dbName, strategy) {
+				indexes[i] = j
+				break
+			}
+		}
+		if indexes[i] == -1 || used[indexes[i]] {
+			return nil, false
+		}
+		used[indexes[i]] = true
+	}
+
+	return indexes, true
+}
@@ -0,0 +1,88 @@
+package pgtype_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructFields(t *testing.T) {
+	type Embedded struct {
+		E1 int32
+		e2 int32
+	}
+
+	type unexportedEmbedded struct {
+		U1 int32
+	}
+
+	type S struct {
+		A string
+		b string
+		C string `db:"c_column"`
+		D string `db:"-"`
+		Embedded
+		unexportedEmbedded
+		F *Embedded
+		G string `db:",omitempty"`
+	}
+
+	fields := pgtype.StructFields(reflect.TypeOf(S{}))
+	require.Equal(t, []pgtype.StructField{
+		{Name: "A", Index: []int{0}},
+		{Name: "C", DBName: "c_column", Index: []int{2}},
+		{Name: "E1", Index: []int{4, 0}},
+		{Name: "U1", Index: []int{5, 0}},
+		{Name: "F", Index: []int{6}},
+		{Name: "G", Index: []int{7}},
+	}, fields)
+}
+
+func TestStructFieldMatchName(t *testing.T) {
+	tagged := pgtype.StructField{Name: "UserID", DBName: "uid"}
+	untagged := pgtype.StructField{Name: "UserID"}
+
+	for i, tt := range []struct {
+		field    pgtype.StructField
+		dbName   string
+		strategy pgtype.NamingStrategy
+		expected bool
+	}{
+		{field: tagged, dbName: "uid", strategy: nil, expected: true},
+		{field: tagged, dbName: "UID", strategy: nil, expected: false},
+		{field: tagged, dbName: "user_id", strategy: nil, expected: false},
+		{field: tagged, dbName: "uid", strategy: pgtype.SnakeCaseNamingStrategy, expected: true},
+		{field: untagged, dbName: "user_id", strategy: nil, expected: true},
+		{field: untagged, dbName: "userid", strategy: pgtype.DefaultNamingStrategy, expected: true},
+		{field: untagged, dbName: "USER_ID", strategy: pgtype.DefaultNamingStrategy, expected: true},
+		{field: untagged, dbName: "user_id", strategy: pgtype.SnakeCaseNamingStrategy, expected: true},
+		{field: untagged, dbName: "userid", strategy: pgtype.SnakeCaseNamingStrategy, expected: false},
+		{field: untagged, dbName: "userID", strategy: pgtype.CamelCaseNamingStrategy, expected: true},
+		{field: untagged, dbName: "user_id", strategy: pgtype.CamelCaseNamingStrategy, expected: false},
+		{field: untagged, dbName: "USERID", strategy: pgtype.NamingStrategyFunc(func(s string) string { return "USERID" }), expected: true},
+	} {
+		assert.Equalf(t, tt.expected, tt.field.MatchName(tt.dbName, tt.strategy), "%d", i)
+	}
+}
+
+func TestNamingStrategies(t *testing.T) {
+	for i, tt := range []struct {
+		fieldName string
+		snake     string
+		camel     string
+	}{
+		{fieldName: "ID", snake: "id", camel: "id"},
+		{fieldName: "Name", snake: "name", camel: "name"},
+		{fieldName: "UserID", snake: "user_id", camel: "userID"},
+		{fieldName: "HTTPServer", snake: "http_server", camel: "httpServer"},
+		{fieldName: "Address2", snake: "address2", camel: "address2"},
+		{fieldName: "Line2Text", snake: "line2_text", camel: "line2Text"},
+		{fieldName: "Already_Snake", snake: "already_snake", camel: "already_Snake"},
+	} {
+		assert.Truef(t, pgtype.SnakeCaseNamingStrategy.MatchName(tt.fieldName, tt.snake), "%d", i)
+		assert.Truef(t, pgtype.CamelCaseNamingStrategy.MatchName(tt.fieldName, tt.camel), "%d", i)
+	}
+}
@@ -137,9 +137,9 @@ func (*TimestampCodec) PlanEncode(m *Map, oid uint32, format int16, value any) E
 
 	switch format {
 	case BinaryFormatCode:
-		return encodePlanTimestampCodecBinary{}
+		return wrapInfinityTimeEncodePlan(m, value, encodePlanTimestampCodecBinary{})
 	case TextFormatCode:
-		return encodePlanTimestampCodecText{}
+		return wrapInfinityTimeEncodePlan(m, value, encodePlanTimestampCodecText{})
 	}
 
 	return nil
@@ -161,6 +161,9 @@ func (encodePlanTimestampCodecBinary) Encode(value any, buf []byte) (newBuf []by
 	switch ts.InfinityModifier {
 	case Finite:
 		t := discardTimeZone(ts.Time)
+		if t.Before(MinTimestamp) || t.After(MaxTimestamp) {
+			return nil, fmt.Errorf("timestamp out of range: %v", ts.Time)
+		}
 		microsecSinceUnixEpoch := t.Unix()*1000000 + int64(t.Nanosecond())/1000
 		microsecSinceY2K = microsecSinceUnixEpoch - microsecFromUnixEpochToY2K
 	case Infinity:
@@ -216,6 +219,118 @@ func (encodePlanTimestampCodecText) Encode(value any, buf []byte) (newBuf []byte
 	return buf, nil
 }
 
+// parseTimestampText parses s in the format PostgreSQL uses to output timestamp and timestamptz values with the ISO
+// DateStyle. e.g. "2006-01-02 15:04:05.999999". If withOffset is true, s must end with an offset of the form "-07",
+// "-07:00", or "-07:00:00" and the location of the result is set as time.Parse would. Otherwise, the result is in UTC.
+// The " BC" suffix must already have been removed. ok is false if s is not in this format. The caller should then fall
+// back to time.Parse to parse s or to get a descriptive error. This is much faster than time.Parse.
+func parseTimestampText(s string, withOffset bool) (t time.Time, ok bool) {
+	yearLen := 0
+	for yearLen < len(s) && s[yearLen] >= '0' && s[yearLen] <= '9' {
+		yearLen++
+	}
+	if yearLen < 4 || yearLen > 9 {
+		return time.Time{}, false
+	}
+	year, _ := parseTimestampDigits(s[:yearLen])
+	s = s[yearLen:]
+
+	if len(s) < 15 || s[0] != '-' || s[3] != '-' || s[6] != ' ' || s[9] != ':' || s[12] != ':' {
+		return time.Time{}, false
+	}
+	month, ok1 := parseTimestampDigits(s[1:3])
+	day, ok2 := parseTimestampDigits(s[4:6])
+	hour, ok3 := parseTimestampDigits(s[7:9])
+	minute, ok4 := parseTimestampDigits(s[10:12])
+	second, ok5 := parseTimestampDigits(s[13:15])
+	if !(ok1 && ok2 && ok3 && ok4 && ok5) {
+		return time.Time{}, false
+	}
+	s = s[15:]
+
+	nsec := 0
+	if len(s) > 0 && s[0] == '.' {
+		fracLen := 1
+		for fracLen < len(s) && s[fracLen] >= '0' && s[fracLen] <= '9' {
+			fracLen++
+		}
+		if fracLen == 1 || fracLen > 10 {
+			return time.Time{}, false
+		}
+		nsec, _ = parseTimestampDigits(s[1:fracLen])
+		for i := fracLen; i < 10; i++ {
+			nsec *= 10
+		}
+		s = s[fracLen:]
+	}
+
+	offset := 0
+	if withOffset {
+		if len(s) < 3 || (s[0] != '+' && s[0] != '-') {
+			return time.Time{}, false
+		}
+		sign := s[0]
+		offsetHours, ok := parseTimestampDigits(s[1:3])
+		if !ok {
+			return time.Time{}, false
+		}
+		offset = offsetHours * 3600
+		s = s[3:]
+
+		for i := 0; i < 2 && len(s) > 0; i++ {
+			if len(s) < 3 || s[0] != ':' {
+				return time.Time{}, false
+			}
+			n, ok := parseTimestampDigits(s[1:3])
+			if !ok || n > 59 {
+				return time.Time{}, false
+			}
+			if i == 0 {
+				offset += n * 60
+			} else {
+				offset += n
+			}
+			s = s[3:]
+		}
+
+		if sign == '-' {
+			offset = -offset
+		}
+	}
+
+	if len(s) != 0 || month < 1 || month > 12 || day < 1 || hour > 23 || minute > 59 || second > 59 {
+		return time.Time{}, false
+	}
+
+	t = time.Date(year, time.Month(month), day, hour, minute, second, nsec, time.UTC)
+	if t.Day() != day {
+		return time.Time{}, false // day is out of range for month
+	}
+
+	if !withOffset {
+		return t, true
+	}
+
+	t = t.Add(-time.Duration(offset) * time.Second)
+	localT := t.In(time.Local)
+	if _, localOffset := localT.Zone(); localOffset == offset {
+		return localT, true
+	}
+	return t.In(time.FixedZone("", offset)), true
+}
+
+// parseTimestampDigits parses s as a non-negative decimal integer. s must only contain the digits 0-9.
+func parseTimestampDigits(s string) (int, bool) {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, false
+		}
+		n = n*10 + int(s[i]-'0')
+	}
+	return n, true
+}
+
 func discardTimeZone(t time.Time) time.Time {
 	if t.Location() != time.UTC {
 		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
@@ -229,12 +344,12 @@ func (c *TimestampCodec) PlanScan(m *Map, oid uint32, format int16, target any)
 	case BinaryFormatCode:
 		switch target.(type) {
 		case TimestampScanner:
-			return &scanPlanBinaryTimestampToTimestampScanner{location: c.ScanLocation}
+			return wrapInfinityTimeScanPlan(m, target, &scanPlanBinaryTimestampToTimestampScanner{location: c.ScanLocation})
 		}
 	case TextFormatCode:
 		switch target.(type) {
 		case TimestampScanner:
-			return &scanPlanTextTimestampToTimestampScanner{location: c.ScanLocation}
+			return wrapInfinityTimeScanPlan(m, target, &scanPlanTextTimestampToTimestampScanner{location: c.ScanLocation})
 		}
 	}
 
@@ -298,9 +413,13 @@ func (plan *scanPlanTextTimestampToTimestampScanner) Scan(src []byte, dst any) e
 			sbuf = sbuf[:len(sbuf)-3]
 			bc = true
 		}
-		tim, err := time.Parse(pgTimestampFormat, sbuf)
-		if err != nil {
-			return err
+		tim, ok := parseTimestampText(sbuf, false)
+		if !ok {
+			var err error
+			tim, err = time.Parse(pgTimestampFormat, sbuf)
+			if err != nil {
+				return err
+			}
 		}
 
 		if bc {
@@ -99,6 +99,37 @@ func TestTimestampCodecDecodeTextInvalid(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestTimestampDecodeText(t *testing.T) {
+	c := &pgtype.TimestampCodec{}
+	var ts pgtype.Timestamp
+	plan := c.PlanScan(nil, pgtype.TimestampOID, pgtype.TextFormatCode, &ts)
+
+	for _, tt := range []struct {
+		src      string
+		expected time.Time
+	}{
+		{"2024-03-04 05:06:07", time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC)},
+		{"2024-03-04 05:06:07.1", time.Date(2024, 3, 4, 5, 6, 7, 100000000, time.UTC)},
+		{"2024-03-04 05:06:07.123456", time.Date(2024, 3, 4, 5, 6, 7, 123456000, time.UTC)},
+		{"0001-02-03 04:05:06 BC", time.Date(0, 2, 3, 4, 5, 6, 0, time.UTC)},
+		{"12345-01-01 00:00:00", time.Date(12345, 1, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		err := plan.Scan([]byte(tt.src), &ts)
+		require.NoError(t, err, tt.src)
+		require.Equal(t, pgtype.Timestamp{Time: tt.expected, Valid: true}, ts, tt.src)
+	}
+
+	for _, src := range []string{
+		"2024-02-30 00:00:00",
+		"2024-01-01 00:00:60",
+		"2024-01-01 00:00:00+00",
+		"2024-01-01T00:00:00",
+	} {
+		err := plan.Scan([]byte(src), &ts)
+		require.Error(t, err, src)
+	}
+}
+
 func TestTimestampMarshalJSON(t *testing.T) {
 	successfulTests := []struct {
 		source pgtype.Timestamp
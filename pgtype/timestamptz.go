@@ -145,9 +145,9 @@ func (*TimestamptzCodec) PlanEncode(m *Map, oid uint32, format int16, value any)
 
 	switch format {
 	case BinaryFormatCode:
-		return encodePlanTimestamptzCodecBinary{}
+		return wrapInfinityTimeEncodePlan(m, value, encodePlanTimestamptzCodecBinary{})
 	case TextFormatCode:
-		return encodePlanTimestamptzCodecText{}
+		return wrapInfinityTimeEncodePlan(m, value, encodePlanTimestamptzCodecText{})
 	}
 
 	return nil
@@ -168,6 +168,9 @@ func (encodePlanTimestamptzCodecBinary) Encode(value any, buf []byte) (newBuf []
 	var microsecSinceY2K int64
 	switch ts.InfinityModifier {
 	case Finite:
+		if ts.Time.Before(MinTimestamp) || ts.Time.After(MaxTimestamp) {
+			return nil, fmt.Errorf("timestamptz out of range: %v", ts.Time)
+		}
 		microsecSinceUnixEpoch := ts.Time.Unix()*1000000 + int64(ts.Time.Nanosecond())/1000
 		microsecSinceY2K = microsecSinceUnixEpoch - microsecFromUnixEpochToY2K
 	case Infinity:
@@ -230,12 +233,12 @@ func (c *TimestamptzCodec) PlanScan(m *Map, oid uint32, format int16, target any
 	case BinaryFormatCode:
 		switch target.(type) {
 		case TimestamptzScanner:
-			return &scanPlanBinaryTimestamptzToTimestamptzScanner{location: c.ScanLocation}
+			return wrapInfinityTimeScanPlan(m, target, &scanPlanBinaryTimestamptzToTimestamptzScanner{location: c.ScanLocation})
 		}
 	case TextFormatCode:
 		switch target.(type) {
 		case TimestamptzScanner:
-			return &scanPlanTextTimestamptzToTimestamptzScanner{location: c.ScanLocation}
+			return wrapInfinityTimeScanPlan(m, target, &scanPlanTextTimestamptzToTimestamptzScanner{location: c.ScanLocation})
 		}
 	}
 
@@ -300,18 +303,22 @@ func (plan *scanPlanTextTimestamptzToTimestamptzScanner) Scan(src []byte, dst an
 			bc = true
 		}
 
-		var format string
-		if len(sbuf) >= 9 && (sbuf[len(sbuf)-9] == '-' || sbuf[len(sbuf)-9] == '+') {
-			format = pgTimestamptzSecondFormat
-		} else if len(sbuf) >= 6 && (sbuf[len(sbuf)-6] == '-' || sbuf[len(sbuf)-6] == '+') {
-			format = pgTimestamptzMinuteFormat
-		} else {
-			format = pgTimestamptzHourFormat
-		}
-
-		tim, err := time.Parse(format, sbuf)
-		if err != nil {
-			return err
+		tim, ok := parseTimestampText(sbuf, true)
+		if !ok {
+			var format string
+			if len(sbuf) >= 9 && (sbuf[len(sbuf)-9] == '-' || sbuf[len(sbuf)-9] == '+') {
+				format = pgTimestamptzSecondFormat
+			} else if len(sbuf) >= 6 && (sbuf[len(sbuf)-6] == '-' || sbuf[len(sbuf)-6] == '+') {
+				format = pgTimestamptzMinuteFormat
+			} else {
+				format = pgTimestamptzHourFormat
+			}
+
+			var err error
+			tim, err = time.Parse(format, sbuf)
+			if err != nil {
+				return err
+			}
 		}
 
 		if bc {
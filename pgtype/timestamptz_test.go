@@ -97,6 +97,55 @@ func TestTimestamptzDecodeTextInvalid(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestTimestamptzDecodeText(t *testing.T) {
+	c := &pgtype.TimestamptzCodec{}
+	var tstz pgtype.Timestamptz
+	plan := c.PlanScan(nil, pgtype.TimestamptzOID, pgtype.TextFormatCode, &tstz)
+
+	for _, tt := range []struct {
+		src    string
+		layout string
+	}{
+		{"2024-03-04 05:06:07-05", "2006-01-02 15:04:05Z07"},
+		{"2024-03-04 05:06:07+00", "2006-01-02 15:04:05Z07"},
+		{"2024-03-04 05:06:07.1+02", "2006-01-02 15:04:05.999999999Z07"},
+		{"2024-03-04 05:06:07.123456+05:30", "2006-01-02 15:04:05.999999999Z07:00"},
+		{"1901-12-13 20:45:52.000001+00:09:21", "2006-01-02 15:04:05.999999999Z07:00:00"},
+		{"2024-02-29 23:59:59.999999-12", "2006-01-02 15:04:05.999999999Z07"},
+	} {
+		expected, err := time.Parse(tt.layout, tt.src)
+		require.NoError(t, err)
+
+		err = plan.Scan([]byte(tt.src), &tstz)
+		require.NoError(t, err, tt.src)
+		require.True(t, tstz.Valid, tt.src)
+		require.True(t, expected.Equal(tstz.Time), "%s: expected %v, got %v", tt.src, expected, tstz.Time)
+		require.Equal(t, expected.Location().String(), tstz.Time.Location().String(), tt.src)
+		_, expectedOffset := expected.Zone()
+		_, actualOffset := tstz.Time.Zone()
+		require.Equal(t, expectedOffset, actualOffset, tt.src)
+	}
+
+	err := plan.Scan([]byte("2000-01-01 00:00:00 BC+00"), &tstz)
+	require.Error(t, err)
+
+	err = plan.Scan([]byte("0001-02-03 04:05:06+00 BC"), &tstz)
+	require.NoError(t, err)
+	require.True(t, time.Date(0, 2, 3, 4, 5, 6, 0, time.UTC).Equal(tstz.Time))
+
+	for _, src := range []string{
+		"2024-02-30 00:00:00+00",
+		"2024-13-01 00:00:00+00",
+		"2024-01-01 24:00:00+00",
+		"2024-01-01 00:00:00",
+		"2024-01-01 00:00:00.+00",
+		"2024-01-01 00:00:00+00:",
+	} {
+		err := plan.Scan([]byte(src), &tstz)
+		require.Error(t, err, src)
+	}
+}
+
 func TestTimestamptzMarshalJSON(t *testing.T) {
 	successfulTests := []struct {
 		source pgtype.Timestamptz
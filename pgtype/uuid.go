@@ -3,8 +3,10 @@ package pgtype
 import (
 	"bytes"
 	"database/sql/driver"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"reflect"
 )
 
 type UUIDScanner interface {
@@ -143,20 +145,70 @@ func (UUIDCodec) PreferredFormat() int16 {
 }
 
 func (UUIDCodec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
-	if _, ok := value.(UUIDValuer); !ok {
-		return nil
+	if _, ok := value.(UUIDValuer); ok {
+		switch format {
+		case BinaryFormatCode:
+			return encodePlanUUIDCodecBinaryUUIDValuer{}
+		case TextFormatCode:
+			return encodePlanUUIDCodecTextUUIDValuer{}
+		}
 	}
 
-	switch format {
-	case BinaryFormatCode:
-		return encodePlanUUIDCodecBinaryUUIDValuer{}
-	case TextFormatCode:
-		return encodePlanUUIDCodecTextUUIDValuer{}
+	// Types defined as [16]byte such as github.com/google/uuid.UUID and github.com/gofrs/uuid.UUID are encoded directly
+	// rather than through their driver.Valuer or fmt.Stringer implementations.
+	if isByte16Type(reflect.TypeOf(value)) {
+		switch format {
+		case BinaryFormatCode:
+			return encodePlanUUIDCodecBinaryByte16{}
+		case TextFormatCode:
+			return encodePlanUUIDCodecTextByte16{}
+		}
+	}
+
+	return nil
+}
+
+// RegisterUUIDType registers the Go type of value as the default PostgreSQL type for uuid and slices of it as the
+// default for uuid[]. value must be of a type defined as [16]byte such as github.com/google/uuid.UUID or
+// github.com/gofrs/uuid.UUID. UUIDCodec encodes and scans such types whether or not they are registered. Registering
+// the type is only needed when the OID of a value is not known, e.g. with QueryExecModeExec or when the type of a
+// parameter cannot be determined by the server.
+//
+//	m.RegisterUUIDType(uuid.UUID{})
+func (m *Map) RegisterUUIDType(value any) error {
+	t := reflect.TypeOf(value)
+	if !isByte16Type(t) {
+		return fmt.Errorf("cannot register %T as a uuid type: it must be a type defined as [16]byte", value)
 	}
 
+	m.RegisterDefaultPgType(value, "uuid")
+	m.RegisterDefaultPgType(reflect.Zero(reflect.SliceOf(t)).Interface(), "_uuid")
 	return nil
 }
 
+// isByte16Type returns true if t is a type other than [16]byte whose underlying type is [16]byte.
+func isByte16Type(t reflect.Type) bool {
+	return t != nil && t.Kind() == reflect.Array && t.Len() == 16 && t.Elem().Kind() == reflect.Uint8 &&
+		t != reflect.TypeOf([16]byte{})
+}
+
+func byte16Value(value any) [16]byte {
+	return reflect.ValueOf(value).Convert(reflect.TypeOf([16]byte{})).Interface().([16]byte)
+}
+
+type encodePlanUUIDCodecBinaryByte16 struct{}
+
+func (encodePlanUUIDCodecBinaryByte16) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	b := byte16Value(value)
+	return append(buf, b[:]...), nil
+}
+
+type encodePlanUUIDCodecTextByte16 struct{}
+
+func (encodePlanUUIDCodecTextByte16) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	return append(buf, encodeUUID(byte16Value(value))...), nil
+}
+
 type encodePlanUUIDCodecBinaryUUIDValuer struct{}
 
 func (encodePlanUUIDCodecBinaryUUIDValuer) Encode(value any, buf []byte) (newBuf []byte, err error) {
@@ -203,6 +255,137 @@ func (UUIDCodec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan
 		}
 	}
 
+	if targetType := reflect.TypeOf(target); targetType.Kind() == reflect.Pointer && isByte16Type(targetType.Elem()) {
+		switch format {
+		case BinaryFormatCode:
+			return scanPlanBinaryUUIDToByte16{}
+		case TextFormatCode:
+			return scanPlanTextAnyToByte16{}
+		}
+	}
+
+	return nil
+}
+
+// byte16Target returns dst, which must be a pointer to a type defined as [16]byte, as a *[16]byte.
+func byte16Target(dst any) *[16]byte {
+	return reflect.ValueOf(dst).Convert(reflect.TypeOf((*[16]byte)(nil))).Interface().(*[16]byte)
+}
+
+type scanPlanBinaryUUIDToByte16 struct{}
+
+func (scanPlanBinaryUUIDToByte16) Scan(src []byte, dst any) error {
+	if src == nil {
+		return fmt.Errorf("cannot scan NULL into %T", dst)
+	}
+
+	if len(src) != 16 {
+		return fmt.Errorf("invalid length for UUID: %v", len(src))
+	}
+
+	copy(byte16Target(dst)[:], src)
+	return nil
+}
+
+type scanPlanTextAnyToByte16 struct{}
+
+func (scanPlanTextAnyToByte16) Scan(src []byte, dst any) error {
+	if src == nil {
+		return fmt.Errorf("cannot scan NULL into %T", dst)
+	}
+
+	buf, err := parseUUID(string(src))
+	if err != nil {
+		return err
+	}
+
+	*byte16Target(dst) = buf
+	return nil
+}
+
+// byte16SliceTargetElemType returns the element type of target if it is a pointer to a slice of [16]byte or of a type
+// defined as [16]byte that UUIDCodec scans directly.
+func byte16SliceTargetElemType(target any) (reflect.Type, bool) {
+	if _, ok := target.(*[][16]byte); ok {
+		return reflect.TypeOf([16]byte{}), true
+	}
+
+	targetType := reflect.TypeOf(target)
+	if targetType == nil || targetType.Kind() != reflect.Pointer || targetType.Elem().Kind() != reflect.Slice {
+		return nil, false
+	}
+
+	elemType := targetType.Elem().Elem()
+	if !isByte16Type(elemType) {
+		return nil, false
+	}
+
+	// UUIDCodec prefers these interfaces to scanning the bytes directly.
+	elemPtrType := reflect.PointerTo(elemType)
+	if elemPtrType.Implements(reflect.TypeOf((*UUIDScanner)(nil)).Elem()) ||
+		elemPtrType.Implements(reflect.TypeOf((*TextScanner)(nil)).Elem()) {
+		return nil, false
+	}
+
+	return elemType, true
+}
+
+// scanPlanBinaryUUIDArrayToByte16Slice scans a uuid[] into a pointer to a slice of elemType, which is [16]byte or a type
+// defined as [16]byte. Multi-dimensional arrays are flattened.
+type scanPlanBinaryUUIDArrayToByte16Slice struct {
+	elemType reflect.Type
+}
+
+func (plan *scanPlanBinaryUUIDArrayToByte16Slice) Scan(src []byte, dst any) error {
+	if src == nil {
+		if dstSlice, ok := dst.(*[][16]byte); ok {
+			*dstSlice = nil
+		} else {
+			sliceValue := reflect.ValueOf(dst).Elem()
+			sliceValue.Set(reflect.Zero(sliceValue.Type()))
+		}
+		return nil
+	}
+
+	var arrayHeader arrayHeader
+	rp, err := arrayHeader.DecodeBinary(nil, src)
+	if err != nil {
+		return err
+	}
+
+	slice := make([][16]byte, cardinality(arrayHeader.Dimensions))
+	for i := range slice {
+		if len(src[rp:]) < 4 {
+			return fmt.Errorf("array too short for element %d", i)
+		}
+		elemLen := int(int32(binary.BigEndian.Uint32(src[rp:])))
+		rp += 4
+		if elemLen < 0 {
+			return fmt.Errorf("failed to scan array element %d: cannot scan NULL into *%v", i, plan.elemType)
+		}
+		if elemLen != 16 {
+			return fmt.Errorf("failed to scan array element %d: invalid length for UUID: %v", i, elemLen)
+		}
+		if len(src[rp:]) < elemLen {
+			return fmt.Errorf("array too short for element %d", i)
+		}
+
+		copy(slice[i][:], src[rp:rp+elemLen])
+		rp += elemLen
+	}
+
+	if dstSlice, ok := dst.(*[][16]byte); ok {
+		*dstSlice = slice
+		return nil
+	}
+
+	sliceValue := reflect.MakeSlice(reflect.TypeOf(dst).Elem(), len(slice), len(slice))
+	byte16PtrType := reflect.TypeOf((*[16]byte)(nil))
+	for i := range slice {
+		*sliceValue.Index(i).Addr().Convert(byte16PtrType).Interface().(*[16]byte) = slice[i]
+	}
+	reflect.ValueOf(dst).Elem().Set(sliceValue)
+
 	return nil
 }
 
@@ -2,6 +2,8 @@ package pgtype_test
 
 import (
 	"context"
+	"database/sql/driver"
+	"fmt"
 	"reflect"
 	"testing"
 
@@ -63,6 +65,132 @@ func TestUUIDCodec(t *testing.T) {
 	})
 }
 
+// googleUUID mimics github.com/google/uuid.UUID which implements fmt.Stringer, driver.Valuer, and sql.Scanner.
+type googleUUID [16]byte
+
+func (u googleUUID) String() string {
+	return "not-used"
+}
+
+func (u googleUUID) Value() (driver.Value, error) {
+	return "not-used", nil
+}
+
+func (u *googleUUID) Scan(src any) error {
+	return fmt.Errorf("not used")
+}
+
+func TestUUIDCodecByte16TypeWithoutDatabase(t *testing.T) {
+	m := pgtype.NewMap()
+	u := googleUUID{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+	buf, err := m.Encode(pgtype.UUIDOID, pgtype.TextFormatCode, u, nil)
+	require.NoError(t, err)
+	require.Equal(t, "00010203-0405-0607-0809-0a0b0c0d0e0f", string(buf))
+
+	buf, err = m.Encode(pgtype.UUIDOID, pgtype.BinaryFormatCode, u, nil)
+	require.NoError(t, err)
+	require.Equal(t, u[:], buf)
+
+	var scanned googleUUID
+	err = m.Scan(pgtype.UUIDOID, pgtype.TextFormatCode, []byte("00010203-0405-0607-0809-0a0b0c0d0e0f"), &scanned)
+	require.NoError(t, err)
+	require.Equal(t, u, scanned)
+
+	scanned = googleUUID{}
+	err = m.Scan(pgtype.UUIDOID, pgtype.BinaryFormatCode, u[:], &scanned)
+	require.NoError(t, err)
+	require.Equal(t, u, scanned)
+
+	err = m.Scan(pgtype.UUIDOID, pgtype.BinaryFormatCode, nil, &scanned)
+	require.Error(t, err)
+
+	var ptr *googleUUID
+	err = m.Scan(pgtype.UUIDOID, pgtype.BinaryFormatCode, nil, &ptr)
+	require.NoError(t, err)
+	require.Nil(t, ptr)
+
+	err = m.Scan(pgtype.UUIDOID, pgtype.BinaryFormatCode, u[:], &ptr)
+	require.NoError(t, err)
+	require.Equal(t, u, *ptr)
+}
+
+func TestMapRegisterUUIDTypeWithoutDatabase(t *testing.T) {
+	m := pgtype.NewMap()
+	u := googleUUID{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+	_, ok := m.TypeForValue(u)
+	require.False(t, ok)
+
+	require.NoError(t, m.RegisterUUIDType(googleUUID{}))
+
+	dt, ok := m.TypeForValue(u)
+	require.True(t, ok)
+	require.Equal(t, "uuid", dt.Name)
+
+	dt, ok = m.TypeForValue([]googleUUID{u})
+	require.True(t, ok)
+	require.Equal(t, "_uuid", dt.Name)
+
+	// The OID is unknown so the value is encoded according to the registered type.
+	buf, err := m.Encode(0, pgtype.TextFormatCode, u, nil)
+	require.NoError(t, err)
+	require.Equal(t, "00010203-0405-0607-0809-0a0b0c0d0e0f", string(buf))
+
+	buf, err = m.Encode(0, pgtype.TextFormatCode, []googleUUID{u}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "{00010203-0405-0607-0809-0a0b0c0d0e0f}", string(buf))
+
+	require.Error(t, m.RegisterUUIDType([16]byte{}))
+	require.Error(t, m.RegisterUUIDType("00010203-0405-0607-0809-0a0b0c0d0e0f"))
+}
+
+type googleUUIDs []googleUUID
+
+func TestUUIDArrayCodecScanByte16SliceWithoutDatabase(t *testing.T) {
+	m := pgtype.NewMap()
+	u1 := [16]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	u2 := [16]byte{15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1, 0}
+
+	buf, err := m.Encode(pgtype.UUIDArrayOID, pgtype.BinaryFormatCode, [][16]byte{u1, u2}, nil)
+	require.NoError(t, err)
+
+	var byte16s [][16]byte
+	err = m.Scan(pgtype.UUIDArrayOID, pgtype.BinaryFormatCode, buf, &byte16s)
+	require.NoError(t, err)
+	require.Equal(t, [][16]byte{u1, u2}, byte16s)
+
+	var renamed []renamedUUIDByteArray
+	err = m.Scan(pgtype.UUIDArrayOID, pgtype.BinaryFormatCode, buf, &renamed)
+	require.NoError(t, err)
+	require.Equal(t, []renamedUUIDByteArray{u1, u2}, renamed)
+
+	var scannedGoogleUUIDs googleUUIDs
+	err = m.Scan(pgtype.UUIDArrayOID, pgtype.BinaryFormatCode, buf, &scannedGoogleUUIDs)
+	require.NoError(t, err)
+	require.Equal(t, googleUUIDs{u1, u2}, scannedGoogleUUIDs)
+
+	buf, err = m.Encode(pgtype.UUIDArrayOID, pgtype.BinaryFormatCode, [][16]byte{}, nil)
+	require.NoError(t, err)
+	err = m.Scan(pgtype.UUIDArrayOID, pgtype.BinaryFormatCode, buf, &byte16s)
+	require.NoError(t, err)
+	require.NotNil(t, byte16s)
+	require.Empty(t, byte16s)
+
+	err = m.Scan(pgtype.UUIDArrayOID, pgtype.BinaryFormatCode, nil, &byte16s)
+	require.NoError(t, err)
+	require.Nil(t, byte16s)
+
+	err = m.Scan(pgtype.UUIDArrayOID, pgtype.BinaryFormatCode, nil, &renamed)
+	require.NoError(t, err)
+	require.Nil(t, renamed)
+
+	buf, err = m.Encode(pgtype.UUIDArrayOID, pgtype.BinaryFormatCode, []pgtype.UUID{{Bytes: u1, Valid: true}, {}}, nil)
+	require.NoError(t, err)
+	err = m.Scan(pgtype.UUIDArrayOID, pgtype.BinaryFormatCode, buf, &byte16s)
+	require.EqualError(t, err, "failed to scan array element 1: cannot scan NULL into *[16]uint8")
+}
+
 func TestUUID_String(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,686 @@
+package pgtype
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/internal/pgio"
+)
+
+type VectorScanner interface {
+	ScanVector(v Vector) error
+}
+
+type VectorValuer interface {
+	VectorValue() (Vector, error)
+}
+
+// Vector is the Go representation of the vector and halfvec types of the pgvector extension. The types are not built
+// into PostgreSQL so they must be registered before use. See pgx.RegisterVectorTypes.
+//
+// []float32 can be used instead of Vector when the value cannot be NULL.
+type Vector struct {
+	Vec   []float32
+	Valid bool
+}
+
+func (v *Vector) ScanVector(src Vector) error {
+	*v = src
+	return nil
+}
+
+func (v Vector) VectorValue() (Vector, error) {
+	return v, nil
+}
+
+// Scan implements the database/sql Scanner interface.
+func (v *Vector) Scan(src any) error {
+	if src == nil {
+		*v = Vector{}
+		return nil
+	}
+
+	switch src := src.(type) {
+	case string:
+		vec, err := parseVectorText(src)
+		if err != nil {
+			return err
+		}
+		*v = Vector{Vec: vec, Valid: true}
+		return nil
+	case []byte:
+		vec, err := parseVectorText(string(src))
+		if err != nil {
+			return err
+		}
+		*v = Vector{Vec: vec, Valid: true}
+		return nil
+	}
+
+	return fmt.Errorf("cannot scan %T", src)
+}
+
+// Value implements the database/sql/driver Valuer interface.
+func (v Vector) Value() (driver.Value, error) {
+	if !v.Valid {
+		return nil, nil
+	}
+
+	return string(appendVectorText(nil, v.Vec)), nil
+}
+
+// VectorCodec is the codec for the vector type of the pgvector extension. Its elements are float32.
+type VectorCodec struct{}
+
+func (VectorCodec) FormatSupported(format int16) bool {
+	return format == TextFormatCode || format == BinaryFormatCode
+}
+
+func (VectorCodec) PreferredFormat() int16 {
+	return BinaryFormatCode
+}
+
+func (VectorCodec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
+	return planEncodeVector(format, false, value)
+}
+
+func (VectorCodec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan {
+	return planScanVector(format, false, target)
+}
+
+func (c VectorCodec) DecodeDatabaseSQLValue(m *Map, oid uint32, format int16, src []byte) (driver.Value, error) {
+	return decodeDatabaseSQLValueVector(format, false, src)
+}
+
+func (c VectorCodec) DecodeValue(m *Map, oid uint32, format int16, src []byte) (any, error) {
+	return decodeValueVector(format, false, src)
+}
+
+// HalfVectorCodec is the codec for the halfvec type of the pgvector extension. Its elements are half-precision floats.
+// They are converted to and from float32 in Go. Encoding rounds to the nearest half-precision value.
+type HalfVectorCodec struct{}
+
+func (HalfVectorCodec) FormatSupported(format int16) bool {
+	return format == TextFormatCode || format == BinaryFormatCode
+}
+
+func (HalfVectorCodec) PreferredFormat() int16 {
+	return BinaryFormatCode
+}
+
+func (HalfVectorCodec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
+	return planEncodeVector(format, true, value)
+}
+
+func (HalfVectorCodec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan {
+	return planScanVector(format, true, target)
+}
+
+func (c HalfVectorCodec) DecodeDatabaseSQLValue(m *Map, oid uint32, format int16, src []byte) (driver.Value, error) {
+	return decodeDatabaseSQLValueVector(format, true, src)
+}
+
+func (c HalfVectorCodec) DecodeValue(m *Map, oid uint32, format int16, src []byte) (any, error) {
+	return decodeValueVector(format, true, src)
+}
+
+func planEncodeVector(format int16, half bool, value any) EncodePlan {
+	if format != BinaryFormatCode && format != TextFormatCode {
+		return nil
+	}
+
+	switch value.(type) {
+	case []float32, VectorValuer:
+		return &encodePlanVector{format: format, half: half}
+	}
+
+	return nil
+}
+
+type encodePlanVector struct {
+	format int16
+	half   bool
+}
+
+func (p *encodePlanVector) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	var vec []float32
+	switch value := value.(type) {
+	case []float32:
+		vec = value
+	case VectorValuer:
+		v, err := value.VectorValue()
+		if err != nil {
+			return nil, err
+		}
+		if !v.Valid {
+			return nil, nil
+		}
+		vec = v.Vec
+	}
+
+	if p.format == TextFormatCode {
+		return appendVectorText(buf, vec), nil
+	}
+
+	if len(vec) > math.MaxInt16 {
+		return nil, fmt.Errorf("vector has too many dimensions: %d", len(vec))
+	}
+
+	buf = pgio.AppendInt16(buf, int16(len(vec)))
+	buf = pgio.AppendInt16(buf, 0) // unused
+	for _, f := range vec {
+		if p.half {
+			buf = pgio.AppendUint16(buf, float32ToHalf(f))
+		} else {
+			buf = pgio.AppendUint32(buf, math.Float32bits(f))
+		}
+	}
+
+	return buf, nil
+}
+
+func planScanVector(format int16, half bool, target any) ScanPlan {
+	if format != BinaryFormatCode && format != TextFormatCode {
+		return nil
+	}
+
+	switch target.(type) {
+	case *[]float32, VectorScanner:
+		return &scanPlanVector{format: format, half: half}
+	case TextScanner:
+		if format == BinaryFormatCode {
+			return &scanPlanVector{format: format, half: half}
+		}
+	}
+
+	return nil
+}
+
+type scanPlanVector struct {
+	format int16
+	half   bool
+}
+
+func (p *scanPlanVector) Scan(src []byte, dst any) error {
+	var vec []float32
+	if src != nil {
+		var err error
+		vec, err = decodeVector(p.format, p.half, src)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch dst := dst.(type) {
+	case *[]float32:
+		*dst = vec
+		return nil
+	case VectorScanner:
+		return dst.ScanVector(Vector{Vec: vec, Valid: src != nil})
+	case TextScanner:
+		if src == nil {
+			return dst.ScanText(Text{})
+		}
+		return dst.ScanText(Text{String: string(appendVectorText(nil, vec)), Valid: true})
+	}
+
+	return fmt.Errorf("cannot scan vector into %T", dst)
+}
+
+func decodeDatabaseSQLValueVector(format int16, half bool, src []byte) (driver.Value, error) {
+	if src == nil {
+		return nil, nil
+	}
+
+	if format == TextFormatCode {
+		return string(src), nil
+	}
+
+	vec, err := decodeVector(format, half, src)
+	if err != nil {
+		return nil, err
+	}
+	return string(appendVectorText(nil, vec)), nil
+}
+
+func decodeValueVector(format int16, half bool, src []byte) (any, error) {
+	if src == nil {
+		return nil, nil
+	}
+
+	return decodeVector(format, half, src)
+}
+
+func decodeVector(format int16, half bool, src []byte) ([]float32, error) {
+	if format == TextFormatCode {
+		return parseVectorText(string(src))
+	}
+
+	if len(src) < 4 {
+		return nil, fmt.Errorf("invalid length for vector: %v", len(src))
+	}
+
+	dim := int(binary.BigEndian.Uint16(src))
+	src = src[4:]
+
+	elemSize := 4
+	if half {
+		elemSize = 2
+	}
+	if len(src) != dim*elemSize {
+		return nil, fmt.Errorf("invalid length for vector with %d dimensions: %v", dim, len(src)+4)
+	}
+
+	vec := make([]float32, dim)
+	for i := range vec {
+		if half {
+			vec[i] = halfToFloat32(binary.BigEndian.Uint16(src[i*2:]))
+		} else {
+			vec[i] = math.Float32frombits(binary.BigEndian.Uint32(src[i*4:]))
+		}
+	}
+
+	return vec, nil
+}
+
+// parseVectorText parses the text format of vector and halfvec. e.g. [1,2.5,3]
+func parseVectorText(s string) ([]float32, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '[' || s[len(s)-1] != ']' {
+		return nil, fmt.Errorf("invalid format for vector: %q", s)
+	}
+
+	s = strings.TrimSpace(s[1 : len(s)-1])
+	if s == "" {
+		return []float32{}, nil
+	}
+
+	elems := strings.Split(s, ",")
+	vec := make([]float32, len(elems))
+	for i, e := range elems {
+		f, err := strconv.ParseFloat(strings.TrimSpace(e), 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid format for vector: %q", s)
+		}
+		vec[i] = float32(f)
+	}
+
+	return vec, nil
+}
+
+func appendVectorText(buf []byte, vec []float32) []byte {
+	buf = append(buf, '[')
+	for i, f := range vec {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = strconv.AppendFloat(buf, float64(f), 'g', -1, 32)
+	}
+	return append(buf, ']')
+}
+
+// float32ToHalf converts f to the nearest IEEE 754 half-precision float. Ties are rounded to even.
+func float32ToHalf(f float32) uint16 {
+	b := math.Float32bits(f)
+	sign := uint16(b>>16) & 0x8000
+	exp := int32(b>>23&0xff) - 127 + 15
+	mant := b & 0x7fffff
+
+	switch {
+	case b&0x7fffffff == 0:
+		return sign
+	case b>>23&0xff == 0xff:
+		if mant == 0 {
+			return sign | 0x7c00 // infinity
+		}
+		return sign | 0x7e00 // NaN
+	case exp >= 0x1f:
+		return sign | 0x7c00 // overflow to infinity
+	case exp <= 0:
+		// Subnormal or underflow to zero.
+		if exp < -10 {
+			return sign
+		}
+		mant |= 0x800000
+		shift := uint32(14 - exp)
+		h := uint16(mant >> shift)
+		rem := mant & (1<<shift - 1)
+		halfway := uint32(1) << (shift - 1)
+		if rem > halfway || (rem == halfway && h&1 == 1) {
+			h++
+		}
+		return sign | h
+	default:
+		h := sign | uint16(exp)<<10 | uint16(mant>>13)
+		rem := mant & 0x1fff
+		// A carry out of the mantissa correctly increments the exponent.
+		if rem > 0x1000 || (rem == 0x1000 && h&1 == 1) {
+			h++
+		}
+		return h
+	}
+}
+
+// halfToFloat32 converts the IEEE 754 half-precision float h to a float32.
+func halfToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+
+	switch exp {
+	case 0:
+		// Zero or subnormal.
+		f := float32(mant) / (1 << 24)
+		if sign != 0 {
+			f = -f
+		}
+		return f
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	default:
+		return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+	}
+}
+
+type SparseVectorScanner interface {
+	ScanSparseVector(v SparseVector) error
+}
+
+type SparseVectorValuer interface {
+	SparseVectorValue() (SparseVector, error)
+}
+
+// SparseVector is the Go representation of the sparsevec type of the pgvector extension. Only the non-zero elements are
+// stored. Indices are zero-based and must be in ascending order. Values holds the element at the same position in
+// Indices. The type is not built into PostgreSQL so it must be registered before use. See pgx.RegisterVectorTypes.
+type SparseVector struct {
+	Dim     int32
+	Indices []int32
+	Values  []float32
+	Valid   bool
+}
+
+func (v *SparseVector) ScanSparseVector(src SparseVector) error {
+	*v = src
+	return nil
+}
+
+func (v SparseVector) SparseVectorValue() (SparseVector, error) {
+	return v, nil
+}
+
+// Scan implements the database/sql Scanner interface.
+func (v *SparseVector) Scan(src any) error {
+	if src == nil {
+		*v = SparseVector{}
+		return nil
+	}
+
+	switch src := src.(type) {
+	case string:
+		sv, err := parseSparseVectorText(src)
+		if err != nil {
+			return err
+		}
+		*v = sv
+		return nil
+	case []byte:
+		sv, err := parseSparseVectorText(string(src))
+		if err != nil {
+			return err
+		}
+		*v = sv
+		return nil
+	}
+
+	return fmt.Errorf("cannot scan %T", src)
+}
+
+// Value implements the database/sql/driver Valuer interface.
+func (v SparseVector) Value() (driver.Value, error) {
+	if !v.Valid {
+		return nil, nil
+	}
+
+	buf, err := appendSparseVectorText(nil, v)
+	if err != nil {
+		return nil, err
+	}
+	return string(buf), nil
+}
+
+// SparseVectorCodec is the codec for the sparsevec type of the pgvector extension.
+type SparseVectorCodec struct{}
+
+func (SparseVectorCodec) FormatSupported(format int16) bool {
+	return format == TextFormatCode || format == BinaryFormatCode
+}
+
+func (SparseVectorCodec) PreferredFormat() int16 {
+	return BinaryFormatCode
+}
+
+func (SparseVectorCodec) PlanEncode(m *Map, oid uint32, format int16, value any) EncodePlan {
+	if _, ok := value.(SparseVectorValuer); !ok {
+		return nil
+	}
+
+	switch format {
+	case BinaryFormatCode:
+		return encodePlanSparseVectorCodecBinary{}
+	case TextFormatCode:
+		return encodePlanSparseVectorCodecText{}
+	}
+
+	return nil
+}
+
+type encodePlanSparseVectorCodecBinary struct{}
+
+func (encodePlanSparseVectorCodecBinary) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	v, err := value.(SparseVectorValuer).SparseVectorValue()
+	if err != nil {
+		return nil, err
+	}
+
+	if !v.Valid {
+		return nil, nil
+	}
+
+	if len(v.Indices) != len(v.Values) {
+		return nil, fmt.Errorf("sparsevec has %d indices but %d values", len(v.Indices), len(v.Values))
+	}
+
+	buf = pgio.AppendInt32(buf, v.Dim)
+	buf = pgio.AppendInt32(buf, int32(len(v.Indices)))
+	buf = pgio.AppendInt32(buf, 0) // unused
+	for _, i := range v.Indices {
+		buf = pgio.AppendInt32(buf, i)
+	}
+	for _, f := range v.Values {
+		buf = pgio.AppendUint32(buf, math.Float32bits(f))
+	}
+
+	return buf, nil
+}
+
+type encodePlanSparseVectorCodecText struct{}
+
+func (encodePlanSparseVectorCodecText) Encode(value any, buf []byte) (newBuf []byte, err error) {
+	v, err := value.(SparseVectorValuer).SparseVectorValue()
+	if err != nil {
+		return nil, err
+	}
+
+	if !v.Valid {
+		return nil, nil
+	}
+
+	return appendSparseVectorText(buf, v)
+}
+
+func (SparseVectorCodec) PlanScan(m *Map, oid uint32, format int16, target any) ScanPlan {
+	if _, ok := target.(SparseVectorScanner); !ok {
+		return nil
+	}
+
+	switch format {
+	case BinaryFormatCode:
+		return scanPlanBinarySparseVectorToSparseVectorScanner{}
+	case TextFormatCode:
+		return scanPlanTextAnyToSparseVectorScanner{}
+	}
+
+	return nil
+}
+
+type scanPlanBinarySparseVectorToSparseVectorScanner struct{}
+
+func (scanPlanBinarySparseVectorToSparseVectorScanner) Scan(src []byte, dst any) error {
+	scanner := (dst).(SparseVectorScanner)
+
+	if src == nil {
+		return scanner.ScanSparseVector(SparseVector{})
+	}
+
+	v, err := decodeBinarySparseVector(src)
+	if err != nil {
+		return err
+	}
+
+	return scanner.ScanSparseVector(v)
+}
+
+type scanPlanTextAnyToSparseVectorScanner struct{}
+
+func (scanPlanTextAnyToSparseVectorScanner) Scan(src []byte, dst any) error {
+	scanner := (dst).(SparseVectorScanner)
+
+	if src == nil {
+		return scanner.ScanSparseVector(SparseVector{})
+	}
+
+	v, err := parseSparseVectorText(string(src))
+	if err != nil {
+		return err
+	}
+
+	return scanner.ScanSparseVector(v)
+}
+
+func (c SparseVectorCodec) DecodeDatabaseSQLValue(m *Map, oid uint32, format int16, src []byte) (driver.Value, error) {
+	if src == nil {
+		return nil, nil
+	}
+
+	if format == TextFormatCode {
+		return string(src), nil
+	}
+
+	v, err := decodeBinarySparseVector(src)
+	if err != nil {
+		return nil, err
+	}
+	return v.Value()
+}
+
+func (c SparseVectorCodec) DecodeValue(m *Map, oid uint32, format int16, src []byte) (any, error) {
+	if src == nil {
+		return nil, nil
+	}
+
+	var v SparseVector
+	err := codecScan(c, m, oid, format, src, &v)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func decodeBinarySparseVector(src []byte) (SparseVector, error) {
+	if len(src) < 12 {
+		return SparseVector{}, fmt.Errorf("invalid length for sparsevec: %v", len(src))
+	}
+
+	dim := int32(binary.BigEndian.Uint32(src))
+	nnz := int(binary.BigEndian.Uint32(src[4:]))
+	rp := 12
+
+	if nnz < 0 || len(src) != rp+nnz*8 {
+		return SparseVector{}, fmt.Errorf("invalid length for sparsevec with %d non-zero elements: %v", nnz, len(src))
+	}
+
+	v := SparseVector{Dim: dim, Indices: make([]int32, nnz), Values: make([]float32, nnz), Valid: true}
+	for i := range v.Indices {
+		v.Indices[i] = int32(binary.BigEndian.Uint32(src[rp:]))
+		rp += 4
+	}
+	for i := range v.Values {
+		v.Values[i] = math.Float32frombits(binary.BigEndian.Uint32(src[rp:]))
+		rp += 4
+	}
+
+	return v, nil
+}
+
+// parseSparseVectorText parses the text format of sparsevec. e.g. {1:1.5,3:2}/5. Indices in the text format are one-based.
+func parseSparseVectorText(s string) (SparseVector, error) {
+	elems, dim, found := strings.Cut(strings.TrimSpace(s), "/")
+	if !found || len(elems) < 2 || elems[0] != '{' || elems[len(elems)-1] != '}' {
+		return SparseVector{}, fmt.Errorf("invalid format for sparsevec: %q", s)
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSpace(dim), 10, 32)
+	if err != nil {
+		return SparseVector{}, fmt.Errorf("invalid format for sparsevec: %q", s)
+	}
+	v := SparseVector{Dim: int32(n), Indices: []int32{}, Values: []float32{}, Valid: true}
+
+	elems = strings.TrimSpace(elems[1 : len(elems)-1])
+	if elems == "" {
+		return v, nil
+	}
+
+	for _, e := range strings.Split(elems, ",") {
+		idx, val, found := strings.Cut(e, ":")
+		if !found {
+			return SparseVector{}, fmt.Errorf("invalid format for sparsevec: %q", s)
+		}
+		i, err := strconv.ParseInt(strings.TrimSpace(idx), 10, 32)
+		if err != nil {
+			return SparseVector{}, fmt.Errorf("invalid format for sparsevec: %q", s)
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(val), 32)
+		if err != nil {
+			return SparseVector{}, fmt.Errorf("invalid format for sparsevec: %q", s)
+		}
+		v.Indices = append(v.Indices, int32(i-1))
+		v.Values = append(v.Values, float32(f))
+	}
+
+	return v, nil
+}
+
+func appendSparseVectorText(buf []byte, v SparseVector) ([]byte, error) {
+	if len(v.Indices) != len(v.Values) {
+		return nil, fmt.Errorf("sparsevec has %d indices but %d values", len(v.Indices), len(v.Values))
+	}
+
+	buf = append(buf, '{')
+	for i := range v.Indices {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = strconv.AppendInt(buf, int64(v.Indices[i])+1, 10)
+		buf = append(buf, ':')
+		buf = strconv.AppendFloat(buf, float64(v.Values[i]), 'g', -1, 32)
+	}
+	buf = append(buf, '}', '/')
+	buf = strconv.AppendInt(buf, int64(v.Dim), 10)
+
+	return buf, nil
+}
@@ -0,0 +1,115 @@
+package pgtype_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func newVectorTestMap() *pgtype.Map {
+	m := pgtype.NewMap()
+	m.RegisterType(&pgtype.Type{Name: "vector", OID: 100001, Codec: pgtype.VectorCodec{}})
+	m.RegisterType(&pgtype.Type{Name: "halfvec", OID: 100002, Codec: pgtype.HalfVectorCodec{}})
+	m.RegisterType(&pgtype.Type{Name: "sparsevec", OID: 100003, Codec: pgtype.SparseVectorCodec{}})
+	return m
+}
+
+func TestVectorCodecWithoutDatabase(t *testing.T) {
+	m := newVectorTestMap()
+
+	for _, oid := range []uint32{100001, 100002} {
+		for _, format := range []int16{pgtype.TextFormatCode, pgtype.BinaryFormatCode} {
+			vec := []float32{1, -2.5, 0, 65504, 0.125}
+
+			buf, err := m.Encode(oid, format, vec, nil)
+			require.NoError(t, err)
+
+			var got []float32
+			err = m.Scan(oid, format, buf, &got)
+			require.NoError(t, err)
+			require.Equal(t, vec, got)
+
+			buf, err = m.Encode(oid, format, pgtype.Vector{Vec: vec, Valid: true}, nil)
+			require.NoError(t, err)
+
+			var v pgtype.Vector
+			err = m.Scan(oid, format, buf, &v)
+			require.NoError(t, err)
+			require.Equal(t, pgtype.Vector{Vec: vec, Valid: true}, v)
+
+			var s string
+			err = m.Scan(oid, format, buf, &s)
+			require.NoError(t, err)
+			require.Equal(t, "[1,-2.5,0,65504,0.125]", s)
+
+			buf, err = m.Encode(oid, format, pgtype.Vector{}, nil)
+			require.NoError(t, err)
+			require.Nil(t, buf)
+
+			err = m.Scan(oid, format, nil, &v)
+			require.NoError(t, err)
+			require.False(t, v.Valid)
+		}
+	}
+}
+
+func TestHalfVectorCodecRoundingWithoutDatabase(t *testing.T) {
+	m := newVectorTestMap()
+
+	vec := []float32{
+		1.0 / 3,               // rounded
+		1e-7,                  // subnormal
+		1e-9,                  // underflows to zero
+		70000,                 // overflows to infinity
+		float32(math.Inf(-1)), // infinity
+		float32(math.Copysign(0, -1)),
+	}
+
+	buf, err := m.Encode(100002, pgtype.BinaryFormatCode, vec, nil)
+	require.NoError(t, err)
+
+	var got []float32
+	err = m.Scan(100002, pgtype.BinaryFormatCode, buf, &got)
+	require.NoError(t, err)
+
+	require.Equal(t, float32(0.33325195), got[0])
+	require.InDelta(t, 1e-7, got[1], 6e-8)
+	require.NotZero(t, got[1])
+	require.Zero(t, got[2])
+	require.True(t, math.IsInf(float64(got[3]), 1))
+	require.True(t, math.IsInf(float64(got[4]), -1))
+	require.True(t, math.Signbit(float64(got[5])))
+}
+
+func TestSparseVectorCodecWithoutDatabase(t *testing.T) {
+	m := newVectorTestMap()
+
+	for _, format := range []int16{pgtype.TextFormatCode, pgtype.BinaryFormatCode} {
+		sv := pgtype.SparseVector{Dim: 5, Indices: []int32{0, 3}, Values: []float32{1.5, -2}, Valid: true}
+
+		buf, err := m.Encode(100003, format, sv, nil)
+		require.NoError(t, err)
+
+		var got pgtype.SparseVector
+		err = m.Scan(100003, format, buf, &got)
+		require.NoError(t, err)
+		require.Equal(t, sv, got)
+
+		value, err := pgtype.SparseVectorCodec{}.DecodeDatabaseSQLValue(m, 100003, format, buf)
+		require.NoError(t, err)
+		require.Equal(t, "{1:1.5,4:-2}/5", value)
+
+		empty := pgtype.SparseVector{Dim: 3, Indices: []int32{}, Values: []float32{}, Valid: true}
+		buf, err = m.Encode(100003, format, empty, nil)
+		require.NoError(t, err)
+		err = m.Scan(100003, format, buf, &got)
+		require.NoError(t, err)
+		require.Equal(t, empty, got)
+
+		err = m.Scan(100003, format, nil, &got)
+		require.NoError(t, err)
+		require.False(t, got.Valid)
+	}
+}
@@ -0,0 +1,302 @@
+// Package pgxexport streams query results to CSV or NDJSON.
+//
+// Values are written using the PostgreSQL text format of each column. Columns received in the text format are copied
+// to the output without conversion to a Go type. QueryCSV and QueryNDJSON request the text format for all columns.
+// Columns of Rows passed directly to WriteCSV or WriteNDJSON that were received in the binary format are decoded and
+// re-encoded in the text format with the type map of the connection. This may format some values differently than
+// PostgreSQL would, e.g. timestamptz values are written in UTC.
+//
+// Typical usage in an HTTP handler:
+//
+//	w.Header().Set("Content-Type", "text/csv")
+//	_, err := pgxexport.QueryCSV(ctx, pool, w, &pgxexport.CSVOptions{Header: true}, "select * from widgets")
+package pgxexport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Querier is the interface required to execute a query. It is implemented by *pgx.Conn, pgx.Tx, and *pgxpool.Pool.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// CSVOptions configures the CSV output.
+type CSVOptions struct {
+	// Header writes a header row of column names.
+	Header bool
+
+	// Delimiter separates fields. If 0, ',' is used.
+	Delimiter byte
+
+	// Null is written for NULL values. Defaults to the empty string. Non-NULL values equal to Null are quoted to
+	// distinguish them from NULL. This matches the behavior of the PostgreSQL COPY command.
+	Null string
+}
+
+// QueryCSV executes sql with args on db and writes the results to w as CSV. It returns the number of rows written.
+// options may be nil.
+func QueryCSV(ctx context.Context, db Querier, w io.Writer, options *CSVOptions, sql string, args ...any) (int64, error) {
+	rows, err := db.Query(ctx, sql, textFormatArgs(args)...)
+	if err != nil {
+		return 0, err
+	}
+
+	return WriteCSV(w, rows, options)
+}
+
+// WriteCSV writes rows to w as CSV. It returns the number of rows written. rows is closed before WriteCSV returns.
+// options may be nil.
+func WriteCSV(w io.Writer, rows pgx.Rows, options *CSVOptions) (int64, error) {
+	defer rows.Close()
+
+	if options == nil {
+		options = &CSVOptions{}
+	}
+	delimiter := options.Delimiter
+	if delimiter == 0 {
+		delimiter = ','
+	}
+
+	e := newEncoder(rows)
+	fieldDescriptions := rows.FieldDescriptions()
+	var buf []byte
+
+	if options.Header {
+		for i, fd := range fieldDescriptions {
+			if i > 0 {
+				buf = append(buf, delimiter)
+			}
+			buf = appendCSVField(buf, []byte(fd.Name), delimiter, options.Null)
+		}
+		buf = append(buf, '\n')
+		_, err := w.Write(buf)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var rowCount int64
+	for rows.Next() {
+		buf = buf[:0]
+		for i, raw := range rows.RawValues() {
+			if i > 0 {
+				buf = append(buf, delimiter)
+			}
+
+			if raw == nil {
+				buf = append(buf, options.Null...)
+				continue
+			}
+
+			value, err := e.textValue(fieldDescriptions[i], raw)
+			if err != nil {
+				return rowCount, err
+			}
+			buf = appendCSVField(buf, value, delimiter, options.Null)
+		}
+		buf = append(buf, '\n')
+
+		_, err := w.Write(buf)
+		if err != nil {
+			return rowCount, err
+		}
+		rowCount++
+	}
+
+	return rowCount, rows.Err()
+}
+
+func appendCSVField(buf, value []byte, delimiter byte, null string) []byte {
+	quote := string(value) == null
+	for _, b := range value {
+		if b == delimiter || b == '"' || b == '\r' || b == '\n' {
+			quote = true
+			break
+		}
+	}
+
+	if !quote {
+		return append(buf, value...)
+	}
+
+	buf = append(buf, '"')
+	for _, b := range value {
+		if b == '"' {
+			buf = append(buf, '"')
+		}
+		buf = append(buf, b)
+	}
+	return append(buf, '"')
+}
+
+// QueryNDJSON executes sql with args on db and writes the results to w as newline delimited JSON. It returns the number
+// of rows written. See WriteNDJSON for the format.
+func QueryNDJSON(ctx context.Context, db Querier, w io.Writer, sql string, args ...any) (int64, error) {
+	rows, err := db.Query(ctx, sql, textFormatArgs(args)...)
+	if err != nil {
+		return 0, err
+	}
+
+	return WriteNDJSON(w, rows)
+}
+
+// WriteNDJSON writes rows to w as newline delimited JSON. Each row is written as a JSON object with a property for each
+// column. It returns the number of rows written. rows is closed before WriteNDJSON returns.
+//
+// NULL is written as null. bool columns are written as JSON booleans. Integer, float, and numeric columns are written
+// as JSON numbers except for NaN and infinite values which are written as strings. json and jsonb columns are written
+// as is. All other values are written as JSON strings of their text format.
+func WriteNDJSON(w io.Writer, rows pgx.Rows) (int64, error) {
+	defer rows.Close()
+
+	e := newEncoder(rows)
+	fieldDescriptions := rows.FieldDescriptions()
+	var buf []byte
+
+	var rowCount int64
+	for rows.Next() {
+		buf = append(buf[:0], '{')
+		for i, raw := range rows.RawValues() {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendJSONString(buf, []byte(fieldDescriptions[i].Name))
+			buf = append(buf, ':')
+
+			if raw == nil {
+				buf = append(buf, "null"...)
+				continue
+			}
+
+			value, err := e.textValue(fieldDescriptions[i], raw)
+			if err != nil {
+				return rowCount, err
+			}
+			buf = appendJSONValue(buf, fieldDescriptions[i].DataTypeOID, value)
+		}
+		buf = append(buf, '}', '\n')
+
+		_, err := w.Write(buf)
+		if err != nil {
+			return rowCount, err
+		}
+		rowCount++
+	}
+
+	return rowCount, rows.Err()
+}
+
+func appendJSONValue(buf []byte, oid uint32, value []byte) []byte {
+	switch oid {
+	case pgtype.BoolOID:
+		switch string(value) {
+		case "t":
+			return append(buf, "true"...)
+		case "f":
+			return append(buf, "false"...)
+		}
+	case pgtype.Int2OID, pgtype.Int4OID, pgtype.Int8OID, pgtype.OIDOID, pgtype.Float4OID, pgtype.Float8OID, pgtype.NumericOID:
+		switch string(value) {
+		case "NaN", "Infinity", "-Infinity":
+		default:
+			return append(buf, value...)
+		}
+	case pgtype.JSONOID, pgtype.JSONBOID:
+		return append(buf, value...)
+	}
+
+	return appendJSONString(buf, value)
+}
+
+func appendJSONString(buf, s []byte) []byte {
+	const hex = "0123456789abcdef"
+
+	buf = append(buf, '"')
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			switch {
+			case b == '"' || b == '\\':
+				buf = append(buf, '\\', b)
+			case b == '\n':
+				buf = append(buf, '\\', 'n')
+			case b == '\r':
+				buf = append(buf, '\\', 'r')
+			case b == '\t':
+				buf = append(buf, '\\', 't')
+			case b < 0x20:
+				buf = append(buf, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xF])
+			default:
+				buf = append(buf, b)
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRune(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, `�`...)
+		} else {
+			buf = append(buf, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(buf, '"')
+}
+
+// textFormatArgs returns args with an option prepended that requests the text format for all result columns.
+func textFormatArgs(args []any) []any {
+	newArgs := make([]any, 0, len(args)+1)
+	newArgs = append(newArgs, pgx.QueryResultFormats{pgx.TextFormatCode})
+	return append(newArgs, args...)
+}
+
+// encoder converts values to the text format.
+type encoder struct {
+	m   *pgtype.Map
+	buf []byte
+}
+
+func newEncoder(rows pgx.Rows) *encoder {
+	e := &encoder{}
+	if conn := rows.Conn(); conn != nil {
+		e.m = conn.TypeMap()
+	}
+	return e
+}
+
+// textValue returns raw in the text format. The returned slice is only valid until the next call to textValue.
+func (e *encoder) textValue(fd pgconn.FieldDescription, raw []byte) ([]byte, error) {
+	if fd.Format == pgx.TextFormatCode {
+		return raw, nil
+	}
+
+	if e.m == nil {
+		e.m = pgtype.NewMap()
+	}
+
+	t, ok := e.m.TypeForOID(fd.DataTypeOID)
+	if !ok {
+		return nil, fmt.Errorf("column %s: cannot convert binary value of unknown type (OID %d) to text", fd.Name, fd.DataTypeOID)
+	}
+
+	value, err := t.Codec.DecodeValue(e.m, fd.DataTypeOID, pgx.BinaryFormatCode, raw)
+	if err != nil {
+		return nil, fmt.Errorf("column %s: %w", fd.Name, err)
+	}
+
+	e.buf, err = e.m.Encode(fd.DataTypeOID, pgx.TextFormatCode, value, e.buf[:0])
+	if err != nil {
+		return nil, fmt.Errorf("column %s: %w", fd.Name, err)
+	}
+
+	return e.buf, nil
+}
@@ -0,0 +1,176 @@
+package pgxexport_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxexport"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRows is a pgx.Rows that returns fixed raw values.
+type fakeRows struct {
+	fields []pgconn.FieldDescription
+	values [][][]byte
+	err    error
+
+	row    int
+	closed bool
+}
+
+func (r *fakeRows) Close()                                       { r.closed = true }
+func (r *fakeRows) Err() error                                   { return r.err }
+func (r *fakeRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription { return r.fields }
+func (r *fakeRows) Scan(dest ...any) error                       { return errors.New("not implemented") }
+func (r *fakeRows) Values() ([]any, error)                       { return nil, errors.New("not implemented") }
+func (r *fakeRows) RawValues() [][]byte                          { return r.values[r.row-1] }
+func (r *fakeRows) Conn() *pgx.Conn                              { return nil }
+func (r *fakeRows) Next() bool {
+	if r.closed || r.row >= len(r.values) {
+		r.closed = true
+		return false
+	}
+	r.row++
+	return true
+}
+
+func TestWriteCSV(t *testing.T) {
+	rows := &fakeRows{
+		fields: []pgconn.FieldDescription{
+			{Name: "id", DataTypeOID: pgtype.Int4OID, Format: pgtype.TextFormatCode},
+			{Name: "name", DataTypeOID: pgtype.TextOID, Format: pgtype.TextFormatCode},
+			{Name: "n", DataTypeOID: pgtype.Int8OID, Format: pgtype.BinaryFormatCode},
+		},
+		values: [][][]byte{
+			{[]byte("1"), []byte("plain"), {0, 0, 0, 0, 0, 0, 0, 42}},
+			{[]byte("2"), []byte(`a,"b"`), nil},
+			{[]byte("3"), []byte(""), {0, 0, 0, 0, 0, 0, 0, 0}},
+			{[]byte("4"), []byte("line\nbreak"), {0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		},
+	}
+
+	var buf bytes.Buffer
+	n, err := pgxexport.WriteCSV(&buf, rows, &pgxexport.CSVOptions{Header: true})
+	require.NoError(t, err)
+	require.EqualValues(t, 4, n)
+	require.True(t, rows.closed)
+	require.Equal(t, "id,name,n\n1,plain,42\n2,\"a,\"\"b\"\"\",\n3,\"\",0\n4,\"line\nbreak\",-1\n", buf.String())
+}
+
+func TestWriteCSVOptions(t *testing.T) {
+	rows := &fakeRows{
+		fields: []pgconn.FieldDescription{
+			{Name: "a", DataTypeOID: pgtype.TextOID, Format: pgtype.TextFormatCode},
+			{Name: "b", DataTypeOID: pgtype.TextOID, Format: pgtype.TextFormatCode},
+		},
+		values: [][][]byte{
+			{[]byte("x,y"), nil},
+			{[]byte(`\N`), []byte("x\ty")},
+		},
+	}
+
+	var buf bytes.Buffer
+	n, err := pgxexport.WriteCSV(&buf, rows, &pgxexport.CSVOptions{Delimiter: '\t', Null: `\N`})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, n)
+	require.Equal(t, "x,y\t\\N\n\"\\N\"\t\"x\ty\"\n", buf.String())
+}
+
+func TestWriteCSVRowsError(t *testing.T) {
+	rows := &fakeRows{
+		fields: []pgconn.FieldDescription{{Name: "a", DataTypeOID: pgtype.TextOID, Format: pgtype.TextFormatCode}},
+		values: [][][]byte{{[]byte("x")}},
+		err:    errors.New("boom"),
+	}
+
+	var buf bytes.Buffer
+	n, err := pgxexport.WriteCSV(&buf, rows, nil)
+	require.EqualError(t, err, "boom")
+	require.EqualValues(t, 1, n)
+}
+
+func TestWriteCSVUnknownBinaryType(t *testing.T) {
+	rows := &fakeRows{
+		fields: []pgconn.FieldDescription{{Name: "a", DataTypeOID: 999999, Format: pgtype.BinaryFormatCode}},
+		values: [][][]byte{{[]byte("x")}},
+	}
+
+	var buf bytes.Buffer
+	_, err := pgxexport.WriteCSV(&buf, rows, nil)
+	require.ErrorContains(t, err, "column a")
+	require.True(t, rows.closed)
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	rows := &fakeRows{
+		fields: []pgconn.FieldDescription{
+			{Name: "id", DataTypeOID: pgtype.Int4OID, Format: pgtype.TextFormatCode},
+			{Name: "ok", DataTypeOID: pgtype.BoolOID, Format: pgtype.TextFormatCode},
+			{Name: "f", DataTypeOID: pgtype.Float8OID, Format: pgtype.TextFormatCode},
+			{Name: "doc", DataTypeOID: pgtype.JSONBOID, Format: pgtype.TextFormatCode},
+			{Name: `na"me`, DataTypeOID: pgtype.TextOID, Format: pgtype.TextFormatCode},
+			{Name: "b", DataTypeOID: pgtype.BoolOID, Format: pgtype.BinaryFormatCode},
+		},
+		values: [][][]byte{
+			{[]byte("1"), []byte("t"), []byte("1.5"), []byte(`{"a": [1, 2]}`), []byte("tab\there \"q\" é"), {1}},
+			{[]byte("2"), []byte("f"), []byte("NaN"), nil, []byte("\x01"), {0}},
+		},
+	}
+
+	var buf bytes.Buffer
+	n, err := pgxexport.WriteNDJSON(&buf, rows)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, n)
+	require.True(t, rows.closed)
+	require.Equal(t,
+		`{"id":1,"ok":true,"f":1.5,"doc":{"a": [1, 2]},"na\"me":"tab\there \"q\" é","b":true}`+"\n"+
+			`{"id":2,"ok":false,"f":"NaN","doc":null,"na\"me":"\u0001","b":false}`+"\n",
+		buf.String(),
+	)
+}
+
+func TestQueryCSV(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	conn, err := pgx.Connect(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer conn.Close(ctx)
+
+	var buf bytes.Buffer
+	n, err := pgxexport.QueryCSV(ctx, conn, &buf, &pgxexport.CSVOptions{Header: true},
+		`select n, 'row ' || n as name, case when n = 2 then null else n * 1.5 end as amount, '2024-01-02'::date as d
+		from generate_series(1, $1::int) n`, 3)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, n)
+	require.Equal(t, "n,name,amount,d\n1,row 1,1.5,2024-01-02\n2,row 2,,2024-01-02\n3,row 3,4.5,2024-01-02\n", buf.String())
+}
+
+func TestQueryNDJSON(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	conn, err := pgx.Connect(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer conn.Close(ctx)
+
+	for _, mode := range []pgx.QueryExecMode{pgx.QueryExecModeCacheStatement, pgx.QueryExecModeExec, pgx.QueryExecModeSimpleProtocol} {
+		t.Run(mode.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := pgxexport.QueryNDJSON(ctx, conn, &buf,
+				`select 1::int4 as id, true as ok, 'a"b' as s, '{"x":1}'::jsonb as j, null::text as nothing`, mode)
+			require.NoError(t, err)
+			require.EqualValues(t, 1, n)
+			require.JSONEq(t, `{"id":1,"ok":true,"s":"a\"b","j":{"x":1},"nothing":null}`, buf.String())
+			require.True(t, bytes.HasSuffix(buf.Bytes(), []byte("\n")))
+		})
+	}
+}
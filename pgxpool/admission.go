@@ -0,0 +1,65 @@
+package pgxpool
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// AdmissionController decides whether a query may be sent to the database. It allows protection mechanisms such as
+// per-tenant query rate budgets to be enforced by the pool. Admit is called by the Pool methods that execute queries
+// (Exec, Query, QueryRow, SendBatch, BeginTx, and CopyFrom) before a connection is acquired.
+//
+// Admit may block to delay the query. It must return nil to allow the query or an error to reject it. Admit must be
+// safe for concurrent use.
+type AdmissionController interface {
+	Admit(ctx context.Context, pool *Pool, data AdmissionData) error
+}
+
+// AdmissionData describes the operation an AdmissionController is asked to admit.
+type AdmissionData struct {
+	// SQL is the query to be executed. It is empty for SendBatch, BeginTx, and CopyFrom.
+	SQL string
+
+	// Batch is the batch to be sent. It is only set for SendBatch.
+	Batch *pgx.Batch
+
+	// Stat is the state of the pool at the time of the call.
+	Stat *Stat
+}
+
+// AdmissionFunc is an adapter to allow the use of an ordinary function as an AdmissionController.
+type AdmissionFunc func(ctx context.Context, pool *Pool, data AdmissionData) error
+
+// Admit calls f(ctx, pool, data).
+func (f AdmissionFunc) Admit(ctx context.Context, pool *Pool, data AdmissionData) error {
+	return f(ctx, pool, data)
+}
+
+// AdmissionRejectedError is returned when an AdmissionController rejects a query. Err is the error returned by the
+// AdmissionController.
+type AdmissionRejectedError struct {
+	Err error
+}
+
+func (e *AdmissionRejectedError) Error() string {
+	return fmt.Sprintf("query rejected by admission controller: %v", e.Err)
+}
+
+func (e *AdmissionRejectedError) Unwrap() error {
+	return e.Err
+}
+
+func (p *Pool) admit(ctx context.Context, sql string, batch *pgx.Batch) error {
+	if p.admissionController == nil {
+		return nil
+	}
+
+	err := p.admissionController.Admit(ctx, p, AdmissionData{SQL: sql, Batch: batch, Stat: p.Stat()})
+	if err != nil {
+		return &AdmissionRejectedError{Err: err}
+	}
+
+	return nil
+}
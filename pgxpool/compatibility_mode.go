@@ -0,0 +1,105 @@
+package pgxpool
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CompatibilityMode adapts the pool to a connection pooler such as PgBouncer between the pool and the PostgreSQL
+// server.
+type CompatibilityMode int
+
+const (
+	// CompatibilityModeDefault is for a pool that connects directly to PostgreSQL or to a pooler in session pooling mode.
+	CompatibilityModeDefault CompatibilityMode = iota
+
+	// CompatibilityModeTransactionPooler is for a pool that connects to a pooler in transaction pooling mode such as
+	// PgBouncer with pool_mode=transaction. Such a pooler may run consecutive transactions of a connection on different
+	// server connections, so any session state is unreliable. In this mode:
+	//
+	//   - The statement cache is disabled and a DefaultQueryExecMode of QueryExecModeCacheStatement is changed to
+	//     QueryExecModeExec. This prevents "prepared statement does not exist" errors. Other query exec modes are
+	//     compatible and are not changed. Statements prepared explicitly with Prepare are still the responsibility of the
+	//     application.
+	//   - The statement_timeout of UsageClassConfig.StatementTimeout is not set, as a session level SET would apply to
+	//     whichever client the pooler assigns the server connection to next.
+	//   - Each new connection must report client_encoding=UTF8 and standard_conforming_strings=on. These are required by
+	//     QueryExecModeSimpleProtocol, and connections from a pooler that does not track them may not have them.
+	//   - Features that depend on session state are rejected by NewWithConfig. These are DDLInvalidationChannel, which
+	//     relies on LISTEN, and IdleTrimTime.
+	//
+	// The changes to the pgx.ConnConfig are made before BeforeConnect is called, so BeforeConnect can override them.
+	CompatibilityModeTransactionPooler
+)
+
+func (m CompatibilityMode) String() string {
+	switch m {
+	case CompatibilityModeDefault:
+		return "default"
+	case CompatibilityModeTransactionPooler:
+		return "transaction_pooler"
+	default:
+		return fmt.Sprintf("CompatibilityMode(%d)", int(m))
+	}
+}
+
+func parseCompatibilityMode(s string) (CompatibilityMode, error) {
+	switch s {
+	case "default":
+		return CompatibilityModeDefault, nil
+	case "transaction_pooler":
+		return CompatibilityModeTransactionPooler, nil
+	default:
+		return 0, fmt.Errorf("invalid compatibility mode: %s", s)
+	}
+}
+
+// validateCompatibilityMode returns an error if config enables features that are incompatible with its
+// CompatibilityMode.
+func validateCompatibilityMode(config *Config) error {
+	switch config.CompatibilityMode {
+	case CompatibilityModeDefault:
+		return nil
+	case CompatibilityModeTransactionPooler:
+		if config.DDLInvalidationChannel != "" {
+			return errors.New("DDLInvalidationChannel is not supported with CompatibilityModeTransactionPooler")
+		}
+		if config.IdleTrimTime > 0 {
+			return errors.New("IdleTrimTime is not supported with CompatibilityModeTransactionPooler")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid CompatibilityMode: %v", config.CompatibilityMode)
+	}
+}
+
+// applyCompatibilityMode changes connConfig as required by mode.
+func applyCompatibilityMode(mode CompatibilityMode, connConfig *pgx.ConnConfig) {
+	if mode != CompatibilityModeTransactionPooler {
+		return
+	}
+
+	connConfig.StatementCacheCapacity = 0
+	if connConfig.DefaultQueryExecMode == pgx.QueryExecModeCacheStatement {
+		connConfig.DefaultQueryExecMode = pgx.QueryExecModeExec
+	}
+}
+
+// checkCompatibilityMode returns an error if the parameter statuses reported by conn are not compatible with mode.
+func checkCompatibilityMode(mode CompatibilityMode, conn *pgx.Conn) error {
+	if mode != CompatibilityModeTransactionPooler {
+		return nil
+	}
+
+	pgConn := conn.PgConn()
+	if s := pgConn.ParameterStatus("client_encoding"); s != "UTF8" {
+		return fmt.Errorf("CompatibilityModeTransactionPooler requires client_encoding=UTF8 but it is %q", s)
+	}
+	if s := pgConn.ParameterStatus("standard_conforming_strings"); s != "on" {
+		return fmt.Errorf("CompatibilityModeTransactionPooler requires standard_conforming_strings=on but it is %q", s)
+	}
+
+	return nil
+}
@@ -13,6 +13,9 @@ import (
 type Conn struct {
 	res *puddle.Resource[*connResource]
 	p   *Pool
+
+	usageClass     *usageClass
+	statementSlots *statementSlots
 }
 
 // Release returns c to the pool it was acquired from. Once Release has been called, other methods must not be called.
@@ -24,14 +27,18 @@ func (c *Conn) Release() {
 
 	conn := c.Conn()
 	res := c.res
+	c.p.leakDetector.untrack(c)
 	c.res = nil
+	c.usageClass.releaseSlot()
+	c.statementSlots.release()
 
 	if c.p.releaseTracer != nil {
 		c.p.releaseTracer.TraceRelease(c.p, TraceReleaseData{Conn: conn})
 	}
 
 	if conn.IsClosed() || conn.PgConn().IsBusy() || conn.PgConn().TxStatus() != 'I' {
-		res.Destroy()
+		c.p.destroy(res, ConnDestroyUnusableOnRelease)
+		c.p.connLimit.release(1)
 		// Signal to the health check to run since we just destroyed a connections
 		// and we might be below minConns now
 		c.p.triggerHealthCheck()
@@ -43,7 +50,8 @@ func (c *Conn) Release() {
 	// so we also check the lifetime here and force a health check
 	if c.p.isExpired(res) {
 		atomic.AddInt64(&c.p.lifetimeDestroyCount, 1)
-		res.Destroy()
+		c.p.destroy(res, ConnDestroyMaxLifetime)
+		c.p.connLimit.release(1)
 		// Signal to the health check to run since we just destroyed a connections
 		// and we might be below minConns now
 		c.p.triggerHealthCheck()
@@ -52,14 +60,17 @@ func (c *Conn) Release() {
 
 	if c.p.afterRelease == nil {
 		res.Release()
+		c.p.connLimit.release(1)
 		return
 	}
 
 	go func() {
 		if c.p.afterRelease(conn) {
 			res.Release()
+			c.p.connLimit.release(1)
 		} else {
-			res.Destroy()
+			c.p.destroy(res, ConnDestroyAfterRelease)
+			c.p.connLimit.release(1)
 			// Signal to the health check to run since we just destroyed a connections
 			// and we might be below minConns now
 			c.p.triggerHealthCheck()
@@ -76,27 +87,31 @@ func (c *Conn) Hijack() *pgx.Conn {
 
 	conn := c.Conn()
 	res := c.res
+	c.p.leakDetector.untrack(c)
 	c.res = nil
+	c.usageClass.releaseSlot()
+	c.statementSlots.release()
 
 	res.Hijack()
+	c.p.connLimit.release(1)
 
 	return conn
 }
 
 func (c *Conn) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
-	return c.Conn().Exec(ctx, sql, arguments...)
+	return c.Conn().Exec(ctx, sql, c.p.withQueryRewriter(arguments)...)
 }
 
 func (c *Conn) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
-	return c.Conn().Query(ctx, sql, args...)
+	return c.Conn().Query(ctx, sql, c.p.withQueryRewriter(args)...)
 }
 
 func (c *Conn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
-	return c.Conn().QueryRow(ctx, sql, args...)
+	return c.Conn().QueryRow(ctx, sql, c.p.withQueryRewriter(args)...)
 }
 
 func (c *Conn) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
-	return c.Conn().SendBatch(ctx, b)
+	return c.Conn().SendBatch(ctx, c.p.batchWithQueryRewriter(b))
 }
 
 func (c *Conn) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
@@ -117,6 +132,10 @@ func (c *Conn) Ping(ctx context.Context) error {
 	return c.Conn().Ping(ctx)
 }
 
+func (c *Conn) PingLight(ctx context.Context) error {
+	return c.Conn().PingLight(ctx)
+}
+
 func (c *Conn) Conn() *pgx.Conn {
 	return c.connResource().conn
 }
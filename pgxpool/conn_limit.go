@@ -0,0 +1,201 @@
+package pgxpool
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// connLimit limits the number of connections that are acquired or being established. A puddle.Pool cannot be resized,
+// so the Pool creates it with the largest possible size and connLimit enforces MaxConns instead. A token must be held
+// for every resource acquired from the puddle.Pool and for every resource it is constructing. As new resources are only
+// constructed when there are no idle resources, this also limits the total number of connections. A construct started
+// by Acquire uses the token of the Acquire. See constructToken.
+//
+// Waiters are served in FIFO order.
+type connLimit struct {
+	mux     sync.Mutex
+	limit   int32
+	held    int32
+	closed  bool
+	waiters list.List // of chan bool
+}
+
+func newConnLimit(limit int32) *connLimit {
+	return &connLimit{limit: limit}
+}
+
+// acquire waits for a token. It reports whether it had to wait. It returns ErrPoolClosed if close is called before a
+// token is granted.
+func (l *connLimit) acquire(ctx context.Context) (waited bool, err error) {
+	l.mux.Lock()
+	if l.closed {
+		l.mux.Unlock()
+		return false, ErrPoolClosed
+	}
+	if l.held < l.limit && l.waiters.Len() == 0 {
+		l.held++
+		l.mux.Unlock()
+		return false, nil
+	}
+
+	// ready receives true when a token is granted and false when l is closed.
+	ready := make(chan bool, 1)
+	elem := l.waiters.PushBack(ready)
+	l.mux.Unlock()
+
+	select {
+	case granted := <-ready:
+		if !granted {
+			return true, ErrPoolClosed
+		}
+		return true, nil
+	case <-ctx.Done():
+		l.mux.Lock()
+		select {
+		case granted := <-ready:
+			if granted {
+				// The token was granted after ctx was canceled. Give it to the next waiter.
+				l.held--
+			}
+		default:
+			l.waiters.Remove(elem)
+		}
+		l.notifyWaiters()
+		l.mux.Unlock()
+		return true, ctx.Err()
+	}
+}
+
+// tryAcquire takes a token if one is available without waiting.
+func (l *connLimit) tryAcquire() bool {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	if !l.closed && l.held < l.limit && l.waiters.Len() == 0 {
+		l.held++
+		return true
+	}
+	return false
+}
+
+// add takes n tokens regardless of the limit. It is used for connections that already exist.
+func (l *connLimit) add(n int32) {
+	l.mux.Lock()
+	l.held += n
+	l.mux.Unlock()
+}
+
+// release returns n tokens.
+func (l *connLimit) release(n int32) {
+	l.mux.Lock()
+	l.held -= n
+	l.notifyWaiters()
+	l.mux.Unlock()
+}
+
+// setLimit changes the limit. Waiters are served immediately if the limit grows. If it shrinks below the number of held
+// tokens, new tokens are not granted until enough tokens are released.
+func (l *connLimit) setLimit(limit int32) {
+	l.mux.Lock()
+	l.limit = limit
+	l.notifyWaiters()
+	l.mux.Unlock()
+}
+
+// notifyWaiters grants tokens to waiters while there is room. l.mux must be held.
+func (l *connLimit) notifyWaiters() {
+	for l.held < l.limit {
+		front := l.waiters.Front()
+		if front == nil {
+			return
+		}
+		l.held++
+		l.waiters.Remove(front)
+		front.Value.(chan bool) <- true
+	}
+}
+
+// close causes all current and future calls to acquire to fail with ErrPoolClosed.
+func (l *connLimit) close() {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	l.closed = true
+	for e := l.waiters.Front(); e != nil; e = e.Next() {
+		e.Value.(chan bool) <- false
+	}
+	l.waiters.Init()
+}
+
+// constructToken hands the connLimit token held by Acquire to the connection that the puddle.Pool constructs for it.
+// puddle continues a construct in the background when the Acquire gives up. The token then stays with the construct
+// until the new connection is idle in the pool so the next Acquire cannot construct another connection in the meantime.
+type constructToken struct {
+	mux       sync.Mutex
+	state     constructState
+	abandoned bool
+}
+
+type constructState int8
+
+const (
+	constructNotStarted constructState = iota
+	constructRunning
+	constructFinished
+)
+
+type constructTokenKey struct{}
+
+// start is called when the construct begins. It returns false if the Acquire has already given up, in which case the
+// Acquire keeps the token and no connection should be constructed. t may be nil for a construct without a token.
+func (t *constructToken) start() bool {
+	if t == nil {
+		return true
+	}
+
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if t.abandoned {
+		return false
+	}
+	t.state = constructRunning
+	return true
+}
+
+// finish is called when the construct ends. It returns true if the Acquire gave up while the construct was running, in
+// which case the construct owns the token and must release it. Otherwise, the token returns to the Acquire.
+func (t *constructToken) finish() bool {
+	if t == nil {
+		return false
+	}
+
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	t.state = constructFinished
+	return t.abandoned
+}
+
+// abandon is called when the Acquire gives up. It returns false if the construct has already finished, in which case
+// the Acquire must wait for the connection so that it is not idle in the pool while the token is released.
+func (t *constructToken) abandon() bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if t.state == constructFinished {
+		return false
+	}
+	t.abandoned = true
+	return true
+}
+
+// ownedByConstruct returns true if the Acquire gave up after the construct started. The construct releases the token
+// when it finishes.
+func (t *constructToken) ownedByConstruct() bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	return t.abandoned && t.state != constructNotStarted
+}
@@ -0,0 +1,154 @@
+package pgxpool
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// constructLimiter limits the concurrency and rate of establishing new connections and backs off after construct
+// failures. This prevents a pool from stampeding the server, e.g. after a server restart.
+type constructLimiter struct {
+	clock pgconn.Clock
+
+	// slots limits the number of concurrent constructs. It is nil if there is no limit.
+	slots chan struct{}
+
+	// interval is the minimum time between the start of constructs. It is 0 if there is no limit.
+	interval time.Duration
+
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	onConstructError func(err error, consecutiveFailures int)
+
+	mu                  sync.Mutex
+	nextStart           time.Time
+	backoffUntil        time.Time
+	consecutiveFailures int
+}
+
+func newConstructLimiter(config *Config, clock pgconn.Clock) (*constructLimiter, error) {
+	if config.MaxConcurrentConstructs < 0 {
+		return nil, fmt.Errorf("MaxConcurrentConstructs must not be negative: %d", config.MaxConcurrentConstructs)
+	}
+	if config.ConstructRate < 0 || math.IsNaN(config.ConstructRate) {
+		return nil, fmt.Errorf("ConstructRate must be a number that is not negative: %v", config.ConstructRate)
+	}
+	if config.MinConstructBackoff < 0 || config.MaxConstructBackoff < 0 {
+		return nil, fmt.Errorf("construct backoff must not be negative")
+	}
+
+	l := &constructLimiter{
+		clock:            clock,
+		minBackoff:       config.MinConstructBackoff,
+		maxBackoff:       config.MaxConstructBackoff,
+		onConstructError: config.OnConstructError,
+	}
+
+	if config.MaxConcurrentConstructs > 0 {
+		l.slots = make(chan struct{}, config.MaxConcurrentConstructs)
+	}
+
+	if config.ConstructRate > 0 {
+		l.interval = time.Duration(float64(time.Second) / config.ConstructRate)
+	}
+
+	if l.maxBackoff > 0 && l.maxBackoff < l.minBackoff {
+		l.maxBackoff = l.minBackoff
+	}
+
+	return l, nil
+}
+
+// wait blocks until a construct may start. If it returns nil then done must be called when the construct completes.
+func (l *constructLimiter) wait(ctx context.Context) error {
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	l.mu.Lock()
+	now := l.clock.Now()
+	start := now
+	if l.nextStart.After(start) {
+		start = l.nextStart
+	}
+	if l.backoffUntil.After(start) {
+		start = l.backoffUntil
+	}
+	if l.interval > 0 {
+		l.nextStart = start.Add(l.interval)
+	}
+	l.mu.Unlock()
+
+	if d := start.Sub(now); d > 0 {
+		timer := l.clock.NewTimer(d)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			l.releaseSlot()
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// done records the result of a construct started after wait returned nil.
+func (l *constructLimiter) done(err error) {
+	l.releaseSlot()
+
+	l.mu.Lock()
+	if err == nil {
+		l.consecutiveFailures = 0
+		l.backoffUntil = time.Time{}
+		l.mu.Unlock()
+		return
+	}
+
+	l.consecutiveFailures++
+	consecutiveFailures := l.consecutiveFailures
+	if l.minBackoff > 0 {
+		l.backoffUntil = l.clock.Now().Add(l.backoff(consecutiveFailures))
+	}
+	l.mu.Unlock()
+
+	if l.onConstructError != nil {
+		l.onConstructError(err, consecutiveFailures)
+	}
+}
+
+// backoff returns minBackoff doubled for each failure after the first, capped at maxBackoff.
+func (l *constructLimiter) backoff(consecutiveFailures int) time.Duration {
+	backoff := l.minBackoff
+	for i := 1; i < consecutiveFailures; i++ {
+		if l.maxBackoff > 0 && backoff >= l.maxBackoff {
+			break
+		}
+		if backoff > time.Duration(1<<62) {
+			break
+		}
+		backoff *= 2
+	}
+
+	if l.maxBackoff > 0 && backoff > l.maxBackoff {
+		backoff = l.maxBackoff
+	}
+
+	return backoff
+}
+
+func (l *constructLimiter) releaseSlot() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
@@ -0,0 +1,70 @@
+package pgxpool
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// contextQuerier is implemented by *Conn and pgx.Tx.
+type contextQuerier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+type contextQuerierCtxKey struct{}
+
+// contextQuerierValue is the value stored with WithConn and WithTx. conn is the underlying connection of q. It
+// identifies the Pool that q belongs to.
+type contextQuerierValue struct {
+	q    contextQuerier
+	conn *pgx.Conn
+}
+
+// WithConn returns a copy of ctx that causes Pool.Exec, Pool.Query, Pool.QueryRow, Pool.SendBatch, and Pool.CopyFrom
+// to use c instead of acquiring a connection from the pool. c must remain acquired while ctx is in use.
+//
+// This allows code that takes a *Pool to run on a connection that the caller has already acquired, e.g. to use session
+// state such as temporary tables or advisory locks. Other Pool methods such as Acquire and Begin are not affected. Only
+// the Pool that c was acquired from uses c. Other Pools ignore it.
+func WithConn(ctx context.Context, c *Conn) context.Context {
+	return context.WithValue(ctx, contextQuerierCtxKey{}, contextQuerierValue{q: c, conn: c.Conn()})
+}
+
+// WithTx returns a copy of ctx that causes Pool.Exec, Pool.Query, Pool.QueryRow, Pool.SendBatch, and Pool.CopyFrom to
+// run in tx instead of acquiring a connection from the pool. tx must not be committed or rolled back while ctx is in
+// use.
+//
+// This allows code such as a repository that takes a *Pool to be called both inside and outside of a transaction
+// without a separate code path. Other Pool methods such as Acquire and Begin are not affected. In particular, Begin
+// starts a new transaction on a different connection rather than a nested transaction in tx. Only the Pool that the
+// connection of tx belongs to uses tx. Other Pools ignore it.
+func WithTx(ctx context.Context, tx pgx.Tx) context.Context {
+	return context.WithValue(ctx, contextQuerierCtxKey{}, contextQuerierValue{q: tx, conn: tx.Conn()})
+}
+
+// contextQuerierFromContext returns the *Conn or pgx.Tx set with WithConn or WithTx if it belongs to p.
+func (p *Pool) contextQuerierFromContext(ctx context.Context) (contextQuerier, bool) {
+	v, ok := ctx.Value(contextQuerierCtxKey{}).(contextQuerierValue)
+	if !ok || v.q == nil || v.conn == nil {
+		return nil, false
+	}
+
+	if _, owned := p.conns.Load(v.conn); !owned {
+		return nil, false
+	}
+
+	return v.q, true
+}
+
+// withoutContextQuerier returns a copy of ctx without the *Conn or pgx.Tx set with WithConn or WithTx.
+func withoutContextQuerier(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(contextQuerierCtxKey{}).(contextQuerierValue); !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, contextQuerierCtxKey{}, contextQuerierValue{})
+}
@@ -0,0 +1,67 @@
+package pgxpool
+
+import (
+	"context"
+	"strings"
+)
+
+// DefaultDDLInvalidationChannel is the conventional channel for Config.DDLInvalidationChannel and
+// DDLInvalidationTriggerSQL.
+const DefaultDDLInvalidationChannel = "pgx_ddl"
+
+// DDLInvalidationTriggerSQL returns SQL that installs an event trigger that notifies channel at the end of every DDL
+// command. When Config.DDLInvalidationChannel is channel, pools invalidate their statement caches when notified. As with
+// any notification, it is sent when the transaction that executed the DDL commits. Creating an event trigger requires
+// superuser privileges. The SQL can be executed more than once, e.g. from a migration.
+func DDLInvalidationTriggerSQL(channel string) string {
+	quotedChannel := "'" + strings.ReplaceAll(channel, "'", "''") + "'"
+
+	return `create or replace function pgx_notify_ddl() returns event_trigger language plpgsql as $$
+begin
+	perform pg_notify(` + quotedChannel + `, tg_tag);
+end;
+$$;
+
+drop event trigger if exists pgx_notify_ddl;
+create event trigger pgx_notify_ddl on ddl_command_end execute function pgx_notify_ddl();`
+}
+
+// InvalidateStatementCaches invalidates the statement and description caches of all connections in the pool. As
+// connections may be in use, each connection invalidates its caches the next time it is acquired. See
+// pgx.Conn.InvalidateStatementCaches.
+func (p *Pool) InvalidateStatementCaches() {
+	p.cacheGeneration.Add(1)
+}
+
+// refreshStatementCaches invalidates the caches of the connection in cr if InvalidateStatementCaches was called since
+// they were last invalidated.
+func (p *Pool) refreshStatementCaches(cr *connResource) {
+	generation := p.cacheGeneration.Load()
+	if cr.cacheGeneration != generation {
+		cr.conn.InvalidateStatementCaches()
+		cr.cacheGeneration = generation
+	}
+}
+
+// startDDLInvalidation invalidates the statement caches of the pool whenever a notification is received on channel. As
+// notifications sent while the broker is not listening are lost, the caches are also invalidated whenever the broker
+// starts listening on channel.
+func (p *Pool) startDDLInvalidation(channel string) {
+	p.ddlBroker = newNotificationBroker(p, func(string) { p.InvalidateStatementCaches() })
+
+	go func() {
+		sub, err := p.ddlBroker.Subscribe(context.Background(), channel)
+		if err != nil {
+			return
+		}
+
+		for {
+			select {
+			case <-sub.Notifications():
+				p.InvalidateStatementCaches()
+			case <-sub.Done():
+				return
+			}
+		}
+	}()
+}
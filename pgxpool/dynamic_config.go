@@ -0,0 +1,65 @@
+package pgxpool
+
+import (
+	"fmt"
+	"time"
+)
+
+// DynamicConfig contains the settings of a Pool that can be changed while it is in use. See Pool.UpdateConfig.
+type DynamicConfig struct {
+	// MaxConns is the maximum size of the pool. See Config.MaxConns.
+	MaxConns int32
+
+	// MinConns is the minimum size of the pool. See Config.MinConns.
+	MinConns int32
+
+	// MaxConnLifetime is the duration since creation after which a connection will be automatically closed. See
+	// Config.MaxConnLifetime.
+	MaxConnLifetime time.Duration
+}
+
+// DynamicConfig returns the current values of the settings that can be changed by UpdateConfig.
+func (p *Pool) DynamicConfig() DynamicConfig {
+	return DynamicConfig{
+		MaxConns:        p.maxConns.Load(),
+		MinConns:        p.minConns.Load(),
+		MaxConnLifetime: time.Duration(p.maxConnLifetime.Load()),
+	}
+}
+
+// UpdateConfig changes the settings of the pool while it is in use. It is typically called with the result of
+// DynamicConfig after modifying some of its fields.
+//
+// Increasing MaxConns takes effect immediately. Acquires waiting for a connection proceed and establish new connections
+// as needed. Decreasing MaxConns prevents new connections from being established and further connections from being
+// acquired until fewer than MaxConns connections are in use. Idle connections in excess of MaxConns are closed by the
+// health check. Connections in use are never closed, so the pool shrinks gradually as they are released.
+//
+// A change of MinConns is applied by the health check. A change of MaxConnLifetime applies to existing connections as
+// well as new ones.
+func (p *Pool) UpdateConfig(dc DynamicConfig) error {
+	if dc.MaxConns < 1 {
+		return fmt.Errorf("MaxConns must be >= 1: %d", dc.MaxConns)
+	}
+	if dc.MinConns < 0 {
+		return fmt.Errorf("MinConns must be >= 0: %d", dc.MinConns)
+	}
+	if dc.MinConns > dc.MaxConns {
+		return fmt.Errorf("MinConns (%d) must not be greater than MaxConns (%d)", dc.MinConns, dc.MaxConns)
+	}
+	if dc.MaxConnLifetime < 0 {
+		return fmt.Errorf("MaxConnLifetime must not be negative: %v", dc.MaxConnLifetime)
+	}
+
+	p.updateConfigMux.Lock()
+	defer p.updateConfigMux.Unlock()
+
+	p.maxConnLifetime.Store(int64(dc.MaxConnLifetime))
+	p.minConns.Store(dc.MinConns)
+	p.maxConns.Store(dc.MaxConns)
+	p.connLimit.setLimit(dc.MaxConns)
+
+	p.triggerHealthCheck()
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package pgxpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/puddle/v2"
+)
+
+// ErrPoolClosed is returned by Acquire when the pool is closed. It is the same error as puddle.ErrClosedPool.
+var ErrPoolClosed = puddle.ErrClosedPool
+
+// ErrAcquireTimeout is returned by Acquire when its context deadline is exceeded before a connection is acquired. The
+// returned error also wraps the error that interrupted the acquire, usually context.DeadlineExceeded. If the deadline
+// was exceeded while establishing a new connection the error wraps a *ConstructError as well.
+var ErrAcquireTimeout = errors.New("timeout acquiring connection")
+
+// ConstructError is returned by Acquire when a new connection could not be established. This includes errors from
+// BeforeConnect and AfterConnect.
+type ConstructError struct {
+	Cause error
+}
+
+func (e *ConstructError) Error() string {
+	return e.Cause.Error()
+}
+
+func (e *ConstructError) Unwrap() error {
+	return e.Cause
+}
+
+// acquireError returns err as the typed error callers of Acquire can distinguish.
+func acquireError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && (errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err)) {
+		return fmt.Errorf("%w: %w", ErrAcquireTimeout, err)
+	}
+	return err
+}
@@ -0,0 +1,289 @@
+package pgxpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// JanitorPolicy controls what a Janitor does with the orphaned artifacts it finds.
+type JanitorPolicy int
+
+const (
+	// JanitorReport only reports orphaned artifacts.
+	JanitorReport JanitorPolicy = iota
+
+	// JanitorClean reports orphaned artifacts and then cleans them up. Orphaned prepared transactions are rolled back.
+	// Backends holding orphaned advisory locks are terminated as session level advisory locks can only be released by
+	// the session that holds them.
+	JanitorClean
+)
+
+// OrphanKind is the kind of an OrphanedArtifact.
+type OrphanKind int
+
+const (
+	// OrphanPreparedTransaction is a two-phase transaction that was prepared but never committed or rolled back.
+	OrphanPreparedTransaction OrphanKind = iota
+
+	// OrphanAdvisoryLock is an advisory lock held by an idle session.
+	OrphanAdvisoryLock
+)
+
+func (k OrphanKind) String() string {
+	switch k {
+	case OrphanPreparedTransaction:
+		return "prepared transaction"
+	case OrphanAdvisoryLock:
+		return "advisory lock"
+	default:
+		return "unknown"
+	}
+}
+
+// OrphanedArtifact is a prepared transaction or advisory lock found by a Janitor.
+type OrphanedArtifact struct {
+	Kind OrphanKind
+
+	// GID, Owner, and Prepared are set for OrphanPreparedTransaction.
+	GID      string
+	Owner    string
+	Prepared time.Time
+
+	// PID, ApplicationName, ClassID, ObjID, ObjSubID, and IdleSince are set for OrphanAdvisoryLock.
+	PID             uint32
+	ApplicationName string
+	ClassID         uint32
+	ObjID           uint32
+	ObjSubID        uint16
+	IdleSince       time.Time
+
+	// Cleaned is true if the artifact was cleaned up. CleanErr is the error from the attempt to clean it up if any.
+	Cleaned  bool
+	CleanErr error
+}
+
+// JanitorConfig configures a Janitor.
+type JanitorConfig struct {
+	// Interval is the delay between scans. It must be greater than 0.
+	Interval time.Duration
+
+	// GIDPrefix identifies the prepared transactions created by this application. If empty prepared transactions are not
+	// scanned.
+	GIDPrefix string
+
+	// ApplicationName identifies the sessions created by this application. Advisory locks held by sessions with this
+	// application_name that have been idle for longer than MinAge are considered orphaned. The connections of the pool
+	// the janitor scans with are never considered orphaned. If empty advisory locks are not scanned.
+	ApplicationName string
+
+	// MinAge is the minimum age of a prepared transaction or the minimum idle time of a session holding an advisory lock
+	// before it is considered orphaned. It should be comfortably longer than any legitimate use.
+	MinAge time.Duration
+
+	// Policy controls whether orphaned artifacts are only reported or also cleaned up.
+	Policy JanitorPolicy
+
+	// OnOrphan is called for each orphaned artifact found. When Policy is JanitorClean it is called after the clean up
+	// has been attempted. It may be nil.
+	OnOrphan func(artifact *OrphanedArtifact)
+
+	// OnError is called with any error scanning for orphaned artifacts. It may be nil.
+	OnError func(err error)
+}
+
+// Janitor periodically scans the database for prepared transactions and advisory locks that were leaked by this
+// application. It catches leaked two-phase transactions and advisory locks before they block vacuum or other sessions.
+type Janitor struct {
+	pool   *Pool
+	config JanitorConfig
+
+	stopOnce sync.Once
+	cancel   context.CancelFunc
+	doneChan chan struct{}
+}
+
+// StartJanitor starts a Janitor that scans using connections from pool. Stop must be called to stop it.
+func StartJanitor(pool *Pool, config JanitorConfig) (*Janitor, error) {
+	if config.Interval <= 0 {
+		return nil, errors.New("pgxpool: JanitorConfig.Interval must be greater than 0")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &Janitor{
+		pool:     pool,
+		config:   config,
+		cancel:   cancel,
+		doneChan: make(chan struct{}),
+	}
+
+	go j.run(ctx)
+
+	return j, nil
+}
+
+// Stop stops the janitor and waits for any scan in progress to finish.
+func (j *Janitor) Stop() {
+	j.stopOnce.Do(j.cancel)
+	<-j.doneChan
+}
+
+func (j *Janitor) run(ctx context.Context) {
+	defer close(j.doneChan)
+
+	for {
+		timer := j.pool.clock.NewTimer(j.config.Interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+
+		artifacts, err := j.Scan(ctx)
+		if err != nil && ctx.Err() == nil && j.config.OnError != nil {
+			j.config.OnError(err)
+		}
+
+		if j.config.OnOrphan != nil {
+			for _, a := range artifacts {
+				j.config.OnOrphan(a)
+			}
+		}
+	}
+}
+
+// Scan performs a single scan for orphaned artifacts and applies the policy to them. It is called periodically by the
+// janitor but may also be called directly.
+func (j *Janitor) Scan(ctx context.Context) ([]*OrphanedArtifact, error) {
+	c, err := j.pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Release()
+
+	var artifacts []*OrphanedArtifact
+
+	if j.config.GIDPrefix != "" {
+		prepared, err := j.scanPreparedTransactions(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, prepared...)
+	}
+
+	if j.config.ApplicationName != "" {
+		locks, err := j.scanAdvisoryLocks(ctx, c)
+		if err != nil {
+			return artifacts, err
+		}
+		artifacts = append(artifacts, locks...)
+	}
+
+	return artifacts, nil
+}
+
+// backendPIDs returns the backend process IDs of all connections of the pool, including acquired connections.
+func (p *Pool) backendPIDs() []int32 {
+	var pids []int32
+	p.conns.Range(func(key, _ any) bool {
+		pids = append(pids, int32(key.(*pgx.Conn).PgConn().PID()))
+		return true
+	})
+	return pids
+}
+
+func (j *Janitor) scanPreparedTransactions(ctx context.Context, c *Conn) ([]*OrphanedArtifact, error) {
+	rows, err := c.Query(ctx, `select gid, owner, prepared
+from pg_prepared_xacts
+where database = current_database()
+	and left(gid, length($1)) = $1
+	and prepared < now() - make_interval(secs => $2)`,
+		j.config.GIDPrefix, j.config.MinAge.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("scan prepared transactions: %w", err)
+	}
+
+	var artifacts []*OrphanedArtifact
+	for rows.Next() {
+		a := &OrphanedArtifact{Kind: OrphanPreparedTransaction}
+		err = rows.Scan(&a.GID, &a.Owner, &a.Prepared)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan prepared transactions: %w", err)
+		}
+		artifacts = append(artifacts, a)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan prepared transactions: %w", rows.Err())
+	}
+
+	if j.config.Policy == JanitorClean {
+		for _, a := range artifacts {
+			var gid string
+			gid, a.CleanErr = c.Conn().PgConn().EscapeString(a.GID)
+			if a.CleanErr == nil {
+				_, a.CleanErr = c.Exec(ctx, "rollback prepared '"+gid+"'")
+			}
+			a.Cleaned = a.CleanErr == nil
+		}
+	}
+
+	return artifacts, nil
+}
+
+func (j *Janitor) scanAdvisoryLocks(ctx context.Context, c *Conn) ([]*OrphanedArtifact, error) {
+	rows, err := c.Query(ctx, `select l.pid, a.application_name, l.classid, l.objid, l.objsubid, a.state_change
+from pg_locks l
+	join pg_stat_activity a on a.pid = l.pid
+where l.locktype = 'advisory'
+	and l.granted
+	and l.database = (select oid from pg_database where datname = current_database())
+	and l.pid <> all($3)
+	and a.application_name = $1
+	and a.state = 'idle'
+	and a.state_change < now() - make_interval(secs => $2)`,
+		j.config.ApplicationName, j.config.MinAge.Seconds(), j.pool.backendPIDs(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("scan advisory locks: %w", err)
+	}
+
+	var artifacts []*OrphanedArtifact
+	for rows.Next() {
+		a := &OrphanedArtifact{Kind: OrphanAdvisoryLock}
+		err = rows.Scan(&a.PID, &a.ApplicationName, &a.ClassID, &a.ObjID, &a.ObjSubID, &a.IdleSince)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan advisory locks: %w", err)
+		}
+		artifacts = append(artifacts, a)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan advisory locks: %w", rows.Err())
+	}
+
+	if j.config.Policy == JanitorClean {
+		terminated := make(map[uint32]error)
+		for _, a := range artifacts {
+			err, ok := terminated[a.PID]
+			if !ok {
+				var success bool
+				err = c.QueryRow(ctx, "select pg_terminate_backend($1)", a.PID).Scan(&success)
+				if err == nil && !success {
+					err = fmt.Errorf("could not terminate backend %d", a.PID)
+				}
+				terminated[a.PID] = err
+			}
+			a.CleanErr = err
+			a.Cleaned = err == nil
+		}
+	}
+
+	return artifacts, nil
+}
@@ -0,0 +1,141 @@
+package pgxpool_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJanitorAdvisoryLock(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	poolConfig, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	poolConfig.ConnConfig.RuntimeParams["application_name"] = "pgx_janitor_test"
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	// A pool connection that holds an advisory lock is in use by the application and is not orphaned.
+	poolConn, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	_, err = poolConn.Exec(ctx, "select pg_advisory_lock(4243)")
+	require.NoError(t, err)
+	poolConn.Release()
+
+	config, err := pgx.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.RuntimeParams["application_name"] = "pgx_janitor_test"
+	leaker, err := pgx.ConnectConfig(ctx, config)
+	require.NoError(t, err)
+	defer leaker.Close(ctx)
+
+	_, err = leaker.Exec(ctx, "select pg_advisory_lock(4242)")
+	require.NoError(t, err)
+
+	janitor, err := pgxpool.StartJanitor(pool, pgxpool.JanitorConfig{
+		Interval:        time.Hour,
+		ApplicationName: "pgx_janitor_test",
+		Policy:          pgxpool.JanitorReport,
+	})
+	require.NoError(t, err)
+	defer janitor.Stop()
+
+	artifacts, err := janitor.Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, artifacts, 1)
+	assert.Equal(t, pgxpool.OrphanAdvisoryLock, artifacts[0].Kind)
+	assert.Equal(t, leaker.PgConn().PID(), artifacts[0].PID)
+	assert.EqualValues(t, 4242, artifacts[0].ObjID)
+	assert.False(t, artifacts[0].Cleaned)
+
+	cleaner, err := pgxpool.StartJanitor(pool, pgxpool.JanitorConfig{
+		Interval:        time.Hour,
+		ApplicationName: "pgx_janitor_test",
+		Policy:          pgxpool.JanitorClean,
+	})
+	require.NoError(t, err)
+	defer cleaner.Stop()
+
+	artifacts, err = cleaner.Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, artifacts, 1)
+	assert.True(t, artifacts[0].Cleaned)
+	assert.NoError(t, artifacts[0].CleanErr)
+
+	require.Eventually(t, func() bool {
+		artifacts, err := janitor.Scan(ctx)
+		return err == nil && len(artifacts) == 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestJanitorPreparedTransaction(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	var maxPreparedTransactions int
+	err = pool.QueryRow(ctx, "select current_setting('max_prepared_transactions')::int").Scan(&maxPreparedTransactions)
+	require.NoError(t, err)
+	if maxPreparedTransactions == 0 {
+		t.Skip("Server does not allow prepared transactions")
+	}
+
+	c, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	_, err = c.Exec(ctx, "begin")
+	require.NoError(t, err)
+	_, err = c.Exec(ctx, "prepare transaction 'pgx_janitor_test_1'")
+	require.NoError(t, err)
+	c.Release()
+
+	reported := make(chan *pgxpool.OrphanedArtifact, 1)
+	janitor, err := pgxpool.StartJanitor(pool, pgxpool.JanitorConfig{
+		Interval:  10 * time.Millisecond,
+		GIDPrefix: "pgx_janitor_test_",
+		Policy:    pgxpool.JanitorClean,
+		OnOrphan: func(artifact *pgxpool.OrphanedArtifact) {
+			select {
+			case reported <- artifact:
+			default:
+			}
+		},
+	})
+	require.NoError(t, err)
+	defer janitor.Stop()
+
+	select {
+	case artifact := <-reported:
+		assert.Equal(t, pgxpool.OrphanPreparedTransaction, artifact.Kind)
+		assert.Equal(t, "pgx_janitor_test_1", artifact.GID)
+		assert.True(t, artifact.Cleaned)
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for janitor")
+	}
+
+	var n int
+	err = pool.QueryRow(ctx, "select count(*) from pg_prepared_xacts where gid = 'pgx_janitor_test_1'").Scan(&n)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestStartJanitorRequiresInterval(t *testing.T) {
+	t.Parallel()
+
+	_, err := pgxpool.StartJanitor(nil, pgxpool.JanitorConfig{})
+	require.Error(t, err)
+}
@@ -0,0 +1,133 @@
+package pgxpool
+
+import (
+	"errors"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// LeakedConn describes a connection that has been held for longer than Config.LeakDetectionThreshold. See
+// Config.OnConnLeak.
+type LeakedConn struct {
+	Conn *pgx.Conn
+
+	// AcquiredAt is when the connection was acquired. HeldFor is how long it had been held when the leak was detected.
+	AcquiredAt time.Time
+	HeldFor    time.Duration
+
+	// Stack is the stack trace of the goroutine that acquired the connection as formatted by runtime/debug.Stack.
+	Stack []byte
+}
+
+// leakDetector tracks acquired connections and reports the ones that are held for longer than threshold. A nil
+// *leakDetector tracks nothing.
+type leakDetector struct {
+	threshold time.Duration
+	onLeak    func(LeakedConn)
+	clock     pgconn.Clock
+
+	mux  sync.Mutex
+	held map[*Conn]*heldConn
+}
+
+type heldConn struct {
+	conn       *pgx.Conn
+	acquiredAt time.Time
+	stack      []byte
+	reported   bool
+}
+
+// newLeakDetector returns a leakDetector for p that reports leaks to config.OnConnLeak and to the ConnLeakTracer of the
+// pool. An error is returned if leak detection is enabled and leaks are not reported to either.
+func newLeakDetector(p *Pool, config *Config) (*leakDetector, error) {
+	if config.LeakDetectionThreshold <= 0 {
+		return nil, nil
+	}
+
+	tracer, _ := config.ConnConfig.Tracer.(ConnLeakTracer)
+	if config.OnConnLeak == nil && tracer == nil {
+		return nil, errors.New("LeakDetectionThreshold requires OnConnLeak or a Tracer that implements ConnLeakTracer")
+	}
+
+	onConnLeak := config.OnConnLeak
+	onLeak := func(lc LeakedConn) {
+		if onConnLeak != nil {
+			onConnLeak(lc)
+		}
+		if tracer != nil {
+			tracer.TraceConnLeak(p, TraceConnLeakData{LeakedConn: lc})
+		}
+	}
+
+	return &leakDetector{
+		threshold: config.LeakDetectionThreshold,
+		onLeak:    onLeak,
+		clock:     p.clock,
+		held:      make(map[*Conn]*heldConn),
+	}, nil
+}
+
+// track records that c was acquired by the calling goroutine.
+func (d *leakDetector) track(c *Conn) {
+	if d == nil {
+		return
+	}
+
+	hc := &heldConn{conn: c.Conn(), acquiredAt: d.clock.Now(), stack: debug.Stack()}
+
+	d.mux.Lock()
+	d.held[c] = hc
+	d.mux.Unlock()
+}
+
+// untrack records that c was released or hijacked.
+func (d *leakDetector) untrack(c *Conn) {
+	if d == nil {
+		return
+	}
+
+	d.mux.Lock()
+	delete(d.held, c)
+	d.mux.Unlock()
+}
+
+// check reports the connections that have been held for longer than the threshold. Each acquisition is only reported
+// once.
+func (d *leakDetector) check() {
+	now := d.clock.Now()
+
+	var leaked []LeakedConn
+	d.mux.Lock()
+	for _, hc := range d.held {
+		heldFor := now.Sub(hc.acquiredAt)
+		if !hc.reported && heldFor >= d.threshold {
+			hc.reported = true
+			leaked = append(leaked, LeakedConn{Conn: hc.conn, AcquiredAt: hc.acquiredAt, HeldFor: heldFor, Stack: hc.stack})
+		}
+	}
+	d.mux.Unlock()
+
+	for _, lc := range leaked {
+		d.onLeak(lc)
+	}
+}
+
+// run checks for leaks until closeChan is closed. Leaks are detected at most half of the threshold after they occur.
+func (d *leakDetector) run(closeChan <-chan struct{}) {
+	period := max(d.threshold/2, 1)
+	timer := d.clock.NewTimer(period)
+	defer timer.Stop()
+	for {
+		select {
+		case <-closeChan:
+			return
+		case <-timer.C():
+			d.check()
+			timer.Reset(period)
+		}
+	}
+}
@@ -0,0 +1,345 @@
+package pgxpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// notificationBrokerReconnectDelay is the time a NotificationBroker waits before reacquiring a connection after its
+// listening connection fails.
+var notificationBrokerReconnectDelay = time.Second
+
+// ErrNotificationBrokerClosed is returned by NotificationBroker.Subscribe after the broker has been closed.
+var ErrNotificationBrokerClosed = errors.New("notification broker closed")
+
+// NotificationBroker multiplexes LISTEN / NOTIFY for many subscribers over a single connection acquired from a Pool.
+// This allows independent components of an application to receive notifications without each holding a connection of
+// its own.
+//
+// The connection is held for the lifetime of the broker. It is not reported by Config.LeakDetectionThreshold. If it
+// fails, a new connection is acquired and all channels with subscribers are listened to again. Notifications sent while
+// there is no listening connection are lost.
+type NotificationBroker struct {
+	pool *Pool
+
+	// onListen, if not nil, is called with each channel after LISTEN is executed for it, including when it is listened
+	// to again on a new connection.
+	onListen func(channel string)
+
+	mux           sync.Mutex
+	subscriptions map[string]map[*Subscription]struct{}
+	listening     map[string]struct{}
+	waiters       map[string][]chan struct{}
+	cancelWait    context.CancelFunc
+	closed        bool
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+	doneChan  chan struct{}
+}
+
+// Subscription is a subscription to a channel created by NotificationBroker.Subscribe.
+type Subscription struct {
+	broker   *NotificationBroker
+	channel  string
+	notifyCh chan *pgconn.Notification
+
+	unsubscribeOnce sync.Once
+	doneChan        chan struct{}
+}
+
+// NewNotificationBroker creates a NotificationBroker that listens on a connection acquired from pool. The connection is
+// acquired in the background. Close must be called to return the connection to the pool.
+func NewNotificationBroker(pool *Pool) *NotificationBroker {
+	return newNotificationBroker(pool, nil)
+}
+
+func newNotificationBroker(pool *Pool, onListen func(channel string)) *NotificationBroker {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &NotificationBroker{
+		pool:          pool,
+		onListen:      onListen,
+		subscriptions: make(map[string]map[*Subscription]struct{}),
+		listening:     make(map[string]struct{}),
+		waiters:       make(map[string][]chan struct{}),
+		ctx:           ctx,
+		cancel:        cancel,
+		doneChan:      make(chan struct{}),
+	}
+
+	go b.run()
+
+	return b
+}
+
+// Subscribe subscribes to notifications sent on channel. It returns once the broker's connection is listening on
+// channel or ctx is done. Notifications are delivered to the channel returned by Subscription.Notifications. The
+// broker waits for each subscriber to receive a notification before delivering the next so subscribers must not block
+// for long.
+func (b *NotificationBroker) Subscribe(ctx context.Context, channel string) (*Subscription, error) {
+	sub := &Subscription{
+		broker:   b,
+		channel:  channel,
+		notifyCh: make(chan *pgconn.Notification, 16),
+		doneChan: make(chan struct{}),
+	}
+
+	b.mux.Lock()
+	if b.closed {
+		b.mux.Unlock()
+		return nil, ErrNotificationBrokerClosed
+	}
+
+	subs, ok := b.subscriptions[channel]
+	if !ok {
+		subs = make(map[*Subscription]struct{})
+		b.subscriptions[channel] = subs
+	}
+	subs[sub] = struct{}{}
+
+	if _, ok := b.listening[channel]; ok {
+		b.mux.Unlock()
+		return sub, nil
+	}
+
+	listenedChan := make(chan struct{})
+	b.waiters[channel] = append(b.waiters[channel], listenedChan)
+	b.wake()
+	b.mux.Unlock()
+
+	select {
+	case <-listenedChan:
+		return sub, nil
+	case <-b.doneChan:
+		sub.Unsubscribe()
+		return nil, ErrNotificationBrokerClosed
+	case <-ctx.Done():
+		sub.Unsubscribe()
+		return nil, ctx.Err()
+	}
+}
+
+// Close unsubscribes all subscriptions, stops listening, and returns the connection to the pool. It waits for the
+// broker's background goroutine to finish.
+func (b *NotificationBroker) Close() {
+	b.closeOnce.Do(func() {
+		b.mux.Lock()
+		b.closed = true
+		var subs []*Subscription
+		for _, channelSubs := range b.subscriptions {
+			for sub := range channelSubs {
+				subs = append(subs, sub)
+			}
+		}
+		b.mux.Unlock()
+
+		b.cancel()
+		for _, sub := range subs {
+			sub.Unsubscribe()
+		}
+	})
+
+	<-b.doneChan
+}
+
+// wake interrupts the broker's wait for notifications so it can update the channels it is listening on. b.mux must be
+// held.
+func (b *NotificationBroker) wake() {
+	if b.cancelWait != nil {
+		b.cancelWait()
+		b.cancelWait = nil
+	}
+}
+
+func (b *NotificationBroker) run() {
+	defer close(b.doneChan)
+
+	for {
+		b.listen()
+
+		b.mux.Lock()
+		b.listening = make(map[string]struct{})
+		b.mux.Unlock()
+
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-time.After(notificationBrokerReconnectDelay):
+		}
+	}
+}
+
+// listen acquires a connection and dispatches notifications until the connection fails or the broker is closed.
+func (b *NotificationBroker) listen() {
+	c, err := b.pool.Acquire(b.ctx)
+	if err != nil {
+		return
+	}
+	// The connection is intentionally held for the lifetime of the broker.
+	b.pool.leakDetector.untrack(c)
+	defer func() {
+		if !c.Conn().IsClosed() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			_, err := c.Exec(ctx, "unlisten *")
+			cancel()
+			if err != nil {
+				c.Conn().Close(context.Background())
+			}
+		}
+		c.Release()
+	}()
+
+	for {
+		if err := b.updateListening(c.Conn()); err != nil {
+			return
+		}
+
+		b.mux.Lock()
+		if b.ctx.Err() != nil {
+			b.mux.Unlock()
+			return
+		}
+		if b.hasPendingChanges() {
+			b.mux.Unlock()
+			continue
+		}
+		waitCtx, cancel := context.WithCancel(b.ctx)
+		b.cancelWait = cancel
+		b.mux.Unlock()
+
+		n, err := c.Conn().WaitForNotification(waitCtx)
+		cancel()
+
+		if n != nil {
+			b.dispatch(n)
+		}
+		if err != nil {
+			if c.Conn().IsClosed() || b.ctx.Err() != nil || waitCtx.Err() == nil {
+				return
+			}
+		}
+	}
+}
+
+// hasPendingChanges reports if the set of channels with subscribers differs from the set of channels being listened
+// to. b.mux must be held.
+func (b *NotificationBroker) hasPendingChanges() bool {
+	if len(b.subscriptions) != len(b.listening) {
+		return true
+	}
+	for channel := range b.subscriptions {
+		if _, ok := b.listening[channel]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// updateListening executes LISTEN and UNLISTEN as necessary to make the channels conn is listening on match the
+// channels with subscribers.
+func (b *NotificationBroker) updateListening(conn *pgx.Conn) error {
+	b.mux.Lock()
+	var toListen, toUnlisten []string
+	for channel := range b.subscriptions {
+		if _, ok := b.listening[channel]; !ok {
+			toListen = append(toListen, channel)
+		}
+	}
+	for channel := range b.listening {
+		if _, ok := b.subscriptions[channel]; !ok {
+			toUnlisten = append(toUnlisten, channel)
+		}
+	}
+	b.mux.Unlock()
+
+	for _, channel := range toListen {
+		_, err := conn.Exec(b.ctx, "listen "+pgx.Identifier{channel}.Sanitize())
+		if err != nil {
+			return err
+		}
+
+		b.mux.Lock()
+		b.listening[channel] = struct{}{}
+		for _, ch := range b.waiters[channel] {
+			close(ch)
+		}
+		delete(b.waiters, channel)
+		b.mux.Unlock()
+
+		if b.onListen != nil {
+			b.onListen(channel)
+		}
+	}
+
+	for _, channel := range toUnlisten {
+		_, err := conn.Exec(b.ctx, "unlisten "+pgx.Identifier{channel}.Sanitize())
+		if err != nil {
+			return err
+		}
+
+		b.mux.Lock()
+		delete(b.listening, channel)
+		b.mux.Unlock()
+	}
+
+	return nil
+}
+
+func (b *NotificationBroker) dispatch(n *pgconn.Notification) {
+	b.mux.Lock()
+	subs := make([]*Subscription, 0, len(b.subscriptions[n.Channel]))
+	for sub := range b.subscriptions[n.Channel] {
+		subs = append(subs, sub)
+	}
+	b.mux.Unlock()
+
+	for _, sub := range subs {
+		select {
+		case sub.notifyCh <- n:
+		case <-sub.doneChan:
+		case <-b.ctx.Done():
+			return
+		}
+	}
+}
+
+// Channel returns the name of the channel s is subscribed to.
+func (s *Subscription) Channel() string {
+	return s.channel
+}
+
+// Notifications returns a channel that receives the notifications for s. It is not closed by Unsubscribe. Use Done to
+// detect when s has been unsubscribed.
+func (s *Subscription) Notifications() <-chan *pgconn.Notification {
+	return s.notifyCh
+}
+
+// Done returns a channel that is closed when s is unsubscribed.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.doneChan
+}
+
+// Unsubscribe stops delivery of notifications to s. The broker stops listening on the channel when it has no
+// remaining subscribers. It is safe to call Unsubscribe multiple times.
+func (s *Subscription) Unsubscribe() {
+	s.unsubscribeOnce.Do(func() {
+		close(s.doneChan)
+
+		b := s.broker
+		b.mux.Lock()
+		defer b.mux.Unlock()
+
+		subs := b.subscriptions[s.channel]
+		delete(subs, s)
+		if len(subs) == 0 {
+			delete(b.subscriptions, s.channel)
+			b.wake()
+		}
+	})
+}
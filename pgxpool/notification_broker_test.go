@@ -0,0 +1,80 @@
+package pgxpool_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationBroker(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	broker := pgxpool.NewNotificationBroker(pool)
+	defer broker.Close()
+
+	sub1, err := broker.Subscribe(ctx, "broker_a")
+	require.NoError(t, err)
+	sub2, err := broker.Subscribe(ctx, "broker_a")
+	require.NoError(t, err)
+	sub3, err := broker.Subscribe(ctx, "broker_b")
+	require.NoError(t, err)
+
+	_, err = pool.Exec(ctx, "select pg_notify('broker_a', 'hello')")
+	require.NoError(t, err)
+
+	for _, sub := range []*pgxpool.Subscription{sub1, sub2} {
+		select {
+		case n := <-sub.Notifications():
+			assert.Equal(t, "broker_a", n.Channel)
+			assert.Equal(t, "hello", n.Payload)
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for notification")
+		}
+	}
+
+	select {
+	case n := <-sub3.Notifications():
+		t.Fatalf("unexpected notification: %v", n)
+	default:
+	}
+
+	sub1.Unsubscribe()
+	sub2.Unsubscribe()
+
+	_, err = pool.Exec(ctx, "select pg_notify('broker_a', 'ignored'), pg_notify('broker_b', 'world')")
+	require.NoError(t, err)
+
+	select {
+	case n := <-sub3.Notifications():
+		assert.Equal(t, "broker_b", n.Channel)
+		assert.Equal(t, "world", n.Payload)
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for notification")
+	}
+
+	select {
+	case n := <-sub1.Notifications():
+		t.Fatalf("unexpected notification after unsubscribe: %v", n)
+	default:
+	}
+
+	broker.Close()
+	<-sub3.Done()
+
+	_, err = broker.Subscribe(ctx, "broker_a")
+	require.ErrorIs(t, err, pgxpool.ErrNotificationBrokerClosed)
+
+	assert.EqualValues(t, 0, pool.Stat().AcquiredConns())
+}
@@ -0,0 +1,118 @@
+package pgxpool
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PollConfig configures Poll.
+type PollConfig[T any] struct {
+	// SQL is the query to run on each poll.
+	SQL string
+
+	// Args are the arguments for SQL.
+	Args []any
+
+	// Interval is the delay between the end of one poll and the start of the next. It must be greater than 0.
+	Interval time.Duration
+
+	// Jitter is the maximum random duration added to each delay. It spreads out the polls of many pollers started at
+	// the same time. 0 disables jitter.
+	Jitter time.Duration
+
+	// MaxBackoff is the maximum delay after consecutive errors. After an error the delay doubles until it reaches
+	// MaxBackoff. A successful poll resets the delay to Interval. If MaxBackoff is less than Interval, Interval is used
+	// and errors do not increase the delay.
+	MaxBackoff time.Duration
+
+	// RowToFunc converts each row to a T. It must not be nil.
+	RowToFunc pgx.RowToFunc[T]
+
+	// Handle is called with the rows of each poll. It is called even when the query returns no rows. It is never called
+	// concurrently and the next poll does not start until it returns. If it returns an error the delay before the next
+	// poll is increased the same as a query error.
+	Handle func(ctx context.Context, rows []T) error
+
+	// OnError is called with any error from the query or Handle. It may be nil.
+	OnError func(err error)
+}
+
+// Poll runs config.SQL on pool every config.Interval and passes the resulting rows to config.Handle. A connection is
+// only acquired from pool for the duration of the query, not while waiting or running Handle. Polls never overlap. Poll
+// runs until ctx is canceled and then returns ctx.Err().
+//
+// Poll is intended for the common pattern of checking a table for new work:
+//
+//	err := pgxpool.Poll(ctx, pool, pgxpool.PollConfig[Job]{
+//		SQL:        "select id, payload from jobs where status = 'pending' order by id limit 100",
+//		Interval:   time.Second,
+//		Jitter:     250 * time.Millisecond,
+//		MaxBackoff: time.Minute,
+//		RowToFunc:  pgx.RowToStructByName[Job],
+//		Handle: func(ctx context.Context, jobs []Job) error {
+//			...
+//		},
+//	})
+func Poll[T any](ctx context.Context, pool *Pool, config PollConfig[T]) error {
+	if config.Interval <= 0 {
+		return errors.New("pgxpool: PollConfig.Interval must be greater than 0")
+	}
+	if config.RowToFunc == nil {
+		return errors.New("pgxpool: PollConfig.RowToFunc must not be nil")
+	}
+	if config.Handle == nil {
+		return errors.New("pgxpool: PollConfig.Handle must not be nil")
+	}
+
+	delay := config.Interval
+	for {
+		err := pollOnce(ctx, pool, &config)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err != nil {
+			if config.OnError != nil {
+				config.OnError(err)
+			}
+			delay *= 2
+			if delay > config.MaxBackoff {
+				delay = max(config.MaxBackoff, config.Interval)
+			}
+		} else {
+			delay = config.Interval
+		}
+
+		wait := delay
+		if config.Jitter > 0 {
+			wait += time.Duration(rand.Int63n(int64(config.Jitter)))
+		}
+
+		timer := pool.clock.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C():
+		}
+	}
+}
+
+func pollOnce[T any](ctx context.Context, pool *Pool, config *PollConfig[T]) error {
+	rows, err := pool.Query(ctx, config.SQL, config.Args...)
+	if err != nil {
+		return err
+	}
+
+	// CollectRows closes rows which releases the connection before Handle is called.
+	values, err := pgx.CollectRows(rows, config.RowToFunc)
+	if err != nil {
+		return err
+	}
+
+	return config.Handle(ctx, values)
+}
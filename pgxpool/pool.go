@@ -2,7 +2,9 @@ package pgxpool
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"math/rand"
 	"runtime"
 	"strconv"
@@ -12,6 +14,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/puddle/v2"
 )
 
@@ -20,13 +23,35 @@ var defaultMinConns = int32(0)
 var defaultMaxConnLifetime = time.Hour
 var defaultMaxConnIdleTime = time.Minute * 30
 var defaultHealthCheckPeriod = time.Minute
+var defaultStatHistorySize = 60
 
 type connResource struct {
-	conn       *pgx.Conn
-	conns      []Conn
-	poolRows   []poolRow
-	poolRowss  []poolRows
-	maxAgeTime time.Time
+	conn      *pgx.Conn
+	conns     []Conn
+	poolRows  []poolRow
+	poolRowss []poolRows
+
+	// createdAt is when the connection was established. lifetimeJitter is the random duration added to MaxConnLifetime
+	// for this connection.
+	createdAt      time.Time
+	lifetimeJitter time.Duration
+
+	// usageClass is the usage class the connection was last acquired for. statementTimeout is the statement_timeout
+	// set on the connection for that usage class.
+	usageClass       *usageClass
+	statementTimeout time.Duration
+
+	// destroyReason is the reason the connection was destroyed. It is only valid if destroyReasonSet is true.
+	destroyReason    ConnDestroyReason
+	destroyReasonSet bool
+
+	// cacheGeneration is the value of Pool.cacheGeneration when the statement caches of the connection were last
+	// invalidated.
+	cacheGeneration int64
+
+	// trimmedLastUsedNanotime is the last used time of the resource when the connection was last trimmed. The connection
+	// has not been used since it was trimmed if it equals the current last used time.
+	trimmedLastUsedNanotime int64
 }
 
 func (cr *connResource) getConn(p *Pool, res *puddle.Resource[*connResource]) *Conn {
@@ -39,6 +64,7 @@ func (cr *connResource) getConn(p *Pool, res *puddle.Resource[*connResource]) *C
 
 	c.res = res
 	c.p = p
+	c.usageClass = nil
 
 	return c
 }
@@ -78,6 +104,7 @@ type Pool struct {
 	newConnsCount        int64
 	lifetimeDestroyCount int64
 	idleDestroyCount     int64
+	idleTrimCount        int64
 
 	p                     *puddle.Pool[*connResource]
 	config                *Config
@@ -86,22 +113,127 @@ type Pool struct {
 	beforeAcquire         func(context.Context, *pgx.Conn) bool
 	afterRelease          func(*pgx.Conn) bool
 	beforeClose           func(*pgx.Conn)
-	minConns              int32
-	maxConns              int32
-	maxConnLifetime       time.Duration
+	onConnDestroy         func(*pgx.Conn, ConnDestroyReason)
 	maxConnLifetimeJitter time.Duration
 	maxConnIdleTime       time.Duration
+	idleTrimTime          time.Duration
 	healthCheckPeriod     time.Duration
+	clock                 pgconn.Clock
+
+	constructLimiter *constructLimiter
+
+	// minConns, maxConns, and maxConnLifetime can be changed by UpdateConfig. connLimit enforces maxConns.
+	updateConfigMux sync.Mutex
+	minConns        atomic.Int32
+	maxConns        atomic.Int32
+	maxConnLifetime atomic.Int64
+	connLimit       *connLimit
+
+	// connLimitWaitCount and connLimitWaitDuration record the acquires that waited for connLimit.
+	connLimitWaitCount    atomic.Int64
+	connLimitWaitDuration atomic.Int64
 
 	healthCheckChan chan struct{}
 
+	statSamplePeriod time.Duration
+	statHistory      *statHistory
+
 	acquireTracer AcquireTracer
 	releaseTracer ReleaseTracer
 
+	admissionController AdmissionController
+
+	queryRewriter pgx.QueryRewriter
+
+	usageClasses map[UsageClass]*usageClass
+
+	statementThrottle *statementThrottle
+
+	leakDetector *leakDetector
+
+	// conns contains the *pgx.Conn of every connection of the pool. It is used to find out whether a connection set with
+	// WithConn or WithTx belongs to the pool.
+	conns sync.Map
+
+	// cacheGeneration is incremented by InvalidateStatementCaches.
+	cacheGeneration atomic.Int64
+	ddlBroker       *NotificationBroker
+
+	lazyConnect bool
+	acquired    atomic.Bool
+
 	closeOnce sync.Once
 	closeChan chan struct{}
 }
 
+// ConnDestroyReason is the reason a connection was destroyed by the pool. See Config.OnConnDestroy.
+type ConnDestroyReason int
+
+const (
+	// ConnDestroyPoolClosed means the connection was destroyed because the pool was closed.
+	ConnDestroyPoolClosed ConnDestroyReason = iota
+
+	// ConnDestroyPoolReset means the connection was destroyed by Pool.Reset.
+	ConnDestroyPoolReset
+
+	// ConnDestroyMaxLifetime means the connection was older than MaxConnLifetime.
+	ConnDestroyMaxLifetime
+
+	// ConnDestroyMaxIdleTime means the connection was idle for longer than MaxConnIdleTime.
+	ConnDestroyMaxIdleTime
+
+	// ConnDestroyPingFailed means the ping of a connection that had been idle failed when it was acquired.
+	ConnDestroyPingFailed
+
+	// ConnDestroyBeforeAcquire means BeforeAcquire returned false.
+	ConnDestroyBeforeAcquire
+
+	// ConnDestroyAfterRelease means AfterRelease returned false.
+	ConnDestroyAfterRelease
+
+	// ConnDestroyUnusableOnRelease means the connection was closed, busy, or in a transaction when it was released.
+	ConnDestroyUnusableOnRelease
+
+	// ConnDestroyUsageClassFailed means the settings of a usage class could not be applied to the connection.
+	ConnDestroyUsageClassFailed
+
+	// ConnDestroyIdleTrimFailed means trimming an idle connection failed. See Config.IdleTrimTime.
+	ConnDestroyIdleTrimFailed
+
+	// ConnDestroyMaxConns means the pool had more connections than MaxConns after MaxConns was reduced by
+	// Pool.UpdateConfig.
+	ConnDestroyMaxConns
+)
+
+func (r ConnDestroyReason) String() string {
+	switch r {
+	case ConnDestroyPoolClosed:
+		return "pool closed"
+	case ConnDestroyPoolReset:
+		return "pool reset"
+	case ConnDestroyMaxLifetime:
+		return "max lifetime"
+	case ConnDestroyMaxIdleTime:
+		return "max idle time"
+	case ConnDestroyPingFailed:
+		return "ping failed"
+	case ConnDestroyBeforeAcquire:
+		return "before acquire"
+	case ConnDestroyAfterRelease:
+		return "after release"
+	case ConnDestroyUnusableOnRelease:
+		return "unusable on release"
+	case ConnDestroyUsageClassFailed:
+		return "usage class failed"
+	case ConnDestroyIdleTrimFailed:
+		return "idle trim failed"
+	case ConnDestroyMaxConns:
+		return "max conns"
+	default:
+		return "unknown"
+	}
+}
+
 // Config is the configuration struct for creating a pool. It must be created by [ParseConfig] and then it can be
 // modified.
 type Config struct {
@@ -126,7 +258,12 @@ type Config struct {
 	// BeforeClose is called right before a connection is closed and removed from the pool.
 	BeforeClose func(*pgx.Conn)
 
-	// MaxConnLifetime is the duration since creation after which a connection will be automatically closed.
+	// OnConnDestroy is called right before a connection is closed and removed from the pool with the reason it was
+	// destroyed. It is called after BeforeClose.
+	OnConnDestroy func(conn *pgx.Conn, reason ConnDestroyReason)
+
+	// MaxConnLifetime is the duration since creation after which a connection will be automatically closed. It can be
+	// changed while the pool is in use with Pool.UpdateConfig.
 	MaxConnLifetime time.Duration
 
 	// MaxConnLifetimeJitter is the duration after MaxConnLifetime to randomly decide to close a connection.
@@ -136,17 +273,120 @@ type Config struct {
 	// MaxConnIdleTime is the duration after which an idle connection will be automatically closed by the health check.
 	MaxConnIdleTime time.Duration
 
-	// MaxConns is the maximum size of the pool. The default is the greater of 4 or runtime.NumCPU().
+	// IdleTrimTime, if greater than 0, is the duration after which the health check trims an idle connection to release
+	// server memory without destroying it. Trimming deallocates all prepared statements and discards temporary tables
+	// (DEALLOCATE ALL and DISCARD TEMP). The client side statement and description caches are cleared to match. A
+	// connection is trimmed at most once until it is used again. If trimming fails the connection is destroyed.
+	IdleTrimTime time.Duration
+
+	// MaxConns is the maximum size of the pool. The default is the greater of 4 or runtime.NumCPU(). It can be changed
+	// while the pool is in use with Pool.UpdateConfig.
 	MaxConns int32
 
 	// MinConns is the minimum size of the pool. After connection closes, the pool might dip below MinConns. A low
 	// number of MinConns might mean the pool is empty after MaxConnLifetime until the health check has a chance
-	// to create new connections.
+	// to create new connections. It can be changed while the pool is in use with Pool.UpdateConfig.
 	MinConns int32
 
-	// HealthCheckPeriod is the duration between checks of the health of idle connections.
+	// HealthCheckPeriod is the duration between checks of the health of idle connections. The health check and connection
+	// lifetimes are timed with ConnConfig.Clock. Connection idle time is always measured with the system clock.
 	HealthCheckPeriod time.Duration
 
+	// AdmissionController, if set, is called before the pool executes a query. It can delay or reject the query. See
+	// AdmissionController for details.
+	AdmissionController AdmissionController
+
+	// QueryRewriter, if set, is the default pgx.QueryRewriter for every query executed through the pool, an acquired
+	// Conn, or a Tx begun from the pool, including each query in a batch. A pgx.QueryRewriter passed explicitly as a
+	// query argument takes precedence. This can be used to consistently inject tenancy predicates or sharding hints.
+	// A batch with a query queued by pgx.Batch.QueueDependent fails as the QueryRewriter cannot be applied to it.
+	QueryRewriter pgx.QueryRewriter
+
+	// UsageClasses configures limits for connections acquired with a context tagged with WithUsageClass. This allows
+	// long-running report queries and latency-critical traffic to share a pool without the former starving the latter.
+	UsageClasses map[UsageClass]UsageClassConfig
+
+	// DDLInvalidationChannel, if set, is a channel the pool listens on with a NotificationBroker. When a notification is
+	// received the statement caches of all connections are invalidated with InvalidateStatementCaches. This prevents a
+	// storm of "cached plan must not change result type" errors after a migration. The conventional channel is
+	// DefaultDDLInvalidationChannel. Use DDLInvalidationTriggerSQL to install an event trigger that notifies the channel.
+	// The listening connection is held for the lifetime of the pool and is not reported by LeakDetectionThreshold. As
+	// notifications are lost while the listening connection is reconnecting, the caches are also invalidated whenever
+	// it starts listening.
+	DDLInvalidationChannel string
+
+	// MaxStatementConcurrency, if greater than 0, is the maximum number of connections that Exec, Query, and QueryRow
+	// may hold at the same time for statements with the same StatementFingerprint. This prevents a heavy query from
+	// occupying the entire pool.
+	MaxStatementConcurrency int32
+
+	// StatementConcurrencyLimits overrides MaxStatementConcurrency for the statements with the same StatementFingerprint
+	// as its keys. A limit of 0 removes the limit for the statement.
+	StatementConcurrencyLimits map[string]int32
+
+	// RejectThrottledStatements causes statements that are at their concurrency limit to fail immediately with
+	// ErrStatementThrottled instead of waiting for another execution of the statement to release its connection.
+	RejectThrottledStatements bool
+
+	// MaxConcurrentConstructs is the maximum number of connections that may be established at the same time. If 0, there
+	// is no limit. This and ConstructRate prevent a pool from overwhelming the server with connection attempts, e.g. after
+	// a server restart.
+	MaxConcurrentConstructs int32
+
+	// ConstructRate is the maximum number of connection attempts per second. If 0, there is no limit.
+	ConstructRate float64
+
+	// MinConstructBackoff, if greater than 0, is the delay before another connection attempt after an attempt fails. The
+	// delay doubles for each consecutive failure up to MaxConstructBackoff. A successful attempt resets the delay.
+	MinConstructBackoff time.Duration
+
+	// MaxConstructBackoff is the maximum delay between connection attempts after consecutive failures. If 0, the delay
+	// is not limited.
+	MaxConstructBackoff time.Duration
+
+	// OnConstructError is called when an attempt to establish a connection fails. consecutiveFailures is the number of
+	// attempts that have failed since the last successful attempt.
+	OnConstructError func(err error, consecutiveFailures int)
+
+	// PlanCache, if set, is shared by the type maps of all connections in the pool so a new connection does not have to
+	// plan scanning and encoding for queries that other connections have already executed. It is installed after
+	// AfterConnect, so types registered in AfterConnect are supported, but all connections must register the same types.
+	// See pgtype.Map.SetPlanCache.
+	PlanCache *pgtype.PlanCache
+
+	// MaxTxRetries is the number of times BeginTxFunc and BeginFunc retry a transaction that failed with an error for
+	// which pgx.IsRetryableTxError returns true. If 0, transactions are not retried.
+	MaxTxRetries int
+
+	// LazyConnect delays establishing MinConns connections until the first time a connection is acquired from the pool.
+	// This avoids the cost of connections that may never be used by applications that create many pools at startup, e.g.
+	// one per tenant.
+	LazyConnect bool
+
+	// StatSamplePeriod, if greater than 0, is the duration between samples of the pool statistics recorded in the
+	// background. The most recent StatHistorySize samples are returned by Pool.StatHistory. This allows analyzing pool
+	// saturation after an incident without an external metrics pipeline.
+	StatSamplePeriod time.Duration
+
+	// StatHistorySize is the number of samples kept when StatSamplePeriod is set. The default is 60.
+	StatHistorySize int
+
+	// LeakDetectionThreshold, if greater than 0, enables detection of leaked connections. The stack trace of the
+	// goroutine that acquired each connection is recorded, and connections that are held for longer than
+	// LeakDetectionThreshold without being released are reported to OnConnLeak and to the ConnConfig.Tracer if it
+	// implements ConnLeakTracer. At least one of them is required. This helps find the code responsible for exhausting
+	// the pool. Recording stack traces slows down Acquire, so this is primarily intended for debugging.
+	LeakDetectionThreshold time.Duration
+
+	// OnConnLeak is called with each connection that is held for longer than LeakDetectionThreshold. It is called at most
+	// once per acquisition from a background goroutine while the connection may still be in use, so it must not use the
+	// connection.
+	OnConnLeak func(LeakedConn)
+
+	// CompatibilityMode adapts the pool to a connection pooler between the pool and the PostgreSQL server. See
+	// CompatibilityModeTransactionPooler.
+	CompatibilityMode CompatibilityMode
+
 	createdByParseConfig bool // Used to enforce created by ParseConfig rule.
 }
 
@@ -157,6 +397,18 @@ func (c *Config) Copy() *Config {
 	newConfig := new(Config)
 	*newConfig = *c
 	newConfig.ConnConfig = c.ConnConfig.Copy()
+	if c.UsageClasses != nil {
+		newConfig.UsageClasses = make(map[UsageClass]UsageClassConfig, len(c.UsageClasses))
+		for k, v := range c.UsageClasses {
+			newConfig.UsageClasses[k] = v
+		}
+	}
+	if c.StatementConcurrencyLimits != nil {
+		newConfig.StatementConcurrencyLimits = make(map[string]int32, len(c.StatementConcurrencyLimits))
+		for k, v := range c.StatementConcurrencyLimits {
+			newConfig.StatementConcurrencyLimits[k] = v
+		}
+	}
 	return newConfig
 }
 
@@ -188,16 +440,67 @@ func NewWithConfig(ctx context.Context, config *Config) (*Pool, error) {
 		beforeAcquire:         config.BeforeAcquire,
 		afterRelease:          config.AfterRelease,
 		beforeClose:           config.BeforeClose,
-		minConns:              config.MinConns,
-		maxConns:              config.MaxConns,
-		maxConnLifetime:       config.MaxConnLifetime,
+		onConnDestroy:         config.OnConnDestroy,
 		maxConnLifetimeJitter: config.MaxConnLifetimeJitter,
 		maxConnIdleTime:       config.MaxConnIdleTime,
+		idleTrimTime:          config.IdleTrimTime,
 		healthCheckPeriod:     config.HealthCheckPeriod,
+		admissionController:   config.AdmissionController,
+		queryRewriter:         config.QueryRewriter,
+		lazyConnect:           config.LazyConnect,
+		clock:                 config.ConnConfig.Clock,
 		healthCheckChan:       make(chan struct{}, 1),
 		closeChan:             make(chan struct{}),
 	}
 
+	if p.clock == nil {
+		p.clock = pgconn.SystemClock()
+	}
+
+	if config.MaxConns < 1 {
+		return nil, errors.New("MaxConns must be >= 1")
+	}
+	if err := validateCompatibilityMode(config); err != nil {
+		return nil, err
+	}
+	p.minConns.Store(config.MinConns)
+	p.maxConns.Store(config.MaxConns)
+	p.maxConnLifetime.Store(int64(config.MaxConnLifetime))
+	p.connLimit = newConnLimit(config.MaxConns)
+
+	if config.StatSamplePeriod > 0 {
+		if config.StatHistorySize < 0 {
+			return nil, errors.New("StatHistorySize must not be negative")
+		}
+		statHistorySize := config.StatHistorySize
+		if statHistorySize == 0 {
+			statHistorySize = defaultStatHistorySize
+		}
+		p.statSamplePeriod = config.StatSamplePeriod
+		p.statHistory = newStatHistory(statHistorySize)
+	}
+
+	var err error
+	p.usageClasses, err = newUsageClasses(config.UsageClasses)
+	if err != nil {
+		return nil, err
+	}
+
+	p.constructLimiter, err = newConstructLimiter(config, p.clock)
+	if err != nil {
+		return nil, err
+	}
+
+	p.statementThrottle, err = newStatementThrottle(config)
+	if err != nil {
+		return nil, err
+	}
+
+	p.leakDetector, err = newLeakDetector(p, config)
+	if err != nil {
+		return nil, err
+	}
+
 	if t, ok := config.ConnConfig.Tracer.(AcquireTracer); ok {
 		p.acquireTracer = t
 	}
@@ -206,64 +509,33 @@ func NewWithConfig(ctx context.Context, config *Config) (*Pool, error) {
 		p.releaseTracer = t
 	}
 
-	var err error
 	p.p, err = puddle.NewPool(
 		&puddle.Config[*connResource]{
 			Constructor: func(ctx context.Context) (*connResource, error) {
-				atomic.AddInt64(&p.newConnsCount, 1)
-				connConfig := p.config.ConnConfig.Copy()
-
-				// Connection will continue in background even if Acquire is canceled. Ensure that a connect won't hang forever.
-				if connConfig.ConnectTimeout <= 0 {
-					connConfig.ConnectTimeout = 2 * time.Minute
-				}
-
-				if p.beforeConnect != nil {
-					if err := p.beforeConnect(ctx, connConfig); err != nil {
-						return nil, err
-					}
+				if pc, ok := ctx.Value(parkedConnKey{}).(*parkedConn); ok {
+					pc.parked = true
+					return pc.cr, nil
 				}
 
-				conn, err := pgx.ConnectConfig(ctx, connConfig)
-				if err != nil {
-					return nil, err
+				token, _ := ctx.Value(constructTokenKey{}).(*constructToken)
+				if !token.start() {
+					return nil, errConstructAbandoned
 				}
 
-				if p.afterConnect != nil {
-					err = p.afterConnect(ctx, conn)
-					if err != nil {
-						conn.Close(ctx)
-						return nil, err
+				cr, err := p.limitedConstruct(ctx)
+				if token.finish() {
+					// The Acquire gave up. Keep the connection for later use and only then release the token.
+					if err == nil {
+						p.parkConn(cr)
 					}
+					p.connLimit.release(1)
+					return nil, errConstructAbandoned
 				}
-
-				jitterSecs := rand.Float64() * config.MaxConnLifetimeJitter.Seconds()
-				maxAgeTime := time.Now().Add(config.MaxConnLifetime).Add(time.Duration(jitterSecs) * time.Second)
-
-				cr := &connResource{
-					conn:       conn,
-					conns:      make([]Conn, 64),
-					poolRows:   make([]poolRow, 64),
-					poolRowss:  make([]poolRows, 64),
-					maxAgeTime: maxAgeTime,
-				}
-
-				return cr, nil
+				return cr, err
 			},
-			Destructor: func(value *connResource) {
-				ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-				conn := value.conn
-				if p.beforeClose != nil {
-					p.beforeClose(conn)
-				}
-				conn.Close(ctx)
-				select {
-				case <-conn.PgConn().CleanupDone():
-				case <-ctx.Done():
-				}
-				cancel()
-			},
-			MaxSize: config.MaxConns,
+			Destructor: p.destructConn,
+			// The size of the pool is limited by connLimit so it can be changed by UpdateConfig.
+			MaxSize: math.MaxInt32,
 		},
 	)
 	if err != nil {
@@ -271,13 +543,139 @@ func NewWithConfig(ctx context.Context, config *Config) (*Pool, error) {
 	}
 
 	go func() {
-		p.createIdleResources(ctx, int(p.minConns))
+		if !p.lazyConnect {
+			p.createIdleResources(ctx, int(p.minConns.Load()))
+		}
 		p.backgroundHealthCheck()
 	}()
 
+	if p.statHistory != nil {
+		go p.backgroundStatSample()
+	}
+
+	if p.leakDetector != nil {
+		go p.leakDetector.run(p.closeChan)
+	}
+
+	if config.DDLInvalidationChannel != "" {
+		p.startDDLInvalidation(config.DDLInvalidationChannel)
+	}
+
 	return p, nil
 }
 
+// limitedConstruct establishes a new connection for the pool subject to the construct concurrency, rate, and backoff
+// limits.
+func (p *Pool) limitedConstruct(ctx context.Context) (*connResource, error) {
+	if err := p.constructLimiter.wait(ctx); err != nil {
+		return nil, err
+	}
+	cr, err := p.construct(ctx)
+	p.constructLimiter.done(err)
+	if err != nil {
+		return nil, &ConstructError{Cause: err}
+	}
+	return cr, nil
+}
+
+// parkedConn is a connection constructed for an Acquire that gave up. parkConn adds it to the puddle.Pool as an idle
+// resource.
+type parkedConn struct {
+	cr     *connResource
+	parked bool
+}
+
+type parkedConnKey struct{}
+
+// errConstructAbandoned is returned to the puddle.Pool by a construct for an Acquire that gave up. No one receives it.
+var errConstructAbandoned = errors.New("acquire canceled while constructing connection")
+
+// parkConn adds cr to the pool as an idle connection. It is closed if the pool is closed.
+func (p *Pool) parkConn(cr *connResource) {
+	pc := &parkedConn{cr: cr}
+	err := p.p.CreateResource(context.WithValue(context.Background(), parkedConnKey{}, pc))
+	// The puddle.Pool destroys the connection if it was closed after the constructor was called.
+	if err != nil && !pc.parked {
+		p.destructConn(cr)
+	}
+}
+
+// destructConn closes the connection of a resource that is destroyed.
+func (p *Pool) destructConn(value *connResource) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	conn := value.conn
+	p.conns.Delete(conn)
+	if p.beforeClose != nil {
+		p.beforeClose(conn)
+	}
+	if p.onConnDestroy != nil {
+		p.onConnDestroy(conn, p.connDestroyReason(value))
+	}
+	conn.Close(ctx)
+	select {
+	case <-conn.PgConn().CleanupDone():
+	case <-ctx.Done():
+	}
+	cancel()
+}
+
+// construct establishes a new connection for the pool.
+func (p *Pool) construct(ctx context.Context) (*connResource, error) {
+	atomic.AddInt64(&p.newConnsCount, 1)
+	cacheGeneration := p.cacheGeneration.Load()
+	connConfig := p.config.ConnConfig.Copy()
+
+	// Connection will continue in background even if Acquire is canceled. Ensure that a connect won't hang forever.
+	if connConfig.ConnectTimeout <= 0 {
+		connConfig.ConnectTimeout = 2 * time.Minute
+	}
+
+	applyCompatibilityMode(p.config.CompatibilityMode, connConfig)
+
+	if p.beforeConnect != nil {
+		if err := p.beforeConnect(ctx, connConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	conn, err := pgx.ConnectConfig(ctx, connConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkCompatibilityMode(p.config.CompatibilityMode, conn); err != nil {
+		conn.Close(ctx)
+		return nil, err
+	}
+
+	if p.afterConnect != nil {
+		err = p.afterConnect(ctx, conn)
+		if err != nil {
+			conn.Close(ctx)
+			return nil, err
+		}
+	}
+
+	if p.config.PlanCache != nil {
+		conn.TypeMap().SetPlanCache(p.config.PlanCache)
+	}
+
+	jitterSecs := rand.Float64() * p.maxConnLifetimeJitter.Seconds()
+
+	cr := &connResource{
+		conn:            conn,
+		cacheGeneration: cacheGeneration,
+		conns:           make([]Conn, 64),
+		poolRows:        make([]poolRow, 64),
+		poolRowss:       make([]poolRows, 64),
+		createdAt:       p.clock.Now(),
+		lifetimeJitter:  time.Duration(jitterSecs) * time.Second,
+	}
+	p.conns.Store(conn, struct{}{})
+
+	return cr, nil
+}
+
 // ParseConfig builds a Config from connString. It parses connString with the same behavior as [pgx.ParseConfig] with the
 // addition of the following variables:
 //
@@ -285,8 +683,14 @@ func NewWithConfig(ctx context.Context, config *Config) (*Pool, error) {
 //   - pool_min_conns: integer 0 or greater (default 0)
 //   - pool_max_conn_lifetime: duration string (default 1 hour)
 //   - pool_max_conn_idle_time: duration string (default 30 minutes)
+//   - pool_idle_trim_time: duration string (default 0, no trimming)
 //   - pool_health_check_period: duration string (default 1 minute)
 //   - pool_max_conn_lifetime_jitter: duration string (default 0)
+//   - pool_max_concurrent_constructs: integer 0 or greater (default 0, no limit)
+//   - pool_construct_rate: number of connection attempts per second 0 or greater (default 0, no limit)
+//   - pool_min_construct_backoff: duration string (default 0, no backoff)
+//   - pool_max_construct_backoff: duration string (default 0, no limit)
+//   - pool_compatibility_mode: default or transaction_pooler (default default)
 //
 // See Config for definitions of these arguments.
 //
@@ -356,6 +760,15 @@ func ParseConfig(connString string) (*Config, error) {
 		config.MaxConnIdleTime = defaultMaxConnIdleTime
 	}
 
+	if s, ok := config.ConnConfig.Config.RuntimeParams["pool_idle_trim_time"]; ok {
+		delete(connConfig.Config.RuntimeParams, "pool_idle_trim_time")
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pool_idle_trim_time: %w", err)
+		}
+		config.IdleTrimTime = d
+	}
+
 	if s, ok := config.ConnConfig.Config.RuntimeParams["pool_health_check_period"]; ok {
 		delete(connConfig.Config.RuntimeParams, "pool_health_check_period")
 		d, err := time.ParseDuration(s)
@@ -376,6 +789,57 @@ func ParseConfig(connString string) (*Config, error) {
 		config.MaxConnLifetimeJitter = d
 	}
 
+	if s, ok := config.ConnConfig.Config.RuntimeParams["pool_max_concurrent_constructs"]; ok {
+		delete(connConfig.Config.RuntimeParams, "pool_max_concurrent_constructs")
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse pool_max_concurrent_constructs: %w", err)
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("pool_max_concurrent_constructs too small: %d", n)
+		}
+		config.MaxConcurrentConstructs = int32(n)
+	}
+
+	if s, ok := config.ConnConfig.Config.RuntimeParams["pool_construct_rate"]; ok {
+		delete(connConfig.Config.RuntimeParams, "pool_construct_rate")
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse pool_construct_rate: %w", err)
+		}
+		if n < 0 || math.IsNaN(n) {
+			return nil, fmt.Errorf("invalid pool_construct_rate: %v", n)
+		}
+		config.ConstructRate = n
+	}
+
+	if s, ok := config.ConnConfig.Config.RuntimeParams["pool_min_construct_backoff"]; ok {
+		delete(connConfig.Config.RuntimeParams, "pool_min_construct_backoff")
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pool_min_construct_backoff: %w", err)
+		}
+		config.MinConstructBackoff = d
+	}
+
+	if s, ok := config.ConnConfig.Config.RuntimeParams["pool_max_construct_backoff"]; ok {
+		delete(connConfig.Config.RuntimeParams, "pool_max_construct_backoff")
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pool_max_construct_backoff: %w", err)
+		}
+		config.MaxConstructBackoff = d
+	}
+
+	if s, ok := config.ConnConfig.Config.RuntimeParams["pool_compatibility_mode"]; ok {
+		delete(connConfig.Config.RuntimeParams, "pool_compatibility_mode")
+		mode, err := parseCompatibilityMode(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pool_compatibility_mode: %w", err)
+		}
+		config.CompatibilityMode = mode
+	}
+
 	return config, nil
 }
 
@@ -384,19 +848,48 @@ func ParseConfig(connString string) (*Config, error) {
 func (p *Pool) Close() {
 	p.closeOnce.Do(func() {
 		close(p.closeChan)
+		p.connLimit.close()
+		if p.ddlBroker != nil {
+			p.ddlBroker.Close()
+		}
 		p.p.Close()
 	})
 }
 
+// destroy destroys res and records reason for OnConnDestroy.
+func (p *Pool) destroy(res *puddle.Resource[*connResource], reason ConnDestroyReason) {
+	cr := res.Value()
+	cr.destroyReason = reason
+	cr.destroyReasonSet = true
+	res.Destroy()
+}
+
+// connDestroyReason returns the reason cr is being destroyed. Connections destroyed without a recorded reason were
+// destroyed by Close or Reset.
+func (p *Pool) connDestroyReason(cr *connResource) ConnDestroyReason {
+	if cr.destroyReasonSet {
+		return cr.destroyReason
+	}
+
+	select {
+	case <-p.closeChan:
+		return ConnDestroyPoolClosed
+	default:
+		return ConnDestroyPoolReset
+	}
+}
+
 func (p *Pool) isExpired(res *puddle.Resource[*connResource]) bool {
-	return time.Now().After(res.Value().maxAgeTime)
+	cr := res.Value()
+	maxAgeTime := cr.createdAt.Add(time.Duration(p.maxConnLifetime.Load())).Add(cr.lifetimeJitter)
+	return p.clock.Now().After(maxAgeTime)
 }
 
 func (p *Pool) triggerHealthCheck() {
 	go func() {
 		// Destroy is asynchronous so we give it time to actually remove itself from
 		// the pool otherwise we might try to check the pool size too soon
-		time.Sleep(500 * time.Millisecond)
+		<-p.clock.NewTimer(500 * time.Millisecond).C()
 		select {
 		case p.healthCheckChan <- struct{}{}:
 		default:
@@ -405,16 +898,31 @@ func (p *Pool) triggerHealthCheck() {
 }
 
 func (p *Pool) backgroundHealthCheck() {
-	ticker := time.NewTicker(p.healthCheckPeriod)
-	defer ticker.Stop()
+	timer := p.clock.NewTimer(p.healthCheckPeriod)
+	defer timer.Stop()
 	for {
 		select {
 		case <-p.closeChan:
 			return
 		case <-p.healthCheckChan:
 			p.checkHealth()
-		case <-ticker.C:
+		case <-timer.C():
 			p.checkHealth()
+			timer.Reset(p.healthCheckPeriod)
+		}
+	}
+}
+
+func (p *Pool) backgroundStatSample() {
+	timer := p.clock.NewTimer(p.statSamplePeriod)
+	defer timer.Stop()
+	for {
+		select {
+		case <-p.closeChan:
+			return
+		case now := <-timer.C():
+			p.statHistory.add(StatSample{Time: now, Stat: p.Stat()})
+			timer.Reset(p.statSamplePeriod)
 		}
 	}
 }
@@ -433,10 +941,12 @@ func (p *Pool) checkHealth() {
 		}
 		// Technically Destroy is asynchronous but 500ms should be enough for it to
 		// remove it from the underlying pool
+		timer := p.clock.NewTimer(500 * time.Millisecond)
 		select {
 		case <-p.closeChan:
+			timer.Stop()
 			return
-		case <-time.After(500 * time.Millisecond):
+		case <-timer.C():
 		}
 	}
 }
@@ -445,34 +955,102 @@ func (p *Pool) checkHealth() {
 // it's idle or too old, and returns true if any were destroyed
 func (p *Pool) checkConnsHealth() bool {
 	var destroyed bool
+	minConns := p.minConns.Load()
+	maxConns := p.maxConns.Load()
 	totalConns := p.Stat().TotalConns()
-	resources := p.p.AcquireAllIdle()
+	resources := p.acquireAllIdle()
 	for _, res := range resources {
 		// We're okay going under minConns if the lifetime is up
-		if p.isExpired(res) && totalConns >= p.minConns {
+		if p.isExpired(res) && totalConns >= minConns {
 			atomic.AddInt64(&p.lifetimeDestroyCount, 1)
-			res.Destroy()
+			p.destroy(res, ConnDestroyMaxLifetime)
 			destroyed = true
 			// Since Destroy is async we manually decrement totalConns.
 			totalConns--
-		} else if res.IdleDuration() > p.maxConnIdleTime && totalConns > p.minConns {
+		} else if totalConns > maxConns {
+			// MaxConns was reduced by UpdateConfig. Connections in use are culled once they become idle.
+			p.destroy(res, ConnDestroyMaxConns)
+			destroyed = true
+			totalConns--
+		} else if res.IdleDuration() > p.maxConnIdleTimeFor(res) && totalConns > minConns {
 			atomic.AddInt64(&p.idleDestroyCount, 1)
-			res.Destroy()
+			p.destroy(res, ConnDestroyMaxIdleTime)
 			destroyed = true
 			// Since Destroy is async we manually decrement totalConns.
 			totalConns--
+		} else if p.needsIdleTrim(res) {
+			if err := p.trimIdleConn(res); err != nil {
+				p.destroy(res, ConnDestroyIdleTrimFailed)
+				destroyed = true
+				totalConns--
+			} else {
+				res.ReleaseUnused()
+			}
 		} else {
 			res.ReleaseUnused()
 		}
 	}
+	p.connLimit.release(int32(len(resources)))
 	return destroyed
 }
 
+// acquireAllIdle acquires all idle resources and holds a connLimit token for each of them. The tokens are taken
+// regardless of the limit as the connections already exist. They are taken before the resources are acquired so a
+// concurrent Acquire cannot construct a connection in their place.
+func (p *Pool) acquireAllIdle() []*puddle.Resource[*connResource] {
+	reserved := p.p.Stat().IdleResources()
+	p.connLimit.add(reserved)
+
+	resources := p.p.AcquireAllIdle()
+	if n := int32(len(resources)); n > reserved {
+		p.connLimit.add(n - reserved)
+	} else if n < reserved {
+		p.connLimit.release(reserved - n)
+	}
+
+	return resources
+}
+
+// needsIdleTrim returns true if res has been idle for longer than IdleTrimTime and has not been trimmed since it was
+// last used.
+func (p *Pool) needsIdleTrim(res *puddle.Resource[*connResource]) bool {
+	return p.idleTrimTime > 0 &&
+		res.IdleDuration() > p.idleTrimTime &&
+		res.Value().trimmedLastUsedNanotime != res.LastUsedNanotime()
+}
+
+// trimIdleConn releases the server memory held by the prepared statements and temporary tables of an idle connection.
+func (p *Pool) trimIdleConn(res *puddle.Resource[*connResource]) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	cr := res.Value()
+	err := cr.conn.DeallocateAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = cr.conn.Exec(ctx, "discard temp")
+	if err != nil {
+		return err
+	}
+
+	atomic.AddInt64(&p.idleTrimCount, 1)
+	cr.trimmedLastUsedNanotime = res.LastUsedNanotime()
+
+	return nil
+}
+
 func (p *Pool) checkMinConns() error {
+	// A lazy pool does not establish connections until it has been used.
+	if p.lazyConnect && !p.acquired.Load() {
+		return nil
+	}
+
 	// TotalConns can include ones that are being destroyed but we should have
 	// sleep(500ms) around all of the destroys to help prevent that from throwing
 	// off this check
-	toCreate := p.minConns - p.Stat().TotalConns()
+	toCreate := p.minConns.Load() - p.Stat().TotalConns()
 	if toCreate > 0 {
 		return p.createIdleResources(context.Background(), int(toCreate))
 	}
@@ -487,7 +1065,13 @@ func (p *Pool) createIdleResources(parentCtx context.Context, targetResources in
 
 	for i := 0; i < targetResources; i++ {
 		go func() {
+			// The pool is full if there is no connLimit token.
+			if !p.connLimit.tryAcquire() {
+				errs <- nil
+				return
+			}
 			err := p.p.CreateResource(ctx)
+			p.connLimit.release(1)
 			// Ignore ErrNotAvailable since it means that the pool has become full since we started creating resource.
 			if err == puddle.ErrNotAvailable {
 				err = nil
@@ -508,7 +1092,11 @@ func (p *Pool) createIdleResources(parentCtx context.Context, targetResources in
 	return firstError
 }
 
-// Acquire returns a connection (*Conn) from the Pool
+// Acquire returns a connection (*Conn) from the Pool.
+//
+// Errors can be distinguished with errors.Is and errors.As. ErrPoolClosed is returned if the pool is closed. An error
+// wrapping ErrAcquireTimeout is returned if the deadline of ctx is exceeded. A *ConstructError is returned if a new
+// connection could not be established.
 func (p *Pool) Acquire(ctx context.Context) (c *Conn, err error) {
 	if p.acquireTracer != nil {
 		ctx = p.acquireTracer.TraceAcquireStart(ctx, p, TraceAcquireStartData{})
@@ -521,10 +1109,37 @@ func (p *Pool) Acquire(ctx context.Context) (c *Conn, err error) {
 		}()
 	}
 
+	if p.lazyConnect && !p.acquired.Load() && p.acquired.CompareAndSwap(false, true) {
+		p.triggerHealthCheck()
+	}
+
+	select {
+	case <-p.closeChan:
+		return nil, ErrPoolClosed
+	default:
+	}
+
+	uc, err := p.acquireUsageClassSlot(ctx)
+	if err != nil {
+		return nil, acquireError(ctx, err)
+	}
+
+	startTime := p.clock.Now()
+	waited, err := p.connLimit.acquire(ctx)
+	if waited {
+		p.connLimitWaitCount.Add(1)
+		p.connLimitWaitDuration.Add(int64(p.clock.Now().Sub(startTime)))
+	}
+	if err != nil {
+		uc.releaseSlot()
+		return nil, acquireError(ctx, err)
+	}
+
 	for {
-		res, err := p.p.Acquire(ctx)
+		res, err := p.acquireResource(ctx)
 		if err != nil {
-			return nil, err
+			uc.releaseSlot()
+			return nil, acquireError(ctx, err)
 		}
 
 		cr := res.Value()
@@ -532,19 +1147,67 @@ func (p *Pool) Acquire(ctx context.Context) (c *Conn, err error) {
 		if res.IdleDuration() > time.Second {
 			err := cr.conn.Ping(ctx)
 			if err != nil {
-				res.Destroy()
+				p.destroy(res, ConnDestroyPingFailed)
 				continue
 			}
 		}
 
 		if p.beforeAcquire == nil || p.beforeAcquire(ctx, cr.conn) {
-			return cr.getConn(p, res), nil
+			err := p.applyUsageClass(ctx, res, uc)
+			if err != nil {
+				p.destroy(res, ConnDestroyUsageClassFailed)
+				p.connLimit.release(1)
+				uc.releaseSlot()
+				return nil, err
+			}
+
+			p.refreshStatementCaches(cr)
+			c := cr.getConn(p, res)
+			c.usageClass = uc
+			p.leakDetector.track(c)
+			return c, nil
 		}
 
-		res.Destroy()
+		p.destroy(res, ConnDestroyBeforeAcquire)
 	}
 }
 
+// acquireResource acquires a resource from the puddle.Pool for an Acquire that holds a connLimit token. The token is
+// handed to the construct if a new connection is established. If an error is returned the token has been released or
+// is released by the construct when it finishes.
+func (p *Pool) acquireResource(ctx context.Context) (*puddle.Resource[*connResource], error) {
+	if err := ctx.Err(); err != nil {
+		p.connLimit.release(1)
+		return nil, err
+	}
+
+	token := &constructToken{}
+
+	// The puddle.Pool is only canceled if the construct has not finished. Otherwise, the puddle.Pool would release the
+	// new connection to the idle resources concurrently with the token being released.
+	puddleCtx, cancel := context.WithCancel(context.WithValue(context.WithoutCancel(ctx), constructTokenKey{}, token))
+	defer cancel()
+	stop := context.AfterFunc(ctx, func() {
+		if token.abandon() {
+			cancel()
+		}
+	})
+
+	res, err := p.p.Acquire(puddleCtx)
+	stop()
+	if err != nil {
+		if !token.ownedByConstruct() {
+			p.connLimit.release(1)
+		}
+		if ctx.Err() != nil && errors.Is(err, context.Canceled) {
+			err = ctx.Err()
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
 // AcquireFunc acquires a *Conn and calls f with that *Conn. ctx will only affect the Acquire. It has no effect on the
 // call of f. The return value is either an error acquiring the *Conn or the return value of f. The *Conn is
 // automatically released after the call of f.
@@ -561,14 +1224,18 @@ func (p *Pool) AcquireFunc(ctx context.Context, f func(*Conn) error) error {
 // AcquireAllIdle atomically acquires all currently idle connections. Its intended use is for health check and
 // keep-alive functionality. It does not update pool statistics.
 func (p *Pool) AcquireAllIdle(ctx context.Context) []*Conn {
-	resources := p.p.AcquireAllIdle()
+	resources := p.acquireAllIdle()
 	conns := make([]*Conn, 0, len(resources))
 	for _, res := range resources {
 		cr := res.Value()
 		if p.beforeAcquire == nil || p.beforeAcquire(ctx, cr.conn) {
-			conns = append(conns, cr.getConn(p, res))
+			p.refreshStatementCaches(cr)
+			c := cr.getConn(p, res)
+			p.leakDetector.track(c)
+			conns = append(conns, c)
 		} else {
-			res.Destroy()
+			p.destroy(res, ConnDestroyBeforeAcquire)
+			p.connLimit.release(1)
 		}
 	}
 
@@ -584,25 +1251,61 @@ func (p *Pool) Reset() {
 	p.p.Reset()
 }
 
-// Config returns a copy of config that was used to initialize this pool.
-func (p *Pool) Config() *Config { return p.config.Copy() }
+// Config returns a copy of config that was used to initialize this pool. MaxConns, MinConns, and MaxConnLifetime are
+// their current values if they were changed by UpdateConfig.
+func (p *Pool) Config() *Config {
+	config := p.config.Copy()
+	config.MaxConns = p.maxConns.Load()
+	config.MinConns = p.minConns.Load()
+	config.MaxConnLifetime = time.Duration(p.maxConnLifetime.Load())
+	return config
+}
 
 // Stat returns a pgxpool.Stat struct with a snapshot of Pool statistics.
 func (p *Pool) Stat() *Stat {
 	return &Stat{
-		s:                    p.p.Stat(),
-		newConnsCount:        atomic.LoadInt64(&p.newConnsCount),
-		lifetimeDestroyCount: atomic.LoadInt64(&p.lifetimeDestroyCount),
-		idleDestroyCount:     atomic.LoadInt64(&p.idleDestroyCount),
+		s:                     p.p.Stat(),
+		maxConns:              p.maxConns.Load(),
+		connLimitWaitCount:    p.connLimitWaitCount.Load(),
+		connLimitWaitDuration: time.Duration(p.connLimitWaitDuration.Load()),
+		newConnsCount:         atomic.LoadInt64(&p.newConnsCount),
+		lifetimeDestroyCount:  atomic.LoadInt64(&p.lifetimeDestroyCount),
+		idleDestroyCount:      atomic.LoadInt64(&p.idleDestroyCount),
+		idleTrimCount:         atomic.LoadInt64(&p.idleTrimCount),
 	}
 }
 
+// StatHistory returns the pool statistics recorded by the background sampler from oldest to newest. It returns nil if
+// Config.StatSamplePeriod is not set.
+func (p *Pool) StatHistory() []StatSample {
+	if p.statHistory == nil {
+		return nil
+	}
+	return p.statHistory.all()
+}
+
 // Exec acquires a connection from the Pool and executes the given SQL.
 // SQL can be either a prepared statement name or an SQL string.
 // Arguments should be referenced positionally from the SQL string as $1, $2, etc.
 // The acquired connection is returned to the pool when the Exec function returns.
+//
+// If ctx was created with WithConn or WithTx, the SQL is executed on that connection or transaction instead. The same
+// applies to Query, QueryRow, SendBatch, and CopyFrom.
 func (p *Pool) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
-	c, err := p.Acquire(ctx)
+	if q, ok := p.contextQuerierFromContext(ctx); ok {
+		return q.Exec(ctx, sql, arguments...)
+	}
+
+	ctx, sql, err := p.config.ConnConfig.ApplySQLMiddleware(ctx, sql)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+
+	if err := p.admit(ctx, sql, nil); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+
+	c, err := p.acquireForStatement(ctx, sql)
 	if err != nil {
 		return pgconn.CommandTag{}, err
 	}
@@ -622,7 +1325,20 @@ func (p *Pool) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.C
 // QueryResultFormatsByOID may be used as the first args to control exactly how the query is executed. This is rarely
 // needed. See the documentation for those types for details.
 func (p *Pool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
-	c, err := p.Acquire(ctx)
+	if q, ok := p.contextQuerierFromContext(ctx); ok {
+		return q.Query(ctx, sql, args...)
+	}
+
+	ctx, sql, err := p.config.ConnConfig.ApplySQLMiddleware(ctx, sql)
+	if err != nil {
+		return errRows{err: err}, err
+	}
+
+	if err := p.admit(ctx, sql, nil); err != nil {
+		return errRows{err: err}, err
+	}
+
+	c, err := p.acquireForStatement(ctx, sql)
 	if err != nil {
 		return errRows{err: err}, err
 	}
@@ -649,7 +1365,20 @@ func (p *Pool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, er
 // QueryResultFormatsByOID may be used as the first args to control exactly how the query is executed. This is rarely
 // needed. See the documentation for those types for details.
 func (p *Pool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
-	c, err := p.Acquire(ctx)
+	if q, ok := p.contextQuerierFromContext(ctx); ok {
+		return q.QueryRow(ctx, sql, args...)
+	}
+
+	ctx, sql, err := p.config.ConnConfig.ApplySQLMiddleware(ctx, sql)
+	if err != nil {
+		return errRow{err: err}
+	}
+
+	if err := p.admit(ctx, sql, nil); err != nil {
+		return errRow{err: err}
+	}
+
+	c, err := p.acquireForStatement(ctx, sql)
 	if err != nil {
 		return errRow{err: err}
 	}
@@ -659,6 +1388,19 @@ func (p *Pool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
 }
 
 func (p *Pool) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	if q, ok := p.contextQuerierFromContext(ctx); ok {
+		return q.SendBatch(ctx, b)
+	}
+
+	ctx, b, err := p.config.ConnConfig.ApplySQLMiddlewareToBatch(ctx, b)
+	if err != nil {
+		return errBatchResults{err: err}
+	}
+
+	if err := p.admit(ctx, "", b); err != nil {
+		return errBatchResults{err: err}
+	}
+
 	c, err := p.Acquire(ctx)
 	if err != nil {
 		return errBatchResults{err: err}
@@ -681,6 +1423,10 @@ func (p *Pool) Begin(ctx context.Context) (pgx.Tx, error) {
 // *pgxpool.Tx is returned, which implements the pgx.Tx interface.
 // Commit or Rollback must be called on the returned transaction to finalize the transaction block.
 func (p *Pool) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	if err := p.admit(ctx, "", nil); err != nil {
+		return nil, err
+	}
+
 	c, err := p.Acquire(ctx)
 	if err != nil {
 		return nil, err
@@ -695,7 +1441,45 @@ func (p *Pool) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, er
 	return &Tx{t: t, c: c}, nil
 }
 
+// BeginFunc acquires a connection from the Pool, starts a transaction, and calls fn. See BeginTxFunc.
+func (p *Pool) BeginFunc(ctx context.Context, fn func(pgx.Tx) error) error {
+	return p.BeginTxFunc(ctx, pgx.TxOptions{}, fn)
+}
+
+// BeginTxFunc acquires a connection from the Pool, starts a transaction with pgx.TxOptions determining the transaction
+// mode, and calls fn. If fn does not return an error then the transaction is committed. If fn returns an error the
+// transaction is rolled back. The connection is released before BeginTxFunc returns. The context will be used when
+// executing the transaction control statements (BEGIN, ROLLBACK, and COMMIT) but does not otherwise affect the
+// execution of fn.
+//
+// If the transaction fails with a serialization failure (SQLSTATE 40001), a deadlock (SQLSTATE 40P01), or
+// pgx.ErrPlanInvalidated, fn is called again in a new transaction up to Config.MaxTxRetries times. fn must be safe to
+// call more than once when retries are enabled. If txOptions.RetrySerializable is set, it determines the retries
+// instead of Config.MaxTxRetries.
+func (p *Pool) BeginTxFunc(ctx context.Context, txOptions pgx.TxOptions, fn func(pgx.Tx) error) error {
+	// pgx.BeginTxFunc retries by itself when txOptions.RetrySerializable is set. Retrying here as well would multiply the
+	// attempts.
+	if txOptions.RetrySerializable.MaxAttempts > 1 {
+		return pgx.BeginTxFunc(ctx, p, txOptions, fn)
+	}
+
+	for retries := 0; ; retries++ {
+		err := pgx.BeginTxFunc(ctx, p, txOptions, fn)
+		if err == nil || retries >= p.config.MaxTxRetries || !pgx.IsRetryableTxError(err) || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
 func (p *Pool) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	if q, ok := p.contextQuerierFromContext(ctx); ok {
+		return q.CopyFrom(ctx, tableName, columnNames, rowSrc)
+	}
+
+	if err := p.admit(ctx, "", nil); err != nil {
+		return 0, err
+	}
+
 	c, err := p.Acquire(ctx)
 	if err != nil {
 		return 0, err
@@ -715,3 +1499,43 @@ func (p *Pool) Ping(ctx context.Context) error {
 	defer c.Release()
 	return c.Ping(ctx)
 }
+
+// PingLight acquires a connection from the Pool and pings it with pgconn.PgConn.PingLight. This does not execute a
+// statement, so it is cheaper than Ping but does not verify that the server can execute statements.
+func (p *Pool) PingLight(ctx context.Context) error {
+	c, err := p.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Release()
+	return c.PingLight(ctx)
+}
+
+// withQueryRewriter returns args with the pool's default QueryRewriter prepended. A QueryRewriter already in args will
+// take precedence as query option arguments are processed in order.
+func (p *Pool) withQueryRewriter(args []any) []any {
+	if p.queryRewriter == nil {
+		return args
+	}
+
+	newArgs := make([]any, 0, len(args)+1)
+	newArgs = append(newArgs, p.queryRewriter)
+	return append(newArgs, args...)
+}
+
+// batchWithQueryRewriter returns a copy of b with the pool's default QueryRewriter prepended to the arguments of each
+// query. b is not modified so it can be sent again. If there is no default QueryRewriter b itself is returned.
+func (p *Pool) batchWithQueryRewriter(b *pgx.Batch) *pgx.Batch {
+	if p.queryRewriter == nil {
+		return b
+	}
+
+	rewritten := &pgx.Batch{QueuedQueries: make([]*pgx.QueuedQuery, len(b.QueuedQueries))}
+	for i, qq := range b.QueuedQueries {
+		qqCopy := *qq
+		qqCopy.Arguments = p.withQueryRewriter(qq.Arguments)
+		rewritten.QueuedQueries[i] = &qqCopy
+	}
+
+	return rewritten
+}
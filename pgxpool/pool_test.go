@@ -4,14 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"os"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/jackc/puddle/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -51,6 +56,131 @@ func TestParseConfigExtractsPoolArguments(t *testing.T) {
 	assert.NotContains(t, config.ConnConfig.Config.RuntimeParams, "pool_min_conns")
 }
 
+func TestParseConfigExtractsConstructArguments(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig("pool_max_concurrent_constructs=3 pool_construct_rate=2.5 pool_min_construct_backoff=100ms pool_max_construct_backoff=10s")
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, config.MaxConcurrentConstructs)
+	assert.Equal(t, 2.5, config.ConstructRate)
+	assert.Equal(t, 100*time.Millisecond, config.MinConstructBackoff)
+	assert.Equal(t, 10*time.Second, config.MaxConstructBackoff)
+	assert.NotContains(t, config.ConnConfig.Config.RuntimeParams, "pool_max_concurrent_constructs")
+	assert.NotContains(t, config.ConnConfig.Config.RuntimeParams, "pool_construct_rate")
+	assert.NotContains(t, config.ConnConfig.Config.RuntimeParams, "pool_min_construct_backoff")
+	assert.NotContains(t, config.ConnConfig.Config.RuntimeParams, "pool_max_construct_backoff")
+
+	_, err = pgxpool.ParseConfig("pool_max_concurrent_constructs=-1")
+	require.Error(t, err)
+
+	_, err = pgxpool.ParseConfig("pool_construct_rate=NaN")
+	require.Error(t, err)
+}
+
+func TestParseConfigExtractsIdleTrimTime(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig("pool_idle_trim_time=5m")
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, config.IdleTrimTime)
+	assert.NotContains(t, config.ConnConfig.Config.RuntimeParams, "pool_idle_trim_time")
+
+	_, err = pgxpool.ParseConfig("pool_idle_trim_time=x")
+	require.Error(t, err)
+}
+
+func TestParseConfigExtractsCompatibilityMode(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig("pool_compatibility_mode=transaction_pooler")
+	require.NoError(t, err)
+	assert.Equal(t, pgxpool.CompatibilityModeTransactionPooler, config.CompatibilityMode)
+	assert.NotContains(t, config.ConnConfig.Config.RuntimeParams, "pool_compatibility_mode")
+
+	_, err = pgxpool.ParseConfig("pool_compatibility_mode=x")
+	require.Error(t, err)
+}
+
+func TestPoolCompatibilityModeTransactionPoolerWithoutDatabase(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig("host=localhost")
+	require.NoError(t, err)
+	config.CompatibilityMode = pgxpool.CompatibilityModeTransactionPooler
+	config.LazyConnect = true
+
+	var connConfig *pgx.ConnConfig
+	config.BeforeConnect = func(ctx context.Context, cfg *pgx.ConnConfig) error {
+		connConfig = cfg
+		return errors.New("no connection")
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	_, err = pool.Acquire(ctx)
+	require.Error(t, err)
+	require.NotNil(t, connConfig)
+	assert.Equal(t, 0, connConfig.StatementCacheCapacity)
+	assert.Equal(t, pgx.QueryExecModeExec, connConfig.DefaultQueryExecMode)
+
+	// The pool config itself is not changed.
+	assert.Equal(t, pgx.QueryExecModeCacheStatement, pool.Config().ConnConfig.DefaultQueryExecMode)
+
+	config.BeforeConnect = nil
+	config.DDLInvalidationChannel = pgxpool.DefaultDDLInvalidationChannel
+	_, err = pgxpool.NewWithConfig(ctx, config)
+	require.Error(t, err)
+
+	config.DDLInvalidationChannel = ""
+	config.IdleTrimTime = time.Minute
+	_, err = pgxpool.NewWithConfig(ctx, config)
+	require.Error(t, err)
+}
+
+func TestPoolCompatibilityModeTransactionPooler(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.CompatibilityMode = pgxpool.CompatibilityModeTransactionPooler
+	config.UsageClasses = map[pgxpool.UsageClass]pgxpool.UsageClassConfig{
+		"report": {StatementTimeout: time.Minute},
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	c, err := pool.Acquire(pgxpool.WithUsageClass(ctx, "report"))
+	require.NoError(t, err)
+	defer c.Release()
+
+	for i := 0; i < 3; i++ {
+		var n int32
+		err = c.QueryRow(ctx, "select $1::int4", i).Scan(&n)
+		require.NoError(t, err)
+		assert.EqualValues(t, i, n)
+	}
+
+	var statementTimeout string
+	err = c.QueryRow(ctx, "show statement_timeout").Scan(&statementTimeout)
+	require.NoError(t, err)
+	assert.NotEqual(t, "1min", statementTimeout)
+
+	var preparedCount int
+	err = c.QueryRow(ctx, "select count(*) from pg_prepared_statements").Scan(&preparedCount)
+	require.NoError(t, err)
+	assert.Equal(t, 0, preparedCount)
+}
+
 func TestConstructorIgnoresContext(t *testing.T) {
 	t.Parallel()
 
@@ -285,6 +415,44 @@ func TestPoolAfterConnect(t *testing.T) {
 	assert.EqualValues(t, 1, n)
 }
 
+func TestPoolPlanCache(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.PlanCache = pgtype.NewPlanCache()
+
+	db, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	c1, err := db.Acquire(ctx)
+	require.NoError(t, err)
+	defer c1.Release()
+
+	c2, err := db.Acquire(ctx)
+	require.NoError(t, err)
+	defer c2.Release()
+
+	require.Same(t, config.PlanCache, c1.Conn().TypeMap().PlanCache())
+	require.Same(t, config.PlanCache, c2.Conn().TypeMap().PlanCache())
+
+	var n int32
+	err = c1.QueryRow(ctx, "select $1::int4", 42).Scan(&n)
+	require.NoError(t, err)
+	assert.EqualValues(t, 42, n)
+	planCount := config.PlanCache.Len()
+	require.NotZero(t, planCount)
+
+	err = c2.QueryRow(ctx, "select $1::int4", 43).Scan(&n)
+	require.NoError(t, err)
+	assert.EqualValues(t, 43, n)
+	require.Equal(t, planCount, config.PlanCache.Len())
+}
+
 func TestPoolBeforeAcquire(t *testing.T) {
 	t.Parallel()
 
@@ -406,6 +574,68 @@ func TestPoolBeforeClose(t *testing.T) {
 	assert.ElementsMatch(t, acquiredPIDs, closedPIDs)
 }
 
+func TestPoolOnConnDestroy(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+
+	reasons := make(chan pgxpool.ConnDestroyReason, 10)
+	config.OnConnDestroy = func(c *pgx.Conn, reason pgxpool.ConnDestroyReason) {
+		reasons <- reason
+	}
+
+	rejectNext := false
+	config.BeforeAcquire = func(ctx context.Context, c *pgx.Conn) bool {
+		if rejectNext {
+			rejectNext = false
+			return false
+		}
+		return true
+	}
+
+	db, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+
+	// Released in a transaction.
+	conn, err := db.Acquire(ctx)
+	require.NoError(t, err)
+	_, err = conn.Exec(ctx, "begin")
+	require.NoError(t, err)
+	conn.Release()
+	require.Equal(t, pgxpool.ConnDestroyUnusableOnRelease, <-reasons)
+
+	// Rejected by BeforeAcquire.
+	conn, err = db.Acquire(ctx)
+	require.NoError(t, err)
+	conn.Release()
+	rejectNext = true
+	conn, err = db.Acquire(ctx)
+	require.NoError(t, err)
+	require.Equal(t, pgxpool.ConnDestroyBeforeAcquire, <-reasons)
+	conn.Release()
+
+	db.Reset()
+	require.Equal(t, pgxpool.ConnDestroyPoolReset, <-reasons)
+
+	conn, err = db.Acquire(ctx)
+	require.NoError(t, err)
+	conn.Release()
+	db.Close()
+	require.Equal(t, pgxpool.ConnDestroyPoolClosed, <-reasons)
+}
+
+func TestConnDestroyReasonString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "max lifetime", pgxpool.ConnDestroyMaxLifetime.String())
+	assert.Equal(t, "before acquire", pgxpool.ConnDestroyBeforeAcquire.String())
+	assert.Equal(t, "unknown", pgxpool.ConnDestroyReason(-1).String())
+}
+
 func TestPoolAcquireAllIdle(t *testing.T) {
 	t.Parallel()
 
@@ -587,6 +817,47 @@ func TestPoolBackgroundChecksMaxConnIdleTime(t *testing.T) {
 	assert.EqualValues(t, 1, stats.NewConnsCount())
 }
 
+func TestPoolBackgroundTrimsIdleConns(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+
+	config.MaxConns = 1
+	config.IdleTrimTime = 100 * time.Millisecond
+	config.HealthCheckPeriod = 150 * time.Millisecond
+
+	db, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	c, err := db.Acquire(ctx)
+	require.NoError(t, err)
+	_, err = c.Exec(ctx, "create temporary table pool_trim_test (id int)")
+	require.NoError(t, err)
+	_, err = c.Conn().Prepare(ctx, "pool_trim_test", "select 1")
+	require.NoError(t, err)
+	c.Release()
+
+	require.Eventually(t, func() bool { return db.Stat().IdleTrimCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// The connection is only trimmed once until it is used again.
+	time.Sleep(2 * config.HealthCheckPeriod)
+	stats := db.Stat()
+	assert.EqualValues(t, 1, stats.IdleTrimCount())
+	assert.EqualValues(t, 1, stats.TotalConns())
+	assert.EqualValues(t, 0, stats.MaxIdleDestroyCount())
+
+	var tableCount, statementCount int
+	err = db.QueryRow(ctx, "select (select count(*) from pg_tables where tablename = 'pool_trim_test'), (select count(*) from pg_prepared_statements)", pgx.QueryExecModeSimpleProtocol).Scan(&tableCount, &statementCount)
+	require.NoError(t, err)
+	assert.Equal(t, 0, tableCount)
+	assert.Equal(t, 0, statementCount)
+}
+
 func TestPoolBackgroundChecksMinConns(t *testing.T) {
 	t.Parallel()
 
@@ -697,6 +968,97 @@ func TestPoolQueryRow(t *testing.T) {
 	assert.EqualValues(t, 1, stats.TotalConns())
 }
 
+func TestPoolWithConn(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	c, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	defer c.Release()
+
+	_, err = c.Exec(ctx, "create temporary table t (n int)")
+	require.NoError(t, err)
+
+	connCtx := pgxpool.WithConn(ctx, c)
+
+	// The temporary table only exists on c.
+	_, err = pool.Exec(connCtx, "insert into t (n) values (1)")
+	require.NoError(t, err)
+
+	batch := &pgx.Batch{}
+	batch.Queue("insert into t (n) values (2)")
+	err = pool.SendBatch(connCtx, batch).Close()
+	require.NoError(t, err)
+
+	copyCount, err := pool.CopyFrom(connCtx, pgx.Identifier{"t"}, []string{"n"}, pgx.CopyFromRows([][]any{{3}}))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, copyCount)
+
+	rows, _ := pool.Query(connCtx, "select n from t order by n")
+	ns, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+	require.NoError(t, err)
+	require.Equal(t, []int32{1, 2, 3}, ns)
+
+	var n int32
+	err = pool.QueryRow(connCtx, "select count(*) from t").Scan(&n)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, n)
+
+	// Only c was used.
+	assert.EqualValues(t, 1, pool.Stat().TotalConns())
+
+	// Another pool ignores c.
+	otherPool, err := pgxpool.New(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer otherPool.Close()
+	_, err = otherPool.Exec(connCtx, "select * from t")
+	require.Error(t, err)
+}
+
+func TestPoolWithTx(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	tx, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+
+	txCtx := pgxpool.WithTx(ctx, tx)
+
+	var inTx bool
+	err = pool.QueryRow(txCtx, "select now() = statement_timestamp()").Scan(&inTx)
+	require.NoError(t, err)
+	require.False(t, inTx, "query did not run in the transaction")
+
+	_, err = pool.Exec(txCtx, "create temporary table t (n int) on commit drop")
+	require.NoError(t, err)
+	_, err = pool.Exec(txCtx, "insert into t (n) values (1)")
+	require.NoError(t, err)
+
+	var n int32
+	err = pool.QueryRow(txCtx, "select count(*) from t").Scan(&n)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+
+	// Without the transaction in the context a different connection is used.
+	_, err = pool.Exec(ctx, "select * from t")
+	require.Error(t, err)
+
+	require.NoError(t, tx.Commit(ctx))
+}
+
 // https://github.com/jackc/pgx/issues/677
 func TestPoolQueryRowErrNoRows(t *testing.T) {
 	t.Parallel()
@@ -955,6 +1317,89 @@ func TestConnReleaseWhenBeginFail(t *testing.T) {
 	assert.EqualValues(t, 0, db.Stat().TotalConns())
 }
 
+func TestPoolBeginTxFunc(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxTxRetries = 2
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	var isoLevel, readOnly string
+	err = pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable, AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, "select current_setting('transaction_isolation'), current_setting('transaction_read_only')").Scan(&isoLevel, &readOnly)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "serializable", isoLevel)
+	assert.Equal(t, "on", readOnly)
+
+	serializationFailure := &pgconn.PgError{Code: "40001"}
+
+	calls := 0
+	err = pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		calls++
+		if calls < 3 {
+			return serializationFailure
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		calls++
+		return serializationFailure
+	})
+	require.ErrorIs(t, err, serializationFailure)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		calls++
+		return errors.New("some error")
+	})
+	require.EqualError(t, err, "some error")
+	assert.Equal(t, 1, calls)
+
+	// RetrySerializable replaces MaxTxRetries rather than multiplying the attempts.
+	calls = 0
+	err = pool.BeginTxFunc(ctx, pgx.TxOptions{RetrySerializable: pgx.TxRetryOptions{MaxAttempts: 2}}, func(tx pgx.Tx) error {
+		calls++
+		return serializationFailure
+	})
+	require.ErrorIs(t, err, serializationFailure)
+	assert.Equal(t, 2, calls)
+
+	waitForReleaseToComplete()
+	assert.EqualValues(t, 0, pool.Stat().AcquiredConns())
+}
+
+func TestPoolBeginTxFuncRetryDisabled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	calls := 0
+	err = pool.BeginTxFunc(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		calls++
+		return &pgconn.PgError{Code: "40P01"}
+	})
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
 func TestTxBeginFuncNestedTransactionCommit(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
@@ -1161,3 +1606,1076 @@ func TestPoolSendBatchBatchCloseTwice(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+func TestPoolAdmissionController(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+
+	errBudgetExceeded := errors.New("budget exceeded")
+	var admitted int32
+	config.AdmissionController = pgxpool.AdmissionFunc(func(ctx context.Context, pool *pgxpool.Pool, data pgxpool.AdmissionData) error {
+		require.NotNil(t, data.Stat)
+		if atomic.AddInt32(&admitted, 1) > 2 {
+			return errBudgetExceeded
+		}
+		return nil
+	})
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	_, err = pool.Exec(ctx, "select 1")
+	require.NoError(t, err)
+
+	var n int
+	err = pool.QueryRow(ctx, "select 1").Scan(&n)
+	require.NoError(t, err)
+
+	_, err = pool.Exec(ctx, "select 1")
+	var rejectedErr *pgxpool.AdmissionRejectedError
+	require.ErrorAs(t, err, &rejectedErr)
+	require.ErrorIs(t, err, errBudgetExceeded)
+
+	rows, err := pool.Query(ctx, "select 1")
+	require.ErrorIs(t, err, errBudgetExceeded)
+	rows.Close()
+
+	assert.EqualValues(t, 0, pool.Stat().AcquiredConns())
+}
+
+func TestPoll(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	pollCtx, pollCancel := context.WithCancel(ctx)
+	defer pollCancel()
+
+	var polls int32
+	var errorCount int32
+	err = pgxpool.Poll(pollCtx, pool, pgxpool.PollConfig[int32]{
+		SQL:        "select n from generate_series(1, $1::int4) n",
+		Args:       []any{3},
+		Interval:   10 * time.Millisecond,
+		Jitter:     5 * time.Millisecond,
+		MaxBackoff: 40 * time.Millisecond,
+		RowToFunc:  pgx.RowTo[int32],
+		Handle: func(ctx context.Context, rows []int32) error {
+			assert.Equal(t, []int32{1, 2, 3}, rows)
+			assert.EqualValues(t, 0, pool.Stat().AcquiredConns())
+			n := atomic.AddInt32(&polls, 1)
+			if n == 2 {
+				return errors.New("handler failed")
+			}
+			if n == 4 {
+				pollCancel()
+			}
+			return nil
+		},
+		OnError: func(err error) {
+			atomic.AddInt32(&errorCount, 1)
+		},
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	assert.EqualValues(t, 4, atomic.LoadInt32(&polls))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&errorCount))
+
+	err = pgxpool.Poll(ctx, pool, pgxpool.PollConfig[int32]{SQL: "select 1", RowToFunc: pgx.RowTo[int32]})
+	require.Error(t, err)
+}
+
+func TestPoolSQLMiddlewareRoutingMetadata(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+
+	var middlewareCalls int32
+	config.ConnConfig.SQLMiddleware = func(ctx context.Context, sql string) (context.Context, string, error) {
+		atomic.AddInt32(&middlewareCalls, 1)
+		return pgx.WithRoutingMetadata(ctx, "shard_a"), sql, nil
+	}
+
+	var acquireMetadata []any
+	config.BeforeAcquire = func(ctx context.Context, conn *pgx.Conn) bool {
+		md, _ := pgx.RoutingMetadataFromContext(ctx)
+		acquireMetadata = append(acquireMetadata, md)
+		return true
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	var n int
+	err = pool.QueryRow(ctx, "select 1").Scan(&n)
+	require.NoError(t, err)
+
+	// The middleware runs once before acquiring the connection and is not run again by the connection.
+	assert.EqualValues(t, 1, atomic.LoadInt32(&middlewareCalls))
+	assert.Equal(t, []any{"shard_a"}, acquireMetadata)
+}
+
+type tenantQueryRewriter struct {
+	tenantID int32
+}
+
+func (r tenantQueryRewriter) RewriteQuery(ctx context.Context, conn *pgx.Conn, sql string, args []any) (string, []any, error) {
+	return fmt.Sprintf("%s /* tenant */ and $%d::int4 = %d", sql, len(args)+1, r.tenantID), append(args, r.tenantID), nil
+}
+
+func TestPoolQueryRewriter(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.QueryRewriter = tenantQueryRewriter{tenantID: 7}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	var n int32
+	err = pool.QueryRow(ctx, "select $1::int4 where true", 1).Scan(&n)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+
+	batch := &pgx.Batch{}
+	batch.Queue("select 2 where true")
+	batch.Queue("select $1::int4 where true", 3)
+	br := pool.SendBatch(ctx, batch)
+	err = br.QueryRow().Scan(&n)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+	err = br.QueryRow().Scan(&n)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, n)
+	require.NoError(t, br.Close())
+
+	// The batch is not modified so sending it again does not apply the QueryRewriter twice.
+	assert.Equal(t, "select 2 where true", batch.QueuedQueries[0].SQL)
+	assert.Empty(t, batch.QueuedQueries[0].Arguments)
+	assert.Equal(t, "select $1::int4 where true", batch.QueuedQueries[1].SQL)
+	assert.Equal(t, []any{3}, batch.QueuedQueries[1].Arguments)
+
+	// The QueryRewriter cannot be applied to a dependent query so the batch fails instead of skipping it.
+	batch = &pgx.Batch{}
+	batch.QueueDependent("select $1::int4 where true", func() ([]any, error) {
+		return []any{5}, nil
+	})
+	err = pool.SendBatch(ctx, batch).Close()
+	require.ErrorContains(t, err, "QueryRewriter is not supported")
+
+	tx, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+
+	// An explicit QueryRewriter takes precedence over the pool default.
+	err = tx.QueryRow(ctx, "select 4 where true", tenantQueryRewriter{tenantID: 8}).Scan(&n)
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, n)
+}
+
+func TestPoolUsageClasses(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 2
+	config.UsageClasses = map[pgxpool.UsageClass]pgxpool.UsageClassConfig{
+		"reporting": {MaxConns: 1, StatementTimeout: 1500 * time.Millisecond},
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	reportingCtx := pgxpool.WithUsageClass(ctx, "reporting")
+
+	c1, err := pool.Acquire(reportingCtx)
+	require.NoError(t, err)
+
+	var statementTimeout string
+	err = c1.QueryRow(ctx, "show statement_timeout").Scan(&statementTimeout)
+	require.NoError(t, err)
+	require.Equal(t, "1500ms", statementTimeout)
+
+	// The reporting class is limited to one connection.
+	shortCtx, shortCancel := context.WithTimeout(reportingCtx, 50*time.Millisecond)
+	_, err = pool.Acquire(shortCtx)
+	shortCancel()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Other usage is not limited by the reporting class.
+	c2, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	c2.Release()
+
+	c1.Release()
+
+	// The statement timeout is reset when the connection is acquired without a usage class.
+	c3, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	defer c3.Release()
+	c4, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	defer c4.Release()
+
+	for _, c := range []*pgxpool.Conn{c3, c4} {
+		err = c.QueryRow(ctx, "show statement_timeout").Scan(&statementTimeout)
+		require.NoError(t, err)
+		require.NotEqual(t, "1500ms", statementTimeout)
+	}
+}
+
+func TestConfigCopyUsageClasses(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig("")
+	require.NoError(t, err)
+	config.UsageClasses = map[pgxpool.UsageClass]pgxpool.UsageClassConfig{"batch": {MaxConns: 2}}
+
+	copied := config.Copy()
+	copied.UsageClasses["batch"] = pgxpool.UsageClassConfig{MaxConns: 3}
+	require.EqualValues(t, 2, config.UsageClasses["batch"].MaxConns)
+}
+
+func TestNewWithConfigUsageClassStatementTimeoutWithoutDatabase(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig("host=localhost")
+	require.NoError(t, err)
+	config.LazyConnect = true
+	config.UsageClasses = map[pgxpool.UsageClass]pgxpool.UsageClassConfig{"report": {StatementTimeout: 500 * time.Microsecond}}
+
+	_, err = pgxpool.NewWithConfig(context.Background(), config)
+	require.ErrorContains(t, err, `usage class "report" StatementTimeout must be 0 or at least 1ms`)
+}
+
+func TestPoolLazyConnect(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MinConns = 2
+	config.LazyConnect = true
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	require.EqualValues(t, 0, pool.Stat().NewConnsCount())
+
+	c, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	c.Release()
+
+	require.Eventually(t, func() bool {
+		return pool.Stat().TotalConns() >= config.MinConns
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestPoolLazyConnectDoesNotConnectUntilUsed(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig("host=127.0.0.1 port=1 sslmode=disable")
+	require.NoError(t, err)
+	config.MinConns = 2
+	config.LazyConnect = true
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	require.EqualValues(t, 0, pool.Stat().NewConnsCount())
+
+	_, err = pool.Acquire(ctx)
+	require.Error(t, err)
+	require.EqualValues(t, 1, pool.Stat().NewConnsCount())
+}
+
+func TestPoolStatHistory(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig("host=127.0.0.1 port=1 sslmode=disable")
+	require.NoError(t, err)
+	config.MaxConns = 7
+	config.StatSamplePeriod = 10 * time.Millisecond
+	config.StatHistorySize = 3
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	require.Eventually(t, func() bool { return len(pool.StatHistory()) == 3 }, time.Second, 10*time.Millisecond)
+
+	// Wait for the ring buffer to wrap.
+	time.Sleep(100 * time.Millisecond)
+
+	history := pool.StatHistory()
+	require.Len(t, history, 3)
+	for i, sample := range history {
+		require.EqualValues(t, 7, sample.Stat.MaxConns())
+		if i > 0 {
+			require.True(t, sample.Time.After(history[i-1].Time))
+		}
+	}
+}
+
+func TestPoolStatHistoryDisabled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig("host=127.0.0.1 port=1 sslmode=disable")
+	require.NoError(t, err)
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	require.Nil(t, pool.StatHistory())
+}
+
+func TestPoolMaxConcurrentConstructs(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig("host=127.0.0.1 port=1 sslmode=disable")
+	require.NoError(t, err)
+	config.MaxConns = 10
+	config.MaxConcurrentConstructs = 2
+
+	var mux sync.Mutex
+	var active, maxActive, constructs int
+	config.BeforeConnect = func(context.Context, *pgx.ConnConfig) error {
+		mux.Lock()
+		active++
+		constructs++
+		if active > maxActive {
+			maxActive = active
+		}
+		mux.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mux.Lock()
+		active--
+		mux.Unlock()
+		return errors.New("construct failed")
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := pool.Acquire(ctx)
+			assert.Error(t, err)
+		}()
+	}
+	wg.Wait()
+
+	mux.Lock()
+	defer mux.Unlock()
+	assert.Equal(t, 8, constructs)
+	assert.Equal(t, 2, maxActive)
+}
+
+func TestPoolConstructRate(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig("host=127.0.0.1 port=1 sslmode=disable")
+	require.NoError(t, err)
+	config.ConstructRate = 20 // 1 per 50ms
+
+	var starts []time.Time
+	config.BeforeConnect = func(context.Context, *pgx.ConnConfig) error {
+		starts = append(starts, time.Now())
+		return errors.New("construct failed")
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	for i := 0; i < 3; i++ {
+		_, err := pool.Acquire(ctx)
+		require.Error(t, err)
+	}
+
+	require.Len(t, starts, 3)
+	for i := 1; i < len(starts); i++ {
+		assert.GreaterOrEqual(t, starts[i].Sub(starts[i-1]), 45*time.Millisecond)
+	}
+}
+
+func TestPoolConstructBackoff(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig("host=127.0.0.1 port=1 sslmode=disable")
+	require.NoError(t, err)
+	config.MinConstructBackoff = 25 * time.Millisecond
+	config.MaxConstructBackoff = 50 * time.Millisecond
+
+	var starts []time.Time
+	config.BeforeConnect = func(context.Context, *pgx.ConnConfig) error {
+		starts = append(starts, time.Now())
+		return errors.New("construct failed")
+	}
+
+	var failures []int
+	config.OnConstructError = func(err error, consecutiveFailures int) {
+		assert.EqualError(t, err, "construct failed")
+		failures = append(failures, consecutiveFailures)
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	for i := 0; i < 4; i++ {
+		_, err := pool.Acquire(ctx)
+		require.Error(t, err)
+	}
+
+	assert.Equal(t, []int{1, 2, 3, 4}, failures)
+	require.Len(t, starts, 4)
+	assert.GreaterOrEqual(t, starts[1].Sub(starts[0]), 25*time.Millisecond)
+	assert.GreaterOrEqual(t, starts[2].Sub(starts[1]), 50*time.Millisecond)
+	assert.GreaterOrEqual(t, starts[3].Sub(starts[2]), 50*time.Millisecond)
+}
+
+func TestStatementFingerprint(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		sql      string
+		expected string
+	}{
+		{"select 1", "select 1"},
+		{"  select\n\t1  ", "select 1"},
+		{"select  'a  b',\n\"c  d\"", "select 'a  b', \"c  d\""},
+		{"select 'it''s  ok'   from t", "select 'it''s  ok' from t"},
+	} {
+		assert.Equal(t, tt.expected, pgxpool.StatementFingerprint(tt.sql), tt.sql)
+	}
+}
+
+func TestPoolStatementConcurrencyLimits(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 4
+	config.StatementConcurrencyLimits = map[string]int32{"select n from generate_series(1, 10) n": 1}
+	config.RejectThrottledStatements = true
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	rows, err := pool.Query(ctx, "select n from generate_series(1, 10) n")
+	require.NoError(t, err)
+
+	_, err = pool.Query(ctx, "select n\nfrom generate_series(1, 10) n")
+	require.ErrorIs(t, err, pgxpool.ErrStatementThrottled)
+
+	_, err = pool.Exec(ctx, "select 1")
+	require.NoError(t, err)
+
+	rows.Close()
+	require.NoError(t, rows.Err())
+
+	rows, err = pool.Query(ctx, "select n from generate_series(1, 10) n")
+	require.NoError(t, err)
+	rows.Close()
+	require.NoError(t, rows.Err())
+}
+
+func TestPoolMaxStatementConcurrencyWaits(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 4
+	config.MaxStatementConcurrency = 1
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	rows, err := pool.Query(ctx, "select 1")
+	require.NoError(t, err)
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	_, err = pool.Exec(waitCtx, "select 1")
+	waitCancel()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = pool.Exec(ctx, "select 2")
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		_, err := pool.Exec(ctx, "select 1")
+		done <- err
+	}()
+
+	rows.Close()
+	require.NoError(t, <-done)
+}
+
+func TestNewWithConfigRejectsNegativeStatementConcurrency(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.StatementConcurrencyLimits = map[string]int32{"select 1": -1}
+
+	_, err = pgxpool.NewWithConfig(context.Background(), config)
+	require.ErrorContains(t, err, "must not be negative")
+}
+
+func TestNewWithConfigRejectsNaNConstructRate(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig("")
+	require.NoError(t, err)
+	config.ConstructRate = math.NaN()
+
+	_, err = pgxpool.NewWithConfig(context.Background(), config)
+	require.ErrorContains(t, err, "ConstructRate")
+}
+
+func TestPoolInvalidateStatementCaches(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 1
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	_, err = pool.Exec(ctx, "drop table if exists pgxpool_invalidate_test; create table pgxpool_invalidate_test(a int)")
+	require.NoError(t, err)
+	defer pool.Exec(ctx, "drop table if exists pgxpool_invalidate_test")
+
+	countColumns := func() (int, error) {
+		rows, err := pool.Query(ctx, "select * from pgxpool_invalidate_test")
+		if err != nil {
+			return 0, err
+		}
+		rows.Close()
+		return len(rows.FieldDescriptions()), rows.Err()
+	}
+
+	n, err := countColumns()
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	_, err = pool.Exec(ctx, "alter table pgxpool_invalidate_test add column b int")
+	require.NoError(t, err)
+
+	pool.InvalidateStatementCaches()
+
+	n, err = countColumns()
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+}
+
+func TestPoolDDLInvalidationChannel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 2
+	config.DDLInvalidationChannel = "pgxpool_ddl_test"
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	_, err = pool.Exec(ctx, "drop table if exists pgxpool_ddl_test; create table pgxpool_ddl_test(a int)")
+	require.NoError(t, err)
+	defer pool.Exec(ctx, "drop table if exists pgxpool_ddl_test")
+
+	rows, err := pool.Query(ctx, "select * from pgxpool_ddl_test")
+	require.NoError(t, err)
+	rows.Close()
+	require.NoError(t, rows.Err())
+
+	_, err = pool.Exec(ctx, "alter table pgxpool_ddl_test add column b int")
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		_, err := pool.Exec(ctx, "select pg_notify($1, 'ALTER TABLE')", config.DDLInvalidationChannel)
+		require.NoError(t, err)
+
+		rows, err := pool.Query(ctx, "select * from pgxpool_ddl_test")
+		if err != nil {
+			return false
+		}
+		rows.Close()
+		return rows.Err() == nil && len(rows.FieldDescriptions()) == 2
+	}, 5*time.Second, 100*time.Millisecond)
+}
+
+func TestPoolDDLInvalidationChannelReconnect(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 2
+	config.DDLInvalidationChannel = "pgxpool_ddl_reconnect_test"
+	config.LeakDetectionThreshold = 200 * time.Millisecond
+	var leaks atomic.Int32
+	config.OnConnLeak = func(pgxpool.LeakedConn) {
+		leaks.Add(1)
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	_, err = pool.Exec(ctx, "drop table if exists pgxpool_ddl_reconnect_test; create table pgxpool_ddl_reconnect_test(a int)")
+	require.NoError(t, err)
+	defer pool.Exec(ctx, "drop table if exists pgxpool_ddl_reconnect_test")
+
+	rows, err := pool.Query(ctx, "select * from pgxpool_ddl_reconnect_test")
+	require.NoError(t, err)
+	rows.Close()
+	require.NoError(t, rows.Err())
+
+	listenerPID := func() int32 {
+		var pid int32
+		err := pool.QueryRow(ctx, "select coalesce(max(pid), 0) from pg_stat_activity where query = $1",
+			`listen "pgxpool_ddl_reconnect_test"`).Scan(&pid)
+		require.NoError(t, err)
+		return pid
+	}
+
+	var oldPID int32
+	require.Eventually(t, func() bool {
+		oldPID = listenerPID()
+		return oldPID != 0
+	}, 5*time.Second, 100*time.Millisecond)
+
+	// The DDL is executed while the broker is reconnecting so its notification would be lost.
+	_, err = pool.Exec(ctx, "select pg_terminate_backend($1)", oldPID)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, "alter table pgxpool_ddl_reconnect_test add column b int")
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		pid := listenerPID()
+		return pid != 0 && pid != oldPID
+	}, 10*time.Second, 100*time.Millisecond)
+
+	// The caches were invalidated when the broker listened again.
+	rows, err = pool.Query(ctx, "select * from pgxpool_ddl_reconnect_test")
+	require.NoError(t, err)
+	rows.Close()
+	require.NoError(t, rows.Err())
+	require.Len(t, rows.FieldDescriptions(), 2)
+
+	// The connection held by the broker is not a leak.
+	assert.EqualValues(t, 0, leaks.Load())
+}
+
+func TestDDLInvalidationTriggerSQL(t *testing.T) {
+	t.Parallel()
+
+	sql := pgxpool.DDLInvalidationTriggerSQL("it's")
+	assert.Contains(t, sql, "pg_notify('it''s', tg_tag)")
+	assert.Contains(t, sql, "on ddl_command_end")
+	assert.Contains(t, pgxpool.DDLInvalidationTriggerSQL(pgxpool.DefaultDDLInvalidationChannel), "'pgx_ddl'")
+}
+
+func TestPoolAcquireCanceledDuringConstructDoesNotExceedMaxConns(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 1
+	var connectCount atomic.Int32
+	config.BeforeConnect = func(ctx context.Context, cfg *pgx.ConnConfig) error {
+		connectCount.Add(1)
+		time.Sleep(500 * time.Millisecond)
+		return nil
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	shortCtx, shortCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	_, err = pool.Acquire(shortCtx)
+	shortCancel()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The connection is still being established in the background. The second Acquire must wait for it instead of
+	// establishing another connection.
+	c, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	defer c.Release()
+
+	assert.LessOrEqual(t, pool.Stat().TotalConns(), config.MaxConns)
+	assert.EqualValues(t, 1, connectCount.Load())
+	assert.EqualValues(t, 1, pool.Stat().NewConnsCount())
+}
+
+func TestPoolUpdateConfigGrow(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 1
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	c1, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	defer c1.Release()
+
+	acquired := make(chan *pgxpool.Conn)
+	go func() {
+		c, err := pool.Acquire(ctx)
+		if err != nil {
+			close(acquired)
+			return
+		}
+		acquired <- c
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquired more than MaxConns connections")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	dc := pool.DynamicConfig()
+	dc.MaxConns = 2
+	err = pool.UpdateConfig(dc)
+	require.NoError(t, err)
+
+	c2, ok := <-acquired
+	require.True(t, ok)
+	c2.Release()
+
+	stat := pool.Stat()
+	require.EqualValues(t, 2, stat.MaxConns())
+	require.EqualValues(t, 2, stat.TotalConns())
+	require.EqualValues(t, 2, pool.Config().MaxConns)
+}
+
+func TestPoolUpdateConfigShrink(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	destroyReasons := make(chan pgxpool.ConnDestroyReason, 3)
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 3
+	config.HealthCheckPeriod = 100 * time.Millisecond
+	config.OnConnDestroy = func(conn *pgx.Conn, reason pgxpool.ConnDestroyReason) {
+		destroyReasons <- reason
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	conns := make([]*pgxpool.Conn, 3)
+	for i := range conns {
+		conns[i], err = pool.Acquire(ctx)
+		require.NoError(t, err)
+	}
+
+	dc := pool.DynamicConfig()
+	dc.MaxConns = 1
+	err = pool.UpdateConfig(dc)
+	require.NoError(t, err)
+
+	// Connections in use are not closed.
+	require.EqualValues(t, 3, pool.Stat().TotalConns())
+
+	conns[0].Release()
+
+	// Another connection cannot be acquired while more than MaxConns connections are in use.
+	shortCtx, shortCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	_, err = pool.Acquire(shortCtx)
+	shortCancel()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	conns[1].Release()
+	conns[2].Release()
+
+	require.Eventually(t, func() bool {
+		return pool.Stat().TotalConns() == 1
+	}, 5*time.Second, 50*time.Millisecond)
+	require.Equal(t, pgxpool.ConnDestroyMaxConns, <-destroyReasons)
+	require.Equal(t, pgxpool.ConnDestroyMaxConns, <-destroyReasons)
+
+	c, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	c.Release()
+}
+
+func TestPoolUpdateConfigMaxConnLifetime(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	c, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+
+	dc := pool.DynamicConfig()
+	dc.MaxConnLifetime = time.Millisecond
+	err = pool.UpdateConfig(dc)
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+
+	// The new lifetime applies to the existing connection.
+	c.Release()
+	require.EqualValues(t, 1, pool.Stat().MaxLifetimeDestroyCount())
+}
+
+func TestPoolUpdateConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig("")
+	require.NoError(t, err)
+	config.LazyConnect = true
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	dc := pool.DynamicConfig()
+	require.Equal(t, config.MaxConns, dc.MaxConns)
+	require.Equal(t, config.MinConns, dc.MinConns)
+	require.Equal(t, config.MaxConnLifetime, dc.MaxConnLifetime)
+
+	for _, tt := range []pgxpool.DynamicConfig{
+		{MaxConns: 0},
+		{MaxConns: 2, MinConns: -1},
+		{MaxConns: 2, MinConns: 3},
+		{MaxConns: 2, MaxConnLifetime: -time.Second},
+	} {
+		require.Error(t, pool.UpdateConfig(tt))
+	}
+	require.Equal(t, dc, pool.DynamicConfig())
+
+	dc = pgxpool.DynamicConfig{MaxConns: 8, MinConns: 2, MaxConnLifetime: time.Minute}
+	require.NoError(t, pool.UpdateConfig(dc))
+	require.Equal(t, dc, pool.DynamicConfig())
+	require.EqualValues(t, 8, pool.Stat().MaxConns())
+}
+
+func TestPoolLeakDetection(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+
+	leaks := make(chan pgxpool.LeakedConn, 10)
+	config.LeakDetectionThreshold = 100 * time.Millisecond
+	config.OnConnLeak = func(lc pgxpool.LeakedConn) {
+		leaks <- lc
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	// A connection released before the threshold is not reported.
+	c, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	c.Release()
+
+	leaked := acquireForLeakDetectionTest(t, ctx, pool)
+
+	select {
+	case lc := <-leaks:
+		require.Same(t, leaked.Conn(), lc.Conn)
+		require.GreaterOrEqual(t, lc.HeldFor, config.LeakDetectionThreshold)
+		require.Contains(t, string(lc.Stack), "acquireForLeakDetectionTest")
+	case <-ctx.Done():
+		t.Fatal("leak was not detected")
+	}
+
+	// Each acquisition is only reported once.
+	time.Sleep(3 * config.LeakDetectionThreshold)
+	leaked.Release()
+	require.Len(t, leaks, 0)
+}
+
+func TestNewWithConfigLeakDetectionRequiresReporter(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgxpool.ParseConfig("")
+	require.NoError(t, err)
+	config.LeakDetectionThreshold = time.Second
+
+	_, err = pgxpool.NewWithConfig(context.Background(), config)
+	require.ErrorContains(t, err, "OnConnLeak")
+}
+
+func acquireForLeakDetectionTest(t testing.TB, ctx context.Context, pool *pgxpool.Pool) *pgxpool.Conn {
+	c, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	return c
+}
+
+func TestPoolAcquireErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("closed pool", func(t *testing.T) {
+		config, err := pgxpool.ParseConfig("")
+		require.NoError(t, err)
+		config.LazyConnect = true
+
+		pool, err := pgxpool.NewWithConfig(context.Background(), config)
+		require.NoError(t, err)
+		pool.Close()
+
+		_, err = pool.Acquire(context.Background())
+		require.ErrorIs(t, err, pgxpool.ErrPoolClosed)
+		require.ErrorIs(t, err, puddle.ErrClosedPool)
+	})
+
+	t.Run("construct error", func(t *testing.T) {
+		config, err := pgxpool.ParseConfig("")
+		require.NoError(t, err)
+		config.LazyConnect = true
+		errConnect := errors.New("connect failed")
+		config.BeforeConnect = func(context.Context, *pgx.ConnConfig) error {
+			return errConnect
+		}
+
+		pool, err := pgxpool.NewWithConfig(context.Background(), config)
+		require.NoError(t, err)
+		defer pool.Close()
+
+		_, err = pool.Acquire(context.Background())
+		var constructErr *pgxpool.ConstructError
+		require.ErrorAs(t, err, &constructErr)
+		require.ErrorIs(t, constructErr.Cause, errConnect)
+		require.NotErrorIs(t, err, pgxpool.ErrAcquireTimeout)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		config, err := pgxpool.ParseConfig("")
+		require.NoError(t, err)
+		config.LazyConnect = true
+		config.BeforeConnect = func(ctx context.Context, _ *pgx.ConnConfig) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+
+		pool, err := pgxpool.NewWithConfig(context.Background(), config)
+		require.NoError(t, err)
+		defer pool.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err = pool.Acquire(ctx)
+		require.ErrorIs(t, err, pgxpool.ErrAcquireTimeout)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		config, err := pgxpool.ParseConfig("")
+		require.NoError(t, err)
+		config.LazyConnect = true
+
+		pool, err := pgxpool.NewWithConfig(context.Background(), config)
+		require.NoError(t, err)
+		defer pool.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err = pool.Acquire(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+		require.NotErrorIs(t, err, pgxpool.ErrAcquireTimeout)
+	})
+}
@@ -0,0 +1,455 @@
+package pgxpool
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ShadowConfig is the configuration for a ShadowPool.
+type ShadowConfig struct {
+	// SampleRate is the fraction of calls in the range [0, 1] that are mirrored to the shadow Pool.
+	SampleRate float64
+
+	// ShouldMirror is called for each sampled call. If it returns false the call is not mirrored. e.g. it can restrict
+	// mirroring to read-only statements. If nil, all sampled calls are mirrored.
+	ShouldMirror func(ctx context.Context, sql string) bool
+
+	// Timeout limits the duration of each mirrored call. A mirrored call is not canceled when the context of the primary
+	// call is canceled. If 0, there is no limit.
+	Timeout time.Duration
+
+	// MaxConcurrent is the maximum number of mirrored calls in progress. When it is reached further calls are not
+	// mirrored until a mirrored call finishes. If 0, the MaxConns of the shadow Pool is used.
+	MaxConcurrent int
+
+	// Compare returns true if the results of the primary and shadow calls match. If nil, ShadowComparison.Match is used.
+	Compare func(c *ShadowComparison) bool
+
+	// OnDiff is called when the results of a mirrored call do not match. ctx is the context of the primary call without
+	// its cancellation. OnDiff is called from a separate goroutine and must be safe for concurrent use.
+	OnDiff func(ctx context.Context, c *ShadowComparison)
+
+	// OnMatch is called when the results of a mirrored call match. It is optional. Like OnDiff it is called from a
+	// separate goroutine.
+	OnMatch func(ctx context.Context, c *ShadowComparison)
+}
+
+// ShadowResult is the result of one side of a mirrored call.
+type ShadowResult struct {
+	CommandTag pgconn.CommandTag
+
+	// Rows are the rows returned by Query or QueryRow. It is nil for Exec. The values of both the primary and the shadow
+	// rows are decoded from a copy of the raw values in a separate goroutine with a pgtype.Map that only has the default
+	// types. A value of a type that is not known to it is a string in the text format or a []byte in the binary format.
+	Rows [][]any
+
+	// Complete is false if the application closed the primary rows before reading all of them. Then Rows only contains
+	// the rows that were read and CommandTag is empty. The shadow result is always complete.
+	Complete bool
+
+	Err      error
+	Duration time.Duration
+}
+
+// ShadowComparison is the result of a mirrored call on both the primary and the shadow Pool.
+type ShadowComparison struct {
+	SQL     string
+	Args    []any
+	Primary ShadowResult
+	Shadow  ShadowResult
+}
+
+// Match returns true if the primary and shadow results are equivalent. Both must have succeeded or failed. When both
+// failed with a *pgconn.PgError the SQLSTATE codes must be the same. When both succeeded the command tags and rows must
+// be equal. If the primary result is not complete, only the rows read from the primary are compared.
+func (c *ShadowComparison) Match() bool {
+	if (c.Primary.Err == nil) != (c.Shadow.Err == nil) {
+		return false
+	}
+
+	if c.Primary.Err != nil {
+		var primaryPgErr, shadowPgErr *pgconn.PgError
+		if errors.As(c.Primary.Err, &primaryPgErr) && errors.As(c.Shadow.Err, &shadowPgErr) {
+			return primaryPgErr.Code == shadowPgErr.Code
+		}
+		return true
+	}
+
+	shadowRows := c.Shadow.Rows
+	if c.Primary.Complete {
+		if c.Primary.CommandTag.String() != c.Shadow.CommandTag.String() || len(c.Primary.Rows) != len(c.Shadow.Rows) {
+			return false
+		}
+	} else {
+		if len(c.Primary.Rows) > len(c.Shadow.Rows) {
+			return false
+		}
+		shadowRows = shadowRows[:len(c.Primary.Rows)]
+	}
+
+	for i := range c.Primary.Rows {
+		if !reflect.DeepEqual(c.Primary.Rows[i], shadowRows[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ShadowPool is an experimental wrapper that mirrors a sample of Exec, Query, and QueryRow calls to a shadow Pool and
+// compares the results. It is intended for validating that a new cluster or a refactored schema returns the same
+// results as the existing one before switching to it.
+//
+// The result returned to the application is always the result of the primary Pool. Mirrored calls run concurrently in
+// separate goroutines and their errors, timeouts, and results never affect the primary call. When mirroring would
+// exceed ShadowConfig.MaxConcurrent the call is simply not mirrored.
+//
+// The arguments of a mirrored call may still be in use by the shadow call after the primary call returns, so they must
+// not be modified. Writes are mirrored like any other statement. Use ShadowConfig.ShouldMirror to only mirror reads if
+// the shadow must not be written to.
+//
+// Other operations such as transactions, batches, and CopyFrom are not mirrored. Use Primary to perform them.
+type ShadowPool struct {
+	primary *Pool
+	shadow  *Pool
+	config  ShadowConfig
+
+	sem chan struct{}
+	wg  sync.WaitGroup
+}
+
+// NewShadowPool returns a ShadowPool that mirrors calls from primary to shadow. The ShadowPool does not take ownership
+// of the Pools. The application must close them after calling Wait.
+func NewShadowPool(primary, shadow *Pool, config *ShadowConfig) (*ShadowPool, error) {
+	if config.SampleRate < 0 || config.SampleRate > 1 {
+		return nil, errors.New("SampleRate must be in the range [0, 1]")
+	}
+	if config.MaxConcurrent < 0 {
+		return nil, errors.New("MaxConcurrent must be >= 0")
+	}
+
+	maxConcurrent := config.MaxConcurrent
+	if maxConcurrent == 0 {
+		maxConcurrent = int(shadow.Config().MaxConns)
+	}
+
+	return &ShadowPool{
+		primary: primary,
+		shadow:  shadow,
+		config:  *config,
+		sem:     make(chan struct{}, maxConcurrent),
+	}, nil
+}
+
+// Primary returns the primary Pool.
+func (s *ShadowPool) Primary() *Pool {
+	return s.primary
+}
+
+// Shadow returns the shadow Pool.
+func (s *ShadowPool) Shadow() *Pool {
+	return s.shadow
+}
+
+// Wait waits for all mirrored calls in progress to finish and their callbacks to return. A mirrored Query does not
+// finish until the application closes the primary rows.
+func (s *ShadowPool) Wait() {
+	s.wg.Wait()
+}
+
+// Exec executes sql on the primary Pool and possibly mirrors it to the shadow Pool. See Pool.Exec.
+func (s *ShadowPool) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	call := s.mirror(ctx, sql, arguments, false)
+
+	start := time.Now()
+	commandTag, err := s.primary.Exec(ctx, sql, arguments...)
+	if call != nil {
+		call.finish(ShadowResult{CommandTag: commandTag, Complete: true, Err: err, Duration: time.Since(start)})
+	}
+
+	return commandTag, err
+}
+
+// Query executes sql on the primary Pool and possibly mirrors it to the shadow Pool. See Pool.Query. The values of the
+// primary rows are recorded for comparison as they are read by the application.
+func (s *ShadowPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	call := s.mirror(ctx, sql, args, true)
+	if call == nil {
+		return s.primary.Query(ctx, sql, args...)
+	}
+
+	return s.query(ctx, call, sql, args)
+}
+
+// QueryRow executes sql on the primary Pool and possibly mirrors it to the shadow Pool. See Pool.QueryRow. Only the
+// first row of the shadow result is compared.
+func (s *ShadowPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	call := s.mirror(ctx, sql, args, true)
+	if call == nil {
+		return s.primary.QueryRow(ctx, sql, args...)
+	}
+
+	rows, _ := s.query(ctx, call, sql, args)
+	return shadowRow{rows: rows}
+}
+
+func (s *ShadowPool) query(ctx context.Context, call *shadowCall, sql string, args []any) (pgx.Rows, error) {
+	start := time.Now()
+	rows, err := s.primary.Query(ctx, sql, args...)
+	if err != nil {
+		call.finish(ShadowResult{Complete: true, Err: err, Duration: time.Since(start)})
+		return rows, err
+	}
+
+	return &shadowRows{Rows: rows, call: call, start: start}, nil
+}
+
+// mirror starts a mirrored call of sql on the shadow Pool. It returns nil if the call is not mirrored.
+func (s *ShadowPool) mirror(ctx context.Context, sql string, args []any, query bool) *shadowCall {
+	if s.config.SampleRate == 0 || rand.Float64() >= s.config.SampleRate {
+		return nil
+	}
+	if s.config.ShouldMirror != nil && !s.config.ShouldMirror(ctx, sql) {
+		return nil
+	}
+
+	select {
+	case s.sem <- struct{}{}:
+	default:
+		return nil
+	}
+
+	call := &shadowCall{
+		s:       s,
+		ctx:     context.WithoutCancel(ctx),
+		sql:     sql,
+		args:    args,
+		query:   query,
+		primary: make(chan shadowPrimaryResult, 1),
+	}
+
+	s.wg.Add(1)
+	go call.run()
+
+	return call
+}
+
+type shadowCall struct {
+	s     *ShadowPool
+	ctx   context.Context
+	sql   string
+	args  []any
+	query bool
+
+	primary chan shadowPrimaryResult
+}
+
+// shadowPrimaryResult is the result of the primary call. The rows are decoded by the shadowCall.
+type shadowPrimaryResult struct {
+	result ShadowResult
+	fields []pgconn.FieldDescription
+	raw    [][][]byte
+}
+
+// finish reports the result of the primary call. It must be called exactly once.
+func (call *shadowCall) finish(result ShadowResult) {
+	call.primary <- shadowPrimaryResult{result: result}
+}
+
+func (call *shadowCall) run() {
+	defer call.s.wg.Done()
+	defer func() { <-call.s.sem }()
+
+	// The context may have a Conn or Tx of the primary set with WithConn or WithTx. The shadow call must not use it.
+	ctx := withoutContextQuerier(call.ctx)
+	if call.s.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, call.s.config.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	shadow := ShadowResult{Complete: true}
+	var shadowFields []pgconn.FieldDescription
+	var shadowRaw [][][]byte
+	if call.query {
+		rows, _ := call.s.shadow.Query(ctx, call.sql, call.args...)
+		shadowFields, shadowRaw, shadow.CommandTag, shadow.Err = collectShadowRows(rows)
+	} else {
+		shadow.CommandTag, shadow.Err = call.s.shadow.Exec(ctx, call.sql, call.args...)
+	}
+	shadow.Duration = time.Since(start)
+
+	primary := <-call.primary
+	if call.query {
+		m := pgtype.NewMap()
+		primary.result.Rows = decodeShadowRows(m, primary.fields, primary.raw)
+		shadow.Rows = decodeShadowRows(m, shadowFields, shadowRaw)
+	}
+
+	c := &ShadowComparison{
+		SQL:     call.sql,
+		Args:    call.args,
+		Primary: primary.result,
+		Shadow:  shadow,
+	}
+
+	var match bool
+	if call.s.config.Compare != nil {
+		match = call.s.config.Compare(c)
+	} else {
+		match = c.Match()
+	}
+
+	if match {
+		if call.s.config.OnMatch != nil {
+			call.s.config.OnMatch(call.ctx, c)
+		}
+	} else if call.s.config.OnDiff != nil {
+		call.s.config.OnDiff(call.ctx, c)
+	}
+}
+
+func collectShadowRows(rows pgx.Rows) ([]pgconn.FieldDescription, [][][]byte, pgconn.CommandTag, error) {
+	defer rows.Close()
+
+	var fields []pgconn.FieldDescription
+	var raw [][][]byte
+	for rows.Next() {
+		if fields == nil {
+			fields = slices.Clone(rows.FieldDescriptions())
+		}
+		raw = append(raw, copyRawValues(rows.RawValues()))
+	}
+	rows.Close()
+
+	return fields, raw, rows.CommandTag(), rows.Err()
+}
+
+func copyRawValues(values [][]byte) [][]byte {
+	copied := make([][]byte, len(values))
+	for i, v := range values {
+		if v != nil {
+			copied[i] = slices.Clone(v)
+		}
+	}
+	return copied
+}
+
+// decodeShadowRows decodes raw like pgx.Rows.Values does. A value that cannot be decoded is a []byte.
+func decodeShadowRows(m *pgtype.Map, fields []pgconn.FieldDescription, raw [][][]byte) [][]any {
+	if raw == nil {
+		return nil
+	}
+
+	rows := make([][]any, len(raw))
+	for i, rawValues := range raw {
+		values := make([]any, len(rawValues))
+		for j, buf := range rawValues {
+			if buf == nil || j >= len(fields) {
+				continue
+			}
+
+			fd := &fields[j]
+			if dt, ok := m.TypeForOID(fd.DataTypeOID); ok {
+				if value, err := dt.Codec.DecodeValue(m, fd.DataTypeOID, fd.Format, buf); err == nil {
+					values[j] = value
+					continue
+				}
+			}
+
+			if fd.Format == pgtype.TextFormatCode {
+				values[j] = string(buf)
+			} else {
+				values[j] = buf
+			}
+		}
+		rows[i] = values
+	}
+
+	return rows
+}
+
+// shadowRows records a copy of the raw values of the primary rows as they are read and reports them to the shadowCall
+// when the rows are closed. The values are not decoded here, as a decoding error would fail the primary rows.
+type shadowRows struct {
+	pgx.Rows
+	call  *shadowCall
+	start time.Time
+
+	fields   []pgconn.FieldDescription
+	raw      [][][]byte
+	complete bool
+	finished bool
+}
+
+func (rows *shadowRows) Next() bool {
+	if !rows.Rows.Next() {
+		rows.complete = true
+		rows.finish()
+		return false
+	}
+
+	if rows.fields == nil {
+		rows.fields = slices.Clone(rows.Rows.FieldDescriptions())
+	}
+	rows.raw = append(rows.raw, copyRawValues(rows.Rows.RawValues()))
+
+	return true
+}
+
+func (rows *shadowRows) Close() {
+	rows.Rows.Close()
+	rows.finish()
+}
+
+func (rows *shadowRows) finish() {
+	if rows.finished {
+		return
+	}
+	rows.finished = true
+
+	result := ShadowResult{
+		Complete: rows.complete,
+		Err:      rows.Rows.Err(),
+		Duration: time.Since(rows.start),
+	}
+	if rows.complete {
+		result.CommandTag = rows.Rows.CommandTag()
+	}
+	rows.call.primary <- shadowPrimaryResult{result: result, fields: rows.fields, raw: rows.raw}
+}
+
+type shadowRow struct {
+	rows pgx.Rows
+}
+
+func (r shadowRow) Scan(dest ...any) error {
+	defer r.rows.Close()
+
+	if err := r.rows.Err(); err != nil {
+		return err
+	}
+
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return pgx.ErrNoRows
+	}
+
+	if err := r.rows.Scan(dest...); err != nil {
+		return err
+	}
+	r.rows.Close()
+	return r.rows.Err()
+}
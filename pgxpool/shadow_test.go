@@ -0,0 +1,219 @@
+package pgxpool_test
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShadowComparisonMatch(t *testing.T) {
+	t.Parallel()
+
+	selectTag := pgconn.NewCommandTag("SELECT 2")
+	rows := [][]any{{int32(1), "a"}, {int32(2), "b"}}
+
+	for i, tt := range []struct {
+		primary pgxpool.ShadowResult
+		shadow  pgxpool.ShadowResult
+		match   bool
+	}{
+		{
+			primary: pgxpool.ShadowResult{CommandTag: selectTag, Rows: rows, Complete: true},
+			shadow:  pgxpool.ShadowResult{CommandTag: selectTag, Rows: rows, Complete: true},
+			match:   true,
+		},
+		{
+			primary: pgxpool.ShadowResult{CommandTag: selectTag, Rows: rows, Complete: true},
+			shadow:  pgxpool.ShadowResult{CommandTag: selectTag, Rows: [][]any{{int32(1), "a"}, {int32(2), "c"}}, Complete: true},
+			match:   false,
+		},
+		{
+			primary: pgxpool.ShadowResult{CommandTag: selectTag, Rows: rows, Complete: true},
+			shadow:  pgxpool.ShadowResult{CommandTag: pgconn.NewCommandTag("SELECT 1"), Rows: rows[:1], Complete: true},
+			match:   false,
+		},
+		{
+			primary: pgxpool.ShadowResult{Rows: rows[:1]},
+			shadow:  pgxpool.ShadowResult{CommandTag: selectTag, Rows: rows, Complete: true},
+			match:   true,
+		},
+		{
+			primary: pgxpool.ShadowResult{CommandTag: selectTag, Rows: rows, Complete: true},
+			shadow:  pgxpool.ShadowResult{Err: &pgconn.PgError{Code: "42P01"}, Complete: true},
+			match:   false,
+		},
+		{
+			primary: pgxpool.ShadowResult{Err: &pgconn.PgError{Code: "23505"}, Complete: true},
+			shadow:  pgxpool.ShadowResult{Err: &pgconn.PgError{Code: "23505"}, Complete: true},
+			match:   true,
+		},
+		{
+			primary: pgxpool.ShadowResult{Err: &pgconn.PgError{Code: "23505"}, Complete: true},
+			shadow:  pgxpool.ShadowResult{Err: &pgconn.PgError{Code: "42P01"}, Complete: true},
+			match:   false,
+		},
+	} {
+		c := &pgxpool.ShadowComparison{Primary: tt.primary, Shadow: tt.shadow}
+		assert.Equalf(t, tt.match, c.Match(), "%d", i)
+	}
+}
+
+func TestShadowPoolWithoutDatabase(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig("host=127.0.0.1 port=1 sslmode=disable")
+	require.NoError(t, err)
+	config.LazyConnect = true
+	primary, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer primary.Close()
+	shadow, err := pgxpool.NewWithConfig(ctx, config.Copy())
+	require.NoError(t, err)
+	defer shadow.Close()
+
+	_, err = pgxpool.NewShadowPool(primary, shadow, &pgxpool.ShadowConfig{SampleRate: 2})
+	require.Error(t, err)
+
+	var mux sync.Mutex
+	var matches []*pgxpool.ShadowComparison
+	sp, err := pgxpool.NewShadowPool(primary, shadow, &pgxpool.ShadowConfig{
+		SampleRate: 1,
+		OnMatch: func(ctx context.Context, c *pgxpool.ShadowComparison) {
+			mux.Lock()
+			matches = append(matches, c)
+			mux.Unlock()
+		},
+		OnDiff: func(ctx context.Context, c *pgxpool.ShadowComparison) {
+			t.Errorf("unexpected diff: %v", c)
+		},
+	})
+	require.NoError(t, err)
+
+	// The primary error is returned and the errors of both Pools are compared.
+	_, err = sp.Exec(ctx, "select $1::int", 42)
+	require.Error(t, err)
+	var n int
+	err = sp.QueryRow(ctx, "select 1").Scan(&n)
+	require.Error(t, err)
+	sp.Wait()
+
+	require.Len(t, matches, 2)
+	assert.Equal(t, "select $1::int", matches[0].SQL)
+	assert.Equal(t, []any{42}, matches[0].Args)
+	assert.Error(t, matches[0].Primary.Err)
+	assert.Error(t, matches[0].Shadow.Err)
+	assert.Equal(t, "select 1", matches[1].SQL)
+	assert.Error(t, matches[1].Primary.Err)
+	assert.Error(t, matches[1].Shadow.Err)
+
+	sp, err = pgxpool.NewShadowPool(primary, shadow, &pgxpool.ShadowConfig{
+		SampleRate:   1,
+		ShouldMirror: func(ctx context.Context, sql string) bool { return false },
+		OnMatch:      func(ctx context.Context, c *pgxpool.ShadowComparison) { t.Error("unexpected mirrored call") },
+	})
+	require.NoError(t, err)
+	_, err = sp.Exec(ctx, "select 1")
+	require.Error(t, err)
+	sp.Wait()
+}
+
+func TestShadowPool(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	primaryConfig, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	primaryConfig.ConnConfig.RuntimeParams["application_name"] = "primary"
+	primary, err := pgxpool.NewWithConfig(ctx, primaryConfig)
+	require.NoError(t, err)
+	defer primary.Close()
+
+	shadowConfig, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	shadowConfig.ConnConfig.RuntimeParams["application_name"] = "shadow"
+	shadow, err := pgxpool.NewWithConfig(ctx, shadowConfig)
+	require.NoError(t, err)
+	defer shadow.Close()
+
+	var mux sync.Mutex
+	var matches, diffs []*pgxpool.ShadowComparison
+	sp, err := pgxpool.NewShadowPool(primary, shadow, &pgxpool.ShadowConfig{
+		SampleRate: 1,
+		Timeout:    30 * time.Second,
+		OnMatch: func(ctx context.Context, c *pgxpool.ShadowComparison) {
+			mux.Lock()
+			matches = append(matches, c)
+			mux.Unlock()
+		},
+		OnDiff: func(ctx context.Context, c *pgxpool.ShadowComparison) {
+			mux.Lock()
+			diffs = append(diffs, c)
+			mux.Unlock()
+		},
+	})
+	require.NoError(t, err)
+
+	rows, err := sp.Query(ctx, "select n from generate_series(1, $1::int) n", 3)
+	require.NoError(t, err)
+	var sum int32
+	for rows.Next() {
+		var n int32
+		require.NoError(t, rows.Scan(&n))
+		sum += n
+	}
+	require.NoError(t, rows.Err())
+	require.EqualValues(t, 6, sum)
+	sp.Wait()
+
+	var appName string
+	err = sp.QueryRow(ctx, "select current_setting('application_name')").Scan(&appName)
+	require.NoError(t, err)
+	require.Equal(t, "primary", appName)
+	sp.Wait()
+
+	// A failing shadow does not affect the primary.
+	_, err = sp.Exec(ctx, "select case when current_setting('application_name') = 'shadow' then 1/0 end")
+	require.NoError(t, err)
+	sp.Wait()
+
+	require.Len(t, matches, 1)
+	assert.Equal(t, [][]any{{int32(1)}, {int32(2)}, {int32(3)}}, matches[0].Primary.Rows)
+	assert.Equal(t, "SELECT 3", matches[0].Shadow.CommandTag.String())
+
+	require.Len(t, diffs, 2)
+	assert.Equal(t, [][]any{{"primary"}}, diffs[0].Primary.Rows)
+	assert.Equal(t, [][]any{{"shadow"}}, diffs[0].Shadow.Rows)
+	assert.NoError(t, diffs[1].Primary.Err)
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, diffs[1].Shadow.Err, &pgErr)
+	assert.Equal(t, "22012", pgErr.Code)
+
+	// A Conn of the primary set with WithConn is not used by the shadow call.
+	c, err := primary.Acquire(ctx)
+	require.NoError(t, err)
+	defer c.Release()
+	_, err = c.Exec(ctx, "create temporary table shadow_t (n int)")
+	require.NoError(t, err)
+	_, err = sp.Exec(pgxpool.WithConn(ctx, c), "insert into shadow_t (n) values (1)")
+	require.NoError(t, err)
+	sp.Wait()
+	require.Len(t, diffs, 3)
+	require.ErrorAs(t, diffs[2].Shadow.Err, &pgErr)
+	assert.Equal(t, "42P01", pgErr.Code)
+	var n int64
+	err = c.QueryRow(ctx, "select count(*) from shadow_t").Scan(&n)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+}
@@ -0,0 +1,240 @@
+package pgxpool
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+)
+
+// ShardSelector returns the index in names of the shard for key. names is the names of all shards in the order they
+// were configured. It must return a value in the range [0, len(names)).
+type ShardSelector func(key string, names []string) int
+
+// RendezvousShardSelector selects the shard with the highest hash of its name and key (rendezvous hashing). The shard
+// for a key depends only on the shard names, not their order. Adding a shard only moves the keys that now belong to the
+// new shard and removing a shard only moves the keys that belonged to the removed shard.
+func RendezvousShardSelector(key string, names []string) int {
+	selected := 0
+	var selectedScore uint64
+	for i, name := range names {
+		score := rendezvousScore(name, key)
+		if i == 0 || score > selectedScore {
+			selected = i
+			selectedScore = score
+		}
+	}
+	return selected
+}
+
+func rendezvousScore(name, key string) uint64 {
+	h := fnv.New64a()
+	var lenBuf [8]byte
+	binary.BigEndian.PutUint64(lenBuf[:], uint64(len(name)))
+	h.Write(lenBuf[:])
+	h.Write([]byte(name))
+	h.Write([]byte(key))
+
+	// FNV-1a alone distributes similar inputs poorly. Finish with the splitmix64 finalizer.
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// ShardConfig is the configuration of a single shard of a ShardedPool.
+type ShardConfig struct {
+	// Name identifies the shard. It must be unique within a ShardedPool. It is used by RendezvousShardSelector so changing
+	// it changes which keys belong to the shard.
+	Name string
+
+	// Config is the configuration of the shard's Pool. It must have been created by ParseConfig.
+	Config *Config
+}
+
+// ShardedConfig is the configuration for a ShardedPool.
+type ShardedConfig struct {
+	Shards []ShardConfig
+
+	// Selector selects the shard for a key. If nil, RendezvousShardSelector is used.
+	Selector ShardSelector
+}
+
+// ShardedPool routes by key to one of several Pools. Each shard is a separate Pool with the usual semantics.
+type ShardedPool struct {
+	names    []string
+	pools    []*Pool
+	byName   map[string]*Pool
+	selector ShardSelector
+}
+
+// NewSharded creates a ShardedPool with a shard for each entry in connStrings. The keys of connStrings are the shard
+// names.
+func NewSharded(ctx context.Context, connStrings map[string]string) (*ShardedPool, error) {
+	config := &ShardedConfig{Shards: make([]ShardConfig, 0, len(connStrings))}
+	for name, connString := range connStrings {
+		poolConfig, err := ParseConfig(connString)
+		if err != nil {
+			return nil, fmt.Errorf("shard %s: %w", name, err)
+		}
+		config.Shards = append(config.Shards, ShardConfig{Name: name, Config: poolConfig})
+	}
+	sort.Slice(config.Shards, func(i, j int) bool { return config.Shards[i].Name < config.Shards[j].Name })
+
+	return NewShardedWithConfig(ctx, config)
+}
+
+// NewShardedWithConfig creates a ShardedPool. If creating the Pool for any shard fails the Pools already created are
+// closed.
+func NewShardedWithConfig(ctx context.Context, config *ShardedConfig) (*ShardedPool, error) {
+	if len(config.Shards) == 0 {
+		return nil, errors.New("at least one shard is required")
+	}
+
+	s := &ShardedPool{
+		names:    make([]string, 0, len(config.Shards)),
+		pools:    make([]*Pool, 0, len(config.Shards)),
+		byName:   make(map[string]*Pool, len(config.Shards)),
+		selector: config.Selector,
+	}
+	if s.selector == nil {
+		s.selector = RendezvousShardSelector
+	}
+
+	for _, shard := range config.Shards {
+		if _, ok := s.byName[shard.Name]; ok {
+			s.Close()
+			return nil, fmt.Errorf("duplicate shard name: %s", shard.Name)
+		}
+
+		pool, err := NewWithConfig(ctx, shard.Config)
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("shard %s: %w", shard.Name, err)
+		}
+
+		s.names = append(s.names, shard.Name)
+		s.pools = append(s.pools, pool)
+		s.byName[shard.Name] = pool
+	}
+
+	return s, nil
+}
+
+// Shard returns the Pool of the shard for key.
+func (s *ShardedPool) Shard(key string) *Pool {
+	return s.pools[s.selector(key, s.names)]
+}
+
+// ShardName returns the name of the shard for key.
+func (s *ShardedPool) ShardName(key string) string {
+	return s.names[s.selector(key, s.names)]
+}
+
+// ShardByName returns the Pool of the shard named name.
+func (s *ShardedPool) ShardByName(name string) (*Pool, bool) {
+	pool, ok := s.byName[name]
+	return pool, ok
+}
+
+// Names returns the names of the shards in the order they were configured.
+func (s *ShardedPool) Names() []string {
+	names := make([]string, len(s.names))
+	copy(names, s.names)
+	return names
+}
+
+// Close closes the Pools of all shards. See Pool.Close.
+func (s *ShardedPool) Close() {
+	for _, pool := range s.pools {
+		pool.Close()
+	}
+}
+
+// Stat returns the statistics of all shards.
+func (s *ShardedPool) Stat() *ShardedStat {
+	stat := &ShardedStat{Shards: make(map[string]*Stat, len(s.pools))}
+	for i, pool := range s.pools {
+		stat.Shards[s.names[i]] = pool.Stat()
+	}
+	return stat
+}
+
+// ShardedStat is a snapshot of ShardedPool statistics. The methods return the sum of the statistics of all shards.
+type ShardedStat struct {
+	// Shards is the statistics of each shard by name.
+	Shards map[string]*Stat
+}
+
+func (s *ShardedStat) sumInt32(f func(*Stat) int32) int32 {
+	var n int32
+	for _, stat := range s.Shards {
+		n += f(stat)
+	}
+	return n
+}
+
+func (s *ShardedStat) sumInt64(f func(*Stat) int64) int64 {
+	var n int64
+	for _, stat := range s.Shards {
+		n += f(stat)
+	}
+	return n
+}
+
+// AcquireCount returns the cumulative count of successful acquires from all shards.
+func (s *ShardedStat) AcquireCount() int64 {
+	return s.sumInt64((*Stat).AcquireCount)
+}
+
+// AcquireDuration returns the total duration of all successful acquires from all shards.
+func (s *ShardedStat) AcquireDuration() time.Duration {
+	return time.Duration(s.sumInt64(func(stat *Stat) int64 { return int64(stat.AcquireDuration()) }))
+}
+
+// AcquiredConns returns the number of currently acquired connections in all shards.
+func (s *ShardedStat) AcquiredConns() int32 {
+	return s.sumInt32((*Stat).AcquiredConns)
+}
+
+// CanceledAcquireCount returns the cumulative count of acquires from all shards that were canceled by a context.
+func (s *ShardedStat) CanceledAcquireCount() int64 {
+	return s.sumInt64((*Stat).CanceledAcquireCount)
+}
+
+// ConstructingConns returns the number of conns with construction in progress in all shards.
+func (s *ShardedStat) ConstructingConns() int32 {
+	return s.sumInt32((*Stat).ConstructingConns)
+}
+
+// EmptyAcquireCount returns the cumulative count of successful acquires from all shards that waited for a resource to
+// be released or constructed because the pool was empty.
+func (s *ShardedStat) EmptyAcquireCount() int64 {
+	return s.sumInt64((*Stat).EmptyAcquireCount)
+}
+
+// IdleConns returns the number of currently idle conns in all shards.
+func (s *ShardedStat) IdleConns() int32 {
+	return s.sumInt32((*Stat).IdleConns)
+}
+
+// MaxConns returns the sum of the maximum sizes of all shards.
+func (s *ShardedStat) MaxConns() int32 {
+	return s.sumInt32((*Stat).MaxConns)
+}
+
+// TotalConns returns the total number of resources currently in all shards.
+func (s *ShardedStat) TotalConns() int32 {
+	return s.sumInt32((*Stat).TotalConns)
+}
+
+// NewConnsCount returns the cumulative count of new connections opened in all shards.
+func (s *ShardedStat) NewConnsCount() int64 {
+	return s.sumInt64((*Stat).NewConnsCount)
+}
@@ -0,0 +1,157 @@
+package pgxpool_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRendezvousShardSelector(t *testing.T) {
+	t.Parallel()
+
+	names := []string{"a", "b", "c"}
+	counts := make([]int, len(names))
+	selected := make(map[string]int)
+	for i := 0; i < 3000; i++ {
+		key := fmt.Sprintf("key%d", i)
+		idx := pgxpool.RendezvousShardSelector(key, names)
+		require.Equal(t, idx, pgxpool.RendezvousShardSelector(key, names))
+		counts[idx]++
+		selected[key] = idx
+	}
+
+	for _, n := range counts {
+		assert.InDelta(t, 1000, n, 150)
+	}
+
+	// The shard for a key does not depend on the order of the names.
+	reversed := []string{"c", "b", "a"}
+	for key, idx := range selected {
+		require.Equal(t, names[idx], reversed[pgxpool.RendezvousShardSelector(key, reversed)])
+	}
+
+	// Adding a shard only moves keys to the new shard.
+	withD := []string{"a", "b", "c", "d"}
+	moved := 0
+	for key, idx := range selected {
+		newName := withD[pgxpool.RendezvousShardSelector(key, withD)]
+		if newName != names[idx] {
+			require.Equal(t, "d", newName)
+			moved++
+		}
+	}
+	assert.InDelta(t, 750, moved, 150)
+}
+
+func TestShardedPool(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	sp, err := pgxpool.NewSharded(ctx, map[string]string{
+		"b": "host=127.0.0.1 port=1 sslmode=disable pool_max_conns=3",
+		"a": "host=127.0.0.1 port=1 sslmode=disable pool_max_conns=2",
+	})
+	require.NoError(t, err)
+	defer sp.Close()
+
+	require.Equal(t, []string{"a", "b"}, sp.Names())
+
+	a, ok := sp.ShardByName("a")
+	require.True(t, ok)
+	b, ok := sp.ShardByName("b")
+	require.True(t, ok)
+	_, ok = sp.ShardByName("c")
+	require.False(t, ok)
+
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key%d", i)
+		name := sp.ShardName(key)
+		pool, _ := sp.ShardByName(name)
+		require.Same(t, pool, sp.Shard(key))
+		require.True(t, pool == a || pool == b)
+	}
+
+	stat := sp.Stat()
+	require.Len(t, stat.Shards, 2)
+	assert.EqualValues(t, 2, stat.Shards["a"].MaxConns())
+	assert.EqualValues(t, 3, stat.Shards["b"].MaxConns())
+	assert.EqualValues(t, 5, stat.MaxConns())
+	assert.EqualValues(t, 0, stat.TotalConns())
+}
+
+func TestShardedPoolSelector(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	config := &pgxpool.ShardedConfig{
+		Selector: func(key string, names []string) int {
+			if key == "eu" {
+				return 1
+			}
+			return 0
+		},
+	}
+	for _, name := range []string{"us", "eu"} {
+		poolConfig, err := pgxpool.ParseConfig("host=127.0.0.1 port=1 sslmode=disable")
+		require.NoError(t, err)
+		config.Shards = append(config.Shards, pgxpool.ShardConfig{Name: name, Config: poolConfig})
+	}
+
+	sp, err := pgxpool.NewShardedWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer sp.Close()
+
+	assert.Equal(t, "eu", sp.ShardName("eu"))
+	assert.Equal(t, "us", sp.ShardName("other"))
+}
+
+func TestNewShardedWithConfigErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := pgxpool.NewShardedWithConfig(ctx, &pgxpool.ShardedConfig{})
+	require.Error(t, err)
+
+	poolConfig, err := pgxpool.ParseConfig("host=127.0.0.1 port=1 sslmode=disable")
+	require.NoError(t, err)
+	_, err = pgxpool.NewShardedWithConfig(ctx, &pgxpool.ShardedConfig{
+		Shards: []pgxpool.ShardConfig{{Name: "a", Config: poolConfig}, {Name: "a", Config: poolConfig.Copy()}},
+	})
+	require.EqualError(t, err, "duplicate shard name: a")
+
+	_, err = pgxpool.NewSharded(ctx, map[string]string{"a": "host=127.0.0.1 port=1 pool_max_conns=x"})
+	require.ErrorContains(t, err, "shard a:")
+}
+
+func TestShardedPoolQuery(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	sp, err := pgxpool.NewSharded(ctx, map[string]string{
+		"a": os.Getenv("PGX_TEST_DATABASE"),
+		"b": os.Getenv("PGX_TEST_DATABASE"),
+	})
+	require.NoError(t, err)
+	defer sp.Close()
+
+	var n int32
+	err = sp.Shard("some key").QueryRow(ctx, "select 1").Scan(&n)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+
+	assert.EqualValues(t, 1, sp.Stat().AcquireCount())
+}
@@ -1,6 +1,7 @@
 package pgxpool
 
 import (
+	"sync"
 	"time"
 
 	"github.com/jackc/puddle/v2"
@@ -9,9 +10,16 @@ import (
 // Stat is a snapshot of Pool statistics.
 type Stat struct {
 	s                    *puddle.Stat
+	maxConns             int32
 	newConnsCount        int64
 	lifetimeDestroyCount int64
 	idleDestroyCount     int64
+	idleTrimCount        int64
+
+	// connLimitWaitCount and connLimitWaitDuration are the acquires that waited because MaxConns connections were in use.
+	// The underlying puddle.Pool does not know about these waits.
+	connLimitWaitCount    int64
+	connLimitWaitDuration time.Duration
 }
 
 // AcquireCount returns the cumulative count of successful acquires from the pool.
@@ -22,7 +30,7 @@ func (s *Stat) AcquireCount() int64 {
 // AcquireDuration returns the total duration of all successful acquires from
 // the pool.
 func (s *Stat) AcquireDuration() time.Duration {
-	return s.s.AcquireDuration()
+	return s.s.AcquireDuration() + s.connLimitWaitDuration
 }
 
 // AcquiredConns returns the number of currently acquired connections in the pool.
@@ -46,7 +54,7 @@ func (s *Stat) ConstructingConns() int32 {
 // that waited for a resource to be released or constructed because the pool was
 // empty.
 func (s *Stat) EmptyAcquireCount() int64 {
-	return s.s.EmptyAcquireCount()
+	return s.s.EmptyAcquireCount() + s.connLimitWaitCount
 }
 
 // IdleConns returns the number of currently idle conns in the pool.
@@ -56,7 +64,7 @@ func (s *Stat) IdleConns() int32 {
 
 // MaxConns returns the maximum size of the pool.
 func (s *Stat) MaxConns() int32 {
-	return s.s.MaxResources()
+	return s.maxConns
 }
 
 // TotalConns returns the total number of resources currently in the pool.
@@ -83,9 +91,62 @@ func (s *Stat) MaxIdleDestroyCount() int64 {
 	return s.idleDestroyCount
 }
 
+// IdleTrimCount returns the cumulative count of idle connections trimmed because they exceeded IdleTrimTime.
+func (s *Stat) IdleTrimCount() int64 {
+	return s.idleTrimCount
+}
+
 // EmptyAcquireWaitTime returns the cumulative time waited for successful acquires
 // from the pool for a resource to be released or constructed because the pool was
 // empty.
 func (s *Stat) EmptyAcquireWaitTime() time.Duration {
-	return s.s.EmptyAcquireWaitTime()
+	return s.s.EmptyAcquireWaitTime() + s.connLimitWaitDuration
+}
+
+// StatSample is a Stat recorded by the background stat sampler. See Config.StatSamplePeriod.
+type StatSample struct {
+	Time time.Time
+	Stat *Stat
+}
+
+// statHistory is a fixed-size ring buffer of StatSamples.
+type statHistory struct {
+	mux     sync.Mutex
+	samples []StatSample
+	next    int
+	full    bool
+}
+
+func newStatHistory(size int) *statHistory {
+	return &statHistory{samples: make([]StatSample, size)}
+}
+
+// add records sample, overwriting the oldest sample if the buffer is full.
+func (h *statHistory) add(sample StatSample) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	h.samples[h.next] = sample
+	h.next++
+	if h.next == len(h.samples) {
+		h.next = 0
+		h.full = true
+	}
+}
+
+// all returns the recorded samples from oldest to newest.
+func (h *statHistory) all() []StatSample {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if !h.full {
+		samples := make([]StatSample, h.next)
+		copy(samples, h.samples[:h.next])
+		return samples
+	}
+
+	samples := make([]StatSample, 0, len(h.samples))
+	samples = append(samples, h.samples[h.next:]...)
+	samples = append(samples, h.samples[:h.next]...)
+	return samples
 }
@@ -0,0 +1,173 @@
+package pgxpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ErrStatementThrottled is returned by Exec, Query, and QueryRow when Config.RejectThrottledStatements is set and the
+// statement is already executing at its concurrency limit.
+var ErrStatementThrottled = errors.New("statement throttled")
+
+// StatementFingerprint returns the fingerprint of sql that is used to apply Config.MaxStatementConcurrency and
+// Config.StatementConcurrencyLimits. Statements that differ only in whitespace outside of quoted strings and
+// identifiers have the same fingerprint.
+func StatementFingerprint(sql string) string {
+	var sb strings.Builder
+	sb.Grow(len(sql))
+
+	var quote rune
+	pendingSpace := false
+	for _, r := range sql {
+		if quote == 0 && unicode.IsSpace(r) {
+			pendingSpace = sb.Len() > 0
+			continue
+		}
+
+		if pendingSpace {
+			sb.WriteByte(' ')
+			pendingSpace = false
+		}
+		sb.WriteRune(r)
+
+		switch {
+		case quote == 0 && (r == '\'' || r == '"'):
+			quote = r
+		case r == quote:
+			// A doubled quote is an escaped quote. It ends and immediately restarts the quoted text.
+			quote = 0
+		}
+	}
+
+	return sb.String()
+}
+
+// statementThrottle limits the number of connections held at the same time for each statement fingerprint.
+type statementThrottle struct {
+	defaultLimit int32
+	limits       map[string]int32
+	reject       bool
+
+	mu    sync.Mutex
+	slots map[string]*statementSlots
+}
+
+// statementSlots are the slots of a fingerprint. They are removed from statementThrottle.slots when no caller holds or
+// waits for a slot.
+type statementSlots struct {
+	throttle    *statementThrottle
+	fingerprint string
+	slots       chan struct{}
+	refs        int
+}
+
+func newStatementThrottle(config *Config) (*statementThrottle, error) {
+	if config.MaxStatementConcurrency < 0 {
+		return nil, fmt.Errorf("MaxStatementConcurrency must not be negative: %d", config.MaxStatementConcurrency)
+	}
+
+	if config.MaxStatementConcurrency == 0 && len(config.StatementConcurrencyLimits) == 0 {
+		return nil, nil
+	}
+
+	t := &statementThrottle{
+		defaultLimit: config.MaxStatementConcurrency,
+		limits:       make(map[string]int32, len(config.StatementConcurrencyLimits)),
+		reject:       config.RejectThrottledStatements,
+		slots:        make(map[string]*statementSlots),
+	}
+
+	for sql, limit := range config.StatementConcurrencyLimits {
+		if limit < 0 {
+			return nil, fmt.Errorf("statement concurrency limit for %q must not be negative: %d", sql, limit)
+		}
+		t.limits[StatementFingerprint(sql)] = limit
+	}
+
+	return t, nil
+}
+
+// acquire waits for a free slot for sql. It returns nil if sql has no concurrency limit. Otherwise, release must be
+// called on the returned slots when the connection used for sql is released.
+func (t *statementThrottle) acquire(ctx context.Context, sql string) (*statementSlots, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	fingerprint := StatementFingerprint(sql)
+	limit, ok := t.limits[fingerprint]
+	if !ok {
+		limit = t.defaultLimit
+	}
+	if limit == 0 {
+		return nil, nil
+	}
+
+	t.mu.Lock()
+	ss := t.slots[fingerprint]
+	if ss == nil {
+		ss = &statementSlots{throttle: t, fingerprint: fingerprint, slots: make(chan struct{}, limit)}
+		t.slots[fingerprint] = ss
+	}
+	ss.refs++
+	t.mu.Unlock()
+
+	if t.reject {
+		select {
+		case ss.slots <- struct{}{}:
+			return ss, nil
+		default:
+			ss.unref()
+			return nil, ErrStatementThrottled
+		}
+	}
+
+	select {
+	case ss.slots <- struct{}{}:
+		return ss, nil
+	case <-ctx.Done():
+		ss.unref()
+		return nil, ctx.Err()
+	}
+}
+
+// release frees the slot acquired by statementThrottle.acquire. ss may be nil.
+func (ss *statementSlots) release() {
+	if ss == nil {
+		return
+	}
+
+	<-ss.slots
+	ss.unref()
+}
+
+func (ss *statementSlots) unref() {
+	t := ss.throttle
+	t.mu.Lock()
+	ss.refs--
+	if ss.refs == 0 {
+		delete(t.slots, ss.fingerprint)
+	}
+	t.mu.Unlock()
+}
+
+// acquireForStatement acquires a connection for executing sql after waiting for a slot for sql.
+func (p *Pool) acquireForStatement(ctx context.Context, sql string) (*Conn, error) {
+	ss, err := p.statementThrottle.acquire(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := p.Acquire(ctx)
+	if err != nil {
+		ss.release()
+		return nil, err
+	}
+	c.statementSlots = ss
+
+	return c, nil
+}
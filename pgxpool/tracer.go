@@ -31,3 +31,14 @@ type ReleaseTracer interface {
 type TraceReleaseData struct {
 	Conn *pgx.Conn
 }
+
+// ConnLeakTracer traces connections that are held for longer than Config.LeakDetectionThreshold.
+type ConnLeakTracer interface {
+	// TraceConnLeak is called at most once per acquisition from a background goroutine while the connection may still
+	// be in use, so it must not use the connection.
+	TraceConnLeak(pool *Pool, data TraceConnLeakData)
+}
+
+type TraceConnLeakData struct {
+	LeakedConn
+}
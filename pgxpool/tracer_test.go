@@ -15,6 +15,7 @@ type testTracer struct {
 	traceAcquireStart func(ctx context.Context, pool *pgxpool.Pool, data pgxpool.TraceAcquireStartData) context.Context
 	traceAcquireEnd   func(ctx context.Context, pool *pgxpool.Pool, data pgxpool.TraceAcquireEndData)
 	traceRelease      func(pool *pgxpool.Pool, data pgxpool.TraceReleaseData)
+	traceConnLeak     func(pool *pgxpool.Pool, data pgxpool.TraceConnLeakData)
 }
 
 type ctxKey string
@@ -38,6 +39,12 @@ func (tt *testTracer) TraceRelease(pool *pgxpool.Pool, data pgxpool.TraceRelease
 	}
 }
 
+func (tt *testTracer) TraceConnLeak(pool *pgxpool.Pool, data pgxpool.TraceConnLeakData) {
+	if tt.traceConnLeak != nil {
+		tt.traceConnLeak(pool, data)
+	}
+}
+
 func (tt *testTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
 	return ctx
 }
@@ -128,3 +135,38 @@ func TestTraceRelease(t *testing.T) {
 	c.Release()
 	require.True(t, traceReleaseCalled)
 }
+
+func TestTraceConnLeak(t *testing.T) {
+	t.Parallel()
+
+	tracer := &testTracer{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.ConnConfig.Tracer = tracer
+	config.LeakDetectionThreshold = 100 * time.Millisecond
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	leaks := make(chan pgxpool.TraceConnLeakData, 10)
+	tracer.traceConnLeak = func(p *pgxpool.Pool, data pgxpool.TraceConnLeakData) {
+		leaks <- data
+	}
+
+	c, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	defer c.Release()
+
+	select {
+	case data := <-leaks:
+		require.Same(t, c.Conn(), data.Conn)
+		require.GreaterOrEqual(t, data.HeldFor, config.LeakDetectionThreshold)
+	case <-ctx.Done():
+		t.Fatal("leak was not traced")
+	}
+}
@@ -48,7 +48,7 @@ func (tx *Tx) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnName
 }
 
 func (tx *Tx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
-	return tx.t.SendBatch(ctx, b)
+	return tx.t.SendBatch(ctx, tx.c.p.batchWithQueryRewriter(b))
 }
 
 func (tx *Tx) LargeObjects() pgx.LargeObjects {
@@ -67,15 +67,15 @@ func (tx *Tx) Prepare(ctx context.Context, name, sql string) (*pgconn.StatementD
 }
 
 func (tx *Tx) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
-	return tx.t.Exec(ctx, sql, arguments...)
+	return tx.t.Exec(ctx, sql, tx.c.p.withQueryRewriter(arguments)...)
 }
 
 func (tx *Tx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
-	return tx.t.Query(ctx, sql, args...)
+	return tx.t.Query(ctx, sql, tx.c.p.withQueryRewriter(args)...)
 }
 
 func (tx *Tx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
-	return tx.t.QueryRow(ctx, sql, args...)
+	return tx.t.QueryRow(ctx, sql, tx.c.p.withQueryRewriter(args)...)
 }
 
 func (tx *Tx) Conn() *pgx.Conn {
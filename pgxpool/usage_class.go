@@ -0,0 +1,147 @@
+package pgxpool
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/puddle/v2"
+)
+
+// UsageClass identifies a category of pool usage such as latency-critical OLTP traffic, batch jobs, or reporting.
+// Acquisitions are tagged with a UsageClass with WithUsageClass. Limits for each UsageClass are configured with
+// Config.UsageClasses.
+type UsageClass string
+
+// UsageClassConfig configures the limits of a UsageClass.
+type UsageClassConfig struct {
+	// MaxConns is the maximum number of connections that may be acquired for the class at the same time. Acquire blocks
+	// until a connection is released by the class or the context is canceled. If 0, the class is only limited by
+	// Config.MaxConns. This prevents a class such as long-running reports from starving other classes.
+	MaxConns int32
+
+	// MaxConnIdleTime, if greater than 0, is used by the health check instead of Config.MaxConnIdleTime for
+	// connections last acquired for the class.
+	MaxConnIdleTime time.Duration
+
+	// StatementTimeout, if greater than 0, is set as the statement_timeout of the connection while it is acquired for
+	// the class. It is reset to the session default when the connection is next acquired without a statement timeout. It
+	// is ignored with CompatibilityModeTransactionPooler. It is truncated to whole milliseconds so it must be at least
+	// 1ms.
+	StatementTimeout time.Duration
+}
+
+type usageClassCtxKey struct{}
+
+// WithUsageClass returns a copy of ctx that causes connections acquired with it to be acquired for class. Classes that
+// are not configured in Config.UsageClasses have no limits.
+func WithUsageClass(ctx context.Context, class UsageClass) context.Context {
+	return context.WithValue(ctx, usageClassCtxKey{}, class)
+}
+
+// UsageClassFromContext returns the UsageClass set with WithUsageClass.
+func UsageClassFromContext(ctx context.Context) (UsageClass, bool) {
+	class, ok := ctx.Value(usageClassCtxKey{}).(UsageClass)
+	return class, ok
+}
+
+type usageClass struct {
+	name   UsageClass
+	config UsageClassConfig
+
+	// slots limits the number of connections acquired for the class. It is nil if the class has no MaxConns.
+	slots chan struct{}
+}
+
+func newUsageClasses(configs map[UsageClass]UsageClassConfig) (map[UsageClass]*usageClass, error) {
+	if len(configs) == 0 {
+		return nil, nil
+	}
+
+	classes := make(map[UsageClass]*usageClass, len(configs))
+	for name, config := range configs {
+		if config.MaxConns < 0 {
+			return nil, fmt.Errorf("usage class %q MaxConns must be >= 0", name)
+		}
+		// statement_timeout has millisecond resolution and a timeout of 0 disables it.
+		if config.StatementTimeout > 0 && config.StatementTimeout < time.Millisecond {
+			return nil, fmt.Errorf("usage class %q StatementTimeout must be 0 or at least 1ms", name)
+		}
+
+		uc := &usageClass{name: name, config: config}
+		if config.MaxConns > 0 {
+			uc.slots = make(chan struct{}, config.MaxConns)
+		}
+		classes[name] = uc
+	}
+
+	return classes, nil
+}
+
+// acquireUsageClassSlot waits for a free slot in the usage class of ctx. It returns nil if ctx has no configured usage
+// class.
+func (p *Pool) acquireUsageClassSlot(ctx context.Context) (*usageClass, error) {
+	name, ok := UsageClassFromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	uc := p.usageClasses[name]
+	if uc == nil || uc.slots == nil {
+		return uc, nil
+	}
+
+	select {
+	case uc.slots <- struct{}{}:
+		return uc, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (uc *usageClass) releaseSlot() {
+	if uc != nil && uc.slots != nil {
+		<-uc.slots
+	}
+}
+
+// applyUsageClass sets the statement timeout of the connection in res for uc. uc may be nil.
+func (p *Pool) applyUsageClass(ctx context.Context, res *puddle.Resource[*connResource], uc *usageClass) error {
+	cr := res.Value()
+	cr.usageClass = uc
+
+	// A session level SET is not reliable with a transaction pooler.
+	if p.config.CompatibilityMode == CompatibilityModeTransactionPooler {
+		return nil
+	}
+
+	var statementTimeout time.Duration
+	if uc != nil {
+		statementTimeout = uc.config.StatementTimeout
+	}
+
+	if statementTimeout == cr.statementTimeout {
+		return nil
+	}
+
+	var err error
+	if statementTimeout > 0 {
+		_, err = cr.conn.Exec(ctx, fmt.Sprintf("set statement_timeout = %d", statementTimeout.Milliseconds()))
+	} else {
+		_, err = cr.conn.Exec(ctx, "reset statement_timeout")
+	}
+	if err != nil {
+		return err
+	}
+
+	cr.statementTimeout = statementTimeout
+	return nil
+}
+
+// maxConnIdleTimeFor returns the max idle time of the connection in res.
+func (p *Pool) maxConnIdleTimeFor(res *puddle.Resource[*connResource]) time.Duration {
+	if uc := res.Value().usageClass; uc != nil && uc.config.MaxConnIdleTime > 0 {
+		return uc.config.MaxConnIdleTime
+	}
+	return p.maxConnIdleTime
+}
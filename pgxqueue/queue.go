@@ -0,0 +1,231 @@
+// Package pgxqueue is a job queue stored in a PostgreSQL table.
+//
+// Jobs are claimed with SELECT ... FOR UPDATE SKIP LOCKED so any number of workers can fetch jobs concurrently without
+// blocking each other or claiming the same job. A claimed job is leased to the worker until its lock expires. A worker
+// processing a job for longer than the lock duration must extend the lease with Heartbeat. A job whose lease expires,
+// e.g. because its worker crashed, becomes available to other workers.
+//
+// The queue table can be created with CreateTable. Its schema is:
+//
+//	create table jobs (
+//	    id bigserial primary key,
+//	    payload jsonb not null,
+//	    run_at timestamptz not null default now(),
+//	    attempts int4 not null default 0,
+//	    lock_token text,
+//	    locked_until timestamptz
+//	);
+//
+// Typical usage:
+//
+//	queue, err := pgxqueue.New(pool, &pgxqueue.Config{Table: pgx.Identifier{"jobs"}, LockDuration: time.Minute})
+//	...
+//	jobs, err := queue.FetchAndLock(ctx, 10)
+//	for _, job := range jobs {
+//	    err := process(job.Payload)
+//	    if err != nil {
+//	        queue.Release(ctx, job, time.Now().Add(time.Minute)) // retry in a minute
+//	        continue
+//	    }
+//	    queue.Complete(ctx, job)
+//	}
+package pgxqueue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrLockLost is returned when the lock of a job is no longer held by the worker. This occurs when the lock expired and
+// the job was claimed by another worker or when the job was already completed or released.
+var ErrLockLost = errors.New("pgxqueue: job lock lost")
+
+// DB is the interface required by Queue. It is implemented by *pgx.Conn, *pgxpool.Pool, and pgx.Tx.
+type DB interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Config configures a Queue.
+type Config struct {
+	// Table is the name of the queue table. It is required.
+	Table pgx.Identifier
+
+	// LockDuration is how long a job is locked when it is fetched or its lock is extended by Heartbeat. It must be
+	// greater than 0.
+	LockDuration time.Duration
+}
+
+// Queue is a job queue stored in a PostgreSQL table. It is safe for concurrent use if its DB is.
+type Queue struct {
+	db           DB
+	table        string
+	lockDuration time.Duration
+}
+
+// Job is a job claimed by FetchAndLock.
+type Job struct {
+	ID       int64
+	Payload  []byte
+	Attempts int32
+
+	// LockedUntil is when the lock of the job expires. It is updated by Heartbeat.
+	LockedUntil time.Time
+
+	lockToken string
+}
+
+// New returns a Queue that stores jobs in config.Table using db.
+func New(db DB, config *Config) (*Queue, error) {
+	if len(config.Table) == 0 {
+		return nil, errors.New("pgxqueue: Config.Table is required")
+	}
+	if config.LockDuration <= 0 {
+		return nil, errors.New("pgxqueue: Config.LockDuration must be greater than 0")
+	}
+
+	return &Queue{
+		db:           db,
+		table:        config.Table.Sanitize(),
+		lockDuration: config.LockDuration,
+	}, nil
+}
+
+// CreateTable creates the queue table if it does not already exist.
+func (q *Queue) CreateTable(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, fmt.Sprintf(`create table if not exists %s (
+	id bigserial primary key,
+	payload jsonb not null,
+	run_at timestamptz not null default now(),
+	attempts int4 not null default 0,
+	lock_token text,
+	locked_until timestamptz
+)`, q.table))
+	return err
+}
+
+// Enqueue adds a job to the queue that will be available to workers at runAt. payload is encoded as jsonb. If runAt is
+// the zero value, the job is available immediately. It returns the ID of the new job.
+func (q *Queue) Enqueue(ctx context.Context, payload any, runAt time.Time) (int64, error) {
+	var runAtArg any
+	if !runAt.IsZero() {
+		runAtArg = runAt
+	}
+
+	var id int64
+	err := q.db.QueryRow(ctx,
+		fmt.Sprintf(`insert into %s (payload, run_at) values ($1, coalesce($2, now())) returning id`, q.table),
+		payload, runAtArg,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// FetchAndLock claims up to limit jobs that are available to run, oldest first. Jobs that are locked by other workers
+// are skipped. Each claimed job is locked for the configured lock duration and its attempts are incremented. It returns
+// an empty slice if no jobs are available.
+func (q *Queue) FetchAndLock(ctx context.Context, limit int) ([]*Job, error) {
+	lockToken, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.db.Query(ctx, fmt.Sprintf(`with next as (
+	select id
+	from %[1]s
+	where run_at <= now()
+		and (locked_until is null or locked_until < now())
+	order by run_at, id
+	limit $1
+	for update skip locked
+)
+update %[1]s j
+set attempts = j.attempts + 1,
+	lock_token = $2,
+	locked_until = now() + make_interval(secs => $3)
+from next
+where j.id = next.id
+returning j.id, j.payload, j.attempts, j.locked_until`, q.table),
+		limit, lockToken, q.lockDuration.Seconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*Job, error) {
+		job := &Job{lockToken: lockToken}
+		err := row.Scan(&job.ID, &job.Payload, &job.Attempts, &job.LockedUntil)
+		return job, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// Heartbeat extends the lock of job by the configured lock duration. It returns ErrLockLost if the lock is no longer
+// held.
+func (q *Queue) Heartbeat(ctx context.Context, job *Job) error {
+	err := q.db.QueryRow(ctx,
+		fmt.Sprintf(`update %s set locked_until = now() + make_interval(secs => $3) where id = $1 and lock_token = $2 returning locked_until`, q.table),
+		job.ID, job.lockToken, q.lockDuration.Seconds(),
+	).Scan(&job.LockedUntil)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrLockLost
+	}
+	return err
+}
+
+// Complete deletes job from the queue. It returns ErrLockLost if the lock is no longer held.
+func (q *Queue) Complete(ctx context.Context, job *Job) error {
+	ct, err := q.db.Exec(ctx, fmt.Sprintf(`delete from %s where id = $1 and lock_token = $2`, q.table), job.ID, job.lockToken)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Release unlocks job so it will be available to workers again at runAt. If runAt is the zero value, the job is
+// available immediately. It returns ErrLockLost if the lock is no longer held.
+func (q *Queue) Release(ctx context.Context, job *Job, runAt time.Time) error {
+	var runAtArg any
+	if !runAt.IsZero() {
+		runAtArg = runAt
+	}
+
+	ct, err := q.db.Exec(ctx,
+		fmt.Sprintf(`update %s set lock_token = null, locked_until = null, run_at = coalesce($3, now()) where id = $1 and lock_token = $2`, q.table),
+		job.ID, job.lockToken, runAtArg,
+	)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+func newLockToken() (string, error) {
+	buf := make([]byte, 16)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
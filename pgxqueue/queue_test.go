@@ -0,0 +1,69 @@
+package pgxqueue_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxqueue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	conn, err := pgx.Connect(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer conn.Close(ctx)
+
+	queue, err := pgxqueue.New(conn, &pgxqueue.Config{Table: pgx.Identifier{"pg_temp", "jobs"}, LockDuration: time.Minute})
+	require.NoError(t, err)
+	require.NoError(t, queue.CreateTable(ctx))
+
+	for i := 0; i < 3; i++ {
+		_, err := queue.Enqueue(ctx, map[string]any{"n": i}, time.Time{})
+		require.NoError(t, err)
+	}
+	_, err = queue.Enqueue(ctx, map[string]any{"n": 3}, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	jobs, err := queue.FetchAndLock(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	require.JSONEq(t, `{"n": 0}`, string(jobs[0].Payload))
+	require.EqualValues(t, 1, jobs[0].Attempts)
+
+	// Locked jobs and jobs scheduled in the future are not fetched.
+	moreJobs, err := queue.FetchAndLock(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, moreJobs, 1)
+	require.JSONEq(t, `{"n": 2}`, string(moreJobs[0].Payload))
+
+	lockedUntil := jobs[0].LockedUntil
+	require.NoError(t, queue.Heartbeat(ctx, jobs[0]))
+	require.False(t, jobs[0].LockedUntil.Before(lockedUntil))
+
+	require.NoError(t, queue.Complete(ctx, jobs[0]))
+	require.ErrorIs(t, queue.Complete(ctx, jobs[0]), pgxqueue.ErrLockLost)
+	require.ErrorIs(t, queue.Heartbeat(ctx, jobs[0]), pgxqueue.ErrLockLost)
+
+	require.NoError(t, queue.Release(ctx, jobs[1], time.Time{}))
+	require.ErrorIs(t, queue.Release(ctx, jobs[1], time.Time{}), pgxqueue.ErrLockLost)
+
+	retriedJobs, err := queue.FetchAndLock(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, retriedJobs, 1)
+	require.Equal(t, jobs[1].ID, retriedJobs[0].ID)
+	require.EqualValues(t, 2, retriedJobs[0].Attempts)
+}
+
+func TestNewValidatesConfig(t *testing.T) {
+	_, err := pgxqueue.New(nil, &pgxqueue.Config{LockDuration: time.Minute})
+	require.Error(t, err)
+
+	_, err = pgxqueue.New(nil, &pgxqueue.Config{Table: pgx.Identifier{"jobs"}})
+	require.Error(t, err)
+}
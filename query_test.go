@@ -2246,3 +2246,36 @@ insert into products (name, price) values
 	// Fries: $5
 	// Soft Drink: $3
 }
+
+func TestQueryNextRowTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		rows, err := conn.Query(ctx, "select n from generate_series(1, 10) n", pgx.QueryNextRowTimeout(5*time.Second))
+		require.NoError(t, err)
+		numbers, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Len(t, numbers, 10)
+
+		// The server only flushes its output buffer when it is full or the query is finished. Produce enough rows before
+		// the sleep for some of them to be received.
+		rows, err = conn.Query(ctx,
+			"select n, pg_sleep(case when n = 100000 then 5 else 0 end) from generate_series(1, 100000) n",
+			pgx.QueryNextRowTimeout(time.Second),
+		)
+		require.NoError(t, err)
+		rowCount := 0
+		for rows.Next() {
+			rowCount++
+		}
+		require.Greater(t, rowCount, 0)
+		require.Less(t, rowCount, 100000)
+		var rowTimeoutErr *pgconn.RowTimeoutError
+		require.ErrorAs(t, rows.Err(), &rowTimeoutErr)
+		require.ErrorIs(t, rows.Err(), context.DeadlineExceeded)
+		require.NoError(t, ctx.Err())
+	})
+}
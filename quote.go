@@ -0,0 +1,130 @@
+package pgx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// QuoteIdentifier returns parts quoted as a possibly qualified identifier such as "schema"."table". It is equivalent to
+// Identifier(parts).Sanitize(). It is provided on Conn alongside QuoteLiteral and QuoteValue for convenience when
+// generating SQL.
+func (c *Conn) QuoteIdentifier(parts ...string) string {
+	return Identifier(parts).Sanitize()
+}
+
+// QuoteLiteral returns s quoted as a string literal that is safe to interpolate into SQL executed on c. The quoting
+// depends on the standard_conforming_strings setting of the connection. When it is off and s contains a backslash, an
+// escape string constant (E'...') is returned.
+//
+// An error is returned if s contains a zero byte, which PostgreSQL does not allow in text, or if the connection uses a
+// client_encoding in which a multibyte character may contain a quote or backslash byte (e.g. SJIS or BIG5).
+//
+// The result is only valid for the connection's current settings. It should not be saved and used on another
+// connection or after the settings have been changed.
+func (c *Conn) QuoteLiteral(s string) (string, error) {
+	standardConformingStrings, err := c.quoteSettings()
+	if err != nil {
+		return "", err
+	}
+
+	if strings.IndexByte(s, 0) != -1 {
+		return "", errors.New("cannot quote literal containing a zero byte")
+	}
+
+	return quoteLiteral(s, standardConformingStrings), nil
+}
+
+// QuoteBytea returns buf as a bytea literal in the hex format such as '\x0102'::bytea. A nil buf is returned as NULL.
+// Like QuoteLiteral, the result depends on the settings of the connection.
+func (c *Conn) QuoteBytea(buf []byte) (string, error) {
+	if buf == nil {
+		return "NULL", nil
+	}
+	return c.QuoteValue(buf)
+}
+
+// QuoteValue returns value as a literal with an explicit type cast such as '42'::"int4" or '{1,2,3}'::"_int4". The
+// PostgreSQL type is the type registered in the connection's type map for the Go type of value. value is encoded in the
+// text format by the type map in the same way as a query argument. This makes it possible to produce array, bytea,
+// range, composite, and other non-string literals for dynamic SQL without duplicating the encoding logic of pgtype.
+// e.g. QuoteValue([]string{"a", "b,c"}) returns '{a,"b,c"}'::"_text".
+//
+// A nil value or a value that encodes to NULL is returned as NULL. An error is returned if no type is registered for
+// the Go type of value. Like QuoteLiteral, the result depends on the settings of the connection.
+func (c *Conn) QuoteValue(value any) (string, error) {
+	standardConformingStrings, err := c.quoteSettings()
+	if err != nil {
+		return "", err
+	}
+
+	return quoteValue(c.typeMap, value, standardConformingStrings)
+}
+
+// quoteSettings returns the settings of the connection that affect quoting. It returns an error if the connection uses
+// a client_encoding for which quoting is not safe.
+func (c *Conn) quoteSettings() (standardConformingStrings bool, err error) {
+	switch encoding := c.pgConn.ParameterStatus("client_encoding"); strings.ToUpper(encoding) {
+	case "SJIS", "SHIFT_JIS_2004", "BIG5", "GBK", "UHC", "GB18030", "JOHAB":
+		// These encodings are only allowed as client encodings because the second byte of a multibyte character can be
+		// an ASCII byte such as a quote or backslash.
+		return false, fmt.Errorf("cannot quote literal with client_encoding=%s", encoding)
+	}
+
+	return c.pgConn.ParameterStatus("standard_conforming_strings") == "on", nil
+}
+
+func quoteValue(m *pgtype.Map, value any, standardConformingStrings bool) (string, error) {
+	if value == nil {
+		return "NULL", nil
+	}
+
+	dt, ok := m.TypeForValue(value)
+	if !ok {
+		return "", fmt.Errorf("cannot find PostgreSQL type for %T", value)
+	}
+
+	buf, err := m.Encode(dt.OID, TextFormatCode, value, nil)
+	if err != nil {
+		return "", err
+	}
+	if buf == nil {
+		return "NULL", nil
+	}
+
+	s := string(buf)
+	if strings.IndexByte(s, 0) != -1 {
+		return "", fmt.Errorf("cannot quote %T value containing a zero byte", value)
+	}
+
+	// The type name may be qualified with a schema and may contain characters that must be quoted.
+	return quoteLiteral(s, standardConformingStrings) + "::" + Identifier(strings.Split(dt.Name, ".")).Sanitize(), nil
+}
+
+// quoteLiteral quotes s as a string literal. When standardConformingStrings is false backslashes are escaped in an
+// escape string constant.
+func quoteLiteral(s string, standardConformingStrings bool) string {
+	escapeBackslashes := !standardConformingStrings && strings.IndexByte(s, '\\') != -1
+
+	var sb strings.Builder
+	sb.Grow(len(s) + 3)
+	if escapeBackslashes {
+		sb.WriteByte('E')
+	}
+	sb.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\'':
+			sb.WriteString("''")
+		case s[i] == '\\' && escapeBackslashes:
+			sb.WriteString(`\\`)
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	sb.WriteByte('\'')
+
+	return sb.String()
+}
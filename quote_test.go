@@ -0,0 +1,101 @@
+package pgx_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnQuoteLiteral(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn)
+
+	pgxtest.SkipCockroachDB(t, conn, "Server does not support standard_conforming_strings = off (https://github.com/cockroachdb/cockroach/issues/36215)")
+
+	strs := []string{
+		"",
+		"foo",
+		"it's",
+		`back\slash`,
+		`\'; drop table users; --`,
+		`'\\''`,
+		"日本語",
+	}
+
+	for _, scs := range []string{"on", "off"} {
+		mustExec(t, conn, "set standard_conforming_strings to "+scs)
+
+		for _, s := range strs {
+			quoted, err := conn.QuoteLiteral(s)
+			require.NoError(t, err)
+
+			var result string
+			err = conn.QueryRow(context.Background(), "select "+quoted, pgx.QueryExecModeSimpleProtocol).Scan(&result)
+			require.NoErrorf(t, err, "standard_conforming_strings=%s: %s", scs, quoted)
+			require.Equalf(t, s, result, "standard_conforming_strings=%s: %s", scs, quoted)
+		}
+	}
+
+	_, err := conn.QuoteLiteral("a\x00b")
+	require.Error(t, err)
+
+	ensureConnValid(t, conn)
+}
+
+func TestConnQuoteValue(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn)
+
+	pgxtest.SkipCockroachDB(t, conn, "Server does not support standard_conforming_strings = off (https://github.com/cockroachdb/cockroach/issues/36215)")
+
+	for _, scs := range []string{"on", "off"} {
+		mustExec(t, conn, "set standard_conforming_strings to "+scs)
+
+		quoted, err := conn.QuoteBytea([]byte{0, 1, '\\', '\'', 255})
+		require.NoError(t, err)
+		var buf []byte
+		err = conn.QueryRow(context.Background(), "select "+quoted, pgx.QueryExecModeSimpleProtocol).Scan(&buf)
+		require.NoError(t, err)
+		require.Equal(t, []byte{0, 1, '\\', '\'', 255}, buf)
+
+		quoted, err = conn.QuoteValue([]string{"a", "b c", `d"e`, `f\g`, "h'i"})
+		require.NoError(t, err)
+		var strs []string
+		err = conn.QueryRow(context.Background(), "select "+quoted, pgx.QueryExecModeSimpleProtocol).Scan(&strs)
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b c", `d"e`, `f\g`, "h'i"}, strs)
+
+		quoted, err = conn.QuoteValue([]int32{1, 2, 3})
+		require.NoError(t, err)
+		var n []int32
+		err = conn.QueryRow(context.Background(), "select "+quoted, pgx.QueryExecModeSimpleProtocol).Scan(&n)
+		require.NoError(t, err)
+		require.Equal(t, []int32{1, 2, 3}, n)
+	}
+
+	quoted, err := conn.QuoteValue(nil)
+	require.NoError(t, err)
+	require.Equal(t, "NULL", quoted)
+
+	_, err = conn.QuoteValue(struct{}{})
+	require.Error(t, err)
+
+	ensureConnValid(t, conn)
+}
+
+func TestConnQuoteIdentifier(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn)
+
+	require.Equal(t, `"public"."my ""table"""`, conn.QuoteIdentifier("public", `my "table"`))
+}
@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -135,6 +137,15 @@ type baseRows struct {
 	scanPlans []pgtype.ScanPlan
 	scanTypes []reflect.Type
 
+	// namedStructType and namedStructFields cache the most recent struct mapping computed by RowToStructByName and
+	// friends so it is not looked up again for each row.
+	namedStructType   reflect.Type
+	namedStructFields *namedStructFields
+
+	// scanByNameNames and scanByNameIndexes cache the most recent column names passed to ScanByName and their positions.
+	scanByNameNames   []string
+	scanByNameIndexes []int
+
 	conn              *Conn
 	multiResultReader *pgconn.MultiResultReader
 
@@ -181,6 +192,8 @@ func (rows *baseRows) Close() {
 		if sc := rows.conn.descriptionCache; sc != nil {
 			sc.Invalidate(rows.sql)
 		}
+
+		rows.err = rows.conn.planInvalidatedError(rows.err)
 	}
 
 	if rows.batchTracer != nil {
@@ -255,7 +268,7 @@ func (rows *baseRows) Scan(dest ...any) error {
 		rows.scanPlans = make([]pgtype.ScanPlan, len(values))
 		rows.scanTypes = make([]reflect.Type, len(values))
 		for i := range dest {
-			rows.scanPlans[i] = m.PlanScan(fieldDescriptions[i].DataTypeOID, fieldDescriptions[i].Format, dest[i])
+			rows.scanPlans[i] = rows.planScan(&fieldDescriptions[i], dest[i])
 			rows.scanTypes[i] = reflect.TypeOf(dest[i])
 		}
 	}
@@ -266,13 +279,17 @@ func (rows *baseRows) Scan(dest ...any) error {
 		}
 
 		if rows.scanTypes[i] != reflect.TypeOf(dst) {
-			rows.scanPlans[i] = m.PlanScan(fieldDescriptions[i].DataTypeOID, fieldDescriptions[i].Format, dest[i])
+			rows.scanPlans[i] = rows.planScan(&fieldDescriptions[i], dest[i])
 			rows.scanTypes[i] = reflect.TypeOf(dest[i])
 		}
 
 		err := rows.scanPlans[i].Scan(values[i], dst)
+		if err != nil && m.IntegerOutOfRangeScanPolicy != nil {
+			fd := &fieldDescriptions[i]
+			err = m.ResolveScanError(fd.DataTypeOID, fd.Format, values[i], dst, i, fd.Name, err)
+		}
 		if err != nil {
-			err = ScanArgError{ColumnIndex: i, Err: err}
+			err = rows.scanArgError(i, err)
 			rows.fatal(err)
 			return err
 		}
@@ -281,6 +298,15 @@ func (rows *baseRows) Scan(dest ...any) error {
 	return nil
 }
 
+// planScan plans the scan of the column described by fd into target. ConnConfig.ColumnScanPlan is applied if set.
+func (rows *baseRows) planScan(fd *pgconn.FieldDescription, target any) pgtype.ScanPlan {
+	plan := rows.typeMap.PlanScan(fd.DataTypeOID, fd.Format, target)
+	if rows.conn != nil && rows.conn.config.ColumnScanPlan != nil {
+		plan = rows.conn.config.ColumnScanPlan(rows.typeMap, fd, target, plan)
+	}
+	return plan
+}
+
 func (rows *baseRows) Values() ([]any, error) {
 	if rows.closed {
 		return nil, errors.New("rows is closed")
@@ -332,13 +358,105 @@ func (rows *baseRows) Conn() *Conn {
 	return rows.conn
 }
 
+// scanArgError returns a ScanArgError for column i. The query context is included when ConnConfig.ScanErrorContext is
+// enabled.
+func (rows *baseRows) scanArgError(i int, err error) ScanArgError {
+	scanErr := ScanArgError{ColumnIndex: i, Err: err}
+	if rows.conn == nil || !rows.conn.config.ScanErrorContext {
+		return scanErr
+	}
+
+	fd := rows.FieldDescriptions()[i]
+	scanErr.ColumnName = fd.Name
+	scanErr.DataTypeOID = fd.DataTypeOID
+	scanErr.Row = rows.rowCount
+	scanErr.SQL = summarizeScanErrorSQL(rows.sql)
+	scanErr.Args = summarizeScanErrorArgs(rows.args, rows.conn.config.RedactScanErrorArgs)
+	return scanErr
+}
+
+const (
+	scanErrorSQLMaxLen = 64
+	scanErrorArgMaxLen = 32
+)
+
+// summarizeScanErrorSQL collapses whitespace in sql and truncates it.
+func summarizeScanErrorSQL(sql string) string {
+	return truncateScanErrorValue(strings.Join(strings.Fields(sql), " "), scanErrorSQLMaxLen)
+}
+
+// summarizeScanErrorArgs formats args as $1=value, ... with each value truncated. If redact is true the types of the
+// values are used instead.
+func summarizeScanErrorArgs(args []any, redact bool) string {
+	sb := &strings.Builder{}
+	for i, arg := range args {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(sb, "$%d=", i+1)
+		if redact {
+			fmt.Fprintf(sb, "%T", arg)
+		} else {
+			sb.WriteString(truncateScanErrorValue(fmt.Sprintf("%#v", arg), scanErrorArgMaxLen))
+		}
+	}
+	return sb.String()
+}
+
+func truncateScanErrorValue(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	// Do not split a multi-byte character.
+	n := maxLen
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "..."
+}
+
+// ColumnScanPlanFunc chooses the scan plan for a column of a query result. It allows special decoding of specific
+// table columns rather than only by type. e.g. Transparently decrypting an encrypted bytea column.
+//
+// fd describes the column. Its TableOID and TableAttributeNumber identify the table column the result column is
+// selected from, if any. plan is the scan plan chosen by m for the type of the column and target. The returned plan is
+// used instead. It may be plan itself, a plan that wraps plan, or a plan of a different codec. e.g.
+// codec.PlanScan(m, fd.DataTypeOID, fd.Format, target).
+//
+// The returned plan is reused for all rows of the result as long as the type of target is unchanged.
+type ColumnScanPlanFunc func(m *pgtype.Map, fd *pgconn.FieldDescription, target any, plan pgtype.ScanPlan) pgtype.ScanPlan
+
+// ScanArgError is returned when scanning the value of a column fails.
 type ScanArgError struct {
 	ColumnIndex int
 	Err         error
+
+	// The following fields are only set when ConnConfig.ScanErrorContext is enabled.
+
+	// ColumnName is the name of the column.
+	ColumnName string
+
+	// DataTypeOID is the OID of the type of the column.
+	DataTypeOID uint32
+
+	// Row is the 1-based number of the row in the result set.
+	Row int
+
+	// SQL is the SQL of the query with whitespace collapsed, truncated to a length that identifies the query in a log.
+	SQL string
+
+	// Args is a summary of the arguments of the query. Long values are truncated. Values are replaced by their types
+	// when ConnConfig.RedactScanErrorArgs is enabled.
+	Args string
 }
 
 func (e ScanArgError) Error() string {
-	return fmt.Sprintf("can't scan into dest[%d]: %v", e.ColumnIndex, e.Err)
+	if e.Row == 0 {
+		return fmt.Sprintf("can't scan into dest[%d]: %v", e.ColumnIndex, e.Err)
+	}
+
+	return fmt.Sprintf("can't scan into dest[%d] (column %q, OID %d, row %d, sql %q, args [%s]): %v",
+		e.ColumnIndex, e.ColumnName, e.DataTypeOID, e.Row, e.SQL, e.Args, e.Err)
 }
 
 func (e ScanArgError) Unwrap() error {
@@ -446,6 +564,62 @@ func CollectRows[T any](rows Rows, fn RowToFunc[T]) ([]T, error) {
 	return AppendRows([]T{}, rows, fn)
 }
 
+// CollectRowsWithCapacity is like CollectRows but preallocates the result slice with capacity. This avoids repeatedly
+// growing the slice when the number of rows is known or can be estimated in advance. e.g. from the LIMIT of the query.
+// An error is returned if capacity is negative.
+//
+// This function closes the rows automatically on return.
+func CollectRowsWithCapacity[T any](rows Rows, capacity int, fn RowToFunc[T]) ([]T, error) {
+	if capacity < 0 {
+		rows.Close()
+		return nil, errors.New("capacity must not be negative")
+	}
+
+	return AppendRows(make([]T, 0, capacity), rows, fn)
+}
+
+// CollectRowsChunked iterates through rows, calling fn for each row, and collecting the results into chunks of up to
+// chunkSize values. chunkFn is called with each chunk as it is filled and with the final partial chunk, if any. This
+// bounds the memory used to process very large result sets. The slice passed to chunkFn is reused for the next chunk so
+// chunkFn must not retain it. If chunkFn returns an error iteration stops and that error is returned.
+//
+// This function closes the rows automatically on return.
+func CollectRowsChunked[T any](rows Rows, chunkSize int, fn RowToFunc[T], chunkFn func(chunk []T) error) error {
+	defer rows.Close()
+
+	if chunkSize < 1 {
+		return errors.New("chunkSize must be greater than 0")
+	}
+
+	chunk := make([]T, 0, chunkSize)
+	for rows.Next() {
+		value, err := fn(rows)
+		if err != nil {
+			return err
+		}
+		chunk = append(chunk, value)
+
+		if len(chunk) == chunkSize {
+			err = chunkFn(chunk)
+			if err != nil {
+				return err
+			}
+			clear(chunk)
+			chunk = chunk[:0]
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(chunk) > 0 {
+		return chunkFn(chunk)
+	}
+
+	return nil
+}
+
 // CollectOneRow calls fn for the first row in rows and returns the result. If no rows are found returns an error where errors.Is(ErrNoRows) is true.
 // CollectOneRow is to CollectRows as QueryRow is to Query.
 //
@@ -609,7 +783,7 @@ func computeStructFields(
 		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
 			fields = computeStructFields(sf.Type, fields, fieldStack)
 		} else if sf.PkgPath == "" {
-			dbTag, _ := sf.Tag.Lookup(structTagKey)
+			dbTag, _ := sf.Tag.Lookup(pgtype.StructTagKey)
 			if dbTag == "-" {
 				// Field is ignored, skip it.
 				continue
@@ -624,8 +798,10 @@ func computeStructFields(
 }
 
 // RowToStructByName returns a T scanned from row. T must be a struct. T must have the same number of named public
-// fields as row has fields. The row and T fields will be matched by name. The match is case-insensitive. The database
-// column name can be overridden with a "db" struct tag. If the "db" struct tag is "-" then the field will be ignored.
+// fields as row has fields. The row and T fields will be matched by name. The database column name can be overridden
+// with a "db" struct tag. If the "db" struct tag is "-" then the field will be ignored. Fields without a "db" tag are
+// matched with the pgtype.NamingStrategy of the connection's type map. By default, the match is case-insensitive and
+// ignores underscores. See pgtype.StructFields for the complete mapping rules.
 func RowToStructByName[T any](row CollectableRow) (T, error) {
 	var value T
 	err := (&namedStructRowScanner{ptrToStruct: &value}).ScanRow(row)
@@ -633,9 +809,7 @@ func RowToStructByName[T any](row CollectableRow) (T, error) {
 }
 
 // RowToAddrOfStructByName returns the address of a T scanned from row. T must be a struct. T must have the same number
-// of named public fields as row has fields. The row and T fields will be matched by name. The match is
-// case-insensitive. The database column name can be overridden with a "db" struct tag. If the "db" struct tag is "-"
-// then the field will be ignored.
+// of named public fields as row has fields. The row and T fields will be matched by name as in RowToStructByName.
 func RowToAddrOfStructByName[T any](row CollectableRow) (*T, error) {
 	var value T
 	err := (&namedStructRowScanner{ptrToStruct: &value}).ScanRow(row)
@@ -643,8 +817,7 @@ func RowToAddrOfStructByName[T any](row CollectableRow) (*T, error) {
 }
 
 // RowToStructByNameLax returns a T scanned from row. T must be a struct. T must have greater than or equal number of named public
-// fields as row has fields. The row and T fields will be matched by name. The match is case-insensitive. The database
-// column name can be overridden with a "db" struct tag. If the "db" struct tag is "-" then the field will be ignored.
+// fields as row has fields. The row and T fields will be matched by name as in RowToStructByName.
 func RowToStructByNameLax[T any](row CollectableRow) (T, error) {
 	var value T
 	err := (&namedStructRowScanner{ptrToStruct: &value, lax: true}).ScanRow(row)
@@ -652,9 +825,8 @@ func RowToStructByNameLax[T any](row CollectableRow) (T, error) {
 }
 
 // RowToAddrOfStructByNameLax returns the address of a T scanned from row. T must be a struct. T must have greater than or
-// equal number of named public fields as row has fields. The row and T fields will be matched by name. The match is
-// case-insensitive. The database column name can be overridden with a "db" struct tag. If the "db" struct tag is "-"
-// then the field will be ignored.
+// equal number of named public fields as row has fields. The row and T fields will be matched by name as in
+// RowToStructByName.
 func RowToAddrOfStructByNameLax[T any](row CollectableRow) (*T, error) {
 	var value T
 	err := (&namedStructRowScanner{ptrToStruct: &value, lax: true}).ScanRow(row)
@@ -668,8 +840,7 @@ type namedStructRowScanner struct {
 
 func (rs *namedStructRowScanner) ScanRow(rows CollectableRow) error {
 	typ := reflect.TypeOf(rs.ptrToStruct).Elem()
-	fldDescs := rows.FieldDescriptions()
-	namedStructFields, err := lookupNamedStructFields(typ, fldDescs)
+	namedStructFields, err := rowNamedStructFields(rows, typ)
 	if err != nil {
 		return err
 	}
@@ -681,12 +852,164 @@ func (rs *namedStructRowScanner) ScanRow(rows CollectableRow) error {
 	return rows.Scan(scanTargets...)
 }
 
+// ScanByName reads the values of the current row of row into destinations matched to columns by name instead of by
+// position. namesAndDests must be pairs of a column name and a destination as accepted by Scan, e.g.
+// ScanByName(rows, "id", &id, "name", &name). Columns that are not named are skipped. It is an error if a name does not
+// match exactly one column. This prevents values from being silently scanned into the wrong destinations when the
+// columns of a query such as "select *" change.
+//
+// When row is the Rows returned by Query the positions of the columns are only looked up again when the names change.
+func ScanByName(row CollectableRow, namesAndDests ...any) error {
+	if len(namesAndDests)%2 != 0 {
+		return errors.New("ScanByName requires pairs of column names and destinations")
+	}
+
+	names := make([]string, len(namesAndDests)/2)
+	for i := range names {
+		name, ok := namesAndDests[i*2].(string)
+		if !ok {
+			return fmt.Errorf("ScanByName argument %d must be a column name, got %T", i*2, namesAndDests[i*2])
+		}
+		names[i] = name
+	}
+
+	indexes, err := rowScanByNameIndexes(row, names)
+	if err != nil {
+		return err
+	}
+
+	dest := make([]any, len(row.FieldDescriptions()))
+	for i, idx := range indexes {
+		dest[idx] = namesAndDests[i*2+1]
+	}
+
+	return row.Scan(dest...)
+}
+
+// rowScanByNameIndexes returns the positions of the columns of row named names. When row is a *baseRows the positions
+// are cached on row as the field descriptions cannot change while reading a result set.
+func rowScanByNameIndexes(row CollectableRow, names []string) ([]int, error) {
+	br, ok := row.(*baseRows)
+	if ok && slices.Equal(br.scanByNameNames, names) {
+		return br.scanByNameIndexes, nil
+	}
+
+	fldDescs := row.FieldDescriptions()
+	indexes := make([]int, len(names))
+	for i, name := range names {
+		indexes[i] = -1
+		for j := range fldDescs {
+			if fldDescs[j].Name != name {
+				continue
+			}
+			if indexes[i] != -1 {
+				return nil, fmt.Errorf("column name %q is ambiguous", name)
+			}
+			indexes[i] = j
+		}
+		if indexes[i] == -1 {
+			return nil, fmt.Errorf("cannot find column %q in returned row", name)
+		}
+		if slices.Contains(indexes[:i], indexes[i]) {
+			return nil, fmt.Errorf("column %q is named more than once", name)
+		}
+	}
+
+	if ok {
+		br.scanByNameNames = names
+		br.scanByNameIndexes = indexes
+	}
+
+	return indexes, nil
+}
+
+// rowNamedStructFields returns the struct mapping of t for the fields of rows. When rows is a *baseRows the mapping is
+// cached on rows as the field descriptions cannot change while reading a result set.
+func rowNamedStructFields(rows CollectableRow, t reflect.Type) (*namedStructFields, error) {
+	br, ok := rows.(*baseRows)
+	if ok && br.namedStructType == t {
+		return br.namedStructFields, nil
+	}
+
+	namedStructFields, err := lookupNamedStructFields(t, rows.FieldDescriptions(), rowNamingStrategy(rows))
+	if err != nil {
+		return nil, err
+	}
+
+	if ok {
+		br.namedStructType = t
+		br.namedStructFields = namedStructFields
+	}
+
+	return namedStructFields, nil
+}
+
+// StructMapping is a precomputed mapping of the fields of a result set to the fields of the struct T. It can be
+// created once and reused for any number of queries that return the same columns. It is safe for concurrent use.
+type StructMapping[T any] struct {
+	fields []structRowField
+}
+
+// NewStructMapping returns a StructMapping of fieldDescriptions to T using the same matching rules as
+// RowToStructByName with pgtype.DefaultNamingStrategy.
+func NewStructMapping[T any](fieldDescriptions []pgconn.FieldDescription) (*StructMapping[T], error) {
+	return newStructMapping[T](fieldDescriptions, false)
+}
+
+// NewStructMappingLax returns a StructMapping of fieldDescriptions to T using the same matching rules as
+// RowToStructByNameLax with pgtype.DefaultNamingStrategy.
+func NewStructMappingLax[T any](fieldDescriptions []pgconn.FieldDescription) (*StructMapping[T], error) {
+	return newStructMapping[T](fieldDescriptions, true)
+}
+
+func newStructMapping[T any](fieldDescriptions []pgconn.FieldDescription, lax bool) (*StructMapping[T], error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%v is not a struct", typ)
+	}
+
+	namedStructFields, err := lookupNamedStructFields(typ, fieldDescriptions, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !lax && namedStructFields.missingField != "" {
+		return nil, fmt.Errorf("cannot find field %s in returned row", namedStructFields.missingField)
+	}
+
+	return &StructMapping[T]{fields: namedStructFields.fields}, nil
+}
+
+// RowToStruct returns a T scanned from row. It is intended to be used with CollectRows and similar functions. The
+// column names of row are not checked against the mapping, only the number of columns.
+func (m *StructMapping[T]) RowToStruct(row CollectableRow) (T, error) {
+	var value T
+	err := m.scanRow(row, &value)
+	return value, err
+}
+
+// RowToAddrOfStruct returns the address of a T scanned from row. It is intended to be used with CollectRows and
+// similar functions. The column names of row are not checked against the mapping, only the number of columns.
+func (m *StructMapping[T]) RowToAddrOfStruct(row CollectableRow) (*T, error) {
+	var value T
+	err := m.scanRow(row, &value)
+	return &value, err
+}
+
+func (m *StructMapping[T]) scanRow(row CollectableRow, ptrToStruct *T) error {
+	if len(row.FieldDescriptions()) != len(m.fields) {
+		return fmt.Errorf("struct mapping has %d fields but row has %d fields", len(m.fields), len(row.FieldDescriptions()))
+	}
+	scanTargets := setupStructScanTargets(ptrToStruct, m.fields)
+	return row.Scan(scanTargets...)
+}
+
 // Map from namedStructFieldMap -> *namedStructFields
 var namedStructFieldMap sync.Map
 
 type namedStructFieldsKey struct {
 	t        reflect.Type
 	colNames string
+	strategy pgtype.NamingStrategy
 }
 
 type namedStructFields struct {
@@ -699,25 +1022,29 @@ type namedStructFields struct {
 func lookupNamedStructFields(
 	t reflect.Type,
 	fldDescs []pgconn.FieldDescription,
+	strategy pgtype.NamingStrategy,
 ) (*namedStructFields, error) {
+	if strategy == nil {
+		strategy = pgtype.DefaultNamingStrategy
+	}
+
+	// A NamingStrategy that is not comparable cannot be part of the cache key.
+	cacheable := reflect.TypeOf(strategy).Comparable()
 	key := namedStructFieldsKey{
 		t:        t,
 		colNames: joinFieldNames(fldDescs),
 	}
-	if cached, ok := namedStructFieldMap.Load(key); ok {
-		return cached.(*namedStructFields), nil
+	if cacheable {
+		key.strategy = strategy
+		if cached, ok := namedStructFieldMap.Load(key); ok {
+			return cached.(*namedStructFields), nil
+		}
 	}
 
 	// We could probably do two-levels of caching, where we compute the key -> fields mapping
 	// for a type only once, cache it by type, then use that to compute the column -> fields
 	// mapping for a given set of columns.
-	fieldStack := make([]int, 0, 1)
-	fields, missingField := computeNamedStructFields(
-		fldDescs,
-		t,
-		make([]structRowField, len(fldDescs)),
-		&fieldStack,
-	)
+	fields, missingField := computeNamedStructFields(fldDescs, t, strategy)
 	for i, f := range fields {
 		if f.path == nil {
 			return nil, fmt.Errorf(
@@ -727,10 +1054,11 @@ func lookupNamedStructFields(
 		}
 	}
 
-	fieldsIface, _ := namedStructFieldMap.LoadOrStore(
-		key,
-		&namedStructFields{fields: fields, missingField: missingField},
-	)
+	namedFields := &namedStructFields{fields: fields, missingField: missingField}
+	if !cacheable {
+		return namedFields, nil
+	}
+	fieldsIface, _ := namedStructFieldMap.LoadOrStore(key, namedFields)
 	return fieldsIface.(*namedStructFields), nil
 }
 
@@ -756,84 +1084,46 @@ func joinFieldNames(fldDescs []pgconn.FieldDescription) string {
 	return b.String()
 }
 
+// computeNamedStructFields maps the fields of the struct type t to fldDescs with pgtype.StructFields. The returned
+// fields are in the order of fldDescs. A column without a matching struct field has a nil path. The name of the first
+// struct field without a matching column is returned as missingField.
 func computeNamedStructFields(
 	fldDescs []pgconn.FieldDescription,
 	t reflect.Type,
-	fields []structRowField,
-	fieldStack *[]int,
-) ([]structRowField, string) {
-	var missingField string
-	tail := len(*fieldStack)
-	*fieldStack = append(*fieldStack, 0)
-	for i := 0; i < t.NumField(); i++ {
-		sf := t.Field(i)
-		(*fieldStack)[tail] = i
-		if sf.PkgPath != "" && !sf.Anonymous {
-			// Field is unexported, skip it.
-			continue
-		}
-		// Handle anonymous struct embedding, but do not try to handle embedded pointers.
-		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
-			var missingSubField string
-			fields, missingSubField = computeNamedStructFields(
-				fldDescs,
-				sf.Type,
-				fields,
-				fieldStack,
-			)
+	strategy pgtype.NamingStrategy,
+) (fields []structRowField, missingField string) {
+	fields = make([]structRowField, len(fldDescs))
+	for _, sf := range pgtype.StructFields(t) {
+		fpos := fieldPosByName(fldDescs, &sf, strategy)
+		if fpos == -1 {
 			if missingField == "" {
-				missingField = missingSubField
-			}
-		} else {
-			dbTag, dbTagPresent := sf.Tag.Lookup(structTagKey)
-			if dbTagPresent {
-				dbTag, _, _ = strings.Cut(dbTag, ",")
-			}
-			if dbTag == "-" {
-				// Field is ignored, skip it.
-				continue
-			}
-			colName := dbTag
-			if !dbTagPresent {
-				colName = sf.Name
-			}
-			fpos := fieldPosByName(fldDescs, colName, !dbTagPresent)
-			if fpos == -1 {
-				if missingField == "" {
-					missingField = colName
-				}
-				continue
-			}
-			fields[fpos] = structRowField{
-				path: append([]int(nil), *fieldStack...),
+				missingField = sf.ColumnName()
 			}
+			continue
 		}
+		fields[fpos] = structRowField{path: sf.Index}
 	}
-	*fieldStack = (*fieldStack)[:tail]
 
 	return fields, missingField
 }
 
-const structTagKey = "db"
-
-func fieldPosByName(fldDescs []pgconn.FieldDescription, field string, normalize bool) (i int) {
-	i = -1
-
-	if normalize {
-		field = strings.ReplaceAll(field, "_", "")
-	}
+func fieldPosByName(fldDescs []pgconn.FieldDescription, field *pgtype.StructField, strategy pgtype.NamingStrategy) int {
 	for i, desc := range fldDescs {
-		if normalize {
-			if strings.EqualFold(strings.ReplaceAll(desc.Name, "_", ""), field) {
-				return i
-			}
-		} else {
-			if desc.Name == field {
-				return i
-			}
+		if field.MatchName(desc.Name, strategy) {
+			return i
 		}
 	}
-	return
+	return -1
+}
+
+// rowNamingStrategy returns the NamingStrategy of the type map of the connection of row if it has one.
+func rowNamingStrategy(row CollectableRow) pgtype.NamingStrategy {
+	if r, ok := row.(interface{ Conn() *Conn }); ok {
+		if conn := r.Conn(); conn != nil && conn.typeMap != nil {
+			return conn.typeMap.NamingStrategy
+		}
+	}
+	return nil
 }
 
 // structRowField describes a field of a struct.
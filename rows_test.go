@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxtest"
 )
 
@@ -109,6 +111,104 @@ func TestForEachRowScanError(t *testing.T) {
 	})
 }
 
+func TestScanErrorContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.ScanErrorContext = true
+
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	rows, _ := conn.Query(ctx, `select n, case when n = 2 then 'x' else n::text end as label
+		from generate_series(1, $1::int) n`, 3)
+	var n, label int32
+	_, err := pgx.ForEachRow(rows, []any{&n, &label}, func() error { return nil })
+	require.EqualError(t, err, `can't scan into dest[1] (column "label", OID 25, row 2, sql "select n, case when n = 2 then 'x' else n::text end as label fro...", args [$1=3]): cannot scan text (OID 25) in text format into *int32`)
+
+	var scanErr pgx.ScanArgError
+	require.ErrorAs(t, err, &scanErr)
+	assert.Equal(t, 1, scanErr.ColumnIndex)
+	assert.Equal(t, "label", scanErr.ColumnName)
+	assert.EqualValues(t, pgtype.TextOID, scanErr.DataTypeOID)
+	assert.Equal(t, 2, scanErr.Row)
+
+	config.RedactScanErrorArgs = true
+	redactedConn := mustConnect(t, config)
+	defer closeConn(t, redactedConn)
+
+	var i int32
+	err = redactedConn.QueryRow(ctx, "select $1::text", "secret").Scan(&i)
+	require.EqualError(t, err, `can't scan into dest[0] (column "text", OID 25, row 1, sql "select $1::text", args [$1=string]): cannot scan text (OID 25) in text format into *int32`)
+}
+
+// xorScanPlan decodes values that were encoded by XORing each byte with key and then scans them with next.
+type xorScanPlan struct {
+	key  byte
+	next pgtype.ScanPlan
+}
+
+func (p xorScanPlan) Scan(src []byte, target any) error {
+	if src == nil {
+		return p.next.Scan(src, target)
+	}
+
+	decoded := make([]byte, len(src))
+	for i := range src {
+		decoded[i] = src[i] ^ p.key
+	}
+	return p.next.Scan(decoded, target)
+}
+
+func TestColumnScanPlan(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	var secretTableOID uint32
+	config.ColumnScanPlan = func(m *pgtype.Map, fd *pgconn.FieldDescription, target any, plan pgtype.ScanPlan) pgtype.ScanPlan {
+		if fd.TableOID == secretTableOID && fd.TableAttributeNumber == 2 {
+			return xorScanPlan{key: 0x2a, next: plan}
+		}
+		return plan
+	}
+
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	_, err := conn.Exec(ctx, `create temporary table secrets (id int4, secret bytea, plain bytea)`)
+	require.NoError(t, err)
+	err = conn.QueryRow(ctx, `select 'secrets'::regclass::oid`).Scan(&secretTableOID)
+	require.NoError(t, err)
+
+	encoded := []byte("hello")
+	for i := range encoded {
+		encoded[i] ^= 0x2a
+	}
+	_, err = conn.Exec(ctx, `insert into secrets values (1, $1, $2)`, encoded, []byte("plain"))
+	require.NoError(t, err)
+
+	for _, mode := range []pgx.QueryExecMode{pgx.QueryExecModeCacheStatement, pgx.QueryExecModeSimpleProtocol} {
+		var secret, plain, renamed []byte
+		err = conn.QueryRow(ctx, `select secret, plain, secret as copy from secrets where id = 1`, mode).Scan(&secret, &plain, &renamed)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("hello"), secret, mode.String())
+		assert.Equal(t, []byte("plain"), plain, mode.String())
+		// The column is still from the table when it is renamed.
+		assert.Equal(t, []byte("hello"), renamed, mode.String())
+	}
+
+	var s []byte
+	err = conn.QueryRow(ctx, `select secret || '' from secrets where id = 1`).Scan(&s)
+	require.NoError(t, err)
+	assert.Equal(t, encoded, s)
+}
+
 func TestForEachRowAbort(t *testing.T) {
 	t.Parallel()
 
@@ -190,6 +290,81 @@ func TestCollectRowsEmpty(t *testing.T) {
 	})
 }
 
+func TestCollectRowsMaxResultBytes(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.MaxResultBytes = 100
+
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	rows, _ := conn.Query(ctx, `select n from generate_series(1, 1000) n`)
+	numbers, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+	var tooLargeErr *pgconn.ResultTooLargeError
+	require.ErrorAs(t, err, &tooLargeErr)
+	require.Nil(t, numbers)
+
+	ensureConnValid(t, conn)
+}
+
+func TestCollectRowsWithCapacity(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		rows, _ := conn.Query(ctx, `select n from generate_series(0, 99) n limit 100`)
+		numbers, err := pgx.CollectRowsWithCapacity(rows, 100, pgx.RowTo[int32])
+		require.NoError(t, err)
+
+		assert.Len(t, numbers, 100)
+		assert.Equal(t, 100, cap(numbers))
+		for i := range numbers {
+			assert.Equal(t, int32(i), numbers[i])
+		}
+
+		rows, _ = conn.Query(ctx, `select 1`)
+		numbers, err = pgx.CollectRowsWithCapacity(rows, -1, pgx.RowTo[int32])
+		require.EqualError(t, err, "capacity must not be negative")
+		assert.Nil(t, numbers)
+	})
+}
+
+func TestCollectRowsChunked(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		rows, _ := conn.Query(ctx, `select n from generate_series(0, 24) n`)
+
+		var chunkLens []int
+		var numbers []int32
+		err := pgx.CollectRowsChunked(rows, 10, pgx.RowTo[int32], func(chunk []int32) error {
+			chunkLens = append(chunkLens, len(chunk))
+			numbers = append(numbers, chunk...)
+			return nil
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{10, 10, 5}, chunkLens)
+		assert.Len(t, numbers, 25)
+		for i := range numbers {
+			assert.Equal(t, int32(i), numbers[i])
+		}
+	})
+}
+
+func TestCollectRowsChunkedStopsOnChunkFnError(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		rows, _ := conn.Query(ctx, `select n from generate_series(0, 24) n`)
+
+		chunkCount := 0
+		err := pgx.CollectRowsChunked(rows, 10, pgx.RowTo[int32], func(chunk []int32) error {
+			chunkCount++
+			return errors.New("stop")
+		})
+		require.EqualError(t, err, "stop")
+		assert.Equal(t, 1, chunkCount)
+	})
+}
+
 // This example uses CollectRows with a manually written collector function. In most cases RowTo, RowToAddrOf,
 // RowToStructByPos, RowToAddrOfStructByPos, or another generic function would be used.
 func ExampleCollectRows() {
@@ -634,6 +809,96 @@ func TestRowToAddrOfStructPos(t *testing.T) {
 	})
 }
 
+func TestStructMapping(t *testing.T) {
+	type person struct {
+		Name string
+		Age  int32
+	}
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var mapping *pgx.StructMapping[person]
+		for i := 0; i < 2; i++ {
+			rows, _ := conn.Query(ctx, `select n as age, 'Joe' as name from generate_series(0, 9) n`)
+			if mapping == nil {
+				var err error
+				mapping, err = pgx.NewStructMapping[person](rows.FieldDescriptions())
+				require.NoError(t, err)
+			}
+			slice, err := pgx.CollectRows(rows, mapping.RowToAddrOfStruct)
+			require.NoError(t, err)
+
+			assert.Len(t, slice, 10)
+			for i := range slice {
+				assert.Equal(t, "Joe", slice[i].Name)
+				assert.EqualValues(t, i, slice[i].Age)
+			}
+		}
+
+		rows, _ := conn.Query(ctx, `select 'Joe' as name`)
+		_, err := pgx.NewStructMapping[person](rows.FieldDescriptions())
+		rows.Close()
+		assert.ErrorContains(t, err, "cannot find field Age in returned row")
+
+		rows, _ = conn.Query(ctx, `select 'Joe' as name`)
+		laxMapping, err := pgx.NewStructMappingLax[person](rows.FieldDescriptions())
+		require.NoError(t, err)
+		p, err := pgx.CollectOneRow(rows, laxMapping.RowToStruct)
+		require.NoError(t, err)
+		assert.Equal(t, person{Name: "Joe"}, p)
+
+		rows, _ = conn.Query(ctx, `select 'Joe' as name, 1 as age, 2 as extra`)
+		_, err = pgx.CollectRows(rows, mapping.RowToStruct)
+		assert.ErrorContains(t, err, "struct mapping has 2 fields but row has 3 fields")
+	})
+}
+
+func TestScanByName(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		rows, _ := conn.Query(ctx, `select n as a, 'n' || n as b, n * 2 as c from generate_series(0, 9) n`)
+		var count int
+		for rows.Next() {
+			var a, c int32
+			var b string
+			err := pgx.ScanByName(rows, "c", &c, "b", &b, "a", &a)
+			require.NoError(t, err)
+			assert.EqualValues(t, count, a)
+			assert.Equal(t, fmt.Sprintf("n%d", count), b)
+			assert.EqualValues(t, count*2, c)
+			count++
+		}
+		require.NoError(t, rows.Err())
+		assert.Equal(t, 10, count)
+
+		// Columns that are not named are skipped.
+		rows, _ = conn.Query(ctx, `select 1 as a, 'x' as b`)
+		b, err := pgx.CollectExactlyOneRow(rows, func(row pgx.CollectableRow) (string, error) {
+			var b string
+			err := pgx.ScanByName(row, "b", &b)
+			return b, err
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "x", b)
+
+		for _, tt := range []struct {
+			sql           string
+			namesAndDests []any
+			errContains   string
+		}{
+			{`select 1 as a`, []any{"b", new(int32)}, `cannot find column "b"`},
+			{`select 1 as a, 2 as a`, []any{"a", new(int32)}, `column name "a" is ambiguous`},
+			{`select 1 as a`, []any{"a", new(int32), "a", new(int32)}, `column "a" is named more than once`},
+			{`select 1 as a`, []any{"a"}, "pairs of column names and destinations"},
+			{`select 1 as a`, []any{1, new(int32)}, "must be a column name"},
+		} {
+			rows, _ := conn.Query(ctx, tt.sql)
+			_, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (struct{}, error) {
+				return struct{}{}, pgx.ScanByName(row, tt.namesAndDests...)
+			})
+			assert.ErrorContains(t, err, tt.errContains)
+		}
+	})
+}
+
 func TestRowToStructByName(t *testing.T) {
 	type person struct {
 		Last      string
@@ -702,6 +967,34 @@ func TestRowToStructByNameDbTags(t *testing.T) {
 	})
 }
 
+func TestRowToStructByNameNamingStrategy(t *testing.T) {
+	type person struct {
+		UserID    int32
+		FirstName string
+		Last      string `db:"surname"`
+	}
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		conn.TypeMap().NamingStrategy = pgtype.CamelCaseNamingStrategy
+
+		rows, _ := conn.Query(ctx, `select 'Smith' as surname, 'John' as "firstName", 42 as "userID"`)
+		p, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[person])
+		require.NoError(t, err)
+		assert.Equal(t, person{UserID: 42, FirstName: "John", Last: "Smith"}, p)
+
+		// The default case-insensitive match does not apply.
+		rows, _ = conn.Query(ctx, `select 'Smith' as surname, 'John' as first_name, 42 as user_id`)
+		_, err = pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[person])
+		assert.ErrorContains(t, err, "struct doesn't have corresponding row field")
+
+		conn.TypeMap().NamingStrategy = pgtype.NamingStrategyFunc(strings.ToUpper)
+		rows, _ = conn.Query(ctx, `select 'Smith' as surname, 'John' as "FIRSTNAME", 42 as "USERID"`)
+		p, err = pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[person])
+		require.NoError(t, err)
+		assert.Equal(t, person{UserID: 42, FirstName: "John", Last: "Smith"}, p)
+	})
+}
+
 func TestRowToStructByNameEmbeddedStruct(t *testing.T) {
 	type Name struct {
 		Last  string `db:"last_name"`
@@ -0,0 +1,72 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+)
+
+// SQLMiddleware is called with the SQL of every query sent by Exec, Query, QueryRow, and SendBatch. It returns the
+// SQL to send and the context to use for the remainder of the operation. It can be used to annotate or rewrite SQL and
+// to extract routing metadata such as a shard key and attach it to the context with WithRoutingMetadata.
+//
+// When a pgxpool.Pool is used the middleware runs before a connection is acquired so the routing metadata is visible
+// to the pool's BeforeConnect and BeforeAcquire callbacks as well as to tracers.
+//
+// SQLMiddleware must be safe for concurrent use. The Batch passed to SendBatch is not modified. The rewritten SQL of
+// its queries is sent from a copy.
+type SQLMiddleware func(ctx context.Context, sql string) (context.Context, string, error)
+
+type routingMetadataCtxKey struct{}
+
+type sqlMiddlewareAppliedCtxKey struct{}
+
+// WithRoutingMetadata returns a copy of ctx with md attached. It is intended to be called by a SQLMiddleware.
+func WithRoutingMetadata(ctx context.Context, md any) context.Context {
+	return context.WithValue(ctx, routingMetadataCtxKey{}, md)
+}
+
+// RoutingMetadataFromContext returns the routing metadata attached to ctx by WithRoutingMetadata.
+func RoutingMetadataFromContext(ctx context.Context) (any, bool) {
+	md := ctx.Value(routingMetadataCtxKey{})
+	return md, md != nil
+}
+
+// ApplySQLMiddleware applies cc.SQLMiddleware to sql. The returned context is marked so that the middleware is not
+// applied again when it is passed to a Conn query method. If cc.SQLMiddleware is nil or ctx has already been marked
+// then ctx and sql are returned unchanged.
+//
+// This is used by pgxpool to run the middleware before a connection is acquired. It is rarely needed otherwise.
+func (cc *ConnConfig) ApplySQLMiddleware(ctx context.Context, sql string) (context.Context, string, error) {
+	if cc.SQLMiddleware == nil || ctx.Value(sqlMiddlewareAppliedCtxKey{}) != nil {
+		return ctx, sql, nil
+	}
+
+	ctx, sql, err := cc.SQLMiddleware(ctx, sql)
+	if err != nil {
+		return ctx, sql, fmt.Errorf("sql middleware failed: %w", err)
+	}
+
+	return context.WithValue(ctx, sqlMiddlewareAppliedCtxKey{}, true), sql, nil
+}
+
+// ApplySQLMiddlewareToBatch applies cc.SQLMiddleware to each query in b. It behaves the same as ApplySQLMiddleware
+// except that it returns a copy of b with the SQL of each queued query replaced. b is not modified so it can be sent
+// again. If the middleware is not applied b itself is returned.
+func (cc *ConnConfig) ApplySQLMiddlewareToBatch(ctx context.Context, b *Batch) (context.Context, *Batch, error) {
+	if cc.SQLMiddleware == nil || ctx.Value(sqlMiddlewareAppliedCtxKey{}) != nil {
+		return ctx, b, nil
+	}
+
+	rewritten := &Batch{QueuedQueries: make([]*QueuedQuery, len(b.QueuedQueries))}
+	for i, qq := range b.QueuedQueries {
+		qqCopy := *qq
+		var err error
+		ctx, qqCopy.SQL, err = cc.SQLMiddleware(ctx, qq.SQL)
+		if err != nil {
+			return ctx, b, fmt.Errorf("sql middleware failed: %w", err)
+		}
+		rewritten.QueuedQueries[i] = &qqCopy
+	}
+
+	return context.WithValue(ctx, sqlMiddlewareAppliedCtxKey{}, true), rewritten, nil
+}
@@ -23,6 +23,13 @@
 //
 //	db := stdlib.OpenDBFromPool(pool)
 //
+// A *pgxpool.Pool can also be registered with the driver for libraries that can only be configured with a connection
+// string.
+//
+//	connStr := stdlib.RegisterPool(pool)
+//	db, _ := sql.Open("pgx", connStr)
+//	db.SetMaxIdleConns(0)
+//
 // Or a pgx.ConnConfig can be used to set configuration not accessible via connection string. In this case the
 // pgx.ConnConfig must first be registered with the driver. This registration returns a connection string which is used
 // with sql.Open.
@@ -95,6 +102,7 @@ var pgxDriver *Driver
 func init() {
 	pgxDriver = &Driver{
 		configs: make(map[string]*pgx.ConnConfig),
+		pools:   make(map[string]*pgxpool.Pool),
 	}
 
 	// if pgx driver was already registered by different pgx major version then we
@@ -142,6 +150,9 @@ func OptionAfterConnect(ac func(context.Context, *pgx.Conn) error) OptionOpenDB
 // OptionResetSession provides a callback that can be used to add custom logic prior to executing a query on the
 // connection if the connection has been used before.
 // If ResetSessionFunc returns ErrBadConn error the connection will be discarded.
+//
+// pgx.Conn.Reset can be used to restore each connection to a pristine session state. However, it deallocates all
+// prepared statements so it must not be used with *sql.Stmt values that outlive a single use of the connection.
 func OptionResetSession(rs func(context.Context, *pgx.Conn) error) OptionOpenDB {
 	return func(dc *connector) {
 		dc.ResetSession = rs
@@ -299,7 +310,9 @@ func GetDefaultDriver() driver.Driver {
 type Driver struct {
 	configMutex sync.Mutex
 	configs     map[string]*pgx.ConnConfig
+	pools       map[string]*pgxpool.Pool
 	sequence    int
+	poolSeq     int
 }
 
 func (d *Driver) Open(name string) (driver.Conn, error) {
@@ -332,6 +345,21 @@ func (d *Driver) unregisterConnConfig(connStr string) {
 	d.configMutex.Unlock()
 }
 
+func (d *Driver) registerPool(pool *pgxpool.Pool) string {
+	d.configMutex.Lock()
+	connStr := fmt.Sprintf("registeredPool%d", d.poolSeq)
+	d.poolSeq++
+	d.pools[connStr] = pool
+	d.configMutex.Unlock()
+	return connStr
+}
+
+func (d *Driver) unregisterPool(connStr string) {
+	d.configMutex.Lock()
+	delete(d.pools, connStr)
+	d.configMutex.Unlock()
+}
+
 type driverConnector struct {
 	driver *Driver
 	name   string
@@ -342,8 +370,13 @@ func (dc *driverConnector) Connect(ctx context.Context) (driver.Conn, error) {
 
 	dc.driver.configMutex.Lock()
 	connConfig = dc.driver.configs[dc.name]
+	pool := dc.driver.pools[dc.name]
 	dc.driver.configMutex.Unlock()
 
+	if pool != nil {
+		return GetPoolConnector(pool).Connect(ctx)
+	}
+
 	if connConfig == nil {
 		var err error
 		connConfig, err = pgx.ParseConfig(dc.name)
@@ -383,6 +416,19 @@ func UnregisterConnConfig(connStr string) {
 	pgxDriver.unregisterConnConfig(connStr)
 }
 
+// RegisterPool registers a *pgxpool.Pool and returns the connection string to use with Open. Connections of a
+// *sql.DB opened with the connection string are acquired from pool. This allows libraries that can only be configured
+// with a driver name and connection string, such as some migration tools, to share the connections and limits of pool.
+// As with OpenDBFromPool, the maximum idle connections of the *sql.DB should be set to zero.
+func RegisterPool(pool *pgxpool.Pool) string {
+	return pgxDriver.registerPool(pool)
+}
+
+// UnregisterPool removes the *pgxpool.Pool registration for connStr.
+func UnregisterPool(connStr string) {
+	pgxDriver.unregisterPool(connStr)
+}
+
 type Conn struct {
 	conn                 *pgx.Conn
 	close                func(context.Context) error
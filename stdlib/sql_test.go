@@ -178,6 +178,33 @@ func TestSQLOpenFromPool(t *testing.T) {
 	db.Close()
 }
 
+func TestSQLOpenRegisteredPool(t *testing.T) {
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 1
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	connStr := stdlib.RegisterPool(pool)
+	defer stdlib.UnregisterPool(connStr)
+
+	db, err := sql.Open("pgx", connStr)
+	require.NoError(t, err)
+	defer closeDB(t, db)
+	db.SetMaxIdleConns(0)
+
+	ensureDBValid(t, db)
+
+	// The *sql.DB connection is acquired from pool so it counts against the pool limit.
+	conn, err := db.Conn(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 1, pool.Stat().AcquiredConns())
+	require.NoError(t, conn.Close())
+	require.EqualValues(t, 0, pool.Stat().AcquiredConns())
+}
+
 func TestNormalLifeCycle(t *testing.T) {
 	db := openDB(t)
 	defer closeDB(t, db)
@@ -347,6 +347,14 @@ func (tl *TraceLog) TracePrepareEnd(ctx context.Context, conn *pgx.Conn, data pg
 	}
 }
 
+func (tl *TraceLog) TraceTxRetry(ctx context.Context, conn *pgx.Conn, data pgx.TraceTxRetryData) {
+	tl.ensureConfig()
+
+	if tl.shouldLog(LogLevelWarn) {
+		tl.log(ctx, conn, LogLevelWarn, "TxRetry", map[string]any{"attempt": data.Attempt, "err": data.Err, "backoff": data.Backoff})
+	}
+}
+
 func (tl *TraceLog) shouldLog(lvl LogLevel) bool {
 	return tl.LogLevel >= lvl
 }
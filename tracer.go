@@ -2,6 +2,7 @@ package pgx
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgconn"
 )
@@ -105,3 +106,21 @@ type TraceConnectEndData struct {
 	Conn *Conn
 	Err  error
 }
+
+// TxRetryTracer traces the retries of transactions by BeginTxFunc with TxOptions.RetrySerializable.
+type TxRetryTracer interface {
+	// TraceTxRetry is called after an attempt of a transaction failed with an error for which IsRetryableTxError returns
+	// true and before the transaction is retried.
+	TraceTxRetry(ctx context.Context, conn *Conn, data TraceTxRetryData)
+}
+
+type TraceTxRetryData struct {
+	// Attempt is the number of the attempt that failed. The first attempt is 1.
+	Attempt int
+
+	// Err is the error that caused the attempt to fail.
+	Err error
+
+	// Backoff is the delay before the next attempt.
+	Backoff time.Duration
+}
@@ -22,6 +22,7 @@ type testTracer struct {
 	tracePrepareEnd    func(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareEndData)
 	traceConnectStart  func(ctx context.Context, data pgx.TraceConnectStartData) context.Context
 	traceConnectEnd    func(ctx context.Context, data pgx.TraceConnectEndData)
+	traceTxRetry       func(ctx context.Context, conn *pgx.Conn, data pgx.TraceTxRetryData)
 }
 
 type ctxKey string
@@ -97,6 +98,12 @@ func (tt *testTracer) TraceConnectEnd(ctx context.Context, data pgx.TraceConnect
 	}
 }
 
+func (tt *testTracer) TraceTxRetry(ctx context.Context, conn *pgx.Conn, data pgx.TraceTxRetryData) {
+	if tt.traceTxRetry != nil {
+		tt.traceTxRetry(ctx, conn, data)
+	}
+}
+
 func TestTraceExec(t *testing.T) {
 	t.Parallel()
 
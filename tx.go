@@ -5,6 +5,7 @@ import (
 	"errors"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgconn"
 )
@@ -49,6 +50,40 @@ type TxOptions struct {
 	BeginQuery string
 	// CommitQuery is the SQL query that will be executed to commit the transaction.
 	CommitQuery string
+
+	// RetrySerializable configures BeginTxFunc to call its function again in a new transaction when the transaction
+	// fails with an error for which IsRetryableTxError returns true. It has no effect on BeginTx.
+	RetrySerializable TxRetryOptions
+}
+
+// TxRetryOptions configures the retries of a transaction by BeginTxFunc. See TxOptions.RetrySerializable.
+type TxRetryOptions struct {
+	// MaxAttempts is the maximum number of times the function passed to BeginTxFunc is called. If less than 2, the
+	// transaction is not retried. The function must be safe to call more than once when retries are enabled.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry. The delay doubles for each subsequent retry up to MaxBackoff.
+	Backoff time.Duration
+
+	// MaxBackoff is the maximum delay before a retry. If 0, the delay is not limited.
+	MaxBackoff time.Duration
+}
+
+// backoff returns the delay before the retry after attempt failed.
+func (o TxRetryOptions) backoff(attempt int) time.Duration {
+	backoff := o.Backoff
+	for i := 1; i < attempt && backoff > 0 && backoff < time.Duration(1<<62); i++ {
+		if o.MaxBackoff > 0 && backoff >= o.MaxBackoff {
+			break
+		}
+		backoff *= 2
+	}
+
+	if o.MaxBackoff > 0 && backoff > o.MaxBackoff {
+		backoff = o.MaxBackoff
+	}
+
+	return backoff
 }
 
 var emptyTxOptions TxOptions
@@ -407,6 +442,10 @@ func BeginFunc(
 // BeginTxFunc calls BeginTx on db and then calls fn. If fn does not return an error then it calls Commit on db. If fn
 // returns an error it calls Rollback on db. The context will be used when executing the transaction control statements
 // (BEGIN, ROLLBACK, and COMMIT) but does not otherwise affect the execution of fn.
+//
+// If txOptions.RetrySerializable is set and the transaction fails with an error for which IsRetryableTxError returns
+// true, fn is called again in a new transaction until it succeeds, fails with another error, or
+// txOptions.RetrySerializable.MaxAttempts is reached. Each retry is reported to the TxRetryTracer of the connection if its tracer implements it.
 func BeginTxFunc(
 	ctx context.Context,
 	db interface {
@@ -415,13 +454,51 @@ func BeginTxFunc(
 	txOptions TxOptions,
 	fn func(Tx) error,
 ) (err error) {
-	var tx Tx
-	tx, err = db.BeginTx(ctx, txOptions)
-	if err != nil {
-		return err
+	retry := txOptions.RetrySerializable
+	for attempt := 1; ; attempt++ {
+		var tx Tx
+		tx, err = db.BeginTx(ctx, txOptions)
+		if err != nil {
+			return err
+		}
+		conn := tx.Conn()
+
+		err = beginFuncExec(ctx, tx, fn)
+		if err == nil || attempt >= retry.MaxAttempts || !IsRetryableTxError(err) {
+			return err
+		}
+
+		backoff := retry.backoff(attempt)
+		if conn != nil && conn.txRetryTracer != nil {
+			conn.txRetryTracer.TraceTxRetry(ctx, conn, TraceTxRetryData{Attempt: attempt, Err: err, Backoff: backoff})
+		}
+
+		if backoff > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		} else if ctx.Err() != nil {
+			return err
+		}
 	}
+}
 
-	return beginFuncExec(ctx, tx, fn)
+// IsRetryableTxError returns true if err is a serialization failure (SQLSTATE 40001), a deadlock (SQLSTATE 40P01), or
+// ErrPlanInvalidated. A transaction that failed with such an error may succeed if it is run again.
+func IsRetryableTxError(err error) bool {
+	if errors.Is(err, ErrPlanInvalidated) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "40001" || pgErr.Code == "40P01"
+	}
+	return false
 }
 
 func beginFuncExec(ctx context.Context, tx Tx, fn func(Tx) error) (err error) {
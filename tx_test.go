@@ -3,6 +3,7 @@ package pgx_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -354,6 +355,68 @@ func TestBeginFuncRollbackOnError(t *testing.T) {
 	require.EqualValues(t, 0, n)
 }
 
+func TestBeginTxFuncRetrySerializable(t *testing.T) {
+	t.Parallel()
+
+	var retries []pgx.TraceTxRetryData
+	tracer := &testTracer{
+		traceTxRetry: func(ctx context.Context, conn *pgx.Conn, data pgx.TraceTxRetryData) {
+			retries = append(retries, data)
+		},
+	}
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.Tracer = tracer
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	txOptions := pgx.TxOptions{
+		IsoLevel: pgx.Serializable,
+		RetrySerializable: pgx.TxRetryOptions{
+			MaxAttempts: 3,
+			Backoff:     time.Millisecond,
+		},
+	}
+
+	attempts := 0
+	err := pgx.BeginTxFunc(context.Background(), conn, txOptions, func(tx pgx.Tx) error {
+		attempts++
+		if attempts < 3 {
+			return &pgconn.PgError{Code: "40001"}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+	require.Len(t, retries, 2)
+	require.Equal(t, 1, retries[0].Attempt)
+	require.Equal(t, time.Millisecond, retries[0].Backoff)
+	require.Equal(t, 2, retries[1].Attempt)
+	require.Equal(t, 2*time.Millisecond, retries[1].Backoff)
+
+	attempts = 0
+	retries = nil
+	err = pgx.BeginTxFunc(context.Background(), conn, txOptions, func(tx pgx.Tx) error {
+		attempts++
+		return &pgconn.PgError{Code: "40P01"}
+	})
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "40P01", pgErr.Code)
+	require.Equal(t, 3, attempts)
+	require.Len(t, retries, 2)
+
+	attempts = 0
+	err = pgx.BeginTxFunc(context.Background(), conn, txOptions, func(tx pgx.Tx) error {
+		attempts++
+		return errors.New("some error")
+	})
+	require.EqualError(t, err, "some error")
+	require.Equal(t, 1, attempts)
+
+	ensureConnValid(t, conn)
+}
+
 func TestBeginReadOnly(t *testing.T) {
 	t.Parallel()
 
@@ -642,3 +705,57 @@ func TestTxSendBatchClosed(t *testing.T) {
 	_, err = br.Query()
 	require.Error(t, err)
 }
+
+func TestIsRetryableTxErrorWithoutDatabase(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, pgx.IsRetryableTxError(&pgconn.PgError{Code: "40001"}))
+	require.True(t, pgx.IsRetryableTxError(fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: "40P01"})))
+	require.True(t, pgx.IsRetryableTxError(fmt.Errorf("%w: %w", pgx.ErrPlanInvalidated, &pgconn.PgError{Code: "0A000"})))
+	require.False(t, pgx.IsRetryableTxError(&pgconn.PgError{Code: "0A000"}))
+	require.False(t, pgx.IsRetryableTxError(errors.New("foo")))
+}
+
+func TestTxPlanInvalidated(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pgxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, []pgx.QueryExecMode{pgx.QueryExecModeCacheStatement}, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support cached plan invalidation")
+
+		mustExec(t, conn, "create temporary table plan_invalidated (a int4)")
+		defer mustExec(t, conn, "drop table plan_invalidated")
+		mustExec(t, conn, "insert into plan_invalidated values (1)")
+
+		sql := "select * from plan_invalidated where a = $1"
+		_, err := conn.Exec(ctx, sql, 1)
+		require.NoError(t, err)
+
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		_, err = tx.Exec(ctx, "alter table plan_invalidated add column b int4")
+		require.NoError(t, err)
+
+		_, err = tx.Exec(ctx, sql, 1)
+		require.ErrorIs(t, err, pgx.ErrPlanInvalidated)
+		require.True(t, pgx.IsRetryableTxError(err))
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "0A000", pgErr.Code)
+		require.Equal(t, "RevalidateCachedQuery", pgErr.Routine)
+		require.NoError(t, tx.Rollback(ctx))
+
+		// Restarting the transaction succeeds as the cached statement was invalidated.
+		err = pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			_, err := tx.Exec(ctx, "alter table plan_invalidated add column b int4")
+			if err != nil {
+				return err
+			}
+			var a, b *int32
+			return tx.QueryRow(ctx, sql, 1).Scan(&a, &b)
+		})
+		require.NoError(t, err)
+	})
+}
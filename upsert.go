@@ -0,0 +1,238 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// UpsertOutcome is the outcome of upserting a row with UpsertRows.
+type UpsertOutcome int
+
+const (
+	// UpsertInserted means the row was inserted.
+	UpsertInserted UpsertOutcome = iota
+
+	// UpsertUpdated means the row had the same key as an existing row and updated it.
+	UpsertUpdated
+)
+
+func (o UpsertOutcome) String() string {
+	switch o {
+	case UpsertInserted:
+		return "inserted"
+	case UpsertUpdated:
+		return "updated"
+	default:
+		return "unknown"
+	}
+}
+
+// maxQueryParams is the maximum number of parameters of a query. The protocol sends the number of parameters as an
+// int16 that the server reads as unsigned.
+const maxQueryParams = 65535
+
+// UpsertRows inserts rows into tableName and updates the existing rows that have the same keyColumns instead. It returns
+// the outcome of each row in the order of rows.
+//
+// T must be a struct. Its fields are matched to the columns of tableName as with RowToStructByName. Every field must
+// have a column, but columns without a field are not set. keyColumns must be the columns of a unique index or
+// constraint and must have fields. The other columns with fields are updated when a row already exists.
+//
+// The rows are upserted with INSERT ... ON CONFLICT DO UPDATE statements sent in a single batch. As many rows as the
+// parameter limit allows are upserted by each statement. A batch is executed in an implicit transaction unless it is
+// sent in an explicit transaction, so either all rows are upserted or none. As with any INSERT ... ON CONFLICT DO
+// UPDATE, rows must not have the same key as another row in the same statement.
+//
+// UpsertRows first queries tableName to get its columns. The outcome of a row is determined with the xmax system
+// column, which is 0 for a newly inserted row.
+func UpsertRows[T any](
+	ctx context.Context,
+	db interface {
+		Query(ctx context.Context, sql string, args ...any) (Rows, error)
+		SendBatch(ctx context.Context, b *Batch) BatchResults
+	},
+	tableName Identifier,
+	keyColumns []string,
+	rows []T,
+) ([]UpsertOutcome, error) {
+	structType := reflect.TypeOf((*T)(nil)).Elem()
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("upsert: %v is not a struct", structType)
+	}
+	if len(keyColumns) == 0 {
+		return nil, errors.New("upsert: keyColumns are required")
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	columns, fieldPaths, err := upsertColumns(ctx, db, tableName, structType)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keyColumns {
+		if !slices.Contains(columns, k) {
+			return nil, fmt.Errorf("upsert: key column %q is not a column of %v with a field", k, structType)
+		}
+	}
+
+	rowsPerStatement := maxQueryParams / len(columns)
+
+	batch := &Batch{}
+	for start := 0; start < len(rows); start += rowsPerStatement {
+		end := min(start+rowsPerStatement, len(rows))
+
+		args := make([]any, 0, (end-start)*len(columns))
+		for i := start; i < end; i++ {
+			v := reflect.ValueOf(&rows[i]).Elem()
+			for _, path := range fieldPaths {
+				args = append(args, v.FieldByIndex(path).Interface())
+			}
+		}
+
+		batch.Queue(upsertSQL(tableName, columns, keyColumns, end-start), args...)
+	}
+
+	br := db.SendBatch(ctx, batch)
+	defer br.Close()
+
+	outcomes := make([]UpsertOutcome, 0, len(rows))
+	for start := 0; start < len(rows); start += rowsPerStatement {
+		end := min(start+rowsPerStatement, len(rows))
+
+		qrows, err := br.Query()
+		if err != nil {
+			return nil, err
+		}
+		var inserted bool
+		_, err = ForEachRow(qrows, []any{&inserted}, func() error {
+			if inserted {
+				outcomes = append(outcomes, UpsertInserted)
+			} else {
+				outcomes = append(outcomes, UpsertUpdated)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(outcomes) != end {
+			return nil, fmt.Errorf("upsert: expected %d rows to be upserted but %d were", end-start, len(outcomes)-start)
+		}
+	}
+
+	err = br.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return outcomes, nil
+}
+
+// upsertColumns returns the columns of tableName that have a field in structType and the paths of the fields.
+func upsertColumns(
+	ctx context.Context,
+	db interface {
+		Query(ctx context.Context, sql string, args ...any) (Rows, error)
+	},
+	tableName Identifier,
+	structType reflect.Type,
+) ([]string, [][]int, error) {
+	rows, err := db.Query(ctx, "select * from "+tableName.Sanitize()+" where false")
+	if err != nil {
+		return nil, nil, err
+	}
+	fldDescs := slices.Clone(rows.FieldDescriptions())
+	strategy := rowNamingStrategy(rows)
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	fields, missingField := computeNamedStructFields(fldDescs, structType, strategy)
+	if missingField != "" {
+		return nil, nil, fmt.Errorf("upsert: %v has no column named %q", tableName, missingField)
+	}
+
+	var columns []string
+	var fieldPaths [][]int
+	for i, f := range fields {
+		if f.path != nil {
+			columns = append(columns, fldDescs[i].Name)
+			fieldPaths = append(fieldPaths, f.path)
+		}
+	}
+	if len(columns) == 0 {
+		return nil, nil, fmt.Errorf("upsert: %v has no columns for the fields of %v", tableName, structType)
+	}
+
+	return columns, fieldPaths, nil
+}
+
+// upsertSQL returns an upsert statement of rowCount rows.
+func upsertSQL(tableName Identifier, columns, keyColumns []string, rowCount int) string {
+	var sb strings.Builder
+	sb.WriteString("insert into ")
+	sb.WriteString(tableName.Sanitize())
+	sb.WriteString(" (")
+	for i, c := range columns {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(Identifier{c}.Sanitize())
+	}
+	sb.WriteString(") values ")
+
+	n := 0
+	for i := 0; i < rowCount; i++ {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteByte('(')
+		for j := range columns {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			n++
+			fmt.Fprintf(&sb, "$%d", n)
+		}
+		sb.WriteByte(')')
+	}
+
+	sb.WriteString(" on conflict (")
+	for i, k := range keyColumns {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(Identifier{k}.Sanitize())
+	}
+	sb.WriteString(") do update set ")
+
+	updateColumns := make([]string, 0, len(columns))
+	for _, c := range columns {
+		if !slices.Contains(keyColumns, c) {
+			updateColumns = append(updateColumns, c)
+		}
+	}
+	if len(updateColumns) == 0 {
+		// Updating a key column to its own value makes the existing row part of the result.
+		updateColumns = keyColumns[:1]
+	}
+	for i, c := range updateColumns {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		ident := Identifier{c}.Sanitize()
+		sb.WriteString(ident)
+		sb.WriteString(" = excluded.")
+		sb.WriteString(ident)
+	}
+
+	sb.WriteString(" returning xmax = 0")
+
+	return sb.String()
+}
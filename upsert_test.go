@@ -0,0 +1,126 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertRows(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table upsert_items (
+	id int primary key,
+	name text not null,
+	quantity int not null,
+	created_at timestamptz not null default now()
+)`)
+		mustExec(t, conn, `insert into upsert_items (id, name, quantity) values (1, 'a', 1), (2, 'b', 2)`)
+
+		type item struct {
+			ID       int32
+			Name     string
+			Quantity int32
+		}
+
+		outcomes, err := pgx.UpsertRows(ctx, conn, pgx.Identifier{"upsert_items"}, []string{"id"}, []item{
+			{ID: 2, Name: "B", Quantity: 20},
+			{ID: 3, Name: "c", Quantity: 3},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []pgx.UpsertOutcome{pgx.UpsertUpdated, pgx.UpsertInserted}, outcomes)
+
+		rows, _ := conn.Query(ctx, `select id, name, quantity from upsert_items order by id`)
+		items, err := pgx.CollectRows(rows, pgx.RowToStructByName[item])
+		require.NoError(t, err)
+		assert.Equal(t, []item{{1, "a", 1}, {2, "B", 20}, {3, "c", 3}}, items)
+	})
+}
+
+func TestUpsertRowsMultipleStatements(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table upsert_items (a int, b int, n int not null, primary key (a, b))`)
+		mustExec(t, conn, `insert into upsert_items select n, -n, 0 from generate_series(0, 9) n`)
+
+		type item struct {
+			A int32
+			B int32
+			N int32
+		}
+
+		// 3 columns allow 21845 rows per statement.
+		items := make([]item, 50000)
+		for i := range items {
+			items[i] = item{A: int32(i), B: int32(-i), N: 1}
+		}
+
+		outcomes, err := pgx.UpsertRows(ctx, conn, pgx.Identifier{"upsert_items"}, []string{"a", "b"}, items)
+		require.NoError(t, err)
+		require.Len(t, outcomes, len(items))
+		for i, o := range outcomes {
+			if i < 10 {
+				require.Equal(t, pgx.UpsertUpdated, o, i)
+			} else {
+				require.Equal(t, pgx.UpsertInserted, o, i)
+			}
+		}
+
+		var count, sum int64
+		err = conn.QueryRow(ctx, `select count(*), sum(n) from upsert_items`).Scan(&count, &sum)
+		require.NoError(t, err)
+		assert.EqualValues(t, len(items), count)
+		assert.EqualValues(t, len(items), sum)
+	})
+}
+
+func TestUpsertRowsOnlyKeyColumns(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table upsert_items (id int primary key)`)
+		mustExec(t, conn, `insert into upsert_items values (1)`)
+
+		type item struct {
+			ID int32
+		}
+
+		outcomes, err := pgx.UpsertRows(ctx, conn, pgx.Identifier{"upsert_items"}, []string{"id"}, []item{{1}, {2}})
+		require.NoError(t, err)
+		assert.Equal(t, []pgx.UpsertOutcome{pgx.UpsertUpdated, pgx.UpsertInserted}, outcomes)
+	})
+}
+
+func TestUpsertRowsErrors(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table upsert_items (id int primary key, name text)`)
+
+		type item struct {
+			ID   int32
+			Name string
+		}
+
+		_, err := pgx.UpsertRows(ctx, conn, pgx.Identifier{"upsert_items"}, nil, []item{{1, "a"}})
+		require.ErrorContains(t, err, "keyColumns are required")
+
+		_, err = pgx.UpsertRows(ctx, conn, pgx.Identifier{"upsert_items"}, []string{"id"}, []int32{1})
+		require.ErrorContains(t, err, "is not a struct")
+
+		type extraField struct {
+			ID    int32
+			Extra string
+		}
+		_, err = pgx.UpsertRows(ctx, conn, pgx.Identifier{"upsert_items"}, []string{"id"}, []extraField{{1, "a"}})
+		require.ErrorContains(t, err, `no column named "Extra"`)
+
+		type keyless struct {
+			Name string
+		}
+		_, err = pgx.UpsertRows(ctx, conn, pgx.Identifier{"upsert_items"}, []string{"id"}, []keyless{{"a"}})
+		require.ErrorContains(t, err, `key column "id"`)
+
+		_, err = pgx.UpsertRows(ctx, conn, pgx.Identifier{"upsert_items"}, []string{"id"}, []item{{1, "a"}, {1, "b"}})
+		require.Error(t, err)
+
+		ensureConnValid(t, conn)
+	})
+}
@@ -0,0 +1,61 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// RegisterVectorTypes registers the vector, halfvec, and sparsevec types of the pgvector extension and their array types
+// with conn's type map. Types that are not provided by the installed version of the extension are skipped. It returns
+// an error if the extension is not installed in the database.
+//
+// As the OIDs of the types differ between databases, RegisterVectorTypes must be called for each connection (e.g. in
+// pgxpool.Config.AfterConnect).
+func RegisterVectorTypes(ctx context.Context, conn *Conn) error {
+	codecs := map[string]pgtype.Codec{
+		"vector":    pgtype.VectorCodec{},
+		"halfvec":   pgtype.HalfVectorCodec{},
+		"sparsevec": pgtype.SparseVectorCodec{},
+	}
+
+	rows, _ := conn.Query(ctx,
+		`select t.oid, t.typname, n.nspname, t.typarray, coalesce(a.typname, '')
+from pg_catalog.pg_extension e
+	join pg_catalog.pg_depend d on d.refclassid = 'pg_catalog.pg_extension'::regclass and d.refobjid = e.oid and d.deptype = 'e'
+	join pg_catalog.pg_type t on d.classid = 'pg_catalog.pg_type'::regclass and d.objid = t.oid
+	join pg_catalog.pg_namespace n on n.oid = t.typnamespace
+	left join pg_catalog.pg_type a on a.oid = t.typarray
+where e.extname = 'vector' and t.typname = any($1)`,
+		[]string{"vector", "halfvec", "sparsevec"},
+	)
+
+	var oid, arrayOID uint32
+	var name, nspName, arrayName string
+	m := conn.TypeMap()
+	registered := 0
+	_, err := ForEachRow(rows, []any{&oid, &name, &nspName, &arrayOID, &arrayName}, func() error {
+		// Register the types by name and by schema qualified name like LoadTypes.
+		codec := codecs[name]
+		vectorType := &pgtype.Type{Name: name, OID: oid, Codec: codec}
+		m.RegisterType(vectorType)
+		m.RegisterType(&pgtype.Type{Name: nspName + "." + name, OID: oid, Codec: codec})
+		if arrayOID != 0 {
+			arrayCodec := &pgtype.ArrayCodec{ElementType: vectorType}
+			m.RegisterType(&pgtype.Type{Name: arrayName, OID: arrayOID, Codec: arrayCodec})
+			m.RegisterType(&pgtype.Type{Name: nspName + "." + arrayName, OID: arrayOID, Codec: arrayCodec})
+		}
+		registered++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if registered == 0 {
+		return errors.New("pgvector extension is not installed")
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterVectorTypes(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support the pgvector extension")
+
+		var available bool
+		err := conn.QueryRow(ctx, `select exists(select 1 from pg_available_extensions where name = 'vector')`).Scan(&available)
+		require.NoError(t, err)
+		if !available {
+			t.Skip("pgvector extension is not available")
+		}
+
+		_, err = conn.Exec(ctx, `create extension if not exists vector`)
+		require.NoError(t, err)
+
+		err = pgx.RegisterVectorTypes(ctx, conn)
+		require.NoError(t, err)
+
+		vec := []float32{1, 2.5, -3}
+		var got []float32
+		err = conn.QueryRow(ctx, `select $1::vector`, vec).Scan(&got)
+		require.NoError(t, err)
+		require.Equal(t, vec, got)
+
+		var gotVectors [][]float32
+		err = conn.QueryRow(ctx, `select array[$1::vector, $1::vector]`, vec).Scan(&gotVectors)
+		require.NoError(t, err)
+		require.Equal(t, [][]float32{vec, vec}, gotVectors)
+
+		var distance float64
+		err = conn.QueryRow(ctx, `select $1::vector <-> $2::vector`, []float32{0, 0}, []float32{3, 4}).Scan(&distance)
+		require.NoError(t, err)
+		require.Equal(t, 5.0, distance)
+
+		if _, ok := conn.TypeMap().TypeForName("halfvec"); ok {
+			err = conn.QueryRow(ctx, `select $1::halfvec`, vec).Scan(&got)
+			require.NoError(t, err)
+			require.Equal(t, vec, got)
+		}
+
+		if _, ok := conn.TypeMap().TypeForName("sparsevec"); ok {
+			sv := pgtype.SparseVector{Dim: 5, Indices: []int32{0, 3}, Values: []float32{1.5, -2}, Valid: true}
+			var gotSparse pgtype.SparseVector
+			err = conn.QueryRow(ctx, `select $1::sparsevec`, sv).Scan(&gotSparse)
+			require.NoError(t, err)
+			require.Equal(t, sv, gotSparse)
+		}
+	})
+}